@@ -0,0 +1,183 @@
+// Package acceptance tracks a ticket's explicit acceptance criteria through
+// a run: it parses them from the description into a checklist, records the
+// reviewer's verdict on each, and renders their status for the PR body.
+package acceptance
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Status is where a criterion stands in review.
+type Status string
+
+const (
+	// Unchecked criteria have not been verified by a reviewer yet.
+	Unchecked Status = "unchecked"
+	// Met criteria were verified by a reviewer.
+	Met Status = "met"
+	// Unmet criteria were found not to hold by a reviewer.
+	Unmet Status = "unmet"
+)
+
+// Criterion is one item of the checklist.
+type Criterion struct {
+	ID       int // 1-based position in the ticket, used by the reviewer
+	Text     string
+	Required bool // Optional items do not block review
+	Status   Status
+	Evidence string // The reviewer's reason for the status
+}
+
+// Check is the reviewer's verdict on one criterion.
+type Check struct {
+	ID       int    `json:"id"`
+	Met      bool   `json:"met"`
+	Evidence string `json:"evidence,omitempty"`
+}
+
+// Checklist holds a ticket's acceptance criteria.
+type Checklist struct {
+	Criteria []Criterion
+}
+
+var (
+	// sectionHeading matches the headings acceptance criteria are given under,
+	// in markdown, bold, Jira wiki markup or as a label ending in a colon.
+	sectionHeading = regexp.MustCompile(`(?i)^(?:#{1,6}\s*|h[1-6]\.\s*)?(?:\*\*|__)?\s*(acceptance criteria|acceptance tests?|definition of done|done when|ac)\s*:?\s*(?:\*\*|__)?\s*:?\s*$`)
+	// anyHeading matches a heading, which ends the criteria section.
+	anyHeading = regexp.MustCompile(`^(?:#{1,6}\s|h[1-6]\.\s|(?:\*\*|__)[^*_]+(?:\*\*|__):?\s*$)`)
+	// listItem matches bulleted, numbered and checkbox items.
+	listItem = regexp.MustCompile(`^\s*(?:[-*+•]|\d+[.)])\s+(?:\[[ xX]\]\s+)?(.+)$`)
+	// optionalMarker marks an item that does not block review.
+	optionalMarker = regexp.MustCompile(`(?i)^\s*(?:optional|nice to have)\s*[:-]\s*|\s*[(\[](?:optional|nice to have)[)\]]\s*`)
+)
+
+// Parse extracts the acceptance criteria listed under an "Acceptance
+// Criteria" (or "Definition of Done") heading in description. Only explicit
+// lists count: a ticket without one has an empty checklist. Items marked
+// "(optional)" or "Nice to have:" are tracked but not required.
+func Parse(description string) *Checklist {
+	c := &Checklist{}
+	inSection := false
+	for _, line := range strings.Split(description, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if sectionHeading.MatchString(trimmed) {
+			inSection = true
+			continue
+		}
+		if !inSection || trimmed == "" {
+			continue
+		}
+		m := listItem.FindStringSubmatch(line)
+		if m == nil {
+			// An introduction may precede the list; anything after it ends it
+			if anyHeading.MatchString(trimmed) || len(c.Criteria) > 0 {
+				inSection = false
+			}
+			continue
+		}
+		text := strings.TrimSpace(m[1])
+		required := !optionalMarker.MatchString(text)
+		text = strings.TrimSpace(optionalMarker.ReplaceAllString(text, " "))
+		if text == "" {
+			continue
+		}
+		c.Criteria = append(c.Criteria, Criterion{
+			ID:       len(c.Criteria) + 1,
+			Text:     text,
+			Required: required,
+			Status:   Unchecked,
+		})
+	}
+	return c
+}
+
+// Record applies a review's checks to the checklist. Criteria the review
+// did not report keep their status, so a differential review that only
+// looks at new changes does not undo earlier verification.
+func (c *Checklist) Record(checks []Check) {
+	for _, check := range checks {
+		for i := range c.Criteria {
+			if c.Criteria[i].ID != check.ID {
+				continue
+			}
+			c.Criteria[i].Status = Unmet
+			if check.Met {
+				c.Criteria[i].Status = Met
+			}
+			c.Criteria[i].Evidence = check.Evidence
+		}
+	}
+}
+
+// Outstanding returns the required criteria that are not met yet.
+func (c *Checklist) Outstanding() []Criterion {
+	var outstanding []Criterion
+	for _, criterion := range c.Criteria {
+		if criterion.Required && criterion.Status != Met {
+			outstanding = append(outstanding, criterion)
+		}
+	}
+	return outstanding
+}
+
+// MetCount returns how many criteria are met.
+func (c *Checklist) MetCount() int {
+	met := 0
+	for _, criterion := range c.Criteria {
+		if criterion.Status == Met {
+			met++
+		}
+	}
+	return met
+}
+
+// Prompt renders the checklist as a review prompt section asking the
+// reviewer to verify each criterion. Returns "" for an empty checklist.
+func (c *Checklist) Prompt() string {
+	if len(c.Criteria) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("## Acceptance Criteria\n\n")
+	sb.WriteString("Verify each of the ticket's acceptance criteria against the changes. A criterion is met only if the code or tests show it holds.\n")
+	for _, criterion := range c.Criteria {
+		kind := "required"
+		if !criterion.Required {
+			kind = "optional"
+		}
+		sb.WriteString(fmt.Sprintf("%d. [%s] %s\n", criterion.ID, kind, criterion.Text))
+	}
+	sb.WriteString("\nInclude a \"criteria\" array in your response with one entry per criterion: ")
+	sb.WriteString(`{"id": number, "met": boolean, "evidence": "where the changes meet it, or what is missing"}.`)
+	sb.WriteString(" Do not pass the review while a required criterion is not met.\n")
+	return sb.String()
+}
+
+// Markdown renders the checklist for the PR body, checking off the met
+// criteria. Returns "" for an empty checklist.
+func (c *Checklist) Markdown() string {
+	if len(c.Criteria) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("### ✅ Acceptance Criteria\n")
+	for _, criterion := range c.Criteria {
+		box := " "
+		if criterion.Status == Met {
+			box = "x"
+		}
+		line := fmt.Sprintf("- [%s] %s", box, criterion.Text)
+		if !criterion.Required {
+			line += " _(optional)_"
+		}
+		if criterion.Status == Unmet && criterion.Evidence != "" {
+			line += " — " + criterion.Evidence
+		}
+		sb.WriteString(line + "\n")
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
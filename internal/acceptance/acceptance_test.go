@@ -0,0 +1,93 @@
+package acceptance
+
+import (
+	"strings"
+	"testing"
+)
+
+const ticket = `Checkout should round totals per currency.
+
+## Acceptance Criteria
+The following must hold:
+- [ ] JPY totals have no decimal places
+- [x] BHD totals are rounded to three decimals
+* Nice to have: a currency switcher on the cart page
+1. Rounding happens before tax (optional)
+
+## Notes
+- Not a criterion`
+
+func TestParse(t *testing.T) {
+	c := Parse(ticket)
+	want := []Criterion{
+		{ID: 1, Text: "JPY totals have no decimal places", Required: true, Status: Unchecked},
+		{ID: 2, Text: "BHD totals are rounded to three decimals", Required: true, Status: Unchecked},
+		{ID: 3, Text: "a currency switcher on the cart page", Required: false, Status: Unchecked},
+		{ID: 4, Text: "Rounding happens before tax", Required: false, Status: Unchecked},
+	}
+	if len(c.Criteria) != len(want) {
+		t.Fatalf("Criteria = %+v", c.Criteria)
+	}
+	for i := range want {
+		if c.Criteria[i] != want[i] {
+			t.Errorf("Criteria[%d] = %+v, want %+v", i, c.Criteria[i], want[i])
+		}
+	}
+}
+
+func TestParseHeadings(t *testing.T) {
+	for _, description := range []string{
+		"**Acceptance Criteria:**\n- Totals are rounded\n\nSome closing remark.\n- Not a criterion",
+		"h3. Definition of Done\n* Totals are rounded",
+		"AC:\n- Totals are rounded",
+	} {
+		c := Parse(description)
+		if len(c.Criteria) != 1 || c.Criteria[0].Text != "Totals are rounded" {
+			t.Errorf("Parse(%q) = %+v", description, c.Criteria)
+		}
+	}
+	if c := Parse("- Fix the rounding\n- Add tests"); len(c.Criteria) != 0 {
+		t.Errorf("Parse without a criteria heading = %+v", c.Criteria)
+	}
+}
+
+func TestRecord(t *testing.T) {
+	c := Parse(ticket)
+	c.Record([]Check{{ID: 1, Met: true, Evidence: "round.go uses minor units"}, {ID: 2, Met: false, Evidence: "BHD is not handled"}})
+	if n := c.MetCount(); n != 1 {
+		t.Errorf("MetCount = %d, want 1", n)
+	}
+	outstanding := c.Outstanding()
+	if len(outstanding) != 1 || outstanding[0].ID != 2 || outstanding[0].Status != Unmet {
+		t.Errorf("Outstanding = %+v", outstanding)
+	}
+
+	// A later review that does not report a criterion keeps its status
+	c.Record([]Check{{ID: 2, Met: true}})
+	if len(c.Outstanding()) != 0 || c.Criteria[0].Status != Met {
+		t.Errorf("Criteria = %+v", c.Criteria)
+	}
+}
+
+func TestPromptAndMarkdown(t *testing.T) {
+	c := Parse(ticket)
+	c.Record([]Check{{ID: 1, Met: true}, {ID: 2, Met: false, Evidence: "BHD is not handled"}})
+
+	prompt := c.Prompt()
+	for _, want := range []string{"## Acceptance Criteria", "1. [required] JPY totals", "3. [optional] a currency switcher", `"criteria"`} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Prompt missing %q:\n%s", want, prompt)
+		}
+	}
+
+	md := c.Markdown()
+	for _, want := range []string{"- [x] JPY totals have no decimal places\n", "- [ ] BHD totals are rounded to three decimals — BHD is not handled\n", "- [ ] Rounding happens before tax _(optional)_\n"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("Markdown missing %q:\n%s", want, md)
+		}
+	}
+
+	if empty := Parse("No criteria here"); empty.Prompt() != "" || empty.Markdown() != "" {
+		t.Error("empty checklist renders sections")
+	}
+}
@@ -0,0 +1,79 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/acceptance"
+	"github.com/philjestin/boatmanmode/internal/scottbott"
+)
+
+// checklist returns the ticket's acceptance criteria, parsing them on first
+// use. A resumed run starts from the checked-off items of its last review.
+// Returns nil when review.acceptance_criteria is off or there are none.
+func (a *Agent) checklist(wc *workContext) *acceptance.Checklist {
+	if !a.config.Review.AcceptanceCriteria {
+		return nil
+	}
+	if wc.acceptance == nil {
+		wc.acceptance = acceptance.Parse(wc.task.GetDescription())
+		if wc.reviewResult != nil {
+			wc.acceptance.Record(wc.reviewResult.Criteria)
+		}
+		if n := len(wc.acceptance.Criteria); n > 0 {
+			fmt.Fprintf(a.output(), "   ☑️  Tracking %d acceptance criteria\n", n)
+		}
+	}
+	if len(wc.acceptance.Criteria) == 0 {
+		return nil
+	}
+	return wc.acceptance
+}
+
+// applyAcceptance records a fresh review's verdict on each acceptance
+// criterion and fails the review while a required one is unmet or was not
+// verified, adding it as a major issue for the refactor to address.
+// Inconclusive reviews already need a human and are left as they are.
+func (a *Agent) applyAcceptance(wc *workContext, result *scottbott.ReviewResult) {
+	checklist := a.checklist(wc)
+	if checklist == nil || result.Inconclusive {
+		return
+	}
+	checklist.Record(result.Criteria)
+	outstanding := checklist.Outstanding()
+	fmt.Fprintf(a.output(), "   ☑️  Acceptance criteria: %d/%d met\n", checklist.MetCount(), len(checklist.Criteria))
+	if len(outstanding) == 0 {
+		return
+	}
+
+	var texts []string
+	for _, criterion := range outstanding {
+		issue := scottbott.Issue{
+			Severity:    "major",
+			Description: "Acceptance criterion not verified: " + criterion.Text,
+			Suggestion:  "Make sure the changes meet this criterion and that the code or tests show it",
+		}
+		if criterion.Status == acceptance.Unmet {
+			issue.Description = "Acceptance criterion not met: " + criterion.Text
+			if criterion.Evidence != "" {
+				issue.Suggestion = criterion.Evidence
+			}
+		}
+		result.Issues = append(result.Issues, issue)
+		texts = append(texts, criterion.Text)
+	}
+	if result.Passed {
+		wc.decisions.Record("review", "fail review on unmet acceptance criteria",
+			fmt.Sprintf("required criteria not met: %s", strings.Join(texts, "; ")))
+	}
+	result.Passed = false
+}
+
+// acceptanceSection renders the acceptance checklist for the PR body.
+func (a *Agent) acceptanceSection(wc *workContext) string {
+	checklist := a.checklist(wc)
+	if checklist == nil {
+		return ""
+	}
+	return checklist.Markdown()
+}
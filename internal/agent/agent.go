@@ -3,29 +3,63 @@
 package agent
 
 import (
+	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/philjestin/boatmanmode/internal/acceptance"
+	"github.com/philjestin/boatmanmode/internal/bundlesize"
+	"github.com/philjestin/boatmanmode/internal/checkpoint"
 	"github.com/philjestin/boatmanmode/internal/config"
 	"github.com/philjestin/boatmanmode/internal/contextpin"
 	"github.com/philjestin/boatmanmode/internal/coordinator"
 	"github.com/philjestin/boatmanmode/internal/cost"
+	"github.com/philjestin/boatmanmode/internal/coverage"
+	"github.com/philjestin/boatmanmode/internal/decisionlog"
 	"github.com/philjestin/boatmanmode/internal/diffverify"
+	"github.com/philjestin/boatmanmode/internal/e2e"
+	"github.com/philjestin/boatmanmode/internal/egress"
+	"github.com/philjestin/boatmanmode/internal/estimate"
 	"github.com/philjestin/boatmanmode/internal/events"
 	"github.com/philjestin/boatmanmode/internal/executor"
-	"github.com/philjestin/boatmanmode/internal/github"
+	"github.com/philjestin/boatmanmode/internal/featureflags"
+	"github.com/philjestin/boatmanmode/internal/forge"
+	"github.com/philjestin/boatmanmode/internal/gerrit"
+	"github.com/philjestin/boatmanmode/internal/gitops"
+	"github.com/philjestin/boatmanmode/internal/graphqlschema"
 	"github.com/philjestin/boatmanmode/internal/handoff"
+	"github.com/philjestin/boatmanmode/internal/hooks"
+	"github.com/philjestin/boatmanmode/internal/impact"
+	"github.com/philjestin/boatmanmode/internal/ingest"
+	"github.com/philjestin/boatmanmode/internal/jira"
 	"github.com/philjestin/boatmanmode/internal/linear"
+	"github.com/philjestin/boatmanmode/internal/lint"
+	"github.com/philjestin/boatmanmode/internal/llm"
+	"github.com/philjestin/boatmanmode/internal/manifest"
+	"github.com/philjestin/boatmanmode/internal/memory"
+	"github.com/philjestin/boatmanmode/internal/notify"
+	"github.com/philjestin/boatmanmode/internal/observability"
+	"github.com/philjestin/boatmanmode/internal/pipeline"
 	"github.com/philjestin/boatmanmode/internal/planner"
 	"github.com/philjestin/boatmanmode/internal/preflight"
+	"github.com/philjestin/boatmanmode/internal/retro"
+	"github.com/philjestin/boatmanmode/internal/retry"
 	"github.com/philjestin/boatmanmode/internal/scottbott"
+	"github.com/philjestin/boatmanmode/internal/security"
+	"github.com/philjestin/boatmanmode/internal/sentry"
+	"github.com/philjestin/boatmanmode/internal/snapshots"
+	"github.com/philjestin/boatmanmode/internal/specs"
 	"github.com/philjestin/boatmanmode/internal/task"
+	"github.com/philjestin/boatmanmode/internal/telemetry"
 	"github.com/philjestin/boatmanmode/internal/testrunner"
+	"github.com/philjestin/boatmanmode/internal/unidiff"
 	"github.com/philjestin/boatmanmode/internal/worktree"
 )
 
@@ -33,7 +67,20 @@ import (
 type Agent struct {
 	config       *config.Config
 	linearClient *linear.Client
+	sentryClient *sentry.Client
+	specClient   *specs.Client
+	fetcher      *ingest.Fetcher
+	jiraClient   *jira.Client
 	coordinator  *coordinator.Coordinator
+	notifier     *notify.Notifier
+	stages       pipeline.Pipeline
+	hooks        *hooks.Runner // Commands run around steps; nil without hooks
+	input        *bufio.Reader // Operator input for pair mode; defaults to stdin
+	force        bool          // Run tasks the success predictor would decline
+	approvePlan  bool          // Approve the plan of a run paused for plan review
+	approve      bool          // Approve what a paused run is waiting on
+	runID        string        // Checkpoint ID for the next run; generated when empty
+	out          io.Writer     // Progress output; stdout when nil
 }
 
 // WorkResult represents the outcome of the work command.
@@ -41,6 +88,8 @@ type WorkResult struct {
 	PRCreated    bool
 	PRURL        string
 	Message      string
+	Paused       bool // Waiting for the operator; continue with boatman resume
+	Cancelled    bool // Stopped by boatman cancel or an interrupt; continue with boatman resume
 	Iterations   int
 	TestsPassed  bool
 	TestCoverage float64
@@ -49,79 +98,248 @@ type WorkResult struct {
 // workContext holds state shared between workflow steps.
 type workContext struct {
 	task         task.Task
+	repoPath     string
 	worktree     *worktree.Worktree
 	branchName   string
+	baseCommit   string // HEAD when the worktree was created, for history curation
 	pinner       *contextpin.ContextPinner
 	plan         *planner.Plan
 	exec         *executor.Executor
 	execResult   *executor.ExecutionResult
 	testResult   *testrunner.TestResult
+	testedTree   string // Index tree testResult was produced from
 	reviewResult *scottbott.ReviewResult
+	reviews      []*scottbott.ReviewResult // Every review of the run, for the retro
+	fixed        []string                  // Issues verified as fixed across refactors
+	newIssues    []scottbott.Issue         // Detected by diff verification, fed into the next review
+	verification *diffverify.VerificationResult
+	focusIssues  []string // Unaddressed issues for the next review to focus on
+	resolved     []string // Issues verified as fixed by the last refactor
+	reviewedTree string   // Index tree at the last review, for differential reviews
+	finalDiff    string
+	flags        *featureflags.Checker
+	instrumented *observability.Checker
+	bundle       *bundlesize.Checker
+	bundleReport *bundlesize.Report
+	snapshots    *snapshots.Policy
+	snapReasons  map[string]string
+	gerrit       bool
+	testFixes    int
+	protected    []string // Committed files matching config.ProtectedPaths
+	humanEdited  []string // Files edited by hand in pair mode
+	pairDone     bool     // Operator asked to stop pair mode pauses
+	reviewPlan   bool     // Have the operator approve the plan before execution
+	gateApproved bool     // The require_approval gate was approved on resume
+	failureModes string   // Historical failure warnings for agent prompts
+	errorContext string   // Distilled Sentry errors of a bug ticket
+	specs        string   // Summaries of the specs the ticket links
+	media        string   // The ticket's screenshots and linked pages as text
+	declined     bool     // Success predictor declined the task
 	iterations   int
+	iterTags     []iterationTag // Review iterations tagged under git.iteration_tags
 	startTime    time.Time
 	costTracker  *cost.Tracker
+	decisions    *decisionlog.Log
+	checkpoint   *checkpoint.Manager // Progress for `boatman status`; nil if unavailable
+	stepMetrics  []telemetry.StepMetric
+	failedStep   string
+	acceptance   *acceptance.Checklist     // The ticket's acceptance criteria; parsed on first use
+	e2eResult    *e2e.Result               // The last run of the change's E2E specs
+	cancelMu     sync.Mutex                // Guards cancelReq, set by the cancel watcher
+	cancelReq    *checkpoint.CancelRequest // Set when boatman cancel stopped the run
+	lintedTree   string                    // Index tree lintResult was produced from
+	lintResult   *lint.Result              // The last lint of the change
+	covBaseline  coverage.Profile          // Per-file coverage before the change, under min_coverage
+	coveredTree  string                    // Index tree covFindings were produced from
+	covFindings  []coverage.Finding        // Changed files covered below min_coverage
+	scannedTree  string                    // Index tree scanResult was produced from
+	scanResult   *security.Result          // Security scan of the change
+	verifiedTree string                    // Index tree pactIssues were produced from
+	pactIssues   []scottbott.Issue         // Broken consumer pacts of the change
+	schemaTree   string                    // Index tree schemaReport was produced from
+	schemaReport *graphqlschema.Report     // GraphQL schema changes of the last review
+	proxy        *egress.Proxy             // Egress proxy of the sessions; nil without network.enabled
+	manifest     *manifest.Manifest        // Conditions the run started under
 }
 
 // New creates a new Agent.
 func New(cfg *config.Config) (*Agent, error) {
+	if _, err := llm.New(cfg.Claude.Provider); err != nil {
+		return nil, err
+	}
+	stages, err := pipeline.Parse(cfg.Pipeline)
+	if err != nil {
+		return nil, err
+	}
+	runner, err := hooks.New(cfg.Hooks)
+	if err != nil {
+		return nil, err
+	}
 	return &Agent{
 		config:       cfg,
 		linearClient: linear.New(cfg.LinearKey),
+		jiraClient:   jira.New(cfg.Jira),
+		sentryClient: sentry.New(cfg.Sentry),
+		specClient:   specs.New(cfg.Specs),
+		fetcher:      ingest.New(),
 		coordinator:  coordinator.New(),
+		notifier:     notify.New(cfg.Notify),
+		stages:       stages,
+		hooks:        runner,
 	}, nil
 }
 
+// SetForce makes the agent run tasks even when the success predictor
+// would decline them.
+func (a *Agent) SetForce(force bool) {
+	a.force = force
+}
+
+// SetOutput sends the agent's progress output, and that of the agents it
+// runs, to w; nil restores stdout. Events are not affected.
+func (a *Agent) SetOutput(w io.Writer) {
+	a.out = w
+	if a.hooks != nil {
+		a.hooks.SetOutput(w)
+	}
+}
+
+// output returns where progress output goes.
+func (a *Agent) output() io.Writer {
+	if a.out == nil {
+		return os.Stdout
+	}
+	return a.out
+}
+
+// SetRunID sets the checkpoint ID of the next run, for runs whose ID was
+// reported before they started, such as detached runs.
+func (a *Agent) SetRunID(id string) {
+	a.runID = id
+}
+
 // Work executes the complete workflow for a task.
 // Orchestrates 9 steps: prepare → worktree → plan → validate → execute → test → review → commit → PR
 func (a *Agent) Work(ctx context.Context, t task.Task) (*WorkResult, error) {
+	wc := newWorkContext(t)
+
+	// Track progress in a checkpoint so `boatman status` can show this run
+	if cp, err := checkpoint.NewManager(""); err == nil {
+		id := a.runID
+		if id == "" {
+			id = checkpoint.NewID(t.GetID())
+		}
+		// Webhooks and pollers may hand us a ticket that is already running
+		unlock, err := cp.Lock(t.GetID(), id)
+		if err != nil {
+			return nil, err
+		}
+		defer unlock()
+		cp.StartWithID(id, t.GetID(), a.config.MaxIterations)
+		cp.SetSettings(runSettings(a.config))
+		wc.checkpoint = cp
+	}
+	wc.reviewPlan = a.config.ReviewPlan
+	return a.run(ctx, wc, checkpoint.StepFetchTicket)
+}
+
+func newWorkContext(t task.Task) *workContext {
 	wc := &workContext{
 		task:        t,
 		startTime:   time.Now(),
 		costTracker: cost.NewTracker(),
+		decisions:   decisionlog.New(),
 	}
+	wc.costTracker.OnAdd(emitCost)
+	return wc
+}
+
+// run executes the workflow from step on; earlier steps' state must
+// already be in wc.
+func (a *Agent) run(ctx context.Context, wc *workContext, from checkpoint.Step) (result *WorkResult, err error) {
+	defer wc.checkpoint.Finish()
+	defer func() { emitRunCompleted(wc, result, err) }()
+	defer func() { a.notifyFinished(wc, result, err) }()
+	a.notify(wc, notify.Event{Type: notify.Started})
+	defer func() { a.reportTelemetry(wc, result, err) }()
+	defer func() { a.recordStepFailure(wc, err) }()
+	defer func() {
+		if errors.Is(err, errCancelled) {
+			result, err = a.cancelled(wc), nil
+		}
+	}()
+	ctx, stopWatch := a.watchCancel(ctx, wc)
+	defer stopWatch()
 
 	// Start the coordinator
 	a.coordinator.Start(ctx)
 	defer a.coordinator.Stop()
 
-	// Step 1: Prepare task (already received as parameter)
-	if err := a.stepPrepareTask(ctx, wc); err != nil {
+	if err := a.startEgress(wc); err != nil {
 		return nil, err
 	}
-
-	// Step 2: Setup worktree
-	if err := a.stepSetupWorktree(ctx, wc); err != nil {
-		return nil, err
+	defer a.stopEgress(wc)
+	a.warnUnpricedBudget(wc)
+
+	steps := []struct {
+		step checkpoint.Step
+		run  func(context.Context, *workContext) error
+	}{
+		{checkpoint.StepFetchTicket, a.stepPrepareTask},        // Step 1: Prepare task (already received as parameter)
+		{checkpoint.StepCreateWorktree, a.stepSetupWorktree},   // Step 2: Setup worktree
+		{checkpoint.StepPlanning, a.stepPlanning},              // Step 3: Planning
+		{checkpoint.StepPlanReview, a.stepReviewPlan},          // Step 3: Operator approval of the plan (review_plan)
+		{checkpoint.StepValidation, a.stepPreflightValidation}, // Step 4: Pre-flight validation
+		{checkpoint.StepExecution, a.stepExecute},              // Step 5: Execute development task
+		{checkpoint.StepLint, a.stepLint},                      // Step 5: Lint (pipeline: lint)
+		{checkpoint.StepTesting, a.stepTestAndReview},          // Step 6: Run tests, then initial review with test results
+		{checkpoint.StepReview, a.stepRefactorLoop},            // Step 7: Review & refactor loop
 	}
-
-	// Step 3: Planning
-	if err := a.stepPlanning(ctx, wc); err != nil {
-		return nil, err
-	}
-
-	// Step 4: Pre-flight validation
-	if err := a.stepPreflightValidation(ctx, wc); err != nil {
-		return nil, err
+	for _, s := range steps {
+		if checkpoint.Before(s.step, from) {
+			continue
+		}
+		if !a.runsStep(s.step) {
+			wc.checkpoint.SkipStep(s.step)
+			continue
+		}
+		if err := a.runStep(ctx, wc, s.step, s.run); err != nil {
+			if errors.Is(err, errAwaitingApproval) {
+				return &WorkResult{Paused: true, Message: wc.checkpoint.Current.Paused}, nil
+			}
+			if stop := a.budgetStop(wc, err); stop != nil {
+				return stop, nil
+			}
+			return nil, err
+		}
 	}
 
-	// Step 5: Execute development task
-	if err := a.stepExecute(ctx, wc); err != nil {
-		return nil, err
+	if ctx.Err() != nil {
+		return nil, errCancelled
 	}
 
-	// Step 6: Run tests and initial review (parallel)
-	if err := a.stepTestAndReview(ctx, wc); err != nil {
-		return nil, err
+	// Release context pins
+	wc.pinner.Unpin("executor")
+	a.skipReview(wc)
+
+	// Track review scores over time for trend reporting, unless the run
+	// that reviewed already did, before pausing or stopping
+	if !checkpoint.Before(checkpoint.StepReview, from) && a.stages.Has(pipeline.Review) {
+		a.recordScoreHistory(wc)
+		if a.config.Retro && a.checkBudget(wc, "retro") == nil {
+			a.runRetro(ctx, wc)
+		}
 	}
 
-	// Step 7: Review & refactor loop
-	if err := a.stepRefactorLoop(ctx, wc); err != nil {
-		return nil, err
+	if wc.reviewResult.Inconclusive {
+		fmt.Fprint(a.output(), wc.decisions.Format())
+		return &WorkResult{
+			PRCreated:  false,
+			Message:    fmt.Sprintf("Review inconclusive; human approval required (worktree: %s)", wc.worktree.Path),
+			Iterations: wc.iterations,
+		}, nil
 	}
 
-	// Release context pins
-	wc.pinner.Unpin("executor")
-
 	// Check if review passed
 	if !wc.reviewResult.Passed {
 		return &WorkResult{
@@ -131,13 +349,224 @@ func (a *Agent) Work(ctx context.Context, t task.Task) (*WorkResult, error) {
 		}, nil
 	}
 
+	if a.config.DryRun {
+		return a.finishDryRun(wc), nil
+	}
+	if !a.runsStep(checkpoint.StepCommit) {
+		return a.finishPipeline(wc), nil
+	}
+
 	// Step 8: Commit and push
-	if err := a.stepCommitAndPush(ctx, wc); err != nil {
+	if paused := a.awaitApproval(wc, checkpoint.StepCommit); paused != nil {
+		return paused, nil
+	}
+	if checkpoint.Before(checkpoint.StepCommit, from) {
+		wc.finalDiff, _ = wc.exec.Git().Diff(wc.baseCommit, "HEAD")
+		wc.gerrit = a.useGerrit(wc)
+	} else if err := a.runStep(ctx, wc, checkpoint.StepCommit, a.stepCommit); err != nil {
+		if stop := a.budgetStop(wc, err); stop != nil {
+			return stop, nil
+		}
 		return nil, err
 	}
+	if !a.runsStep(checkpoint.StepCreatePR) {
+		return a.finishPipeline(wc), nil
+	}
+	if paused := a.awaitApproval(wc, checkpoint.StepPush); paused != nil {
+		return paused, nil
+	}
+	// Gerrit changes are pushed for review in place of the PR
+	if !wc.gerrit && !checkpoint.Before(checkpoint.StepPush, from) {
+		if err := a.runStep(ctx, wc, checkpoint.StepPush, a.stepPush); err != nil {
+			if stop := a.budgetStop(wc, err); stop != nil {
+				return stop, nil
+			}
+			return nil, err
+		}
+	}
 
 	// Step 9: Create PR
-	return a.stepCreatePR(ctx, wc)
+	if paused := a.awaitApproval(wc, checkpoint.StepCreatePR); paused != nil {
+		return paused, nil
+	}
+	err = a.runStep(ctx, wc, checkpoint.StepCreatePR, func(ctx context.Context, wc *workContext) error {
+		var err error
+		result, err = a.stepCreatePR(ctx, wc)
+		return err
+	})
+	if stop := a.budgetStop(wc, err); stop != nil {
+		return stop, nil
+	}
+	if err == nil && result != nil && result.PRCreated {
+		a.recordPR(wc, result.PRURL)
+	}
+	return result, err
+}
+
+// runStep runs a workflow step, recording its progress and the cost so far
+// in the run's checkpoint.
+func (a *Agent) runStep(ctx context.Context, wc *workContext, step checkpoint.Step, run func(context.Context, *workContext) error) error {
+	if ctx.Err() != nil {
+		return errCancelled
+	}
+	wc.checkpoint.BeginStep(step)
+	events.StepStarted(string(step))
+	start := time.Now()
+	err := a.checkBudget(wc, string(step))
+	if err == nil {
+		err = a.runHooks(ctx, wc, step, hooks.Before)
+	}
+	if err == nil {
+		err = run(ctx, wc)
+	}
+	if err == nil {
+		err = a.runHooks(ctx, wc, step, hooks.After)
+	}
+	if ctx.Err() != nil {
+		// Whatever the step made of its cut-short work, it runs again on
+		// resume
+		events.StepCompleted(string(step), "cancelled", time.Since(start), wc.costTracker.Total().TotalCostUSD)
+		return errCancelled
+	}
+	if errors.Is(err, errAwaitingApproval) {
+		// Left in progress, to run again on resume
+		events.StepCompleted(string(step), "paused", time.Since(start), wc.costTracker.Total().TotalCostUSD)
+		return err
+	}
+	wc.stepMetrics = append(wc.stepMetrics, telemetry.StepMetric{
+		Name:       string(step),
+		DurationMs: time.Since(start).Milliseconds(),
+		Failed:     err != nil,
+	})
+	total := wc.costTracker.Total().TotalCostUSD
+	wc.checkpoint.SetCost(total)
+	status := "success"
+	if err != nil {
+		status = "failed"
+	}
+	events.StepCompleted(string(step), status, time.Since(start), total)
+	if err != nil {
+		wc.failedStep = string(step)
+		wc.checkpoint.FailStep(step, err)
+		return err
+	}
+	wc.checkpoint.CompleteStep(step, stepOutput(wc, step))
+	return nil
+}
+
+// TaskRecord is the checkpoint output of the prepare step.
+type TaskRecord struct {
+	ID          string `json:"id"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Source      string `json:"source,omitempty"`
+}
+
+// WorktreeRecord is the checkpoint output of the worktree step. The
+// worktree's path and branch are on the checkpoint itself.
+type WorktreeRecord struct {
+	RepoPath   string `json:"repo_path"`
+	BaseCommit string `json:"base_commit"`
+}
+
+// ExecutionRecord is the checkpoint output of the execute step.
+type ExecutionRecord struct {
+	Summary      string            `json:"summary"`
+	FilesChanged []string          `json:"files_changed"`
+	Renames      map[string]string `json:"renames,omitempty"`
+	HumanEdited  []string          `json:"human_edited,omitempty"`
+}
+
+// ReviewRecord is the checkpoint output of the review loop: the final
+// review, flattened so it decodes as a scottbott.ReviewResult, and every
+// review of the run in order.
+type ReviewRecord struct {
+	*scottbott.ReviewResult
+	History []*scottbott.ReviewResult `json:"history,omitempty"`
+}
+
+// runSettings lists the models and options that shape a run's outcome.
+func runSettings(cfg *config.Config) map[string]string {
+	settings := map[string]string{
+		"max_iterations": fmt.Sprint(cfg.MaxIterations),
+		"review_skill":   cfg.ReviewSkill,
+		"enable_tools":   fmt.Sprint(cfg.EnableTools),
+	}
+	for name, model := range map[string]string{
+		"planner":    cfg.Claude.Models.Planner,
+		"executor":   cfg.Claude.Models.Executor,
+		"reviewer":   cfg.Claude.Models.Reviewer,
+		"refactor":   cfg.Claude.Models.Refactor,
+		"verifier":   cfg.Claude.Models.Verifier,
+		"test_fixer": cfg.Claude.Models.TestFixer,
+	} {
+		if model == "" {
+			model = "default"
+		}
+		settings["model."+name] = model
+	}
+	return settings
+}
+
+// stepOutput returns what a completed step records in the checkpoint, so
+// a run's context (task, plan, review) can be inspected after it ends.
+func stepOutput(wc *workContext, step checkpoint.Step) interface{} {
+	switch step {
+	case checkpoint.StepFetchTicket:
+		return TaskRecord{ID: wc.task.GetID(), Title: wc.task.GetTitle(), Description: wc.task.GetDescription(), Source: string(wc.task.GetMetadata().Source)}
+	case checkpoint.StepCreateWorktree:
+		return WorktreeRecord{RepoPath: wc.repoPath, BaseCommit: wc.baseCommit}
+	case checkpoint.StepPlanning, checkpoint.StepPlanReview:
+		if wc.plan != nil {
+			return wc.plan
+		}
+	case checkpoint.StepExecution:
+		return ExecutionRecord{Summary: wc.execResult.Summary, FilesChanged: wc.execResult.FilesChanged, Renames: wc.execResult.Renames, HumanEdited: wc.humanEdited}
+	case checkpoint.StepTesting:
+		if wc.reviewResult != nil {
+			return wc.reviewResult
+		}
+	case checkpoint.StepReview:
+		if wc.reviewResult != nil {
+			return ReviewRecord{ReviewResult: wc.reviewResult, History: wc.reviews}
+		}
+	}
+	return nil
+}
+
+// reportTelemetry sends the anonymized run summary if the user opted in.
+func (a *Agent) reportTelemetry(wc *workContext, result *WorkResult, err error) {
+	client := telemetry.New(a.config.Telemetry, "")
+	if !client.Enabled() {
+		return
+	}
+
+	report := telemetry.Report{
+		Iterations: wc.iterations,
+		DurationMs: time.Since(wc.startTime).Milliseconds(),
+		Steps:      wc.stepMetrics,
+	}
+	switch {
+	case err != nil:
+		report.Outcome = telemetry.OutcomeError
+		report.FailureCategory = telemetry.FailureCategory(wc.failedStep, err)
+	case result != nil && result.PRCreated:
+		report.Outcome = telemetry.OutcomePRCreated
+	case result != nil && result.Cancelled:
+		report.Outcome = telemetry.OutcomeCancelled
+	case wc.reviewResult != nil && wc.reviewResult.Inconclusive:
+		report.Outcome = telemetry.OutcomeInconclusive
+	case wc.reviewResult != nil && !wc.reviewResult.Passed:
+		report.Outcome = telemetry.OutcomeReviewFailed
+	default:
+		report.Outcome = telemetry.OutcomeNoPR
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Send(ctx, report); err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Telemetry not sent: %v\n", err)
+	}
 }
 
 // stepPrepareTask displays task information (Step 1).
@@ -145,22 +574,22 @@ func (a *Agent) stepPrepareTask(ctx context.Context, wc *workContext) error {
 	agentID := fmt.Sprintf("prepare-%s", wc.task.GetID())
 	events.AgentStarted(agentID, "Preparing Task", fmt.Sprintf("Preparing task %s", wc.task.GetID()))
 
-	printStep(1, 9, "Preparing task")
-	fmt.Printf("   🎫 Task ID: %s\n", wc.task.GetID())
+	a.printStep(1, 9, "Preparing task")
+	fmt.Fprintf(a.output(), "   🎫 Task ID: %s\n", wc.task.GetID())
 
 	metadata := wc.task.GetMetadata()
-	fmt.Printf("   📋 Source: %s\n", metadata.Source)
-	fmt.Printf("   📝 Title: %s\n", wc.task.GetTitle())
+	fmt.Fprintf(a.output(), "   📋 Source: %s\n", metadata.Source)
+	fmt.Fprintf(a.output(), "   📝 Title: %s\n", wc.task.GetTitle())
 
 	labels := wc.task.GetLabels()
 	if len(labels) > 0 {
-		fmt.Printf("   🏷️  Labels: %s\n", strings.Join(labels, ", "))
+		fmt.Fprintf(a.output(), "   🏷️  Labels: %s\n", strings.Join(labels, ", "))
 	}
 
-	fmt.Println()
-	fmt.Println("   📝 Description:")
-	printIndented(truncate(wc.task.GetDescription(), 800), "      ")
-	fmt.Println()
+	fmt.Fprintln(a.output())
+	fmt.Fprintln(a.output(), "   📝 Description:")
+	a.printIndented(truncate(wc.task.GetDescription(), 800), "      ")
+	fmt.Fprintln(a.output())
 
 	events.AgentCompleted(agentID, "Preparing Task", "success")
 	return nil
@@ -171,39 +600,58 @@ func (a *Agent) stepSetupWorktree(ctx context.Context, wc *workContext) error {
 	agentID := fmt.Sprintf("worktree-%s", wc.task.GetID())
 	events.AgentStarted(agentID, "Setup Worktree", "Creating isolated git worktree")
 
-	printStep(2, 9, "Setting up git worktree")
+	a.printStep(2, 9, "Setting up git worktree")
 
 	repoPath, err := os.Getwd()
 	if err != nil {
 		events.AgentCompleted(agentID, "Setup Worktree", "failed")
 		return fmt.Errorf("failed to get working directory: %w", err)
 	}
-	fmt.Printf("   📂 Repo: %s\n", repoPath)
+	fmt.Fprintf(a.output(), "   📂 Repo: %s\n", repoPath)
 
-	wtManager, err := worktree.New(repoPath)
+	wtManager, err := worktree.NewWithOptions(repoPath, worktree.Options{
+		Sparse:     a.config.Worktree.Sparse,
+		FetchDepth: a.config.Worktree.FetchDepth,
+		Root:       a.config.Worktree.Root,
+		Output:     a.out,
+	})
 	if err != nil {
 		events.AgentCompleted(agentID, "Setup Worktree", "failed")
 		return fmt.Errorf("failed to create worktree manager: %w", err)
 	}
 
 	branchName := wc.task.GetBranchName()
-	fmt.Printf("   🌿 Branch: %s\n", branchName)
+	fmt.Fprintf(a.output(), "   🌿 Branch: %s\n", branchName)
 
 	wt, err := wtManager.Create(branchName, a.config.BaseBranch)
 	if err != nil {
 		events.AgentCompleted(agentID, "Setup Worktree", "failed")
 		return fmt.Errorf("failed to create worktree: %w", err)
 	}
-	fmt.Printf("   📁 Worktree: %s\n", wt.Path)
-	fmt.Println()
+	fmt.Fprintf(a.output(), "   📁 Worktree: %s\n", wt.Path)
+	if wt.Sparse {
+		fmt.Fprintln(a.output(), "   🪶 Sparse checkout enabled (paths added after planning)")
+		if err := wt.ExpandSparse(a.config.Worktree.SparsePaths...); err != nil {
+			fmt.Fprintf(a.output(), "   ⚠️  Failed to add configured sparse paths: %v\n", err)
+		}
+	}
+	if wt.LFSReady {
+		fmt.Fprintf(a.output(), "   📦 Git LFS configured (%d tracked pattern(s))\n", len(wt.LFSPatterns))
+	}
+	fmt.Fprintln(a.output())
 
+	wc.repoPath = repoPath
 	wc.worktree = wt
 	wc.branchName = branchName
+	wc.checkpoint.SetWorktree(wt.Path, branchName)
+	wc.baseCommit, _ = gitops.New(wt.Path).RevParse("HEAD")
 
 	// Initialize context pinner for multi-file coordination
 	wc.pinner = contextpin.New(wt.Path)
 	wc.pinner.SetCoordinator(a.coordinator)
 
+	a.recordManifest(wc)
+
 	events.AgentCompleted(agentID, "Setup Worktree", "success")
 	return nil
 }
@@ -213,17 +661,36 @@ func (a *Agent) stepPlanning(ctx context.Context, wc *workContext) error {
 	agentID := fmt.Sprintf("planning-%s", wc.task.GetID())
 	events.AgentStarted(agentID, "Planning & Analysis", "Analyzing codebase and creating implementation plan")
 
-	printStep(3, 9, "Planning & analysis (parallel)")
+	a.printStep(3, 9, "Planning & analysis (parallel)")
+
+	wc.failureModes = loadFailureModes(wc.repoPath)
+	if wc.failureModes != "" {
+		fmt.Fprintln(a.output(), "   📉 Warning agents about historical failure modes")
+	}
+	wc.errorContext = a.errorContext(ctx, wc)
+	wc.specs = a.linkedSpecs(ctx, wc)
+	wc.media = a.ticketMedia(ctx, wc)
+	locations := a.stackLocations(ctx, wc)
 
 	var wg sync.WaitGroup
 
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
-		planAgent := planner.New(wc.worktree.Path, a.config)
+		// Sparse worktrees lack most files, so plan against the full repo
+		planDir := wc.worktree.Path
+		if wc.worktree.Sparse {
+			planDir = wc.repoPath
+		}
+		planAgent := planner.New(planDir, a.config)
+		planAgent.SetOutput(a.out)
+		planAgent.SetFailureModes(wc.failureModes)
+		planAgent.SetStackLocations(locations)
+		planAgent.SetSpecs(wc.specs)
+		planAgent.SetMedia(wc.media)
 		plan, usage, err := planAgent.Analyze(ctx, wc.task)
 		if err != nil {
-			fmt.Printf("   ⚠️  Planning failed: %v (continuing without plan)\n", err)
+			fmt.Fprintf(a.output(), "   ⚠️  Planning failed: %v (continuing without plan)\n", err)
 			events.AgentCompleted(agentID, "Planning & Analysis", "failed")
 			return
 		}
@@ -242,21 +709,54 @@ func (a *Agent) stepPlanning(ctx context.Context, wc *workContext) error {
 	}()
 
 	wg.Wait()
-	fmt.Println()
+
+	a.applySparsePlan(wc)
+	fmt.Fprintln(a.output())
 
 	return nil
 }
 
+// applySparsePlan checks out the plan's files and directories in a sparse
+// worktree. Falls back to a full checkout when the plan names no paths.
+func (a *Agent) applySparsePlan(wc *workContext) {
+	if !wc.worktree.Sparse {
+		return
+	}
+
+	var paths []string
+	if wc.plan != nil {
+		paths = append(paths, wc.plan.RelevantFiles...)
+		paths = append(paths, wc.plan.RelevantDirs...)
+	}
+
+	reason := "plan named no relevant paths"
+	if len(paths) > 0 {
+		err := wc.worktree.ExpandSparse(paths...)
+		if err == nil {
+			fmt.Fprintf(a.output(), "   🪶 Sparse checkout: added %d plan path(s)\n", len(paths))
+			return
+		}
+		reason = fmt.Sprintf("adding plan paths failed: %v", err)
+	}
+
+	if err := wc.worktree.DisableSparse(); err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Failed to disable sparse checkout: %v\n", err)
+		return
+	}
+	fmt.Fprintln(a.output(), "   🪶 Sparse checkout disabled (full checkout)")
+	wc.decisions.Record("worktree", "switched sparse worktree to full checkout", reason)
+}
+
 // stepPreflightValidation validates the plan before execution (Step 4).
 func (a *Agent) stepPreflightValidation(ctx context.Context, wc *workContext) error {
 	agentID := fmt.Sprintf("preflight-%s", wc.task.GetID())
 	events.AgentStarted(agentID, "Pre-flight Validation", "Validating implementation plan")
 
-	printStep(4, 9, "Pre-flight validation")
+	a.printStep(4, 9, "Pre-flight validation")
 
 	if wc.plan == nil {
-		fmt.Println("   ⏭️  Skipping (no plan)")
-		fmt.Println()
+		fmt.Fprintln(a.output(), "   ⏭️  Skipping (no plan)")
+		fmt.Fprintln(a.output())
 		events.AgentCompleted(agentID, "Pre-flight Validation", "success")
 		return nil
 	}
@@ -265,43 +765,82 @@ func (a *Agent) stepPreflightValidation(ctx context.Context, wc *workContext) er
 	preflightAgent.SetCoordinator(a.coordinator)
 	validation, err := preflightAgent.Validate(ctx, wc.plan)
 	if err != nil {
-		fmt.Printf("   ⚠️  Validation error: %v\n", err)
+		fmt.Fprintf(a.output(), "   ⚠️  Validation error: %v\n", err)
 		events.AgentCompleted(agentID, "Pre-flight Validation", "failed")
 	} else {
-		fmt.Printf("   %s\n", (&preflight.ValidationHandoff{Result: validation}).Concise())
+		fmt.Fprintf(a.output(), "   %s\n", (&preflight.ValidationHandoff{Result: validation}).Concise())
 		if !validation.Valid {
-			fmt.Println("   ⚠️  Validation failed but continuing...")
+			fmt.Fprintln(a.output(), "   ⚠️  Validation failed but continuing...")
 			for _, e := range validation.Errors {
-				fmt.Printf("      ❌ %s\n", e.Message)
+				fmt.Fprintf(a.output(), "      ❌ %s\n", e.Message)
 			}
 		}
 		for _, w := range validation.Warnings {
-			fmt.Printf("      ⚠️  %s\n", w.Message)
+			fmt.Fprintf(a.output(), "      ⚠️  %s\n", w.Message)
 		}
 		events.AgentCompleted(agentID, "Pre-flight Validation", "success")
 	}
 
+	if err := a.checkSuccessLikelihood(wc); err != nil {
+		return err
+	}
+
 	// Pin files from the plan for context consistency
 	if len(wc.plan.RelevantFiles) > 0 {
-		fmt.Println("   📌 Pinning context for relevant files...")
+		fmt.Fprintln(a.output(), "   📌 Pinning context for relevant files...")
 		wc.pinner.AnalyzeFiles(wc.plan.RelevantFiles)
 		if _, err := wc.pinner.Pin("executor", wc.plan.RelevantFiles, false); err != nil {
-			fmt.Printf("   ⚠️  Could not pin files: %v\n", err)
+			fmt.Fprintf(a.output(), "   ⚠️  Could not pin files: %v\n", err)
 		}
 	}
 
-	fmt.Println()
+	fmt.Fprintln(a.output())
 	return nil
 }
 
+// checkSuccessLikelihood declines tasks unlikely to succeed without human
+// help, judged from the plan and this repo's history, unless forced.
+func (a *Agent) checkSuccessLikelihood(wc *workContext) error {
+	minLikelihood := a.config.MinSuccessLikelihood
+	if minLikelihood <= 0 {
+		return nil
+	}
+
+	var mem *memory.Memory
+	if store, err := memory.NewStore(""); err == nil {
+		mem, _ = store.Get(wc.repoPath)
+	}
+	e := estimate.New(wc.task.GetID(), wc.task.GetTitle(), wc.plan, wc.costTracker.Total().TotalCostUSD, mem, a.config.MaxIterations)
+	e.AssessSuccess(wc.repoPath, a.config.Commands.Test)
+	fmt.Fprintf(a.output(), "   🎲 Success likelihood: %d%%\n", e.SuccessLikelihood)
+	if e.SuccessLikelihood >= minLikelihood {
+		return nil
+	}
+
+	reason := fmt.Sprintf("%d%% likelihood of success is below min_success_likelihood (%d%%)", e.SuccessLikelihood, minLikelihood)
+	if risks := e.RiskSummary(); risks != "" {
+		reason += ": " + risks
+	}
+	if a.force {
+		fmt.Fprintf(a.output(), "   ⚠️  %s (continuing: --force)\n", reason)
+		wc.decisions.Record("validate", "run despite low success likelihood", reason)
+		return nil
+	}
+	wc.decisions.Record("validate", "decline task", reason)
+	wc.declined = true
+	return fmt.Errorf("declined: %s (use --force to run anyway)", reason)
+}
+
 // stepExecute runs the executor to implement the task (Step 5).
 func (a *Agent) stepExecute(ctx context.Context, wc *workContext) error {
 	agentID := fmt.Sprintf("execute-%s", wc.task.GetID())
 	events.AgentStarted(agentID, "Execution", "Implementing code changes")
 
-	printStep(5, 9, "Executing development task")
+	a.printStep(5, 9, "Executing development task")
 
-	wc.exec = executor.New(wc.worktree.Path, a.config)
+	a.setupExecutor(wc)
+	a.setupBundle(ctx, wc)
+	a.setupCoverage(ctx, wc)
 	result, usage, err := wc.exec.ExecuteWithPlan(ctx, wc.task, wc.plan)
 	if err != nil {
 		events.AgentCompleted(agentID, "Execution", "failed")
@@ -318,14 +857,25 @@ func (a *Agent) stepExecute(ctx context.Context, wc *workContext) error {
 	}
 
 	wc.execResult = result
-	fmt.Println()
+	wc.addSnapshotReasons(result.SnapshotReasons)
+	a.followRenames(wc)
+	a.writeE2ESpecs(ctx, wc)
+	fmt.Fprintln(a.output())
 
 	// Stage changes
-	fmt.Println("   📥 Staging changes...")
+	fmt.Fprintln(a.output(), "   📥 Staging changes...")
 	if err := wc.exec.StageChanges(); err != nil {
 		events.AgentCompleted(agentID, "Execution", "failed")
 		return fmt.Errorf("failed to stage changes: %w", err)
 	}
+	a.checkLFSFiles(wc)
+	a.checkSubmoduleChanges(wc)
+
+	if err := a.pairPause(wc, "execute"); err != nil {
+		events.AgentCompleted(agentID, "Execution", "failed")
+		return err
+	}
+	a.commitWIP(wc, "implementation")
 
 	// Get diff for metadata
 	diff, _ := wc.exec.GetDiff()
@@ -335,112 +885,305 @@ func (a *Agent) stepExecute(ctx context.Context, wc *workContext) error {
 	return nil
 }
 
-// stepTestAndReview runs tests and initial review in parallel (Step 6).
-func (a *Agent) stepTestAndReview(ctx context.Context, wc *workContext) error {
-	testAgentID := fmt.Sprintf("test-%s", wc.task.GetID())
-	reviewAgentID := fmt.Sprintf("review-1-%s", wc.task.GetID())
+// setupExecutor creates the run's executor with the guidance and policies
+// the repo configures.
+func (a *Agent) setupExecutor(wc *workContext) {
+	wc.exec = executor.New(wc.worktree.Path, a.config)
+	wc.exec.SetOutput(a.out)
+	wc.exec.SetProxy(wc.proxyEnv())
+	wc.exec.SetSparseCheckout(wc.worktree.Sparse)
+	wc.exec.SetFailureModes(wc.failureModes)
+	wc.exec.SetErrorContext(wc.errorContext)
+	wc.exec.SetSpecs(wc.specs)
+	wc.exec.SetMedia(wc.media)
+	a.setupFeatureFlags(wc)
+	a.setupObservability(wc)
+	a.setupSnapshots(wc)
+	if a.config.Git.WIPCommits {
+		wc.exec.SetBaseCommit(wc.baseCommit)
+	}
+}
 
-	printStep(6, 9, "Running tests & initial review (parallel)")
+// followRenames moves the dependency graph and pins of files the executor
+// moved to their new paths, then re-reads the moved files' imports.
+func (a *Agent) followRenames(wc *workContext) {
+	if len(wc.execResult.Renames) == 0 {
+		return
+	}
+	var moved []string
+	for to, from := range wc.execResult.Renames {
+		wc.pinner.Rename(from, to)
+		moved = append(moved, to)
+	}
+	wc.pinner.AnalyzeFiles(moved)
+	fmt.Fprintf(a.output(), "   🚚 %d file(s) moved; tests and dependencies follow them\n", len(moved))
+}
 
-	// Get diff for review
-	initialDiff, err := wc.exec.GetDiff()
+// checkLFSFiles warns when changed files match Git LFS patterns but LFS
+// isn't set up, since they would be committed as regular blobs.
+func (a *Agent) checkLFSFiles(wc *workContext) {
+	if len(wc.worktree.LFSPatterns) == 0 || wc.worktree.LFSReady {
+		return
+	}
+
+	entries, err := wc.exec.Git().Status()
 	if err != nil {
-		return fmt.Errorf("failed to get diff: %w", err)
+		return
+	}
+	paths := make([]string, 0, len(entries))
+	for _, e := range entries {
+		paths = append(paths, e.Path)
 	}
 
-	var wg sync.WaitGroup
-	wg.Add(2)
+	tracked, err := wc.exec.Git().LFSTracked(paths...)
+	if err != nil || len(tracked) == 0 {
+		return
+	}
 
-	// Run tests in parallel
-	go func() {
-		defer wg.Done()
-		events.AgentStarted(testAgentID, "Running Tests", "Running unit tests for changed files")
-		testAgent := testrunner.New(wc.worktree.Path)
-		testAgent.SetCoordinator(a.coordinator)
-		wc.testResult, _ = testAgent.RunForFiles(ctx, wc.execResult.FilesChanged)
-		if wc.testResult != nil && wc.testResult.Passed {
-			events.AgentCompleted(testAgentID, "Running Tests", "success")
-		} else {
-			events.AgentCompleted(testAgentID, "Running Tests", "failed")
-		}
-	}()
+	fmt.Fprintf(a.output(), "   ⚠️  %d changed file(s) match Git LFS patterns but git-lfs is not configured:\n", len(tracked))
+	for _, path := range tracked {
+		fmt.Fprintf(a.output(), "      • %s\n", path)
+	}
+	wc.decisions.Record("execute", "commit LFS-pattern files without LFS",
+		fmt.Sprintf("git-lfs not configured; %s will be stored as regular blobs", strings.Join(tracked, ", ")))
+}
 
-	// Run initial review in parallel
-	go func() {
-		defer wg.Done()
-		events.AgentStarted(reviewAgentID, "Code Review #1", "Reviewing code quality and best practices")
-		reviewHandoff := handoff.NewReviewHandoff(wc.task, initialDiff, wc.execResult.FilesChanged)
-		reviewer := scottbott.NewWithSkill(wc.worktree.Path, 1, a.config.ReviewSkill, a.config)
-		reviewResult, usage, _ := reviewer.Review(ctx, reviewHandoff.Concise(), initialDiff)
-		wc.reviewResult = reviewResult
-		if usage != nil {
-			wc.costTracker.Add("Review #1", *usage)
+// checkProtectedPaths flags changes to protected paths so a human looks
+// at them; the PR description calls them out too.
+func (a *Agent) checkProtectedPaths(wc *workContext) {
+	if len(a.config.ProtectedPaths) == 0 {
+		return
+	}
+	entries, err := wc.exec.Git().Status()
+	if err != nil {
+		return
+	}
+	paths := make([]string, 0, len(entries))
+	for _, e := range entries {
+		paths = append(paths, e.Path)
+	}
+
+	wc.protected = a.config.MatchProtected(paths)
+	if len(wc.protected) == 0 {
+		return
+	}
+	fmt.Fprintf(a.output(), "   ⚠️  %d changed file(s) are in protected paths:\n", len(wc.protected))
+	for _, path := range wc.protected {
+		fmt.Fprintf(a.output(), "      • %s\n", path)
+	}
+	wc.decisions.Record("commit", "commit changes to protected paths",
+		fmt.Sprintf("%s match protected_paths; flagged in the PR for human review", strings.Join(wc.protected, ", ")))
+}
+
+// protectedPathsSection lists protected files touched, for the PR body.
+func protectedPathsSection(wc *workContext) string {
+	if len(wc.protected) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("### ⚠️ Protected Paths\n")
+	sb.WriteString("This change touches paths marked as protected; please review them carefully:\n")
+	for _, path := range wc.protected {
+		sb.WriteString(fmt.Sprintf("- `%s`\n", path))
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// checkSubmoduleChanges warns about uncommitted edits inside submodules.
+// They appear in the diff but staging in the parent repo can't include them,
+// so they would be missing from the commit.
+func (a *Agent) checkSubmoduleChanges(wc *workContext) {
+	if !wc.worktree.Submodules {
+		return
+	}
+
+	git := wc.exec.Git()
+	submodules, err := git.SubmodulePaths()
+	if err != nil || len(submodules) == 0 {
+		return
+	}
+	entries, err := git.Status()
+	if err != nil {
+		return
+	}
+
+	var dirty []string
+	for _, e := range entries {
+		if len(e.Code) == 2 && e.Code[1] == 'M' && slices.Contains(submodules, e.Path) {
+			dirty = append(dirty, e.Path)
 		}
-		if reviewResult != nil && reviewResult.Passed {
-			feedback := reviewResult.Summary
-			if feedback == "" && len(reviewResult.Issues) > 0 {
-				feedback = fmt.Sprintf("Found %d issues", len(reviewResult.Issues))
-			}
-			events.AgentCompletedWithData(reviewAgentID, "Code Review #1", "success", map[string]any{
-				"feedback": feedback,
-				"issues":   reviewResult.Issues,
-			})
-		} else {
-			feedback := ""
-			if reviewResult != nil {
-				feedback = reviewResult.Summary
+	}
+	if len(dirty) == 0 {
+		return
+	}
+
+	fmt.Fprintf(a.output(), "   ⚠️  Uncommitted changes inside submodule(s): %s\n", strings.Join(dirty, ", "))
+	wc.decisions.Record("execute", "leave submodule edits uncommitted",
+		fmt.Sprintf("changes inside %s must be committed in the submodule repository", strings.Join(dirty, ", ")))
+}
+
+// stepTestAndReview runs tests and then the initial review (Step 6), so
+// the review's verdict accounts for failing tests.
+func (a *Agent) stepTestAndReview(ctx context.Context, wc *workContext) error {
+	a.printStep(6, 9, "Running tests & initial review")
+
+	// Get diff for review
+	initialDiff, err := wc.exec.GetDiff()
+	if err != nil {
+		return fmt.Errorf("failed to get diff: %w", err)
+	}
+
+	if a.stages.Has(pipeline.Test) {
+		a.runInitialTests(ctx, wc, initialDiff)
+	}
+	if a.stages.Has(pipeline.Review) {
+		if wc.testFixes > 0 {
+			// Review the code the tests were fixed in
+			if diff, err := wc.exec.GetDiff(); err == nil {
+				initialDiff = diff
 			}
-			events.AgentCompletedWithData(reviewAgentID, "Code Review #1", "failed", map[string]any{
-				"feedback": feedback,
-			})
 		}
-	}()
+		a.runInitialReview(ctx, wc, initialDiff)
+	}
+	if wc.reviewResult != nil {
+		wc.reviews = append(wc.reviews, wc.reviewResult)
+	}
 
-	wg.Wait()
+	// Remember what the initial review saw for differential follow-ups
+	wc.reviewedTree, _ = wc.exec.SnapshotTree()
+	wc.testedTree = wc.reviewedTree
+	a.tagIteration(wc, 1)
 
 	// Display test results
 	if wc.testResult != nil {
-		fmt.Printf("   🧪 Tests: %s\n", (&testrunner.TestResultHandoff{Result: wc.testResult}).Concise())
+		fmt.Fprintf(a.output(), "   🧪 Tests: %s\n", (&testrunner.TestResultHandoff{Result: wc.testResult}).Concise())
 	}
 
 	// Display review results
 	if wc.reviewResult != nil {
-		fmt.Println(wc.reviewResult.FormatReview())
+		fmt.Fprintln(a.output(), wc.reviewResult.FormatReview())
 	}
-	fmt.Println()
+	fmt.Fprintln(a.output())
 
-	return nil
+	return a.enforceCoverage(ctx, wc)
+}
+
+// runInitialTests runs the tests for the changed files, fixing failures
+// if test_fix is enabled.
+func (a *Agent) runInitialTests(ctx context.Context, wc *workContext, diff string) {
+	testAgentID := fmt.Sprintf("test-%s", wc.task.GetID())
+	events.AgentStarted(testAgentID, "Running Tests", "Running unit tests for changed files")
+	testAgent := testrunner.New(wc.worktree.Path)
+	testAgent.SetCoordinator(a.coordinator)
+	testAgent.SetCommand(a.config.Commands.Test)
+	testAgent.SetRenames(wc.execResult.Renames)
+	testAgent.SetDiff(diff)
+	wc.testResult, _ = testAgent.RunForFiles(ctx, wc.execResult.FilesChanged)
+	emitTestResults(wc.testResult)
+	a.fixFailingTests(ctx, wc, testAgent)
+	if wc.testResult != nil && wc.testResult.Passed {
+		events.AgentCompleted(testAgentID, "Running Tests", "success")
+	} else {
+		events.AgentCompleted(testAgentID, "Running Tests", "failed")
+	}
+}
+
+// runInitialReview runs the first review of diff, with the test results.
+func (a *Agent) runInitialReview(ctx context.Context, wc *workContext, diff string) {
+	reviewAgentID := fmt.Sprintf("review-1-%s", wc.task.GetID())
+	reviewHandoff := handoff.NewReviewHandoff(wc.task, diff, wc.execResult.FilesChanged)
+	reviewHandoff.Renames = wc.execResult.Renames
+	reviewHandoff.HumanEdited = wc.humanEdited
+	events.AgentStarted(reviewAgentID, "Code Review #1", "Reviewing code quality and best practices")
+	if wc.testResult != nil {
+		reviewHandoff.TestResults = &testrunner.TestResultHandoff{Result: wc.testResult}
+	}
+	reviewer := scottbott.NewWithSkill(wc.worktree.Path, 1, a.config.ReviewSkill, a.config)
+	reviewer.SetOutput(a.out)
+	reviewer.SetDecisionLog(wc.decisions)
+	reviewer.SetCriteria(a.checklist(wc))
+	reviewResult, usage, _ := reviewer.Review(ctx, reviewHandoff.Concise(), diff)
+	if reviewResult != nil {
+		a.applyPolicies(ctx, wc, reviewResult, diff)
+		a.applyAcceptance(wc, reviewResult)
+	}
+	emitReviewVerdict(1, reviewResult)
+	wc.reviewResult = reviewResult
+	if usage != nil {
+		wc.costTracker.Add("Review #1", *usage)
+	}
+	if reviewResult != nil && reviewResult.Passed {
+		feedback := reviewResult.Summary
+		if feedback == "" && len(reviewResult.Issues) > 0 {
+			feedback = fmt.Sprintf("Found %d issues", len(reviewResult.Issues))
+		}
+		events.AgentCompletedWithData(reviewAgentID, "Code Review #1", "success", map[string]any{
+			"feedback": feedback,
+			"issues":   reviewResult.Issues,
+		})
+	} else {
+		feedback := ""
+		if reviewResult != nil {
+			feedback = reviewResult.Summary
+		}
+		events.AgentCompletedWithData(reviewAgentID, "Code Review #1", "failed", map[string]any{
+			"feedback": feedback,
+		})
+	}
 }
 
 // stepRefactorLoop runs the review/refactor loop until passing or max iterations (Step 7).
 func (a *Agent) stepRefactorLoop(ctx context.Context, wc *workContext) error {
-	printStep(7, 9, "Review & refactor loop")
+	a.printStep(7, 9, "Review & refactor loop")
 
 	previousDiff, _ := wc.exec.GetDiff()
 
 	for wc.iterations < a.config.MaxIterations {
 		wc.iterations++
-		fmt.Printf("\n   🔄 Iteration %d of %d\n", wc.iterations, a.config.MaxIterations)
-		fmt.Println("   ─────────────────────────────")
+		wc.checkpoint.SetIteration(wc.iterations)
+		wc.checkpoint.SetCost(wc.costTracker.Total().TotalCostUSD)
+		fmt.Fprintf(a.output(), "\n   🔄 Iteration %d of %d\n", wc.iterations, a.config.MaxIterations)
+		fmt.Fprintln(a.output(), "   ─────────────────────────────")
 
 		events.Progress(fmt.Sprintf("Review & refactor iteration %d of %d", wc.iterations, a.config.MaxIterations))
 
 		// Use existing review for first iteration, get fresh review for subsequent
 		if wc.iterations > 1 || wc.reviewResult == nil {
+			if err := a.checkBudget(wc, fmt.Sprintf("review #%d", wc.iterations)); err != nil {
+				return err
+			}
 			if err := a.doReview(ctx, wc, &previousDiff); err != nil {
 				return err
 			}
 		}
 
+		// No reviewer could reach a verdict - refactoring won't help
+		if wc.reviewResult.Inconclusive {
+			fmt.Fprintln(a.output(), "   ❔ Review inconclusive: human approval required")
+			break
+		}
+
+		if wc.reviewResult.Passed && a.needsConfirmationReview(wc) {
+			continue
+		}
+
 		if wc.reviewResult.Passed {
-			fmt.Println("   ✅ Review passed!")
+			fmt.Fprintln(a.output(), "   ✅ Review passed!")
 
 			// Run final tests to confirm
 			if wc.testResult == nil || !wc.testResult.Passed {
 				testAgent := testrunner.New(wc.worktree.Path)
+				testAgent.SetCommand(a.config.Commands.Test)
+				testAgent.SetRenames(wc.execResult.Renames)
+				if diff, err := wc.exec.GetDiff(); err == nil {
+					testAgent.SetDiff(diff)
+				}
 				wc.testResult, _ = testAgent.RunForFiles(ctx, wc.execResult.FilesChanged)
+				emitTestResults(wc.testResult)
+				wc.testedTree, _ = wc.exec.SnapshotTree()
 				if wc.testResult != nil && !wc.testResult.Passed {
-					fmt.Printf("   ⚠️  Tests failed: %s\n", (&testrunner.TestResultHandoff{Result: wc.testResult}).Concise())
+					fmt.Fprintf(a.output(), "   ⚠️  Tests failed: %s\n", (&testrunner.TestResultHandoff{Result: wc.testResult}).Concise())
 					wc.reviewResult.Passed = false
 					wc.reviewResult.Issues = append(wc.reviewResult.Issues, scottbott.Issue{
 						Severity:    "major",
@@ -455,11 +1198,14 @@ func (a *Agent) stepRefactorLoop(ctx context.Context, wc *workContext) error {
 		}
 
 		if wc.iterations >= a.config.MaxIterations {
-			fmt.Println("   ⚠️  Maximum iterations reached without passing review")
+			fmt.Fprintln(a.output(), "   ⚠️  Maximum iterations reached without passing review")
 			break
 		}
 
 		// Refactor based on feedback
+		if err := a.checkBudget(wc, fmt.Sprintf("refactor #%d", wc.iterations)); err != nil {
+			return err
+		}
 		if err := a.doRefactor(ctx, wc, previousDiff); err != nil {
 			return err
 		}
@@ -468,17 +1214,69 @@ func (a *Agent) stepRefactorLoop(ctx context.Context, wc *workContext) error {
 	return nil
 }
 
+// needsConfirmationReview checks whether a passing review should be confirmed
+// by another review because the last diff verification fell below the
+// configured confidence threshold. Each verification gates at most once.
+func (a *Agent) needsConfirmationReview(wc *workContext) bool {
+	v := wc.verification
+	wc.verification = nil
+	if v == nil || v.AllAddressed {
+		return false
+	}
+	minConfidence := a.config.Review.MinVerificationConfidence
+	if v.MeetsThreshold(minConfidence) || wc.iterations >= a.config.MaxIterations {
+		return false
+	}
+	fmt.Fprintf(a.output(), "   🔁 Verification confidence %d%% below %d%% with %d unaddressed issue(s), forcing another review\n",
+		v.Confidence, minConfidence, len(v.UnaddressedIssues))
+	return true
+}
+
 // doReview gets a fresh diff and runs the review.
+// In differential mode, the reviewer sees only changes since its last review
+// along with its previous issue list.
 func (a *Agent) doReview(ctx context.Context, wc *workContext, previousDiff *string) error {
 	diff, err := wc.exec.GetDiff()
 	if err != nil {
 		return fmt.Errorf("failed to get diff: %w", err)
 	}
-	fmt.Printf("   📏 Diff size: %d lines\n", strings.Count(diff, "\n"))
+	fmt.Fprintf(a.output(), "   📏 Diff size: %d lines\n", strings.Count(diff, "\n"))
+
+	reviewDiff := diff
+	var previousIssues []string
+	if a.config.Review.Differential && wc.reviewedTree != "" && wc.reviewResult != nil {
+		interDiff, err := wc.exec.DiffSinceTree(wc.reviewedTree)
+		if err == nil && strings.TrimSpace(interDiff) != "" {
+			reviewDiff = interDiff
+			for _, issue := range wc.reviewResult.Issues {
+				previousIssues = append(previousIssues, formatIssue(issue))
+			}
+			fmt.Fprintf(a.output(), "   🔀 Differential review: %d lines since last review\n", strings.Count(interDiff, "\n"))
+		}
+	}
 
-	reviewHandoff := handoff.NewReviewHandoff(wc.task, diff, wc.execResult.FilesChanged)
+	reviewHandoff := handoff.NewReviewHandoff(wc.task, reviewDiff, wc.execResult.FilesChanged)
+	reviewHandoff.Renames = wc.execResult.Renames
+	if reviewDiff != diff {
+		reviewHandoff.Differential = true
+		reviewHandoff.PreviousIssues = previousIssues
+	}
+	if len(wc.focusIssues) > 0 {
+		fmt.Fprintf(a.output(), "   🎯 Focusing review on %d unaddressed issue(s)\n", len(wc.focusIssues))
+		reviewHandoff.FocusIssues = wc.focusIssues
+		reviewHandoff.ResolvedIssues = wc.resolved
+	}
+	wc.focusIssues, wc.resolved = nil, nil
+	reviewHandoff.HumanEdited = wc.humanEdited
+	// Only share test results that match the code under review
+	if tree, _ := wc.exec.SnapshotTree(); wc.testResult != nil && tree != "" && tree == wc.testedTree {
+		reviewHandoff.TestResults = &testrunner.TestResultHandoff{Result: wc.testResult}
+	}
 	reviewer := scottbott.NewWithSkill(wc.worktree.Path, wc.iterations, a.config.ReviewSkill, a.config)
-	reviewResult, usage, err := reviewer.Review(ctx, reviewHandoff.ForTokenBudget(handoff.DefaultBudget.Context), diff)
+	reviewer.SetOutput(a.out)
+	reviewer.SetDecisionLog(wc.decisions)
+	reviewer.SetCriteria(a.checklist(wc))
+	reviewResult, usage, err := reviewer.Review(ctx, reviewHandoff.ForTokenBudget(handoff.DefaultBudget.Context), reviewDiff)
 	if err != nil {
 		return fmt.Errorf("review failed: %w", err)
 	}
@@ -487,8 +1285,16 @@ func (a *Agent) doReview(ctx context.Context, wc *workContext, previousDiff *str
 		wc.costTracker.Add(fmt.Sprintf("Review #%d", wc.iterations), *usage)
 	}
 
-	fmt.Println(reviewResult.FormatReview())
+	a.mergeDetectedIssues(wc, reviewResult)
+	a.applyPolicies(ctx, wc, reviewResult, diff)
+	a.applyAcceptance(wc, reviewResult)
+	emitReviewVerdict(wc.iterations, reviewResult)
+
+	fmt.Fprintln(a.output(), reviewResult.FormatReview())
 	wc.reviewResult = reviewResult
+	wc.reviews = append(wc.reviews, reviewResult)
+	wc.reviewedTree, _ = wc.exec.SnapshotTree()
+	a.tagIteration(wc, wc.iterations)
 	*previousDiff = diff
 
 	return nil
@@ -499,9 +1305,28 @@ func (a *Agent) doRefactor(ctx context.Context, wc *workContext, previousDiff st
 	refactorAgentID := fmt.Sprintf("refactor-%d-%s", wc.iterations, wc.task.GetID())
 	events.AgentStarted(refactorAgentID, fmt.Sprintf("Refactoring #%d", wc.iterations), "Applying code review feedback")
 
-	fmt.Printf("   🔧 Refactoring (attempt %d)...\n", wc.iterations)
+	fmt.Fprintf(a.output(), "   🔧 Refactoring (attempt %d)...\n", wc.iterations)
+
+	// Make sure files named in review issues are checked out
+	if wc.worktree.Sparse {
+		var issueFiles []string
+		for _, issue := range wc.reviewResult.Issues {
+			if issue.File != "" {
+				issueFiles = append(issueFiles, issue.File)
+			}
+		}
+		if err := wc.worktree.ExpandSparse(issueFiles...); err != nil {
+			fmt.Fprintf(a.output(), "   ⚠️  Failed to expand sparse checkout: %v\n", err)
+		}
+	}
 
 	refactorExec := executor.NewRefactorExecutor(wc.worktree.Path, wc.iterations, a.config)
+	refactorExec.SetOutput(a.out)
+	refactorExec.SetProxy(wc.proxyEnv())
+	refactorExec.SetSparseCheckout(wc.worktree.Sparse)
+	if wc.snapshots != nil {
+		refactorExec.SetSnapshotPolicy(wc.snapshots.Guidance())
+	}
 	currentCode, _ := refactorExec.GetSpecificFiles(wc.execResult.FilesChanged)
 
 	// Load project rules for proper refactoring
@@ -530,17 +1355,39 @@ func (a *Agent) doRefactor(ctx context.Context, wc *workContext, previousDiff st
 	if len(wc.reviewResult.Issues) > 0 {
 		newDiff, _ := wc.exec.GetDiff()
 		verifier := diffverify.New(wc.worktree.Path)
+		verifier.SetOutput(a.out)
 		verifier.SetCoordinator(a.coordinator)
+		verifier.SetMinConfidence(a.config.Review.MinVerificationConfidence)
+		verifier.SetModel(a.config.Claude.Models.Verifier)
+		if a.config.Verify.UseLLM {
+			verifier.EnableLLM(a.config.Claude)
+		}
+		if patterns := a.config.Review.NewIssuePatterns; len(patterns) > 0 {
+			if err := verifier.SetPatterns(toDiffverifyPatterns(patterns)); err != nil {
+				fmt.Fprintf(a.output(), "   ⚠️  %v (using default patterns)\n", err)
+			}
+		}
 		verification, _ := verifier.Verify(ctx, wc.reviewResult.Issues, previousDiff, newDiff)
+		if verification != nil && verification.Usage != nil {
+			wc.costTracker.Add(fmt.Sprintf("Verification #%d", wc.iterations), *verification.Usage)
+		}
 		if verification != nil {
-			fmt.Printf("   🔍 Verification: %s\n", (&diffverify.VerificationHandoff{Result: verification}).Concise())
+			fmt.Fprintf(a.output(), "   🔍 Verification: %s\n", (&diffverify.VerificationHandoff{Result: verification}).Concise())
 			if len(verification.UnaddressedIssues) > 0 {
-				fmt.Printf("   ⚠️  %d issues may not be addressed\n", len(verification.UnaddressedIssues))
+				fmt.Fprintf(a.output(), "   ⚠️  %d issues may not be addressed\n", len(verification.UnaddressedIssues))
 			}
+			if len(verification.NewIssues) > 0 {
+				fmt.Fprintf(a.output(), "   🚩 %d new issue(s) introduced, adding to next review\n", len(verification.NewIssues))
+				wc.newIssues = verification.NewIssues
+			}
+			wc.verification = verification
+			wc.focusIssues, wc.resolved = verificationFocus(verification)
+			wc.fixed = append(wc.fixed, wc.resolved...)
 		}
 	}
 
 	wc.execResult.FilesChanged = refactorResult.FilesChanged
+	wc.addSnapshotReasons(refactorResult.SnapshotReasons)
 
 	if !refactorResult.Success {
 		events.AgentCompleted(refactorAgentID, fmt.Sprintf("Refactoring #%d", wc.iterations), "failed")
@@ -548,12 +1395,18 @@ func (a *Agent) doRefactor(ctx context.Context, wc *workContext, previousDiff st
 	}
 
 	// Stage new changes
-	fmt.Println("   📥 Staging refactored changes...")
+	fmt.Fprintln(a.output(), "   📥 Staging refactored changes...")
 	if err := wc.exec.StageChanges(); err != nil {
 		events.AgentCompleted(refactorAgentID, fmt.Sprintf("Refactoring #%d", wc.iterations), "failed")
 		return fmt.Errorf("failed to stage changes: %w", err)
 	}
 
+	if err := a.pairPause(wc, "refactor"); err != nil {
+		events.AgentCompleted(refactorAgentID, fmt.Sprintf("Refactoring #%d", wc.iterations), "failed")
+		return err
+	}
+	a.commitWIP(wc, fmt.Sprintf("refactor %d", wc.iterations))
+
 	// Get refactored diff for metadata
 	refactorDiff, _ := wc.exec.GetDiff()
 	events.AgentCompletedWithData(refactorAgentID, fmt.Sprintf("Refactoring #%d", wc.iterations), "success", map[string]any{
@@ -562,123 +1415,225 @@ func (a *Agent) doRefactor(ctx context.Context, wc *workContext, previousDiff st
 	return nil
 }
 
-// stepCommitAndPush commits and pushes changes (Step 8).
-func (a *Agent) stepCommitAndPush(ctx context.Context, wc *workContext) error {
+// mergeDetectedIssues appends issues found by diff verification to a fresh review.
+// Critical and major pattern hits fail the review so they get fixed before PR.
+func (a *Agent) mergeDetectedIssues(wc *workContext, result *scottbott.ReviewResult) {
+	if len(wc.newIssues) == 0 {
+		return
+	}
+	for _, issue := range wc.newIssues {
+		result.Issues = append(result.Issues, issue)
+		if issue.Severity == "critical" || issue.Severity == "major" {
+			result.Passed = false
+		}
+	}
+	wc.newIssues = nil
+}
+
+// verificationFocus splits verified issues into those the next review should
+// focus on and those already fixed. Returns nil slices unless at least one
+// issue was verified as fixed, since otherwise there is nothing to narrow.
+func verificationFocus(v *diffverify.VerificationResult) ([]string, []string) {
+	if len(v.AddressedIssues) == 0 || len(v.UnaddressedIssues) == 0 {
+		return nil, nil
+	}
+	focus := make([]string, len(v.UnaddressedIssues))
+	for i, u := range v.UnaddressedIssues {
+		focus[i] = formatIssue(u.Original)
+	}
+	resolved := make([]string, len(v.AddressedIssues))
+	for i, ad := range v.AddressedIssues {
+		resolved[i] = formatIssue(ad.Original)
+	}
+	return focus, resolved
+}
+
+// formatIssue renders an issue as a single line for prompts.
+func formatIssue(issue scottbott.Issue) string {
+	line := fmt.Sprintf("[%s] %s", issue.Severity, issue.Description)
+	if issue.File != "" {
+		location := issue.File
+		if issue.Line > 0 {
+			location = fmt.Sprintf("%s:%d", issue.File, issue.Line)
+		}
+		line += fmt.Sprintf(" (%s)", location)
+	}
+	return line
+}
+
+// toDiffverifyPatterns converts configured issue patterns for the verifier.
+func toDiffverifyPatterns(patterns []config.IssuePattern) []diffverify.Pattern {
+	result := make([]diffverify.Pattern, len(patterns))
+	for i, p := range patterns {
+		result[i] = diffverify.Pattern{
+			Pattern:  p.Pattern,
+			Severity: p.Severity,
+			Message:  p.Message,
+		}
+	}
+	return result
+}
+
+// stepCommit commits the change (Step 8).
+func (a *Agent) stepCommit(ctx context.Context, wc *workContext) error {
 	agentID := fmt.Sprintf("commit-%s", wc.task.GetID())
-	events.AgentStarted(agentID, "Commit & Push", "Committing and pushing changes to remote")
+	events.AgentStarted(agentID, "Commit", "Committing the change")
 
-	printStep(8, 9, "Committing and pushing")
+	a.printStep(8, 9, "Committing and pushing")
 
-	commitMsg := fmt.Sprintf("feat(%s): %s\n\n%s",
-		wc.task.GetID(),
-		wc.task.GetTitle(),
-		wc.reviewResult.Summary,
-	)
-	fmt.Println("   💾 Creating commit...")
-	fmt.Printf("   📝 Message: %s\n", strings.Split(commitMsg, "\n")[0])
+	commitMsg := commitMessage(wc)
+	wc.gerrit = a.useGerrit(wc)
+	if wc.gerrit {
+		commitMsg = gerrit.WithChangeID(commitMsg, changeID(wc))
+	}
+	fmt.Fprintln(a.output(), "   💾 Creating commit...")
+	fmt.Fprintf(a.output(), "   📝 Message: %s\n", strings.Split(commitMsg, "\n")[0])
+
+	// Capture the final diff before committing for the PR impact report
+	wc.finalDiff, _ = wc.exec.GetDiff()
+	a.checkProtectedPaths(wc)
+	if err := a.enforceSecurity(ctx, wc); err != nil {
+		events.AgentCompleted(agentID, "Commit", "failed")
+		return err
+	}
 
-	if err := wc.exec.Commit(commitMsg); err != nil {
-		events.AgentCompleted(agentID, "Commit & Push", "failed")
+	if err := a.curateHistory(ctx, wc, commitMsg); err != nil {
+		events.AgentCompleted(agentID, "Commit", "failed")
 		return fmt.Errorf("failed to commit: %w", err)
 	}
+	a.noteIterations(wc)
+	if wc.gerrit {
+		fmt.Fprintln(a.output())
+	}
+	events.AgentCompleted(agentID, "Commit", "success")
+	return nil
+}
 
-	fmt.Println("   📤 Pushing to origin...")
-	if err := wc.exec.Push(wc.branchName); err != nil {
-		events.AgentCompleted(agentID, "Commit & Push", "failed")
+// stepPush pushes the committed branch (Step 8).
+func (a *Agent) stepPush(ctx context.Context, wc *workContext) error {
+	agentID := fmt.Sprintf("push-%s", wc.task.GetID())
+	events.AgentStarted(agentID, "Push", "Pushing the branch to origin")
+
+	fmt.Fprintln(a.output(), "   📤 Pushing to origin...")
+	pushResult, err := wc.exec.Git().WithContext(ctx).SafePush(gitops.PushOptions{
+		Remote:     "origin",
+		Branch:     wc.branchName,
+		OnDiverged: a.config.Git.OnDiverged,
+		Retry: retry.Config{
+			MaxAttempts:  a.config.Retry.MaxAttempts,
+			InitialDelay: a.config.Retry.InitialDelay,
+			MaxDelay:     a.config.Retry.MaxDelay,
+			Multiplier:   2.0,
+			Jitter:       0.1,
+		},
+	})
+	if pushResult != nil && pushResult.Diverged {
+		decision := fmt.Sprintf("remote branch diverged; resolved with %s", pushResult.Action)
+		if err != nil {
+			decision = "remote branch diverged; push aborted"
+		}
+		wc.decisions.Record("push", decision, fmt.Sprintf("git.on_diverged is %q", a.config.Git.OnDiverged))
+	}
+	if err != nil {
+		events.AgentCompleted(agentID, "Push", "failed")
+		if errors.Is(err, gitops.ErrAuth) {
+			return fmt.Errorf("failed to push (check your git credentials for origin): %w", err)
+		}
 		return fmt.Errorf("failed to push: %w", err)
 	}
-	fmt.Println()
+	if pushResult.Diverged {
+		fmt.Fprintf(a.output(), "   🔀 Remote branch had diverged; pushed via %s\n", pushResult.Action)
+	}
+	fmt.Fprintln(a.output())
 
-	events.AgentCompleted(agentID, "Commit & Push", "success")
+	events.AgentCompleted(agentID, "Push", "success")
 	return nil
 }
 
+// commitMessage is the message of the run's final commit.
+func commitMessage(wc *workContext) string {
+	return fmt.Sprintf("feat(%s): %s\n\n%s",
+		wc.task.GetID(),
+		wc.task.GetTitle(),
+		wc.reviewResult.Summary,
+	)
+}
+
+// finishDryRun ends a dry run after review: it prints what would have
+// been committed and leaves the change in the worktree.
+func (a *Agent) finishDryRun(wc *workContext) *WorkResult {
+	wc.finalDiff, _ = wc.exec.GetDiff()
+	wc.decisions.Record("commit", "skip commit, push and PR", "dry run")
+
+	fmt.Fprintln(a.output())
+	fmt.Fprintln(a.output(), "═══════════════════════════════════════════════════════════════════════")
+	fmt.Fprintln(a.output(), "🏃 DRY RUN COMPLETE - nothing committed or pushed")
+	fmt.Fprintln(a.output(), "═══════════════════════════════════════════════════════════════════════")
+	fmt.Fprintf(a.output(), "   🎫 Task:       %s\n", wc.task.GetID())
+	fmt.Fprintf(a.output(), "   🌿 Branch:     %s\n", wc.branchName)
+	fmt.Fprintf(a.output(), "   📁 Worktree:   %s\n", wc.worktree.Path)
+	fmt.Fprintf(a.output(), "   🔄 Iterations: %d\n", wc.iterations)
+	fmt.Fprintf(a.output(), "   🧪 Tests:      %s\n", formatTestStatus(wc.testResult))
+	fmt.Fprintf(a.output(), "   📝 Would commit: %s\n", strings.Split(commitMessage(wc), "\n")[0])
+	for _, f := range unidiff.Parse(wc.finalDiff) {
+		fmt.Fprintf(a.output(), "      • %s (+%d -%d)\n", f.Path(), len(f.Added()), len(f.Removed()))
+	}
+	fmt.Fprintf(a.output(), "   🔗 Would open a PR against %s\n", a.config.BaseBranch)
+	if wc.costTracker.HasUsage() {
+		fmt.Fprint(a.output(), wc.costTracker.Summary())
+	}
+	fmt.Fprint(a.output(), wc.decisions.Format())
+	fmt.Fprintln(a.output(), "═══════════════════════════════════════════════════════════════════════")
+
+	return &WorkResult{
+		PRCreated:    false,
+		Message:      fmt.Sprintf("Dry run; changes left uncommitted in %s", wc.worktree.Path),
+		Iterations:   wc.iterations,
+		TestsPassed:  wc.testResult == nil || wc.testResult.Passed,
+		TestCoverage: getTestCoverage(wc.testResult),
+	}
+}
+
 // stepCreatePR creates a pull request (Step 9).
 func (a *Agent) stepCreatePR(ctx context.Context, wc *workContext) (*WorkResult, error) {
+	if a.useGerrit(wc) {
+		return a.submitToGerrit(ctx, wc)
+	}
+	if a.useAzure(wc) {
+		return a.createAzurePR(ctx, wc)
+	}
 	agentID := fmt.Sprintf("pr-%s", wc.task.GetID())
 	events.AgentStarted(agentID, "Create PR", "Creating pull request")
 
-	printStep(9, 9, "Creating pull request")
-
-	// Format PR body based on task source
-	metadata := wc.task.GetMetadata()
-	var prBody string
-
-	if metadata.Source == task.SourceLinear {
-		// Linear mode - include ticket link
-		prBody = fmt.Sprintf(`## %s
-
-### Ticket
-[%s](https://linear.app/issue/%s)
-
-### Description
-%s
-
-### Changes
-%s
-
-### Quality
-- Review iterations: %d
-- Tests: %s
-- Coverage: %.1f%%
-
----
-*Automated by BoatmanMode 🚣*
-`,
-			wc.task.GetTitle(),
-			wc.task.GetID(),
-			wc.task.GetID(),
-			wc.task.GetDescription(),
-			wc.reviewResult.Summary,
-			wc.iterations,
-			formatTestStatus(wc.testResult),
-			getTestCoverage(wc.testResult),
-		)
-	} else {
-		// Prompt/File mode - no ticket link
-		taskType := "Prompt-based"
-		if metadata.Source == task.SourceFile {
-			taskType = "File-based"
-		}
-
-		prBody = fmt.Sprintf(`## %s
-
-### Task
-%s task (%s)
-
-### Description
-%s
+	a.printStep(9, 9, "Creating pull request")
 
-### Changes
-%s
-
-### Quality
-- Review iterations: %d
-- Tests: %s
-- Coverage: %.1f%%
-
----
-*Automated by BoatmanMode 🚣*
-`,
-			wc.task.GetTitle(),
-			taskType,
-			wc.task.GetID(),
-			truncate(wc.task.GetDescription(), 500),
-			wc.reviewResult.Summary,
-			wc.iterations,
-			formatTestStatus(wc.testResult),
-			getTestCoverage(wc.testResult),
-		)
+	prBody, err := a.buildPRBody(ctx, wc)
+	if err != nil {
+		events.AgentCompleted(agentID, "Create PR", "failed")
+		return nil, fmt.Errorf("failed to create PR: %w", err)
 	}
 
-	fmt.Println("   🔗 Running: gh pr create")
-	prResult, err := github.CreatePRInDir(ctx, wc.worktree.Path, wc.task.GetTitle(), prBody, a.config.BaseBranch)
+	host := a.forge(wc)
+	prResult, err := host.CreatePR(ctx, wc.worktree.Path, forge.NewPR{
+		Title: wc.task.GetTitle(),
+		Body:  prBody,
+		Base:  a.config.BaseBranch,
+		Head:  wc.branchName,
+		Draft: a.config.PR.Draft,
+	})
 	if err != nil {
 		events.AgentCompleted(agentID, "Create PR", "failed")
 		return nil, fmt.Errorf("failed to create PR: %w", err)
 	}
+	if labels := a.config.PR.Labels; len(labels) > 0 {
+		if err := host.AddLabels(ctx, prResult.URL, labels); err != nil {
+			fmt.Fprintf(a.output(), "   ⚠️  Could not add labels: %v\n", err)
+		}
+	}
+	a.requestReviewers(ctx, wc, host, prResult.URL)
 
 	events.AgentCompleted(agentID, "Create PR", "success")
+	a.transitionJiraTicket(ctx, wc)
 	a.printWorkflowSummary(wc, prResult.URL)
 
 	return &WorkResult{
@@ -691,27 +1646,213 @@ func (a *Agent) stepCreatePR(ctx context.Context, wc *workContext) (*WorkResult,
 	}, nil
 }
 
+// transitionJiraTicket moves a Jira ticket to jira.transition once its PR
+// exists. Failures only warn; the PR is what matters.
+func (a *Agent) transitionJiraTicket(ctx context.Context, wc *workContext) {
+	jt, ok := wc.task.(*task.JiraTask)
+	if !ok || a.config.Jira.Transition == "" {
+		return
+	}
+	if err := a.jiraClient.Transition(ctx, jt.GetID(), a.config.Jira.Transition); err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Could not move %s to %s: %v\n", jt.GetID(), a.config.Jira.Transition, err)
+		return
+	}
+	fmt.Fprintf(a.output(), "   🎫 Moved %s to %s\n", jt.GetID(), a.config.Jira.Transition)
+}
+
+// buildImpactSection renders the blast-radius report for the PR body.
+// Returns an empty string if analysis yields nothing.
+func (a *Agent) buildImpactSection(wc *workContext) string {
+	if wc.finalDiff == "" {
+		return ""
+	}
+	report, err := impact.New(wc.worktree.Path).Analyze(wc.finalDiff)
+	if err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Impact analysis failed: %v\n", err)
+		return ""
+	}
+	section := report.Markdown()
+	if section == "" {
+		return ""
+	}
+	fmt.Fprintf(a.output(), "   🎯 Impact: %d changed symbol(s)\n", len(report.Symbols))
+	return section + "\n"
+}
+
+// recordScoreHistory stores the final review score in project memory.
+// Failures are reported but never fail the workflow.
+func (a *Agent) recordScoreHistory(wc *workContext) {
+	if wc.reviewResult == nil || wc.repoPath == "" {
+		return
+	}
+	store, err := memory.NewStore("")
+	if err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Could not open memory store: %v\n", err)
+		return
+	}
+	mem, err := store.Get(wc.repoPath)
+	if err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Could not load project memory: %v\n", err)
+		return
+	}
+
+	mem.RecordScore(memory.ScoreRecord{
+		TaskID:       wc.task.GetID(),
+		Score:        wc.reviewResult.Score,
+		Breakdown:    wc.reviewResult.Breakdown,
+		Passed:       wc.reviewResult.Passed,
+		Iterations:   wc.iterations,
+		CostUSD:      wc.costTracker.Total().TotalCostUSD,
+		FilesChanged: len(wc.execResult.FilesChanged),
+	})
+	mem.UpdateStats(wc.reviewResult.Passed, wc.iterations, time.Since(wc.startTime))
+	if !wc.reviewResult.Passed && !wc.reviewResult.Inconclusive {
+		mem.RecordFailure(string(checkpoint.StepReview), wc.iterations, reviewFailureReason(wc))
+	}
+	if err := store.Save(mem); err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Could not save score history: %v\n", err)
+		return
+	}
+
+	if trends := mem.FormatScoreTrends(10); trends != "" && len(mem.ScoreHistory) > 1 {
+		a.printIndented(strings.TrimRight(trends, "\n"), "   ")
+	}
+}
+
+// recordPR links the session's score record to its PR, for analytics.
+func (a *Agent) recordPR(wc *workContext, url string) {
+	store, err := memory.NewStore("")
+	if err != nil {
+		return
+	}
+	mem, err := store.Get(wc.repoPath)
+	if err != nil {
+		return
+	}
+	mem.SetPRURL(wc.task.GetID(), url)
+	if err := store.Save(mem); err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Could not record PR in project memory: %v\n", err)
+	}
+}
+
+// runRetro has a cheap model distill lessons from the run's reviews and
+// test results into project memory. Failures never fail the workflow.
+func (a *Agent) runRetro(ctx context.Context, wc *workContext) {
+	if wc.repoPath == "" {
+		return
+	}
+	fmt.Fprintln(a.output(), "   🪞 Running retro...")
+	events.Progress("Distilling lessons from the run")
+
+	run := retro.Run{
+		Title:        wc.task.GetTitle(),
+		Description:  wc.task.GetDescription(),
+		FilesChanged: wc.execResult.FilesChanged,
+		Reviews:      wc.reviews,
+		Resolved:     wc.fixed,
+		Passed:       wc.reviewResult.Passed,
+		Iterations:   wc.iterations,
+	}
+	if wc.testResult != nil {
+		run.TestSummary = (&testrunner.TestResultHandoff{Result: wc.testResult}).Concise()
+	}
+	lessons, usage, err := retro.New(wc.worktree.Path, a.config).Analyze(ctx, run)
+	if usage != nil {
+		wc.costTracker.Add("Retro", *usage)
+	}
+	if err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Retro failed: %v\n", err)
+		return
+	}
+
+	store, err := memory.NewStore("")
+	if err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Could not open memory store: %v\n", err)
+		return
+	}
+	mem, err := store.Get(wc.repoPath)
+	if err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Could not load project memory: %v\n", err)
+		return
+	}
+	n := lessons.Apply(mem)
+	if err := store.Save(mem); err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Could not save retro lessons: %v\n", err)
+		return
+	}
+	fmt.Fprintf(a.output(), "   🪞 Retro: %d lesson(s) saved to project memory\n", n)
+}
+
+// recordStepFailure stores the step a failed workflow stopped at in
+// project memory, so later runs can be warned about it.
+func (a *Agent) recordStepFailure(wc *workContext, err error) {
+	if err == nil || wc.failedStep == "" || wc.declined || wc.repoPath == "" {
+		return
+	}
+	store, serr := memory.NewStore("")
+	if serr != nil {
+		return
+	}
+	mem, serr := store.Get(wc.repoPath)
+	if serr != nil {
+		return
+	}
+	mem.RecordFailure(wc.failedStep, wc.iterations, err.Error())
+	mem.UpdateStats(false, wc.iterations, time.Since(wc.startTime))
+	if serr := store.Save(mem); serr != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Could not save failure point: %v\n", serr)
+	}
+}
+
+// reviewFailureReason summarizes why the review never passed, leading
+// with the first blocking issue.
+func reviewFailureReason(wc *workContext) string {
+	reason := fmt.Sprintf("review did not pass after %d iteration(s)", wc.iterations)
+	for _, issue := range wc.reviewResult.Issues {
+		if issue.Severity == "critical" || issue.Severity == "major" {
+			return reason + ": " + issue.Description
+		}
+	}
+	return reason
+}
+
+// loadFailureModes returns the historical failure warnings for repoPath,
+// or "" if there are none.
+func loadFailureModes(repoPath string) string {
+	store, err := memory.NewStore("")
+	if err != nil {
+		return ""
+	}
+	mem, err := store.Get(repoPath)
+	if err != nil {
+		return ""
+	}
+	return mem.FailureModes(5)
+}
+
 // printWorkflowSummary prints the final workflow completion summary.
 func (a *Agent) printWorkflowSummary(wc *workContext, prURL string) {
 	totalElapsed := time.Since(wc.startTime)
 
-	fmt.Println()
-	fmt.Println("═══════════════════════════════════════════════════════════════════════")
-	fmt.Println("✅ WORKFLOW COMPLETE")
-	fmt.Println("═══════════════════════════════════════════════════════════════════════")
-	fmt.Printf("   🎫 Task:       %s\n", wc.task.GetID())
-	fmt.Printf("   🌿 Branch:     %s\n", wc.branchName)
-	fmt.Printf("   🔄 Iterations: %d\n", wc.iterations)
-	fmt.Printf("   🧪 Tests:      %s\n", formatTestStatus(wc.testResult))
-	fmt.Printf("   ⏱️  Total time: %s\n", totalElapsed.Round(time.Second))
-	fmt.Printf("   🔗 PR:         %s\n", prURL)
+	fmt.Fprintln(a.output())
+	fmt.Fprintln(a.output(), "═══════════════════════════════════════════════════════════════════════")
+	fmt.Fprintln(a.output(), "✅ WORKFLOW COMPLETE")
+	fmt.Fprintln(a.output(), "═══════════════════════════════════════════════════════════════════════")
+	fmt.Fprintf(a.output(), "   🎫 Task:       %s\n", wc.task.GetID())
+	fmt.Fprintf(a.output(), "   🌿 Branch:     %s\n", wc.branchName)
+	fmt.Fprintf(a.output(), "   🔄 Iterations: %d\n", wc.iterations)
+	fmt.Fprintf(a.output(), "   🧪 Tests:      %s\n", formatTestStatus(wc.testResult))
+	fmt.Fprintf(a.output(), "   ⏱️  Total time: %s\n", totalElapsed.Round(time.Second))
+	fmt.Fprintf(a.output(), "   🔗 PR:         %s\n", prURL)
 
 	// Display cost summary if any usage was tracked
 	if wc.costTracker.HasUsage() {
-		fmt.Print(wc.costTracker.Summary())
+		fmt.Fprint(a.output(), wc.costTracker.Summary())
 	}
 
-	fmt.Println("═══════════════════════════════════════════════════════════════════════")
+	fmt.Fprint(a.output(), wc.decisions.Format())
+
+	fmt.Fprintln(a.output(), "═══════════════════════════════════════════════════════════════════════")
 }
 
 // formatTestStatus formats test result for display.
@@ -734,28 +1875,22 @@ func getTestCoverage(result *testrunner.TestResult) float64 {
 }
 
 // printStep prints a formatted step header.
-func printStep(current, total int, description string) {
-	fmt.Println()
-	fmt.Printf("━━━ Step %d/%d: %s ━━━\n", current, total, description)
+func (a *Agent) printStep(current, total int, description string) {
+	fmt.Fprintln(a.output())
+	fmt.Fprintf(a.output(), "━━━ Step %d/%d: %s ━━━\n", current, total, description)
 }
 
 // printIndented prints text with indentation.
-func printIndented(text, indent string) {
+func (a *Agent) printIndented(text, indent string) {
 	lines := strings.Split(text, "\n")
 	for _, line := range lines {
-		fmt.Printf("%s%s\n", indent, line)
+		fmt.Fprintf(a.output(), "%s%s\n", indent, line)
 	}
 }
 
 // getRepoURL gets the remote URL for the repository.
 func getRepoURL(repoPath string) (string, error) {
-	cmd := exec.Command("git", "remote", "get-url", "origin")
-	cmd.Dir = repoPath
-	output, err := cmd.Output()
-	if err != nil {
-		return "", err
-	}
-	return strings.TrimSpace(string(output)), nil
+	return gitops.New(repoPath).RemoteURL("origin")
 }
 
 // truncate shortens a string to the given length.
@@ -0,0 +1,75 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/checkpoint"
+	"github.com/philjestin/boatmanmode/internal/events"
+)
+
+// approvalGates maps require_approval to the step the run stops before.
+var approvalGates = map[string]checkpoint.Step{
+	"commit": checkpoint.StepCommit,
+	"push":   checkpoint.StepPush,
+	"pr":     checkpoint.StepCreatePR,
+}
+
+// gateActions describes what each gated step does, for prompts.
+var gateActions = map[checkpoint.Step]string{
+	checkpoint.StepCommit:   "commit",
+	checkpoint.StepPush:     "push",
+	checkpoint.StepCreatePR: "open the PR",
+}
+
+// SetApprove approves whatever a paused run is waiting on when it is
+// resumed: its plan, or its require_approval gate (boatman approve).
+func (a *Agent) SetApprove(approve bool) {
+	a.approve = approve
+}
+
+// awaitApproval stops the run before step when require_approval gates it.
+// On a terminal the operator approves it there; otherwise, or if they
+// decline, the run pauses until boatman approve. It returns the result of
+// a paused run, or nil to go on.
+func (a *Agent) awaitApproval(wc *workContext, step checkpoint.Step) *WorkResult {
+	gate, ok := approvalGates[a.config.RequireApproval]
+	if !ok || gate != step {
+		return nil
+	}
+	action := gateActions[step]
+	if wc.gateApproved {
+		fmt.Fprintf(a.output(), "   ✅ Approved to %s\n", action)
+		wc.decisions.Record(string(step), "continue past the approval gate", "approved with boatman approve")
+		return nil
+	}
+
+	events.Progress(fmt.Sprintf("Waiting for approval to %s", action))
+	fmt.Fprintf(a.output(), "\n   ✋ Approval required to %s (require_approval: %s)\n", action, a.config.RequireApproval)
+	fmt.Fprintf(a.output(), "   🌿 Branch %s in %s\n", wc.branchName, wc.worktree.Path)
+	if wc.execResult != nil && len(wc.execResult.FilesChanged) > 0 {
+		fmt.Fprintf(a.output(), "   📝 Files: %s\n", strings.Join(wc.execResult.FilesChanged, ", "))
+	}
+	if a.input != nil || stdinIsTerminal() {
+		fmt.Fprintf(a.output(), "   Continue and %s now? [y/N]: ", action)
+		line, _ := a.pairInput().ReadString('\n')
+		fmt.Fprintln(a.output())
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "y", "yes":
+			wc.decisions.Record(string(step), "continue past the approval gate", "approved by the operator")
+			return nil
+		}
+	}
+
+	if wc.checkpoint == nil || wc.checkpoint.Current == nil {
+		// Nowhere to pause; stop rather than act unapproved
+		return &WorkResult{Message: fmt.Sprintf("Stopped before the %s: approval required (worktree: %s)", action, wc.worktree.Path)}
+	}
+	id := wc.checkpoint.Current.ID
+	reason := fmt.Sprintf("approval required to %s: review %s, then run boatman approve %s", action, wc.worktree.Path, id)
+	wc.checkpoint.Pause(reason)
+	wc.decisions.Record(string(step), "pause for approval", fmt.Sprintf("require_approval is %q", a.config.RequireApproval))
+	fmt.Fprintf(a.output(), "   ⏸️  Paused. Approve with: boatman approve %s\n", id)
+	fmt.Fprintln(a.output())
+	return &WorkResult{Paused: true, Message: reason, Iterations: wc.iterations}
+}
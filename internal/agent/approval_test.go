@@ -0,0 +1,89 @@
+package agent
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/checkpoint"
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/decisionlog"
+	"github.com/philjestin/boatmanmode/internal/worktree"
+)
+
+func newApprovalContext(t *testing.T) *workContext {
+	t.Helper()
+	cp, err := checkpoint.NewManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp.Start("ENG-1", 3)
+	return &workContext{
+		branchName: "eng-1-retries",
+		worktree:   &worktree.Worktree{Path: "/tmp/worktrees/eng-1"},
+		checkpoint: cp,
+		decisions:  decisionlog.New(),
+	}
+}
+
+func TestAwaitApprovalOnlyAtGate(t *testing.T) {
+	wc := newApprovalContext(t)
+	a := &Agent{config: &config.Config{RequireApproval: "push"}}
+	for _, step := range []checkpoint.Step{checkpoint.StepCommit, checkpoint.StepCreatePR} {
+		if result := a.awaitApproval(wc, step); result != nil {
+			t.Errorf("stopped before %s: %+v", step, result)
+		}
+	}
+	a.config.RequireApproval = ""
+	if result := a.awaitApproval(wc, checkpoint.StepPush); result != nil {
+		t.Errorf("stopped without require_approval: %+v", result)
+	}
+}
+
+func TestAwaitApprovalPrompt(t *testing.T) {
+	tests := []struct {
+		answer string
+		paused bool
+	}{
+		{"y\n", false},
+		{"yes\n", false},
+		{"\n", true},
+		{"n\n", true},
+		{"", true},
+	}
+	for _, tt := range tests {
+		t.Run(strings.TrimSpace(tt.answer), func(t *testing.T) {
+			wc := newApprovalContext(t)
+			a := &Agent{config: &config.Config{RequireApproval: "commit"}, input: bufio.NewReader(strings.NewReader(tt.answer))}
+			result := a.awaitApproval(wc, checkpoint.StepCommit)
+			if paused := result != nil && result.Paused; paused != tt.paused {
+				t.Errorf("paused = %v, want %v", paused, tt.paused)
+			}
+			if (wc.checkpoint.Current.Paused != "") != tt.paused {
+				t.Errorf("checkpoint Paused = %q", wc.checkpoint.Current.Paused)
+			}
+		})
+	}
+}
+
+func TestAwaitApprovalPausesWithoutTerminal(t *testing.T) {
+	defer func(orig func() bool) { stdinIsTerminal = orig }(stdinIsTerminal)
+	stdinIsTerminal = func() bool { return false }
+
+	wc := newApprovalContext(t)
+	a := &Agent{config: &config.Config{RequireApproval: "pr"}}
+	result := a.awaitApproval(wc, checkpoint.StepCreatePR)
+	if result == nil || !result.Paused {
+		t.Fatalf("result = %+v, want paused", result)
+	}
+	id := wc.checkpoint.Current.ID
+	if !strings.Contains(result.Message, "boatman approve "+id) || wc.checkpoint.Current.Paused != result.Message {
+		t.Errorf("Message = %q, Paused = %q", result.Message, wc.checkpoint.Current.Paused)
+	}
+
+	// boatman approve resumes the run past the gate
+	wc.gateApproved = true
+	if result := a.awaitApproval(wc, checkpoint.StepCreatePR); result != nil {
+		t.Errorf("stopped after approval: %+v", result)
+	}
+}
@@ -0,0 +1,103 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/philjestin/boatmanmode/internal/azure"
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/events"
+	"github.com/philjestin/boatmanmode/internal/task"
+)
+
+// useAzure reports whether the PR is opened in Azure Repos instead of
+// GitHub, per pr.provider or, when auto, the origin remote.
+func (a *Agent) useAzure(wc *workContext) bool {
+	switch a.config.PR.Provider {
+	case "azure":
+		return true
+	case "auto", "":
+		url, _ := wc.exec.Git().RemoteURL("origin")
+		_, ok := azure.ParseRemote(url)
+		return ok
+	}
+	return false
+}
+
+// azureRepo returns the Azure DevOps settings for the run's repository:
+// the configured ones, with blanks filled in from the origin remote.
+func (a *Agent) azureRepo(wc *workContext) config.AzureConfig {
+	cfg := a.config.Azure
+	url, _ := wc.exec.Git().RemoteURL("origin")
+	if remote, ok := azure.ParseRemote(url); ok {
+		if cfg.Organization == "" {
+			cfg.Organization = remote.Organization
+		}
+		if cfg.Project == "" {
+			cfg.Project = remote.Project
+		}
+		if cfg.Repository == "" {
+			cfg.Repository = remote.Repository
+		}
+	}
+	return cfg
+}
+
+// createAzurePR opens the pull request in Azure Repos (Step 9), linking
+// the run's work item when the task came from Azure Boards.
+func (a *Agent) createAzurePR(ctx context.Context, wc *workContext) (*WorkResult, error) {
+	agentID := fmt.Sprintf("pr-%s", wc.task.GetID())
+	events.AgentStarted(agentID, "Create PR", "Creating Azure Repos pull request")
+
+	a.printStep(9, 9, "Creating pull request")
+
+	cfg := a.azureRepo(wc)
+	if cfg.Repository == "" {
+		events.AgentCompleted(agentID, "Create PR", "failed")
+		return nil, fmt.Errorf("failed to create PR: azure.repository is not set and origin is not an Azure Repos remote")
+	}
+
+	prBody, err := a.buildPRBody(ctx, wc)
+	if err != nil {
+		events.AgentCompleted(agentID, "Create PR", "failed")
+		return nil, fmt.Errorf("failed to create PR: %w", err)
+	}
+	if len(prBody) > azure.MaxDescription {
+		prBody = truncate(prBody, azure.MaxDescription-3)
+	}
+
+	pr := azure.NewPullRequest{
+		Repository:  cfg.Repository,
+		Source:      wc.branchName,
+		Target:      a.config.BaseBranch,
+		Title:       wc.task.GetTitle(),
+		Description: prBody,
+		Draft:       a.config.PR.Draft,
+	}
+	if at, ok := wc.task.(*task.AzureTask); ok {
+		pr.WorkItems = []int{at.GetWorkItem().ID}
+	}
+
+	fmt.Fprintf(a.output(), "   🔗 Creating pull request in %s/%s/%s\n", cfg.Organization, cfg.Project, cfg.Repository)
+	created, err := azure.New(cfg).CreatePullRequest(ctx, pr)
+	if err != nil {
+		events.AgentCompleted(agentID, "Create PR", "failed")
+		return nil, fmt.Errorf("failed to create PR: %w", err)
+	}
+	if len(pr.WorkItems) > 0 {
+		fmt.Fprintf(a.output(), "   🎫 Linked work item %d\n", pr.WorkItems[0])
+	}
+
+	events.AgentCompleted(agentID, "Create PR", "success")
+	a.transitionJiraTicket(ctx, wc)
+	a.printWorkflowSummary(wc, created.URL)
+
+	return &WorkResult{
+		PRCreated:    true,
+		PRURL:        created.URL,
+		Message:      "Successfully created PR",
+		Iterations:   wc.iterations,
+		TestsPassed:  wc.testResult == nil || wc.testResult.Passed,
+		TestCoverage: getTestCoverage(wc.testResult),
+	}, nil
+}
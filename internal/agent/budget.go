@@ -0,0 +1,70 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/llm"
+)
+
+// BudgetError stops a run that has spent its Config.MaxCostUSD.
+type BudgetError struct {
+	SpentUSD float64
+	MaxUSD   float64
+	// Before is the step the run stopped before.
+	Before string
+}
+
+func (e *BudgetError) Error() string {
+	return fmt.Sprintf("cost budget exceeded: spent $%.2f of $%.2f before %s", e.SpentUSD, e.MaxUSD, e.Before)
+}
+
+// checkBudget returns a *BudgetError when the run has spent its budget.
+// It is called before each step that may call Claude.
+func (a *Agent) checkBudget(wc *workContext, next string) error {
+	if a.config.MaxCostUSD <= 0 {
+		return nil
+	}
+	spent := wc.costTracker.Total().TotalCostUSD
+	if spent < a.config.MaxCostUSD {
+		return nil
+	}
+	return &BudgetError{SpentUSD: spent, MaxUSD: a.config.MaxCostUSD, Before: next}
+}
+
+// warnUnpricedBudget warns that max_cost_usd does not cover models whose
+// cost the provider does not report, such as Ollama's.
+func (a *Agent) warnUnpricedBudget(wc *workContext) {
+	if a.config.MaxCostUSD <= 0 {
+		return
+	}
+	unpriced := llm.UnpricedModels(a.config)
+	if len(unpriced) == 0 {
+		return
+	}
+	reason := fmt.Sprintf("provider %s reports no cost for %s", a.config.Claude.Provider.Name, strings.Join(unpriced, ", "))
+	fmt.Fprintf(a.output(), "   ⚠️  max_cost_usd only counts priced models: %s\n", reason)
+	wc.decisions.Record("budget", "enforce max_cost_usd on priced models only", reason)
+}
+
+// budgetStop turns a budget cutoff into the run's result. The failed step
+// is in the checkpoint, so the run can be resumed with a higher budget.
+func (a *Agent) budgetStop(wc *workContext, err error) *WorkResult {
+	var budget *BudgetError
+	if !errors.As(err, &budget) {
+		return nil
+	}
+	fmt.Fprintf(a.output(), "\n   💸 Budget reached: $%.2f of $%.2f spent, stopping before %s\n", budget.SpentUSD, budget.MaxUSD, budget.Before)
+	wc.decisions.Record("budget", "stop the run", budget.Error())
+	message := fmt.Sprintf("Stopped before %s: spent $%.2f of the $%.2f budget (max_cost_usd). Raise it and run boatman resume %s to continue",
+		budget.Before, budget.SpentUSD, budget.MaxUSD, wc.task.GetID())
+	if wc.worktree != nil {
+		message += fmt.Sprintf(" (worktree: %s)", wc.worktree.Path)
+	}
+	return &WorkResult{
+		PRCreated:  false,
+		Message:    message,
+		Iterations: wc.iterations,
+	}
+}
@@ -0,0 +1,75 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/checkpoint"
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/linear"
+	"github.com/philjestin/boatmanmode/internal/task"
+	"github.com/philjestin/boatmanmode/internal/worktree"
+)
+
+func TestBudgetWithProvider(t *testing.T) {
+	// One planning call of 400k input tokens costs $1.20 on claude-sonnet-4-5
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"content":[{"type":"text","text":"{\"summary\": \"Add retries\"}"}],"usage":{"input_tokens":400000,"output_tokens":0}}`))
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{MaxCostUSD: 1}
+	cfg.Claude.Provider = config.ProviderConfig{Name: "anthropic", BaseURL: srv.URL, APIKey: "sk-test"}
+	a, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	a.SetOutput(&out)
+	wc := newWorkContext(task.NewLinearTask(&linear.Ticket{Identifier: "ENG-1", Title: "Retries"}))
+	wc.worktree = &worktree.Worktree{Path: t.TempDir()}
+
+	ctx := context.Background()
+	if err := a.runStep(ctx, wc, checkpoint.StepPlanning, a.stepPlanning); err != nil {
+		t.Fatal(err)
+	}
+	if spent := wc.costTracker.Total().TotalCostUSD; spent < 1 {
+		t.Fatalf("Expected the planning call to be priced, spent $%g", spent)
+	}
+	err = a.runStep(ctx, wc, checkpoint.StepValidation, func(context.Context, *workContext) error {
+		t.Error("Expected the step to be skipped once the budget is spent")
+		return nil
+	})
+	var budget *BudgetError
+	if !errors.As(err, &budget) || budget.MaxUSD != 1 {
+		t.Errorf("Expected a budget cutoff, got %v", err)
+	}
+}
+
+func TestWarnUnpricedBudget(t *testing.T) {
+	cfg := &config.Config{MaxCostUSD: 5}
+	cfg.Claude.Provider = config.ProviderConfig{Name: "ollama"}
+	cfg.Claude.Models.Executor = "qwen2.5-coder"
+	a := &Agent{config: cfg}
+	var out bytes.Buffer
+	a.SetOutput(&out)
+	wc := newWorkContext(task.NewLinearTask(&linear.Ticket{Identifier: "ENG-1"}))
+
+	a.warnUnpricedBudget(wc)
+	if !strings.Contains(out.String(), "qwen2.5-coder") || wc.decisions.Len() != 1 {
+		t.Errorf("Expected a warning naming the unpriced model, got %q", out.String())
+	}
+
+	cfg.Claude.Provider.Name = "anthropic"
+	cfg.Claude.Models.Executor = "claude-sonnet-4-5"
+	out.Reset()
+	a.warnUnpricedBudget(wc)
+	if out.Len() != 0 {
+		t.Errorf("Expected no warning with priced models, got %q", out.String())
+	}
+}
@@ -0,0 +1,88 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/gitops"
+)
+
+// errCancelled is returned by a step the run was stopped in, by boatman
+// cancel or an interrupt.
+var errCancelled = errors.New("run cancelled")
+
+// cancelPollInterval is how often a run looks for a boatman cancel request.
+var cancelPollInterval = time.Second
+
+// watchCancel returns a context that is cancelled once boatman cancel asks
+// the run to stop; stop ends the watch. A request left by an earlier run
+// that died before reading it is dropped.
+func (a *Agent) watchCancel(ctx context.Context, wc *workContext) (context.Context, func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	cp := wc.checkpoint
+	if cp == nil {
+		return ctx, cancel
+	}
+	ticketID := wc.task.GetID()
+	cp.ClearCancel(ticketID)
+	go func() {
+		ticker := time.NewTicker(cancelPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if req, ok := cp.CancelRequested(ticketID); ok {
+					wc.cancelMu.Lock()
+					wc.cancelReq = &req
+					wc.cancelMu.Unlock()
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+	return ctx, cancel
+}
+
+// cancelled winds a stopped run down. Claude's sessions already ended
+// with the context and the coordinator's locks are released as the run
+// returns; this records the cancellation in the checkpoint, leaving the
+// interrupted step to run again on resume, and removes the worktree when
+// boatman cancel asked for it.
+func (a *Agent) cancelled(wc *workContext) *WorkResult {
+	reason := "interrupted"
+	wc.cancelMu.Lock()
+	req := wc.cancelReq
+	wc.cancelMu.Unlock()
+	if req != nil {
+		reason = "cancelled with boatman cancel"
+	}
+	wc.decisions.Record("cancel", "stop the run", reason)
+
+	message := fmt.Sprintf("Run %s", reason)
+	if wc.checkpoint != nil && wc.checkpoint.Current != nil {
+		wc.checkpoint.Cancel(reason)
+		wc.checkpoint.ClearCancel(wc.task.GetID())
+		message = fmt.Sprintf("Run %s at %s; continue with: boatman resume %s",
+			reason, wc.checkpoint.Current.CurrentStep, wc.checkpoint.Current.ID)
+	}
+
+	if req != nil && req.RemoveWorktree && wc.worktree != nil {
+		repo := wc.repoPath
+		if repo == "" {
+			repo = wc.worktree.Path
+		}
+		if err := gitops.New(repo).WorktreeRemove(wc.worktree.Path, true); err != nil {
+			fmt.Fprintf(a.output(), "   ⚠️  Could not remove worktree: %v\n", err)
+		} else {
+			fmt.Fprintf(a.output(), "   🧹 Removed worktree %s (branch %s is kept)\n", wc.worktree.Path, wc.worktree.BranchName)
+			message = fmt.Sprintf("Run %s; worktree removed", reason)
+		}
+	}
+	fmt.Fprintf(a.output(), "\n🛑 %s\n", message)
+	return &WorkResult{Cancelled: true, Message: message, Iterations: wc.iterations}
+}
@@ -0,0 +1,105 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/coverage"
+	"github.com/philjestin/boatmanmode/internal/pipeline"
+	"github.com/philjestin/boatmanmode/internal/scottbott"
+	"github.com/philjestin/boatmanmode/internal/testrunner"
+	"github.com/philjestin/boatmanmode/internal/unidiff"
+)
+
+// gatesCoverage reports whether min_coverage applies to this run.
+func (a *Agent) gatesCoverage() bool {
+	return a.config.MinCoverage > 0 && a.stages.Has(pipeline.Test)
+}
+
+// setupCoverage measures per-file coverage before the change when
+// min_coverage is set, so files are only held to what they had.
+func (a *Agent) setupCoverage(ctx context.Context, wc *workContext) {
+	if !a.gatesCoverage() {
+		return
+	}
+	fmt.Fprintln(a.output(), "   📊 Measuring test coverage before the change...")
+	profile := a.measureCoverage(ctx, wc)
+	if profile == nil {
+		fmt.Fprintln(a.output(), "   ⚠️  No per-file coverage before the change; changed files are held to min_coverage")
+	}
+	wc.covBaseline = profile
+}
+
+// measureCoverage runs the full test suite for per-file coverage. Returns
+// nil when the tests report none.
+func (a *Agent) measureCoverage(ctx context.Context, wc *workContext) coverage.Profile {
+	runner := testrunner.New(wc.worktree.Path)
+	runner.SetCommand(a.config.Commands.Test)
+	runner.SetCoverage(true)
+	result, err := runner.RunAll(ctx)
+	if err != nil || result == nil {
+		return nil
+	}
+	return result.Files
+}
+
+// coverageFindings returns the changed files covered below min_coverage.
+// The result stands until the change does.
+func (a *Agent) coverageFindings(ctx context.Context, wc *workContext) []coverage.Finding {
+	tree, _ := wc.exec.SnapshotTree()
+	if tree != "" && tree == wc.coveredTree {
+		return wc.covFindings
+	}
+	diff, err := wc.exec.GetDiff()
+	if err != nil {
+		return nil
+	}
+	var changed []string
+	for _, f := range unidiff.Parse(diff) {
+		if f.Status != unidiff.Deleted {
+			changed = append(changed, f.Path())
+		}
+	}
+
+	fmt.Fprintln(a.output(), "   📊 Measuring test coverage of the changed files...")
+	profile := a.measureCoverage(ctx, wc)
+	if profile == nil {
+		fmt.Fprintln(a.output(), "   ⚠️  Coverage gate skipped: the tests reported no per-file coverage")
+		return nil
+	}
+	wc.coveredTree = tree
+	wc.covFindings = coverage.Check(wc.covBaseline, profile, changed, a.config.MinCoverage)
+	if len(wc.covFindings) > 0 {
+		var files []string
+		for _, f := range wc.covFindings {
+			files = append(files, f.String())
+		}
+		wc.decisions.Record("review", "fail review on test coverage",
+			fmt.Sprintf("below min_coverage %.0f%%: %s", a.config.MinCoverage, strings.Join(files, "; ")))
+	}
+	return wc.covFindings
+}
+
+// checkCoverage returns a major issue per changed file covered below
+// min_coverage, so the refactor loop adds tests.
+func (a *Agent) checkCoverage(ctx context.Context, wc *workContext) []scottbott.Issue {
+	return coverage.Issues(a.coverageFindings(ctx, wc), a.config.MinCoverage)
+}
+
+// enforceCoverage fails a run without review whose changed files are
+// covered below min_coverage, as no refactor loop can add the tests.
+func (a *Agent) enforceCoverage(ctx context.Context, wc *workContext) error {
+	if !a.gatesCoverage() || a.stages.Has(pipeline.Review) {
+		return nil
+	}
+	findings := a.coverageFindings(ctx, wc)
+	if len(findings) == 0 {
+		return nil
+	}
+	var files []string
+	for _, f := range findings {
+		files = append(files, f.String())
+	}
+	return fmt.Errorf("test coverage below min_coverage (%.0f%%): %s", a.config.MinCoverage, strings.Join(files, "; "))
+}
@@ -0,0 +1,110 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/e2e"
+	"github.com/philjestin/boatmanmode/internal/localize"
+	"github.com/philjestin/boatmanmode/internal/scottbott"
+)
+
+// writeE2ESpecs has an agent cover the ticket's acceptance criteria with
+// specs in the repo's Playwright or Cypress suite, under e2e.enabled. A
+// writer that fails only warns: the change stands without them.
+func (a *Agent) writeE2ESpecs(ctx context.Context, wc *workContext) {
+	if !a.config.E2E.Enabled {
+		return
+	}
+	checklist := a.checklist(wc)
+	if checklist == nil {
+		return
+	}
+	framework := e2e.Detect(wc.worktree.Path, a.config.E2E)
+	if framework == nil {
+		return
+	}
+	fmt.Fprintf(a.output(), "   🎭 Writing %s specs for %d acceptance criteria...\n", framework.Name, len(checklist.Criteria))
+	usage, err := e2e.NewWriter(wc.worktree.Path, framework, a.config).Write(ctx, wc.task.GetTitle(), checklist)
+	if usage != nil {
+		wc.costTracker.Add("E2E Specs", *usage)
+	}
+	if err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Could not write E2E specs: %v\n", err)
+		return
+	}
+	wc.decisions.Record("execute", fmt.Sprintf("write %s specs for the acceptance criteria", framework.Name),
+		fmt.Sprintf("the ticket lists %d acceptance criteria and e2e.enabled is set", len(checklist.Criteria)))
+}
+
+// checkE2E runs the E2E specs the diff adds or changes headless, returning
+// a major issue per failing test. A framework that cannot run only warns.
+func (a *Agent) checkE2E(ctx context.Context, wc *workContext, diff string) []scottbott.Issue {
+	framework := e2e.Detect(wc.worktree.Path, a.config.E2E)
+	if framework == nil {
+		return nil
+	}
+	specs := framework.Specs(diff)
+	if len(specs) == 0 {
+		return nil
+	}
+	fmt.Fprintf(a.output(), "   🎭 Running %d %s spec(s) headless...\n", len(specs), framework.Name)
+	result, err := framework.Run(ctx, specs)
+	if err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  E2E specs could not run: %v\n", err)
+		return nil
+	}
+	wc.e2eResult = result
+
+	var issues []scottbott.Issue
+	for _, test := range result.Failed() {
+		description := fmt.Sprintf("E2E test fails: %s", test.Name)
+		if criterion := a.criterionText(wc, test.Criterion()); criterion != "" {
+			description = fmt.Sprintf("E2E test for acceptance criterion %q fails: %s", criterion, test.Name)
+		}
+		if test.Failure != "" {
+			description += ": " + test.Failure
+		}
+		issues = append(issues, scottbott.Issue{
+			Severity:    "major",
+			File:        test.File,
+			Description: description,
+			Suggestion:  "Fix the code so the test passes; change the test only if it checks the criterion wrongly",
+		})
+	}
+	return issues
+}
+
+// criterionText returns the text of the acceptance criterion with id.
+func (a *Agent) criterionText(wc *workContext, id int) string {
+	checklist := a.checklist(wc)
+	if checklist == nil {
+		return ""
+	}
+	for _, criterion := range checklist.Criteria {
+		if criterion.ID == id {
+			return criterion.Text
+		}
+	}
+	return ""
+}
+
+// e2eStatus is the PR quality line for the last E2E run, listing failing
+// tests under it. Empty when no specs ran.
+func e2eStatus(wc *workContext, l localize.Labels) string {
+	result := wc.e2eResult
+	if result == nil || len(result.Tests) == 0 {
+		return ""
+	}
+	failed := result.Failed()
+	if len(failed) == 0 {
+		return fmt.Sprintf("- %s: ✅ %d passed (%s)\n", l.E2E, result.PassedCount(), result.Framework)
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("- %s: ❌ %d failed, %d passed (%s)\n", l.E2E, len(failed), result.PassedCount(), result.Framework))
+	for _, test := range failed {
+		sb.WriteString(fmt.Sprintf("  - %s\n", test.Name))
+	}
+	return sb.String()
+}
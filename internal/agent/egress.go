@@ -0,0 +1,44 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/egress"
+)
+
+// startEgress starts the egress proxy the run's executor, refactor and
+// test-fix sessions reach the network through, when network.enabled is
+// set. The run fails rather than go without it.
+func (a *Agent) startEgress(wc *workContext) error {
+	proxy, err := egress.Start(a.config.Network)
+	if err != nil {
+		return err
+	}
+	if proxy != nil {
+		proxy.SetOutput(a.out)
+		fmt.Fprintf(a.output(), "   🌐 Network egress policy on (allowing %s)\n", strings.Join(a.config.Network.Allow, ", "))
+	}
+	wc.proxy = proxy
+	return nil
+}
+
+// stopEgress stops the egress proxy, recording the hosts it refused.
+func (a *Agent) stopEgress(wc *workContext) {
+	if wc.proxy == nil {
+		return
+	}
+	if blocked := wc.proxy.Blocked(); len(blocked) > 0 {
+		wc.decisions.Record("execute", "blocked network access", strings.Join(blocked, ", ")+" not allowed by the network policy")
+	}
+	wc.proxy.Close()
+}
+
+// proxyEnv returns the environment pointing a session at the egress
+// proxy, or nil without one.
+func (wc *workContext) proxyEnv() map[string]string {
+	if wc.proxy == nil {
+		return nil
+	}
+	return wc.proxy.Env()
+}
@@ -0,0 +1,60 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/task"
+)
+
+// maxSentryIssues caps the Sentry issues fetched for a ticket.
+const maxSentryIssues = 3
+
+// errorContext distills the latest event of each Sentry issue a bug ticket
+// links to, in its description or attachments: the exception, its frames
+// and the breadcrumbs before it. Empty for other tickets, or without a
+// Sentry token.
+func (a *Agent) errorContext(ctx context.Context, wc *workContext) string {
+	if a.sentryClient == nil || !a.sentryClient.Configured() || !isBug(wc.task) {
+		return ""
+	}
+	text := wc.task.GetDescription()
+	if lt, ok := wc.task.(*task.LinearTask); ok {
+		for _, att := range lt.GetTicket().Attachments {
+			text += "\n" + att.URL
+		}
+	}
+
+	var parts, ids []string
+	for i, issue := range a.sentryClient.Issues(text) {
+		if i == maxSentryIssues {
+			break
+		}
+		event, err := a.sentryClient.LatestEvent(ctx, issue)
+		if err != nil {
+			fmt.Fprintf(a.output(), "   ⚠️  Could not fetch Sentry issue %s: %v\n", issue.ID, err)
+			continue
+		}
+		parts = append(parts, event.Distill(issue))
+		ids = append(ids, issue.ID)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	fmt.Fprintf(a.output(), "   🐛 Added error context from %d Sentry issue(s)\n", len(parts))
+	wc.decisions.Record("planning", "add Sentry error context to the execution prompt",
+		fmt.Sprintf("the bug ticket links Sentry issue(s) %s", strings.Join(ids, ", ")))
+	return strings.Join(parts, "\n")
+}
+
+// isBug reports whether t is a bug ticket, by its labels; Jira and Azure
+// tasks have their issue type among them.
+func isBug(t task.Task) bool {
+	for _, label := range t.GetLabels() {
+		if strings.EqualFold(label, "bug") {
+			return true
+		}
+	}
+	return false
+}
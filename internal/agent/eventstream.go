@@ -0,0 +1,65 @@
+package agent
+
+import (
+	"github.com/philjestin/boatmanmode/internal/cost"
+	"github.com/philjestin/boatmanmode/internal/events"
+	"github.com/philjestin/boatmanmode/internal/scottbott"
+	"github.com/philjestin/boatmanmode/internal/testrunner"
+)
+
+// emitCost reports each Claude call's cost as it is recorded.
+func emitCost(step string, usage, total cost.Usage) {
+	events.Cost(step, usage.TotalCostUSD, total.TotalCostUSD, usage.InputTokens, usage.OutputTokens)
+}
+
+// emitTestResults reports a test run. No result means no tests ran.
+func emitTestResults(result *testrunner.TestResult) {
+	if result == nil {
+		return
+	}
+	events.TestResults(result.Passed, result.Framework, result.TotalTests, result.FailedTests, result.SkippedTests, result.FailedNames)
+}
+
+// emitReviewVerdict reports a review's verdict after policies applied.
+func emitReviewVerdict(iteration int, result *scottbott.ReviewResult) {
+	if result == nil {
+		return
+	}
+	verdict := "failed"
+	switch {
+	case result.Inconclusive:
+		verdict = "inconclusive"
+	case result.Passed:
+		verdict = "passed"
+	}
+	issues := map[string]int{}
+	for _, issue := range result.Issues {
+		issues[issue.Severity]++
+	}
+	events.ReviewVerdict(iteration, verdict, result.Score, issues)
+}
+
+// emitRunCompleted reports how the run ended.
+func emitRunCompleted(wc *workContext, result *WorkResult, err error) {
+	id := wc.task.GetID()
+	if wc.checkpoint != nil && wc.checkpoint.Current != nil {
+		id = wc.checkpoint.Current.ID
+	}
+	data := map[string]any{
+		"ticket_id":      wc.task.GetID(),
+		"iterations":     wc.iterations,
+		"total_cost_usd": wc.costTracker.Total().TotalCostUSD,
+	}
+	switch {
+	case err != nil:
+		data["failed_step"] = wc.failedStep
+		events.RunCompleted(id, "failed", err.Error(), data)
+	case result != nil && result.Cancelled:
+		events.RunCompleted(id, "cancelled", result.Message, data)
+	case result != nil && result.PRCreated:
+		data["pr_url"] = result.PRURL
+		events.RunCompleted(id, "pr_created", result.Message, data)
+	case result != nil:
+		events.RunCompleted(id, "stopped", result.Message, data)
+	}
+}
@@ -0,0 +1,28 @@
+package agent
+
+import (
+	"fmt"
+
+	"github.com/philjestin/boatmanmode/internal/forge"
+)
+
+// forge returns the code host the run's PR is opened on: the one
+// pr.provider names, or for auto the one hosting the origin remote.
+func (a *Agent) forge(wc *workContext) forge.Forge {
+	provider := a.config.PR.Provider
+	if provider == "auto" || provider == "" {
+		url, _ := wc.exec.Git().RemoteURL("origin")
+		provider = forge.Detect(url)
+	}
+	if provider == forge.ProviderBitbucket {
+		fmt.Fprintln(a.output(), "   🔗 Creating PR through the Bitbucket API")
+		return forge.NewBitbucket(a.config.Bitbucket)
+	}
+	gh := forge.NewGitHub(a.config.GitHub)
+	if gh.UsesAPI() {
+		fmt.Fprintln(a.output(), "   🔗 Creating PR through the GitHub API")
+	} else {
+		fmt.Fprintln(a.output(), "   🔗 Running: gh pr create")
+	}
+	return gh
+}
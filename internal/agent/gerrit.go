@@ -0,0 +1,64 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/philjestin/boatmanmode/internal/events"
+	"github.com/philjestin/boatmanmode/internal/gerrit"
+)
+
+// useGerrit reports whether the change goes to Gerrit for review instead
+// of a GitHub PR, per pr.provider or, when auto, the repo's remote.
+func (a *Agent) useGerrit(wc *workContext) bool {
+	switch a.config.PR.Provider {
+	case "gerrit":
+		return true
+	case "github", "bitbucket":
+		return false
+	}
+	url, _ := wc.exec.Git().RemoteURL(a.config.Gerrit.Remote)
+	return gerrit.Detect(wc.worktree.Path, url)
+}
+
+// changeID is the Change-Id of the run's change.
+func changeID(wc *workContext) string {
+	return gerrit.ChangeID(wc.task.GetID(), wc.branchName)
+}
+
+// submitToGerrit pushes the commit for review in place of creating a PR
+// (Step 9). The change description is the commit message.
+func (a *Agent) submitToGerrit(ctx context.Context, wc *workContext) (*WorkResult, error) {
+	agentID := fmt.Sprintf("pr-%s", wc.task.GetID())
+	events.AgentStarted(agentID, "Create PR", "Pushing change for Gerrit review")
+
+	a.printStep(9, 9, "Pushing for Gerrit review")
+
+	topic := a.config.Gerrit.Topic
+	if topic == "" {
+		topic = wc.task.GetID()
+	}
+	fmt.Fprintf(a.output(), "   🔗 Running: git push %s HEAD:%s\n", a.config.Gerrit.Remote, gerrit.Ref(a.config.BaseBranch, topic))
+	url, err := gerrit.Push(ctx, wc.worktree.Path, a.config.Gerrit.Remote, a.config.BaseBranch, topic)
+	if err != nil {
+		events.AgentCompleted(agentID, "Create PR", "failed")
+		return nil, fmt.Errorf("failed to push for review: %w", err)
+	}
+	if url == "" {
+		// Gerrit finds the change by its Change-Id
+		url = changeID(wc)
+	}
+
+	events.AgentCompleted(agentID, "Create PR", "success")
+	a.transitionJiraTicket(ctx, wc)
+	a.printWorkflowSummary(wc, url)
+
+	return &WorkResult{
+		PRCreated:    true,
+		PRURL:        url,
+		Message:      "Successfully pushed change for Gerrit review",
+		Iterations:   wc.iterations,
+		TestsPassed:  wc.testResult == nil || wc.testResult.Passed,
+		TestCoverage: getTestCoverage(wc.testResult),
+	}, nil
+}
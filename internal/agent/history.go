@@ -0,0 +1,157 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/gitops"
+)
+
+// History curation strategies for git.history.
+const (
+	historySquash    = "squash"
+	historyPlanSteps = "plan-steps"
+	historyKeep      = "keep"
+)
+
+// commitWIP commits the staged work in progress when git.wip_commits is
+// set, so the branch records the run as it goes. Failures only warn.
+func (a *Agent) commitWIP(wc *workContext, label string) {
+	if !a.config.Git.WIPCommits {
+		return
+	}
+	git := wc.exec.Git()
+	if !git.HasStagedChanges() {
+		return
+	}
+	if err := git.Commit(fmt.Sprintf("wip(%s): %s", wc.task.GetID(), label)); err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Could not commit work in progress: %v\n", err)
+		return
+	}
+	fmt.Fprintf(a.output(), "   💾 Committed work in progress (%s)\n", label)
+}
+
+// curateHistory turns the run's changes, staged or in work-in-progress
+// commits since the run began, into the commits git.history asks for. The
+// last commit carries message. Git is stopped if ctx is cancelled.
+func (a *Agent) curateHistory(ctx context.Context, wc *workContext, message string) error {
+	git := wc.exec.Git().WithContext(ctx)
+	history := a.config.Git.History
+	if wc.gerrit && history != historySquash {
+		// Each commit pushed for review becomes its own Gerrit change
+		wc.decisions.Record("history", "squash into one commit", "Gerrit reviews a change per commit")
+		history = historySquash
+	}
+	switch history {
+	case historyKeep:
+		// The WIP commits may already hold everything
+		_, err := git.Run("commit", "--allow-empty", "-m", message)
+		return err
+	case historyPlanSteps:
+		if err := a.resetToBase(wc, git); err != nil {
+			return err
+		}
+		return a.commitPlanSteps(wc, git, message)
+	default: // historySquash
+		if err := a.resetToBase(wc, git); err != nil {
+			return err
+		}
+		return git.Commit(message)
+	}
+}
+
+// resetToBase moves the branch back to where the run began, leaving the
+// changes of any commits since staged.
+func (a *Agent) resetToBase(wc *workContext, git *gitops.Repo) error {
+	if wc.baseCommit == "" {
+		return nil
+	}
+	if err := git.ResetSoft(wc.baseCommit); err != nil {
+		return fmt.Errorf("failed to squash work in progress: %w", err)
+	}
+	return nil
+}
+
+// commitPlanSteps commits the staged changes as one commit per plan step,
+// holding the changed files that step is the first to mention. Files no
+// step mentions go in a final commit with message, as do the last step's
+// files when every file is mentioned.
+func (a *Agent) commitPlanSteps(wc *workContext, git *gitops.Repo, message string) error {
+	var approach []string
+	if wc.plan != nil {
+		approach = wc.plan.Approach
+	}
+	out, err := git.Run("diff", "--cached", "--name-only", "--no-renames", "-z")
+	if err != nil {
+		return fmt.Errorf("failed to list changes: %w", err)
+	}
+	files := strings.Split(strings.TrimSuffix(out, "\x00"), "\x00")
+	groups, rest := planStepFiles(approach, files)
+
+	// The final commit needs changes of its own
+	last := len(groups) - 1
+	for last >= 0 && len(groups[last]) == 0 {
+		last--
+	}
+	if len(rest) == 0 && last >= 0 {
+		groups = groups[:last]
+	}
+
+	if err := git.Unstage(); err != nil {
+		return fmt.Errorf("failed to regroup changes: %w", err)
+	}
+	for i, group := range groups {
+		if len(group) == 0 {
+			continue
+		}
+		if err := git.Stage(group...); err != nil {
+			return fmt.Errorf("failed to stage plan step %d: %w", i+1, err)
+		}
+		subject := fmt.Sprintf("feat(%s): %s", wc.task.GetID(), stepSubject(approach[i]))
+		if err := git.Commit(subject); err != nil {
+			return fmt.Errorf("failed to commit plan step %d: %w", i+1, err)
+		}
+		fmt.Fprintf(a.output(), "   📝 Step %d: %d file(s)\n", i+1, len(group))
+	}
+	if err := git.AddAll(); err != nil {
+		return fmt.Errorf("failed to stage remaining changes: %w", err)
+	}
+	return git.Commit(message)
+}
+
+// planStepFiles assigns each file to the first plan step naming its path
+// or base name, returning the files per step and those no step names.
+func planStepFiles(approach, files []string) ([][]string, []string) {
+	groups := make([][]string, len(approach))
+	var rest []string
+	for _, file := range files {
+		if file == "" {
+			continue
+		}
+		step := -1
+		for i, text := range approach {
+			if strings.Contains(text, file) || strings.Contains(text, filepath.Base(file)) {
+				step = i
+				break
+			}
+		}
+		if step < 0 {
+			rest = append(rest, file)
+			continue
+		}
+		groups[step] = append(groups[step], file)
+	}
+	return groups, rest
+}
+
+// stepSubject shortens a plan step to a commit subject.
+func stepSubject(step string) string {
+	subject := strings.TrimSpace(strings.SplitN(step, "\n", 2)[0])
+	subject = strings.TrimSuffix(subject, ".")
+	if runes := []rune(subject); len(runes) > 60 {
+		subject = strings.TrimSpace(string(runes[:57])) + "..."
+	}
+	return subject
+}
@@ -0,0 +1,125 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/executor"
+	"github.com/philjestin/boatmanmode/internal/linear"
+	"github.com/philjestin/boatmanmode/internal/planner"
+	"github.com/philjestin/boatmanmode/internal/task"
+)
+
+func TestPlanStepFiles(t *testing.T) {
+	approach := []string{
+		"Add the Invoice model in billing/invoice.go",
+		"Expose it from api/handlers.go and cover invoice.go with tests",
+		"Update docs",
+	}
+	files := []string{"billing/invoice.go", "api/handlers.go", "billing/invoice_test.go", "README.md"}
+
+	groups, rest := planStepFiles(approach, files)
+
+	want := [][]string{{"billing/invoice.go"}, {"api/handlers.go"}, nil}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("Expected groups %v, got %v", want, groups)
+	}
+	if !reflect.DeepEqual(rest, []string{"billing/invoice_test.go", "README.md"}) {
+		t.Errorf("Unexpected unassigned files: %v", rest)
+	}
+}
+
+// newHistoryContext returns a run in a fresh repo with two WIP commits
+// and a staged change on top of its base.
+func newHistoryContext(t *testing.T, cfg *config.Config) (*workContext, *Agent) {
+	t.Helper()
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(path, content string) {
+		t.Helper()
+		os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0755)
+		if err := os.WriteFile(filepath.Join(dir, path), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "-q")
+	git("config", "user.email", "test@example.com")
+	git("config", "user.name", "Test")
+	write("README.md", "# app\n")
+	git("add", "-A")
+	git("commit", "-q", "-m", "initial")
+
+	a := &Agent{config: cfg}
+	wc := &workContext{
+		task:       task.NewLinearTask(&linear.Ticket{Identifier: "ENG-9", Title: "Invoices"}),
+		exec:       executor.New(dir, cfg),
+		plan:       &planner.Plan{Approach: []string{"Add billing/invoice.go", "Wire api/handlers.go"}},
+		iterations: 1,
+	}
+	wc.baseCommit = strings.TrimSpace(gitOutput(t, dir, "rev-parse", "HEAD"))
+
+	write("billing/invoice.go", "package billing\n")
+	wc.exec.StageChanges()
+	a.commitWIP(wc, "implementation")
+	write("api/handlers.go", "package api\n")
+	wc.exec.StageChanges()
+	a.commitWIP(wc, "refactor 1")
+	write("README.md", "# app\n\nInvoices.\n")
+	wc.exec.StageChanges()
+	return wc, a
+}
+
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+	if err != nil {
+		t.Fatalf("git %v: %v", args, err)
+	}
+	return string(out)
+}
+
+func TestCurateHistory(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	for _, tc := range []struct {
+		history string
+		want    []string
+	}{
+		{"squash", []string{"feat(ENG-9): Invoices", "initial"}},
+		{"plan-steps", []string{"feat(ENG-9): Invoices", "feat(ENG-9): Wire api/handlers.go", "feat(ENG-9): Add billing/invoice.go", "initial"}},
+		{"keep", []string{"feat(ENG-9): Invoices", "wip(ENG-9): refactor 1", "wip(ENG-9): implementation", "initial"}},
+	} {
+		t.Run(tc.history, func(t *testing.T) {
+			cfg := &config.Config{Git: config.GitConfig{WIPCommits: true, History: tc.history}}
+			wc, a := newHistoryContext(t, cfg)
+			dir := wc.exec.Git().Dir()
+
+			if err := a.curateHistory(context.Background(), wc, "feat(ENG-9): Invoices"); err != nil {
+				t.Fatalf("curateHistory failed: %v", err)
+			}
+
+			log := strings.Split(strings.TrimSpace(gitOutput(t, dir, "log", "--format=%s")), "\n")
+			if !reflect.DeepEqual(log, tc.want) {
+				t.Errorf("Expected commits %v, got %v", tc.want, log)
+			}
+			if status := gitOutput(t, dir, "status", "--porcelain"); status != "" {
+				t.Errorf("Expected every change committed, got:\n%s", status)
+			}
+			if files := strings.Fields(gitOutput(t, dir, "diff", "--name-only", wc.baseCommit, "HEAD")); len(files) != 3 {
+				t.Errorf("Expected all three changed files on the branch, got %v", files)
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/philjestin/boatmanmode/internal/checkpoint"
+	"github.com/philjestin/boatmanmode/internal/hooks"
+)
+
+// runHooks runs the configured hooks for when around step, in the worktree
+// once there is one.
+func (a *Agent) runHooks(ctx context.Context, wc *workContext, step checkpoint.Step, when string) error {
+	if a.hooks == nil || !a.hooks.Has(step, when) {
+		return nil
+	}
+	dir := wc.repoPath
+	hc := hooks.Context{
+		Branch:     wc.branchName,
+		BaseCommit: wc.baseCommit,
+		Plan:       wc.plan,
+	}
+	if wc.task != nil {
+		hc.Task = hooks.Task{
+			ID:          wc.task.GetID(),
+			Title:       wc.task.GetTitle(),
+			Description: wc.task.GetDescription(),
+			Labels:      wc.task.GetLabels(),
+		}
+	}
+	if wc.worktree != nil {
+		dir, hc.Worktree = wc.worktree.Path, wc.worktree.Path
+	}
+	if wc.exec != nil {
+		hc.Diff, _ = wc.exec.GetDiff()
+	}
+	return a.hooks.Run(ctx, step, when, dir, hc)
+}
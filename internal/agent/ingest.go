@@ -0,0 +1,180 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/ingest"
+	"github.com/philjestin/boatmanmode/internal/specs"
+	"github.com/philjestin/boatmanmode/internal/task"
+)
+
+const (
+	// maxScreenshots caps the images described for a ticket.
+	maxScreenshots = 5
+	// maxImageDownloads caps the uploads and images downloaded to find
+	// them; uploads may be logs.
+	maxImageDownloads = 10
+	// maxLinkedPages caps the web pages fetched for a ticket.
+	maxLinkedPages = 3
+)
+
+// ticketMedia turns the ticket's screenshots and the web pages it links to
+// into text for the planning and execution prompts, under ingest.*.
+func (a *Agent) ticketMedia(ctx context.Context, wc *workContext) string {
+	var screenshots string
+	if a.config.Ingest.Images {
+		screenshots = a.describeScreenshots(ctx, wc)
+	}
+	var pages []*ingest.Page
+	if a.config.Ingest.URLs && a.fetcher != nil {
+		pages = a.linkedPages(ctx, wc)
+	}
+	return ingest.Render(screenshots, pages, a.config.Ingest.MaxTokens)
+}
+
+// describeScreenshots downloads the ticket's images, from Linear uploads
+// and from images embedded in the description, and has the vision model
+// describe them.
+func (a *Agent) describeScreenshots(ctx context.Context, wc *workContext) string {
+	type source struct {
+		url, alt string
+		upload   bool // Uploaded to Linear, which needs the API key
+	}
+	var sources []source
+	seen := make(map[string]bool)
+	alts := make(map[string]string)
+	for _, img := range ingest.Images(wc.task.GetDescription()) {
+		alts[img.URL] = img.Alt
+	}
+	if lt, ok := wc.task.(*task.LinearTask); ok && a.linearClient != nil {
+		for _, u := range lt.GetTicket().UploadURLs() {
+			seen[u] = true
+			sources = append(sources, source{u, alts[u], true})
+		}
+	}
+	for _, img := range ingest.Images(wc.task.GetDescription()) {
+		if !seen[img.URL] && a.fetcher != nil {
+			seen[img.URL] = true
+			sources = append(sources, source{img.URL, img.Alt, false})
+		}
+	}
+	if len(sources) == 0 {
+		return ""
+	}
+
+	dir, err := os.MkdirTemp("", "boatman-screenshots-")
+	if err != nil {
+		return ""
+	}
+	defer os.RemoveAll(dir)
+
+	var files, captions []string
+	for i, src := range sources {
+		if len(files) == maxScreenshots || i == maxImageDownloads {
+			break
+		}
+		var data []byte
+		if src.upload {
+			data, err = a.linearClient.Download(ctx, src.url)
+		} else {
+			data, _, err = a.fetcher.Download(ctx, src.url)
+		}
+		if err != nil {
+			fmt.Fprintf(a.output(), "   ⚠️  Could not download image: %v\n", err)
+			continue
+		}
+		// Log files and other uploads are read for stack traces instead
+		ext := ingest.ImageExtension(data)
+		if ext == "" {
+			continue
+		}
+		name := fmt.Sprintf("screenshot-%d%s", len(files)+1, ext)
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			continue
+		}
+		files = append(files, name)
+		captions = append(captions, src.alt)
+	}
+	if len(files) == 0 {
+		return ""
+	}
+
+	fmt.Fprintf(a.output(), "   🖼️  Describing %d screenshot(s)\n", len(files))
+	description, usage, err := ingest.NewDescriber(dir, a.config).Describe(ctx, wc.task.GetTitle(), files, captions)
+	if usage != nil {
+		wc.costTracker.Add("Vision", *usage)
+	}
+	if err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Could not describe screenshots: %v\n", err)
+		return ""
+	}
+	wc.decisions.Record("planning", "add screenshot descriptions to the planning and execution prompts",
+		fmt.Sprintf("the ticket includes %d screenshot(s)", len(files)))
+	return description
+}
+
+// linkedPages fetches the public web pages the ticket's description links
+// to. Specs, Sentry issues, uploads and the issue trackers' own pages are
+// left out: their integrations read them, or they need a login.
+func (a *Agent) linkedPages(ctx context.Context, wc *workContext) []*ingest.Page {
+	var pages []*ingest.Page
+	var titles []string
+	for _, link := range ingest.Links(wc.task.GetDescription()) {
+		if len(pages) == maxLinkedPages {
+			break
+		}
+		if a.handledElsewhere(link) {
+			continue
+		}
+		page, err := a.fetcher.Page(ctx, link)
+		if err != nil {
+			fmt.Fprintf(a.output(), "   ⚠️  Could not fetch %s: %v\n", link, err)
+			continue
+		}
+		if page.Text == "" {
+			continue
+		}
+		pages = append(pages, page)
+		title := page.Title
+		if title == "" {
+			title = page.URL
+		}
+		titles = append(titles, title)
+	}
+	if len(pages) > 0 {
+		fmt.Fprintf(a.output(), "   🔗 Added %d linked page(s): %s\n", len(pages), strings.Join(titles, ", "))
+		wc.decisions.Record("planning", "add the linked pages to the planning and execution prompts",
+			fmt.Sprintf("the ticket links %s", strings.Join(titles, ", ")))
+	}
+	return pages
+}
+
+// trackerHosts serve pages that need a login to read.
+var trackerHosts = []string{"linear.app", "atlassian.net", "dev.azure.com", "bitbucket.org"}
+
+// handledElsewhere reports whether link is read by another integration or
+// needs a login.
+func (a *Agent) handledElsewhere(link string) bool {
+	if len(specs.Links(link)) > 0 {
+		return true
+	}
+	if a.sentryClient != nil && len(a.sentryClient.Issues(link)) > 0 {
+		return true
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return true
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, tracker := range trackerHosts {
+		if host == tracker || strings.HasSuffix(host, "."+tracker) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package agent
+
+import (
+	"fmt"
+	"strings"
+)
+
+// iterationNotes is the notes ref the final commit's convergence summary
+// is written to (refs/notes/boatman).
+const iterationNotes = "boatman"
+
+// iterationTag records the code a review saw and how it scored.
+type iterationTag struct {
+	Iteration  int
+	Tag        string
+	Score      int
+	Unresolved int
+	Passed     bool
+}
+
+// tagIteration snapshots the code under the latest review as a commit on top
+// of HEAD and tags it with the iteration's outcome, when git.iteration_tags
+// is set. The branch itself is untouched, so the run still ends in a single
+// commit. Failures only warn: traceability must not fail the run.
+func (a *Agent) tagIteration(wc *workContext, iteration int) {
+	if !a.config.Git.IterationTags || wc.reviewResult == nil || wc.reviewedTree == "" {
+		return
+	}
+	it := iterationTag{
+		Iteration:  iteration,
+		Tag:        fmt.Sprintf("boatman/%s/iter-%d", runID(wc), iteration),
+		Score:      wc.reviewResult.Score,
+		Unresolved: len(wc.reviewResult.Issues),
+		Passed:     wc.reviewResult.Passed,
+	}
+	message := fmt.Sprintf("boatman: %s iteration %d\n\n%s", wc.task.GetID(), iteration, it.summary())
+
+	git := wc.exec.Git()
+	commit, err := git.CommitTree(wc.reviewedTree, "HEAD", message)
+	if err == nil {
+		err = git.Tag(it.Tag, commit)
+	}
+	if err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Could not tag iteration %d: %v\n", iteration, err)
+		return
+	}
+	wc.iterTags = append(wc.iterTags, it)
+	fmt.Fprintf(a.output(), "   🏷️  Tagged iteration %d as %s\n", iteration, it.Tag)
+}
+
+// noteIterations attaches the run's iteration history to the final commit.
+func (a *Agent) noteIterations(wc *workContext) {
+	if len(wc.iterTags) == 0 {
+		return
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("boatman run %s: %d iteration(s)\n\n", runID(wc), len(wc.iterTags)))
+	for _, it := range wc.iterTags {
+		sb.WriteString(fmt.Sprintf("Iteration %d (%s): %s\n", it.Iteration, it.Tag, it.summary()))
+	}
+	if err := wc.exec.Git().AddNote(iterationNotes, "HEAD", sb.String()); err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Could not note iteration history: %v\n", err)
+	}
+}
+
+// summary describes the iteration's review outcome on one line.
+func (it iterationTag) summary() string {
+	verdict := "failed"
+	if it.Passed {
+		verdict = "passed"
+	}
+	return fmt.Sprintf("review %s, score %d, %d unresolved issue(s)", verdict, it.Score, it.Unresolved)
+}
+
+// runID returns the run's checkpoint ID, or one in the same form when the
+// run has no checkpoint.
+func runID(wc *workContext) string {
+	if wc.checkpoint != nil && wc.checkpoint.Current != nil {
+		return wc.checkpoint.Current.ID
+	}
+	return fmt.Sprintf("%s-%d", wc.task.GetID(), wc.startTime.Unix())
+}
@@ -0,0 +1,36 @@
+package agent
+
+import (
+	"fmt"
+
+	"github.com/philjestin/boatmanmode/internal/llm"
+	"github.com/philjestin/boatmanmode/internal/manifest"
+)
+
+// recordManifest saves the manifest of the run's conditions once its
+// worktree exists, before any agent has changed the rules files.
+func (a *Agent) recordManifest(wc *workContext) {
+	wc.manifest = manifest.New(a.config, runID(wc), wc.task.GetID(), wc.worktree.Path, wc.baseCommit)
+	if provider := wc.manifest.Provider; wc.manifest.Sampling != nil && (provider == "" || provider == llm.ClaudeCLI) {
+		fmt.Fprintln(a.output(), "   ⚠️  claude.sampling is not applied by the Claude CLI, only by API providers")
+	}
+	path, err := wc.manifest.Save("")
+	if err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Failed to save the run manifest: %v\n", err)
+		return
+	}
+	fmt.Fprintf(a.output(), "   🧾 Run manifest: %s\n", path)
+}
+
+// manifestComment returns the run's manifest as a hidden comment for the
+// PR body. A resumed run uses the manifest saved when it started.
+func (a *Agent) manifestComment(wc *workContext) string {
+	if wc.manifest == nil {
+		if saved, err := manifest.Load("", runID(wc)); err == nil {
+			wc.manifest = saved
+		} else {
+			a.recordManifest(wc)
+		}
+	}
+	return "\n" + wc.manifest.Comment()
+}
@@ -0,0 +1,46 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/notify"
+)
+
+// notify sends a run lifecycle event to the configured webhooks, filling
+// in the run's ticket, ID and cost.
+func (a *Agent) notify(wc *workContext, e notify.Event) {
+	if a.notifier == nil {
+		return
+	}
+	e.TicketID = wc.task.GetID()
+	e.Title = wc.task.GetTitle()
+	if wc.checkpoint != nil && wc.checkpoint.Current != nil {
+		e.RunID = wc.checkpoint.Current.ID
+	}
+	e.Iterations = wc.iterations
+	e.CostUSD = wc.costTracker.Total().TotalCostUSD
+
+	// The run's context may already be cancelled when it fails
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := a.notifier.Send(ctx, e); err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Notification not sent: %v\n", err)
+	}
+}
+
+// notifyFinished tells the webhooks how the run ended. Runs that stop
+// without a PR for other reasons, such as dry runs or cancellation, are
+// not announced.
+func (a *Agent) notifyFinished(wc *workContext, result *WorkResult, err error) {
+	switch {
+	case result != nil && result.Cancelled:
+	case err != nil:
+		a.notify(wc, notify.Event{Type: notify.Failed, Message: err.Error()})
+	case result != nil && result.PRCreated:
+		a.notify(wc, notify.Event{Type: notify.PRCreated, PRURL: result.PRURL})
+	case result != nil && wc.reviewResult != nil && (wc.reviewResult.Inconclusive || !wc.reviewResult.Passed):
+		a.notify(wc, notify.Event{Type: notify.ReviewFailed, Message: result.Message})
+	}
+}
@@ -0,0 +1,114 @@
+package agent
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/events"
+)
+
+// pairPause gives the operator a chance to edit the worktree by hand
+// (pair mode). Manual edits are staged and treated as part of the change:
+// their files join FilesChanged, context pins are refreshed, and the next
+// test run and review see them like any agent edit.
+func (a *Agent) pairPause(wc *workContext, stage string) error {
+	if !a.config.Pair || wc.pairDone {
+		return nil
+	}
+
+	before, err := wc.exec.SnapshotTree()
+	if err != nil {
+		return err
+	}
+
+	events.Progress(fmt.Sprintf("Waiting for manual edits after %s", stage))
+	fmt.Fprintf(a.output(), "\n   ✋ Pair mode (%s): edit files in %s\n", stage, wc.worktree.Path)
+	fmt.Fprint(a.output(), "   Press Enter when done, or type \"done\" to stop pausing: ")
+	line, err := a.pairInput().ReadString('\n')
+	if err != nil || strings.TrimSpace(line) == "done" {
+		// No operator on the other end (or they're finished): stop asking
+		wc.pairDone = true
+	}
+	fmt.Fprintln(a.output())
+
+	if err := wc.exec.StageChanges(); err != nil {
+		return fmt.Errorf("failed to stage manual edits: %w", err)
+	}
+	after, err := wc.exec.SnapshotTree()
+	if err != nil {
+		return err
+	}
+	if after == before {
+		fmt.Fprintln(a.output(), "   No manual edits")
+		return nil
+	}
+
+	out, err := wc.exec.Git().Run("diff", "--cached", "--name-only", "-z", before)
+	if err != nil {
+		return fmt.Errorf("failed to list manual edits: %w", err)
+	}
+	var edited []string
+	if out = strings.TrimSuffix(out, "\x00"); out != "" {
+		edited = strings.Split(out, "\x00")
+	}
+
+	fmt.Fprintf(a.output(), "   ✍️  %d file(s) edited by hand:\n", len(edited))
+	for _, path := range edited {
+		fmt.Fprintf(a.output(), "      • %s\n", path)
+		if !slices.Contains(wc.execResult.FilesChanged, path) {
+			wc.execResult.FilesChanged = append(wc.execResult.FilesChanged, path)
+		}
+	}
+	wc.humanEdited = appendMissing(wc.humanEdited, edited...)
+
+	// Re-pin so later agents see the edited contents and their dependencies
+	wc.pinner.AnalyzeFiles(edited)
+	var pinned []string
+	if wc.plan != nil {
+		pinned = append(pinned, wc.plan.RelevantFiles...)
+	}
+	pinned = appendMissing(pinned, wc.execResult.FilesChanged...)
+	if _, err := wc.pinner.Pin("executor", pinned, false); err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Could not refresh pins: %v\n", err)
+	}
+
+	wc.decisions.Record(stage, "include manual edits in the change",
+		fmt.Sprintf("pair mode: %s edited by hand; tests and review re-run on them", strings.Join(edited, ", ")))
+	return nil
+}
+
+// pairInput returns the reader pair mode prompts read from.
+func (a *Agent) pairInput() *bufio.Reader {
+	if a.input == nil {
+		a.input = bufio.NewReader(os.Stdin)
+	}
+	return a.input
+}
+
+// appendMissing appends the items not already in slice.
+func appendMissing(slice []string, items ...string) []string {
+	for _, item := range items {
+		if !slices.Contains(slice, item) {
+			slice = append(slice, item)
+		}
+	}
+	return slice
+}
+
+// humanEditsSection lists files edited by hand in pair mode, for the PR body.
+func humanEditsSection(wc *workContext) string {
+	if len(wc.humanEdited) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("### ✍️ Manual Edits\n")
+	sb.WriteString("These files were edited by hand during the run (pair mode):\n")
+	for _, path := range wc.humanEdited {
+		sb.WriteString(fmt.Sprintf("- `%s`\n", path))
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
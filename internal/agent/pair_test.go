@@ -0,0 +1,123 @@
+package agent
+
+import (
+	"bufio"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/contextpin"
+	"github.com/philjestin/boatmanmode/internal/decisionlog"
+	"github.com/philjestin/boatmanmode/internal/executor"
+	"github.com/philjestin/boatmanmode/internal/planner"
+	"github.com/philjestin/boatmanmode/internal/worktree"
+)
+
+// editingReader simulates an operator: it edits files on the first read,
+// then answers the prompt.
+type editingReader struct {
+	edit   func()
+	answer *strings.Reader
+}
+
+func (r *editingReader) Read(p []byte) (int, error) {
+	if r.edit != nil {
+		r.edit()
+		r.edit = nil
+	}
+	return r.answer.Read(p)
+}
+
+func newPairContext(t *testing.T) (*workContext, string) {
+	t.Helper()
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test"},
+	} {
+		if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{Pair: true}
+	wc := &workContext{
+		worktree:   &worktree.Worktree{Path: dir},
+		exec:       executor.New(dir, cfg),
+		execResult: &executor.ExecutionResult{Success: true, FilesChanged: []string{"main.go"}},
+		plan:       &planner.Plan{},
+		pinner:     contextpin.New(dir),
+		decisions:  decisionlog.New(),
+	}
+	if err := wc.exec.StageChanges(); err != nil {
+		t.Fatal(err)
+	}
+	return wc, dir
+}
+
+func TestPairPauseIncludesManualEdits(t *testing.T) {
+	wc, dir := newPairContext(t)
+	a := &Agent{config: &config.Config{Pair: true}}
+	a.input = bufio.NewReader(&editingReader{
+		edit: func() {
+			os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644)
+			os.WriteFile(filepath.Join(dir, "helper util.go"), []byte("package main\n"), 0644)
+		},
+		answer: strings.NewReader("\n"),
+	})
+
+	if err := a.pairPause(wc, "execute"); err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"main.go", "helper util.go"}; !reflect.DeepEqual(wc.execResult.FilesChanged, want) {
+		t.Errorf("Expected FilesChanged %v, got %v", want, wc.execResult.FilesChanged)
+	}
+	if want := []string{"helper util.go", "main.go"}; !reflect.DeepEqual(wc.humanEdited, want) {
+		t.Errorf("Expected humanEdited %v, got %v", want, wc.humanEdited)
+	}
+	if diff, _ := wc.exec.GetDiff(); !strings.Contains(diff, "helper util.go") {
+		t.Errorf("Expected manual edits to be staged, got diff:\n%s", diff)
+	}
+	if content, ok := wc.pinner.GetPinnedContent("executor", "main.go"); !ok || !strings.Contains(content, "func main") {
+		t.Errorf("Expected pin refreshed with edited content, got %q", content)
+	}
+	if len(wc.decisions.Entries()) != 1 {
+		t.Errorf("Expected one decision, got %v", wc.decisions.Entries())
+	}
+	if wc.pairDone {
+		t.Error("Expected pair mode to keep pausing")
+	}
+}
+
+func TestPairPauseNoEditsAndDone(t *testing.T) {
+	wc, _ := newPairContext(t)
+	a := &Agent{config: &config.Config{Pair: true}}
+	a.input = bufio.NewReader(strings.NewReader("done\n"))
+
+	if err := a.pairPause(wc, "refactor"); err != nil {
+		t.Fatal(err)
+	}
+	if len(wc.humanEdited) != 0 || len(wc.decisions.Entries()) != 0 {
+		t.Errorf("Expected no manual edits, got %v", wc.humanEdited)
+	}
+	if !wc.pairDone {
+		t.Error("Expected \"done\" to stop pair mode pauses")
+	}
+}
+
+func TestPairPauseDisabled(t *testing.T) {
+	a := &Agent{config: &config.Config{}}
+	// A nil workContext proves nothing is touched when pair mode is off
+	if err := a.pairPause(nil, "execute"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,129 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/philjestin/boatmanmode/internal/checkpoint"
+	"github.com/philjestin/boatmanmode/internal/events"
+	"github.com/philjestin/boatmanmode/internal/lint"
+	"github.com/philjestin/boatmanmode/internal/pipeline"
+	"github.com/philjestin/boatmanmode/internal/scottbott"
+	"github.com/philjestin/boatmanmode/internal/unidiff"
+)
+
+// runsStep reports whether the configured pipeline includes the stage
+// step belongs to. The prepare and worktree steps always run.
+func (a *Agent) runsStep(step checkpoint.Step) bool {
+	switch step {
+	case checkpoint.StepPlanning, checkpoint.StepPlanReview, checkpoint.StepValidation:
+		return a.stages.Has(pipeline.Plan)
+	case checkpoint.StepLint:
+		return a.stages.Has(pipeline.Lint)
+	case checkpoint.StepTesting:
+		// Tests and the initial review run side by side in one step
+		return a.stages.Has(pipeline.Test) || a.stages.Has(pipeline.Review)
+	case checkpoint.StepReview:
+		return a.stages.Has(pipeline.Refactor)
+	case checkpoint.StepCommit:
+		return a.stages.Has(pipeline.Commit)
+	case checkpoint.StepPush, checkpoint.StepCreatePR:
+		return a.stages.Has(pipeline.PR)
+	}
+	return true
+}
+
+// skipReview stands in for the review of a pipeline without one: the
+// change passes as executed, described by the executor's summary.
+func (a *Agent) skipReview(wc *workContext) {
+	if wc.reviewResult != nil || a.stages.Has(pipeline.Review) {
+		return
+	}
+	wc.reviewResult = &scottbott.ReviewResult{Passed: true, Summary: wc.execResult.Summary}
+}
+
+// finishPipeline ends a run whose pipeline stops before the commit or the
+// PR, leaving the change in the worktree or on its branch.
+func (a *Agent) finishPipeline(wc *workContext) *WorkResult {
+	last := a.stages.Last()
+	wc.decisions.Record("pipeline", "end the run after "+string(last), "pipeline: "+a.stages.String())
+	fmt.Fprint(a.output(), wc.decisions.Format())
+
+	message := fmt.Sprintf("Pipeline ends after %s; the change is in %s", last, wc.worktree.Path)
+	if last == pipeline.Commit {
+		message = fmt.Sprintf("Pipeline ends after commit; the change is committed on %s", wc.branchName)
+	}
+	return &WorkResult{Message: message, Iterations: wc.iterations, TestsPassed: wc.testResult != nil && wc.testResult.Passed}
+}
+
+// stepLint lints the changed files. With review in the pipeline
+// violations are held against every review until they are fixed; without
+// it the run fails here.
+func (a *Agent) stepLint(ctx context.Context, wc *workContext) error {
+	a.printStep(5, 9, "Lint")
+	result := a.lint(ctx, wc)
+	if result.Passed {
+		fmt.Fprintf(a.output(), "   ✅ Lint passed (%s)\n", result.Summary())
+		return nil
+	}
+	if !a.stages.Has(pipeline.Review) {
+		if result.Failure != "" {
+			return fmt.Errorf("lint failed: %s", result.Failure)
+		}
+		return fmt.Errorf("lint failed: %s", result.Summary())
+	}
+	fmt.Fprintf(a.output(), "   ⚠️  Lint fails (%s); review will not pass until it is fixed\n", result.Summary())
+	return nil
+}
+
+// checkLint returns the lint violations in the changed files as review
+// issues, so the refactor loop fixes them.
+func (a *Agent) checkLint(ctx context.Context, wc *workContext) []scottbott.Issue {
+	result := a.lint(ctx, wc)
+	if result.Passed && len(result.Violations) == 0 {
+		return nil
+	}
+	if !result.Passed {
+		wc.decisions.Record("review", "fail review on lint violations", result.Summary())
+	}
+	return result.Issues()
+}
+
+// lint runs the linters on the files the change touches: commands.lint
+// when set, otherwise the linters the project is configured for. The
+// result stands until the change does.
+func (a *Agent) lint(ctx context.Context, wc *workContext) *lint.Result {
+	tree, _ := wc.exec.SnapshotTree()
+	if tree != "" && tree == wc.lintedTree && wc.lintResult != nil {
+		return wc.lintResult
+	}
+	var changed []string
+	if diff, err := wc.exec.GetDiff(); err == nil {
+		for _, f := range unidiff.Parse(diff) {
+			if f.Status != unidiff.Deleted {
+				changed = append(changed, f.Path())
+			}
+		}
+	}
+
+	agentID := fmt.Sprintf("lint-%s", wc.task.GetID())
+	linter := lint.New(wc.worktree.Path)
+	linter.SetCoordinator(a.coordinator)
+	linter.SetCommand(a.config.Commands.Lint)
+	events.AgentStarted(agentID, "Linting", "Linting changed files")
+	result, err := linter.Run(ctx, changed)
+	if err != nil {
+		events.AgentCompleted(agentID, "Linting", "failed")
+		return &lint.Result{Passed: true}
+	}
+	for _, skipped := range result.Skipped {
+		fmt.Fprintf(a.output(), "   ⚠️  Linter skipped: %s\n", skipped)
+	}
+	status := "success"
+	if !result.Passed {
+		status = "failed"
+	}
+	events.AgentCompleted(agentID, "Linting", status)
+	wc.lintedTree, wc.lintResult = tree, result
+	return result
+}
@@ -0,0 +1,190 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/events"
+	"github.com/philjestin/boatmanmode/internal/planner"
+)
+
+// errAwaitingApproval stops a run that paused for its plan to be approved.
+var errAwaitingApproval = errors.New("plan awaiting approval")
+
+// stdinIsTerminal reports whether an operator can answer prompts; tests
+// override it.
+var stdinIsTerminal = func() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// SetApprovePlan approves the plan of a run paused for plan review, as
+// edited in its plan file.
+func (a *Agent) SetApprovePlan(approve bool) {
+	a.approvePlan = approve
+}
+
+// stepReviewPlan has the operator approve the plan before execution
+// (review_plan). On a terminal they can approve, edit or reject it;
+// otherwise the plan is written to a file and the run pauses until it is
+// resumed with --approve-plan.
+func (a *Agent) stepReviewPlan(ctx context.Context, wc *workContext) error {
+	if !wc.reviewPlan && !a.approvePlan {
+		return nil
+	}
+	if wc.plan == nil {
+		fmt.Fprintln(a.output(), "   ⏭️  No plan to review")
+		fmt.Fprintln(a.output())
+		return nil
+	}
+	path := planFile(wc)
+
+	if a.approvePlan {
+		if path != "" {
+			if plan, err := readPlan(path); err == nil {
+				wc.plan = plan
+			} else if !errors.Is(err, os.ErrNotExist) {
+				return err
+			}
+		}
+		fmt.Fprintln(a.output(), "   ✅ Plan approved")
+		fmt.Fprintln(a.output())
+		wc.decisions.Record("plan_review", "execute the approved plan", "approved with boatman resume --approve-plan")
+		return nil
+	}
+
+	if a.input != nil || stdinIsTerminal() {
+		approved, err := a.promptPlan(wc, path)
+		if err != nil || approved {
+			return err
+		}
+		// Nobody answered; fall back to pausing
+	}
+
+	if wc.checkpoint == nil || path == "" {
+		return fmt.Errorf("cannot pause for plan approval without a checkpoint")
+	}
+	if err := writePlan(path, wc.plan); err != nil {
+		return err
+	}
+	id := wc.checkpoint.Current.ID
+	reason := fmt.Sprintf("plan awaiting approval: edit %s if needed, then run boatman resume %s --approve-plan", path, id)
+	wc.checkpoint.Pause(reason)
+	events.Progress("Waiting for plan approval")
+	a.printPlan(wc.plan)
+	fmt.Fprintf(a.output(), "   ⏸️  Paused for plan approval. The plan is in %s\n", path)
+	fmt.Fprintf(a.output(), "   Edit it if needed, then run: boatman resume %s --approve-plan\n", id)
+	fmt.Fprintln(a.output())
+	return errAwaitingApproval
+}
+
+// promptPlan shows the plan and asks the operator to approve, edit or
+// reject it. It returns false without an error if nobody answers.
+func (a *Agent) promptPlan(wc *workContext, path string) (bool, error) {
+	events.Progress("Waiting for plan approval")
+	for {
+		a.printPlan(wc.plan)
+		fmt.Fprint(a.output(), "   Approve the plan? [Y]es / [e]dit / [n]o: ")
+		line, err := a.pairInput().ReadString('\n')
+		if err != nil && line == "" {
+			fmt.Fprintln(a.output())
+			return false, nil
+		}
+		fmt.Fprintln(a.output())
+
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "", "y", "yes":
+			fmt.Fprintln(a.output(), "   ✅ Plan approved")
+			fmt.Fprintln(a.output())
+			wc.decisions.Record("plan_review", "execute the approved plan", "approved by the operator")
+			return true, nil
+		case "n", "no":
+			wc.decisions.Record("plan_review", "stop before execution", "the operator rejected the plan")
+			return false, fmt.Errorf("plan rejected by the operator")
+		case "e", "edit":
+			if path == "" {
+				path = filepath.Join(os.TempDir(), fmt.Sprintf("boatman-plan-%s.json", wc.task.GetID()))
+			}
+			plan, err := editPlan(path, wc.plan)
+			if err != nil {
+				fmt.Fprintf(a.output(), "   ⚠️  %v\n", err)
+				continue
+			}
+			wc.plan = plan
+			wc.decisions.Record("plan_review", "use the plan as edited", "the operator edited the plan before execution")
+		default:
+			fmt.Fprintln(a.output(), "   Answer y, e or n")
+		}
+	}
+}
+
+// editPlan writes plan to path, opens it in the operator's editor and
+// reads it back.
+func editPlan(path string, plan *planner.Plan) (*planner.Plan, error) {
+	if err := writePlan(path, plan); err != nil {
+		return nil, err
+	}
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+	args := append(strings.Fields(editor), path)
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("editor %s failed: %w", args[0], err)
+	}
+	return readPlan(path)
+}
+
+// planFile is where a run's plan is written for review, next to its
+// checkpoint; empty without one.
+func planFile(wc *workContext) string {
+	if wc.checkpoint == nil || wc.checkpoint.Current == nil {
+		return ""
+	}
+	return filepath.Join(wc.checkpoint.BaseDir, "plans", wc.checkpoint.Current.ID+".json")
+}
+
+func writePlan(path string, plan *planner.Plan) error {
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create plan directory: %w", err)
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+func readPlan(path string) (*planner.Plan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var plan planner.Plan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("invalid plan in %s: %w", path, err)
+	}
+	return &plan, nil
+}
+
+// printPlan renders the plan for review, indented under the step.
+func (a *Agent) printPlan(plan *planner.Plan) {
+	fmt.Fprintln(a.output(), "   📋 Plan for review:")
+	for _, line := range strings.Split(strings.TrimRight(plan.ToHandoff(), "\n"), "\n") {
+		fmt.Fprintf(a.output(), "      %s\n", line)
+	}
+	fmt.Fprintln(a.output())
+}
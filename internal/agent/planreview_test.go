@@ -0,0 +1,94 @@
+package agent
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/checkpoint"
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/decisionlog"
+	"github.com/philjestin/boatmanmode/internal/planner"
+)
+
+func newPlanReviewContext(t *testing.T) *workContext {
+	t.Helper()
+	cp, err := checkpoint.NewManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp.Start("ENG-1", 3)
+	return &workContext{
+		plan:       &planner.Plan{Summary: "Add retries", Approach: []string{"Wrap uploads in retry.Do"}},
+		reviewPlan: true,
+		checkpoint: cp,
+		decisions:  decisionlog.New(),
+	}
+}
+
+func TestReviewPlanPrompt(t *testing.T) {
+	tests := []struct {
+		answer  string
+		wantErr bool
+	}{
+		{"\n", false},
+		{"yes\n", false},
+		{"n\n", true},
+	}
+	for _, tt := range tests {
+		t.Run(strings.TrimSpace(tt.answer), func(t *testing.T) {
+			wc := newPlanReviewContext(t)
+			a := &Agent{config: &config.Config{ReviewPlan: true}, input: bufio.NewReader(strings.NewReader(tt.answer))}
+			err := a.stepReviewPlan(context.Background(), wc)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if wc.checkpoint.Current.Paused != "" {
+				t.Errorf("paused with an operator present: %s", wc.checkpoint.Current.Paused)
+			}
+		})
+	}
+}
+
+func TestReviewPlanPausesWithoutTerminal(t *testing.T) {
+	defer func(orig func() bool) { stdinIsTerminal = orig }(stdinIsTerminal)
+	stdinIsTerminal = func() bool { return false }
+
+	wc := newPlanReviewContext(t)
+	a := &Agent{config: &config.Config{ReviewPlan: true}}
+	if err := a.stepReviewPlan(context.Background(), wc); !errors.Is(err, errAwaitingApproval) {
+		t.Fatalf("err = %v, want errAwaitingApproval", err)
+	}
+	if !strings.Contains(wc.checkpoint.Current.Paused, "--approve-plan") {
+		t.Errorf("Paused = %q", wc.checkpoint.Current.Paused)
+	}
+
+	// The operator edits the plan file, then approves it
+	path := planFile(wc)
+	edited := `{"summary": "Add retries with backoff", "approach": ["Use retry.Do with backoff"]}`
+	if err := os.WriteFile(path, []byte(edited), 0644); err != nil {
+		t.Fatal(err)
+	}
+	a.SetApprovePlan(true)
+	if err := a.stepReviewPlan(context.Background(), wc); err != nil {
+		t.Fatal(err)
+	}
+	if wc.plan.Summary != "Add retries with backoff" {
+		t.Errorf("plan = %+v, want the edited plan", wc.plan)
+	}
+}
+
+func TestReviewPlanOff(t *testing.T) {
+	wc := newPlanReviewContext(t)
+	wc.reviewPlan = false
+	a := &Agent{config: &config.Config{}}
+	if err := a.stepReviewPlan(context.Background(), wc); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(planFile(wc)); !os.IsNotExist(err) {
+		t.Errorf("plan file written with review_plan off: %v", err)
+	}
+}
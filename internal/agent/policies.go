@@ -0,0 +1,332 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/bundlesize"
+	"github.com/philjestin/boatmanmode/internal/contracts"
+	"github.com/philjestin/boatmanmode/internal/featureflags"
+	"github.com/philjestin/boatmanmode/internal/graphqlschema"
+	"github.com/philjestin/boatmanmode/internal/memory"
+	"github.com/philjestin/boatmanmode/internal/migrations"
+	"github.com/philjestin/boatmanmode/internal/observability"
+	"github.com/philjestin/boatmanmode/internal/pipeline"
+	"github.com/philjestin/boatmanmode/internal/racecheck"
+	"github.com/philjestin/boatmanmode/internal/scottbott"
+	"github.com/philjestin/boatmanmode/internal/security"
+	"github.com/philjestin/boatmanmode/internal/snapshots"
+	"github.com/philjestin/boatmanmode/internal/sqlreview"
+)
+
+// applyPolicies adds the issues the repo's configured policies find in diff
+// to a fresh review. Critical and major findings fail the review so they
+// are fixed before the PR, like diff verification's pattern hits.
+func (a *Agent) applyPolicies(ctx context.Context, wc *workContext, result *scottbott.ReviewResult, diff string) {
+	var issues []scottbott.Issue
+	if wc.flags != nil {
+		issues = append(issues, wc.flags.Issues(diff)...)
+	}
+	if wc.instrumented != nil {
+		issues = append(issues, wc.instrumented.Issues(diff)...)
+	}
+	if a.config.Review.RaceCheck {
+		issues = append(issues, a.checkRaces(ctx, wc, diff)...)
+	}
+	if wc.bundle != nil {
+		issues = append(issues, a.checkBundle(ctx, wc)...)
+	}
+	if wc.snapshots != nil {
+		issues = append(issues, wc.snapshots.Issues(diff, wc.snapReasons)...)
+	}
+	if checker := sqlreview.New(wc.worktree.Path, a.config.Review.SQL); checker != nil {
+		issues = append(issues, a.checkSQL(ctx, wc, checker, diff)...)
+	}
+	if runner := migrations.New(wc.worktree.Path, a.config.Review.Migrations); runner != nil {
+		issues = append(issues, a.checkMigrations(ctx, wc, runner, diff)...)
+	}
+	if verifier := contracts.New(wc.worktree.Path, a.config.Review.Contracts); verifier != nil {
+		issues = append(issues, a.checkContracts(ctx, wc, verifier, diff)...)
+	}
+	if checker := graphqlschema.New(wc.worktree.Path, a.config.Review.GraphQL); checker != nil {
+		issues = append(issues, a.checkGraphQL(ctx, wc, checker, diff)...)
+	}
+	if a.config.E2E.Enabled {
+		issues = append(issues, a.checkE2E(ctx, wc, diff)...)
+	}
+	if a.stages.Has(pipeline.Lint) {
+		issues = append(issues, a.checkLint(ctx, wc)...)
+	}
+	if a.gatesCoverage() {
+		issues = append(issues, a.checkCoverage(ctx, wc)...)
+	}
+	if scanner := security.New(wc.worktree.Path, a.config.Review.Security); scanner != nil {
+		issues = append(issues, a.checkSecurity(ctx, wc, scanner)...)
+	}
+	for _, issue := range issues {
+		result.Issues = append(result.Issues, issue)
+		if issue.Severity == "critical" || issue.Severity == "major" {
+			result.Passed = false
+		}
+	}
+	if len(issues) > 0 {
+		fmt.Fprintf(a.output(), "   📐 Policies: %d issue(s) added to review\n", len(issues))
+	}
+}
+
+// checkRaces runs the race detector and racy-pattern scan on the diff.
+// A race detector that cannot run only warns; the scan's findings stand.
+func (a *Agent) checkRaces(ctx context.Context, wc *workContext, diff string) []scottbott.Issue {
+	fmt.Fprintln(a.output(), "   🏁 Checking changed Go code for data races...")
+	issues, err := racecheck.New(wc.worktree.Path).Check(ctx, diff)
+	if err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Race detector unavailable: %v\n", err)
+	}
+	if len(issues) > 0 {
+		wc.decisions.Record("review", "fail review on concurrency findings",
+			fmt.Sprintf("%d data race or racy pattern finding(s) under review.race_check", len(issues)))
+	}
+	return issues
+}
+
+// checkSQL explains the diff's queries and runs the SQL analyzer. A tool
+// that cannot run only warns.
+func (a *Agent) checkSQL(ctx context.Context, wc *workContext, checker *sqlreview.Checker, diff string) []scottbott.Issue {
+	if !sqlreview.TouchesSQL(diff) {
+		return nil
+	}
+	fmt.Fprintln(a.output(), "   🗄️  Reviewing changed SQL queries...")
+	issues, err := checker.Check(ctx, diff)
+	if err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  SQL review incomplete: %v\n", err)
+	}
+	if len(issues) > 0 {
+		wc.decisions.Record("review", "add SQL query findings to review",
+			fmt.Sprintf("%d finding(s) from EXPLAIN or the SQL analyzer under review.sql", len(issues)))
+	}
+	return issues
+}
+
+// checkMigrations dry-runs the diff's new migrations against an ephemeral
+// database. A database that cannot start only warns.
+func (a *Agent) checkMigrations(ctx context.Context, wc *workContext, runner *migrations.Runner, diff string) []scottbott.Issue {
+	added := runner.NewMigrations(diff)
+	if len(added) == 0 {
+		return nil
+	}
+	fmt.Fprintf(a.output(), "   🐘 Dry-running %d new migration(s) against an ephemeral database...\n", len(added))
+	issues, err := runner.Check(ctx, diff)
+	if err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Migration dry run skipped: %v\n", err)
+		return nil
+	}
+	if len(issues) > 0 {
+		wc.decisions.Record("review", "fail review on migration dry run",
+			fmt.Sprintf("%d new migration(s) failed to apply or roll back under review.migrations", len(added)))
+	}
+	return issues
+}
+
+// checkContracts verifies the provider against its consumers' pacts when
+// the diff changes API handlers. The result stands until the change does;
+// a verifier that cannot run only warns.
+func (a *Agent) checkContracts(ctx context.Context, wc *workContext, verifier *contracts.Verifier, diff string) []scottbott.Issue {
+	tree, _ := wc.exec.SnapshotTree()
+	if tree != "" && tree == wc.verifiedTree {
+		return wc.pactIssues
+	}
+	handlers := verifier.Handlers(diff)
+	if len(handlers) == 0 {
+		wc.verifiedTree, wc.pactIssues = tree, nil
+		return nil
+	}
+	fmt.Fprintf(a.output(), "   🤝 Verifying consumer pacts (%d API handler file(s) changed)...\n", len(handlers))
+	failures, err := verifier.Verify(ctx)
+	if err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Pact verification skipped: %v\n", err)
+		return nil
+	}
+	issues := contracts.Issues(failures, handlers[0])
+	wc.verifiedTree, wc.pactIssues = tree, issues
+	if len(issues) > 0 {
+		wc.decisions.Record("review", "fail review on broken pacts",
+			fmt.Sprintf("provider verification failed for %d consumer(s) under review.contracts", len(issues)))
+	}
+	return issues
+}
+
+// checkGraphQL classifies the diff's schema changes. Breaking changes fail
+// the review unless the ticket carries review.graphql.allow_label. An
+// inspector that cannot run only warns. The report stands until the change
+// does.
+func (a *Agent) checkGraphQL(ctx context.Context, wc *workContext, checker *graphqlschema.Checker, diff string) []scottbott.Issue {
+	tree, _ := wc.exec.SnapshotTree()
+	if tree != "" && tree == wc.schemaTree {
+		if wc.schemaReport == nil {
+			return nil
+		}
+		return wc.schemaReport.Issues()
+	}
+	schemas := checker.Schemas(diff)
+	if len(schemas) == 0 {
+		wc.schemaTree, wc.schemaReport = tree, nil
+		return nil
+	}
+	fmt.Fprintf(a.output(), "   🧬 Diffing %d GraphQL schema file(s)...\n", len(schemas))
+	report, err := checker.Check(ctx, wc.baseCommit, diff)
+	if err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  GraphQL schema check skipped: %v\n", err)
+		wc.schemaTree, wc.schemaReport = "", nil
+		return nil
+	}
+	for _, label := range wc.task.GetLabels() {
+		if strings.EqualFold(label, report.AllowLabel) {
+			report.Allowed = true
+		}
+	}
+	wc.schemaTree, wc.schemaReport = tree, report
+
+	breaking := len(report.Breaking())
+	switch {
+	case breaking > 0 && report.Allowed:
+		wc.decisions.Record("review", "allow breaking schema changes",
+			fmt.Sprintf("%d breaking change(s); the ticket is labeled %q", breaking, report.AllowLabel))
+	case breaking > 0:
+		wc.decisions.Record("review", "fail review on breaking schema changes",
+			fmt.Sprintf("%d breaking change(s) and no %q label on the ticket", breaking, report.AllowLabel))
+	}
+	return report.Issues()
+}
+
+// checkBundle rebuilds the bundle and compares it with the baseline. A
+// failed build only warns; the tests and review catch broken builds.
+func (a *Agent) checkBundle(ctx context.Context, wc *workContext) []scottbott.Issue {
+	fmt.Fprintln(a.output(), "   📦 Measuring bundle size...")
+	report, err := wc.bundle.Check(ctx)
+	if err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Bundle check skipped: %v\n", err)
+		return nil
+	}
+	wc.bundleReport = report
+	fmt.Fprintf(a.output(), "   📦 Bundle: %s → %s (%+.1f%%)\n",
+		bundlesize.FormatSize(report.Before), bundlesize.FormatSize(report.After), report.Growth())
+	if len(report.Issues) > 0 {
+		wc.decisions.Record("review", "fail review on bundle size",
+			fmt.Sprintf("%d bundle budget finding(s)", len(report.Issues)))
+	}
+	return report.Issues
+}
+
+// setupBundle measures the bundle before the change when bundle.build is
+// set, so reviews can compare against it.
+func (a *Agent) setupBundle(ctx context.Context, wc *workContext) {
+	checker, err := bundlesize.New(wc.worktree.Path, a.config.Bundle)
+	if err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Bundle checks disabled: %v\n", err)
+		return
+	}
+	if checker == nil {
+		return
+	}
+	fmt.Fprintln(a.output(), "   📦 Building bundle for a size baseline...")
+	if err := checker.SetBaseline(ctx); err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Bundle checks disabled: %v\n", err)
+		return
+	}
+	wc.bundle = checker
+}
+
+// setupSnapshots gives the executor the snapshot update policy when
+// review.snapshots.max_lines is set.
+func (a *Agent) setupSnapshots(wc *workContext) {
+	policy := snapshots.New(a.config.Review.Snapshots)
+	if policy == nil {
+		return
+	}
+	wc.snapshots = policy
+	wc.exec.SetSnapshotPolicy(policy.Guidance())
+}
+
+// addSnapshotReasons keeps the justifications from each execution and
+// refactor, so a refactor that leaves a snapshot alone keeps its reason.
+func (wc *workContext) addSnapshotReasons(reasons map[string]string) {
+	if len(reasons) == 0 {
+		return
+	}
+	if wc.snapReasons == nil {
+		wc.snapReasons = map[string]string{}
+	}
+	for file, reason := range reasons {
+		wc.snapReasons[file] = reason
+	}
+}
+
+// setupFeatureFlags gives the executor the repo's feature-flag guidance
+// when feature_flags.system is set.
+func (a *Agent) setupFeatureFlags(wc *workContext) {
+	flags, err := featureflags.New(wc.worktree.Path, a.config.FeatureFlags)
+	if err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Feature flags disabled: %v\n", err)
+		return
+	}
+	if flags == nil {
+		return
+	}
+	wc.flags = flags
+	wc.exec.SetFeatureFlags(flags.Guidance())
+}
+
+// setupObservability enables the review.observability policy, with
+// examples from the project's rules and memory.
+func (a *Agent) setupObservability(wc *workContext) {
+	checker, err := observability.New(a.config.Review.Observability)
+	if err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Observability policy disabled: %v\n", err)
+		return
+	}
+	if checker == nil {
+		return
+	}
+	var patterns []memory.Pattern
+	if store, err := memory.NewStore(""); err == nil {
+		if mem, err := store.Get(wc.repoPath); err == nil {
+			patterns = mem.Patterns
+		}
+	}
+	checker.SetExamples(wc.exec.LoadProjectRules(), patterns)
+	wc.instrumented = checker
+}
+
+// bundleSection reports the bundle size change, for the PR body.
+func bundleSection(wc *workContext) string {
+	if wc.bundleReport == nil {
+		return ""
+	}
+	return wc.bundleReport.Markdown()
+}
+
+// graphQLSection lists the schema changes, for the PR body.
+func graphQLSection(wc *workContext) string {
+	if wc.schemaReport == nil {
+		return ""
+	}
+	return wc.schemaReport.Markdown()
+}
+
+// snapshotsSection lists the snapshot updates and why they were made, for
+// the PR body.
+func snapshotsSection(wc *workContext) string {
+	if wc.snapshots == nil || wc.finalDiff == "" {
+		return ""
+	}
+	return wc.snapshots.Markdown(wc.finalDiff, wc.snapReasons)
+}
+
+// featureFlagsSection lists the flags gating the change, for the PR body.
+func featureFlagsSection(wc *workContext) string {
+	if wc.flags == nil || wc.finalDiff == "" {
+		return ""
+	}
+	return wc.flags.Markdown(wc.finalDiff)
+}
@@ -0,0 +1,130 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/philjestin/boatmanmode/internal/localize"
+	"github.com/philjestin/boatmanmode/internal/prsections"
+	"github.com/philjestin/boatmanmode/internal/task"
+	"github.com/philjestin/boatmanmode/internal/testrunner"
+)
+
+// buildPRBody formats the PR body for the task's source, in pr.language.
+// Fails when a required section from pr.sections cannot be filled.
+func (a *Agent) buildPRBody(ctx context.Context, wc *workContext) (string, error) {
+	language := a.config.PR.Language
+	l := localize.LabelsFor(language)
+	metadata := wc.task.GetMetadata()
+	requiredSections, err := a.writePRSections(ctx, wc)
+	if err != nil {
+		return "", err
+	}
+	extraSections := a.acceptanceSection(wc) + a.buildImpactSection(wc) + protectedPathsSection(wc) + humanEditsSection(wc) + featureFlagsSection(wc) + bundleSection(wc) + graphQLSection(wc) + snapshotsSection(wc) + requiredSections
+
+	var header string
+	description := wc.task.GetDescription()
+	if metadata.Source == task.SourceLinear {
+		// Linear mode - include ticket link
+		header = fmt.Sprintf("### %s\n[%s](https://linear.app/issue/%s)", l.Ticket, wc.task.GetID(), wc.task.GetID())
+	} else if jt, ok := wc.task.(*task.JiraTask); ok {
+		header = fmt.Sprintf("### %s\n[%s](%s)", l.Ticket, jt.GetID(), jt.GetTicket().URL)
+	} else if at, ok := wc.task.(*task.AzureTask); ok {
+		// AB#123 also links the work item from GitHub PRs
+		header = fmt.Sprintf("### %s\n[AB#%s](%s)", l.Ticket, at.GetID(), at.GetWorkItem().URL)
+	} else {
+		// Prompt/File mode - no ticket link
+		taskType := l.PromptTask
+		if metadata.Source == task.SourceFile {
+			taskType = l.FileTask
+		}
+		header = fmt.Sprintf("### %s\n%s (%s)", l.Task, taskType, wc.task.GetID())
+		description = truncate(description, 500)
+	}
+
+	body := fmt.Sprintf(`## %s
+
+%s
+
+### %s
+%s
+
+### %s
+%s
+
+%s### %s
+- %s: %d
+- %s: %s
+- %s: %.1f%%
+%s
+---
+*%s*
+`,
+		wc.task.GetTitle(),
+		header,
+		l.Description,
+		description,
+		l.Changes,
+		wc.reviewResult.Summary,
+		extraSections,
+		l.Quality,
+		l.ReviewIterations,
+		wc.iterations,
+		l.Tests,
+		formatTestStatus(wc.testResult),
+		l.Coverage,
+		getTestCoverage(wc.testResult),
+		e2eStatus(wc, l),
+		l.Footer,
+	)
+
+	if !localize.IsEnglish(language) {
+		body = a.translate(ctx, wc, body, language)
+	}
+	return body + a.manifestComment(wc), nil
+}
+
+// writePRSections has the agent fill the sections pr.sections requires.
+func (a *Agent) writePRSections(ctx context.Context, wc *workContext) (string, error) {
+	sections := a.config.PR.Sections
+	if len(sections) == 0 {
+		return "", nil
+	}
+	fmt.Fprintf(a.output(), "   📋 Writing %d required PR section(s)...\n", len(sections))
+	run := prsections.Run{
+		Title:         wc.task.GetTitle(),
+		Description:   wc.task.GetDescription(),
+		FilesChanged:  wc.execResult.FilesChanged,
+		Diff:          wc.finalDiff,
+		ReviewSummary: wc.reviewResult.Summary,
+	}
+	if wc.plan != nil {
+		run.PlanSummary = wc.plan.Summary
+	}
+	if wc.testResult != nil {
+		run.TestSummary = (&testrunner.TestResultHandoff{Result: wc.testResult}).Concise()
+	}
+	filled, usage, err := prsections.New(wc.worktree.Path, a.config).Write(ctx, sections, run)
+	if usage != nil {
+		wc.costTracker.Add("PR Sections", *usage)
+	}
+	if err != nil {
+		return "", err
+	}
+	return prsections.Markdown(sections, filled), nil
+}
+
+// translate has the model translate text into language, returning text
+// unchanged if it cannot: an untranslated PR beats no PR.
+func (a *Agent) translate(ctx context.Context, wc *workContext, text, language string) string {
+	fmt.Fprintf(a.output(), "   🌐 Translating into %s...\n", language)
+	translated, usage, err := localize.New(wc.worktree.Path, a.config).Translate(ctx, text, language)
+	if usage != nil {
+		wc.costTracker.Add("Translate", *usage)
+	}
+	if err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Translation failed, keeping English: %v\n", err)
+		return text
+	}
+	return translated
+}
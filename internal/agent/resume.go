@@ -0,0 +1,162 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/checkpoint"
+	"github.com/philjestin/boatmanmode/internal/contextpin"
+	"github.com/philjestin/boatmanmode/internal/cost"
+	"github.com/philjestin/boatmanmode/internal/executor"
+	"github.com/philjestin/boatmanmode/internal/gitops"
+	"github.com/philjestin/boatmanmode/internal/planner"
+	"github.com/philjestin/boatmanmode/internal/scottbott"
+	"github.com/philjestin/boatmanmode/internal/task"
+	"github.com/philjestin/boatmanmode/internal/worktree"
+)
+
+// Resume continues the interrupted run in cp's current checkpoint from
+// its resume point, in the worktree it left behind. The steps it skips
+// are rebuilt from their checkpoint outputs; t is the run's task.
+func (a *Agent) Resume(ctx context.Context, cp *checkpoint.Manager, t task.Task) (*WorkResult, error) {
+	run := cp.Current
+	if run == nil {
+		return nil, fmt.Errorf("no checkpoint to resume")
+	}
+	if !run.CanResume() {
+		return nil, fmt.Errorf("run %s cannot be resumed (at %s)", run.ID, run.CurrentStep)
+	}
+	from := run.GetResumePoint()
+	unlock, err := cp.Lock(run.TicketID, run.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	wc := newWorkContext(t)
+	wc.checkpoint = cp
+	wc.iterations = run.Iteration
+	// A run paused for plan approval is asked again unless approved
+	wc.reviewPlan = a.config.ReviewPlan || run.CurrentStep == checkpoint.StepPlanReview && run.Paused != ""
+	if a.approve && run.Paused != "" {
+		if run.CurrentStep == checkpoint.StepPlanReview {
+			a.approvePlan = true
+		} else {
+			wc.gateApproved = true
+		}
+	}
+	if err := a.restore(wc, run, from); err != nil {
+		return nil, err
+	}
+	// Execution still needs the error context, specs and media planning fetched
+	if checkpoint.Before(checkpoint.StepPlanning, from) && !checkpoint.Before(checkpoint.StepExecution, from) {
+		wc.errorContext = a.errorContext(ctx, wc)
+		wc.specs = a.linkedSpecs(ctx, wc)
+		wc.media = a.ticketMedia(ctx, wc)
+	}
+	fmt.Fprintf(a.output(), "⏯️  Resuming %s from %s\n", run.ID, from)
+	wc.decisions.Record("resume", fmt.Sprintf("resume from %s", from),
+		fmt.Sprintf("checkpoint %s completed the steps before it", run.ID))
+
+	run.Error = ""
+	run.Paused = ""
+	run.Cancelled = ""
+	run.PID = os.Getpid()
+	if run.CostUSD > 0 {
+		wc.costTracker.Add("Before resume", cost.Usage{TotalCostUSD: run.CostUSD})
+	}
+	return a.run(ctx, wc, from)
+}
+
+// restore rebuilds the state of the steps before from.
+func (a *Agent) restore(wc *workContext, run *checkpoint.Checkpoint, from checkpoint.Step) error {
+	if !checkpoint.Before(checkpoint.StepCreateWorktree, from) {
+		return nil
+	}
+	if run.WorktreePath == "" {
+		return fmt.Errorf("run %s has no worktree to resume in", run.ID)
+	}
+	if _, err := os.Stat(run.WorktreePath); err != nil {
+		return fmt.Errorf("worktree %s is gone; start again with boatman work", run.WorktreePath)
+	}
+	var rec WorktreeRecord
+	if _, err := run.StepOutput(checkpoint.StepCreateWorktree, &rec); err != nil {
+		return err
+	}
+	if rec.RepoPath == "" {
+		rec.RepoPath, _ = os.Getwd()
+	}
+	git := gitops.New(run.WorktreePath)
+	if rec.BaseCommit == "" {
+		out, _ := git.Run("merge-base", "HEAD", a.config.BaseBranch)
+		rec.BaseCommit = strings.TrimSpace(out)
+	}
+	wc.repoPath = rec.RepoPath
+	wc.worktree = &worktree.Worktree{
+		Path:       run.WorktreePath,
+		BranchName: run.BranchName,
+		BaseBranch: a.config.BaseBranch,
+		Sparse:     git.IsSparse(),
+	}
+	wc.branchName = run.BranchName
+	wc.baseCommit = rec.BaseCommit
+	wc.pinner = contextpin.New(run.WorktreePath)
+	wc.pinner.SetCoordinator(a.coordinator)
+	fmt.Fprintf(a.output(), "   📁 Worktree: %s (%s)\n", run.WorktreePath, run.BranchName)
+
+	if checkpoint.Before(checkpoint.StepPlanning, from) {
+		wc.failureModes = loadFailureModes(wc.repoPath)
+		// The plan as the operator approved it, else as planned
+		for _, step := range []checkpoint.Step{checkpoint.StepPlanReview, checkpoint.StepPlanning} {
+			if !checkpoint.Before(step, from) {
+				continue
+			}
+			var plan planner.Plan
+			if ok, err := run.StepOutput(step, &plan); err != nil {
+				return err
+			} else if ok {
+				wc.plan = &plan
+				break
+			}
+		}
+	}
+
+	if !checkpoint.Before(checkpoint.StepExecution, from) {
+		return nil
+	}
+	var exec ExecutionRecord
+	if ok, err := run.StepOutput(checkpoint.StepExecution, &exec); err != nil {
+		return err
+	} else if !ok {
+		return fmt.Errorf("run %s has no execution output to resume from", run.ID)
+	}
+	wc.execResult = &executor.ExecutionResult{
+		Success:      true,
+		FilesChanged: exec.FilesChanged,
+		Renames:      exec.Renames,
+		Summary:      exec.Summary,
+	}
+	wc.humanEdited = exec.HumanEdited
+	a.setupExecutor(wc)
+
+	var review ReviewRecord
+	if ok, err := run.StepOutput(checkpoint.StepReview, &review); err != nil {
+		return err
+	} else if ok && checkpoint.Before(checkpoint.StepReview, from) {
+		wc.reviewResult = review.ReviewResult
+		wc.reviews = review.History
+		return nil
+	}
+	if checkpoint.Before(checkpoint.StepTesting, from) {
+		var initial scottbott.ReviewResult
+		if ok, err := run.StepOutput(checkpoint.StepTesting, &initial); err != nil {
+			return err
+		} else if ok {
+			wc.reviewResult = &initial
+			wc.reviews = []*scottbott.ReviewResult{&initial}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/forge"
+	"github.com/philjestin/boatmanmode/internal/reviewers"
+)
+
+// requestReviewers asks the reviewers pr.reviewers picks to review the PR
+// at url and records the assignment for the rotation. Failures only warn;
+// the PR exists either way.
+func (a *Agent) requestReviewers(ctx context.Context, wc *workContext, host forge.Forge, url string) {
+	cfg := a.config.PR.Reviewers
+	if cfg.Strategy == "" {
+		return
+	}
+	history, err := reviewers.LoadHistory("")
+	if err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Could not load reviewer history: %v\n", err)
+		return
+	}
+	var files []string
+	if changes, err := wc.exec.Git().ChangesSince(wc.baseCommit); err == nil {
+		for _, c := range changes {
+			files = append(files, c.Path)
+		}
+	}
+	picked, err := reviewers.Select(cfg, history, wc.worktree.Path, files, time.Now())
+	if err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Could not pick reviewers: %v\n", err)
+		return
+	}
+	if len(picked) == 0 {
+		fmt.Fprintln(a.output(), "   ⚠️  No reviewers to request (check pr.reviewers)")
+		return
+	}
+	if err := host.RequestReviewers(ctx, url, picked); err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Could not request reviewers: %v\n", err)
+		return
+	}
+	fmt.Fprintf(a.output(), "   👥 Requested review from %s\n", strings.Join(picked, ", "))
+	wc.decisions.Record("pr", "requested review from "+strings.Join(picked, ", "),
+		fmt.Sprintf("pr.reviewers.strategy is %q", cfg.Strategy))
+
+	history.Record(url, picked, time.Now())
+	if err := history.Save(); err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Could not save reviewer history: %v\n", err)
+	}
+}
@@ -0,0 +1,67 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/scottbott"
+	"github.com/philjestin/boatmanmode/internal/security"
+)
+
+// scanSecurity scans the change for secrets and dangerous code. The
+// result stands until the change does; a scan that fails only warns.
+func (a *Agent) scanSecurity(ctx context.Context, wc *workContext, scanner *security.Scanner) *security.Result {
+	tree, _ := wc.exec.SnapshotTree()
+	if tree != "" && tree == wc.scannedTree && wc.scanResult != nil {
+		return wc.scanResult
+	}
+	diff, err := wc.exec.GetDiff()
+	if err != nil {
+		return nil
+	}
+	fmt.Fprintln(a.output(), "   🔐 Scanning the change for secrets and dangerous code...")
+	result, err := scanner.Scan(ctx, diff)
+	if err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  Security scan failed: %v\n", err)
+		return nil
+	}
+	for _, skipped := range result.Skipped {
+		fmt.Fprintf(a.output(), "   ⚠️  Security scanner skipped: %s\n", skipped)
+	}
+	wc.scannedTree, wc.scanResult = tree, result
+	return result
+}
+
+// checkSecurity returns the scan's findings as review issues, so the
+// refactor loop removes them.
+func (a *Agent) checkSecurity(ctx context.Context, wc *workContext, scanner *security.Scanner) []scottbott.Issue {
+	result := a.scanSecurity(ctx, wc, scanner)
+	if result == nil || len(result.Findings) == 0 {
+		return nil
+	}
+	wc.decisions.Record("review", "add security findings to review", result.Summary()+" under review.security")
+	return result.Issues()
+}
+
+// enforceSecurity blocks the commit while the change has critical
+// findings, such as a hard-coded secret, whether or not review ran.
+func (a *Agent) enforceSecurity(ctx context.Context, wc *workContext) error {
+	scanner := security.New(wc.worktree.Path, a.config.Review.Security)
+	if scanner == nil {
+		return nil
+	}
+	result := a.scanSecurity(ctx, wc, scanner)
+	if result == nil {
+		return nil
+	}
+	var critical []string
+	for _, f := range result.Critical() {
+		critical = append(critical, f.String())
+	}
+	if len(critical) == 0 {
+		return nil
+	}
+	wc.decisions.Record("commit", "block the commit on security findings", strings.Join(critical, "; "))
+	return fmt.Errorf("security scan blocks the commit: %s", strings.Join(critical, "; "))
+}
@@ -0,0 +1,35 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/specs"
+)
+
+// linkedSpecs fetches the Notion, Google Docs and Confluence pages the
+// ticket's description links to, and summarizes them within
+// specs.max_tokens for the planning and execution prompts.
+func (a *Agent) linkedSpecs(ctx context.Context, wc *workContext) string {
+	if a.specClient == nil {
+		return ""
+	}
+	docs := a.specClient.FetchAll(ctx, wc.task.GetDescription(), func(format string, args ...any) {
+		fmt.Fprintf(a.output(), "   ⚠️  "+format+"\n", args...)
+	})
+	if len(docs) == 0 {
+		return ""
+	}
+	titles := make([]string, len(docs))
+	for i, doc := range docs {
+		titles[i] = doc.Title
+		if titles[i] == "" {
+			titles[i] = doc.URL
+		}
+	}
+	fmt.Fprintf(a.output(), "   📚 Added %d linked spec(s): %s\n", len(docs), strings.Join(titles, ", "))
+	wc.decisions.Record("planning", "add the linked specs to the planning and execution prompts",
+		fmt.Sprintf("the ticket links %s", strings.Join(titles, ", ")))
+	return specs.Summarize(docs, a.config.Specs.MaxTokens)
+}
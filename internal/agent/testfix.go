@@ -0,0 +1,80 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/philjestin/boatmanmode/internal/executor"
+	"github.com/philjestin/boatmanmode/internal/testrunner"
+)
+
+// fixFailingTests hands failing tests back to Claude and re-runs them
+// until they pass or max_test_fix_attempts is reached (Step 6). Tests
+// still failing go to review as they are.
+func (a *Agent) fixFailingTests(ctx context.Context, wc *workContext, runner *testrunner.Agent) {
+	for attempt := 1; attempt <= a.config.MaxTestFixAttempts; attempt++ {
+		if wc.testResult == nil || wc.testResult.Passed {
+			break
+		}
+		if err := a.checkBudget(wc, "fixing tests"); err != nil {
+			fmt.Fprintf(a.output(), "   ⚠️  Not fixing tests: %v\n", err)
+			break
+		}
+
+		fmt.Fprintf(a.output(), "   🩹 Fixing failing tests (attempt %d/%d): %s\n", attempt, a.config.MaxTestFixAttempts,
+			(&testrunner.TestResultHandoff{Result: wc.testResult}).Concise())
+		fixer := executor.NewTestFixExecutor(wc.worktree.Path, attempt, a.config)
+		fixer.SetOutput(a.out)
+		fixer.SetProxy(wc.proxyEnv())
+		result, usage, err := fixer.FixTests(ctx, wc.task, wc.testResult, wc.execResult.FilesChanged)
+		if usage != nil {
+			wc.costTracker.Add(fmt.Sprintf("Test fix #%d", attempt), *usage)
+		}
+		if err != nil || !result.Success {
+			if err == nil {
+				err = result.Error
+			}
+			fmt.Fprintf(a.output(), "   ⚠️  Test fix failed: %v\n", err)
+			break
+		}
+		wc.testFixes = attempt
+		wc.execResult.FilesChanged = mergeFiles(wc.execResult.FilesChanged, result.FilesChanged)
+		for file, from := range result.Renames {
+			if wc.execResult.Renames == nil {
+				wc.execResult.Renames = make(map[string]string)
+			}
+			wc.execResult.Renames[file] = from
+		}
+
+		runner.SetRenames(wc.execResult.Renames)
+		if diff, err := wc.exec.GetDiff(); err == nil {
+			runner.SetDiff(diff)
+		}
+		wc.testResult, _ = runner.RunForFiles(ctx, wc.execResult.FilesChanged)
+		emitTestResults(wc.testResult)
+	}
+
+	if wc.testFixes > 0 && wc.testResult != nil {
+		if wc.testResult.Passed {
+			fmt.Fprintf(a.output(), "   ✅ Tests fixed after %d attempt(s)\n", wc.testFixes)
+		} else {
+			wc.decisions.Record("testing", "review with failing tests",
+				fmt.Sprintf("tests still failed after %d fix attempt(s)", wc.testFixes))
+		}
+	}
+}
+
+// mergeFiles adds the files in more that files does not list.
+func mergeFiles(files, more []string) []string {
+	seen := make(map[string]bool, len(files))
+	for _, f := range files {
+		seen[f] = true
+	}
+	for _, f := range more {
+		if !seen[f] {
+			seen[f] = true
+			files = append(files, f)
+		}
+	}
+	return files
+}
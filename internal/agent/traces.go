@@ -0,0 +1,56 @@
+package agent
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/gitops"
+	"github.com/philjestin/boatmanmode/internal/stacktrace"
+	"github.com/philjestin/boatmanmode/internal/task"
+)
+
+// maxTraceDownloads caps the uploads downloaded from a ticket.
+const maxTraceDownloads = 5
+
+// stackLocations finds the repo locations behind the stack traces and log
+// snippets of the ticket: pasted into its description, or uploaded to
+// Linear and attached.
+func (a *Agent) stackLocations(ctx context.Context, wc *workContext) []stacktrace.Location {
+	// Sentry frames are written as file:line too
+	texts := []string{wc.task.GetDescription(), wc.errorContext}
+	if lt, ok := wc.task.(*task.LinearTask); ok && a.linearClient != nil {
+		for i, url := range lt.GetTicket().UploadURLs() {
+			if i == maxTraceDownloads {
+				break
+			}
+			data, err := a.linearClient.Download(ctx, url)
+			if err != nil {
+				fmt.Fprintf(a.output(), "   ⚠️  Could not download attachment: %v\n", err)
+				continue
+			}
+			// Screenshots and other binary uploads have no frames
+			if bytes.IndexByte(data, 0) >= 0 {
+				continue
+			}
+			texts = append(texts, string(data))
+		}
+	}
+
+	frames := stacktrace.Parse(strings.Join(texts, "\n"))
+	if len(frames) == 0 {
+		return nil
+	}
+	out, err := gitops.New(wc.worktree.Path).Run("ls-files")
+	if err != nil {
+		return nil
+	}
+	locations := stacktrace.Resolve(frames, strings.Split(out, "\n"))
+	if len(locations) > 0 {
+		fmt.Fprintf(a.output(), "   🧵 Stack traces point at %d location(s), e.g. %s\n", len(locations), locations[0])
+		wc.decisions.Record("planning", "seed the plan with stack trace locations",
+			fmt.Sprintf("the ticket's traces reference %s", strings.Join(stacktrace.Files(locations), ", ")))
+	}
+	return locations
+}
@@ -0,0 +1,200 @@
+// Package analytics exports run history and memory stats from every repo
+// boatman has worked in, in a normalized schema for BI tools.
+package analytics
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/forge"
+	"github.com/philjestin/boatmanmode/internal/memory"
+)
+
+// SchemaVersion is bumped when columns change meaning or are removed.
+// Adding columns at the end does not bump it.
+const SchemaVersion = 1
+
+// Export formats.
+const (
+	FormatCSV   = "csv"
+	FormatJSONL = "jsonl"
+)
+
+// RunRow is one session: a task worked to a final review.
+type RunRow struct {
+	SchemaVersion int       `json:"schema_version"`
+	Repo          string    `json:"repo"`
+	ProjectID     string    `json:"project_id"`
+	TaskID        string    `json:"task_id"`
+	RecordedAt    time.Time `json:"recorded_at"`
+	Score         int       `json:"score"`
+	Passed        bool      `json:"passed"`
+	Iterations    int       `json:"iterations"`
+	CostUSD       float64   `json:"cost_usd"`
+	FilesChanged  int       `json:"files_changed"`
+	PRURL         string    `json:"pr_url"`
+	// PRState is OPEN, MERGED or CLOSED; empty when not looked up
+	PRState string `json:"pr_state"`
+}
+
+// RepoRow aggregates one repo's sessions.
+type RepoRow struct {
+	SchemaVersion      int     `json:"schema_version"`
+	Repo               string  `json:"repo"`
+	ProjectID          string  `json:"project_id"`
+	Sessions           int     `json:"sessions"`
+	SuccessfulSessions int     `json:"successful_sessions"`
+	ReviewPassRate     float64 `json:"review_pass_rate"` // Share of recorded runs that passed review (0-1)
+	AvgIterations      float64 `json:"avg_iterations"`
+	AvgDurationSeconds float64 `json:"avg_duration_seconds"`
+	TotalCostUSD       float64 `json:"total_cost_usd"`
+	PRsCreated         int     `json:"prs_created"`
+	PRsMerged          int     `json:"prs_merged"`
+	// CostPerMergedPR includes the cost of runs that never merged; 0 when
+	// no PR is known to be merged
+	CostPerMergedPR     float64   `json:"cost_per_merged_pr"`
+	CommonFailurePoints []string  `json:"common_failure_points"`
+	LastRunAt           time.Time `json:"last_run_at"`
+}
+
+// PRStateFunc looks up the state of a PR by URL.
+type PRStateFunc func(url string) (string, error)
+
+// Collect normalizes the memory of each repo into run and repo rows.
+// prState may be nil to skip PR lookups; without them PRsMerged is 0.
+func Collect(mems []*memory.Memory, prState PRStateFunc) ([]RunRow, []RepoRow) {
+	var runs []RunRow
+	var repos []RepoRow
+	for _, mem := range mems {
+		repo := RepoRow{
+			SchemaVersion:       SchemaVersion,
+			Repo:                repoName(mem),
+			ProjectID:           mem.ProjectID,
+			Sessions:            mem.Stats.TotalSessions,
+			SuccessfulSessions:  mem.Stats.SuccessfulSessions,
+			AvgDurationSeconds:  mem.Stats.AvgDuration.Seconds(),
+			CommonFailurePoints: mem.Stats.CommonFailurePoints,
+		}
+
+		var passed, iterations int
+		for _, rec := range mem.ScoreHistory {
+			run := RunRow{
+				SchemaVersion: SchemaVersion,
+				Repo:          repo.Repo,
+				ProjectID:     mem.ProjectID,
+				TaskID:        rec.TaskID,
+				RecordedAt:    rec.RecordedAt.UTC(),
+				Score:         rec.Score,
+				Passed:        rec.Passed,
+				Iterations:    rec.Iterations,
+				CostUSD:       rec.CostUSD,
+				FilesChanged:  rec.FilesChanged,
+				PRURL:         rec.PRURL,
+			}
+			if rec.PRURL != "" {
+				repo.PRsCreated++
+				if prState != nil {
+					run.PRState, _ = prState(rec.PRURL)
+				}
+				if run.PRState == forge.PRStateMerged {
+					repo.PRsMerged++
+				}
+			}
+			if rec.Passed {
+				passed++
+			}
+			iterations += rec.Iterations
+			repo.TotalCostUSD += rec.CostUSD
+			if run.RecordedAt.After(repo.LastRunAt) {
+				repo.LastRunAt = run.RecordedAt
+			}
+			runs = append(runs, run)
+		}
+
+		if n := len(mem.ScoreHistory); n > 0 {
+			repo.ReviewPassRate = float64(passed) / float64(n)
+			repo.AvgIterations = float64(iterations) / float64(n)
+		}
+		if repo.PRsMerged > 0 {
+			repo.CostPerMergedPR = repo.TotalCostUSD / float64(repo.PRsMerged)
+		}
+		repos = append(repos, repo)
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].RecordedAt.Before(runs[j].RecordedAt) })
+	sort.SliceStable(repos, func(i, j int) bool { return repos[i].Repo < repos[j].Repo })
+	return runs, repos
+}
+
+// repoName is the repo's directory name, or its project ID for memory
+// recorded before paths were stored.
+func repoName(mem *memory.Memory) string {
+	if mem.ProjectPath == "" {
+		return mem.ProjectID
+	}
+	return filepath.Base(mem.ProjectPath)
+}
+
+// WriteRuns writes run rows in format.
+func WriteRuns(w io.Writer, format string, runs []RunRow) error {
+	header := []string{"schema_version", "repo", "project_id", "task_id", "recorded_at", "score", "passed",
+		"iterations", "cost_usd", "files_changed", "pr_url", "pr_state"}
+	records := make([][]string, len(runs))
+	for i, r := range runs {
+		records[i] = []string{strconv.Itoa(r.SchemaVersion), r.Repo, r.ProjectID, r.TaskID, formatTime(r.RecordedAt), strconv.Itoa(r.Score),
+			strconv.FormatBool(r.Passed), strconv.Itoa(r.Iterations), formatFloat(r.CostUSD),
+			strconv.Itoa(r.FilesChanged), r.PRURL, r.PRState}
+	}
+	return write(w, format, header, records, runs)
+}
+
+// WriteRepos writes repo rows in format. In CSV, failure points are
+// joined with "; ".
+func WriteRepos(w io.Writer, format string, repos []RepoRow) error {
+	header := []string{"schema_version", "repo", "project_id", "sessions", "successful_sessions", "review_pass_rate",
+		"avg_iterations", "avg_duration_seconds", "total_cost_usd", "prs_created", "prs_merged",
+		"cost_per_merged_pr", "common_failure_points", "last_run_at"}
+	records := make([][]string, len(repos))
+	for i, r := range repos {
+		records[i] = []string{strconv.Itoa(r.SchemaVersion), r.Repo, r.ProjectID, strconv.Itoa(r.Sessions), strconv.Itoa(r.SuccessfulSessions),
+			formatFloat(r.ReviewPassRate), formatFloat(r.AvgIterations), formatFloat(r.AvgDurationSeconds),
+			formatFloat(r.TotalCostUSD), strconv.Itoa(r.PRsCreated), strconv.Itoa(r.PRsMerged),
+			formatFloat(r.CostPerMergedPR), strings.Join(r.CommonFailurePoints, "; "), formatTime(r.LastRunAt)}
+	}
+	return write(w, format, header, records, repos)
+}
+
+func write[T any](w io.Writer, format string, header []string, records [][]string, rows []T) error {
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		cw.Write(header)
+		cw.WriteAll(records)
+		return cw.Error()
+	case FormatJSONL:
+		enc := json.NewEncoder(w)
+		for _, row := range rows {
+			if err := enc.Encode(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown format %q (use %s or %s)", format, FormatCSV, FormatJSONL)
+}
+
+func formatFloat(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
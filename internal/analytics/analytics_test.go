@@ -0,0 +1,109 @@
+package analytics
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/memory"
+)
+
+func testMemory() []*memory.Memory {
+	day := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	api := &memory.Memory{
+		ProjectID:   "p1",
+		ProjectPath: "/src/api",
+		Stats:       memory.SessionStats{TotalSessions: 4, SuccessfulSessions: 2, AvgDuration: 90 * time.Second},
+		ScoreHistory: []memory.ScoreRecord{
+			{TaskID: "ENG-2", Score: 60, Iterations: 3, CostUSD: 3.0, RecordedAt: day.Add(time.Hour)},
+			{TaskID: "ENG-1", Score: 90, Passed: true, Iterations: 1, CostUSD: 1.0, FilesChanged: 2, PRURL: "https://github.com/o/api/pull/1", RecordedAt: day},
+			{TaskID: "ENG-3", Score: 85, Passed: true, Iterations: 2, CostUSD: 2.0, PRURL: "https://github.com/o/api/pull/2", RecordedAt: day.Add(2 * time.Hour)},
+		},
+	}
+	api.Stats.CommonFailurePoints = []string{"review (iteration 3): missing migration"}
+	legacy := &memory.Memory{ProjectID: "p2"}
+	return []*memory.Memory{legacy, api}
+}
+
+func TestCollect(t *testing.T) {
+	states := map[string]string{"https://github.com/o/api/pull/1": "MERGED", "https://github.com/o/api/pull/2": "OPEN"}
+	runs, repos := Collect(testMemory(), func(url string) (string, error) { return states[url], nil })
+
+	if len(runs) != 3 || runs[0].TaskID != "ENG-1" || runs[0].Repo != "api" || runs[0].PRState != "MERGED" {
+		t.Fatalf("Expected runs sorted by time with PR state, got %+v", runs)
+	}
+	if len(repos) != 2 || repos[0].Repo != "api" || repos[1].Repo != "p2" {
+		t.Fatalf("Expected repos sorted by name, legacy memory named by ID, got %+v", repos)
+	}
+
+	api := repos[0]
+	if api.PRsCreated != 2 || api.PRsMerged != 1 || api.TotalCostUSD != 6 || api.CostPerMergedPR != 6 {
+		t.Errorf("Unexpected PR and cost totals: %+v", api)
+	}
+	if api.ReviewPassRate != 2.0/3 || api.AvgIterations != 2 || api.AvgDurationSeconds != 90 {
+		t.Errorf("Unexpected rates: %+v", api)
+	}
+	if !api.LastRunAt.Equal(runs[2].RecordedAt) {
+		t.Errorf("Expected last run at %s, got %s", runs[2].RecordedAt, api.LastRunAt)
+	}
+
+	// Without lookups merged PRs are unknown
+	_, repos = Collect(testMemory(), nil)
+	if repos[0].PRsMerged != 0 || repos[0].CostPerMergedPR != 0 {
+		t.Errorf("Expected no merged PRs without lookups, got %+v", repos[0])
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	runs, repos := Collect(testMemory(), nil)
+
+	var buf bytes.Buffer
+	if err := WriteRuns(&buf, FormatCSV, runs); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 4 || rows[0][0] != "schema_version" || rows[0][len(rows[0])-1] != "pr_state" {
+		t.Fatalf("Expected a header and 3 runs, got %v", rows)
+	}
+	if got := strings.Join(rows[1], ","); got != "1,api,p1,ENG-1,2026-03-01T12:00:00Z,90,true,1,1,2,https://github.com/o/api/pull/1," {
+		t.Errorf("Unexpected run row: %s", got)
+	}
+
+	buf.Reset()
+	if err := WriteRepos(&buf, FormatCSV, repos); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "review (iteration 3): missing migration") {
+		t.Errorf("Expected failure points in repo export:\n%s", buf.String())
+	}
+}
+
+func TestWriteJSONL(t *testing.T) {
+	_, repos := Collect(testMemory(), nil)
+
+	var buf bytes.Buffer
+	if err := WriteRepos(&buf, FormatJSONL, repos); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected one line per repo, got %d", len(lines))
+	}
+	var row map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &row); err != nil {
+		t.Fatal(err)
+	}
+	if row["schema_version"] != float64(SchemaVersion) || row["repo"] != "api" || row["prs_created"] != float64(2) {
+		t.Errorf("Unexpected JSONL row: %v", row)
+	}
+
+	if err := WriteRuns(&buf, "parquet", nil); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,204 @@
+// Package automerge watches a PR boatman opened until it lands: once its
+// checks pass and it is approved, auto-merge is enabled so GitHub merges it
+// under the repository's branch protection, and when it merges the Linear
+// ticket gets a comment and moves to done.
+package automerge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/forge"
+	"github.com/philjestin/boatmanmode/internal/retry"
+)
+
+// GitHub is the PR access the watcher needs.
+type GitHub interface {
+	GetPRStatus(ctx context.Context, url string) (*forge.PRStatus, error)
+	MergeMethod(ctx context.Context, url string) (string, error)
+	EnableAutoMerge(ctx context.Context, url, method string) error
+}
+
+// Tracker is the ticket access the watcher needs.
+type Tracker interface {
+	Comment(ctx context.Context, issue, body string) error
+	MoveToState(ctx context.Context, issue, state string) error
+}
+
+// GH is the GitHub implementation backed by the gh CLI.
+type GH struct{}
+
+// GetPRStatus runs gh pr view.
+func (GH) GetPRStatus(ctx context.Context, url string) (*forge.PRStatus, error) {
+	return forge.GetPRStatus(ctx, url)
+}
+
+// MergeMethod runs gh repo view.
+func (GH) MergeMethod(ctx context.Context, url string) (string, error) {
+	return forge.MergeMethod(ctx, url)
+}
+
+// EnableAutoMerge runs gh pr merge --auto.
+func (GH) EnableAutoMerge(ctx context.Context, url, method string) error {
+	return forge.EnableAutoMerge(ctx, url, method)
+}
+
+// ErrClosed is returned when the PR is closed without merging.
+var ErrClosed = errors.New("PR was closed without merging")
+
+// ErrTimeout is returned when the PR hasn't merged within the timeout.
+var ErrTimeout = errors.New("timed out waiting for the PR to merge")
+
+// Watcher polls a PR until it merges.
+type Watcher struct {
+	cfg     config.AutoMergeConfig
+	github  GitHub
+	tracker Tracker // nil when the ticket isn't in Linear
+	log     func(format string, args ...any)
+	sleep   func(ctx context.Context, d time.Duration) error
+}
+
+// New creates a Watcher. tracker may be nil to skip closing out the ticket.
+func New(cfg config.AutoMergeConfig, gh GitHub, tracker Tracker) *Watcher {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 2 * time.Minute
+	}
+	return &Watcher{
+		cfg:     cfg,
+		github:  gh,
+		tracker: tracker,
+		log:     func(format string, args ...any) { fmt.Printf(format+"\n", args...) },
+		sleep:   retry.Sleep,
+	}
+}
+
+// Watch polls the PR at url until it merges, is closed, or the timeout
+// passes. Auto-merge is enabled once the PR is ready; when it merges,
+// ticket (if set) is commented on and moved to the done state.
+func (w *Watcher) Watch(ctx context.Context, url, ticket string) error {
+	if w.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, w.cfg.Timeout)
+		defer cancel()
+	}
+
+	lastWait := ""
+	for {
+		status, err := w.github.GetPRStatus(ctx, url)
+		if err != nil {
+			// gh hiccups shouldn't end a watch that may last days
+			w.log("⚠️  Could not check %s: %v", url, err)
+		} else {
+			switch status.State {
+			case forge.PRStateMerged:
+				w.log("🎉 %s merged", url)
+				return w.closeTicket(ctx, url, ticket)
+			case forge.PRStateClosed:
+				return fmt.Errorf("%s: %w", url, ErrClosed)
+			}
+			if status.AutoMerge == nil {
+				if wait := w.waitingFor(status); wait != "" {
+					if wait != lastWait {
+						w.log("⏳ Waiting for %s", wait)
+						lastWait = wait
+					}
+				} else if err := w.enable(ctx, url); err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := w.sleep(ctx, w.cfg.Interval); err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return fmt.Errorf("%s: %w after %s", url, ErrTimeout, w.cfg.Timeout)
+			}
+			return err
+		}
+	}
+}
+
+// enable turns on auto-merge with the configured or repository's method.
+func (w *Watcher) enable(ctx context.Context, url string) error {
+	method := w.cfg.Method
+	if method == "" {
+		var err error
+		if method, err = w.github.MergeMethod(ctx, url); err != nil {
+			return err
+		}
+	}
+	if err := w.github.EnableAutoMerge(ctx, url, method); err != nil {
+		return err
+	}
+	w.log("🔀 Checks passed and approved; enabled auto-merge (%s)", method)
+	return nil
+}
+
+// waitingFor describes what the PR still needs before auto-merge, or ""
+// when it is ready.
+func (w *Watcher) waitingFor(status *forge.PRStatus) string {
+	var waits []string
+	pending, failed := checkState(status.Checks)
+	switch {
+	case len(failed) > 0:
+		waits = append(waits, "failing checks to be fixed ("+strings.Join(failed, ", ")+")")
+	case pending > 0:
+		waits = append(waits, fmt.Sprintf("%d check(s) to finish", pending))
+	}
+	if w.cfg.RequireApproval && status.ReviewDecision != "APPROVED" {
+		waits = append(waits, "an approving review")
+	} else if status.ReviewDecision == "CHANGES_REQUESTED" {
+		waits = append(waits, "requested changes to be resolved")
+	}
+	return strings.Join(waits, " and ")
+}
+
+// checkState counts the checks still running and names those that failed.
+func checkState(checks []forge.Check) (pending int, failed []string) {
+	for _, c := range checks {
+		name := c.Name
+		if name == "" {
+			name = c.Context
+		}
+		result := c.Conclusion
+		if c.State != "" {
+			// Commit statuses have a state instead of a status and conclusion
+			result = c.State
+		} else if c.Status != "COMPLETED" {
+			pending++
+			continue
+		}
+		switch result {
+		case "SUCCESS", "NEUTRAL", "SKIPPED":
+		case "PENDING", "EXPECTED":
+			pending++
+		default:
+			failed = append(failed, name)
+		}
+	}
+	return pending, failed
+}
+
+// closeTicket comments on the merged PR's ticket and moves it to done.
+// Failures only warn; the PR is merged either way.
+func (w *Watcher) closeTicket(ctx context.Context, url, ticket string) error {
+	if w.tracker == nil || ticket == "" {
+		return nil
+	}
+	if err := w.tracker.Comment(ctx, ticket, fmt.Sprintf("Merged %s", url)); err != nil {
+		w.log("⚠️  Could not comment on %s: %v", ticket, err)
+	}
+	state := w.cfg.DoneState
+	if state == "" {
+		state = "Done"
+	}
+	if err := w.tracker.MoveToState(ctx, ticket, state); err != nil {
+		w.log("⚠️  Could not move %s to %s: %v", ticket, state, err)
+		return nil
+	}
+	w.log("🎫 Moved %s to %s", ticket, state)
+	return nil
+}
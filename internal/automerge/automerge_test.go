@@ -0,0 +1,147 @@
+package automerge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/forge"
+)
+
+// fakeGitHub returns each status in turn, repeating the last.
+type fakeGitHub struct {
+	statuses []*forge.PRStatus
+	polls    int
+	method   string
+	enabled  string
+}
+
+func (f *fakeGitHub) GetPRStatus(ctx context.Context, url string) (*forge.PRStatus, error) {
+	status := f.statuses[min(f.polls, len(f.statuses)-1)]
+	f.polls++
+	if status == nil {
+		return nil, errors.New("gh: network down")
+	}
+	if f.enabled != "" && status.AutoMerge == nil && status.State == forge.PRStateOpen {
+		status.AutoMerge = &struct {
+			MergeMethod string `json:"mergeMethod"`
+		}{f.enabled}
+	}
+	return status, nil
+}
+
+func (f *fakeGitHub) MergeMethod(ctx context.Context, url string) (string, error) {
+	return f.method, nil
+}
+
+func (f *fakeGitHub) EnableAutoMerge(ctx context.Context, url, method string) error {
+	f.enabled = method
+	return nil
+}
+
+type fakeTracker struct{ calls []string }
+
+func (f *fakeTracker) Comment(ctx context.Context, issue, body string) error {
+	f.calls = append(f.calls, "comment "+issue+": "+body)
+	return nil
+}
+
+func (f *fakeTracker) MoveToState(ctx context.Context, issue, state string) error {
+	f.calls = append(f.calls, "move "+issue+" to "+state)
+	return nil
+}
+
+func newTestWatcher(cfg config.AutoMergeConfig, gh GitHub, tracker Tracker) (*Watcher, *[]string) {
+	w := New(cfg, gh, tracker)
+	var logs []string
+	w.log = func(format string, args ...any) { logs = append(logs, fmt.Sprintf(format, args...)) }
+	w.sleep = func(ctx context.Context, d time.Duration) error { return ctx.Err() }
+	return w, &logs
+}
+
+func open(decision string, checks ...forge.Check) *forge.PRStatus {
+	return &forge.PRStatus{State: forge.PRStateOpen, ReviewDecision: decision, Checks: checks}
+}
+
+var (
+	passed  = forge.Check{Name: "test", Status: "COMPLETED", Conclusion: "SUCCESS"}
+	running = forge.Check{Name: "lint", Status: "IN_PROGRESS"}
+	failing = forge.Check{Name: "build", Status: "COMPLETED", Conclusion: "FAILURE"}
+	status  = forge.Check{Context: "ci/deploy", State: "SUCCESS"}
+)
+
+func TestWatchMerges(t *testing.T) {
+	gh := &fakeGitHub{method: forge.MergeRebase, statuses: []*forge.PRStatus{
+		open("REVIEW_REQUIRED", passed, running),
+		nil,
+		open("APPROVED", passed, running),
+		open("APPROVED", passed, passed, status),
+		open("APPROVED", passed, passed, status),
+		{State: forge.PRStateMerged},
+	}}
+	tracker := &fakeTracker{}
+	w, logs := newTestWatcher(config.AutoMergeConfig{RequireApproval: true, DoneState: "Shipped"}, gh, tracker)
+
+	if err := w.Watch(context.Background(), "https://github.com/acme/web/pull/7", "ENG-123"); err != nil {
+		t.Fatal(err)
+	}
+	if gh.enabled != forge.MergeRebase {
+		t.Errorf("auto-merge method = %q, want the repo's rebase", gh.enabled)
+	}
+	want := []string{"comment ENG-123: Merged https://github.com/acme/web/pull/7", "move ENG-123 to Shipped"}
+	if strings.Join(tracker.calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("tracker calls = %q", tracker.calls)
+	}
+	text := strings.Join(*logs, "\n")
+	for _, s := range []string{"1 check(s) to finish and an approving review", "network down", "enabled auto-merge (rebase)"} {
+		if !strings.Contains(text, s) {
+			t.Errorf("logs missing %q:\n%s", s, text)
+		}
+	}
+}
+
+func TestWatchWaitsOnFailingChecks(t *testing.T) {
+	gh := &fakeGitHub{method: forge.MergeSquash, statuses: []*forge.PRStatus{open("", failing, passed)}}
+	w, logs := newTestWatcher(config.AutoMergeConfig{Timeout: time.Nanosecond}, gh, nil)
+
+	err := w.Watch(context.Background(), "https://github.com/acme/web/pull/7", "ENG-123")
+	if !errors.Is(err, ErrTimeout) {
+		t.Fatalf("err = %v, want ErrTimeout", err)
+	}
+	if gh.enabled != "" {
+		t.Error("auto-merge should wait for the failing check")
+	}
+	if len(*logs) == 0 || !strings.Contains((*logs)[0], "failing checks to be fixed (build)") {
+		t.Errorf("logs = %q", *logs)
+	}
+}
+
+func TestWatchWithoutApproval(t *testing.T) {
+	gh := &fakeGitHub{statuses: []*forge.PRStatus{
+		open("REVIEW_REQUIRED", passed),
+		{State: forge.PRStateMerged},
+	}}
+	w, _ := newTestWatcher(config.AutoMergeConfig{Method: forge.MergeSquash}, gh, nil)
+	if err := w.Watch(context.Background(), "https://github.com/acme/web/pull/7", ""); err != nil {
+		t.Fatal(err)
+	}
+	if gh.enabled != forge.MergeSquash {
+		t.Errorf("auto-merge method = %q, want squash", gh.enabled)
+	}
+}
+
+func TestWatchClosed(t *testing.T) {
+	gh := &fakeGitHub{statuses: []*forge.PRStatus{{State: forge.PRStateClosed}}}
+	tracker := &fakeTracker{}
+	w, _ := newTestWatcher(config.AutoMergeConfig{}, gh, tracker)
+	if err := w.Watch(context.Background(), "https://github.com/acme/web/pull/7", "ENG-123"); !errors.Is(err, ErrClosed) {
+		t.Errorf("err = %v, want ErrClosed", err)
+	}
+	if len(tracker.calls) != 0 {
+		t.Errorf("closed PR touched the ticket: %v", tracker.calls)
+	}
+}
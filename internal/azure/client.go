@@ -0,0 +1,288 @@
+// Package azure provides a client for the Azure DevOps REST API: Azure
+// Boards work items as tickets and Azure Repos pull requests.
+package azure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/retry"
+)
+
+const apiVersion = "7.0"
+
+// MaxDescription is the longest pull request description Azure Repos
+// accepts.
+const MaxDescription = 4000
+
+// Client is an Azure DevOps API client for one project.
+type Client struct {
+	baseURL      string
+	organization string
+	project      string
+	token        string
+	httpClient   *http.Client
+}
+
+// WorkItem represents an Azure Boards work item.
+type WorkItem struct {
+	ID          int      `json:"id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	State       string   `json:"state"`
+	Type        string   `json:"type"`
+	Tags        []string `json:"tags"`
+	URL         string   `json:"url"`
+}
+
+// PullRequest is a created Azure Repos pull request.
+type PullRequest struct {
+	ID  int
+	URL string
+}
+
+// NewPullRequest describes a pull request to create.
+type NewPullRequest struct {
+	Repository  string
+	Source      string // Branch name, without refs/heads/
+	Target      string
+	Title       string
+	Description string
+	Draft       bool
+
+	// WorkItems are linked to the pull request.
+	WorkItems []int
+}
+
+// New creates a new Azure DevOps client.
+func New(cfg config.AzureConfig) *Client {
+	return &Client{
+		baseURL:      strings.TrimSuffix(cfg.URL, "/"),
+		organization: cfg.Organization,
+		project:      cfg.Project,
+		token:        cfg.Token,
+		httpClient:   &http.Client{},
+	}
+}
+
+// GetWorkItem fetches a work item by ID.
+func (c *Client) GetWorkItem(ctx context.Context, id int) (*WorkItem, error) {
+	resp, err := c.execute(ctx, http.MethodGet, "/_apis/wit/workitems/"+strconv.Itoa(id), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		ID     int `json:"id"`
+		Fields struct {
+			Title        string `json:"System.Title"`
+			Description  string `json:"System.Description"`
+			State        string `json:"System.State"`
+			WorkItemType string `json:"System.WorkItemType"`
+			Tags         string `json:"System.Tags"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	item := &WorkItem{
+		ID:          result.ID,
+		Title:       result.Fields.Title,
+		Description: htmlToText(result.Fields.Description),
+		State:       result.Fields.State,
+		Type:        result.Fields.WorkItemType,
+		URL:         c.projectURL() + "/_workitems/edit/" + strconv.Itoa(result.ID),
+	}
+	for _, tag := range strings.Split(result.Fields.Tags, ";") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			item.Tags = append(item.Tags, tag)
+		}
+	}
+	return item, nil
+}
+
+// CreatePullRequest opens a pull request and links its work items.
+func (c *Client) CreatePullRequest(ctx context.Context, pr NewPullRequest) (*PullRequest, error) {
+	body := map[string]interface{}{
+		"sourceRefName": "refs/heads/" + pr.Source,
+		"targetRefName": "refs/heads/" + pr.Target,
+		"title":         pr.Title,
+		"description":   pr.Description,
+		"isDraft":       pr.Draft,
+	}
+	if len(pr.WorkItems) > 0 {
+		refs := make([]map[string]string, len(pr.WorkItems))
+		for i, id := range pr.WorkItems {
+			refs[i] = map[string]string{"id": strconv.Itoa(id)}
+		}
+		body["workItemRefs"] = refs
+	}
+	path := "/_apis/git/repositories/" + url.PathEscape(pr.Repository) + "/pullrequests"
+	resp, err := c.execute(ctx, http.MethodPost, path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		PullRequestID int `json:"pullRequestId"`
+		Repository    struct {
+			Name string `json:"name"`
+		} `json:"repository"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	repo := result.Repository.Name
+	if repo == "" {
+		repo = pr.Repository
+	}
+	return &PullRequest{
+		ID:  result.PullRequestID,
+		URL: fmt.Sprintf("%s/_git/%s/pullrequest/%d", c.projectURL(), url.PathEscape(repo), result.PullRequestID),
+	}, nil
+}
+
+func (c *Client) projectURL() string {
+	return c.baseURL + "/" + url.PathEscape(c.organization) + "/" + url.PathEscape(c.project)
+}
+
+// execute performs a REST request against the project with retry logic.
+func (c *Client) execute(ctx context.Context, method, path string, body interface{}) ([]byte, error) {
+	if c.organization == "" || c.project == "" {
+		return nil, retry.Permanent(fmt.Errorf("azure organization and project are not configured"))
+	}
+
+	var jsonBody []byte
+	if body != nil {
+		var err error
+		jsonBody, err = json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+	}
+
+	endpoint := c.projectURL() + path + "?api-version=" + apiVersion
+	var result []byte
+
+	err := retry.Do(ctx, retry.APIConfig(), "Azure DevOps API request", func() error {
+		req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(jsonBody))
+		if err != nil {
+			return retry.Permanent(fmt.Errorf("failed to create request: %w", err))
+		}
+
+		req.Header.Set("Accept", "application/json")
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		// Personal access tokens go in basic auth with an empty user
+		req.SetBasicAuth("", c.token)
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("request failed: %w", err) // Retryable
+		}
+		defer resp.Body.Close()
+
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+
+		// 429 is retryable; other 4xx errors are permanent (client errors)
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			return fmt.Errorf("API returned status %d: %s", resp.StatusCode, errorMessage(respBody))
+		}
+		if resp.StatusCode >= 400 {
+			return retry.Permanent(fmt.Errorf("API returned status %d: %s", resp.StatusCode, errorMessage(respBody)))
+		}
+		// An expired token is redirected to the sign-in page with 203
+		if resp.StatusCode == http.StatusNonAuthoritativeInfo {
+			return retry.Permanent(fmt.Errorf("API rejected the token (signed out)"))
+		}
+
+		result = respBody
+		return nil
+	})
+
+	return result, err
+}
+
+// errorMessage extracts the Azure DevOps error message from a response body.
+func errorMessage(body []byte) string {
+	var result struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &result) == nil && result.Message != "" {
+		return result.Message
+	}
+	return string(body)
+}
+
+// ParseWorkItemID parses a work item ID given as 123, #123 or AB#123.
+func ParseWorkItemID(s string) (int, error) {
+	id, err := strconv.Atoi(strings.TrimPrefix(strings.TrimPrefix(strings.TrimSpace(s), "AB"), "#"))
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid work item ID %q (want a number, e.g. 123 or AB#123)", s)
+	}
+	return id, nil
+}
+
+// Remote is the Azure Repos repository a git remote points at.
+type Remote struct {
+	Organization string
+	Project      string
+	Repository   string
+}
+
+var remotePatterns = []*regexp.Regexp{
+	// https://dev.azure.com/org/project/_git/repo, with or without user@
+	regexp.MustCompile(`^https://(?:[^@/]+@)?dev\.azure\.com/([^/]+)/([^/]+)/_git/([^/]+?)/?$`),
+	// git@ssh.dev.azure.com:v3/org/project/repo
+	regexp.MustCompile(`^(?:ssh://)?git@ssh\.dev\.azure\.com[:/]v3/([^/]+)/([^/]+)/([^/]+?)/?$`),
+	// https://org.visualstudio.com/project/_git/repo (legacy)
+	regexp.MustCompile(`^https://(?:[^@/]+@)?([^.]+)\.visualstudio\.com/(?:DefaultCollection/)?([^/]+)/_git/([^/]+?)/?$`),
+}
+
+// ParseRemote returns the repository of an Azure Repos remote URL, and
+// false for remotes elsewhere.
+func ParseRemote(remoteURL string) (Remote, bool) {
+	for _, re := range remotePatterns {
+		if m := re.FindStringSubmatch(strings.TrimSpace(remoteURL)); m != nil {
+			unescape := func(s string) string {
+				if u, err := url.PathUnescape(s); err == nil {
+					return u
+				}
+				return s
+			}
+			return Remote{Organization: unescape(m[1]), Project: unescape(m[2]), Repository: unescape(m[3])}, true
+		}
+	}
+	return Remote{}, false
+}
+
+var (
+	blockEnd = regexp.MustCompile(`(?i)<br\s*/?>|</(p|div|li|h[1-6]|tr)>`)
+	listItem = regexp.MustCompile(`(?i)<li[^>]*>`)
+	anyTag   = regexp.MustCompile(`<[^>]*>`)
+	blankRun = regexp.MustCompile(`\n{3,}`)
+)
+
+// htmlToText converts a work item's HTML description to plain text.
+func htmlToText(s string) string {
+	s = blockEnd.ReplaceAllString(s, "\n")
+	s = listItem.ReplaceAllString(s, "- ")
+	s = anyTag.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	s = strings.ReplaceAll(s, "\u00a0", " ")
+	return strings.TrimSpace(blankRun.ReplaceAllString(s, "\n\n"))
+}
@@ -0,0 +1,130 @@
+package azure
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+)
+
+func TestGetWorkItem(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/acme/shop/_apis/wit/workitems/123" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if user, pass, ok := r.BasicAuth(); !ok || user != "" || pass != "pat" {
+			t.Errorf("basic auth = %q, %q, %v", user, pass, ok)
+		}
+		w.Write([]byte(`{"id": 123, "fields": {
+			"System.Title": "Export invoices as PDF",
+			"System.Description": "<div>Finance wants PDFs.</div><ul><li>One per invoice</li></ul><p>Tom &amp; Jerry</p>",
+			"System.State": "Active",
+			"System.WorkItemType": "User Story",
+			"System.Tags": "billing; export"}}`))
+	}))
+	defer srv.Close()
+
+	c := New(config.AzureConfig{URL: srv.URL + "/", Organization: "acme", Project: "shop", Token: "pat"})
+	item, err := c.GetWorkItem(context.Background(), 123)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.ID != 123 || item.Title != "Export invoices as PDF" || item.State != "Active" || item.Type != "User Story" {
+		t.Errorf("item = %+v", item)
+	}
+	if want := "Finance wants PDFs.\n- One per invoice\nTom & Jerry"; item.Description != want {
+		t.Errorf("description = %q, want %q", item.Description, want)
+	}
+	if len(item.Tags) != 2 || item.Tags[0] != "billing" || item.Tags[1] != "export" {
+		t.Errorf("tags = %v", item.Tags)
+	}
+	if want := srv.URL + "/acme/shop/_workitems/edit/123"; item.URL != want {
+		t.Errorf("URL = %s, want %s", item.URL, want)
+	}
+}
+
+func TestGetWorkItemNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"message": "TF401232: Work item 404 does not exist."}`))
+	}))
+	defer srv.Close()
+
+	c := New(config.AzureConfig{URL: srv.URL, Organization: "acme", Project: "shop", Token: "pat"})
+	_, err := c.GetWorkItem(context.Background(), 404)
+	if err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestCreatePullRequest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/acme/shop/_apis/git/repositories/web/pullrequests" {
+			t.Errorf("request = %s %s", r.Method, r.URL.Path)
+		}
+		var body struct {
+			SourceRefName string              `json:"sourceRefName"`
+			TargetRefName string              `json:"targetRefName"`
+			Title         string              `json:"title"`
+			WorkItemRefs  []map[string]string `json:"workItemRefs"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		if body.SourceRefName != "refs/heads/123-export" || body.TargetRefName != "refs/heads/main" || body.Title != "Export" {
+			t.Errorf("body = %+v", body)
+		}
+		if len(body.WorkItemRefs) != 1 || body.WorkItemRefs[0]["id"] != "123" {
+			t.Errorf("workItemRefs = %v", body.WorkItemRefs)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"pullRequestId": 7, "repository": {"name": "web"}}`))
+	}))
+	defer srv.Close()
+
+	c := New(config.AzureConfig{URL: srv.URL, Organization: "acme", Project: "shop", Token: "pat"})
+	pr, err := c.CreatePullRequest(context.Background(), NewPullRequest{
+		Repository: "web", Source: "123-export", Target: "main", Title: "Export", WorkItems: []int{123},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := srv.URL + "/acme/shop/_git/web/pullrequest/7"; pr.ID != 7 || pr.URL != want {
+		t.Errorf("pr = %+v, want URL %s", pr, want)
+	}
+}
+
+func TestParseWorkItemID(t *testing.T) {
+	for _, s := range []string{"123", "#123", "AB#123"} {
+		if id, err := ParseWorkItemID(s); err != nil || id != 123 {
+			t.Errorf("ParseWorkItemID(%q) = %d, %v", s, id, err)
+		}
+	}
+	if _, err := ParseWorkItemID("ENG-123"); err == nil {
+		t.Error("expected an error for a non-numeric ID")
+	}
+}
+
+func TestParseRemote(t *testing.T) {
+	want := Remote{Organization: "acme", Project: "shop", Repository: "web"}
+	for _, url := range []string{
+		"https://dev.azure.com/acme/shop/_git/web",
+		"https://acme@dev.azure.com/acme/shop/_git/web",
+		"git@ssh.dev.azure.com:v3/acme/shop/web",
+		"https://acme.visualstudio.com/shop/_git/web",
+	} {
+		if got, ok := ParseRemote(url); !ok || got != want {
+			t.Errorf("ParseRemote(%q) = %+v, %v", url, got, ok)
+		}
+	}
+	if got, ok := ParseRemote("https://dev.azure.com/acme/My%20Project/_git/web"); !ok || got.Project != "My Project" {
+		t.Errorf("escaped project = %+v, %v", got, ok)
+	}
+	if _, ok := ParseRemote("git@github.com:acme/web.git"); ok {
+		t.Error("GitHub remote should not parse")
+	}
+}
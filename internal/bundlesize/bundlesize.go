@@ -0,0 +1,349 @@
+// Package bundlesize checks frontend bundle sizes against performance
+// budgets: it builds the bundle before and after the change, compares the
+// output files, and raises review issues for blown budgets and large
+// regressions.
+package bundlesize
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/cmdoutput"
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/scottbott"
+)
+
+// DefaultMaxGrowth is the total growth, in percent, that fails review.
+const DefaultMaxGrowth = 5.0
+
+// defaultFiles are the output files measured when bundle.files is unset.
+var defaultFiles = []string{"*.js", "*.css"}
+
+// Sizes maps output files, relative to the output directory and with
+// content hashes removed, to their sizes in bytes.
+type Sizes map[string]int64
+
+// Total is the combined size of all files.
+func (s Sizes) Total() int64 {
+	var total int64
+	for _, size := range s {
+		total += size
+	}
+	return total
+}
+
+// Checker builds and measures a worktree's bundle.
+type Checker struct {
+	worktreePath string
+	cfg          config.BundleConfig
+	budgets      []budget
+	baseline     Sizes
+}
+
+type budget struct {
+	files string
+	max   int64
+}
+
+// New creates a Checker. Returns nil when no build command is configured.
+func New(worktreePath string, cfg config.BundleConfig) (*Checker, error) {
+	if cfg.Build == "" {
+		return nil, nil
+	}
+	if cfg.Dir == "" {
+		return nil, fmt.Errorf("bundle.dir is required with bundle.build")
+	}
+	// It is removed before each build
+	if !filepath.IsLocal(cfg.Dir) || filepath.Clean(cfg.Dir) == "." {
+		return nil, fmt.Errorf("bundle.dir must be a directory inside the repo, got %s", cfg.Dir)
+	}
+	if len(cfg.Files) == 0 {
+		cfg.Files = defaultFiles
+	}
+	if cfg.MaxGrowth <= 0 {
+		cfg.MaxGrowth = DefaultMaxGrowth
+	}
+	c := &Checker{worktreePath: worktreePath, cfg: cfg}
+	for _, b := range cfg.Budgets {
+		max, err := ParseSize(b.Max)
+		if err != nil {
+			return nil, fmt.Errorf("invalid budget for %s: %w", b.Files, err)
+		}
+		c.budgets = append(c.budgets, budget{files: b.Files, max: max})
+	}
+	return c, nil
+}
+
+// SetBaseline builds and measures the bundle before the change.
+func (c *Checker) SetBaseline(ctx context.Context) error {
+	sizes, err := c.Measure(ctx)
+	if err != nil {
+		return err
+	}
+	c.baseline = sizes
+	return nil
+}
+
+// Check builds and measures the bundle with the change, and compares it
+// with the baseline.
+func (c *Checker) Check(ctx context.Context) (*Report, error) {
+	sizes, err := c.Measure(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return c.Compare(c.baseline, sizes), nil
+}
+
+// Measure runs the build and returns the sizes of the output files. The
+// output directory is removed first, so files of earlier builds, such as
+// the baseline's hashed bundles, aren't counted.
+func (c *Checker) Measure(ctx context.Context) (Sizes, error) {
+	root := filepath.Join(c.worktreePath, c.cfg.Dir)
+	if err := os.RemoveAll(root); err != nil {
+		return nil, fmt.Errorf("failed to clean %s: %w", c.cfg.Dir, err)
+	}
+	cmd := exec.CommandContext(ctx, "sh", "-c", c.cfg.Build)
+	cmd.Dir = c.worktreePath
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s failed: %w\n%s", c.cfg.Build, err, cmdoutput.Tail(out.String(), 20))
+	}
+
+	sizes := Sizes{}
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(root, path)
+		rel = filepath.ToSlash(rel)
+		if !c.measured(rel) {
+			return nil
+		}
+		size, err := c.size(path)
+		if err != nil {
+			return err
+		}
+		sizes[Normalize(rel)] += size
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure %s: %w", c.cfg.Dir, err)
+	}
+	if len(sizes) == 0 {
+		return nil, fmt.Errorf("no files matching %s in %s", strings.Join(c.cfg.Files, ", "), c.cfg.Dir)
+	}
+	return sizes, nil
+}
+
+func (c *Checker) measured(rel string) bool {
+	for _, pattern := range c.cfg.Files {
+		if config.MatchPathPattern(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// size returns a file's size, gzipped when bundle.gzip is set.
+func (c *Checker) size(path string) (int64, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	if !c.cfg.Gzip {
+		return int64(len(data)), nil
+	}
+	var buf bytes.Buffer
+	w, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	w.Write(data)
+	w.Close()
+	return int64(buf.Len()), nil
+}
+
+// contentHash matches the content hash bundlers put in file names, as in
+// main.3f9a1c2b.js or chunk-5KJ3QZ7A.css.
+var contentHash = regexp.MustCompile(`[.-]([0-9a-f]{8,}|[0-9A-Z]{8})(\.[^./]+)$`)
+
+// Normalize removes the content hash from an output file name so the same
+// file matches across builds.
+func Normalize(name string) string {
+	return contentHash.ReplaceAllString(name, "$2")
+}
+
+// Entry is a file's size before and after the change. A size of 0 means
+// the file did not exist.
+type Entry struct {
+	File   string
+	Before int64
+	After  int64
+}
+
+// Delta is the change in size, in bytes.
+func (e Entry) Delta() int64 { return e.After - e.Before }
+
+// Report compares the bundle before and after the change.
+type Report struct {
+	Entries []Entry // Files whose size changed, largest change first
+	Before  int64
+	After   int64
+	Issues  []scottbott.Issue
+}
+
+// Growth is the total increase in percent.
+func (r *Report) Growth() float64 {
+	if r.Before == 0 {
+		return 0
+	}
+	return float64(r.After-r.Before) / float64(r.Before) * 100
+}
+
+// Compare builds the report for two measurements, with a major issue for
+// each blown budget and for total growth over bundle.max_growth.
+func (c *Checker) Compare(before, after Sizes) *Report {
+	r := &Report{Before: before.Total(), After: after.Total()}
+	for _, file := range union(before, after) {
+		if before[file] != after[file] {
+			r.Entries = append(r.Entries, Entry{File: file, Before: before[file], After: after[file]})
+		}
+	}
+	sort.SliceStable(r.Entries, func(i, j int) bool {
+		return abs(r.Entries[i].Delta()) > abs(r.Entries[j].Delta())
+	})
+
+	for _, b := range c.budgets {
+		var total, previous int64
+		for file, size := range after {
+			if config.MatchPathPattern(b.files, file) {
+				total += size
+				previous += before[file]
+			}
+		}
+		if total > b.max {
+			r.Issues = append(r.Issues, scottbott.Issue{
+				Severity:    "major",
+				File:        c.cfg.Dir,
+				Description: fmt.Sprintf("Bundle `%s` is %s, over its %s budget (was %s)", b.files, FormatSize(total), FormatSize(b.max), FormatSize(previous)),
+				Suggestion:  "Reduce what the change adds to the bundle: lazy-load it, drop heavy dependencies, or split the chunk",
+			})
+		}
+	}
+	if before.Total() > 0 && r.Growth() > c.cfg.MaxGrowth {
+		var largest string
+		if len(r.Entries) > 0 && r.Entries[0].Delta() > 0 {
+			largest = fmt.Sprintf(", mostly `%s` (+%s)", r.Entries[0].File, FormatSize(r.Entries[0].Delta()))
+		}
+		r.Issues = append(r.Issues, scottbott.Issue{
+			Severity: "major",
+			File:     c.cfg.Dir,
+			Description: fmt.Sprintf("Bundle grew %.1f%% (%s → %s), over the %.0f%% limit%s",
+				r.Growth(), FormatSize(r.Before), FormatSize(r.After), c.cfg.MaxGrowth, largest),
+			Suggestion: "Find what the change pulls into the bundle and lazy-load or remove it",
+		})
+	}
+	return r
+}
+
+// Markdown summarizes the report for the PR body.
+func (r *Report) Markdown() string {
+	var sb strings.Builder
+	sb.WriteString("### 📦 Bundle Size\n")
+	sb.WriteString(fmt.Sprintf("Total: %s → %s (%s, %+.1f%%)\n", FormatSize(r.Before), FormatSize(r.After), formatDelta(r.After-r.Before), r.Growth()))
+	if len(r.Entries) > 0 {
+		sb.WriteString("\n| File | Before | After | Change |\n|---|---|---|---|\n")
+		for i, e := range r.Entries {
+			if i == 10 {
+				sb.WriteString(fmt.Sprintf("| … %d more | | | |\n", len(r.Entries)-10))
+				break
+			}
+			sb.WriteString(fmt.Sprintf("| `%s` | %s | %s | %s |\n", e.File, sizeOrDash(e.Before), sizeOrDash(e.After), formatDelta(e.Delta())))
+		}
+	}
+	for _, issue := range r.Issues {
+		sb.WriteString(fmt.Sprintf("\n⚠️ %s", issue.Description))
+	}
+	sb.WriteString("\n\n")
+	return sb.String()
+}
+
+var sizeExpr = regexp.MustCompile(`(?i)^\s*([0-9.]+)\s*(b|kb|kib|mb|mib)?\s*$`)
+
+// ParseSize parses sizes such as 250KB, 1.5MB or 5000 (bytes). KB and MB
+// are 1024-based, as bundlers report them.
+func ParseSize(s string) (int64, error) {
+	m := sizeExpr.FindStringSubmatch(s)
+	if m == nil {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	n, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	switch strings.ToLower(m[2]) {
+	case "kb", "kib":
+		n *= 1024
+	case "mb", "mib":
+		n *= 1024 * 1024
+	}
+	return int64(n), nil
+}
+
+// FormatSize formats a byte count for people.
+func FormatSize(n int64) string {
+	switch {
+	case abs(n) >= 1024*1024:
+		return fmt.Sprintf("%.2f MB", float64(n)/(1024*1024))
+	case abs(n) >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(n)/1024)
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
+func formatDelta(n int64) string {
+	if n >= 0 {
+		return "+" + FormatSize(n)
+	}
+	return "-" + FormatSize(-n)
+}
+
+func sizeOrDash(n int64) string {
+	if n == 0 {
+		return "—"
+	}
+	return FormatSize(n)
+}
+
+func union(a, b Sizes) []string {
+	seen := map[string]bool{}
+	for k := range a {
+		seen[k] = true
+	}
+	for k := range b {
+		seen[k] = true
+	}
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,157 @@
+package bundlesize
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+)
+
+func TestNormalize(t *testing.T) {
+	for name, want := range map[string]string{
+		"main.3f9a1c2b.js":          "main.js",
+		"assets/chunk-5KJ3QZ7A.css": "assets/chunk.css",
+		"vendor-a1b2c3d4e5f6.js":    "vendor.js",
+		"app.js":                    "app.js",
+		"polyfills.js":              "polyfills.js",
+	} {
+		if got := Normalize(name); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestParseSize(t *testing.T) {
+	for s, want := range map[string]int64{"250KB": 250 * 1024, "1.5MB": 1536 * 1024, "5000": 5000, "10 kb": 10240} {
+		if got, err := ParseSize(s); err != nil || got != want {
+			t.Errorf("ParseSize(%q) = %d, %v; want %d", s, got, err, want)
+		}
+	}
+	if _, err := ParseSize("big"); err == nil {
+		t.Error("ParseSize(big) should fail")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	c, err := New(t.TempDir(), config.BundleConfig{
+		Build:   "true",
+		Dir:     "dist",
+		Budgets: []config.BundleBudget{{Files: "main*.js", Max: "100KB"}, {Files: "*.css", Max: "50KB"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	before := Sizes{"main.js": 90 * 1024, "app.css": 20 * 1024}
+	after := Sizes{"main.js": 110 * 1024, "app.css": 20 * 1024, "chart.js": 5 * 1024}
+
+	r := c.Compare(before, after)
+	if r.Before != 110*1024 || r.After != 135*1024 {
+		t.Errorf("totals = %d → %d", r.Before, r.After)
+	}
+	if len(r.Entries) != 2 || r.Entries[0].File != "main.js" || r.Entries[1].File != "chart.js" {
+		t.Errorf("entries = %+v, want main.js then chart.js", r.Entries)
+	}
+	if len(r.Issues) != 2 {
+		t.Fatalf("issues = %+v, want main budget and total growth", r.Issues)
+	}
+	if !strings.Contains(r.Issues[0].Description, "`main*.js` is 110.0 KB, over its 100.0 KB budget") {
+		t.Errorf("budget issue = %q", r.Issues[0].Description)
+	}
+	if !strings.Contains(r.Issues[1].Description, "grew 22.7%") || !strings.Contains(r.Issues[1].Description, "mostly `main.js`") {
+		t.Errorf("growth issue = %q", r.Issues[1].Description)
+	}
+
+	md := r.Markdown()
+	for _, want := range []string{"### 📦 Bundle Size", "110.0 KB → 135.0 KB", "| `chart.js` | — | 5.0 KB | +5.0 KB |"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+
+	if r := c.Compare(before, Sizes{"main.js": 92 * 1024, "app.css": 20 * 1024}); len(r.Issues) != 0 {
+		t.Errorf("small growth issues = %+v", r.Issues)
+	}
+}
+
+func TestMeasure(t *testing.T) {
+	dir := t.TempDir()
+	build := `mkdir -p dist/assets && printf '%0500d' 0 > dist/main.0a1b2c3d.js && printf 'body{}' > dist/assets/app.css && printf 'x' > dist/index.html`
+	c, err := New(dir, config.BundleConfig{Build: build, Dir: "dist"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sizes, err := c.Measure(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sizes) != 2 || sizes["main.js"] != 500 || sizes["assets/app.css"] != 6 {
+		t.Errorf("sizes = %v", sizes)
+	}
+
+	c.cfg.Gzip = true
+	sizes, err = c.Measure(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sizes["main.js"] >= 500 {
+		t.Errorf("gzipped main.js = %d, want smaller than 500", sizes["main.js"])
+	}
+
+	// Bundles of an earlier build are not counted
+	c.cfg.Gzip = false
+	c.cfg.Build = `mkdir -p dist && printf '%0300d' 0 > dist/main.4e5f6a7b.js`
+	sizes, err = c.Measure(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sizes) != 1 || sizes["main.js"] != 300 {
+		t.Errorf("rebuilt sizes = %v, want only the new main.js", sizes)
+	}
+
+	c.cfg.Build = "echo 'Module not found' >&2; exit 1"
+	if _, err := c.Measure(context.Background()); err == nil || !strings.Contains(err.Error(), "Module not found") {
+		t.Errorf("failed build err = %v", err)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	c, err := New(dir, config.BundleConfig{Build: "mkdir -p out && cp src.js out/app.js", Dir: "out", MaxGrowth: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := filepath.Join(dir, "src.js")
+	os.WriteFile(src, []byte(strings.Repeat("a", 1000)), 0644)
+	if err := c.SetBaseline(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(src, []byte(strings.Repeat("a", 1200)), 0644)
+
+	r, err := c.Check(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Growth() != 20 || len(r.Issues) != 1 {
+		t.Errorf("growth = %.1f, issues = %+v", r.Growth(), r.Issues)
+	}
+}
+
+func TestNew(t *testing.T) {
+	if c, err := New(t.TempDir(), config.BundleConfig{}); c != nil || err != nil {
+		t.Errorf("New without build = %v, %v", c, err)
+	}
+	if _, err := New(t.TempDir(), config.BundleConfig{Build: "npm run build"}); err == nil {
+		t.Error("New without dir should fail")
+	}
+	for _, dir := range []string{".", "../dist", "/tmp/dist"} {
+		if _, err := New(t.TempDir(), config.BundleConfig{Build: "npm run build", Dir: dir}); err == nil {
+			t.Errorf("New with dir %s should fail", dir)
+		}
+	}
+	if _, err := New(t.TempDir(), config.BundleConfig{Build: "b", Dir: "d", Budgets: []config.BundleBudget{{Files: "*.js", Max: "lots"}}}); err == nil {
+		t.Error("New with invalid budget should fail")
+	}
+}
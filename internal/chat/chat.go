@@ -0,0 +1,382 @@
+// Package chat provides an interactive session bound to a finished or
+// paused run: questions are answered with the run's worktree, plan, review
+// and diff as context, and ad-hoc adjustments are applied by the refactor
+// agent and reviewed before they are kept.
+package chat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/agent"
+	"github.com/philjestin/boatmanmode/internal/checkpoint"
+	"github.com/philjestin/boatmanmode/internal/claude"
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/cost"
+	"github.com/philjestin/boatmanmode/internal/executor"
+	"github.com/philjestin/boatmanmode/internal/gitops"
+	"github.com/philjestin/boatmanmode/internal/handoff"
+	"github.com/philjestin/boatmanmode/internal/planner"
+	"github.com/philjestin/boatmanmode/internal/scottbott"
+)
+
+// RunContext is what a run recorded about itself in its checkpoint.
+type RunContext struct {
+	Checkpoint *checkpoint.Checkpoint
+	Task       agent.TaskRecord
+	Plan       *planner.Plan
+	Execution  *agent.ExecutionRecord
+	Review     *scottbott.ReviewResult
+	// Reviews holds every review of the run in order; older runs only
+	// recorded the initial and final reviews
+	Reviews []*scottbott.ReviewResult
+}
+
+// LoadRunContext collects the task, plan, execution summary and latest
+// review from a run's checkpoint. Runs from older versions may only have
+// the ticket ID and worktree.
+func LoadRunContext(cp *checkpoint.Checkpoint) (*RunContext, error) {
+	rc := &RunContext{Checkpoint: cp, Task: agent.TaskRecord{ID: cp.TicketID}}
+	if _, err := cp.StepOutput(checkpoint.StepFetchTicket, &rc.Task); err != nil {
+		return nil, err
+	}
+
+	// The plan as the operator approved it, else as planned
+	for _, step := range []checkpoint.Step{checkpoint.StepPlanReview, checkpoint.StepPlanning} {
+		var plan planner.Plan
+		if ok, err := cp.StepOutput(step, &plan); err != nil {
+			return nil, err
+		} else if ok {
+			rc.Plan = &plan
+			break
+		}
+	}
+
+	var execution agent.ExecutionRecord
+	if ok, err := cp.StepOutput(checkpoint.StepExecution, &execution); err != nil {
+		return nil, err
+	} else if ok {
+		rc.Execution = &execution
+	}
+
+	var initial scottbott.ReviewResult
+	if ok, err := cp.StepOutput(checkpoint.StepTesting, &initial); err != nil {
+		return nil, err
+	} else if ok {
+		rc.Review = &initial
+		rc.Reviews = []*scottbott.ReviewResult{&initial}
+	}
+
+	// The refactor loop's final review supersedes the initial one
+	var record agent.ReviewRecord
+	if ok, err := cp.StepOutput(checkpoint.StepReview, &record); err != nil {
+		return nil, err
+	} else if ok && record.ReviewResult != nil {
+		rc.Review = record.ReviewResult
+		if len(record.History) > 0 {
+			rc.Reviews = record.History
+		} else {
+			rc.Reviews = append(rc.Reviews, record.ReviewResult)
+		}
+	}
+	return rc, nil
+}
+
+// Diff returns the run's changes in its worktree against baseBranch,
+// including anything not yet committed.
+func (rc *RunContext) Diff(baseBranch string) (string, error) {
+	git := gitops.New(rc.Checkpoint.WorktreePath)
+	base := baseBranch
+	if git.RefExists("refs/remotes/origin/" + base) {
+		base = "origin/" + base
+	}
+	mergeBase, err := git.Run("merge-base", base, "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to find merge base with %s: %w", base, err)
+	}
+	diff, err := git.Diff(strings.TrimSpace(mergeBase))
+	if err != nil {
+		return "", err
+	}
+	return gitops.FilterLFSDiff(diff), nil
+}
+
+// FilesChanged returns the files the run changed.
+func (rc *RunContext) FilesChanged() []string {
+	if rc.Execution == nil {
+		return nil
+	}
+	return rc.Execution.FilesChanged
+}
+
+// Summary describes the run for the operator and for Claude.
+func (rc *RunContext) Summary() string {
+	var sb strings.Builder
+	title := rc.Task.Title
+	if title == "" {
+		title = rc.Task.ID
+	}
+	sb.WriteString(fmt.Sprintf("# Run: %s (%s)\n\n", title, rc.Task.ID))
+	sb.WriteString(fmt.Sprintf("- Branch: %s\n", rc.Checkpoint.BranchName))
+	sb.WriteString(fmt.Sprintf("- Worktree: %s\n", rc.Checkpoint.WorktreePath))
+	sb.WriteString(fmt.Sprintf("- Step: %s, iteration %d/%d\n", rc.Checkpoint.CurrentStep, rc.Checkpoint.Iteration, rc.Checkpoint.MaxIterations))
+	if rc.Checkpoint.Error != "" {
+		sb.WriteString(fmt.Sprintf("- Error: %s\n", rc.Checkpoint.Error))
+	}
+
+	if rc.Task.Description != "" {
+		sb.WriteString("\n## Task\n\n")
+		sb.WriteString(strings.TrimSpace(rc.Task.Description))
+		sb.WriteString("\n")
+	}
+
+	if rc.Plan != nil {
+		sb.WriteString("\n## Plan\n\n")
+		sb.WriteString(rc.Plan.Summary)
+		sb.WriteString("\n")
+		for i, step := range rc.Plan.Approach {
+			sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, step))
+		}
+	}
+
+	if rc.Execution != nil {
+		sb.WriteString("\n## Files Changed\n\n")
+		for _, f := range rc.Execution.FilesChanged {
+			sb.WriteString(fmt.Sprintf("- %s\n", f))
+		}
+		if len(rc.Execution.HumanEdited) > 0 {
+			sb.WriteString(fmt.Sprintf("\nEdited by hand: %s\n", strings.Join(rc.Execution.HumanEdited, ", ")))
+		}
+	}
+
+	if r := rc.Review; r != nil {
+		verdict := "failed"
+		if r.Passed {
+			verdict = "passed"
+		}
+		sb.WriteString(fmt.Sprintf("\n## Latest Review (%s, score %d)\n\n", verdict, r.Score))
+		if r.Summary != "" {
+			sb.WriteString(r.Summary)
+			sb.WriteString("\n")
+		}
+		for _, issue := range r.Issues {
+			location := issue.File
+			if location != "" && issue.Line > 0 {
+				location = fmt.Sprintf("%s:%d", issue.File, issue.Line)
+			}
+			if location != "" {
+				location = " (" + location + ")"
+			}
+			sb.WriteString(fmt.Sprintf("- [%s] %s%s\n", issue.Severity, issue.Description, location))
+		}
+	}
+	return sb.String()
+}
+
+// Exchange is one question and answer.
+type Exchange struct {
+	Question string
+	Answer   string
+}
+
+// Adjustment is the outcome of an ad-hoc instruction.
+type Adjustment struct {
+	FilesChanged []string
+	Diff         string // Changes made by the adjustment alone
+	Review       *scottbott.ReviewResult
+	tree         string // Index tree from before the adjustment, for Undo
+}
+
+// Session is a conversation about one run.
+type Session struct {
+	run         *RunContext
+	cfg         *config.Config
+	git         *gitops.Repo
+	client      *claude.Client
+	history     []Exchange
+	adjustments int
+	last        *Adjustment
+	costTracker *cost.Tracker
+}
+
+// historyTokens caps how much earlier conversation is resent per question.
+const historyTokens = 4000
+
+// New starts a session in the run's worktree.
+func New(run *RunContext, cfg *config.Config) (*Session, error) {
+	path := run.Checkpoint.WorktreePath
+	if path == "" {
+		return nil, errors.New("run has no worktree recorded")
+	}
+	if _, err := os.Stat(path); err != nil {
+		return nil, fmt.Errorf("worktree %s is gone: %w", path, err)
+	}
+
+	// Questions only need to read the code
+	client := claude.NewWithTools(path, "chat", []string{"Read", "Grep", "Glob"})
+	client.EnablePromptCaching = cfg.Claude.EnablePromptCaching
+	client.Configure(cfg.Claude)
+
+	return &Session{
+		run:         run,
+		cfg:         cfg,
+		git:         gitops.New(path),
+		client:      client,
+		costTracker: cost.NewTracker(),
+	}, nil
+}
+
+// Cost returns the Claude cost of the session so far.
+func (s *Session) Cost() *cost.Tracker {
+	return s.costTracker
+}
+
+// Diff returns the run's changes against the base branch, including
+// anything not yet committed.
+func (s *Session) Diff() (string, error) {
+	return s.run.Diff(s.cfg.BaseBranch)
+}
+
+const askSystemPrompt = `You are the boatman agent that worked on the run described below.
+The operator is asking about the changes: why something was done, how it works,
+or what the review found. Answer from the run context and the code in the
+worktree. Be concise and reference files and lines. Do not modify any files.`
+
+// Ask answers a question about the run.
+func (s *Session) Ask(ctx context.Context, question string) (string, error) {
+	diff, err := s.Diff()
+	if err != nil {
+		diff = fmt.Sprintf("(diff unavailable: %v)", err)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(s.run.Summary())
+	sb.WriteString("\n## Current Diff\n\n```diff\n")
+	sb.WriteString(handoff.TruncateToTokens(diff, handoff.DefaultBudget.Context))
+	sb.WriteString("\n```\n")
+	if len(s.history) > 0 {
+		var history strings.Builder
+		for _, ex := range s.history {
+			history.WriteString(fmt.Sprintf("Operator: %s\n\nYou: %s\n\n", ex.Question, ex.Answer))
+		}
+		sb.WriteString("\n## Conversation So Far\n\n")
+		sb.WriteString(lastTokens(history.String(), historyTokens))
+	}
+	sb.WriteString("\n## Question\n\n")
+	sb.WriteString(question)
+
+	answer, usage, err := s.client.Message(ctx, askSystemPrompt, sb.String())
+	if err != nil {
+		return "", fmt.Errorf("failed to call Claude: %w", err)
+	}
+	if usage != nil {
+		s.costTracker.Add("Chat", *usage)
+	}
+	answer = strings.TrimSpace(answer)
+	s.history = append(s.history, Exchange{Question: question, Answer: answer})
+	return answer, nil
+}
+
+// Instruct applies an ad-hoc adjustment with the refactor agent, stages
+// it, and reviews the result. The adjustment stays in the worktree either
+// way; Undo reverts it.
+func (s *Session) Instruct(ctx context.Context, instruction string) (*Adjustment, error) {
+	path := s.run.Checkpoint.WorktreePath
+	if err := s.git.AddAll(); err != nil {
+		return nil, fmt.Errorf("failed to stage changes: %w", err)
+	}
+	before, err := s.git.WriteTree()
+	if err != nil {
+		return nil, err
+	}
+
+	s.adjustments++
+	refactorExec := executor.NewRefactorExecutor(path, s.run.Checkpoint.Iteration+s.adjustments, s.cfg)
+	currentCode, _ := refactorExec.GetSpecificFiles(s.run.FilesChanged())
+	refactorHandoff := &handoff.RefactorHandoff{
+		TicketID:      s.run.Task.ID,
+		Title:         s.run.Task.Title,
+		Requirements:  s.run.Task.Description,
+		Issues:        []string{"Operator request: " + instruction},
+		Guidance:      "Make only the adjustment the operator asked for; keep the rest of the change as it is.",
+		FilesToUpdate: s.run.FilesChanged(),
+		CurrentCode:   currentCode,
+		ProjectRules:  refactorExec.LoadProjectRules(),
+	}
+	result, usage, err := refactorExec.RefactorWithHandoff(ctx, refactorHandoff)
+	if err != nil {
+		return nil, err
+	}
+	if usage != nil {
+		s.costTracker.Add(fmt.Sprintf("Adjustment #%d", s.adjustments), *usage)
+	}
+	if !result.Success {
+		return nil, fmt.Errorf("adjustment failed: %v", result.Error)
+	}
+
+	if err := s.git.AddAll(); err != nil {
+		return nil, fmt.Errorf("failed to stage changes: %w", err)
+	}
+	diff, err := s.git.Diff("--cached", before)
+	if err != nil {
+		return nil, err
+	}
+	adj := &Adjustment{FilesChanged: result.FilesChanged, Diff: gitops.FilterLFSDiff(diff), tree: before}
+	s.last = adj
+	if strings.TrimSpace(adj.Diff) == "" {
+		return adj, nil
+	}
+
+	if s.run.Execution != nil {
+		for _, f := range result.FilesChanged {
+			if !slices.Contains(s.run.Execution.FilesChanged, f) {
+				s.run.Execution.FilesChanged = append(s.run.Execution.FilesChanged, f)
+			}
+		}
+	}
+
+	// Review the adjustment on its own, against the instruction
+	reviewHandoff := &handoff.ReviewHandoff{
+		TicketID:     s.run.Task.ID,
+		Title:        s.run.Task.Title,
+		Requirements: fmt.Sprintf("%s\n\nThis diff is a follow-up adjustment requested by the operator: %s", s.run.Task.Description, instruction),
+		Diff:         adj.Diff,
+		FilesChanged: result.FilesChanged,
+	}
+	reviewer := scottbott.NewWithSkill(path, s.run.Checkpoint.Iteration+s.adjustments, s.cfg.ReviewSkill, s.cfg)
+	review, usage, err := reviewer.Review(ctx, reviewHandoff.ForTokenBudget(handoff.DefaultBudget.Context), adj.Diff)
+	if err != nil {
+		return adj, fmt.Errorf("review failed: %w", err)
+	}
+	if usage != nil {
+		s.costTracker.Add(fmt.Sprintf("Adjustment review #%d", s.adjustments), *usage)
+	}
+	adj.Review = review
+	return adj, nil
+}
+
+// Undo reverts the last adjustment, restoring the worktree to how it was
+// before it.
+func (s *Session) Undo() error {
+	if s.last == nil {
+		return errors.New("no adjustment to undo")
+	}
+	if _, err := s.git.Run("read-tree", "-u", "--reset", s.last.tree); err != nil {
+		return fmt.Errorf("failed to undo adjustment: %w", err)
+	}
+	s.last = nil
+	return nil
+}
+
+// lastTokens keeps the end of s within a token budget.
+func lastTokens(s string, maxTokens int) string {
+	maxChars := maxTokens * 4
+	if len(s) <= maxChars {
+		return s
+	}
+	return "... (earlier conversation omitted)\n" + s[len(s)-maxChars:]
+}
@@ -0,0 +1,152 @@
+package chat
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/agent"
+	"github.com/philjestin/boatmanmode/internal/checkpoint"
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/planner"
+	"github.com/philjestin/boatmanmode/internal/scottbott"
+)
+
+func git(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+// recordRun saves a checkpoint like the agent does for a finished run.
+func recordRun(t *testing.T, worktreePath string) *checkpoint.Checkpoint {
+	t.Helper()
+	mgr, err := checkpoint.NewManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp := mgr.Start("ENG-42", 3)
+	mgr.SetWorktree(worktreePath, "eng-42-add-retries")
+	for _, s := range []struct {
+		step   checkpoint.Step
+		output interface{}
+	}{
+		{checkpoint.StepFetchTicket, agent.TaskRecord{ID: "ENG-42", Title: "Add retries", Description: "Retry failed uploads."}},
+		{checkpoint.StepPlanning, &planner.Plan{Summary: "Wrap uploads in retry.Do", Approach: []string{"Add retry loop"}}},
+		{checkpoint.StepExecution, agent.ExecutionRecord{FilesChanged: []string{"upload.go"}}},
+		{checkpoint.StepTesting, &scottbott.ReviewResult{Score: 50, Issues: []scottbott.Issue{{Severity: "major", Description: "No backoff"}}}},
+		{checkpoint.StepReview, &scottbott.ReviewResult{Passed: true, Score: 90, Issues: []scottbott.Issue{{Severity: "minor", File: "upload.go", Line: 12, Description: "Magic number"}}}},
+	} {
+		mgr.BeginStep(s.step)
+		mgr.CompleteStep(s.step, s.output)
+	}
+	mgr.Finish()
+
+	resumed, err := mgr.Resume(cp.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resumed
+}
+
+func TestLoadRunContext(t *testing.T) {
+	rc, err := LoadRunContext(recordRun(t, "/tmp/wt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if rc.Task.Title != "Add retries" || rc.Plan == nil || rc.Plan.Summary != "Wrap uploads in retry.Do" {
+		t.Errorf("Unexpected task %+v / plan %+v", rc.Task, rc.Plan)
+	}
+	if got := rc.FilesChanged(); len(got) != 1 || got[0] != "upload.go" {
+		t.Errorf("Expected upload.go changed, got %v", got)
+	}
+	if rc.Review == nil || rc.Review.Score != 90 {
+		t.Fatalf("Expected the final review, got %+v", rc.Review)
+	}
+
+	summary := rc.Summary()
+	for _, want := range []string{
+		"# Run: Add retries (ENG-42)",
+		"- Branch: eng-42-add-retries",
+		"1. Add retry loop",
+		"## Latest Review (passed, score 90)",
+		"- [minor] Magic number (upload.go:12)",
+	} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Expected %q in summary:\n%s", want, summary)
+		}
+	}
+}
+
+func TestLoadRunContextWithoutOutputs(t *testing.T) {
+	mgr, _ := checkpoint.NewManager(t.TempDir())
+	cp := mgr.Start("ENG-7", 3)
+
+	rc, err := LoadRunContext(cp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rc.Task.ID != "ENG-7" || rc.Plan != nil || rc.Review != nil || rc.FilesChanged() != nil {
+		t.Errorf("Expected only the ticket ID, got %+v", rc)
+	}
+}
+
+func TestSessionDiffAndUndo(t *testing.T) {
+	dir := t.TempDir()
+	git(t, dir, "init", "-q", "-b", "main")
+	git(t, dir, "config", "user.email", "test@example.com")
+	git(t, dir, "config", "user.name", "Test")
+	os.WriteFile(filepath.Join(dir, "upload.go"), []byte("package upload\n"), 0644)
+	git(t, dir, "add", "-A")
+	git(t, dir, "commit", "-qm", "initial")
+
+	rc, err := LoadRunContext(recordRun(t, dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := New(rc, &config.Config{BaseBranch: "main"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Undo(); err == nil {
+		t.Error("Expected an error with nothing to undo")
+	}
+
+	// The run's own change, then an adjustment made on top of it
+	os.WriteFile(filepath.Join(dir, "upload.go"), []byte("package upload\n\nconst retries = 3\n"), 0644)
+	git(t, dir, "add", "-A")
+	tree, _ := s.git.WriteTree()
+	os.WriteFile(filepath.Join(dir, "backoff.go"), []byte("package upload\n"), 0644)
+	git(t, dir, "add", "-A")
+	s.last = &Adjustment{tree: tree}
+
+	diff, err := s.Diff()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(diff, "const retries = 3") || !strings.Contains(diff, "backoff.go") {
+		t.Errorf("Expected run and adjustment changes in diff:\n%s", diff)
+	}
+
+	if err := s.Undo(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "backoff.go")); !os.IsNotExist(err) {
+		t.Error("Expected undo to remove the adjustment's new file")
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "upload.go")); !strings.Contains(string(data), "retries") {
+		t.Error("Expected undo to keep the run's own change")
+	}
+}
+
+func TestNewRequiresWorktree(t *testing.T) {
+	rc, _ := LoadRunContext(recordRun(t, filepath.Join(t.TempDir(), "removed")))
+	if _, err := New(rc, &config.Config{}); err == nil {
+		t.Error("Expected an error for a missing worktree")
+	}
+}
@@ -0,0 +1,57 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// CancelRequest asks the run working on a ticket to stop. Runs poll for
+// requests, which works the same on every platform and for runs in the
+// background or on a daemon.
+type CancelRequest struct {
+	// RemoveWorktree removes the run's worktree once it has stopped, so it
+	// cannot be resumed
+	RemoveWorktree bool      `json:"remove_worktree,omitempty"`
+	RequestedAt    time.Time `json:"requested_at"`
+}
+
+// RequestCancel asks the run holding ticketID to stop.
+func (m *Manager) RequestCancel(ticketID string, req CancelRequest) error {
+	path := m.cancelPath(ticketID)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create cancel directory: %w", err)
+	}
+	if req.RequestedAt.IsZero() {
+		req.RequestedAt = time.Now()
+	}
+	data, _ := json.Marshal(req)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to request cancel: %w", err)
+	}
+	return nil
+}
+
+// CancelRequested returns the pending request to stop ticketID's run.
+func (m *Manager) CancelRequested(ticketID string) (CancelRequest, bool) {
+	var req CancelRequest
+	data, err := os.ReadFile(m.cancelPath(ticketID))
+	if err != nil || json.Unmarshal(data, &req) != nil {
+		return req, false
+	}
+	return req, true
+}
+
+// ClearCancel removes the request to stop ticketID's run, once it has
+// stopped or before a new run starts.
+func (m *Manager) ClearCancel(ticketID string) {
+	os.Remove(m.cancelPath(ticketID))
+}
+
+func (m *Manager) cancelPath(ticketID string) string {
+	name := strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(ticketID)
+	return filepath.Join(m.BaseDir, "cancels", name+".json")
+}
@@ -0,0 +1,49 @@
+package checkpoint
+
+import "testing"
+
+func TestCancelRequest(t *testing.T) {
+	m, err := NewManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := m.CancelRequested("ENG-123"); ok {
+		t.Fatal("cancel requested before any request")
+	}
+	if err := m.RequestCancel("ENG-123", CancelRequest{RemoveWorktree: true}); err != nil {
+		t.Fatal(err)
+	}
+	req, ok := m.CancelRequested("ENG-123")
+	if !ok || !req.RemoveWorktree || req.RequestedAt.IsZero() {
+		t.Errorf("CancelRequested = %+v, %v", req, ok)
+	}
+	if _, ok := m.CancelRequested("ENG-456"); ok {
+		t.Error("cancel requested for another ticket")
+	}
+	m.ClearCancel("ENG-123")
+	if _, ok := m.CancelRequested("ENG-123"); ok {
+		t.Error("cancel still requested after ClearCancel")
+	}
+}
+
+func TestCancel(t *testing.T) {
+	m, err := NewManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.Start("ENG-123", 3)
+	m.BeginStep(StepExecution)
+	m.Cancel("interrupted")
+	m.Finish()
+
+	cp, err := m.Resume(m.Current.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp.Cancelled != "interrupted" || cp.InFlight() || cp.CurrentStep == StepComplete {
+		t.Errorf("checkpoint = %+v, want cancelled and not finished", cp)
+	}
+	if !cp.CanResume() || cp.GetResumePoint() != StepFetchTicket {
+		t.Errorf("cancelled run resumes at %s (CanResume %v)", cp.GetResumePoint(), cp.CanResume())
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
 	"strings"
 	"time"
@@ -19,8 +20,10 @@ const (
 	StepFetchTicket   Step = "fetch_ticket"
 	StepCreateWorktree Step = "create_worktree"
 	StepPlanning      Step = "planning"
+	StepPlanReview    Step = "plan_review"
 	StepValidation    Step = "validation"
 	StepExecution     Step = "execution"
+	StepLint          Step = "lint"
 	StepTesting       Step = "testing"
 	StepReview        Step = "review"
 	StepRefactor      Step = "refactor"
@@ -68,6 +71,19 @@ type Checkpoint struct {
 	UpdatedAt time.Time `json:"updated_at"`
 	// Error holds any error message
 	Error string `json:"error,omitempty"`
+	// PID is the process running the workflow, for detecting abandoned runs
+	PID int `json:"pid,omitempty"`
+	// CostUSD is the Claude cost accumulated so far
+	CostUSD float64 `json:"cost_usd,omitempty"`
+	// Settings records the models and options the run used, so runs of
+	// the same ticket can be compared
+	Settings map[string]string `json:"settings,omitempty"`
+	// Paused says what the run is waiting on, e.g. plan approval; it
+	// continues with boatman resume
+	Paused string `json:"paused,omitempty"`
+	// Cancelled says why the run was stopped before finishing, by boatman
+	// cancel or an interrupt; it continues with boatman resume
+	Cancelled string `json:"cancelled,omitempty"`
 }
 
 // StepRecord records completion of a step.
@@ -108,9 +124,20 @@ func NewManager(baseDir string) (*Manager, error) {
 
 // Start begins a new checkpoint for a ticket.
 func (m *Manager) Start(ticketID string, maxIterations int) *Checkpoint {
+	return m.StartWithID(NewID(ticketID), ticketID, maxIterations)
+}
+
+// NewID returns the ID of a checkpoint for ticketID started now.
+func NewID(ticketID string) string {
+	return fmt.Sprintf("%s-%d", ticketID, time.Now().Unix())
+}
+
+// StartWithID begins a new checkpoint with an ID chosen in advance, such
+// as one reported for a run started in the background.
+func (m *Manager) StartWithID(id, ticketID string, maxIterations int) *Checkpoint {
 	now := time.Now()
 	m.Current = &Checkpoint{
-		ID:            fmt.Sprintf("%s-%d", ticketID, now.Unix()),
+		ID:            id,
 		TicketID:      ticketID,
 		CurrentStep:   StepFetchTicket,
 		StepHistory:   []StepRecord{},
@@ -118,6 +145,7 @@ func (m *Manager) Start(ticketID string, maxIterations int) *Checkpoint {
 		MaxIterations: maxIterations,
 		CreatedAt:     now,
 		UpdatedAt:     now,
+		PID:           os.Getpid(),
 	}
 	return m.Current
 }
@@ -151,16 +179,14 @@ func (m *Manager) ResumeLatest(ticketID string) (*Checkpoint, error) {
 	}
 
 	// Sort by updated time, most recent first
-	sort.Slice(checkpoints, func(i, j int) bool {
-		return checkpoints[i].UpdatedAt.After(checkpoints[j].UpdatedAt)
-	})
+	SortRecent(checkpoints)
 
 	return m.Resume(checkpoints[0].ID)
 }
 
 // BeginStep marks the start of a step.
 func (m *Manager) BeginStep(step Step) {
-	if m.Current == nil {
+	if m == nil || m.Current == nil {
 		return
 	}
 
@@ -177,9 +203,28 @@ func (m *Manager) BeginStep(step Step) {
 	m.Save()
 }
 
+// SkipStep records a step the run does not take, such as one its pipeline
+// leaves out. Resuming goes on after it.
+func (m *Manager) SkipStep(step Step) {
+	if m == nil || m.Current == nil {
+		return
+	}
+
+	now := time.Now()
+	m.Current.StepHistory = append(m.Current.StepHistory, StepRecord{
+		Step:        step,
+		Status:      StatusSkipped,
+		StartedAt:   now,
+		CompletedAt: now,
+	})
+	m.Current.UpdatedAt = now
+
+	m.Save()
+}
+
 // CompleteStep marks a step as complete.
 func (m *Manager) CompleteStep(step Step, output interface{}) {
-	if m.Current == nil {
+	if m == nil || m.Current == nil {
 		return
 	}
 
@@ -202,7 +247,7 @@ func (m *Manager) CompleteStep(step Step, output interface{}) {
 
 // FailStep marks a step as failed.
 func (m *Manager) FailStep(step Step, err error) {
-	if m.Current == nil {
+	if m == nil || m.Current == nil {
 		return
 	}
 
@@ -226,7 +271,7 @@ func (m *Manager) FailStep(step Step, err error) {
 
 // SetWorktree records the worktree path.
 func (m *Manager) SetWorktree(path, branchName string) {
-	if m.Current == nil {
+	if m == nil || m.Current == nil {
 		return
 	}
 	m.Current.WorktreePath = path
@@ -234,18 +279,68 @@ func (m *Manager) SetWorktree(path, branchName string) {
 	m.Save()
 }
 
+// SetSettings records the run's models and options.
+func (m *Manager) SetSettings(settings map[string]string) {
+	if m == nil || m.Current == nil {
+		return
+	}
+	m.Current.Settings = settings
+	m.Save()
+}
+
 // SetIteration updates the current iteration.
 func (m *Manager) SetIteration(iteration int) {
-	if m.Current == nil {
+	if m == nil || m.Current == nil {
 		return
 	}
 	m.Current.Iteration = iteration
 	m.Save()
 }
 
+// SetCost records the cost accumulated so far.
+func (m *Manager) SetCost(usd float64) {
+	if m == nil || m.Current == nil {
+		return
+	}
+	m.Current.CostUSD = usd
+	m.Save()
+}
+
+// Pause records that the run stopped to wait on the operator, leaving its
+// current step to be run again on resume.
+func (m *Manager) Pause(reason string) {
+	if m == nil || m.Current == nil {
+		return
+	}
+	m.Current.Paused = reason
+	m.Current.UpdatedAt = time.Now()
+	m.Save()
+}
+
+// Cancel records that the run was stopped, leaving its current step to be
+// run again on resume.
+func (m *Manager) Cancel(reason string) {
+	if m == nil || m.Current == nil {
+		return
+	}
+	m.Current.Cancelled = reason
+	m.Current.UpdatedAt = time.Now()
+	m.Save()
+}
+
+// Finish marks the workflow complete unless a step failed, it paused or
+// it was cancelled.
+func (m *Manager) Finish() {
+	if m == nil || m.Current == nil || m.Current.Error != "" || m.Current.Paused != "" || m.Current.Cancelled != "" {
+		return
+	}
+	m.Current.CurrentStep = StepComplete
+	m.Save()
+}
+
 // SaveState saves arbitrary state for the current step.
 func (m *Manager) SaveState(state interface{}) error {
-	if m.Current == nil {
+	if m == nil || m.Current == nil {
 		return nil
 	}
 
@@ -269,7 +364,7 @@ func (m *Manager) LoadState(into interface{}) error {
 
 // Save persists the current checkpoint to disk.
 func (m *Manager) Save() error {
-	if m.Current == nil {
+	if m == nil || m.Current == nil {
 		return nil
 	}
 
@@ -375,6 +470,34 @@ func (m *Manager) ListForTicket(ticketID string) ([]Checkpoint, error) {
 	return filtered, nil
 }
 
+// SortRecent sorts checkpoints by last update, most recent first.
+func SortRecent(checkpoints []Checkpoint) {
+	sort.Slice(checkpoints, func(i, j int) bool {
+		return checkpoints[i].UpdatedAt.After(checkpoints[j].UpdatedAt)
+	})
+}
+
+// RecentTicketIDs returns up to limit unique ticket IDs, most recently
+// worked on first.
+func RecentTicketIDs(checkpoints []Checkpoint, limit int) []string {
+	sorted := append([]Checkpoint(nil), checkpoints...)
+	SortRecent(sorted)
+
+	seen := make(map[string]bool)
+	var ids []string
+	for _, cp := range sorted {
+		if cp.TicketID == "" || seen[cp.TicketID] {
+			continue
+		}
+		seen[cp.TicketID] = true
+		ids = append(ids, cp.TicketID)
+		if len(ids) == limit {
+			break
+		}
+	}
+	return ids
+}
+
 // HasIncompleteCheckpoint checks if there's an incomplete checkpoint for a ticket.
 func (m *Manager) HasIncompleteCheckpoint(ticketID string) bool {
 	checkpoints, err := m.ListForTicket(ticketID)
@@ -458,6 +581,22 @@ func (m *Manager) checkpointPath(id string) string {
 	return filepath.Join(m.BaseDir, id+".json")
 }
 
+// InFlight reports whether the workflow has neither completed, failed,
+// paused nor been cancelled. The process may still have died; compare PID
+// against running processes.
+func (cp *Checkpoint) InFlight() bool {
+	return cp.CurrentStep != StepComplete && cp.Error == "" && cp.Paused == "" && cp.Cancelled == ""
+}
+
+// Elapsed returns the time since the workflow started, or its total
+// duration once it is no longer in flight.
+func (cp *Checkpoint) Elapsed(now time.Time) time.Duration {
+	if !cp.InFlight() {
+		return cp.UpdatedAt.Sub(cp.CreatedAt)
+	}
+	return now.Sub(cp.CreatedAt)
+}
+
 // CanResume checks if a checkpoint can be resumed.
 func (cp *Checkpoint) CanResume() bool {
 	// Can resume if not complete and not failed
@@ -492,7 +631,7 @@ func (cp *Checkpoint) GetResumePoint() Step {
 	// Find the last successful step and return the next one
 	for i := len(cp.StepHistory) - 1; i >= 0; i-- {
 		record := cp.StepHistory[i]
-		if record.Status == StatusComplete {
+		if record.Status == StatusComplete || record.Status == StatusSkipped {
 			return getNextStep(record.Step)
 		}
 	}
@@ -501,23 +640,30 @@ func (cp *Checkpoint) GetResumePoint() Step {
 	return StepFetchTicket
 }
 
+// stepOrder is the order of the steps in the workflow.
+var stepOrder = []Step{
+	StepFetchTicket, StepCreateWorktree, StepPlanning, StepPlanReview, StepValidation,
+	StepExecution, StepLint, StepTesting, StepReview, StepRefactor, StepVerify,
+	StepCommit, StepPush, StepCreatePR, StepComplete,
+}
+
 // getNextStep returns the next step in the workflow.
 func getNextStep(current Step) Step {
-	order := []Step{
-		StepFetchTicket, StepCreateWorktree, StepPlanning, StepValidation,
-		StepExecution, StepTesting, StepReview, StepRefactor, StepVerify,
-		StepCommit, StepPush, StepCreatePR, StepComplete,
-	}
-
-	for i, s := range order {
-		if s == current && i < len(order)-1 {
-			return order[i+1]
+	for i, s := range stepOrder {
+		if s == current && i < len(stepOrder)-1 {
+			return stepOrder[i+1]
 		}
 	}
 
 	return StepComplete
 }
 
+// Before reports whether step comes before other in the workflow, so a
+// run resuming at other can skip it.
+func Before(step, other Step) bool {
+	return slices.Index(stepOrder, step) < slices.Index(stepOrder, other)
+}
+
 // FormatCheckpoint returns a formatted summary.
 func (cp *Checkpoint) FormatCheckpoint() string {
 	var sb strings.Builder
@@ -529,10 +675,19 @@ func (cp *Checkpoint) FormatCheckpoint() string {
 	sb.WriteString(fmt.Sprintf("  Iteration: %d/%d\n", cp.Iteration, cp.MaxIterations))
 	sb.WriteString(fmt.Sprintf("  Created: %s\n", cp.CreatedAt.Format(time.RFC3339)))
 	sb.WriteString(fmt.Sprintf("  Updated: %s\n", cp.UpdatedAt.Format(time.RFC3339)))
+	if cp.CostUSD > 0 {
+		sb.WriteString(fmt.Sprintf("  Cost: $%.2f\n", cp.CostUSD))
+	}
 
 	if cp.Error != "" {
 		sb.WriteString(fmt.Sprintf("  Error: %s\n", cp.Error))
 	}
+	if cp.Paused != "" {
+		sb.WriteString(fmt.Sprintf("  Paused: %s\n", cp.Paused))
+	}
+	if cp.Cancelled != "" {
+		sb.WriteString(fmt.Sprintf("  Cancelled: %s\n", cp.Cancelled))
+	}
 
 	sb.WriteString("  Step History:\n")
 	for _, record := range cp.StepHistory {
@@ -557,3 +712,24 @@ func (cp *Checkpoint) FormatCheckpoint() string {
 
 	return sb.String()
 }
+
+// StepOutput decodes the output of the most recent completed run of step
+// into into, reporting whether there was one.
+func (cp *Checkpoint) StepOutput(step Step, into interface{}) (bool, error) {
+	for i := len(cp.StepHistory) - 1; i >= 0; i-- {
+		record := cp.StepHistory[i]
+		if record.Step != step || record.Status != StatusComplete || record.Output == nil {
+			continue
+		}
+		// Output is a generic map once loaded from disk; round-trip it
+		data, err := json.Marshal(record.Output)
+		if err != nil {
+			return false, err
+		}
+		if err := json.Unmarshal(data, into); err != nil {
+			return false, fmt.Errorf("failed to decode %s output: %w", step, err)
+		}
+		return true, nil
+	}
+	return false, nil
+}
@@ -391,6 +391,29 @@ func TestGetResumePoint(t *testing.T) {
 	}
 }
 
+func TestStartWithID(t *testing.T) {
+	manager, _ := NewManager(t.TempDir())
+	cp := manager.StartWithID("ENG-123-42", "ENG-123", 3)
+	if cp.ID != "ENG-123-42" || cp.TicketID != "ENG-123" {
+		t.Errorf("Expected the given ID, got %+v", cp)
+	}
+	if err := manager.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.Resume("ENG-123-42"); err != nil {
+		t.Errorf("Expected the checkpoint saved under its ID: %v", err)
+	}
+}
+
+func TestBefore(t *testing.T) {
+	if !Before(StepPlanning, StepExecution) {
+		t.Error("Planning should come before execution")
+	}
+	if Before(StepCommit, StepRefactor) || Before(StepReview, StepReview) {
+		t.Error("Commit should not come before refactor, nor a step before itself")
+	}
+}
+
 func TestDelete(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "checkpoint-test")
 	if err != nil {
@@ -484,3 +507,166 @@ func TestFormatProgress(t *testing.T) {
 		t.Error("FormatProgress should return content")
 	}
 }
+
+func TestProgressTrackingForStatus(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "checkpoint-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	manager, _ := NewManager(tmpDir)
+	cp := manager.Start("ENG-7", 3)
+
+	if cp.PID != os.Getpid() {
+		t.Errorf("Expected PID %d, got %d", os.Getpid(), cp.PID)
+	}
+	if !cp.InFlight() {
+		t.Error("Expected new checkpoint to be in flight")
+	}
+
+	manager.SetCost(1.25)
+	manager.Finish()
+
+	loaded, err := manager.Resume(cp.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.CostUSD != 1.25 {
+		t.Errorf("Expected cost 1.25, got %f", loaded.CostUSD)
+	}
+	if loaded.InFlight() {
+		t.Error("Expected finished checkpoint not to be in flight")
+	}
+
+	// Elapsed stops at the last update once finished
+	if got := loaded.Elapsed(time.Now().Add(time.Hour)); got >= time.Hour {
+		t.Errorf("Expected elapsed to stop when finished, got %s", got)
+	}
+}
+
+func TestFinishKeepsFailure(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "checkpoint-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	manager, _ := NewManager(tmpDir)
+	manager.Start("ENG-8", 3)
+	manager.BeginStep(StepExecution)
+	manager.FailStep(StepExecution, os.ErrPermission)
+	manager.Finish()
+
+	if manager.Current.CurrentStep != StepExecution {
+		t.Errorf("Expected failed step to remain current, got %s", manager.Current.CurrentStep)
+	}
+}
+
+func TestPause(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "checkpoint-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	manager, _ := NewManager(tmpDir)
+	manager.Start("ENG-9", 3)
+	manager.BeginStep(StepPlanning)
+	manager.CompleteStep(StepPlanning, nil)
+	manager.BeginStep(StepPlanReview)
+	manager.Pause("plan awaiting approval")
+	manager.Finish()
+
+	loaded, err := manager.Resume(manager.Current.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.CurrentStep != StepPlanReview || loaded.Paused == "" {
+		t.Errorf("Expected paused at plan_review, got %s (paused %q)", loaded.CurrentStep, loaded.Paused)
+	}
+	if loaded.InFlight() {
+		t.Error("Expected a paused run not to be in flight")
+	}
+	if !loaded.CanResume() || loaded.GetResumePoint() != StepPlanReview {
+		t.Errorf("Expected to resume at plan_review, got %s", loaded.GetResumePoint())
+	}
+}
+
+func TestNilManagerIsNoOp(t *testing.T) {
+	var manager *Manager
+	manager.BeginStep(StepPlanning)
+	manager.CompleteStep(StepPlanning, nil)
+	manager.SetIteration(2)
+	manager.SetCost(1)
+	manager.Finish()
+	if err := manager.Save(); err != nil {
+		t.Errorf("Expected nil manager Save to succeed, got %v", err)
+	}
+}
+
+func TestRecentTicketIDs(t *testing.T) {
+	now := time.Now()
+	checkpoints := []Checkpoint{
+		{TicketID: "ENG-1", UpdatedAt: now.Add(-3 * time.Hour)},
+		{TicketID: "ENG-2", UpdatedAt: now.Add(-1 * time.Hour)},
+		{TicketID: "ENG-1", UpdatedAt: now},
+		{TicketID: "ENG-3", UpdatedAt: now.Add(-2 * time.Hour)},
+	}
+
+	ids := RecentTicketIDs(checkpoints, 2)
+
+	if len(ids) != 2 || ids[0] != "ENG-1" || ids[1] != "ENG-2" {
+		t.Errorf("Expected [ENG-1 ENG-2], got %v", ids)
+	}
+	if checkpoints[0].TicketID != "ENG-1" || checkpoints[1].TicketID != "ENG-2" {
+		t.Error("Expected input order to be preserved")
+	}
+}
+
+func TestStepOutput(t *testing.T) {
+	manager, _ := NewManager(t.TempDir())
+
+	type review struct {
+		Score int `json:"score"`
+	}
+	cp := manager.Start("ENG-123", 3)
+	manager.BeginStep(StepReview)
+	manager.CompleteStep(StepReview, review{Score: 60})
+	manager.BeginStep(StepReview)
+	manager.CompleteStep(StepReview, review{Score: 90})
+	manager.BeginStep(StepCommit)
+	manager.FailStep(StepCommit, os.ErrClosed)
+
+	resumed, err := manager.Resume(cp.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got review
+	if ok, err := resumed.StepOutput(StepReview, &got); !ok || err != nil {
+		t.Fatalf("Expected review output, got ok=%v err=%v", ok, err)
+	}
+	if got.Score != 90 {
+		t.Errorf("Expected the latest output (90), got %d", got.Score)
+	}
+	if ok, _ := resumed.StepOutput(StepCommit, &got); ok {
+		t.Error("Expected no output for a failed step")
+	}
+}
+
+func TestSkipStep(t *testing.T) {
+	manager, _ := NewManager(t.TempDir())
+
+	manager.Start("ENG-123", 3)
+	manager.BeginStep(StepExecution)
+	manager.CompleteStep(StepExecution, nil)
+	manager.SkipStep(StepLint)
+
+	if got := manager.Current.GetResumePoint(); got != StepTesting {
+		t.Errorf("Expected to resume after the skipped step at %s, got %s", StepTesting, got)
+	}
+	if got := manager.GetProgress().StepsComplete; got != 1 {
+		t.Errorf("Expected skipped steps not to count as complete, got %d", got)
+	}
+}
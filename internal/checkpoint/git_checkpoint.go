@@ -6,12 +6,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/philjestin/boatmanmode/internal/gitops"
 )
 
 // GitCheckpointManager extends the standard manager with git integration.
@@ -368,18 +369,12 @@ func (g *GitCheckpointManager) FormatGitHistory() (string, error) {
 // --- Internal helpers ---
 
 func (g *GitCheckpointManager) gitExec(args ...string) error {
-	cmd := exec.Command("git", args...)
-	cmd.Dir = g.worktreePath
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+	_, err := gitops.New(g.worktreePath).Run(args...)
+	return err
 }
 
 func (g *GitCheckpointManager) gitOutput(args ...string) (string, error) {
-	cmd := exec.Command("git", args...)
-	cmd.Dir = g.worktreePath
-	output, err := cmd.Output()
-	return string(output), err
+	return gitops.New(g.worktreePath).Run(args...)
 }
 
 func (g *GitCheckpointManager) hasChanges() bool {
@@ -241,6 +241,24 @@ func TestRollback(t *testing.T) {
 	}
 }
 
+func TestRollbackErrorKeepsStderr(t *testing.T) {
+	tmpDir := setupGitRepo(t)
+	defer os.RemoveAll(tmpDir)
+
+	mgr, _ := NewGitCheckpointManager(GitCheckpointOptions{
+		WorktreePath: tmpDir,
+		UseGit:       true,
+		BaseDir:      tmpDir,
+	})
+	mgr.Start("ENG-123", 3)
+	mgr.SetWorktreePath(tmpDir)
+
+	err := mgr.Rollback(10)
+	if err == nil || !strings.Contains(err.Error(), "fatal:") {
+		t.Errorf("Expected git's stderr in the error, got %v", err)
+	}
+}
+
 func TestGetGitHistory(t *testing.T) {
 	tmpDir := setupGitRepo(t)
 	defer os.RemoveAll(tmpDir)
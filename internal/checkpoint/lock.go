@@ -0,0 +1,99 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// RunningError is returned by Lock when another live run holds the ticket.
+type RunningError struct {
+	TicketID string
+	RunID    string
+	PID      int
+}
+
+func (e *RunningError) Error() string {
+	return fmt.Sprintf("%s is already being worked on by run %s (pid %d)", e.TicketID, e.RunID, e.PID)
+}
+
+// lockFile is the content of a ticket's lock.
+type lockFile struct {
+	RunID string `json:"run_id"`
+	PID   int    `json:"pid"`
+}
+
+// Lock claims a ticket for a run, across processes, so the same ticket is
+// not worked on twice at once. It fails with a *RunningError naming the
+// existing run when a live process holds the ticket; locks left by
+// processes that have exited are taken over. unlock releases the ticket.
+func (m *Manager) Lock(ticketID, runID string) (unlock func(), err error) {
+	path := m.lockPath(ticketID)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create lock directory: %w", err)
+	}
+	data, _ := json.Marshal(lockFile{RunID: runID, PID: os.Getpid()})
+
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_, err = f.Write(data)
+			f.Close()
+			if err != nil {
+				os.Remove(path)
+				return nil, fmt.Errorf("failed to write lock: %w", err)
+			}
+			return func() { m.unlock(path, runID) }, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to lock %s: %w", ticketID, err)
+		}
+		if holder, ok := readLock(path); ok && ProcessAlive(holder.PID) {
+			return nil, &RunningError{TicketID: ticketID, RunID: holder.RunID, PID: holder.PID}
+		}
+		// The holder exited without unlocking
+		os.Remove(path)
+	}
+	return nil, fmt.Errorf("failed to lock %s: lock keeps changing hands", ticketID)
+}
+
+// LockedBy returns the run holding a ticket, if its process is alive.
+func (m *Manager) LockedBy(ticketID string) (string, bool) {
+	holder, ok := readLock(m.lockPath(ticketID))
+	if !ok || !ProcessAlive(holder.PID) {
+		return "", false
+	}
+	return holder.RunID, true
+}
+
+// unlock removes the lock if it is still runID's.
+func (m *Manager) unlock(path, runID string) {
+	if holder, ok := readLock(path); ok && holder.RunID == runID {
+		os.Remove(path)
+	}
+}
+
+func (m *Manager) lockPath(ticketID string) string {
+	name := strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(ticketID)
+	return filepath.Join(m.BaseDir, "locks", name+".lock")
+}
+
+func readLock(path string) (lockFile, bool) {
+	var holder lockFile
+	data, err := os.ReadFile(path)
+	if err != nil || json.Unmarshal(data, &holder) != nil {
+		return holder, false
+	}
+	return holder, true
+}
+
+// ProcessAlive reports whether a process with the given PID is running.
+func ProcessAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	return processRunning(pid)
+}
@@ -0,0 +1,74 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestLock(t *testing.T) {
+	m, err := NewManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unlock, err := m.Lock("ENG-123", "ENG-123-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id, ok := m.LockedBy("ENG-123"); !ok || id != "ENG-123-1" {
+		t.Errorf("LockedBy = %q, %v, want ENG-123-1", id, ok)
+	}
+
+	_, err = m.Lock("ENG-123", "ENG-123-2")
+	var running *RunningError
+	if !errors.As(err, &running) || running.RunID != "ENG-123-1" || running.PID != os.Getpid() {
+		t.Fatalf("second Lock err = %v, want the first run", err)
+	}
+	if _, err := m.Lock("ENG-456", "ENG-456-1"); err != nil {
+		t.Errorf("Lock of another ticket failed: %v", err)
+	}
+
+	unlock()
+	if _, ok := m.LockedBy("ENG-123"); ok {
+		t.Error("ticket still locked after unlock")
+	}
+	if _, err := m.Lock("ENG-123", "ENG-123-2"); err != nil {
+		t.Errorf("Lock after unlock failed: %v", err)
+	}
+}
+
+func TestLockStale(t *testing.T) {
+	m, err := NewManager(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A lock left by a process that has exited
+	exited := exec.Command("true")
+	if err := exited.Run(); err != nil {
+		t.Skip("true not available")
+	}
+	path := m.lockPath("ENG-123")
+	os.MkdirAll(filepath.Dir(path), 0755)
+	data, _ := json.Marshal(lockFile{RunID: "ENG-123-1", PID: exited.Process.Pid})
+	os.WriteFile(path, data, 0644)
+
+	unlock, err := m.Lock("ENG-123", "ENG-123-2")
+	if err != nil {
+		t.Fatalf("Lock did not take over a stale lock: %v", err)
+	}
+	if id, _ := m.LockedBy("ENG-123"); id != "ENG-123-2" {
+		t.Errorf("LockedBy = %q, want ENG-123-2", id)
+	}
+
+	// Unlocking a lock another run has since taken leaves it alone
+	data, _ = json.Marshal(lockFile{RunID: "ENG-123-3", PID: os.Getpid()})
+	os.WriteFile(path, data, 0644)
+	unlock()
+	if id, _ := m.LockedBy("ENG-123"); id != "ENG-123-3" {
+		t.Errorf("unlock removed another run's lock, LockedBy = %q", id)
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package checkpoint
+
+import (
+	"os"
+	"syscall"
+)
+
+func processRunning(pid int) bool {
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	// Signal 0 checks for existence without affecting the process
+	return proc.Signal(syscall.Signal(0)) == nil
+}
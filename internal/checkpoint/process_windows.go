@@ -0,0 +1,16 @@
+//go:build windows
+
+package checkpoint
+
+import "os"
+
+// processRunning opens the process: on Windows FindProcess fails for
+// processes that have exited, and signals cannot be sent to check.
+func processRunning(pid int) bool {
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	proc.Release()
+	return true
+}
@@ -1,5 +1,6 @@
 // Package claude provides a wrapper around the Claude CLI.
-// Supports both direct exec and tmux-based execution for large prompts.
+// Supports tmux-based execution, and a direct runner that parses the
+// CLI's stream-json output in Go for machines without tmux.
 package claude
 
 import (
@@ -12,13 +13,40 @@ import (
 	"log/slog"
 	"os"
 	"os/exec"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/philjestin/boatmanmode/internal/cmdpolicy"
+	"github.com/philjestin/boatmanmode/internal/config"
 	"github.com/philjestin/boatmanmode/internal/cost"
+	"github.com/philjestin/boatmanmode/internal/llm"
 	"github.com/philjestin/boatmanmode/internal/retry"
 	"github.com/philjestin/boatmanmode/internal/tmux"
 )
 
+// SessionEnv is set to the client's session name (planner, executor,
+// refactor-1, ...) in the environment of the claude command, so wrapper
+// commands can tell agents apart.
+const SessionEnv = "BOATMAN_SESSION"
+
+// ReadOnlyTools are the only tools of a read-only client: it can explore
+// the code but not run commands or change files.
+var ReadOnlyTools = []string{"Read", "Grep", "Glob"}
+
+// mutatingTools are denied to read-only clients even if a skill or agent
+// definition asks for them.
+var mutatingTools = []string{"Bash", "Edit", "MultiEdit", "Write", "NotebookEdit"}
+
+// ReadOnlyArgs returns the claude flags that limit a session to
+// ReadOnlyTools, for commands run outside a Client.
+func ReadOnlyArgs() []string {
+	return []string{
+		"--tools", strings.Join(ReadOnlyTools, ","),
+		"--disallowedTools", strings.Join(mutatingTools, ","),
+	}
+}
+
 // Client wraps the Claude CLI.
 type Client struct {
 	// Command is the claude command to use (default: "claude")
@@ -33,6 +61,10 @@ type Client struct {
 	// UseTmux enables tmux-based execution (better for large prompts)
 	UseTmux bool
 
+	// Direct runs claude as a child process, parsing its stream-json
+	// output in Go, instead of in tmux or with plain streaming.
+	Direct bool
+
 	// TmuxManager manages tmux sessions
 	TmuxManager *tmux.Manager
 
@@ -48,6 +80,10 @@ type Client struct {
 	// Model specifies which Claude model to use (e.g., "claude-sonnet-4.5", "claude-haiku-4")
 	Model string
 
+	// Sampling parameters of the agent's role; only API providers apply
+	// them.
+	Sampling config.SamplingConfig
+
 	// EnablePromptCaching enables prompt caching to reduce costs
 	EnablePromptCaching bool
 
@@ -59,9 +95,23 @@ type Client struct {
 	// If false, tools are explicitly disabled with --tools "".
 	EnableTools bool
 
+	// ReadOnly limits the client to ReadOnlyTools and denies Bash, Edit
+	// and Write outright, for investigation agents.
+	ReadOnly bool
+
 	// SkipPermissions automatically approves all tool uses without user confirmation.
 	// WARNING: This is a security risk - only enable for trusted, non-interactive environments.
 	SkipPermissions bool
+
+	// Provider, when set, answers messages instead of the Claude CLI.
+	Provider llm.Provider
+
+	// CommandPolicy, when set, is enforced on the session's Bash commands
+	// by a PreToolUse hook.
+	CommandPolicy *cmdpolicy.Policy
+
+	// Output receives progress output; nil means stdout.
+	Output io.Writer
 }
 
 // StreamChunk represents a chunk from Claude's stream-json output.
@@ -112,7 +162,7 @@ func NewWithTmux(workDir, sessionName string) *Client {
 	return &Client{
 		Command:     "claude",
 		WorkDir:     workDir,
-		Env:         make(map[string]string),
+		Env:         map[string]string{SessionEnv: sessionName},
 		UseTmux:     true,
 		TmuxManager: tmux.NewManager("boatman"),
 		SessionName: sessionName,
@@ -130,7 +180,7 @@ func NewWithTools(workDir, sessionName string, tools []string) *Client {
 	return &Client{
 		Command:      "claude",
 		WorkDir:      workDir,
-		Env:          make(map[string]string),
+		Env:          map[string]string{SessionEnv: sessionName},
 		UseTmux:      true,
 		TmuxManager:  tmux.NewManager("boatman"),
 		SessionName:  sessionName,
@@ -141,8 +191,134 @@ func NewWithTools(workDir, sessionName string, tools []string) *Client {
 	}
 }
 
+// NewReadOnly creates a client for investigation agents, which may only
+// read and search the code in workDir.
+func NewReadOnly(workDir, sessionName string) *Client {
+	c := NewWithTools(workDir, sessionName, ReadOnlyTools)
+	c.ReadOnly = true
+	return c
+}
+
+// toolArgs returns the claude flags enforcing the client's tool policy.
+func (c *Client) toolArgs() []string {
+	if c.ReadOnly {
+		return ReadOnlyArgs()
+	}
+	if !c.EnableTools {
+		// Explicitly disable tools for backward compatibility
+		return []string{"--tools", ""}
+	}
+	if len(c.AllowedTools) > 0 {
+		// Restrict to specific tools
+		return []string{"--tools", strings.Join(c.AllowedTools, ",")}
+	}
+	// All tools allowed: omit --tools entirely
+	return nil
+}
+
+// restrictions returns the tool flags for the tmux and text-output paths,
+// which have always had every tool: only a restricted toolset is passed
+// on, so clients without tools keep working there as before.
+func (c *Client) restrictions() []string {
+	if c.ReadOnly || (c.EnableTools && len(c.AllowedTools) > 0) {
+		return c.toolArgs()
+	}
+	return nil
+}
+
+// Configure applies the settings every agent's client shares: the claude
+// command to run and the runner to run it with.
+func (c *Client) Configure(cfg config.ClaudeConfig) {
+	if cfg.Command != "" {
+		c.Command = cfg.Command
+	}
+	if resolveRunner(cfg) == RunnerDirect {
+		c.UseTmux = false
+		c.Direct = true
+	}
+	// agent.New has already rejected invalid provider settings.
+	if provider, err := llm.New(cfg.Provider); err == nil {
+		c.Provider = provider
+	}
+	if c.runsBash() {
+		c.CommandPolicy = cmdpolicy.New(cfg.CommandPolicy, c.WorkDir, c.SessionName)
+	}
+}
+
+// runsBash reports whether the client's sessions may use the Bash tool.
+func (c *Client) runsBash() bool {
+	if c.ReadOnly {
+		return false
+	}
+	if c.EnableTools && len(c.AllowedTools) > 0 {
+		return slices.Contains(c.AllowedTools, "Bash")
+	}
+	return true
+}
+
+// ConfineToProxy refuses the session's Bash commands that bypass the
+// egress proxy its Env points at, enforcing the command policy even when
+// it is off.
+func (c *Client) ConfineToProxy() {
+	if !c.runsBash() {
+		return
+	}
+	if c.CommandPolicy == nil {
+		c.CommandPolicy = cmdpolicy.New(config.CommandPolicyConfig{Enabled: true}, c.WorkDir, c.SessionName)
+	}
+	c.CommandPolicy.Proxied = true
+}
+
+// policyArgs returns the claude flags installing the command policy hook.
+func (c *Client) policyArgs() ([]string, error) {
+	if c.CommandPolicy == nil {
+		return nil, nil
+	}
+	return c.CommandPolicy.Args(tmux.OutputDir())
+}
+
+// reportBlocked prints the commands the policy blocked since start.
+func (c *Client) reportBlocked(start time.Time) {
+	for _, b := range c.CommandPolicy.BlockedSince(start) {
+		fmt.Fprintf(c.output(), "   🛑 Blocked command: %s (%s)\n", b.Command, b.Reason)
+	}
+}
+
+// output returns where progress output goes.
+func (c *Client) output() io.Writer {
+	if c.Output == nil {
+		return os.Stdout
+	}
+	return c.Output
+}
+
+// SupportsTools reports whether the client can use tools and skills to
+// work in the worktree itself, which only the Claude CLI can.
+func (c *Client) SupportsTools() bool {
+	return c.Provider == nil
+}
+
+// messageProvider sends a message to the configured API provider.
+func (c *Client) messageProvider(ctx context.Context, systemPrompt, userPrompt string) (string, *cost.Usage, error) {
+	if c.Debug {
+		slog.Debug("provider message", "provider", c.Provider.Name(), "session", c.SessionName, "model", c.Model)
+	}
+	return c.Provider.Complete(ctx, llm.Request{Model: c.Model, System: systemPrompt, Prompt: userPrompt, Sampling: c.Sampling})
+}
+
 // Message sends a message to Claude and returns the response with usage data.
 func (c *Client) Message(ctx context.Context, systemPrompt, userPrompt string) (string, *cost.Usage, error) {
+	if c.Provider != nil {
+		return c.messageProvider(ctx, systemPrompt, userPrompt)
+	}
+	if c.CommandPolicy != nil {
+		defer c.reportBlocked(time.Now())
+	}
+
+	if c.Direct {
+		return c.messageDirect(ctx, systemPrompt, userPrompt)
+	}
+
 	// Use tmux for large prompts or when explicitly enabled
 	if c.UseTmux || len(userPrompt) > 100000 || len(systemPrompt) > 50000 {
 		return c.messageTmux(ctx, systemPrompt, userPrompt)
@@ -159,6 +335,7 @@ func (c *Client) messageTmux(ctx context.Context, systemPrompt, userPrompt strin
 	if c.TmuxManager == nil {
 		c.TmuxManager = tmux.NewManager("boatman")
 	}
+	c.TmuxManager.SetOutput(c.Output)
 
 	sessionName := c.SessionName
 	if sessionName == "" {
@@ -177,6 +354,15 @@ func (c *Client) messageTmux(ctx context.Context, systemPrompt, userPrompt strin
 		Model:               c.Model,
 		EnablePromptCaching: c.EnablePromptCaching,
 	}
+	policyArgs, err := c.policyArgs()
+	if err != nil {
+		return "", nil, err
+	}
+	opts.ToolArgs = append(c.restrictions(), policyArgs...)
+	for k, v := range c.Env {
+		opts.Env = append(opts.Env, k+"="+v)
+	}
+	slices.Sort(opts.Env)
 	return c.TmuxManager.RunClaudeStreamingWithOptions(ctx, sess, systemPrompt, userPrompt, opts)
 }
 
@@ -225,15 +411,12 @@ func (c *Client) doStreamingRequest(ctx context.Context, systemPrompt, userPromp
 		args = append(args, "--dangerously-skip-permissions")
 	}
 
-	// Handle tool permissions
-	if !c.EnableTools {
-		// Explicitly disable tools for backward compatibility
-		args = append(args, "--tools", "")
-	} else if len(c.AllowedTools) > 0 {
-		// Restrict to specific tools
-		args = append(args, "--tools", strings.Join(c.AllowedTools, ","))
+	args = append(args, c.toolArgs()...)
+	policyArgs, err := c.policyArgs()
+	if err != nil {
+		return "", nil, err
 	}
-	// If EnableTools is true and AllowedTools is nil, omit --tools flag entirely (allows all tools)
+	args = append(args, policyArgs...)
 
 	// Add model selection if specified
 	if c.Model != "" {
@@ -277,7 +460,7 @@ func (c *Client) doStreamingRequest(ctx context.Context, systemPrompt, userPromp
 	var fullResponse strings.Builder
 	var resultUsage *cost.Usage
 
-	fmt.Println("   ┌─────────────────────────────────────────────────────────────")
+	fmt.Fprintln(c.output(), "   ┌─────────────────────────────────────────────────────────────")
 
 	// Create a done channel to signal when reading is complete
 	readDone := make(chan streamResult, 1)
@@ -300,7 +483,7 @@ func (c *Client) doStreamingRequest(ctx context.Context, systemPrompt, userPromp
 				if err == io.EOF {
 					// Print any remaining content
 					if lineBuffer != "" {
-						fmt.Printf("   │ %s\n", lineBuffer)
+						fmt.Fprintf(c.output(), "   │ %s\n", lineBuffer)
 					}
 					readDone <- streamResult{response: fullResponse.String(), usage: usage}
 					return
@@ -355,7 +538,7 @@ func (c *Client) doStreamingRequest(ctx context.Context, systemPrompt, userPromp
 					if idx == -1 {
 						break
 					}
-					fmt.Printf("   │ %s\n", lineBuffer[:idx])
+					fmt.Fprintf(c.output(), "   │ %s\n", lineBuffer[:idx])
 					lineBuffer = lineBuffer[idx+1:]
 				}
 			}
@@ -379,12 +562,12 @@ func (c *Client) doStreamingRequest(ctx context.Context, systemPrompt, userPromp
 		return "", nil, fmt.Errorf("claude command failed: %w\nstderr: %s", err, stderr.String())
 	}
 
-	fmt.Println("   └─────────────────────────────────────────────────────────────")
-	fmt.Printf("   📄 Total: %d chars\n", fullResponse.Len())
+	fmt.Fprintln(c.output(), "   └─────────────────────────────────────────────────────────────")
+	fmt.Fprintf(c.output(), "   📄 Total: %d chars\n", fullResponse.Len())
 
 	// Display usage if available
 	if resultUsage != nil && !resultUsage.IsEmpty() {
-		fmt.Printf("   💰 Cost: $%.4f (in: %d, out: %d, cache: %d)\n",
+		fmt.Fprintf(c.output(), "   💰 Cost: $%.4f (in: %d, out: %d, cache: %d)\n",
 			resultUsage.TotalCostUSD, resultUsage.InputTokens, resultUsage.OutputTokens, resultUsage.CacheReadTokens)
 	}
 
@@ -398,6 +581,12 @@ func (c *Client) messageNonStreaming(ctx context.Context, systemPrompt, userProm
 		"-p",
 		"--output-format", "text",
 	}
+	args = append(args, c.restrictions()...)
+	policyArgs, err := c.policyArgs()
+	if err != nil {
+		return "", nil, err
+	}
+	args = append(args, policyArgs...)
 
 	// Add model selection if specified
 	if c.Model != "" {
@@ -426,8 +615,8 @@ func (c *Client) messageNonStreaming(ctx context.Context, systemPrompt, userProm
 	}
 
 	if c.Debug {
-		fmt.Printf("[DEBUG] Running: %s %v\n", c.Command, args[:min(3, len(args))])
-		fmt.Printf("[DEBUG] WorkDir: %s\n", c.WorkDir)
+		fmt.Fprintf(c.output(), "[DEBUG] Running: %s %v\n", c.Command, args[:min(3, len(args))])
+		fmt.Fprintf(c.output(), "[DEBUG] WorkDir: %s\n", c.WorkDir)
 	}
 
 	var stdout, stderr bytes.Buffer
@@ -445,10 +634,23 @@ func (c *Client) messageNonStreaming(ctx context.Context, systemPrompt, userProm
 // MessageWithFiles sends a message with file context to Claude.
 // Note: This uses text output format, so usage data is not available.
 func (c *Client) MessageWithFiles(ctx context.Context, systemPrompt, userPrompt string, files []string) (string, *cost.Usage, error) {
+	if c.Provider != nil {
+		return c.messageProvider(ctx, systemPrompt, userPrompt)
+	}
+	if c.CommandPolicy != nil {
+		defer c.reportBlocked(time.Now())
+	}
+
 	args := []string{
 		"-p",
 		"--output-format", "text",
 	}
+	args = append(args, c.restrictions()...)
+	policyArgs, err := c.policyArgs()
+	if err != nil {
+		return "", nil, err
+	}
+	args = append(args, policyArgs...)
 
 	// Add model selection if specified
 	if c.Model != "" {
@@ -1,7 +1,14 @@
 package claude
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/config"
 )
 
 func TestNewWithTools(t *testing.T) {
@@ -55,3 +62,172 @@ func TestNewWithTmux_BackwardCompat(t *testing.T) {
 		t.Error("NewWithTmux should have nil AllowedTools")
 	}
 }
+
+func TestConfigure(t *testing.T) {
+	defer func(orig func() bool) { tmuxAvailable = orig }(tmuxAvailable)
+	tmuxAvailable = func() bool { return true }
+
+	client := NewWithTools("/tmp", "executor", nil)
+	if client.Env[SessionEnv] != "executor" {
+		t.Errorf("Expected %s=executor, got %v", SessionEnv, client.Env)
+	}
+
+	client.Configure(config.ClaudeConfig{})
+	if client.Command != "claude" || !client.UseTmux {
+		t.Errorf("Expected defaults kept, got command %q, tmux %v", client.Command, client.UseTmux)
+	}
+
+	client.Configure(config.ClaudeConfig{Command: "/opt/fake-claude", Headless: true})
+	if client.Command != "/opt/fake-claude" || client.UseTmux {
+		t.Errorf("Expected a headless fake command, got command %q, tmux %v", client.Command, client.UseTmux)
+	}
+
+	// Without tmux, e.g. on Windows, the direct runner is the default
+	tmuxAvailable = func() bool { return false }
+	client = NewWithTmux("/tmp", "executor")
+	client.Configure(config.ClaudeConfig{})
+	if client.UseTmux || !client.Direct {
+		t.Errorf("Expected the direct runner without tmux, got tmux %v, direct %v", client.UseTmux, client.Direct)
+	}
+	client = NewWithTmux("/tmp", "executor")
+	client.Configure(config.ClaudeConfig{Runner: RunnerTmux})
+	if !client.UseTmux || client.Direct {
+		t.Errorf("Expected an explicit tmux runner kept, got tmux %v, direct %v", client.UseTmux, client.Direct)
+	}
+
+	// Only sessions that can run Bash get the command policy
+	policy := config.ClaudeConfig{CommandPolicy: config.CommandPolicyConfig{Enabled: true}}
+	client = NewWithTools("/tmp", "refactor-1", nil)
+	client.Configure(policy)
+	if client.CommandPolicy == nil || client.CommandPolicy.Session != "refactor-1" || client.CommandPolicy.Worktree != "/tmp" {
+		t.Errorf("Expected the executor's command policy, got %+v", client.CommandPolicy)
+	}
+	for _, client := range []*Client{NewReadOnly("/tmp", "triage"), NewWithTools("/tmp", "planner", ReadOnlyTools)} {
+		client.Configure(policy)
+		if client.CommandPolicy != nil {
+			t.Errorf("Expected no command policy for %s without Bash", client.SessionName)
+		}
+	}
+}
+
+func TestToolArgs(t *testing.T) {
+	tests := []struct {
+		name         string
+		client       *Client
+		args         string
+		restrictions string
+	}{
+		{"all tools", NewWithTools("/tmp", "executor", nil), "[]", "[]"},
+		{"no tools", NewWithTmux("/tmp", "retro"), "[--tools ]", "[]"},
+		{"some tools", NewWithTools("/tmp", "planner", []string{"Read", "Grep"}), "[--tools Read,Grep]", "[--tools Read,Grep]"},
+		{
+			"read-only", NewReadOnly("/tmp", "triage"),
+			"[--tools Read,Grep,Glob --disallowedTools Bash,Edit,MultiEdit,Write,NotebookEdit]",
+			"[--tools Read,Grep,Glob --disallowedTools Bash,Edit,MultiEdit,Write,NotebookEdit]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fmt.Sprint(tt.client.toolArgs()); got != tt.args {
+				t.Errorf("toolArgs() = %s, want %s", got, tt.args)
+			}
+			if got := fmt.Sprint(tt.client.restrictions()); got != tt.restrictions {
+				t.Errorf("restrictions() = %s, want %s", got, tt.restrictions)
+			}
+		})
+	}
+}
+
+func TestParseStream(t *testing.T) {
+	stream := `{"type":"system","subtype":"init","session_id":"s1"}
+{"type":"assistant","message":{"content":[{"type":"text","text":"Let me look at the exporter.\nIt lives in billing."}]}}
+{"type":"assistant","message":{"content":[{"type":"tool_use","name":"Read","input":{"file_path":"billing/export.go"}}]}}
+{"type":"assistant","message":{"content":[{"type":"tool_use","name":"Bash","input":{"command":"go test ./billing/..."}}]}}
+{"type":"user","message":{"content":[{"type":"tool_result","content":"ok"}]}}
+Error: connection reset, retrying
+{"type":"assistant","message":{"content":[{"type":"tool_use","name":"Edit","input":{"file_path":"billing/export.go","old_string":"a","new_string":"b"}}]}}
+{"type":"result","subtype":"success","is_error":false,"result":"Added the tax column.","total_cost_usd":0.42,"usage":{"input_tokens":1200,"output_tokens":300,"cache_read_input_tokens":800}}
+`
+	var shown []string
+	out, err := parseStream(strings.NewReader(stream), func(line string) { shown = append(shown, line) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"💭 Let me look at the exporter.",
+		"📖 Reading: billing/export.go",
+		"🔧 Running: go test ./billing/...",
+		"⚠️  Error: connection reset, retrying",
+		"✏️  Editing: billing/export.go",
+	}
+	if strings.Join(shown, "\n") != strings.Join(want, "\n") {
+		t.Errorf("shown:\n%s\nwant:\n%s", strings.Join(shown, "\n"), strings.Join(want, "\n"))
+	}
+	if !out.gotResult || out.response != "Added the tax column." || out.failure != "" {
+		t.Errorf("out = %+v", out)
+	}
+	if out.usage.TotalCostUSD != 0.42 || out.usage.InputTokens != 1200 || out.usage.CacheReadTokens != 800 {
+		t.Errorf("usage = %+v", out.usage)
+	}
+}
+
+func TestParseStreamResultFormats(t *testing.T) {
+	// Older CLI versions put the reply in message.content
+	out, err := parseStream(strings.NewReader(`{"type":"result","message":{"content":[{"type":"text","text":"done"}]}}`), func(string) {})
+	if err != nil || out.response != "done" {
+		t.Errorf("out = %+v, err = %v", out, err)
+	}
+
+	out, _ = parseStream(strings.NewReader(`{"type":"result","subtype":"error_max_turns","is_error":true}`+"\n"), func(string) {})
+	if out.failure != "error_max_turns" {
+		t.Errorf("failure = %q", out.failure)
+	}
+
+	out, _ = parseStream(strings.NewReader(`{"type":"assistant","message":{"content":[]}}`+"\n"), func(string) {})
+	if out.gotResult {
+		t.Error("gotResult without a result line")
+	}
+}
+
+func TestMessageDirect(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", t.TempDir())
+	script := filepath.Join(dir, "claude")
+	os.WriteFile(script, []byte(`#!/bin/bash
+cat > "$(dirname "$0")/stdin.txt"
+echo "$@" > "$(dirname "$0")/args.txt"
+echo '{"type":"assistant","message":{"content":[{"type":"tool_use","name":"Glob","input":{"pattern":"**/*.go"}}]}}'
+echo '{"type":"result","result":"ok from '"$BOATMAN_SESSION"'","total_cost_usd":0.01}'
+`), 0755)
+
+	client := NewWithTmux(dir, "executor")
+	client.Configure(config.ClaudeConfig{Command: script, Runner: RunnerDirect})
+	if client.UseTmux || !client.Direct {
+		t.Fatalf("Expected the direct runner, got tmux %v, direct %v", client.UseTmux, client.Direct)
+	}
+
+	prompt := strings.Repeat("Implement the export. ", 10000) // Too big for an argument
+	response, usage, err := client.Message(context.Background(), "You are an engineer.", prompt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response != "ok from executor" || usage.TotalCostUSD != 0.01 {
+		t.Errorf("response = %q, usage = %+v", response, usage)
+	}
+	if stdin, _ := os.ReadFile(filepath.Join(dir, "stdin.txt")); string(stdin) != prompt {
+		t.Errorf("prompt was not passed on stdin (%d bytes)", len(stdin))
+	}
+	// boatman watch follows the session's files where there is no tmux
+	if out, err := os.ReadFile(filepath.Join(os.TempDir(), "boatman-sessions", "boatman-executor.out")); err != nil || string(out) != "🔍 Searching files...\n" {
+		t.Errorf("session output = %q, %v", out, err)
+	}
+	if _, err := os.Stat(filepath.Join(os.TempDir(), "boatman-sessions", "boatman-executor.done")); err != nil {
+		t.Errorf("no done file: %v", err)
+	}
+	args, _ := os.ReadFile(filepath.Join(dir, "args.txt"))
+	for _, want := range []string{"--verbose --output-format stream-json", "--system-prompt You are an engineer."} {
+		if !strings.Contains(string(args), want) {
+			t.Errorf("args %q missing %q", args, want)
+		}
+	}
+}
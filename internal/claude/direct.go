@@ -0,0 +1,396 @@
+package claude
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/cost"
+	"github.com/philjestin/boatmanmode/internal/retry"
+	"github.com/philjestin/boatmanmode/internal/tmux"
+)
+
+// Runners for Config.Claude.Runner. Empty picks tmux where it is
+// available and direct elsewhere, including Windows.
+const (
+	// RunnerTmux runs claude in a tmux session per agent, which can be
+	// attached to while it works.
+	RunnerTmux = "tmux"
+	// RunnerDirect runs claude as a child process and parses its output
+	// in Go, for machines without tmux.
+	RunnerDirect = "direct"
+)
+
+// tmuxAvailable is tmux.Available; tests replace it.
+var tmuxAvailable = tmux.Available
+
+// resolveRunner returns the runner cfg asks for, picking one when it is
+// unset.
+func resolveRunner(cfg config.ClaudeConfig) string {
+	switch {
+	case cfg.Headless:
+		return RunnerDirect
+	case cfg.Runner != "":
+		return cfg.Runner
+	case tmuxAvailable():
+		return RunnerTmux
+	default:
+		return RunnerDirect
+	}
+}
+
+// maxStreamLine caps a single stream-json line; tool results with whole
+// files in them can be large.
+const maxStreamLine = 64 << 20
+
+// maxThought caps the length of the assistant text shown as activity.
+const maxThought = 200
+
+// streamEvent is a line of claude's --verbose stream-json output.
+type streamEvent struct {
+	Type    string `json:"type"`
+	Subtype string `json:"subtype"`
+	IsError bool   `json:"is_error"`
+	Result  string `json:"result"`
+	Message struct {
+		Content []struct {
+			Type  string          `json:"type"`
+			Text  string          `json:"text"`
+			Name  string          `json:"name"`
+			Input json.RawMessage `json:"input"`
+		} `json:"content"`
+	} `json:"message"`
+	Usage struct {
+		InputTokens      int `json:"input_tokens"`
+		OutputTokens     int `json:"output_tokens"`
+		CacheReadTokens  int `json:"cache_read_input_tokens"`
+		CacheWriteTokens int `json:"cache_creation_input_tokens"`
+	} `json:"usage"`
+	TotalCostUSD float64 `json:"total_cost_usd"`
+}
+
+// streamOutput is what parseStream collected from a run.
+type streamOutput struct {
+	response  string
+	usage     *cost.Usage
+	gotResult bool
+	failure   string // Set when claude reported the run as failed
+}
+
+// messageDirect runs claude as a child process with retry support. It
+// shows the same activity as the tmux runner, without tmux: the prompt
+// goes in on stdin, so it has no size limit, and the stream-json output
+// is parsed in Go.
+func (c *Client) messageDirect(ctx context.Context, systemPrompt, userPrompt string) (string, *cost.Usage, error) {
+	var response string
+	var usage *cost.Usage
+
+	err := retry.Do(ctx, retry.CLIConfig(), "Claude CLI", func() error {
+		result, resultUsage, err := c.runDirect(ctx, systemPrompt, userPrompt)
+		if err != nil {
+			errStr := err.Error()
+			if strings.Contains(errStr, "rate limit") ||
+				strings.Contains(errStr, "overloaded") ||
+				strings.Contains(errStr, "temporarily") {
+				return err // Retryable
+			}
+			return retry.Permanent(err)
+		}
+		response = result
+		usage = resultUsage
+		return nil
+	})
+
+	return response, usage, err
+}
+
+// runDirect performs a single run of claude.
+func (c *Client) runDirect(ctx context.Context, systemPrompt, userPrompt string) (string, *cost.Usage, error) {
+	// The same flags as the tmux runner
+	args := []string{"-p", "--dangerously-skip-permissions", "--verbose", "--output-format", "stream-json"}
+	if c.Model != "" {
+		args = append(args, "--model", c.Model)
+	}
+	args = append(args, c.restrictions()...)
+	policyArgs, err := c.policyArgs()
+	if err != nil {
+		return "", nil, err
+	}
+	args = append(args, policyArgs...)
+	if systemPrompt != "" {
+		args = append(args, "--system-prompt", systemPrompt)
+	}
+
+	cmd := exec.CommandContext(ctx, c.Command, args...)
+	if c.WorkDir != "" {
+		cmd.Dir = c.WorkDir
+	}
+	cmd.Env = filterParentEnv()
+	for k, v := range c.Env {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
+	}
+	cmd.Stdin = strings.NewReader(userPrompt)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get stdout pipe: %w", err)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Start(); err != nil {
+		return "", nil, fmt.Errorf("failed to start claude: %w", err)
+	}
+
+	log := c.openSessionLog()
+	defer log.close()
+
+	fmt.Fprintln(c.output(), "   ┌─────────────────────────────────────────────────────────────")
+	out, readErr := parseStream(log.tee(stdout), func(line string) {
+		fmt.Fprintf(c.output(), "   │ %s\n", line)
+		log.show(line)
+	})
+	if readErr != nil {
+		// Let claude finish writing so it can exit
+		io.Copy(io.Discard, stdout)
+	}
+	waitErr := cmd.Wait()
+	fmt.Fprintln(c.output(), "   └─────────────────────────────────────────────────────────────")
+
+	if ctx.Err() != nil {
+		return "", nil, ctx.Err()
+	}
+	if readErr != nil {
+		return "", nil, readErr
+	}
+	if waitErr != nil {
+		return "", nil, fmt.Errorf("claude command failed: %w\nstderr: %s", waitErr, stderr.String())
+	}
+	if out.failure != "" {
+		return "", out.usage, fmt.Errorf("claude reported an error: %s", out.failure)
+	}
+	if !out.gotResult {
+		return "", nil, fmt.Errorf("claude exited without a result\nstderr: %s", stderr.String())
+	}
+
+	if out.usage != nil && !out.usage.IsEmpty() {
+		fmt.Fprintf(c.output(), "   💰 Cost: $%.4f (in: %d, out: %d, cache: %d)\n",
+			out.usage.TotalCostUSD, out.usage.InputTokens, out.usage.OutputTokens, out.usage.CacheReadTokens)
+	}
+	return out.response, out.usage, nil
+}
+
+// parseStream reads claude's stream-json output, passing a line of
+// activity to show for each tool use and thought, and collects the final
+// result. Lines that are not JSON are shown only if they look like errors.
+func parseStream(r io.Reader, show func(string)) (streamOutput, error) {
+	var out streamOutput
+
+	reader := bufio.NewReaderSize(r, 64*1024)
+	for {
+		line, err := readLine(reader)
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return out, nil
+			}
+			return out, fmt.Errorf("error reading stream: %w", err)
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		var event streamEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			lower := strings.ToLower(line)
+			for _, word := range []string{"error", "failed", "exception", "invalid"} {
+				if strings.Contains(lower, word) {
+					show("⚠️  " + line)
+					break
+				}
+			}
+			continue
+		}
+
+		switch event.Type {
+		case "assistant":
+			for _, content := range event.Message.Content {
+				switch content.Type {
+				case "tool_use":
+					if activity := toolActivity(content.Name, content.Input); activity != "" {
+						show(activity)
+					}
+				case "text":
+					if thought := firstLine(content.Text); thought != "" {
+						show("💭 " + thought)
+					}
+				}
+			}
+		case "result":
+			out.gotResult = true
+			out.response = event.Result
+			if out.response == "" {
+				var sb strings.Builder
+				for _, content := range event.Message.Content {
+					if content.Type == "text" {
+						sb.WriteString(content.Text)
+					}
+				}
+				out.response = sb.String()
+			}
+			out.usage = &cost.Usage{
+				InputTokens:      event.Usage.InputTokens,
+				OutputTokens:     event.Usage.OutputTokens,
+				CacheReadTokens:  event.Usage.CacheReadTokens,
+				CacheWriteTokens: event.Usage.CacheWriteTokens,
+				TotalCostUSD:     event.TotalCostUSD,
+			}
+			if event.IsError {
+				out.failure = event.Subtype
+				if event.Result != "" {
+					out.failure = event.Result
+				}
+				if out.failure == "" {
+					out.failure = "run failed"
+				}
+			}
+		}
+	}
+}
+
+// readLine reads a whole line, however long, up to maxStreamLine.
+func readLine(r *bufio.Reader) (string, error) {
+	var sb strings.Builder
+	for {
+		chunk, isPrefix, err := r.ReadLine()
+		if err != nil {
+			if errors.Is(err, io.EOF) && sb.Len() > 0 {
+				return sb.String(), nil
+			}
+			return "", err
+		}
+		if sb.Len()+len(chunk) > maxStreamLine {
+			return "", fmt.Errorf("line longer than %d bytes", maxStreamLine)
+		}
+		sb.Write(chunk)
+		if !isPrefix {
+			return sb.String(), nil
+		}
+	}
+}
+
+// toolActivity describes a tool use the way the tmux runner does.
+func toolActivity(name string, input json.RawMessage) string {
+	var args struct {
+		Command  string `json:"command"`
+		FilePath string `json:"file_path"`
+		Pattern  string `json:"pattern"`
+	}
+	_ = json.Unmarshal(input, &args)
+
+	switch name {
+	case "Bash":
+		if cmd := firstLine(args.Command); cmd != "" {
+			return "🔧 Running: " + cmd
+		}
+	case "Edit", "MultiEdit":
+		if args.FilePath != "" {
+			return "✏️  Editing: " + args.FilePath
+		}
+	case "Write":
+		if args.FilePath != "" {
+			return "📝 Writing: " + args.FilePath
+		}
+	case "Read":
+		if args.FilePath != "" {
+			return "📖 Reading: " + args.FilePath
+		}
+	case "Glob":
+		return "🔍 Searching files..."
+	case "Grep":
+		return "🔍 Searching content..."
+	default:
+		if name != "" {
+			return "🔧 " + name
+		}
+	}
+	return ""
+}
+
+// firstLine returns the first non-blank line of s, cut to maxThought.
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			if runes := []rune(line); len(runes) > maxThought {
+				line = string(runes[:maxThought]) + "..."
+			}
+			return line
+		}
+	}
+	return ""
+}
+
+// sessionLog is the output files of a direct run, named for the session
+// like the tmux runner's under tmux.OutputDir(): the activity shown, the
+// raw stream-json, and a done file once claude exits. boatman watch
+// follows them where there is no tmux to attach to. Logging is best
+// effort; files that cannot be created are skipped.
+type sessionLog struct {
+	out, raw *os.File
+	done     string
+}
+
+// sessionFileName returns the name of the session's files, which is also
+// the tmux session name.
+func (c *Client) sessionFileName() string {
+	name := c.SessionName
+	if name == "" {
+		name = "claude"
+	}
+	return "boatman-" + name
+}
+
+func (c *Client) openSessionLog() *sessionLog {
+	dir := tmux.OutputDir()
+	base := filepath.Join(dir, c.sessionFileName())
+	log := &sessionLog{done: base + ".done"}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return log
+	}
+	os.Remove(log.done)
+	log.out, _ = os.Create(base + ".out")
+	log.raw, _ = os.Create(base + "-raw.txt")
+	return log
+}
+
+func (l *sessionLog) tee(r io.Reader) io.Reader {
+	if l.raw == nil {
+		return r
+	}
+	return io.TeeReader(r, l.raw)
+}
+
+func (l *sessionLog) show(line string) {
+	if l.out != nil {
+		fmt.Fprintln(l.out, line)
+	}
+}
+
+func (l *sessionLog) close() {
+	if l.out != nil {
+		l.out.Close()
+	}
+	if l.raw != nil {
+		l.raw.Close()
+	}
+	os.WriteFile(l.done, nil, 0644)
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/philjestin/boatmanmode/internal/analytics"
+	"github.com/philjestin/boatmanmode/internal/forge"
+	"github.com/philjestin/boatmanmode/internal/memory"
+	"github.com/spf13/cobra"
+)
+
+var (
+	analyticsOut     string
+	analyticsFormat  string
+	analyticsPRState bool
+)
+
+// analyticsCmd exports run history for reporting across repos.
+var analyticsCmd = &cobra.Command{
+	Use:   "analytics",
+	Short: "Export run history for BI tools",
+}
+
+var analyticsExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export run history and memory stats for every repo",
+	Long: `Export the run history and memory stats boatman has recorded for every
+repository on this machine, in a normalized schema for BI tools:
+
+  runs.<format>   one row per run: task, review score, iterations, cost, PR
+  repos.<format>  one row per repo: sessions, review pass rate, cost, PRs
+                  created and merged, cost per merged PR, failure points
+
+Only csv and jsonl are supported; Parquet is not written. Convert the CSV
+with your warehouse's importer or DuckDB. With --pr-state, each PR's state
+is looked up with gh so merged PRs (and cost per merged PR) can be counted.`,
+	Args: cobra.NoArgs,
+	RunE: runAnalyticsExport,
+}
+
+func init() {
+	analyticsExportCmd.Flags().StringVarP(&analyticsOut, "out", "o", ".", "Directory to write the export files to")
+	analyticsExportCmd.Flags().StringVar(&analyticsFormat, "format", analytics.FormatCSV, "Export format: csv or jsonl")
+	analyticsExportCmd.Flags().BoolVar(&analyticsPRState, "pr-state", false, "Look up each PR's state with gh to count merged PRs")
+	rootCmd.AddCommand(analyticsCmd)
+	analyticsCmd.AddCommand(analyticsExportCmd)
+}
+
+func runAnalyticsExport(cmd *cobra.Command, args []string) error {
+	if analyticsFormat != analytics.FormatCSV && analyticsFormat != analytics.FormatJSONL {
+		return fmt.Errorf("unknown format %q (only csv and jsonl are supported)", analyticsFormat)
+	}
+
+	store, err := memory.NewStore("")
+	if err != nil {
+		return err
+	}
+	mems, err := store.List()
+	if err != nil {
+		return err
+	}
+
+	var prState analytics.PRStateFunc
+	if analyticsPRState {
+		prState = func(url string) (string, error) {
+			state, err := forge.PRState(context.Background(), url)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "⚠️  Could not look up %s: %v\n", url, err)
+			}
+			return state, err
+		}
+	}
+	runs, repos := analytics.Collect(mems, prState)
+
+	if err := os.MkdirAll(analyticsOut, 0755); err != nil {
+		return err
+	}
+	runsPath := filepath.Join(analyticsOut, "runs."+analyticsFormat)
+	if err := writeExport(runsPath, func(w io.Writer) error { return analytics.WriteRuns(w, analyticsFormat, runs) }); err != nil {
+		return err
+	}
+	reposPath := filepath.Join(analyticsOut, "repos."+analyticsFormat)
+	if err := writeExport(reposPath, func(w io.Writer) error { return analytics.WriteRepos(w, analyticsFormat, repos) }); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "📊 Exported %d run(s) across %d repo(s)\n", len(runs), len(repos))
+	fmt.Fprintf(cmd.OutOrStdout(), "   %s\n   %s\n", runsPath, reposPath)
+	return nil
+}
+
+// writeExport creates path and writes an export to it.
+func writeExport(path string, write func(io.Writer) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/philjestin/boatmanmode/internal/agent"
+	"github.com/philjestin/boatmanmode/internal/checkpoint"
+	"github.com/spf13/cobra"
+)
+
+// approveCmd continues a run paused for approval.
+var approveCmd = &cobra.Command{
+	Use:   "approve <checkpoint-id>",
+	Short: "Approve a paused run and let it continue",
+	Long: `Approve what a paused run is waiting on and resume it: the commit, push
+or PR that require_approval stops before, or a plan paused for review (as
+edited in its plan file). The run can also be given by ticket ID, for its
+latest run.
+
+boatman status lists paused runs and what they are waiting on.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeCheckpointIDs,
+	RunE:              runApprove,
+}
+
+func init() {
+	rootCmd.AddCommand(approveCmd)
+	addOutputFlags(approveCmd)
+}
+
+func runApprove(cmd *cobra.Command, args []string) error {
+	return resumeRun(cmd, args[0], func(a *agent.Agent, cp *checkpoint.Checkpoint) error {
+		if cp.Paused == "" {
+			return fmt.Errorf("run %s is not waiting for approval", cp.ID)
+		}
+		a.SetApprove(true)
+		return nil
+	})
+}
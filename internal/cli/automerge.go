@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/automerge"
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/linear"
+	"github.com/philjestin/boatmanmode/internal/task"
+	"github.com/spf13/cobra"
+)
+
+// automergeCmd watches a PR and enables auto-merge once it is ready.
+var automergeCmd = &cobra.Command{
+	Use:   "automerge <pr-url>",
+	Short: "Enable auto-merge on a PR once checks pass and it is approved",
+	Long: `Watch a GitHub PR until it merges. Once its checks pass and it is approved
+(see pr.auto_merge.require_approval), auto-merge is enabled with
+pr.auto_merge.method, or the first of squash, rebase and merge the repository
+allows, so GitHub merges it under the branch protection rules. When it merges,
+the Linear ticket given with --ticket gets a comment and moves to
+pr.auto_merge.done_state.
+
+With pr.auto_merge.enabled, boatman work starts this in the background for
+each PR it creates.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAutomerge,
+}
+
+func init() {
+	automergeCmd.Flags().String("ticket", "", "Linear ticket to close out when the PR merges")
+	rootCmd.AddCommand(automergeCmd)
+}
+
+func runAutomerge(cmd *cobra.Command, args []string) error {
+	cfg := config.LoadUnvalidated()
+	ticket, _ := cmd.Flags().GetString("ticket")
+
+	var tracker automerge.Tracker
+	if ticket != "" {
+		if cfg.LinearKey == "" {
+			return fmt.Errorf("LINEAR_API_KEY is required to close out %s", ticket)
+		}
+		tracker = linear.New(cfg.LinearKey)
+	}
+
+	fmt.Printf("👀 Watching %s\n", args[0])
+	return automerge.New(cfg.PR.AutoMerge, automerge.GH{}, tracker).Watch(context.Background(), args[0], ticket)
+}
+
+// startAutoMerge runs boatman automerge for a created PR in the
+// background when pr.auto_merge is enabled. Only GitHub PRs are watched,
+// and only Linear tickets are closed out.
+func startAutoMerge(out io.Writer, cfg *config.Config, t task.Task, url string) {
+	if !cfg.PR.AutoMerge.Enabled || !strings.Contains(url, "/pull/") {
+		return
+	}
+	args := []string{"automerge", url}
+	if t.GetMetadata().Source == task.SourceLinear {
+		args = append(args, "--ticket", t.GetID())
+	}
+
+	logPath, err := runLogPath("automerge-" + t.GetID())
+	if err == nil {
+		err = startDetached(logPath, args...)
+	}
+	if err != nil {
+		fmt.Fprintf(out, "⚠️  Could not start the auto-merge watcher: %v\n", err)
+		fmt.Fprintf(out, "   Run it yourself with: boatman automerge %s\n", url)
+		return
+	}
+	fmt.Fprintf(out, "🔀 Watching the PR to auto-merge it (log: %s)\n", logPath)
+}
+
+// startDetached runs boatman with args in the background, writing its
+// output to logPath.
+func startDetached(logPath string, args ...string) error {
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		return err
+	}
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	child := exec.Command(exe, args...)
+	child.Stdout = logFile
+	child.Stderr = logFile
+	child.SysProcAttr = detachedProcess()
+	if err := child.Start(); err != nil {
+		return err
+	}
+	return child.Process.Release()
+}
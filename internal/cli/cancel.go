@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/checkpoint"
+	"github.com/spf13/cobra"
+)
+
+// cancelCmd stops a running workflow.
+var cancelCmd = &cobra.Command{
+	Use:   "cancel <ticket-id>",
+	Short: "Stop a running workflow so it can be resumed later",
+	Long: `Stop the run working on a ticket, here or in another terminal, the
+background or a daemon. The run can also be given by checkpoint ID.
+
+The run stops its Claude session (and tmux session), records where it
+stopped in its checkpoint and releases the ticket's locks. The interrupted
+step runs again with boatman resume. With --remove-worktree the worktree is
+removed too; its branch is kept.
+
+Ctrl-C in the terminal running boatman work stops the run the same way.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeCheckpointIDs,
+	RunE:              runCancel,
+}
+
+func init() {
+	rootCmd.AddCommand(cancelCmd)
+	cancelCmd.Flags().Bool("remove-worktree", false, "Remove the run's worktree once it has stopped")
+	cancelCmd.Flags().Duration("wait", 30*time.Second, "How long to wait for the run to stop (0 to not wait)")
+}
+
+func runCancel(cmd *cobra.Command, args []string) error {
+	removeWorktree, _ := cmd.Flags().GetBool("remove-worktree")
+	wait, _ := cmd.Flags().GetDuration("wait")
+
+	mgr, err := checkpoint.NewManager("")
+	if err != nil {
+		return err
+	}
+	ticketID, runID, err := runningTicket(mgr, args[0])
+	if err != nil {
+		return err
+	}
+	if err := mgr.RequestCancel(ticketID, checkpoint.CancelRequest{RemoveWorktree: removeWorktree}); err != nil {
+		return err
+	}
+	fmt.Printf("🛑 Asked run %s (%s) to stop\n", runID, ticketID)
+	if wait <= 0 {
+		return nil
+	}
+
+	deadline := time.Now().Add(wait)
+	for time.Now().Before(deadline) {
+		if holder, ok := mgr.LockedBy(ticketID); !ok || holder != runID {
+			fmt.Printf("✅ Run stopped; continue with: boatman resume %s\n", runID)
+			return nil
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	return fmt.Errorf("run %s has not stopped after %s; it stops once its current Claude call returns", runID, wait)
+}
+
+// runningTicket returns the ticket and run ID of the run in progress for
+// id, a ticket or checkpoint ID.
+func runningTicket(mgr *checkpoint.Manager, id string) (ticketID, runID string, err error) {
+	if runID, ok := mgr.LockedBy(id); ok {
+		return id, runID, nil
+	}
+	cp, err := mgr.Resume(id)
+	if err != nil {
+		return "", "", fmt.Errorf("no run in progress for %s", id)
+	}
+	if runID, ok := mgr.LockedBy(cp.TicketID); ok && runID == cp.ID {
+		return cp.TicketID, runID, nil
+	}
+	return "", "", fmt.Errorf("run %s is not in progress", cp.ID)
+}
+
+// interruptContext returns a context cancelled by Ctrl-C or SIGTERM, for
+// runs to stop cleanly. A second Ctrl-C quits at once.
+func interruptContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-signals:
+			fmt.Println("\n🛑 Stopping the run; press Ctrl-C again to quit now")
+			signal.Stop(signals)
+			cancel()
+		case <-ctx.Done():
+			signal.Stop(signals)
+		}
+	}()
+	return ctx, cancel
+}
@@ -0,0 +1,156 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/chat"
+	"github.com/philjestin/boatmanmode/internal/checkpoint"
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// chatCmd opens an interactive session about a run.
+var chatCmd = &cobra.Command{
+	Use:   "chat <run-id>",
+	Short: "Ask about a run or request adjustments interactively",
+	Long: `Open an interactive session bound to a run's worktree and context
+(task, plan, latest review and current diff). The run can be given by
+checkpoint ID (see boatman runs list) or ticket ID for its latest run.
+
+Type a question to ask why the agent did something. Commands:
+  /do <instruction>  Apply an adjustment, then review it
+  /undo              Revert the last adjustment
+  /diff              Show the run's current diff
+  /context           Show the run context
+  /quit              Leave (or Ctrl-D)
+
+Adjustments stay in the worktree as staged changes; commit them with
+boatman worktree commit.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeCheckpointIDs,
+	RunE:              runChat,
+}
+
+func init() {
+	rootCmd.AddCommand(chatCmd)
+}
+
+func runChat(cmd *cobra.Command, args []string) error {
+	mgr, err := checkpoint.NewManager("")
+	if err != nil {
+		return err
+	}
+	cp, err := mgr.Resume(args[0])
+	if err != nil {
+		if cp, err = mgr.ResumeLatest(args[0]); err != nil {
+			return fmt.Errorf("no run %q found (see boatman runs list)", args[0])
+		}
+	}
+	if checkpoint.ProcessAlive(cp.PID) && cp.InFlight() {
+		return fmt.Errorf("run %s is still in progress; wait for it to finish", cp.ID)
+	}
+
+	run, err := chat.LoadRunContext(cp)
+	if err != nil {
+		return err
+	}
+	session, err := chat.New(run, config.LoadUnvalidated())
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprint(out, run.Summary())
+	fmt.Fprintln(out, "\nAsk a question, or /do <instruction> to adjust the change. /quit to leave.")
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err = chatLoop(ctx, session, run, bufio.NewReader(cmd.InOrStdin()), out)
+	if total := session.Cost().Total(); total.TotalCostUSD > 0 {
+		fmt.Fprintf(out, "💰 Session cost: $%.4f\n", total.TotalCostUSD)
+	}
+	return err
+}
+
+// chatLoop reads and runs commands until /quit or end of input.
+func chatLoop(ctx context.Context, session *chat.Session, run *chat.RunContext, in *bufio.Reader, out io.Writer) error {
+	for ctx.Err() == nil {
+		fmt.Fprint(out, "\nboatman> ")
+		line, err := in.ReadString('\n')
+		line = strings.TrimSpace(line)
+		if err != nil && line == "" {
+			fmt.Fprintln(out)
+			return nil
+		}
+
+		command, rest, _ := strings.Cut(line, " ")
+		rest = strings.TrimSpace(rest)
+		switch command {
+		case "":
+		case "/quit", "/exit":
+			return nil
+		case "/context":
+			fmt.Fprint(out, run.Summary())
+		case "/diff":
+			diff, err := session.Diff()
+			if err != nil {
+				fmt.Fprintf(out, "❌ %v\n", err)
+				continue
+			}
+			if diff == "" {
+				diff = "(no changes)\n"
+			}
+			fmt.Fprint(out, diff)
+		case "/undo":
+			if err := session.Undo(); err != nil {
+				fmt.Fprintf(out, "❌ %v\n", err)
+				continue
+			}
+			fmt.Fprintln(out, "↩️  Last adjustment reverted")
+		case "/do":
+			if rest == "" {
+				fmt.Fprintln(out, "Usage: /do <instruction>")
+				continue
+			}
+			adj, err := session.Instruct(ctx, rest)
+			if err != nil {
+				fmt.Fprintf(out, "❌ %v\n", err)
+				if adj != nil {
+					fmt.Fprintln(out, "⚠️  The adjustment is staged but unreviewed: /undo to revert")
+				}
+				continue
+			}
+			if strings.TrimSpace(adj.Diff) == "" {
+				fmt.Fprintln(out, "No changes were made")
+				continue
+			}
+			if adj.Review != nil {
+				fmt.Fprintln(out, adj.Review.FormatReview())
+				if !adj.Review.Passed {
+					fmt.Fprintln(out, "⚠️  Review did not pass: /do a follow-up, or /undo to revert")
+					continue
+				}
+			}
+			fmt.Fprintln(out, "✅ Adjustment staged in the worktree (commit with: boatman worktree commit)")
+		default:
+			if strings.HasPrefix(command, "/") {
+				fmt.Fprintf(out, "Unknown command %s (try /do, /undo, /diff, /context, /quit)\n", command)
+				continue
+			}
+			answer, err := session.Ask(ctx, line)
+			if err != nil {
+				fmt.Fprintf(out, "❌ %v\n", err)
+				continue
+			}
+			fmt.Fprintln(out, answer)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"os"
+
+	"github.com/philjestin/boatmanmode/internal/cmdpolicy"
+	"github.com/spf13/cobra"
+)
+
+// commandPolicyCmd is the PreToolUse hook agents' Claude sessions run
+// before each Bash command.
+var commandPolicyCmd = &cobra.Command{
+	Use:   cmdpolicy.Command + " <policy-file>",
+	Short: "Check an agent's Bash command against its command policy",
+	Long: `Run by Claude as a PreToolUse hook before each Bash command of an agent
+session. Reads the tool call from stdin and exits 2, refusing the command,
+when the session's policy blocks it. Blocked attempts are logged to
+~/.boatman/blocked-commands.log.`,
+	Args:   cobra.ExactArgs(1),
+	Hidden: true,
+	// The exit status is the hook's answer
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(cmdpolicy.Hook(args[0], os.Stdin, os.Stderr))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(commandPolicyCmd)
+}
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/philjestin/boatmanmode/internal/chat"
+	"github.com/philjestin/boatmanmode/internal/checkpoint"
+	"github.com/philjestin/boatmanmode/internal/compare"
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/gitops"
+	"github.com/spf13/cobra"
+)
+
+var compareDiff bool
+
+// compareCmd compares two runs, e.g. of one ticket under different models.
+var compareCmd = &cobra.Command{
+	Use:   "compare <run-a> <run-b>",
+	Short: "Compare two runs of a ticket",
+	Long: `Compare two runs, usually of the same ticket with different models or
+settings: the settings each run used, outcome, review score, iterations,
+issues raised, cost, plans and the files each changed. Runs are given by
+checkpoint ID (see boatman runs list).
+
+With --diff, also print the code diff from run A's final changes to run B's.
+Each run's worktree is used if it still exists, otherwise its branch.`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeCheckpointIDs,
+	RunE:              runCompare,
+}
+
+func init() {
+	compareCmd.Flags().BoolVar(&compareDiff, "diff", false, "Print the code diff between the two runs' final changes")
+	rootCmd.AddCommand(compareCmd)
+}
+
+func runCompare(cmd *cobra.Command, args []string) error {
+	mgr, err := checkpoint.NewManager("")
+	if err != nil {
+		return err
+	}
+	var runs [2]*chat.RunContext
+	for i, id := range args {
+		cp, err := mgr.Resume(id)
+		if err != nil {
+			return fmt.Errorf("no run %q found (see boatman runs list)", id)
+		}
+		if runs[i], err = chat.LoadRunContext(cp); err != nil {
+			return err
+		}
+	}
+
+	// Worktrees share their repository's objects, so either one can diff
+	// both runs; without them, runs are found by branch in this repo
+	repo := gitops.New(".")
+	for _, rc := range runs {
+		if path := rc.Checkpoint.WorktreePath; path != "" {
+			if _, err := os.Stat(path); err == nil {
+				repo = gitops.New(path)
+				break
+			}
+		}
+	}
+
+	baseBranch := config.LoadUnvalidated().BaseBranch
+	var sides [2]compare.Run
+	for i, rc := range runs {
+		diff, err := compare.FinalDiff(rc, repo, baseBranch)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  No diff for %s: %v\n", rc.Checkpoint.ID, err)
+		}
+		sides[i] = compare.NewRun(rc, diff)
+	}
+	c := &compare.Comparison{A: sides[0], B: sides[1]}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprint(out, c.Format())
+	if !compareDiff {
+		return nil
+	}
+
+	diff, err := compare.CodeDiff(runs[0], runs[1], repo)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out, "\n   Code diff (A → B):")
+	if diff == "" {
+		fmt.Fprintln(out, "   (identical)")
+		return nil
+	}
+	fmt.Fprintln(out)
+	fmt.Fprint(out, diff)
+	return nil
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/checkpoint"
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/jira"
+	"github.com/philjestin/boatmanmode/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+// recentTicketLimit caps ticket ID suggestions from run history.
+const recentTicketLimit = 20
+
+// loadCheckpoints returns all checkpoints, most recent first.
+func loadCheckpoints() []checkpoint.Checkpoint {
+	mgr, err := checkpoint.NewManager("")
+	if err != nil {
+		return nil
+	}
+	checkpoints, _ := mgr.List()
+	checkpoint.SortRecent(checkpoints)
+	return checkpoints
+}
+
+// completeTicketIDs suggests recently worked ticket IDs, and with the Jira
+// source the tickets assigned to you. In --file mode it falls back to file
+// completion; in --prompt mode there's nothing to suggest.
+func completeTicketIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	if file, _ := cmd.Flags().GetBool("file"); file {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	if prompt, _ := cmd.Flags().GetBool("prompt"); prompt {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	ids := checkpoint.RecentTicketIDs(loadCheckpoints(), recentTicketLimit)
+	if source, _ := cmd.Flags().GetString("source"); source == "jira" {
+		assigned := assignedJiraTickets()
+		for _, id := range ids {
+			if len(filterPrefix(assigned, id+"\t")) == 0 {
+				assigned = append(assigned, id)
+			}
+		}
+		ids = assigned
+	}
+	return filterPrefix(ids, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// assignedJiraTickets lists the open Jira tickets assigned to you as
+// completions, or nothing if Jira is not configured or slow to answer.
+func assignedJiraTickets() []string {
+	cfg := config.LoadUnvalidated()
+	if cfg.Jira.URL == "" || cfg.Jira.Token == "" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	tickets, err := jira.New(cfg.Jira).AssignedTickets(ctx, recentTicketLimit)
+	if err != nil {
+		return nil
+	}
+	ids := make([]string, len(tickets))
+	for i, t := range tickets {
+		ids[i] = t.Key + "\t" + t.Title
+	}
+	return ids
+}
+
+// completeCheckpointIDs suggests checkpoint IDs, most recent first.
+func completeCheckpointIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var ids []string
+	for _, cp := range loadCheckpoints() {
+		ids = append(ids, cp.ID+"\t"+cp.TicketID+" ("+string(cp.CurrentStep)+")")
+	}
+	return filterPrefix(ids, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeWorktreeNames suggests worktree names of the current repo.
+func completeWorktreeNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	cwd, _ := os.Getwd()
+	entries, _ := os.ReadDir(worktreeBaseDir(cwd))
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	return filterPrefix(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeSessionNames suggests boatman tmux session names.
+func completeSessionNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	sessions, _ := tmux.NewManager("boatman").ListSessions()
+	return filterPrefix(sessions, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// filterPrefix keeps candidates starting with prefix. Candidates may carry a
+// tab-separated description, which is ignored for matching.
+func filterPrefix(candidates []string, prefix string) []string {
+	var out []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, prefix) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
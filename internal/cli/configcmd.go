@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// configCmd inspects and validates configuration.
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect and validate configuration",
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the effective configuration (secrets redacted)",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		for _, file := range configFiles {
+			fmt.Printf("# Config file: %s\n", file)
+		}
+		if len(configFiles) == 0 {
+			fmt.Println("# No config file found; using defaults and environment")
+		}
+
+		data, err := json.MarshalIndent(redactSettings(viper.AllSettings()), "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the configuration",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		problems := doctorConfigProblems(config.LoadUnvalidated())
+		if len(problems) == 0 {
+			fmt.Println("✅ Configuration is valid")
+			return nil
+		}
+		for _, p := range problems {
+			fmt.Printf("❌ %s\n", p)
+		}
+		return fmt.Errorf("%d configuration problem(s) found", len(problems))
+	},
+}
+
+// userConfigPath returns the config file user settings are written to:
+// the --config file, else the user config.
+func userConfigPath() (string, error) {
+	if cfgFile != "" {
+		return cfgFile, nil
+	}
+	return config.UserConfigPath()
+}
+
+// redactSettings masks values whose keys look like credentials.
+func redactSettings(settings map[string]any) map[string]any {
+	out := make(map[string]any, len(settings))
+	for k, v := range settings {
+		lower := strings.ToLower(k)
+		switch val := v.(type) {
+		case map[string]any:
+			out[k] = redactSettings(val)
+		default:
+			if v != "" && (strings.Contains(lower, "key") || strings.Contains(lower, "token") || strings.Contains(lower, "secret")) {
+				out[k] = "********"
+			} else {
+				out[k] = v
+			}
+		}
+	}
+	return out
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configValidateCmd)
+}
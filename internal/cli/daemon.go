@@ -0,0 +1,290 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/checkpoint"
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/daemon"
+	"github.com/philjestin/boatmanmode/internal/digest"
+	"github.com/philjestin/boatmanmode/internal/linear"
+	"github.com/philjestin/boatmanmode/internal/memory"
+	"github.com/philjestin/boatmanmode/internal/notify"
+	"github.com/philjestin/boatmanmode/internal/secrets"
+	"github.com/philjestin/boatmanmode/internal/task"
+	"github.com/spf13/cobra"
+)
+
+// daemonCmd polls Linear and works through the tickets that are ready.
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Poll Linear and work through tickets that are ready for boatman",
+	Long: `Poll Linear every daemon.interval for tickets in daemon.state and/or with
+daemon.label (e.g. "Ready for AI"), queue them by priority, and run boatman
+work on each, up to daemon.max_parallel at a time. Picked-up tickets move to
+daemon.in_progress_state; once the PR is open they move to daemon.review_state
+with a comment linking it. Failed runs are commented on and moved to
+daemon.failed_state if one is set.
+
+Each run's output goes to its log under ~/.boatman/logs; follow it with
+boatman logs -f. Run the daemon from the repository the tickets are for,
+or list several repositories or teams under daemon.tenants: each tenant is
+polled and queued on its own, and its runs get only its config, worktree
+root, memory and credentials. --metrics-addr serves each tenant's ticket
+counts as JSON on GET /metrics. Interrupting the daemon stops new tickets
+from starting; running ones finish first. With notify.digest.to set, the
+daemon also emails the daily digest at notify.digest.at.`,
+	Args: cobra.NoArgs,
+	RunE: runDaemon,
+}
+
+func init() {
+	daemonCmd.Flags().Duration("interval", 0, "How often to poll Linear (overrides daemon.interval)")
+	daemonCmd.Flags().Int("max-parallel", 0, "How many tickets to run at once (overrides daemon.max_parallel)")
+	daemonCmd.Flags().String("metrics-addr", "", "Address to serve per-tenant ticket counts on, e.g. :9090")
+	rootCmd.AddCommand(daemonCmd)
+}
+
+func runDaemon(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg.Source != string(task.SourceLinear) {
+		return fmt.Errorf("boatman daemon polls Linear; source is %s", cfg.Source)
+	}
+	if interval, _ := cmd.Flags().GetDuration("interval"); interval > 0 {
+		cfg.Daemon.Interval = interval
+	}
+	if n, _ := cmd.Flags().GetInt("max-parallel"); n > 0 {
+		cfg.Daemon.MaxParallel = n
+	}
+
+	repoPath, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	var names []string
+	daemons := make(map[string]*daemon.Daemon)
+	if len(cfg.Daemon.Tenants) == 0 {
+		d, err := daemon.New(cfg.Daemon, linear.New(cfg.LinearKey), workTicket(repoPath, cfg.Source, nil, nil))
+		if err != nil {
+			return err
+		}
+		names = append(names, filepath.Base(repoPath))
+		daemons[filepath.Base(repoPath)] = d
+	}
+	for _, tenant := range cfg.Daemon.Tenants {
+		d, err := tenantDaemon(cfg, tenant)
+		if err != nil {
+			return fmt.Errorf("tenant %s: %w", tenant.Name, err)
+		}
+		names = append(names, tenant.Name)
+		daemons[tenant.Name] = d
+	}
+
+	mailer, err := notify.NewMailer(cfg.Notify.Digest)
+	if err != nil {
+		return err
+	}
+	if mailer != nil {
+		next, err := digest.NextSend(time.Now(), cfg.Notify.Digest.At)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("📧 Sending the daily digest to %d recipient(s), next at %s\n", len(cfg.Notify.Digest.To), next.Format("Jan 2 15:04"))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if mailer != nil {
+		go sendDigests(ctx, cfg.Notify.Digest, mailer)
+	}
+
+	if addr, _ := cmd.Flags().GetString("metrics-addr"); addr != "" {
+		metrics := serveMetrics(addr, daemons)
+		defer metrics.Close()
+	}
+
+	errs := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = daemons[name].Run(ctx)
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// tenantDaemon returns the daemon for a tenant's tickets, polling with the
+// tenant's own Linear key if it has one.
+func tenantDaemon(cfg *config.Config, tenant config.TenantConfig) (*daemon.Daemon, error) {
+	// Runs start in the tenant's repo, so paths are resolved here
+	for _, path := range []*string{&tenant.Repo, &tenant.Config, &tenant.WorktreeRoot, &tenant.MemoryDir} {
+		if *path == "" {
+			continue
+		}
+		abs, err := filepath.Abs(*path)
+		if err != nil {
+			return nil, err
+		}
+		*path = abs
+	}
+	if info, err := os.Stat(tenant.Repo); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("repo %s not found", tenant.Repo)
+	}
+	key, err := secrets.Resolve(tenant.Getenv("LINEAR_API_KEY"))
+	if err != nil {
+		return nil, err
+	}
+	if key == "" {
+		key = cfg.LinearKey
+	}
+	d, err := daemon.New(tenant.Apply(cfg.Daemon), linear.New(key), workTicket(tenant.Repo, cfg.Source, &tenant, cfg.Daemon.Tenants))
+	if err != nil {
+		return nil, err
+	}
+	d.SetName(tenant.Name)
+	return d, nil
+}
+
+// serveMetrics serves the daemons' ticket counts, by tenant, as JSON on
+// GET /metrics until closed.
+func serveMetrics(addr string, daemons map[string]*daemon.Daemon) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		stats := make(map[string]daemon.Stats, len(daemons))
+		for name, d := range daemons {
+			stats[name] = d.Stats()
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]any{"tenants": stats})
+	})
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Printf("⚠️  Could not serve metrics: %v\n", err)
+		}
+	}()
+	fmt.Printf("📊 Serving metrics on %s/metrics\n", addr)
+	return srv
+}
+
+// workTicket returns a runner that runs boatman work on a ticket from
+// source in a child process, writing its output to the run's log. A
+// tenant's runs get its environment and spend limit, and none of the
+// credentials the other tenants reference.
+func workTicket(repoPath, source string, tenant *config.TenantConfig, tenants []config.TenantConfig) daemon.Runner {
+	return func(ctx context.Context, ticketID string) (string, error) {
+		id := checkpoint.NewID(ticketID)
+		logPath, err := runLogPath(id)
+		if err != nil {
+			return "", err
+		}
+		if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+			return "", fmt.Errorf("failed to create log directory: %w", err)
+		}
+		logFile, err := os.Create(logPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to create run log: %w", err)
+		}
+		defer logFile.Close()
+
+		exe, err := os.Executable()
+		if err != nil {
+			return "", err
+		}
+		started := time.Now()
+		child := exec.Command(exe, "work", ticketID, "--source", source, "--run-id", id)
+		child.Dir = repoPath
+		var memoryDir string
+		if tenant != nil {
+			if tenant.MaxCostUSD > 0 {
+				child.Args = append(child.Args, "--max-cost", strconv.FormatFloat(tenant.MaxCostUSD, 'f', -1, 64))
+			}
+			child.Env = tenantEnv(tenant, tenants)
+			memoryDir = tenant.MemoryDir
+		}
+		child.Stdout = logFile
+		child.Stderr = logFile
+		// Its own session, so interrupting the daemon doesn't interrupt the run
+		child.SysProcAttr = detachedProcess()
+		if err := child.Run(); err != nil {
+			return "", fmt.Errorf("run %s failed (%v); see boatman logs %s", id, err, id)
+		}
+		if url := openedPR(repoPath, memoryDir, ticketID, started); url != "" {
+			return url, nil
+		}
+		return "", fmt.Errorf("run %s finished without opening a PR; see boatman logs %s", id, id)
+	}
+}
+
+// tenantEnv returns the environment of a tenant's runs: the daemon's,
+// without the variables only the other tenants reference, with the tenant's
+// config, worktree root, memory directory and credentials.
+func tenantEnv(tenant *config.TenantConfig, tenants []config.TenantConfig) []string {
+	hidden := map[string]bool{}
+	for _, other := range tenants {
+		if other.Name != tenant.Name {
+			for _, name := range other.References() {
+				hidden[name] = true
+			}
+		}
+	}
+	for _, name := range tenant.References() {
+		delete(hidden, name)
+	}
+	var env []string
+	for _, entry := range os.Environ() {
+		if key, _, _ := strings.Cut(entry, "="); !hidden[key] {
+			env = append(env, entry)
+		}
+	}
+	if tenant.Config != "" {
+		env = append(env, config.TenantConfigEnv+"="+tenant.Config)
+	}
+	if tenant.WorktreeRoot != "" {
+		env = append(env, config.WorktreeRootEnv+"="+tenant.WorktreeRoot)
+	}
+	if tenant.MemoryDir != "" {
+		env = append(env, memory.DirEnv+"="+tenant.MemoryDir)
+	}
+	return append(env, tenant.ExpandedEnv()...)
+}
+
+// openedPR returns the PR a run on ticketID opened since started, from the
+// repo's history in memoryDir (the default memory directory if empty).
+func openedPR(repoPath, memoryDir, ticketID string, started time.Time) string {
+	store, err := memory.NewStore(memoryDir)
+	if err != nil {
+		return ""
+	}
+	mem, err := store.Get(repoPath)
+	if err != nil {
+		return ""
+	}
+	for i := len(mem.ScoreHistory) - 1; i >= 0; i-- {
+		rec := mem.ScoreHistory[i]
+		if rec.TaskID == ticketID && rec.PRURL != "" && !rec.RecordedAt.Before(started) {
+			return rec.PRURL
+		}
+	}
+	return ""
+}
@@ -0,0 +1,33 @@
+package cli
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+)
+
+func TestTenantEnv(t *testing.T) {
+	t.Setenv("PAYMENTS_LINEAR_KEY", "payments-key")
+	t.Setenv("SEARCH_LINEAR_KEY", "search-key")
+	t.Setenv("SHARED_GH_TOKEN", "shared-token")
+	tenants := []config.TenantConfig{
+		{Name: "payments", Env: []string{"LINEAR_API_KEY=${PAYMENTS_LINEAR_KEY}", "GH_TOKEN=$SHARED_GH_TOKEN"}},
+		{Name: "search", Env: []string{"LINEAR_API_KEY=${SEARCH_LINEAR_KEY}", "GH_TOKEN=$SHARED_GH_TOKEN"}},
+	}
+
+	env := tenantEnv(&tenants[0], tenants)
+	if slices.Contains(env, "SEARCH_LINEAR_KEY=search-key") {
+		t.Error("Expected the other tenant's key to be hidden")
+	}
+	for _, want := range []string{"PAYMENTS_LINEAR_KEY=payments-key", "SHARED_GH_TOKEN=shared-token", "LINEAR_API_KEY=payments-key", "GH_TOKEN=shared-token"} {
+		if !slices.Contains(env, want) {
+			t.Errorf("Expected %s in the payments env", want)
+		}
+	}
+
+	env = tenantEnv(&tenants[1], tenants)
+	if slices.Contains(env, "PAYMENTS_LINEAR_KEY=payments-key") || !slices.Contains(env, "LINEAR_API_KEY=search-key") {
+		t.Errorf("Expected only the search tenant's key, got %v", env)
+	}
+}
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package cli
+
+import "syscall"
+
+// detachedProcess starts a detached run in its own session, so it keeps
+// running when the terminal closes.
+func detachedProcess() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
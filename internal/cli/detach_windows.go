@@ -0,0 +1,11 @@
+//go:build windows
+
+package cli
+
+import "syscall"
+
+// detachedProcess starts a detached run in its own process group, so it
+// does not receive the console's Ctrl-C.
+func detachedProcess() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/checkpoint"
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/digest"
+	"github.com/philjestin/boatmanmode/internal/forge"
+	"github.com/philjestin/boatmanmode/internal/memory"
+	"github.com/philjestin/boatmanmode/internal/notify"
+	"github.com/spf13/cobra"
+)
+
+var (
+	digestSince time.Duration
+	digestSend  bool
+)
+
+// digestCmd summarizes recent activity, optionally by email.
+var digestCmd = &cobra.Command{
+	Use:   "digest",
+	Short: "Summarize recent runs, PRs, cost and failures",
+	Long: `Print a summary of boatman's activity on this machine: runs attempted,
+PRs created and merged, total cost, and the failed runs that still need
+attention. With --send, email it to notify.digest.to instead, through SMTP
+or SendGrid. boatman daemon sends it every day at notify.digest.at; without
+the daemon, run boatman digest --send daily from cron.`,
+	Args: cobra.NoArgs,
+	RunE: runDigest,
+}
+
+func init() {
+	digestCmd.Flags().DurationVar(&digestSince, "since", 24*time.Hour, "How far back to summarize")
+	digestCmd.Flags().BoolVar(&digestSend, "send", false, "Email the digest to notify.digest.to")
+	rootCmd.AddCommand(digestCmd)
+}
+
+func runDigest(cmd *cobra.Command, args []string) error {
+	cfg := config.LoadUnvalidated()
+	var mailer *notify.Mailer
+	if digestSend {
+		var err error
+		if mailer, err = notify.NewMailer(cfg.Notify.Digest); err != nil {
+			return err
+		}
+		if mailer == nil {
+			return errors.New("no digest recipients configured (set notify.digest.to)")
+		}
+	}
+
+	now := time.Now()
+	report, err := buildDigest(now.Add(-digestSince), now)
+	if err != nil {
+		return err
+	}
+	if mailer == nil {
+		fmt.Fprint(cmd.OutOrStdout(), report.Text())
+		return nil
+	}
+	if err := mailer.Send(context.Background(), report.Subject(), report.Text()); err != nil {
+		return fmt.Errorf("failed to send digest: %w", err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "📧 Sent digest to %d recipient(s)\n", len(cfg.Notify.Digest.To))
+	return nil
+}
+
+// sendDigests emails the digest of the last day at notify.digest.at every
+// day until ctx is done. The daemon runs it when digest recipients are set.
+func sendDigests(ctx context.Context, cfg config.DigestConfig, mailer *notify.Mailer) {
+	for {
+		now := time.Now()
+		next, err := digest.NextSend(now, cfg.At)
+		if err != nil {
+			fmt.Printf("⚠️  Not sending digests: %v\n", err)
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(next.Sub(now)):
+		}
+
+		report, err := buildDigest(next.Add(-24*time.Hour), next)
+		if err == nil {
+			err = mailer.Send(ctx, report.Subject(), report.Text())
+		}
+		if err != nil {
+			fmt.Printf("⚠️  Failed to send digest: %v\n", err)
+			continue
+		}
+		fmt.Printf("📧 Sent digest to %d recipient(s)\n", len(cfg.To))
+	}
+}
+
+// buildDigest summarizes the activity between since and until from the
+// checkpoints and memory on this machine.
+func buildDigest(since, until time.Time) (digest.Report, error) {
+	mgr, err := checkpoint.NewManager("")
+	if err != nil {
+		return digest.Report{}, err
+	}
+	checkpoints, err := mgr.List()
+	if err != nil {
+		return digest.Report{}, err
+	}
+	store, err := memory.NewStore("")
+	if err != nil {
+		return digest.Report{}, err
+	}
+	mems, err := store.List()
+	if err != nil {
+		return digest.Report{}, err
+	}
+	mergedAt := func(url string) (time.Time, error) {
+		at, err := forge.PRMergedAt(context.Background(), url)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Could not look up %s: %v\n", url, err)
+		}
+		return at, err
+	}
+	return digest.Build(checkpoints, mems, since, until, mergedAt), nil
+}
@@ -0,0 +1,145 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/digest"
+	"github.com/philjestin/boatmanmode/internal/forge"
+	"github.com/philjestin/boatmanmode/internal/healthcheck"
+	"github.com/philjestin/boatmanmode/internal/llm"
+	"github.com/philjestin/boatmanmode/internal/notify"
+	"github.com/philjestin/boatmanmode/internal/queue"
+	"github.com/philjestin/boatmanmode/internal/reviewers"
+	"github.com/spf13/cobra"
+)
+
+// doctorCmd checks dependencies and configuration.
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check dependencies and configuration",
+	Long: `Verify that required tools are installed and that the configuration is valid.
+
+Checks:
+  - git, gh, claude, tmux and git-lfs are available
+  - Linear API key (or Jira or Azure DevOps settings for their source) is configured
+  - Credentials referencing a keychain, Vault or 1Password secret resolve
+  - Git push settings are valid
+  - Branch naming template is valid
+  - Review skill arguments and environment are valid`,
+	RunE: runDoctor,
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// runDoctor runs all checks and fails if any required check fails.
+func runDoctor(cmd *cobra.Command, args []string) error {
+	results := healthcheck.CheckDefault(context.Background())
+	fmt.Print(results.Format())
+	fmt.Println()
+
+	cfg := config.LoadUnvalidated()
+	problems := doctorConfigProblems(cfg)
+
+	fmt.Println("Configuration")
+	fmt.Println("═══════════════════════════════════════")
+	if len(problems) == 0 {
+		fmt.Println("  ✅ No problems found")
+	}
+	for _, p := range problems {
+		fmt.Printf("  ❌ %s\n", p)
+	}
+	fmt.Println("═══════════════════════════════════════")
+
+	if err := results.Error(); err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("%d configuration problem(s) found", len(problems))
+	}
+	return nil
+}
+
+// doctorConfigProblems returns human-readable configuration problems.
+func doctorConfigProblems(cfg *config.Config) []string {
+	var problems []string
+	if err := cfg.Validate(); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if err := cfg.Git.Validate(); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if err := branchScheme(cfg).Validate(); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if cfg.MinSuccessLikelihood < 0 || cfg.MinSuccessLikelihood > 100 {
+		problems = append(problems, fmt.Sprintf("min_success_likelihood must be between 0 and 100, got %d", cfg.MinSuccessLikelihood))
+	}
+	if cfg.Daemon.MaxParallel < 1 {
+		problems = append(problems, fmt.Sprintf("daemon.max_parallel must be at least 1, got %d", cfg.Daemon.MaxParallel))
+	}
+	if _, err := queue.NewWindow(cfg.Daemon.Schedule.WorkingHours); err != nil {
+		problems = append(problems, err.Error())
+	}
+	switch cfg.PR.Provider {
+	case "auto", "github", "gerrit", "azure":
+	case "bitbucket":
+		if cfg.Bitbucket.Token == "" {
+			problems = append(problems, "pr.provider is bitbucket but no token is set (set BITBUCKET_TOKEN or bitbucket.token)")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("pr.provider must be auto, github, gerrit, azure, or bitbucket (got %q)", cfg.PR.Provider))
+	}
+	switch cfg.PR.AutoMerge.Method {
+	case "", forge.MergeSquash, forge.MergeRebase, forge.MergeCommit:
+	default:
+		problems = append(problems, fmt.Sprintf("pr.auto_merge.method must be squash, rebase or merge (got %q)", cfg.PR.AutoMerge.Method))
+	}
+	switch rc := cfg.PR.Reviewers; rc.Strategy {
+	case "", reviewers.StrategyCodeOwners:
+	case reviewers.StrategyRoundRobin:
+		if len(rc.Team) == 0 {
+			problems = append(problems, "pr.reviewers.team is required for the round-robin strategy")
+		}
+	default:
+		problems = append(problems, fmt.Sprintf("pr.reviewers.strategy must be round-robin or codeowners (got %q)", rc.Strategy))
+	}
+	if cfg.PR.Reviewers.Count < 0 {
+		problems = append(problems, fmt.Sprintf("pr.reviewers.count must not be negative, got %d", cfg.PR.Reviewers.Count))
+	}
+	if _, err := notify.NewMailer(cfg.Notify.Digest); err != nil {
+		problems = append(problems, err.Error())
+	}
+	if len(cfg.Notify.Digest.To) > 0 {
+		if _, err := digest.NextSend(time.Now(), cfg.Notify.Digest.At); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if cfg.MaxTestFixAttempts < 0 {
+		problems = append(problems, fmt.Sprintf("max_test_fix_attempts must not be negative, got %d", cfg.MaxTestFixAttempts))
+	}
+	if cfg.MaxCostUSD < 0 {
+		problems = append(problems, fmt.Sprintf("max_cost_usd must not be negative, got %g", cfg.MaxCostUSD))
+	}
+	if unpriced := llm.UnpricedModels(cfg); cfg.MaxCostUSD > 0 && len(unpriced) > 0 {
+		problems = append(problems, fmt.Sprintf("max_cost_usd cannot be enforced: provider %s reports no cost for %s",
+			cfg.Claude.Provider.Name, strings.Join(unpriced, ", ")))
+	}
+	skills := make([]string, 0, len(cfg.Review.Skills))
+	for skill := range cfg.Review.Skills {
+		skills = append(skills, skill)
+	}
+	sort.Strings(skills)
+	for _, skill := range skills {
+		for _, err := range cfg.Review.Skills[skill].Validate() {
+			problems = append(problems, fmt.Sprintf("review.skills.%s: %v", skill, err))
+		}
+	}
+	return problems
+}
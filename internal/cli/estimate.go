@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/estimate"
+	"github.com/philjestin/boatmanmode/internal/gitops"
+	"github.com/philjestin/boatmanmode/internal/memory"
+	"github.com/philjestin/boatmanmode/internal/planner"
+	"github.com/spf13/cobra"
+)
+
+var estimateJSON bool
+
+// estimateCmd plans a task without executing it and predicts its effort.
+var estimateCmd = &cobra.Command{
+	Use:   "estimate [ticket-id-or-prompt]",
+	Short: "Plan a task and estimate its complexity, iterations and cost",
+	Long: `Run only the planning agent for a task and print a structured estimate:
+complexity, files affected, predicted review iterations and cost based on
+this repository's run history, and the likelihood of success without human
+help along with the risks behind it. Nothing is changed; use it to decide whether
+a ticket is worth automating.
+
+  boatman estimate ENG-123
+  boatman estimate --prompt "Add rate limiting to the API" --json`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeTicketIDs,
+	RunE:              runEstimate,
+}
+
+func init() {
+	estimateCmd.Flags().Bool("prompt", false, "Treat argument as inline prompt text")
+	estimateCmd.Flags().Bool("file", false, "Read prompt from file")
+	estimateCmd.Flags().String("title", "", "Override auto-generated task title (prompt/file mode only)")
+	estimateCmd.Flags().BoolVar(&estimateJSON, "json", false, "Print the estimate as JSON")
+	rootCmd.AddCommand(estimateCmd)
+}
+
+func runEstimate(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// Keep stdout clean for --json; progress goes to stderr
+	out, progress := cmd.OutOrStdout(), cmd.OutOrStdout()
+	if estimateJSON {
+		progress = cmd.ErrOrStderr()
+	}
+
+	t, err := parseTaskInput(cmd, args, cfg, progress)
+	if err != nil {
+		return err
+	}
+
+	repoPath, err := gitops.New(".").RevParse("--show-toplevel")
+	if err != nil {
+		return fmt.Errorf("not in a git repository: %w", err)
+	}
+
+	p := planner.New(repoPath, cfg)
+	p.SetOutput(progress)
+	plan, usage, err := p.Analyze(context.Background(), t)
+	if err != nil {
+		return fmt.Errorf("planning failed: %w", err)
+	}
+	var planningCost float64
+	if usage != nil {
+		planningCost = usage.TotalCostUSD
+	}
+
+	var mem *memory.Memory
+	if store, err := memory.NewStore(""); err == nil {
+		mem, _ = store.Get(repoPath)
+	}
+
+	e := estimate.New(t.GetID(), t.GetTitle(), plan, planningCost, mem, cfg.MaxIterations)
+	e.AssessSuccess(repoPath, cfg.Commands.Test)
+	if estimateJSON {
+		data, err := json.MarshalIndent(e, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, string(data))
+		return nil
+	}
+	fmt.Fprintln(out)
+	fmt.Fprint(out, e.Format())
+	return nil
+}
@@ -0,0 +1,332 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/gitops"
+	"github.com/philjestin/boatmanmode/internal/healthcheck"
+	"github.com/philjestin/boatmanmode/internal/linear"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// modelRoles are the claude.models keys, in prompt order.
+var modelRoles = []string{"planner", "executor", "reviewer", "refactor", "preflight", "test_runner", "verifier", "test_fixer"}
+
+// balancedModels is the recommended multi-model preset: capable models for
+// planning, coding and fixing, cheap ones for validation, test parsing and
+// diff verification.
+var balancedModels = map[string]string{
+	"planner":     "claude-sonnet-4.5",
+	"executor":    "claude-sonnet-4.5",
+	"reviewer":    "claude-sonnet-4.5",
+	"refactor":    "claude-sonnet-4.5",
+	"preflight":   "claude-haiku-4",
+	"test_runner": "claude-haiku-4",
+	"verifier":    "claude-haiku-4",
+	"test_fixer":  "claude-sonnet-4.5",
+}
+
+// initCmd runs the first-run setup wizard.
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Interactive first-run setup",
+	Long: `Walk through first-run setup:
+  - Linear API key (validated against the Linear API, or skipped)
+  - Base branch and review skill
+  - Claude models per agent
+  - Cost budget per run
+  - Notification webhook
+
+Dependencies and the resulting configuration are then checked as in
+boatman doctor. Personal settings are written to the user config
+(~/.boatman/config.yaml, or --config); repository settings (base branch and
+review skill) can optionally go into this repo's .boatman.yaml instead.
+
+Press Enter to accept the default shown in [brackets].`,
+	Args: cobra.NoArgs,
+	RunE: runInit,
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+}
+
+// prompter reads answers to interactive questions.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+	eof bool // Input exhausted; every further answer is the default
+}
+
+// ask prints a question and returns the trimmed answer, or def if empty.
+func (p *prompter) ask(question, def string) string {
+	if def != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, def)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+	line, err := p.in.ReadString('\n')
+	if err != nil {
+		p.eof = true
+		fmt.Fprintln(p.out)
+	}
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer
+	}
+	return def
+}
+
+// confirm asks a yes/no question.
+func (p *prompter) confirm(question string, def bool) bool {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	for {
+		switch strings.ToLower(p.ask(question+" ("+hint+")", "")) {
+		case "":
+			return def
+		case "y", "yes":
+			return true
+		case "n", "no":
+			return false
+		}
+		fmt.Fprintln(p.out, "  Please answer y or n")
+	}
+}
+
+// choose asks for one of options; the first option is the default.
+func (p *prompter) choose(question string, options ...string) string {
+	for {
+		answer := strings.ToLower(p.ask(question+" ("+strings.Join(options, "/")+")", options[0]))
+		for _, opt := range options {
+			if answer == opt {
+				return opt
+			}
+		}
+		fmt.Fprintf(p.out, "  Please choose one of: %s\n", strings.Join(options, ", "))
+	}
+}
+
+func runInit(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	p := &prompter{in: bufio.NewReader(cmd.InOrStdin()), out: cmd.OutOrStdout()}
+	cfg := config.LoadUnvalidated()
+
+	userPath, err := userConfigPath()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("🚣 Boatman setup")
+	fmt.Println("═══════════════════════════════════════")
+	fmt.Printf("User config: %s\n\n", userPath)
+
+	user := map[string]any{}
+	repo := map[string]any{}
+
+	// Linear
+	if key := promptLinearKey(ctx, p, cfg.LinearKey); key != "" && key != os.Getenv("LINEAR_API_KEY") {
+		user["linear_key"] = key
+	}
+
+	// Repository settings
+	fmt.Println()
+	repo["base_branch"] = p.ask("Base branch", defaultBaseBranch(cfg))
+	if skill := p.ask("Review skill", cfg.ReviewSkill); skill != "" {
+		repo["review_skill"] = skill
+	}
+
+	// Models
+	fmt.Println()
+	switch p.choose("Claude models", "default", "balanced", "custom") {
+	case "balanced":
+		for _, role := range modelRoles {
+			user["claude.models."+role] = balancedModels[role]
+		}
+	case "custom":
+		fmt.Println("  Leave empty to use the Claude CLI default model")
+		for _, role := range modelRoles {
+			if model := p.ask("  "+role+" model", balancedModels[role]); model != "" {
+				user["claude.models."+role] = model
+			}
+		}
+	}
+
+	// Budget
+	fmt.Println()
+	for {
+		answer := p.ask("Max cost per run in USD (0 = no limit)", strconv.FormatFloat(viper.GetFloat64("max_cost_usd"), 'f', -1, 64))
+		budget, err := strconv.ParseFloat(answer, 64)
+		if err == nil && budget >= 0 {
+			if budget > 0 || viper.IsSet("max_cost_usd") {
+				user["max_cost_usd"] = budget
+			}
+			break
+		}
+		fmt.Println("  Please enter a non-negative number")
+	}
+
+	// Notifications
+	fmt.Println()
+	if url := p.ask("Slack/Discord/webhook URL for notifications (Enter to skip)", ""); url != "" {
+		if !strings.HasPrefix(url, "https://") && !strings.HasPrefix(url, "http://") {
+			fmt.Println("  ⚠️  Not an http(s) URL; skipping notifications")
+		} else {
+			user["notify.webhooks"] = webhooks(url)
+		}
+	}
+
+	// Where repository settings go
+	fmt.Println()
+	repoPath := ""
+	if root, err := gitops.New(".").RevParse("--show-toplevel"); err == nil {
+		repoPath = filepath.Join(root, config.RepoConfigFile)
+		if !p.confirm("Write base branch and review skill to "+repoPath+" for this repo?", false) {
+			repoPath = ""
+		}
+	}
+	if repoPath == "" {
+		for k, v := range repo {
+			user[k] = v
+		}
+		repo = nil
+	}
+
+	if err := writeSettings(userPath, user); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Wrote %s\n", userPath)
+	if repoPath != "" {
+		if err := writeSettings(repoPath, repo); err != nil {
+			return err
+		}
+		fmt.Printf("✅ Wrote %s\n", repoPath)
+	}
+
+	return checkInit(ctx, userPath, repoPath)
+}
+
+// promptLinearKey asks for a Linear API key and validates it live.
+// It returns "" if the user skips.
+func promptLinearKey(ctx context.Context, p *prompter, current string) string {
+	def := ""
+	if current != "" {
+		def = "keep current"
+	}
+	for {
+		key := p.ask("Linear API key (Enter to skip)", def)
+		switch key {
+		case "":
+			fmt.Println("  Skipped; set LINEAR_API_KEY or linear_key before running boatman work with a ticket")
+			return ""
+		case "keep current":
+			key = current
+		}
+
+		checkCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		name, err := linear.New(key).Viewer(checkCtx)
+		cancel()
+		if err == nil {
+			fmt.Printf("  ✅ Authenticated as %s\n", name)
+			return key
+		}
+		fmt.Printf("  ❌ Could not validate the key: %v\n", err)
+		if p.confirm("  Save it anyway?", false) || p.eof {
+			return key
+		}
+	}
+}
+
+// defaultBaseBranch offers the configured base branch if set, else the
+// remote's default branch.
+func defaultBaseBranch(cfg *config.Config) string {
+	if viper.IsSet("base_branch") {
+		return cfg.BaseBranch
+	}
+	if ref, err := gitops.New(".").Run("symbolic-ref", "--short", "refs/remotes/origin/HEAD"); err == nil {
+		if branch := strings.TrimPrefix(strings.TrimSpace(ref), "origin/"); branch != "" {
+			return branch
+		}
+	}
+	return cfg.BaseBranch
+}
+
+// webhooks returns the notify.webhooks setting for a webhook URL.
+func webhooks(url string) []map[string]string {
+	return []map[string]string{{"url": url, "format": webhookFormat(url)}}
+}
+
+// webhookFormat infers the notification format from a webhook URL.
+func webhookFormat(url string) string {
+	switch {
+	case strings.Contains(url, "hooks.slack.com"):
+		return "slack"
+	case strings.Contains(url, "discord.com/api/webhooks"), strings.Contains(url, "discordapp.com/api/webhooks"):
+		return "discord"
+	}
+	return "json"
+}
+
+// writeSettings sets each dotted key in the config file at path.
+func writeSettings(path string, settings map[string]any) error {
+	keys := make([]string, 0, len(settings))
+	for k := range settings {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := config.SetValue(path, k, settings[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkInit reloads the written config and runs the doctor checks on it.
+func checkInit(ctx context.Context, files ...string) error {
+	for i, file := range files {
+		if file == "" {
+			continue
+		}
+		viper.SetConfigFile(file)
+		var err error
+		if i == 0 {
+			err = viper.ReadInConfig()
+		} else {
+			err = viper.MergeInConfig()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", file, err)
+		}
+	}
+
+	fmt.Println()
+	results := healthcheck.CheckDefault(ctx)
+	fmt.Print(results.Format())
+
+	problems := doctorConfigProblems(config.LoadUnvalidated())
+	for _, p := range problems {
+		fmt.Printf("⚠️  %s\n", p)
+	}
+
+	if err := results.Error(); err != nil {
+		fmt.Println("\nConfiguration saved. Install the missing tools, then run `boatman doctor`.")
+		return nil
+	}
+	if len(problems) == 0 {
+		fmt.Println("\n✅ Ready. Try: boatman work ENG-123")
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/spf13/viper"
+)
+
+func TestWriteSettings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	hooks := map[string]string{
+		"https://hooks.slack.com/services/T0/B0/x": "slack",
+		"https://discord.com/api/webhooks/1/x":     "discord",
+		"https://ci.example.com/boatman-notify":    "json",
+	}
+	for url, want := range hooks {
+		if err := writeSettings(path, map[string]any{"notify.webhooks": webhooks(url), "max_cost_usd": 5.0}); err != nil {
+			t.Fatal(err)
+		}
+
+		viper.Reset()
+		viper.SetConfigFile(path)
+		if err := viper.ReadInConfig(); err != nil {
+			t.Fatal(err)
+		}
+		cfg := config.LoadUnvalidated()
+		if len(cfg.Notify.Webhooks) != 1 || cfg.Notify.Webhooks[0].URL != url || cfg.Notify.Webhooks[0].Format != want {
+			t.Errorf("%s: webhooks = %+v, want format %s", url, cfg.Notify.Webhooks, want)
+		}
+		if cfg.MaxCostUSD != 5 {
+			t.Errorf("max_cost_usd = %v", cfg.MaxCostUSD)
+		}
+	}
+	viper.Reset()
+}
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/checkpoint"
+	"github.com/spf13/cobra"
+)
+
+// logsCmd prints the output of a detached run.
+var logsCmd = &cobra.Command{
+	Use:   "logs <run-id>",
+	Short: "Show the output of a background run",
+	Long: `Print the output and event log of a run started with boatman work --detach.
+The run can be given by run ID or ticket ID for its latest run. With -f,
+keep streaming until the run finishes.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeCheckpointIDs,
+	RunE:              runLogs,
+}
+
+var logsFollow bool
+
+func init() {
+	logsCmd.Flags().BoolVarP(&logsFollow, "follow", "f", false, "Stream new output until the run finishes")
+	rootCmd.AddCommand(logsCmd)
+}
+
+// runLogPath returns where a detached run's output is written.
+func runLogPath(id string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".boatman", "logs", id+".log"), nil
+}
+
+func runLogs(cmd *cobra.Command, args []string) error {
+	mgr, err := checkpoint.NewManager("")
+	if err != nil {
+		return err
+	}
+	id := args[0]
+	path, err := runLogPath(id)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err != nil {
+		cp, cpErr := mgr.ResumeLatest(id)
+		if cpErr != nil {
+			return fmt.Errorf("no log for run %q (only runs started with --detach have one)", id)
+		}
+		id = cp.ID
+		if path, err = runLogPath(id); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("no log for run %q (only runs started with --detach have one)", id)
+	}
+	defer f.Close()
+
+	out := cmd.OutOrStdout()
+	for {
+		if _, err := io.Copy(out, f); err != nil {
+			return err
+		}
+		if !logsFollow || !runActive(mgr, id) {
+			break
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	// Output written between the last read and the run ending
+	_, err = io.Copy(out, f)
+	return err
+}
+
+// runActive reports whether a run is still being worked on. Before its
+// checkpoint exists, the detached process recorded at start is checked.
+func runActive(mgr *checkpoint.Manager, id string) bool {
+	cp, err := mgr.Resume(id)
+	if err != nil {
+		path, _ := runLogPath(id)
+		data, err := os.ReadFile(strings.TrimSuffix(path, ".log") + ".pid")
+		if err != nil {
+			return false
+		}
+		pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+		return checkpoint.ProcessAlive(pid)
+	}
+	return cp.InFlight() && checkpoint.ProcessAlive(cp.PID)
+}
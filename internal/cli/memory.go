@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/philjestin/boatmanmode/internal/memory"
+	"github.com/spf13/cobra"
+)
+
+// memoryCmd inspects what boatman has learned about the current repo.
+var memoryCmd = &cobra.Command{
+	Use:   "memory",
+	Short: "Inspect learned project memory",
+	Long:  `Show statistics and review score trends boatman has recorded for the current repository.`,
+}
+
+var memoryShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show memory for the current repository",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		store, err := memory.NewStore("")
+		if err != nil {
+			return err
+		}
+		mem, err := store.Get(cwd)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(mem.FormatStats())
+		if trends := mem.FormatScoreTrends(5); trends != "" {
+			fmt.Println()
+			fmt.Print(trends)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(memoryCmd)
+	memoryCmd.AddCommand(memoryShowCmd)
+}
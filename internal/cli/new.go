@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/gitops"
+	"github.com/philjestin/boatmanmode/internal/task"
+	"github.com/spf13/cobra"
+)
+
+var (
+	newTemplate string
+	newOutput   string
+	newSet      []string
+	newList     bool
+	newForce    bool
+)
+
+// newCmd generates a task prompt file from a template.
+var newCmd = &cobra.Command{
+	Use:   "new --template <name>",
+	Short: "Create a task prompt file from a template",
+	Long: `Generate a structured task prompt from a reusable template, ready for
+boatman work --file.
+
+Built-in templates: bugfix, endpoint, dependency-bump, refactor. Your own
+*.md templates in ~/.boatman/templates or <repo>/.boatman/templates are
+added to (or override) these.
+
+Templates contain {{placeholder}} markers. Fill them with --set, or edit
+the generated file before running it:
+
+  boatman new --template endpoint --set method=POST --set path=/api/users
+  boatman work --file endpoint-task.md`,
+	Args: cobra.NoArgs,
+	RunE: runNew,
+}
+
+func init() {
+	newCmd.Flags().StringVarP(&newTemplate, "template", "t", "", "Template name (see --list)")
+	newCmd.Flags().StringVarP(&newOutput, "output", "o", "", "Output file (default: <template>-task.md)")
+	newCmd.Flags().StringArrayVar(&newSet, "set", nil, "Fill a placeholder: name=value (repeatable)")
+	newCmd.Flags().BoolVar(&newList, "list", false, "List available templates")
+	newCmd.Flags().BoolVar(&newForce, "force", false, "Overwrite the output file if it exists")
+	newCmd.RegisterFlagCompletionFunc("template", completeTemplateNames)
+	rootCmd.AddCommand(newCmd)
+}
+
+func runNew(cmd *cobra.Command, args []string) error {
+	templates, err := task.LoadTemplates(templateDirs()...)
+	if err != nil {
+		return err
+	}
+
+	if newList || newTemplate == "" {
+		if !newList {
+			fmt.Println("Specify a template with --template:")
+		}
+		for _, name := range task.TemplateNames(templates) {
+			t := templates[name]
+			fmt.Printf("  %-16s %s\n", name, t.Description)
+			fmt.Printf("  %-16s placeholders: %s\n", "", strings.Join(t.Placeholders(), ", "))
+		}
+		if !newList {
+			return errors.New("no template specified")
+		}
+		return nil
+	}
+
+	tmpl, ok := templates[newTemplate]
+	if !ok {
+		return fmt.Errorf("unknown template %q (available: %s)", newTemplate, strings.Join(task.TemplateNames(templates), ", "))
+	}
+
+	values := make(map[string]string)
+	for _, kv := range newSet {
+		key, value, ok := strings.Cut(kv, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("invalid --set %q (want name=value)", kv)
+		}
+		values[key] = value
+	}
+	for key := range values {
+		if !slices.Contains(tmpl.Placeholders(), key) {
+			return fmt.Errorf("template %s has no placeholder %q (has: %s)", tmpl.Name, key, strings.Join(tmpl.Placeholders(), ", "))
+		}
+	}
+
+	output := newOutput
+	if output == "" {
+		output = tmpl.Name + "-task.md"
+	}
+	if _, err := os.Stat(output); err == nil && !newForce {
+		return fmt.Errorf("%s already exists (use --force to overwrite)", output)
+	}
+
+	content := tmpl.Render(values)
+	if err := os.WriteFile(output, []byte(content), 0644); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Created %s from template %s\n", output, tmpl.Name)
+	if remaining := task.Placeholders(content); len(remaining) > 0 {
+		fmt.Printf("   Fill in: %s\n", strings.Join(remaining, ", "))
+	}
+	fmt.Printf("   Then run: boatman work --file %s\n", output)
+	return nil
+}
+
+// templateDirs returns user then repository template directories.
+func templateDirs() []string {
+	var dirs []string
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".boatman", "templates"))
+	}
+	if root, err := gitops.New(".").RevParse("--show-toplevel"); err == nil {
+		dirs = append(dirs, filepath.Join(root, ".boatman", "templates"))
+	}
+	return dirs
+}
+
+// completeTemplateNames completes --template values.
+func completeTemplateNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	templates, err := task.LoadTemplates(templateDirs()...)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return filterPrefix(task.TemplateNames(templates), toComplete), cobra.ShellCompDirectiveNoFileComp
+}
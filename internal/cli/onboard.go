@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/onboard"
+	"github.com/spf13/cobra"
+)
+
+var (
+	onboardWrite bool
+	onboardForce bool
+)
+
+// onboardCmd suggests a repo config from what it finds in the repository.
+var onboardCmd = &cobra.Command{
+	Use:   "onboard [dir]",
+	Short: "Analyze a repo and suggest a .boatman.yaml",
+	Long: `Scan a repository (default: current directory) for:
+  - Languages and frameworks (Go, Node, Ruby/Rails, Python, Rust)
+  - Test, build and lint commands (package scripts and make targets preferred)
+  - Lint configs and AI rules files (CLAUDE.md, .cursorrules, ...)
+  - CODEOWNERS entries, suggested as protected paths
+
+The suggested config is printed; use --write to save it as .boatman.yaml.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runOnboard,
+}
+
+func init() {
+	onboardCmd.Flags().BoolVar(&onboardWrite, "write", false, "Write the suggestion to .boatman.yaml in the repo")
+	onboardCmd.Flags().BoolVar(&onboardForce, "force", false, "Overwrite an existing .boatman.yaml with --write")
+	rootCmd.AddCommand(onboardCmd)
+}
+
+func runOnboard(cmd *cobra.Command, args []string) error {
+	dir := "."
+	if len(args) > 0 {
+		dir = args[0]
+	}
+
+	report, err := onboard.Analyze(dir)
+	if err != nil {
+		return fmt.Errorf("failed to analyze %s: %w", dir, err)
+	}
+	suggestion, err := report.YAML()
+	if err != nil {
+		return err
+	}
+
+	if !onboardWrite {
+		fmt.Print(suggestion)
+		return nil
+	}
+
+	path := filepath.Join(dir, config.RepoConfigFile)
+	if _, err := os.Stat(path); err == nil && !onboardForce {
+		return fmt.Errorf("%s already exists (use --force to overwrite, or omit --write to print)", path)
+	} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(suggestion), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Wrote %s\n", path)
+	return nil
+}
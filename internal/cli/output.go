@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/philjestin/boatmanmode/internal/events"
+	"github.com/spf13/cobra"
+)
+
+// addOutputFlags adds the flags that choose where workflow events go.
+func addOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().String("output", "text", "Output format: text, or json for only NDJSON events on stdout")
+	cmd.Flags().String("output-file", "", "Write NDJSON events to this file instead of stdout")
+}
+
+// setupOutput applies the output flags and returns where progress output
+// goes: the command's stdout, or in json mode its stderr, so stdout
+// carries only events. The returned function closes the events file.
+func setupOutput(cmd *cobra.Command) (io.Writer, func(), error) {
+	format, _ := cmd.Flags().GetString("output")
+	path, _ := cmd.Flags().GetString("output-file")
+	if format != "text" && format != "json" {
+		return nil, nil, fmt.Errorf("--output must be text or json (got %q)", format)
+	}
+
+	done := func() {}
+	if path != "" {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open events file: %w", err)
+		}
+		events.SetOutput(f)
+		done = func() {
+			events.SetOutput(nil)
+			f.Close()
+		}
+	} else if format == "json" {
+		events.SetOutput(cmd.OutOrStdout())
+		done = func() { events.SetOutput(nil) }
+	}
+	if format == "json" {
+		return cmd.ErrOrStderr(), done, nil
+	}
+	return cmd.OutOrStdout(), done, nil
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/events"
+	"github.com/spf13/cobra"
+)
+
+func TestSetupOutput(t *testing.T) {
+	stdout := os.Stdout
+	for _, tt := range []struct {
+		format       string
+		wantProgress string // "out" or "err"
+	}{
+		{"text", "out"},
+		{"json", "err"},
+	} {
+		t.Run(tt.format, func(t *testing.T) {
+			cmd := &cobra.Command{}
+			addOutputFlags(cmd)
+			cmd.Flags().Set("output", tt.format)
+			var outBuf, errBuf bytes.Buffer
+			cmd.SetOut(&outBuf)
+			cmd.SetErr(&errBuf)
+
+			progress, done, err := setupOutput(cmd)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fmt.Fprintln(progress, "progress")
+			if tt.format == "json" {
+				events.Emit(events.Event{Type: "test"})
+			}
+			done()
+
+			if os.Stdout != stdout {
+				t.Error("Expected os.Stdout to be left alone")
+			}
+			got := errBuf.String()
+			if tt.wantProgress == "out" {
+				got = outBuf.String()
+			}
+			if got != "progress\n" {
+				t.Errorf("Expected progress on std%s, got %q", tt.wantProgress, got)
+			}
+			if tt.format == "json" && !strings.Contains(outBuf.String(), `"type":"test"`) {
+				t.Errorf("Expected the event on stdout, got %q", outBuf.String())
+			}
+		})
+	}
+
+	cmd := &cobra.Command{}
+	addOutputFlags(cmd)
+	cmd.Flags().Set("output", "yaml")
+	if _, _, err := setupOutput(cmd); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/gitops"
+	"github.com/philjestin/boatmanmode/internal/memory"
+	"github.com/philjestin/boatmanmode/internal/notify"
+	"github.com/philjestin/boatmanmode/internal/postmerge"
+	"github.com/philjestin/boatmanmode/internal/task"
+	"github.com/spf13/cobra"
+)
+
+// postmergeCmd verifies the base branch once a PR merges.
+var postmergeCmd = &cobra.Command{
+	Use:   "postmerge <pr-url>",
+	Short: "Build and smoke test the base branch once a PR merges",
+	Long: `Wait for a GitHub PR to merge, then build and smoke test its merge commit
+in a scratch worktree (pr.post_merge.build and pr.post_merge.smoke, or
+commands.build and commands.test). If either fails, the notify webhooks get a
+main_broken event and the run that created the PR is marked in this repo's
+history as having broken the base branch, which lowers the success likelihood
+boatman estimate predicts.
+
+With pr.post_merge.enabled, boatman work starts this in the background for
+each PR it creates.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runPostmerge,
+}
+
+func init() {
+	postmergeCmd.Flags().String("ticket", "", "Ticket the PR was opened for, for the alert")
+	rootCmd.AddCommand(postmergeCmd)
+}
+
+func runPostmerge(cmd *cobra.Command, args []string) error {
+	cfg := config.LoadUnvalidated()
+	ctx := context.Background()
+	url := args[0]
+	ticket, _ := cmd.Flags().GetString("ticket")
+
+	repoPath, err := gitops.New(".").RevParse("--show-toplevel")
+	if err != nil {
+		return fmt.Errorf("not in a git repository: %w", err)
+	}
+	checker := postmerge.New(cfg.PR.PostMerge, cfg.Commands, postmerge.GH{}, repoPath)
+
+	fmt.Printf("👀 Waiting for %s to merge\n", url)
+	pr, err := checker.WaitForMerge(ctx, url)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("🎉 Merged as %s; verifying %s\n", pr.MergeCommit.OID, pr.BaseBranch)
+	result, err := checker.Verify(ctx, pr)
+	if err != nil {
+		return err
+	}
+	if result.Passed() {
+		fmt.Printf("✅ %s post-merge checks %s\n", pr.BaseBranch, result.Describe())
+		return nil
+	}
+
+	fmt.Printf("🚨 %s post-merge checks: %s\n", pr.BaseBranch, result.Describe())
+	for _, line := range strings.Split(result.Output, "\n") {
+		fmt.Printf("   %s\n", line)
+	}
+	markBrokeMain(repoPath, url, result)
+	if err := notify.New(cfg.Notify).Send(ctx, notify.Event{
+		Type:     notify.MainBroken,
+		TicketID: ticket,
+		Title:    pr.Title,
+		PRURL:    url,
+		Message:  result.Describe(),
+	}); err != nil {
+		fmt.Printf("⚠️  Could not send the alert: %v\n", err)
+	}
+	return fmt.Errorf("merging %s broke %s (%s); revert it with: boatman revert %d --incident <id>",
+		url, pr.BaseBranch, result.Describe(), pr.Number)
+}
+
+// markBrokeMain attributes a failed post-merge check to the run that
+// created the PR, for success predictions and failure modes.
+func markBrokeMain(repoPath, url string, result *postmerge.Result) {
+	store, err := memory.NewStore("")
+	if err != nil {
+		return
+	}
+	mem, err := store.Get(repoPath)
+	if err != nil {
+		return
+	}
+	if !mem.MarkBrokeMain(url) {
+		fmt.Println("⚠️  No boatman run in this repo's history created the PR")
+		return
+	}
+	mem.RecordFailure("post-merge", 0, result.Failed+" failed on the base branch after merging")
+	if err := store.Save(mem); err != nil {
+		fmt.Printf("⚠️  Could not record the failure in project memory: %v\n", err)
+	}
+}
+
+// startPostMerge runs boatman postmerge for a created PR in the background
+// when pr.post_merge is enabled. Only GitHub PRs are watched.
+func startPostMerge(out io.Writer, cfg *config.Config, t task.Task, url string) {
+	if !cfg.PR.PostMerge.Enabled || !strings.Contains(url, "/pull/") {
+		return
+	}
+	logPath, err := runLogPath("postmerge-" + t.GetID())
+	if err == nil {
+		err = startDetached(logPath, "postmerge", url, "--ticket", t.GetID())
+	}
+	if err != nil {
+		fmt.Fprintf(out, "⚠️  Could not start the post-merge check: %v\n", err)
+		fmt.Fprintf(out, "   Run it yourself with: boatman postmerge %s\n", url)
+		return
+	}
+	fmt.Fprintf(out, "🧪 Will verify %s once the PR merges (log: %s)\n", cfg.BaseBranch, logPath)
+}
@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/philjestin/boatmanmode/internal/agent"
+	"github.com/philjestin/boatmanmode/internal/azure"
+	"github.com/philjestin/boatmanmode/internal/checkpoint"
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/jira"
+	"github.com/philjestin/boatmanmode/internal/linear"
+	"github.com/philjestin/boatmanmode/internal/task"
+	"github.com/spf13/cobra"
+)
+
+// resumeCmd continues an interrupted run from its checkpoint.
+var resumeCmd = &cobra.Command{
+	Use:   "resume <ticket-id>",
+	Short: "Resume an interrupted run from its checkpoint",
+	Long: `Continue the latest run of a ticket where it stopped, in the worktree it
+left behind, instead of starting over. The run can also be given by
+checkpoint ID (see boatman runs list).
+
+Steps the run completed (planning, execution, review) are not repeated;
+their results are read back from the checkpoint. Runs that failed while
+committing, pushing or creating the PR cannot be resumed.
+
+Runs paused for plan approval (--review-plan without a terminal) continue
+with --approve-plan, using the plan file as it was edited. Runs paused at a
+require_approval gate continue with boatman approve.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeCheckpointIDs,
+	RunE:              runResume,
+}
+
+func init() {
+	resumeCmd.Flags().Bool("approve-plan", false, "Approve the plan of a run paused for plan review")
+	rootCmd.AddCommand(resumeCmd)
+	addOutputFlags(resumeCmd)
+}
+
+func runResume(cmd *cobra.Command, args []string) error {
+	approve, _ := cmd.Flags().GetBool("approve-plan")
+	return resumeRun(cmd, args[0], func(a *agent.Agent, cp *checkpoint.Checkpoint) error {
+		a.SetApprovePlan(approve)
+		return nil
+	})
+}
+
+// resumeRun continues the run with the given checkpoint or ticket ID;
+// configure sets up the agent for it first.
+func resumeRun(cmd *cobra.Command, id string, configure func(*agent.Agent, *checkpoint.Checkpoint) error) error {
+	ctx, stop := interruptContext()
+	defer stop()
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if err := task.SetBranchScheme(branchScheme(cfg)); err != nil {
+		return fmt.Errorf("invalid branch naming config: %w", err)
+	}
+
+	mgr, err := checkpoint.NewManager("")
+	if err != nil {
+		return err
+	}
+	cp, err := mgr.Resume(id)
+	if err != nil {
+		if cp, err = mgr.ResumeLatest(id); err != nil {
+			return fmt.Errorf("no run %q found (see boatman runs list)", id)
+		}
+	}
+	if checkpoint.ProcessAlive(cp.PID) && cp.InFlight() {
+		return fmt.Errorf("run %s is still in progress", cp.ID)
+	}
+	if cp.CurrentStep == checkpoint.StepComplete {
+		return fmt.Errorf("run %s already completed", cp.ID)
+	}
+
+	out, done, err := setupOutput(cmd)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	t, err := resumeTask(ctx, cfg, cp)
+	if err != nil {
+		return err
+	}
+
+	a, err := agent.New(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create agent: %w", err)
+	}
+	a.SetOutput(out)
+	if err := configure(a, cp); err != nil {
+		return err
+	}
+	result, err := a.Resume(ctx, mgr, t)
+	if alreadyRunning(out, err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("resume failed: %w", err)
+	}
+
+	reportResult(out, cfg, t, result)
+	return nil
+}
+
+// reportResult prints the outcome of a run to out and starts the PR's
+// follow-ups.
+func reportResult(out io.Writer, cfg *config.Config, t task.Task, result *agent.WorkResult) {
+	switch {
+	case result.PRCreated:
+		fmt.Fprintf(out, "✅ PR created: %s\n", result.PRURL)
+		startAutoMerge(out, cfg, t, result.PRURL)
+		startPostMerge(out, cfg, t, result.PRURL)
+	case result.Paused:
+		fmt.Fprintf(out, "⏸️  Run paused: %s\n", result.Message)
+	case result.Cancelled:
+		// The agent has reported how to continue
+	default:
+		fmt.Fprintf(out, "⚠️  Work completed but PR not created: %s\n", result.Message)
+	}
+}
+
+// resumeTask recreates a run's task: tickets are fetched again, prompts
+// are rebuilt from the checkpoint.
+func resumeTask(ctx context.Context, cfg *config.Config, cp *checkpoint.Checkpoint) (task.Task, error) {
+	var rec agent.TaskRecord
+	if _, err := cp.StepOutput(checkpoint.StepFetchTicket, &rec); err != nil {
+		return nil, err
+	}
+	source := task.TaskSource(rec.Source)
+	if source == "" {
+		source = task.TaskSource(cfg.Source)
+	}
+
+	switch source {
+	case task.SourcePrompt, task.SourceFile:
+		return task.RestorePromptTask(rec.ID, rec.Title, rec.Description, cp.BranchName), nil
+	case task.SourceJira:
+		return task.CreateFromJira(ctx, jira.New(cfg.Jira), cp.TicketID)
+	case task.SourceAzure:
+		return task.CreateFromAzure(ctx, azure.New(cfg.Azure), cp.TicketID)
+	default:
+		return task.CreateFromLinear(ctx, linear.New(cfg.LinearKey), cp.TicketID)
+	}
+}
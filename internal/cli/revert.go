@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/forge"
+	"github.com/philjestin/boatmanmode/internal/gitops"
+	"github.com/philjestin/boatmanmode/internal/memory"
+	"github.com/philjestin/boatmanmode/internal/retry"
+	"github.com/philjestin/boatmanmode/internal/revert"
+	"github.com/philjestin/boatmanmode/internal/testrunner"
+	"github.com/philjestin/boatmanmode/internal/worktree"
+	"github.com/spf13/cobra"
+)
+
+// revertCmd opens a PR reverting a merged boatman PR.
+var revertCmd = &cobra.Command{
+	Use:   "revert <pr-number>",
+	Short: "Open a PR reverting a merged boatman PR",
+	Long: `Revert a PR boatman opened and that has since merged: the merge commit is
+reverted on a revert/pr-<number> branch in a new worktree, the test suite is
+run on the revert, and a PR is opened against the original PR's base branch.
+The PR links the original PR, the ticket it was opened for and the incident
+given with --incident. If tests fail, the PR is opened as a draft.
+
+  boatman revert 123 --incident INC-42
+  boatman revert 123 --incident https://status.acme.com/incidents/42
+
+PRs boatman didn't open are refused unless --ticket names the ticket to link.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runRevert,
+}
+
+func init() {
+	revertCmd.Flags().String("incident", "", "Incident ID or URL behind the revert (required)")
+	revertCmd.Flags().String("ticket", "", "Ticket to link, for PRs boatman didn't open")
+	revertCmd.MarkFlagRequired("incident")
+	rootCmd.AddCommand(revertCmd)
+}
+
+func runRevert(cmd *cobra.Command, args []string) error {
+	cfg := config.LoadUnvalidated()
+	ctx := context.Background()
+	incident, _ := cmd.Flags().GetString("incident")
+	ticket, _ := cmd.Flags().GetString("ticket")
+
+	repoPath, err := gitops.New(".").RevParse("--show-toplevel")
+	if err != nil {
+		return fmt.Errorf("not in a git repository: %w", err)
+	}
+
+	pr, err := forge.ViewPR(ctx, repoPath, args[0])
+	if err != nil {
+		return err
+	}
+	if pr.State != forge.PRStateMerged || pr.MergeCommit == nil {
+		return fmt.Errorf("PR #%d is %s; only merged PRs can be reverted", pr.Number, pr.State)
+	}
+	if ticket == "" {
+		if store, err := memory.NewStore(""); err == nil {
+			if mem, err := store.Get(repoPath); err == nil {
+				ticket = revert.Ticket(mem, pr.URL)
+			}
+		}
+		if ticket == "" {
+			return fmt.Errorf("PR #%d was not opened by boatman in this repo; pass --ticket to revert it anyway", pr.Number)
+		}
+	}
+	r := &revert.Revert{Original: pr, Ticket: ticket, Incident: incident}
+	fmt.Printf("⏪ Reverting #%d %q (%s)\n", pr.Number, pr.Title, ticket)
+
+	wtManager, err := worktree.NewWithOptions(repoPath, worktree.Options{
+		FetchDepth: cfg.Worktree.FetchDepth,
+		Root:       cfg.Worktree.Root,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create worktree manager: %w", err)
+	}
+	wt, err := wtManager.Create(r.Branch(), pr.BaseBranch)
+	if err != nil {
+		return fmt.Errorf("failed to create worktree: %w", err)
+	}
+	fmt.Printf("   📁 Worktree: %s\n", wt.Path)
+
+	git := gitops.New(wt.Path).WithContext(ctx)
+	if err := git.Revert(pr.MergeCommit.OID); err != nil {
+		if errors.Is(err, gitops.ErrConflict) {
+			return fmt.Errorf("revert of %s conflicts with later changes on %s; revert it by hand in %s: %w",
+				pr.MergeCommit.OID, pr.BaseBranch, wt.Path, err)
+		}
+		return fmt.Errorf("failed to revert %s: %w", pr.MergeCommit.OID, err)
+	}
+
+	fmt.Println("   🧪 Running tests on the revert...")
+	tests := testrunner.New(wt.Path)
+	tests.SetCommand(cfg.Commands.Test)
+	r.Tests, _ = tests.RunAll(ctx)
+	fmt.Printf("   %s\n", r.TestSummary())
+	failing := r.Tests != nil && !r.Tests.Passed
+	if failing {
+		fmt.Println("   ⚠️  Tests fail on the revert; opening the PR as a draft")
+	}
+
+	fmt.Println("   📤 Pushing to origin...")
+	if _, err := git.SafePush(gitops.PushOptions{
+		Remote:     "origin",
+		Branch:     r.Branch(),
+		OnDiverged: gitops.DivergedFail,
+		Retry: retry.Config{
+			MaxAttempts:  cfg.Retry.MaxAttempts,
+			InitialDelay: cfg.Retry.InitialDelay,
+			MaxDelay:     cfg.Retry.MaxDelay,
+			Multiplier:   2.0,
+			Jitter:       0.1,
+		},
+	}); err != nil {
+		return fmt.Errorf("failed to push: %w", err)
+	}
+
+	result, err := forge.NewGitHub(cfg.GitHub).CreatePR(ctx, wt.Path, forge.NewPR{
+		Title: r.Title(),
+		Body:  r.Body(),
+		Base:  pr.BaseBranch,
+		Head:  r.Branch(),
+		Draft: cfg.PR.Draft || failing,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create PR: %w", err)
+	}
+	fmt.Printf("✅ Revert PR created: %s\n", result.URL)
+	return nil
+}
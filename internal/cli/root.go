@@ -2,8 +2,7 @@
 package cli
 
 import (
-	"os"
-
+	"github.com/philjestin/boatmanmode/internal/config"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -32,7 +31,7 @@ func Execute() error {
 func init() {
 	cobra.OnInitialize(initConfig)
 
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.boatman.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.boatman/config.yaml merged with ./.boatman.yaml)")
 
 	// API Keys
 	rootCmd.PersistentFlags().String("linear-key", "", "Linear API key")
@@ -41,29 +40,37 @@ func init() {
 	viper.BindPFlag("linear_key", rootCmd.PersistentFlags().Lookup("linear-key"))
 }
 
-// initConfig reads in config file and ENV variables if set.
+// configFiles lists the config files that were loaded, lowest precedence first.
+var configFiles []string
+
+// initConfig reads in config files and ENV variables if set. Without
+// --config, the user config (~/.boatman/config.yaml, or legacy
+// ~/.boatman.yaml) is loaded and ./.boatman.yaml is merged over it.
 func initConfig() {
+	viper.SetEnvPrefix("BOATMAN")
+	viper.AutomaticEnv()
+	viper.SetConfigType("yaml")
+
 	if cfgFile != "" {
 		viper.SetConfigFile(cfgFile)
-	} else {
-		home, err := os.UserHomeDir()
-		cobra.CheckErr(err)
-
-		viper.AddConfigPath(home)
-		viper.AddConfigPath(".")
-		viper.SetConfigType("yaml")
-		viper.SetConfigName(".boatman")
+		// A missing explicit config file is an error
+		cobra.CheckErr(viper.ReadInConfig())
+		configFiles = []string{cfgFile}
+		return
 	}
 
-	viper.SetEnvPrefix("BOATMAN")
-	viper.AutomaticEnv()
-
-	if err := viper.ReadInConfig(); err != nil {
-		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
-			// Config file was found but another error occurred (likely YAML syntax error)
-			cobra.CheckErr(err)
+	files, err := config.Files(".")
+	cobra.CheckErr(err)
+	for i, file := range files {
+		viper.SetConfigFile(file)
+		if i == 0 {
+			err = viper.ReadInConfig()
+		} else {
+			err = viper.MergeInConfig()
 		}
-		// Config file not found is OK - will use defaults
+		// A config file with a YAML syntax error is fatal
+		cobra.CheckErr(err)
 	}
+	// No config file is OK - will use defaults
+	configFiles = files
 }
-
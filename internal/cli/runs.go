@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/checkpoint"
+	"github.com/spf13/cobra"
+)
+
+// runsCmd inspects workflow runs recorded in checkpoints.
+var runsCmd = &cobra.Command{
+	Use:     "runs",
+	Aliases: []string{"run"},
+	Short:   "Inspect workflow runs",
+	Long:    `List and inspect boatman runs recorded in checkpoints. See also: boatman status.`,
+}
+
+var runsListLimit int
+
+var runsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List recent runs",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		checkpoints := loadCheckpoints()
+		if len(checkpoints) == 0 {
+			fmt.Println("No runs recorded")
+			return nil
+		}
+
+		fmt.Println("Recent runs:")
+		for i, cp := range checkpoints {
+			if runsListLimit > 0 && i == runsListLimit {
+				break
+			}
+			fmt.Printf("  • %s  %-14s %s  $%.2f  %s\n",
+				cp.TicketID, runState(cp), runElapsed(cp, time.Now()).Round(time.Second), cp.CostUSD, cp.ID)
+		}
+		return nil
+	},
+}
+
+var runsShowCmd = &cobra.Command{
+	Use:               "show [checkpoint-id]",
+	Short:             "Show details of a run",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeCheckpointIDs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		mgr, err := checkpoint.NewManager("")
+		if err != nil {
+			return err
+		}
+		cp, err := mgr.Resume(args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Print(cp.FormatCheckpoint())
+		return nil
+	},
+}
+
+// runState summarizes where a run ended up.
+func runState(cp checkpoint.Checkpoint) string {
+	switch {
+	case cp.Error != "":
+		return "failed"
+	case cp.CurrentStep == checkpoint.StepComplete:
+		return "complete"
+	case cp.Paused != "":
+		return "paused"
+	case cp.Cancelled != "":
+		return "cancelled"
+	case !checkpoint.ProcessAlive(cp.PID):
+		return "stale"
+	default:
+		return string(cp.CurrentStep)
+	}
+}
+
+func init() {
+	runsListCmd.Flags().IntVarP(&runsListLimit, "limit", "n", 20, "Maximum runs to list (0 = all)")
+
+	rootCmd.AddCommand(runsCmd)
+	runsCmd.AddCommand(runsListCmd)
+	runsCmd.AddCommand(runsShowCmd)
+}
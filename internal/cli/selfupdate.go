@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/philjestin/boatmanmode/internal/selfupdate"
+	"github.com/spf13/cobra"
+)
+
+var (
+	updateChannel          string
+	updateCheckOnly        bool
+	updateForce            bool
+	updateRequireSignature bool
+)
+
+// selfUpdateCmd replaces the running binary with the latest release.
+var selfUpdateCmd = &cobra.Command{
+	Use:   "self-update",
+	Short: "Update boatman to the latest release",
+	Long: `Check GitHub releases and replace this binary with the latest version.
+
+Channels:
+  stable  Full releases only (default)
+  beta    Also pre-releases (-beta, -rc)
+
+The download is verified against the release's checksums.txt. If cosign is
+installed, the keyless signature of checksums.txt is verified too; use
+--require-signature to refuse updates that can't be signature-verified.`,
+	RunE: runSelfUpdate,
+}
+
+func init() {
+	selfUpdateCmd.Flags().StringVar(&updateChannel, "channel", selfupdate.ChannelStable, "Release channel: stable or beta")
+	selfUpdateCmd.Flags().BoolVar(&updateCheckOnly, "check", false, "Only check whether an update is available")
+	selfUpdateCmd.Flags().BoolVar(&updateForce, "force", false, "Install even if not newer (or on a dev build)")
+	selfUpdateCmd.Flags().BoolVar(&updateRequireSignature, "require-signature", false, "Fail unless the release signature is verified")
+	rootCmd.AddCommand(selfUpdateCmd)
+}
+
+func runSelfUpdate(cmd *cobra.Command, args []string) error {
+	ctx := context.Background()
+	client := selfupdate.New()
+
+	release, err := client.Latest(ctx, updateChannel)
+	if err != nil {
+		return fmt.Errorf("failed to check releases: %w", err)
+	}
+	latest := release.Version()
+	fmt.Printf("Current version: %s\n", version)
+	fmt.Printf("Latest %s:   %s\n", updateChannel, latest)
+
+	if !updateForce {
+		if version == "dev" {
+			fmt.Println("Development build; use --force to replace it with a release")
+			return nil
+		}
+		if selfupdate.CompareVersions(latest, version) <= 0 {
+			fmt.Println("✅ Already up to date")
+			return nil
+		}
+	}
+	if updateCheckOnly {
+		fmt.Println("Update available: run `boatman self-update` to install")
+		return nil
+	}
+
+	assetName := selfupdate.CurrentAssetName(latest)
+	asset := release.Asset(assetName)
+	checksumsAsset := release.Asset(selfupdate.ChecksumsAsset)
+	if asset == nil || checksumsAsset == nil {
+		return fmt.Errorf("release %s has no %s or %s", release.TagName, assetName, selfupdate.ChecksumsAsset)
+	}
+
+	fmt.Printf("Downloading %s...\n", assetName)
+	archive, err := client.Download(ctx, asset)
+	if err != nil {
+		return err
+	}
+	checksums, err := client.Download(ctx, checksumsAsset)
+	if err != nil {
+		return err
+	}
+
+	if err := verifyReleaseSignature(ctx, client, release, checksums); err != nil {
+		return err
+	}
+	if err := selfupdate.VerifyChecksum(archive, string(checksums), assetName); err != nil {
+		return err
+	}
+	fmt.Println("✅ Checksum verified")
+
+	binary, err := selfupdate.ExtractBinary(archive, assetName)
+	if err != nil {
+		return err
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("cannot locate current binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	if err := selfupdate.ReplaceExecutable(exe, binary); err != nil {
+		return err
+	}
+
+	fmt.Printf("✅ Updated %s to %s\n", exe, latest)
+	return nil
+}
+
+// verifyReleaseSignature checks the cosign signature of checksums.txt.
+// Missing signatures or cosign only warn unless --require-signature is set.
+func verifyReleaseSignature(ctx context.Context, client *selfupdate.Client, release *selfupdate.Release, checksums []byte) error {
+	sigAsset := release.Asset(selfupdate.SignatureAsset)
+	certAsset := release.Asset(selfupdate.CertificateAsset)
+	if sigAsset == nil || certAsset == nil {
+		return signatureUnverified("release is not signed")
+	}
+
+	signature, err := client.Download(ctx, sigAsset)
+	if err != nil {
+		return err
+	}
+	certificate, err := client.Download(ctx, certAsset)
+	if err != nil {
+		return err
+	}
+
+	err = selfupdate.VerifySignature(ctx, client.Repo, checksums, signature, certificate)
+	if errors.Is(err, selfupdate.ErrCosignUnavailable) {
+		return signatureUnverified("cosign is not installed")
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Println("✅ Signature verified")
+	return nil
+}
+
+func signatureUnverified(reason string) error {
+	if updateRequireSignature {
+		return fmt.Errorf("cannot verify signature: %s", reason)
+	}
+	fmt.Printf("⚠️  Signature not verified (%s); relying on checksum\n", reason)
+	return nil
+}
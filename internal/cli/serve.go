@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/daemon"
+	"github.com/philjestin/boatmanmode/internal/linear"
+	"github.com/philjestin/boatmanmode/internal/server"
+	"github.com/philjestin/boatmanmode/internal/task"
+	"github.com/spf13/cobra"
+)
+
+// serveCmd starts runs from Linear and GitHub webhooks.
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Start runs from Linear and GitHub webhooks",
+	Long: `Listen on serve.addr for webhooks and work on the tickets they name:
+
+  POST /webhooks/linear   an issue gains the serve.linear.label label
+  POST /webhooks/github   an issue comment starts with serve.github.command,
+                          e.g. "/boatman implement ENG-123" (the ticket
+                          defaults to the one in the issue title)
+  GET  /healthz           liveness check
+
+Each endpoint is enabled by its secret (LINEAR_WEBHOOK_SECRET or
+GITHUB_WEBHOOK_SECRET), and deliveries whose signature doesn't match are
+refused. GitHub comments only start runs from the serve.github.associations
+(owners, members and collaborators by default).
+
+Requested tickets are queued by priority and run like boatman daemon runs
+them, up to daemon.max_parallel at a time; with Linear as the source, the
+ticket is moved and commented on as it goes. Run the server from the
+repository the tickets are for. Interrupting it stops accepting webhooks;
+running tickets finish first.`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+func init() {
+	serveCmd.Flags().String("addr", "", "Address to listen on (overrides serve.addr)")
+	serveCmd.Flags().Int("max-parallel", 0, "How many tickets to run at once (overrides daemon.max_parallel)")
+	rootCmd.AddCommand(serveCmd)
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if addr, _ := cmd.Flags().GetString("addr"); addr != "" {
+		cfg.Serve.Addr = addr
+	}
+	if n, _ := cmd.Flags().GetInt("max-parallel"); n > 0 {
+		cfg.Daemon.MaxParallel = n
+	}
+
+	repoPath, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	// Only Linear tickets are moved and commented on
+	var tracker daemon.Tracker
+	if cfg.Source == string(task.SourceLinear) {
+		tracker = linear.New(cfg.LinearKey)
+	}
+	jobs, err := daemon.NewWorker(cfg.Daemon, tracker, workTicket(repoPath, cfg.Source, nil, nil))
+	if err != nil {
+		return err
+	}
+	srv, err := server.New(cfg.Serve, jobs)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	httpServer := &http.Server{
+		Addr:              cfg.Serve.Addr,
+		Handler:           srv.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.ListenAndServe()
+	}()
+
+	fmt.Printf("🛰️  Listening on %s\n", cfg.Serve.Addr)
+	if cfg.Serve.Linear.Secret != "" {
+		fmt.Printf("   POST /webhooks/linear: issues labeled %q\n", cfg.Serve.Linear.Label)
+	}
+	if cfg.Serve.GitHub.Secret != "" {
+		fmt.Printf("   POST /webhooks/github: %q comments\n", cfg.Serve.GitHub.Command)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		jobs.Run(ctx)
+	}()
+
+	select {
+	case err = <-serveErr:
+		// The listener failed; stop the worker too
+		stop()
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if shutdownErr := httpServer.Shutdown(shutdownCtx); shutdownErr != nil && err == nil {
+		err = shutdownErr
+	}
+	<-done
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
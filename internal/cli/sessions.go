@@ -13,15 +13,19 @@ import (
 
 // sessionsCmd manages tmux sessions.
 var sessionsCmd = &cobra.Command{
-	Use:   "sessions",
-	Short: "Manage agent tmux sessions",
-	Long:  `List, attach to, or kill tmux sessions used by boatman agents.`,
+	Use:     "sessions",
+	Aliases: []string{"session"},
+	Short:   "Manage agent tmux sessions",
+	Long:    `List, attach to, or kill tmux sessions used by boatman agents.`,
 }
 
 var sessionsListCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List active agent sessions",
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if !tmux.Available() {
+			return listDirectSessions()
+		}
 		mgr := tmux.NewManager("boatman")
 		sessions, err := mgr.ListSessions()
 		if err != nil {
@@ -46,9 +50,10 @@ var sessionsListCmd = &cobra.Command{
 }
 
 var sessionsAttachCmd = &cobra.Command{
-	Use:   "attach [session-name]",
-	Short: "Attach to an agent session",
-	Args:  cobra.ExactArgs(1),
+	Use:               "attach [session-name]",
+	Short:             "Attach to an agent session",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeSessionNames,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		mgr := tmux.NewManager("boatman")
 		sess := &tmux.Session{Name: args[0]}
@@ -65,7 +70,8 @@ var sessionsKillCmd = &cobra.Command{
 
 Without arguments, kills all boatman tmux sessions.
 With --force, also kills any orphaned claude processes.`,
-	Args:  cobra.MaximumNArgs(1),
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeSessionNames,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		mgr := tmux.NewManager("boatman")
 
@@ -133,11 +139,16 @@ var watchCmd = &cobra.Command{
 	Long: `Opens a tmux view showing all active boatman agent sessions.
 	
 Use Ctrl+B then arrow keys to switch between panes.
-Use Ctrl+B then D to detach.`,
+Use Ctrl+B then D to detach.
+
+Without tmux (e.g. on Windows), follows the agents' output files instead.`,
 	RunE: runWatch,
 }
 
 func runWatch(cmd *cobra.Command, args []string) error {
+	if !tmux.Available() {
+		return watchLogs()
+	}
 	mgr := tmux.NewManager("boatman")
 	sessions, err := mgr.ListSessions()
 	if err != nil || len(sessions) == 0 {
@@ -213,16 +224,7 @@ var cleanupCmd = &cobra.Command{
 		
 		cleaned := 0
 		for _, name := range sessions {
-			// Check if session is idle (no running command)
-			checkCmd := exec.Command("tmux", "list-panes", "-t", name, "-F", "#{pane_current_command}")
-			output, err := checkCmd.Output()
-			if err != nil {
-				continue
-			}
-			
-			// If just showing bash/zsh, it's idle
-			command := strings.TrimSpace(string(output))
-			if command == "bash" || command == "zsh" || command == "sh" {
+			if sessionIdle(name) {
 				sess := &tmux.Session{Name: name}
 				mgr.KillSession(sess)
 				fmt.Printf("Cleaned up idle session: %s\n", name)
@@ -237,6 +239,19 @@ var cleanupCmd = &cobra.Command{
 	},
 }
 
+// sessionIdle reports whether a session's pane is just a shell (no running command).
+func sessionIdle(name string) bool {
+	checkCmd := exec.Command("tmux", "list-panes", "-t", name, "-F", "#{pane_current_command}")
+	output, err := checkCmd.Output()
+	if err != nil {
+		return false
+	}
+
+	// If just showing bash/zsh, it's idle
+	command := strings.TrimSpace(string(output))
+	return command == "bash" || command == "zsh" || command == "sh"
+}
+
 func init() {
 	rootCmd.AddCommand(sessionsCmd)
 	rootCmd.AddCommand(watchCmd)
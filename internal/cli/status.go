@@ -0,0 +1,215 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/checkpoint"
+	"github.com/philjestin/boatmanmode/internal/tmux"
+	"github.com/spf13/cobra"
+)
+
+var statusStaleAfter time.Duration
+
+// statusCmd shows in-flight runs and leftovers needing cleanup.
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show in-flight runs and stale worktrees/sessions",
+	Long: `Show, at a glance:
+  - In-flight runs (from checkpoints): current step, iteration, elapsed time,
+    cost so far and associated tmux sessions
+  - Stale runs whose process exited without finishing
+  - Worktrees of this repo with no active run, untouched for --stale-after
+  - Idle boatman tmux sessions with no active run`,
+	RunE: runStatus,
+}
+
+func init() {
+	statusCmd.Flags().DurationVar(&statusStaleAfter, "stale-after", 24*time.Hour, "Age after which unused worktrees are reported as stale")
+	rootCmd.AddCommand(statusCmd)
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	cpManager, err := checkpoint.NewManager("")
+	if err != nil {
+		return err
+	}
+	checkpoints, _ := cpManager.List()
+	sort.Slice(checkpoints, func(i, j int) bool {
+		return checkpoints[i].CreatedAt.Before(checkpoints[j].CreatedAt)
+	})
+
+	sessionPaths, _ := tmux.NewManager("boatman").SessionPaths()
+	now := time.Now()
+
+	var live, stale, paused, cancelled []checkpoint.Checkpoint
+	for _, cp := range checkpoints {
+		if cp.Paused != "" && cp.CurrentStep != checkpoint.StepComplete {
+			paused = append(paused, cp)
+			continue
+		}
+		if cp.Cancelled != "" && cp.CurrentStep != checkpoint.StepComplete {
+			cancelled = append(cancelled, cp)
+			continue
+		}
+		if !cp.InFlight() {
+			continue
+		}
+		if checkpoint.ProcessAlive(cp.PID) {
+			live = append(live, cp)
+		} else {
+			stale = append(stale, cp)
+		}
+	}
+
+	fmt.Println("Boatman Status")
+	fmt.Println("═══════════════════════════════════════")
+
+	fmt.Println("In-flight runs")
+	if len(live) == 0 {
+		fmt.Println("  (none)")
+	}
+	for _, cp := range live {
+		printRun(cp, now, sessionPaths)
+	}
+
+	if len(paused) > 0 {
+		fmt.Println()
+		fmt.Println("Paused runs")
+		for _, cp := range paused {
+			printRun(cp, now, sessionPaths)
+			fmt.Printf("    Waiting: %s\n", cp.Paused)
+		}
+	}
+
+	if len(cancelled) > 0 {
+		fmt.Println()
+		fmt.Println("Cancelled runs (continue with boatman resume)")
+		for _, cp := range cancelled {
+			printRun(cp, now, sessionPaths)
+		}
+	}
+
+	if len(stale) > 0 {
+		fmt.Println()
+		fmt.Println("Stale runs (process exited before finishing)")
+		for _, cp := range stale {
+			printRun(cp, now, sessionPaths)
+		}
+	}
+
+	// Anything not used by a live run is a cleanup candidate
+	activePaths := make(map[string]bool)
+	lastUsed := make(map[string]time.Time)
+	for _, cp := range checkpoints {
+		if cp.WorktreePath == "" {
+			continue
+		}
+		if cp.UpdatedAt.After(lastUsed[cp.WorktreePath]) {
+			lastUsed[cp.WorktreePath] = cp.UpdatedAt
+		}
+	}
+	// Paused and cancelled runs continue in their worktrees
+	for _, cp := range append(append(live, paused...), cancelled...) {
+		activePaths[cp.WorktreePath] = true
+	}
+
+	if staleWorktrees := findStaleWorktrees(activePaths, lastUsed, now); len(staleWorktrees) > 0 {
+		fmt.Println()
+		fmt.Printf("Stale worktrees (no active run, unused for %s)\n", statusStaleAfter)
+		for _, path := range staleWorktrees {
+			fmt.Printf("  • %s\n", path)
+		}
+		fmt.Println("  Clean up with: boatman worktree clean")
+	}
+
+	var staleSessions []string
+	for name, path := range sessionPaths {
+		if !activePaths[path] && sessionIdle(name) {
+			staleSessions = append(staleSessions, name)
+		}
+	}
+	sort.Strings(staleSessions)
+	if len(staleSessions) > 0 {
+		fmt.Println()
+		fmt.Println("Idle sessions (no active run)")
+		for _, name := range staleSessions {
+			fmt.Printf("  • %s\n", name)
+		}
+		fmt.Println("  Clean up with: boatman sessions cleanup")
+	}
+
+	fmt.Println("═══════════════════════════════════════")
+	return nil
+}
+
+// printRun prints one checkpointed run.
+func printRun(cp checkpoint.Checkpoint, now time.Time, sessionPaths map[string]string) {
+	fmt.Printf("  • %s  %s (iteration %d/%d)\n", cp.TicketID, cp.CurrentStep, cp.Iteration, cp.MaxIterations)
+	fmt.Printf("    Elapsed: %s   Cost: $%.2f\n", runElapsed(cp, now).Round(time.Second), cp.CostUSD)
+	if cp.WorktreePath != "" {
+		fmt.Printf("    Worktree: %s\n", cp.WorktreePath)
+	}
+
+	var sessions []string
+	for name, path := range sessionPaths {
+		if cp.WorktreePath != "" && path == cp.WorktreePath {
+			sessions = append(sessions, name)
+		}
+	}
+	sort.Strings(sessions)
+	if len(sessions) > 0 {
+		fmt.Printf("    Sessions: %s\n", strings.Join(sessions, ", "))
+	}
+	fmt.Printf("    Checkpoint: %s\n", cp.ID)
+}
+
+// runElapsed is the run's elapsed time; runs whose process died stop at
+// their last checkpoint update.
+func runElapsed(cp checkpoint.Checkpoint, now time.Time) time.Duration {
+	if cp.InFlight() && !checkpoint.ProcessAlive(cp.PID) {
+		return cp.UpdatedAt.Sub(cp.CreatedAt)
+	}
+	return cp.Elapsed(now)
+}
+
+// findStaleWorktrees returns this repo's worktrees with no active run that
+// haven't been used (per checkpoints, else modification time) for statusStaleAfter.
+func findStaleWorktrees(active map[string]bool, lastUsed map[string]time.Time, now time.Time) []string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	base := worktreeBaseDir(cwd)
+	entries, err := os.ReadDir(base)
+	if err != nil {
+		return nil
+	}
+
+	var stale []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(base, entry.Name())
+		if active[path] {
+			continue
+		}
+		used, ok := lastUsed[path]
+		if !ok {
+			info, err := entry.Info()
+			if err != nil {
+				continue
+			}
+			used = info.ModTime()
+		}
+		if now.Sub(used) >= statusStaleAfter {
+			stale = append(stale, path)
+		}
+	}
+	return stale
+}
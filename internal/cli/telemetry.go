@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/telemetry"
+	"github.com/spf13/cobra"
+)
+
+// telemetryCmd manages opt-in anonymous usage metrics.
+var telemetryCmd = &cobra.Command{
+	Use:   "telemetry",
+	Short: "Manage opt-in anonymous usage metrics",
+	Long: `Telemetry is off by default. When enabled, each run reports:
+  - step names and durations
+  - review/refactor iteration count and total duration
+  - the outcome and a failure category (failed step + timeout/canceled/error)
+  - boatman version, OS and architecture, and a random install ID
+
+Ticket IDs, code, diffs, prompts, file paths, and error messages are never sent.
+Every report is also written to a local log you can inspect.`,
+}
+
+var telemetryStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show whether telemetry is enabled and what was sent",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := config.LoadUnvalidated()
+		client := telemetry.New(cfg.Telemetry, "")
+
+		if client.Enabled() {
+			fmt.Println("Telemetry: enabled")
+		} else {
+			fmt.Println("Telemetry: disabled (enable with: boatman telemetry enable)")
+		}
+		if endpoint := client.Endpoint(); endpoint != "" {
+			fmt.Printf("Endpoint:  %s\n", endpoint)
+		} else {
+			fmt.Println("Endpoint:  none (reports are only logged locally)")
+		}
+		fmt.Printf("Local log: %s\n", client.LogPath())
+
+		reports, err := client.Recent(5)
+		if err != nil {
+			return err
+		}
+		if len(reports) > 0 {
+			fmt.Println()
+			fmt.Println("Recent reports:")
+			for _, r := range reports {
+				line := fmt.Sprintf("  • %s  %s  %d iteration(s)  %s",
+					r.Timestamp.Local().Format(time.DateTime), r.Outcome, r.Iterations,
+					(time.Duration(r.DurationMs) * time.Millisecond).Round(time.Second))
+				if r.FailureCategory != "" {
+					line += "  " + r.FailureCategory
+				}
+				fmt.Println(line)
+			}
+		}
+		return nil
+	},
+}
+
+var telemetryEnableCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "Opt in to anonymous usage metrics",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setTelemetry(true)
+	},
+}
+
+var telemetryDisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Opt out of anonymous usage metrics",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setTelemetry(false)
+	},
+}
+
+func setTelemetry(enabled bool) error {
+	path, err := userConfigPath()
+	if err != nil {
+		return err
+	}
+	if err := config.SetValue(path, "telemetry.enabled", enabled); err != nil {
+		return err
+	}
+	state := "disabled"
+	if enabled {
+		state = "enabled"
+	}
+	fmt.Printf("Telemetry %s (%s)\n", state, path)
+	return nil
+}
+
+func init() {
+	rootCmd.AddCommand(telemetryCmd)
+	telemetryCmd.AddCommand(telemetryStatusCmd)
+	telemetryCmd.AddCommand(telemetryEnableCmd)
+	telemetryCmd.AddCommand(telemetryDisableCmd)
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/estimate"
+	"github.com/philjestin/boatmanmode/internal/gitops"
+	"github.com/philjestin/boatmanmode/internal/linear"
+	"github.com/philjestin/boatmanmode/internal/memory"
+	"github.com/philjestin/boatmanmode/internal/task"
+	"github.com/philjestin/boatmanmode/internal/triage"
+	"github.com/spf13/cobra"
+)
+
+// triageCmd investigates a ticket and posts the analysis without changing
+// any code.
+var triageCmd = &cobra.Command{
+	Use:   "triage <ticket-id>",
+	Short: "Investigate a ticket and post a root-cause analysis without changing code",
+	Long: `Run a read-only agent on a ticket: it explores the code with Read, Grep and
+Glob only, and reports the suspected root cause, the files involved, a proposed
+approach and how to test it, with an effort estimate from this repository's
+run history. The analysis is posted as a comment on Linear tickets; use
+--print to only print it. Nothing in the repository is changed, so triage is
+cheap enough to run across a backlog.
+
+  boatman triage ENG-123
+  boatman triage PROJ-42 --source jira --print`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeTicketIDs,
+	RunE:              runTriage,
+}
+
+func init() {
+	triageCmd.Flags().Bool("print", false, "Print the analysis instead of posting it to the ticket")
+	triageCmd.Flags().Bool("json", false, "Print the analysis and estimate as JSON instead of posting it")
+	rootCmd.AddCommand(triageCmd)
+}
+
+func runTriage(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	printOnly, _ := cmd.Flags().GetBool("print")
+	asJSON, _ := cmd.Flags().GetBool("json")
+
+	// Keep stdout clean for --json; progress goes to stderr
+	out, progress := cmd.OutOrStdout(), cmd.OutOrStdout()
+	if asJSON {
+		progress = cmd.ErrOrStderr()
+	}
+
+	t, err := parseTaskInput(cmd, args, cfg, progress)
+	if err != nil {
+		return err
+	}
+	repoPath, err := gitops.New(".").RevParse("--show-toplevel")
+	if err != nil {
+		return fmt.Errorf("not in a git repository: %w", err)
+	}
+
+	ctx := context.Background()
+	tr := triage.New(repoPath, cfg)
+	tr.SetOutput(progress)
+	analysis, usage, err := tr.Investigate(ctx, t)
+	if err != nil {
+		return err
+	}
+	var investigationCost float64
+	if usage != nil {
+		investigationCost = usage.TotalCostUSD
+	}
+	var mem *memory.Memory
+	if store, err := memory.NewStore(""); err == nil {
+		mem, _ = store.Get(repoPath)
+	}
+	e := estimate.New(t.GetID(), t.GetTitle(), analysis.Plan(), investigationCost, mem, cfg.MaxIterations)
+	e.AssessSuccess(repoPath, cfg.Commands.Test)
+
+	if asJSON {
+		data, err := json.MarshalIndent(struct {
+			*triage.Analysis
+			Estimate *estimate.Estimate `json:"estimate"`
+		}{analysis, e}, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, string(data))
+		return nil
+	}
+
+	comment := analysis.Comment(e)
+	if printOnly || cfg.Source != string(task.SourceLinear) {
+		if !printOnly {
+			fmt.Fprintf(progress, "   ℹ️  Posting triage is supported for Linear tickets; printing it instead\n")
+		}
+		fmt.Fprintln(out)
+		fmt.Fprint(out, comment)
+		return nil
+	}
+	if err := linear.New(cfg.LinearKey).Comment(ctx, t.GetID(), comment); err != nil {
+		return fmt.Errorf("failed to post the analysis to %s: %w", t.GetID(), err)
+	}
+	fmt.Fprintf(out, "💬 Posted the triage to %s (%s complexity, ~$%.2f to automate)\n", t.GetID(), e.Complexity, e.PredictedCostUSD)
+	return nil
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"runtime"
 
+	"github.com/philjestin/boatmanmode/internal/manifest"
+	"github.com/philjestin/boatmanmode/internal/telemetry"
 	"github.com/spf13/cobra"
 )
 
@@ -21,6 +23,8 @@ func SetVersionInfo(v, c, d, b string) {
 	commit = c
 	date = d
 	builtBy = b
+	telemetry.SetVersion(v)
+	manifest.SetVersion(v)
 }
 
 // versionCmd represents the version command.
@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/tmux"
+)
+
+// staleSession is how long a session's output can go unwritten before it
+// is taken for a run that was killed without writing its done file.
+const staleSession = time.Hour
+
+// directSessions returns the output files of the agents running under the
+// direct runner, by session name: those whose done file is not written yet.
+func directSessions() map[string]string {
+	files, _ := filepath.Glob(filepath.Join(tmux.OutputDir(), "boatman-*.out"))
+	sessions := make(map[string]string)
+	for _, file := range files {
+		base := strings.TrimSuffix(file, ".out")
+		if _, err := os.Stat(base + ".done"); err == nil {
+			continue
+		}
+		if info, err := os.Stat(file); err != nil || time.Since(info.ModTime()) > staleSession {
+			continue
+		}
+		sessions[filepath.Base(base)] = file
+	}
+	return sessions
+}
+
+// listDirectSessions lists the agents running under the direct runner.
+func listDirectSessions() error {
+	sessions := directSessions()
+	if len(sessions) == 0 {
+		fmt.Println("No active boatman sessions")
+		return nil
+	}
+	names := make([]string, 0, len(sessions))
+	for name := range sessions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Println("Active boatman sessions:")
+	for _, name := range names {
+		fmt.Printf("  • %s (%s)\n", name, sessions[name])
+	}
+	fmt.Println()
+	fmt.Println("Watch with: boatman watch")
+	return nil
+}
+
+// watchLogs follows the output of direct-runner sessions, printing each
+// new line with its session's name, until they are all done or the
+// operator presses Ctrl-C. It is how boatman watch works without tmux.
+func watchLogs() error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	sessions := directSessions()
+	if len(sessions) == 0 {
+		fmt.Println("No active boatman sessions to watch.")
+		fmt.Println("Start a job first: boatman work <ticket-id>")
+		return nil
+	}
+	fmt.Println("Following agent output (Ctrl-C to stop)...")
+
+	offsets := make(map[string]int64)
+	for {
+		names := make([]string, 0, len(sessions))
+		for name := range sessions {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			offsets[name] = printFrom(sessions[name], offsets[name], strings.TrimPrefix(name, "boatman-"))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(500 * time.Millisecond):
+		}
+
+		// Pick up agents that started since, and stop after the last ends
+		active := directSessions()
+		for name, file := range sessions {
+			if _, ok := active[name]; !ok {
+				printFrom(file, offsets[name], strings.TrimPrefix(name, "boatman-"))
+			}
+		}
+		for name := range active {
+			if _, ok := sessions[name]; !ok {
+				offsets[name] = 0
+			}
+		}
+		if len(active) == 0 {
+			fmt.Println("All agent sessions finished.")
+			return nil
+		}
+		sessions = active
+	}
+}
+
+// printFrom prints the lines of file after offset, prefixed with label,
+// and returns the offset it read up to.
+func printFrom(file string, offset int64, label string) int64 {
+	f, err := os.Open(file)
+	if err != nil {
+		return offset
+	}
+	defer f.Close()
+	// A new run of the session starts its file over
+	if info, err := f.Stat(); err == nil && info.Size() < offset {
+		offset = 0
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return offset
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return offset
+	}
+	// Leave a partly written line for the next read
+	end := strings.LastIndexByte(string(data), '\n') + 1
+	for _, line := range strings.Split(strings.TrimSuffix(string(data[:end]), "\n"), "\n") {
+		if line != "" {
+			fmt.Printf("[%s] %s\n", label, line)
+		}
+	}
+	return offset + int64(end)
+}
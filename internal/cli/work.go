@@ -2,11 +2,20 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/philjestin/boatmanmode/internal/agent"
+	"github.com/philjestin/boatmanmode/internal/azure"
+	"github.com/philjestin/boatmanmode/internal/checkpoint"
 	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/jira"
 	"github.com/philjestin/boatmanmode/internal/linear"
 	"github.com/philjestin/boatmanmode/internal/task"
 	"github.com/spf13/cobra"
@@ -16,13 +25,15 @@ import (
 // workCmd represents the work command - the main workflow executor.
 var workCmd = &cobra.Command{
 	Use:   "work [ticket-id-or-prompt]",
-	Short: "Execute a task from Linear, prompt, or file",
+	Short: "Execute a task from Linear, Jira, Azure Boards, prompt, or file",
 	Long: `Execute a development task from multiple input sources.
 
 Input modes:
   1. Linear ticket (default):    boatman work ENG-123
-  2. Inline prompt:              boatman work --prompt "Add authentication"
-  3. File-based prompt:          boatman work --file ./task.txt
+  2. Jira ticket:                boatman work PROJ-123 --source jira
+  3. Azure Boards work item:     boatman work 123 --source azure
+  4. Inline prompt:              boatman work --prompt "Add authentication"
+  5. File-based prompt:          boatman work --file ./task.txt
 
 The agent will:
   1. Prepare the task
@@ -33,8 +44,9 @@ The agent will:
   6. Create a pull request
 
 Flags like --title and --branch-name can override auto-generated values for prompt/file mode.`,
-	Args: cobra.ExactArgs(1),
-	RunE: runWork,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeTicketIDs,
+	RunE:              runWork,
 }
 
 func init() {
@@ -44,64 +56,169 @@ func init() {
 	workCmd.Flags().Int("max-iterations", 3, "Maximum review/refactor iterations")
 	workCmd.Flags().String("base-branch", "main", "Base branch for worktree")
 	workCmd.Flags().Bool("auto-pr", true, "Automatically create PR on success")
-	workCmd.Flags().Bool("dry-run", false, "Run without making changes")
+	workCmd.Flags().Bool("dry-run", false, "Stop after review without committing, pushing or creating a PR")
 	workCmd.Flags().Int("timeout", 60, "Timeout in minutes for each Claude agent")
+	workCmd.Flags().Float64("max-cost", 0, "Stop the run once it has spent this many USD (0 = no limit)")
 	workCmd.Flags().String("review-skill", "peer-review", "Claude skill/agent to use for code review")
+	workCmd.Flags().Bool("force", false, "Run even if the success predictor declines the task")
+	workCmd.Flags().Bool("pair", false, "Pause after execution and each refactor for manual edits in the worktree")
+	workCmd.Flags().Bool("review-plan", false, "Approve or edit the plan before execution (pauses for boatman resume --approve-plan without a terminal)")
+	workCmd.Flags().Bool("retro", false, "Distill lessons from the finished run into project memory")
+	workCmd.Flags().Bool("detach", false, "Run in the background and print the run ID; follow it with boatman logs -f")
+	workCmd.Flags().String("run-id", "", "Checkpoint ID to use for the run (set by --detach)")
+	workCmd.Flags().MarkHidden("run-id")
+	addOutputFlags(workCmd)
 
 	// New input mode flags
 	workCmd.Flags().Bool("prompt", false, "Treat argument as inline prompt text")
 	workCmd.Flags().Bool("file", false, "Read prompt from file")
 	workCmd.Flags().String("title", "", "Override auto-generated task title (prompt/file mode only)")
 	workCmd.Flags().String("branch-name", "", "Override auto-generated branch name (prompt/file mode only)")
+	workCmd.Flags().String("source", "linear", "Where to fetch ticket IDs from: linear, jira or azure")
 
 	viper.BindPFlag("max_iterations", workCmd.Flags().Lookup("max-iterations"))
 	viper.BindPFlag("base_branch", workCmd.Flags().Lookup("base-branch"))
 	viper.BindPFlag("auto_pr", workCmd.Flags().Lookup("auto-pr"))
 	viper.BindPFlag("timeout", workCmd.Flags().Lookup("timeout"))
+	viper.BindPFlag("max_cost_usd", workCmd.Flags().Lookup("max-cost"))
 	viper.BindPFlag("review_skill", workCmd.Flags().Lookup("review-skill"))
+	viper.BindPFlag("pair", workCmd.Flags().Lookup("pair"))
+	viper.BindPFlag("review_plan", workCmd.Flags().Lookup("review-plan"))
+	viper.BindPFlag("retro", workCmd.Flags().Lookup("retro"))
+	viper.BindPFlag("dry_run", workCmd.Flags().Lookup("dry-run"))
+	viper.BindPFlag("source", workCmd.Flags().Lookup("source"))
 }
 
 // runWork executes the main workflow for a given task.
 func runWork(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
+	ctx, stop := interruptContext()
+	defer stop()
 
 	cfg, err := config.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
+	if err := task.SetBranchScheme(branchScheme(cfg)); err != nil {
+		return fmt.Errorf("invalid branch naming config: %w", err)
+	}
+
+	out, done, err := setupOutput(cmd)
+	if err != nil {
+		return err
+	}
+	defer done()
 
 	// Validate and parse input mode
-	t, err := parseTaskInput(cmd, args, cfg)
+	t, err := parseTaskInput(cmd, args, cfg, out)
 	if err != nil {
 		return err
 	}
 
-	dryRun, _ := cmd.Flags().GetBool("dry-run")
-	if dryRun {
-		fmt.Println("🏃 Dry run mode - no changes will be made")
+	if detach, _ := cmd.Flags().GetBool("detach"); detach {
+		if cfg.Pair {
+			return fmt.Errorf("--pair needs a terminal and cannot be used with --detach")
+		}
+		return detachWork(out, t)
+	}
+
+	if cfg.DryRun {
+		fmt.Fprintln(out, "🏃 Dry run mode - stopping before commit, push and PR")
 	}
 
 	a, err := agent.New(cfg)
 	if err != nil {
 		return fmt.Errorf("failed to create agent: %w", err)
 	}
+	a.SetOutput(out)
+	force, _ := cmd.Flags().GetBool("force")
+	a.SetForce(force)
+	if id, _ := cmd.Flags().GetString("run-id"); id != "" {
+		a.SetRunID(id)
+	}
 
 	result, err := a.Work(ctx, t)
+	if alreadyRunning(out, err) {
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("work failed: %w", err)
 	}
 
-	if result.PRCreated {
-		fmt.Printf("✅ PR created: %s\n", result.PRURL)
-	} else {
-		fmt.Printf("⚠️  Work completed but PR not created: %s\n", result.Message)
+	reportResult(out, cfg, t, result)
+	return nil
+}
+
+// detachWork starts the same work command again in the background, writing
+// its output to the run's log, and returns once it has started.
+func detachWork(out io.Writer, t task.Task) error {
+	if mgr, err := checkpoint.NewManager(""); err == nil {
+		if runID, ok := mgr.LockedBy(t.GetID()); ok {
+			alreadyRunning(out, &checkpoint.RunningError{TicketID: t.GetID(), RunID: runID})
+			return nil
+		}
+	}
+	id := checkpoint.NewID(t.GetID())
+	logPath, err := runLogPath(id)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(logPath), 0755); err != nil {
+		return fmt.Errorf("failed to create log directory: %w", err)
+	}
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		return fmt.Errorf("failed to create run log: %w", err)
+	}
+	defer logFile.Close()
+
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	args := append(os.Args[1:], "--detach=false", "--run-id", id)
+	child := exec.Command(exe, args...)
+	child.Stdout = logFile
+	child.Stderr = logFile
+	child.SysProcAttr = detachedProcess()
+	if err := child.Start(); err != nil {
+		return fmt.Errorf("failed to start background run: %w", err)
 	}
+	pid := child.Process.Pid
+	child.Process.Release()
 
+	pidPath := strings.TrimSuffix(logPath, ".log") + ".pid"
+	os.WriteFile(pidPath, []byte(strconv.Itoa(pid)), 0644)
+
+	fmt.Fprintf(out, "🚀 Started run %s in the background (pid %d)\n", id, pid)
+	fmt.Fprintf(out, "   Follow it with: boatman logs -f %s\n", id)
 	return nil
 }
 
-// parseTaskInput determines the input mode and creates the appropriate Task.
-func parseTaskInput(cmd *cobra.Command, args []string, cfg *config.Config) (task.Task, error) {
+// alreadyRunning reports the existing run when err says the ticket is
+// already being worked on, so a duplicate trigger is not a failure.
+func alreadyRunning(out io.Writer, err error) bool {
+	var running *checkpoint.RunningError
+	if !errors.As(err, &running) {
+		return false
+	}
+	fmt.Fprintf(out, "⏭️  %s is already being worked on by run %s\n", running.TicketID, running.RunID)
+	fmt.Fprintln(out, "   Check on it with: boatman status")
+	return true
+}
+
+// branchScheme converts the branch naming config.
+func branchScheme(cfg *config.Config) task.BranchScheme {
+	return task.BranchScheme{
+		Template:    cfg.Branch.Template,
+		SlugLength:  cfg.Branch.SlugLength,
+		Types:       cfg.Branch.Types,
+		DefaultType: cfg.Branch.DefaultType,
+	}
+}
+
+// parseTaskInput determines the input mode and creates the appropriate Task,
+// printing the mode to out.
+func parseTaskInput(cmd *cobra.Command, args []string, cfg *config.Config, out io.Writer) (task.Task, error) {
 	input := args[0]
 
 	// Get mode flags
@@ -134,21 +251,36 @@ func parseTaskInput(cmd *cobra.Command, args []string, cfg *config.Config) (task
 
 	// Create task based on mode
 	if isPrompt {
-		fmt.Println("📝 Prompt mode")
+		fmt.Fprintln(out, "📝 Prompt mode")
 		return task.CreateFromPrompt(input, overrideTitle, overrideBranch)
 	}
 
 	if isFile {
-		fmt.Println("📄 File mode")
+		fmt.Fprintln(out, "📄 File mode")
 		// Validate file exists
 		if _, err := os.Stat(input); err != nil {
 			return nil, fmt.Errorf("task file does not exist: %s", input)
 		}
-		return task.CreateFromFile(input, overrideTitle, overrideBranch)
+		t, err := task.CreateFromFile(input, overrideTitle, overrideBranch)
+		if err == nil {
+			if placeholders := task.Placeholders(t.GetDescription()); len(placeholders) > 0 {
+				fmt.Fprintf(out, "⚠️  Task file still has template placeholders: %s\n", strings.Join(placeholders, ", "))
+			}
+		}
+		return t, err
+	}
+
+	if cfg.Source == string(task.SourceJira) {
+		fmt.Fprintln(out, "🎫 Jira mode")
+		return task.CreateFromJira(ctx, jira.New(cfg.Jira), input)
+	}
+	if cfg.Source == string(task.SourceAzure) {
+		fmt.Fprintln(out, "🎫 Azure Boards mode")
+		return task.CreateFromAzure(ctx, azure.New(cfg.Azure), input)
 	}
 
 	// Default: Linear mode
-	fmt.Println("🎫 Linear mode")
+	fmt.Fprintln(out, "🎫 Linear mode")
 	linearClient := linear.New(cfg.LinearKey)
 	return task.CreateFromLinear(ctx, linearClient, input)
 }
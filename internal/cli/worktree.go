@@ -3,17 +3,20 @@ package cli
 import (
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/gitops"
+	"github.com/philjestin/boatmanmode/internal/worktree"
 	"github.com/spf13/cobra"
 )
 
 // worktreeCmd manages worktrees.
 var worktreeCmd = &cobra.Command{
-	Use:   "worktree",
-	Short: "Manage boatman worktrees",
-	Long:  `List, commit, or clean up worktrees created by boatman.`,
+	Use:     "worktree",
+	Aliases: []string{"worktrees", "wt"},
+	Short:   "Manage boatman worktrees",
+	Long:    `List, commit, or clean up worktrees created by boatman.`,
 }
 
 var worktreeListCmd = &cobra.Command{
@@ -21,7 +24,7 @@ var worktreeListCmd = &cobra.Command{
 	Short: "List active worktrees",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cwd, _ := os.Getwd()
-		worktreeBase := filepath.Join(cwd, ".worktrees")
+		worktreeBase := worktreeBaseDir(cwd)
 
 		entries, err := os.ReadDir(worktreeBase)
 		if err != nil {
@@ -48,13 +51,14 @@ var worktreeListCmd = &cobra.Command{
 }
 
 var worktreeCommitCmd = &cobra.Command{
-	Use:   "commit [worktree-name] [message]",
-	Short: "Commit changes in a worktree",
+	Use:               "commit [worktree-name] [message]",
+	Short:             "Commit changes in a worktree",
+	ValidArgsFunction: completeWorktreeNames,
 	Long: `Stages all changes and creates a commit in the specified worktree.
 If no worktree name is given, commits to the most recently modified one.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cwd, _ := os.Getwd()
-		worktreeBase := filepath.Join(cwd, ".worktrees")
+		worktreeBase := worktreeBaseDir(cwd)
 
 		var wtPath string
 		var message string
@@ -84,30 +88,21 @@ If no worktree name is given, commits to the most recently modified one.`,
 
 		fmt.Printf("Committing changes in: %s\n", wtPath)
 
+		git := gitops.New(wtPath)
+
 		// Stage all changes
-		stageCmd := exec.Command("git", "add", "-A")
-		stageCmd.Dir = wtPath
-		stageCmd.Stdout = os.Stdout
-		stageCmd.Stderr = os.Stderr
-		if err := stageCmd.Run(); err != nil {
+		if err := git.AddAll(); err != nil {
 			return fmt.Errorf("failed to stage: %w", err)
 		}
 
 		// Check if there are changes to commit
-		statusCmd := exec.Command("git", "status", "--porcelain")
-		statusCmd.Dir = wtPath
-		statusOut, _ := statusCmd.Output()
-		if len(statusOut) == 0 {
+		if changed, _ := git.HasChanges(); !changed {
 			fmt.Println("Nothing to commit - working tree clean")
 			return nil
 		}
 
 		// Commit
-		commitCmd := exec.Command("git", "commit", "-m", message)
-		commitCmd.Dir = wtPath
-		commitCmd.Stdout = os.Stdout
-		commitCmd.Stderr = os.Stderr
-		if err := commitCmd.Run(); err != nil {
+		if err := git.Commit(message); err != nil {
 			return fmt.Errorf("failed to commit: %w", err)
 		}
 
@@ -125,11 +120,12 @@ If no worktree name is given, commits to the most recently modified one.`,
 }
 
 var worktreePushCmd = &cobra.Command{
-	Use:   "push [worktree-name]",
-	Short: "Push a worktree branch to origin",
+	Use:               "push [worktree-name]",
+	Short:             "Push a worktree branch to origin",
+	ValidArgsFunction: completeWorktreeNames,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cwd, _ := os.Getwd()
-		worktreeBase := filepath.Join(cwd, ".worktrees")
+		worktreeBase := worktreeBaseDir(cwd)
 
 		var wtPath string
 		if len(args) >= 1 {
@@ -145,11 +141,7 @@ var worktreePushCmd = &cobra.Command{
 		branch := getBranch(wtPath)
 		fmt.Printf("Pushing branch: %s\n", branch)
 
-		pushCmd := exec.Command("git", "push", "-u", "origin", branch)
-		pushCmd.Dir = wtPath
-		pushCmd.Stdout = os.Stdout
-		pushCmd.Stderr = os.Stderr
-		return pushCmd.Run()
+		return gitops.New(wtPath).Push("origin", branch)
 	},
 }
 
@@ -158,7 +150,7 @@ var worktreeCleanCmd = &cobra.Command{
 	Short: "Remove all boatman worktrees",
 	RunE: func(cmd *cobra.Command, args []string) error {
 		cwd, _ := os.Getwd()
-		worktreeBase := filepath.Join(cwd, ".worktrees")
+		worktreeBase := worktreeBaseDir(cwd)
 
 		entries, err := os.ReadDir(worktreeBase)
 		if err != nil {
@@ -172,9 +164,9 @@ var worktreeCleanCmd = &cobra.Command{
 				fmt.Printf("Removing worktree: %s\n", entry.Name())
 
 				// Remove via git worktree
-				removeCmd := exec.Command("git", "worktree", "remove", wtPath, "--force")
-				removeCmd.Dir = cwd
-				removeCmd.Run()
+				if err := gitops.New(cwd).WorktreeRemove(wtPath, true); err != nil {
+					fmt.Printf("  ⚠️  %v\n", err)
+				}
 			}
 		}
 
@@ -183,25 +175,30 @@ var worktreeCleanCmd = &cobra.Command{
 	},
 }
 
+// worktreeBaseDir returns where boatman creates worktrees for repoPath,
+// honoring worktree.root.
+func worktreeBaseDir(repoPath string) string {
+	base, err := worktree.BaseDir(repoPath, config.LoadUnvalidated().Worktree.Root)
+	if err != nil {
+		return filepath.Join(repoPath, ".worktrees")
+	}
+	return base
+}
+
 func getBranch(wtPath string) string {
-	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
-	cmd.Dir = wtPath
-	out, err := cmd.Output()
+	branch, err := gitops.New(wtPath).CurrentBranch()
 	if err != nil {
 		return "unknown"
 	}
-	return string(out[:len(out)-1]) // trim newline
+	return branch
 }
 
 func getStatus(wtPath string) string {
-	cmd := exec.Command("git", "status", "--porcelain")
-	cmd.Dir = wtPath
-	out, _ := cmd.Output()
-	if len(out) == 0 {
+	entries, _ := gitops.New(wtPath).Status()
+	if len(entries) == 0 {
 		return "clean"
 	}
-	lines := len(out) / 3 // rough estimate of changed files
-	return fmt.Sprintf("%d files changed", lines)
+	return fmt.Sprintf("%d files changed", len(entries))
 }
 
 func init() {
@@ -0,0 +1,14 @@
+// Package cmdoutput trims the output of commands boatman runs, such as
+// test, build and lint commands, to what fits in an error or review issue.
+package cmdoutput
+
+import "strings"
+
+// Tail returns the last n lines of s, without trailing newlines.
+func Tail(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
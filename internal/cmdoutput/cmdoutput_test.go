@@ -0,0 +1,12 @@
+package cmdoutput
+
+import "testing"
+
+func TestTail(t *testing.T) {
+	if got := Tail("a\nb\nc\nd\n\n", 2); got != "c\nd" {
+		t.Errorf("Tail = %q", got)
+	}
+	if got := Tail("only\n", 5); got != "only" {
+		t.Errorf("Tail of a short output = %q", got)
+	}
+}
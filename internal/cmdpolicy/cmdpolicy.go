@@ -0,0 +1,667 @@
+// Package cmdpolicy blocks destructive commands agents try to run with
+// Claude's Bash tool: recursive rm outside the worktree, force pushes to
+// and deletes of protected branches, and downloads piped into a shell.
+// Sessions behind the egress proxy are also refused commands that bypass
+// it.
+//
+// Each session runs Claude with a PreToolUse hook that calls back into
+// boatman (boatman command-policy), which checks the command against the
+// session's Policy. A blocked command is refused, Claude is told why, and
+// the attempt is appended to a log.
+package cmdpolicy
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/gitops"
+)
+
+// Command is the hidden boatman command the hook runs.
+const Command = "command-policy"
+
+// Policy is the command policy of one agent session.
+type Policy struct {
+	// Session is the session's name, e.g. executor or refactor-2.
+	Session string `json:"session"`
+
+	// Worktree is the directory recursive removal is confined to.
+	Worktree string `json:"worktree"`
+
+	// ProtectedBranches may not be force-pushed or deleted.
+	ProtectedBranches []string `json:"protected_branches"`
+
+	// Deny are regexes of further commands the session is refused.
+	Deny []string `json:"deny,omitempty"`
+
+	// Proxied refuses commands that bypass the egress proxy the session
+	// reaches the network through.
+	Proxied bool `json:"proxied,omitempty"`
+
+	// Log is the file blocked attempts are appended to.
+	Log string `json:"log"`
+}
+
+// New returns the policy of a session working in worktree, with the deny
+// patterns for every role and the session's own. Returns nil when the
+// policy is disabled.
+func New(cfg config.CommandPolicyConfig, worktree, session string) *Policy {
+	if !cfg.Enabled {
+		return nil
+	}
+	if worktree == "" {
+		worktree, _ = os.Getwd()
+	}
+	if abs, err := filepath.Abs(worktree); err == nil {
+		worktree = abs
+	}
+	deny := append([]string{}, cfg.Deny...)
+	deny = append(deny, cfg.Roles[Role(session)]...)
+	return &Policy{
+		Session:           session,
+		Worktree:          worktree,
+		ProtectedBranches: cfg.ProtectedBranches,
+		Deny:              deny,
+		Log:               LogPath(),
+	}
+}
+
+// sessionNumber matches the iteration suffix of a session name.
+var sessionNumber = regexp.MustCompile(`-\d+$`)
+
+// Role returns the role of a session: its name without the iteration
+// suffix, e.g. refactor for refactor-2.
+func Role(session string) string {
+	return sessionNumber.ReplaceAllString(session, "")
+}
+
+// LogPath returns the log of blocked attempts, ~/.boatman/blocked-commands.log.
+func LogPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "boatman-blocked-commands.log")
+	}
+	return filepath.Join(home, ".boatman", "blocked-commands.log")
+}
+
+// Args writes the policy to dir and returns the claude flags installing
+// the hook that enforces it.
+func (p *Policy) Args(dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	// Sessions of parallel runs share names, so the file is per worktree too
+	file := filepath.Join(dir, fmt.Sprintf("%s-%08x-command-policy.json", p.Session, crc32.ChecksumIEEE([]byte(p.Worktree))))
+	if err := os.WriteFile(file, data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write command policy: %w", err)
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the boatman binary for the command policy hook: %w", err)
+	}
+
+	type hook struct {
+		Type    string `json:"type"`
+		Command string `json:"command"`
+	}
+	type matcher struct {
+		Matcher string `json:"matcher"`
+		Hooks   []hook `json:"hooks"`
+	}
+	settings := map[string]any{
+		"hooks": map[string][]matcher{
+			"PreToolUse": {{Matcher: "Bash", Hooks: []hook{{Type: "command", Command: quote(self) + " " + Command + " " + quote(file)}}}},
+		},
+	}
+	data, err = json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+	return []string{"--settings", string(data)}, nil
+}
+
+// quote single-quotes s for the shell the hook runs in.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Violation is why the policy blocks a command.
+type Violation struct {
+	Rule   string
+	Reason string
+}
+
+// Blocked is a blocked attempt, as logged.
+type Blocked struct {
+	Time     time.Time `json:"time"`
+	Session  string    `json:"session"`
+	Worktree string    `json:"worktree"`
+	Command  string    `json:"command"`
+	Rule     string    `json:"rule"`
+	Reason   string    `json:"reason"`
+}
+
+// Hook runs the PreToolUse hook for the policy in policyFile: it reads the
+// tool call from stdin and, when the policy blocks its command, logs the
+// attempt, explains why on stderr and returns exit status 2, which makes
+// Claude skip the call and read the explanation. Without a readable policy
+// or tool call every command is blocked.
+func Hook(policyFile string, stdin io.Reader, stderr io.Writer) int {
+	data, err := os.ReadFile(policyFile)
+	if err != nil {
+		fmt.Fprintf(stderr, "boatman command policy unavailable: %v\n", err)
+		return 2
+	}
+	var p Policy
+	if err := json.Unmarshal(data, &p); err != nil {
+		fmt.Fprintf(stderr, "boatman command policy unreadable: %v\n", err)
+		return 2
+	}
+	var call struct {
+		ToolName  string `json:"tool_name"`
+		ToolInput struct {
+			Command string `json:"command"`
+		} `json:"tool_input"`
+		Cwd string `json:"cwd"`
+	}
+	if err := json.NewDecoder(stdin).Decode(&call); err != nil {
+		fmt.Fprintf(stderr, "boatman command policy: unreadable tool call: %v\n", err)
+		return 2
+	}
+	if call.ToolName != "Bash" {
+		return 0
+	}
+
+	v := p.Check(call.ToolInput.Command, call.Cwd)
+	if v == nil {
+		return 0
+	}
+	if err := p.record(Blocked{Time: time.Now(), Session: p.Session, Worktree: p.Worktree, Command: call.ToolInput.Command, Rule: v.Rule, Reason: v.Reason}); err != nil {
+		fmt.Fprintf(stderr, "boatman command policy: failed to log the blocked command: %v\n", err)
+	}
+	fmt.Fprintf(stderr, "Blocked by boatman's command policy: %s. Do not try to work around it; do the task without this command or explain why it is needed.\n", v.Reason)
+	return 2
+}
+
+// record appends a blocked attempt to the log.
+func (p *Policy) record(b Blocked) error {
+	if p.Log == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(p.Log), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(p.Log, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	data, err := json.Marshal(b)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// BlockedSince returns the session's attempts blocked since t.
+func (p *Policy) BlockedSince(t time.Time) []Blocked {
+	f, err := os.Open(p.Log)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var blocked []Blocked
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var b Blocked
+		if json.Unmarshal(scanner.Bytes(), &b) != nil {
+			continue
+		}
+		if b.Session == p.Session && b.Worktree == p.Worktree && !b.Time.Before(t) {
+			blocked = append(blocked, b)
+		}
+	}
+	return blocked
+}
+
+var (
+	// fetchIntoShell matches a download run by a shell through command or
+	// process substitution, e.g. bash <(curl ...) or sh -c "$(wget ...)".
+	fetchIntoShell = regexp.MustCompile("(?:^|[\\s;&|(])(?:(?:ba|z|da|k)?sh|source|eval|\\.)\\s[^|;&]*?(?:\\$\\(|<\\(|`)\\s*(?:curl|wget)\\b")
+
+	// fetchers download code; shells run what they are piped.
+	fetchers = map[string]bool{"curl": true, "wget": true}
+	shells   = map[string]bool{"sh": true, "bash": true, "zsh": true, "dash": true, "ksh": true, "fish": true,
+		"python": true, "python3": true, "perl": true, "ruby": true, "node": true}
+
+	// wrappers run the command that follows them.
+	wrappers = map[string]bool{"sudo": true, "env": true, "command": true, "exec": true, "nohup": true, "time": true, "nice": true}
+
+	// proxyBypasses match commands that reach the network around the
+	// egress proxy: changing or unsetting its variables, clearing the
+	// environment, overriding the proxy of a tool, or opening sockets
+	// with the shell.
+	proxyBypasses = []struct {
+		re     *regexp.Regexp
+		reason string
+	}{
+		{regexp.MustCompile(`(?i)\b(?:https?|all|ftp|no)_proxy\b|\bhttps?\.proxy\b`), "the egress proxy settings may not be changed"},
+		{regexp.MustCompile(`(?:^|[\s;&|(])env\s+(?:-\S+\s+)*(?:-i|--ignore-environment|-)(?:\s|$)`), "the environment, and its egress proxy, may not be cleared"},
+		{regexp.MustCompile(`(?:^|\s)--(?:no-?proxy|proxy)(?:[=\s]|$)`), "the egress proxy may not be overridden"},
+		{regexp.MustCompile(`/dev/(?:tcp|udp)/`), "connections may not bypass the egress proxy"},
+	}
+)
+
+// Check returns why the policy blocks command, run in cwd (default the
+// worktree), or nil if it may run.
+func (p *Policy) Check(command, cwd string) *Violation {
+	if cwd == "" {
+		cwd = p.Worktree
+	}
+	for _, pattern := range p.Deny {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			continue
+		}
+		if re.MatchString(command) {
+			return &Violation{Rule: "deny", Reason: fmt.Sprintf("the command matches the denied pattern %q", pattern)}
+		}
+	}
+	if fetchIntoShell.MatchString(command) {
+		return &Violation{Rule: "pipe-to-shell", Reason: "downloaded code may not be run by a shell"}
+	}
+	if p.Proxied {
+		for _, bypass := range proxyBypasses {
+			if bypass.re.MatchString(command) {
+				return &Violation{Rule: "egress", Reason: bypass.reason}
+			}
+		}
+	}
+
+	for _, pipeline := range pipelines(command) {
+		fetched := false
+		for _, words := range pipeline {
+			words = unwrap(words)
+			if len(words) == 0 {
+				continue
+			}
+			name := filepath.Base(words[0])
+			if fetched && shells[name] {
+				return &Violation{Rule: "pipe-to-shell", Reason: fmt.Sprintf("downloaded code may not be piped into %s", name)}
+			}
+			fetched = fetched || fetchers[name]
+
+			var v *Violation
+			switch name {
+			case "rm":
+				v = p.checkRemove(words[1:], cwd)
+			case "git":
+				v = p.checkGit(words[1:], cwd)
+			case "cd":
+				target := "~"
+				if len(words) > 1 {
+					target = words[1]
+				}
+				cwd = p.resolve(target, cwd)
+			default:
+				if shells[name] {
+					if script := shellScript(words[1:]); script != "" {
+						v = p.Check(script, cwd)
+					}
+				}
+			}
+			if v != nil {
+				return v
+			}
+		}
+	}
+	return nil
+}
+
+// unwrap strips variable assignments and wrappers such as sudo and env
+// from the front of a command.
+func unwrap(words []string) []string {
+	for len(words) > 0 {
+		switch {
+		case strings.Contains(words[0], "=") && !strings.HasPrefix(words[0], "="):
+			words = words[1:]
+		case wrappers[filepath.Base(words[0])]:
+			words = words[1:]
+			for len(words) > 0 && strings.HasPrefix(words[0], "-") {
+				words = words[1:]
+			}
+		default:
+			return words
+		}
+	}
+	return words
+}
+
+// shellScript returns the script a shell is given with -c, if any.
+func shellScript(args []string) string {
+	for i, arg := range args {
+		if !strings.HasPrefix(arg, "-") || strings.HasPrefix(arg, "--") {
+			return ""
+		}
+		if strings.HasSuffix(arg, "c") && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// checkRemove blocks recursive removal of anything outside the worktree,
+// or of the worktree itself.
+func (p *Policy) checkRemove(args []string, cwd string) *Violation {
+	recursive, flags := false, true
+	var targets []string
+	for _, arg := range args {
+		switch {
+		case flags && arg == "--":
+			flags = false
+		case flags && arg == "--recursive":
+			recursive = true
+		case flags && strings.HasPrefix(arg, "--"):
+		case flags && strings.HasPrefix(arg, "-") && len(arg) > 1:
+			recursive = recursive || strings.ContainsAny(arg, "rR")
+		default:
+			targets = append(targets, arg)
+		}
+	}
+	if !recursive {
+		return nil
+	}
+	for _, target := range targets {
+		resolved := p.resolve(target, cwd)
+		if resolved == "" || !p.inWorktree(resolved) {
+			return &Violation{Rule: "rm-outside-worktree", Reason: fmt.Sprintf("rm -r of %s is outside the worktree %s", target, p.Worktree)}
+		}
+	}
+	return nil
+}
+
+// resolve returns the absolute path target names from cwd, or "" when it
+// depends on a command substitution or another user's home.
+func (p *Policy) resolve(target, cwd string) string {
+	if strings.Contains(target, "$(") || strings.Contains(target, "`") {
+		return ""
+	}
+	target = os.Expand(target, os.Getenv)
+	if target == "~" || strings.HasPrefix(target, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		target = home + target[1:]
+	} else if strings.HasPrefix(target, "~") {
+		return ""
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(cwd, target)
+	}
+	return filepath.Clean(target)
+}
+
+// inWorktree reports whether path is inside the worktree, not the
+// worktree itself.
+func (p *Policy) inWorktree(path string) bool {
+	roots := []string{p.Worktree}
+	if real, err := filepath.EvalSymlinks(p.Worktree); err == nil && real != p.Worktree {
+		roots = append(roots, real)
+	}
+	for _, root := range roots {
+		rel, err := filepath.Rel(root, path)
+		if err == nil && rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkGit blocks force pushes to, and deletes of, protected branches.
+func (p *Policy) checkGit(args []string, cwd string) *Violation {
+	// Global options come before the subcommand
+	for len(args) > 0 && strings.HasPrefix(args[0], "-") {
+		switch args[0] {
+		case "-C":
+			if len(args) > 1 {
+				cwd = p.resolve(args[1], cwd)
+				args = args[1:]
+			}
+		case "-c", "--git-dir", "--work-tree", "--namespace":
+			args = args[1:]
+		}
+		args = args[1:]
+	}
+	if len(args) == 0 || args[0] != "push" {
+		return nil
+	}
+
+	force, remove, all := false, false, false
+	var positional []string
+	rest := args[1:]
+	for i := 0; i < len(rest); i++ {
+		arg := rest[i]
+		switch {
+		case arg == "--force" || strings.HasPrefix(arg, "--force-with-lease"):
+			force = true
+		case arg == "--delete":
+			remove = true
+		case arg == "--all" || arg == "--mirror" || arg == "--branches":
+			all = true
+		case arg == "-o" || arg == "--push-option" || arg == "--repo" || arg == "--receive-pack" || arg == "--exec":
+			i++
+		case strings.HasPrefix(arg, "--"):
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			force = force || strings.Contains(arg, "f")
+			remove = remove || strings.Contains(arg, "d")
+		default:
+			positional = append(positional, arg)
+		}
+	}
+
+	var refspecs []string
+	if len(positional) > 1 {
+		refspecs = positional[1:]
+	}
+	if all && (force || remove) {
+		return &Violation{Rule: "force-push", Reason: "force pushing every branch may overwrite protected ones"}
+	}
+	if len(refspecs) == 0 {
+		if !force {
+			return nil
+		}
+		branch := currentBranch(cwd)
+		if branch == "" {
+			return &Violation{Rule: "force-push", Reason: "force push of a branch that cannot be determined"}
+		}
+		refspecs = []string{branch}
+	}
+	for _, refspec := range refspecs {
+		plus := strings.HasPrefix(refspec, "+")
+		src, dst, mapped := strings.Cut(strings.TrimPrefix(refspec, "+"), ":")
+		if !mapped {
+			dst = src
+		}
+		dst = strings.TrimPrefix(dst, "refs/heads/")
+		if dst == "HEAD" {
+			dst = currentBranch(cwd)
+		}
+		if !p.protected(dst) {
+			continue
+		}
+		switch {
+		case remove || (mapped && src == ""):
+			return &Violation{Rule: "delete-protected-branch", Reason: fmt.Sprintf("%s is a protected branch and may not be deleted", dst)}
+		case force || plus:
+			return &Violation{Rule: "force-push", Reason: fmt.Sprintf("%s is a protected branch and may not be force-pushed", dst)}
+		}
+	}
+	return nil
+}
+
+// protected reports whether branch is a protected branch.
+func (p *Policy) protected(branch string) bool {
+	for _, pattern := range p.ProtectedBranches {
+		if ok, _ := path.Match(pattern, branch); ok || pattern == branch {
+			return true
+		}
+	}
+	return false
+}
+
+// branchTimeout bounds the git call naming the current branch, which runs
+// within a PreToolUse hook call.
+const branchTimeout = 5 * time.Second
+
+// currentBranch returns the branch checked out in dir, or "" when HEAD
+// is detached.
+func currentBranch(dir string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), branchTimeout)
+	defer cancel()
+	out, err := gitops.New(dir).WithContext(ctx).Run("symbolic-ref", "--short", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(out)
+}
+
+// pipelines splits a shell command line into pipelines of simple commands,
+// each a list of words with quotes removed. Command and process
+// substitutions and ${...} are kept whole inside their word; redirections
+// are dropped.
+func pipelines(command string) [][][]string {
+	var (
+		result   [][][]string
+		pipeline [][]string
+		words    []string
+		word     strings.Builder
+		inWord   bool
+		// redirected drops the next word, a redirection's target
+		redirected bool
+	)
+	endWord := func() {
+		if inWord {
+			if !redirected {
+				words = append(words, word.String())
+			}
+			redirected = false
+			word.Reset()
+			inWord = false
+		}
+	}
+	endCommand := func() {
+		endWord()
+		if len(words) > 0 {
+			pipeline = append(pipeline, words)
+			words = nil
+		}
+	}
+	endPipeline := func() {
+		endCommand()
+		if len(pipeline) > 0 {
+			result = append(result, pipeline)
+			pipeline = nil
+		}
+	}
+
+	runes := []rune(command)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == '\\' && i+1 < len(runes):
+			i++
+			if runes[i] != '\n' {
+				word.WriteRune(runes[i])
+				inWord = true
+			}
+		case r == '\'':
+			inWord = true
+			for i++; i < len(runes) && runes[i] != '\''; i++ {
+				word.WriteRune(runes[i])
+			}
+		case r == '"':
+			inWord = true
+			for i++; i < len(runes) && runes[i] != '"'; i++ {
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune(`"\$`+"`", runes[i+1]) {
+					i++
+				}
+				word.WriteRune(runes[i])
+			}
+		case (r == '$' || r == '<') && i+1 < len(runes) && runes[i+1] == '(':
+			// Keep the substitution in the word, up to its closing paren
+			inWord = true
+			depth := 0
+			for ; i < len(runes); i++ {
+				word.WriteRune(runes[i])
+				if runes[i] == '(' {
+					depth++
+				} else if runes[i] == ')' {
+					if depth--; depth == 0 {
+						break
+					}
+				}
+			}
+		case r == '$' && i+1 < len(runes) && runes[i+1] == '{':
+			inWord = true
+			for ; i < len(runes) && runes[i] != '}'; i++ {
+				word.WriteRune(runes[i])
+			}
+			word.WriteRune('}')
+		case r == '>' || r == '<' || (r == '&' && i+1 < len(runes) && runes[i+1] == '>'):
+			// A file descriptor number before the operator is part of it
+			if inWord && strings.Trim(word.String(), "0123456789") == "" {
+				word.Reset()
+				inWord = false
+			}
+			endWord()
+			for i+1 < len(runes) && strings.ContainsRune("<>&|", runes[i+1]) {
+				i++
+			}
+			redirected = true
+		case r == '`':
+			inWord = true
+			word.WriteRune(r)
+			for i++; i < len(runes) && runes[i] != '`'; i++ {
+				word.WriteRune(runes[i])
+			}
+			word.WriteRune('`')
+		case r == '|' && i+1 < len(runes) && runes[i+1] == '|':
+			i++
+			endPipeline()
+		case r == '|':
+			if i+1 < len(runes) && runes[i+1] == '&' {
+				i++
+			}
+			endCommand()
+		case r == ';' || r == '&' || r == '\n':
+			endPipeline()
+		case r == '(' || r == ')' || r == '{' || r == '}':
+			endCommand()
+		case r == ' ' || r == '\t':
+			endWord()
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+	endPipeline()
+	return result
+}
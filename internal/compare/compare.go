@@ -0,0 +1,237 @@
+// Package compare diffs two runs, usually of the same ticket under
+// different models or settings: their plans, iterations, review issues,
+// cost and final changes.
+package compare
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/chat"
+	"github.com/philjestin/boatmanmode/internal/gitops"
+	"github.com/philjestin/boatmanmode/internal/unidiff"
+)
+
+// Run summarizes one side of a comparison.
+type Run struct {
+	ID           string
+	TicketID     string
+	Settings     map[string]string
+	PlanSummary  string
+	PlanFiles    []string
+	PlanSteps    int
+	Iterations   int
+	Reviews      int
+	Passed       bool
+	Score        int
+	CostUSD      float64
+	Issues       []string // Distinct issues raised across all reviews
+	FilesChanged []string
+	DiffLines    int
+	Error        string
+}
+
+// NewRun summarizes a run. diff is its final diff, or "" if unavailable.
+func NewRun(rc *chat.RunContext, diff string) Run {
+	cp := rc.Checkpoint
+	r := Run{
+		ID:           cp.ID,
+		TicketID:     cp.TicketID,
+		Settings:     cp.Settings,
+		Iterations:   cp.Iteration,
+		Reviews:      len(rc.Reviews),
+		CostUSD:      cp.CostUSD,
+		FilesChanged: rc.FilesChanged(),
+		DiffLines:    unidiff.Changed(unidiff.Parse(diff)),
+		Error:        cp.Error,
+	}
+	if rc.Plan != nil {
+		r.PlanSummary = rc.Plan.Summary
+		r.PlanFiles = rc.Plan.RelevantFiles
+		r.PlanSteps = len(rc.Plan.Approach)
+	}
+	if rc.Review != nil {
+		r.Passed = rc.Review.Passed
+		r.Score = rc.Review.Score
+	}
+	for _, review := range rc.Reviews {
+		for _, issue := range review.Issues {
+			desc := fmt.Sprintf("[%s] %s", issue.Severity, issue.Description)
+			if !slices.Contains(r.Issues, desc) {
+				r.Issues = append(r.Issues, desc)
+			}
+		}
+	}
+	return r
+}
+
+// Comparison is the difference between two runs.
+type Comparison struct {
+	A, B Run
+}
+
+// Format returns a side-by-side report of the two runs followed by what
+// only one of them planned, raised or changed.
+func (c *Comparison) Format() string {
+	var sb strings.Builder
+	a, b := c.A, c.B
+	sb.WriteString(fmt.Sprintf("⚖️  Comparing A: %s\n              B: %s\n", a.ID, b.ID))
+	if a.TicketID != b.TicketID {
+		sb.WriteString(fmt.Sprintf("   ⚠️  Different tickets (%s vs %s)\n", a.TicketID, b.TicketID))
+	}
+
+	sb.WriteString("\n")
+	row := func(label, va, vb string) {
+		marker := " "
+		if va != vb {
+			marker = "≠"
+		}
+		sb.WriteString(fmt.Sprintf("   %s %-16s %-24s %s\n", marker, label, va, vb))
+	}
+	row("", "A", "B")
+	for _, key := range settingKeys(a.Settings, b.Settings) {
+		row(key, setting(a.Settings, key), setting(b.Settings, key))
+	}
+	row("outcome", outcome(a), outcome(b))
+	row("score", fmt.Sprint(a.Score), fmt.Sprint(b.Score))
+	row("iterations", fmt.Sprint(a.Iterations), fmt.Sprint(b.Iterations))
+	row("reviews", fmt.Sprint(a.Reviews), fmt.Sprint(b.Reviews))
+	row("issues raised", fmt.Sprint(len(a.Issues)), fmt.Sprint(len(b.Issues)))
+	row("cost", fmt.Sprintf("$%.2f", a.CostUSD), fmt.Sprintf("$%.2f", b.CostUSD))
+	row("plan steps", fmt.Sprint(a.PlanSteps), fmt.Sprint(b.PlanSteps))
+	row("plan files", fmt.Sprint(len(a.PlanFiles)), fmt.Sprint(len(b.PlanFiles)))
+	row("files changed", fmt.Sprint(len(a.FilesChanged)), fmt.Sprint(len(b.FilesChanged)))
+	row("diff lines", fmt.Sprint(a.DiffLines), fmt.Sprint(b.DiffLines))
+
+	if a.PlanSummary != b.PlanSummary {
+		sb.WriteString(fmt.Sprintf("\n   Plan A: %s\n   Plan B: %s\n", a.PlanSummary, b.PlanSummary))
+	}
+	writeOnly(&sb, "Planned files", a.PlanFiles, b.PlanFiles)
+	writeOnly(&sb, "Issues raised", a.Issues, b.Issues)
+	writeOnly(&sb, "Files changed", a.FilesChanged, b.FilesChanged)
+	return sb.String()
+}
+
+// writeOnly lists the items only one side has.
+func writeOnly(sb *strings.Builder, title string, a, b []string) {
+	onlyA, onlyB := difference(a, b), difference(b, a)
+	if len(onlyA) == 0 && len(onlyB) == 0 {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("\n   %s:\n", title))
+	for _, item := range onlyA {
+		sb.WriteString(fmt.Sprintf("      A only: %s\n", item))
+	}
+	for _, item := range onlyB {
+		sb.WriteString(fmt.Sprintf("      B only: %s\n", item))
+	}
+}
+
+func difference(a, b []string) []string {
+	var out []string
+	for _, item := range a {
+		if !slices.Contains(b, item) {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func settingKeys(a, b map[string]string) []string {
+	var keys []string
+	for _, m := range []map[string]string{a, b} {
+		for k := range m {
+			if !slices.Contains(keys, k) {
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func setting(settings map[string]string, key string) string {
+	if v, ok := settings[key]; ok {
+		return v
+	}
+	return "-"
+}
+
+func outcome(r Run) string {
+	switch {
+	case r.Error != "":
+		return "error"
+	case r.Passed:
+		return "passed"
+	case r.Reviews == 0:
+		return "no review"
+	}
+	return "failed"
+}
+
+// Snapshot returns a git object naming the run's final code: its
+// worktree's index tree when the worktree still exists, otherwise its
+// branch in repo. Worktrees share the repository's object store, so
+// snapshots of two runs can be diffed from either.
+func Snapshot(rc *chat.RunContext, repo *gitops.Repo) (string, error) {
+	if hasWorktree(rc) {
+		return gitops.New(rc.Checkpoint.WorktreePath).WriteTree()
+	}
+	return branch(rc, repo)
+}
+
+// FinalDiff returns the run's changes against baseBranch, from its
+// worktree when it still exists, otherwise from its branch in repo.
+func FinalDiff(rc *chat.RunContext, repo *gitops.Repo, baseBranch string) (string, error) {
+	if hasWorktree(rc) {
+		return rc.Diff(baseBranch)
+	}
+	ref, err := branch(rc, repo)
+	if err != nil {
+		return "", err
+	}
+	base := baseBranch
+	if repo.RefExists("refs/remotes/origin/" + base) {
+		base = "origin/" + base
+	}
+	diff, err := repo.Diff(base + "..." + ref)
+	if err != nil {
+		return "", err
+	}
+	return gitops.FilterLFSDiff(diff), nil
+}
+
+func hasWorktree(rc *chat.RunContext) bool {
+	if rc.Checkpoint.WorktreePath == "" {
+		return false
+	}
+	_, err := os.Stat(rc.Checkpoint.WorktreePath)
+	return err == nil
+}
+
+func branch(rc *chat.RunContext, repo *gitops.Repo) (string, error) {
+	if b := rc.Checkpoint.BranchName; b != "" && repo.RefExists("refs/heads/"+b) {
+		return b, nil
+	}
+	return "", fmt.Errorf("run %s has neither a worktree nor a branch to compare", rc.Checkpoint.ID)
+}
+
+// CodeDiff returns the diff from run A's final code to run B's.
+func CodeDiff(a, b *chat.RunContext, repo *gitops.Repo) (string, error) {
+	treeA, err := Snapshot(a, repo)
+	if err != nil {
+		return "", err
+	}
+	treeB, err := Snapshot(b, repo)
+	if err != nil {
+		return "", err
+	}
+	diff, err := repo.Diff(treeA, treeB)
+	if err != nil {
+		return "", err
+	}
+	return gitops.FilterLFSDiff(diff), nil
+}
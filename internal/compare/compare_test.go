@@ -0,0 +1,163 @@
+package compare
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/agent"
+	"github.com/philjestin/boatmanmode/internal/chat"
+	"github.com/philjestin/boatmanmode/internal/checkpoint"
+	"github.com/philjestin/boatmanmode/internal/gitops"
+	"github.com/philjestin/boatmanmode/internal/planner"
+	"github.com/philjestin/boatmanmode/internal/scottbott"
+)
+
+func git(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	if out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+type runSpec struct {
+	model    string
+	worktree string
+	branch   string
+	plan     *planner.Plan
+	files    []string
+	reviews  []*scottbott.ReviewResult
+	cost     float64
+}
+
+// recordRun saves a finished run's checkpoint like the agent does.
+func recordRun(t *testing.T, mgr *checkpoint.Manager, spec runSpec) *chat.RunContext {
+	t.Helper()
+	cp := mgr.Start("ENG-42", 3)
+	mgr.SetSettings(map[string]string{"model.executor": spec.model, "max_iterations": "3"})
+	mgr.SetWorktree(spec.worktree, spec.branch)
+	mgr.SetIteration(len(spec.reviews) - 1)
+	mgr.SetCost(spec.cost)
+	final := spec.reviews[len(spec.reviews)-1]
+	for _, s := range []struct {
+		step   checkpoint.Step
+		output interface{}
+	}{
+		{checkpoint.StepFetchTicket, agent.TaskRecord{ID: "ENG-42", Title: "Add retries"}},
+		{checkpoint.StepPlanning, spec.plan},
+		{checkpoint.StepExecution, agent.ExecutionRecord{FilesChanged: spec.files}},
+		{checkpoint.StepTesting, spec.reviews[0]},
+		{checkpoint.StepReview, agent.ReviewRecord{ReviewResult: final, History: spec.reviews}},
+	} {
+		mgr.BeginStep(s.step)
+		mgr.CompleteStep(s.step, s.output)
+	}
+	mgr.Finish()
+
+	resumed, err := mgr.Resume(cp.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resumed.ID = spec.model // IDs are per-second; keep them distinct
+	rc, err := chat.LoadRunContext(resumed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rc
+}
+
+func TestCompareRuns(t *testing.T) {
+	mgr, _ := checkpoint.NewManager(t.TempDir())
+	a := recordRun(t, mgr, runSpec{
+		model: "sonnet",
+		plan:  &planner.Plan{Summary: "Wrap uploads in retry.Do", RelevantFiles: []string{"upload.go"}, Approach: []string{"a"}},
+		files: []string{"upload.go"},
+		reviews: []*scottbott.ReviewResult{
+			{Score: 50, Issues: []scottbott.Issue{{Severity: "major", Description: "No backoff"}}},
+			{Score: 70, Issues: []scottbott.Issue{{Severity: "major", Description: "No backoff"}, {Severity: "minor", Description: "Magic number"}}},
+			{Score: 90, Passed: true},
+		},
+		cost: 1.5,
+	})
+	b := recordRun(t, mgr, runSpec{
+		model:   "opus",
+		plan:    &planner.Plan{Summary: "Add a retrying client", RelevantFiles: []string{"upload.go", "client.go"}, Approach: []string{"a"}},
+		files:   []string{"upload.go", "client.go"},
+		reviews: []*scottbott.ReviewResult{{Score: 85, Passed: true}},
+		cost:    2.25,
+	})
+
+	runA := NewRun(a, "+++ b/upload.go\n+retry\n-old\n")
+	if runA.Reviews != 3 || runA.Iterations != 2 || len(runA.Issues) != 2 || runA.DiffLines != 2 {
+		t.Errorf("Unexpected summary of run A: %+v", runA)
+	}
+
+	out := (&Comparison{A: runA, B: NewRun(b, "")}).Format()
+	for _, want := range []string{
+		"≠ model.executor   sonnet",
+		"  max_iterations   3",
+		"≠ cost             $1.50",
+		"  outcome          passed",
+		"Plan B: Add a retrying client",
+		"B only: client.go",
+		"A only: [minor] Magic number",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in comparison:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Different tickets") {
+		t.Errorf("Expected no ticket warning for runs of one ticket:\n%s", out)
+	}
+}
+
+func TestCodeDiff(t *testing.T) {
+	repoDir := t.TempDir()
+	git(t, repoDir, "init", "-q", "-b", "main")
+	git(t, repoDir, "config", "user.email", "test@example.com")
+	git(t, repoDir, "config", "user.name", "Test")
+	os.WriteFile(filepath.Join(repoDir, "upload.go"), []byte("package upload\n"), 0644)
+	git(t, repoDir, "add", "-A")
+	git(t, repoDir, "commit", "-qm", "initial")
+
+	// Run A's worktree still exists with staged changes; run B only
+	// left a branch behind
+	wtA := filepath.Join(t.TempDir(), "a")
+	git(t, repoDir, "worktree", "add", "-q", "-b", "eng-42-a", wtA)
+	os.WriteFile(filepath.Join(wtA, "upload.go"), []byte("package upload\n\nconst retries = 3\n"), 0644)
+	git(t, wtA, "add", "-A")
+
+	git(t, repoDir, "checkout", "-q", "-b", "eng-42-b")
+	os.WriteFile(filepath.Join(repoDir, "upload.go"), []byte("package upload\n\nconst retries = 5\n"), 0644)
+	git(t, repoDir, "commit", "-qam", "b")
+	git(t, repoDir, "checkout", "-q", "main")
+
+	mgr, _ := checkpoint.NewManager(t.TempDir())
+	reviews := []*scottbott.ReviewResult{{Passed: true}}
+	a := recordRun(t, mgr, runSpec{model: "a", worktree: wtA, branch: "eng-42-a", reviews: reviews})
+	b := recordRun(t, mgr, runSpec{model: "b", worktree: filepath.Join(t.TempDir(), "removed"), branch: "eng-42-b", reviews: reviews})
+
+	repo := gitops.New(wtA)
+	diff, err := CodeDiff(a, b, repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(diff, "-const retries = 3") || !strings.Contains(diff, "+const retries = 5") {
+		t.Errorf("Expected A's change replaced by B's:\n%s", diff)
+	}
+
+	finalB, err := FinalDiff(b, repo, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(finalB, "+const retries = 5") {
+		t.Errorf("Expected B's branch diff against main:\n%s", finalB)
+	}
+
+	gone := recordRun(t, mgr, runSpec{model: "c", branch: "deleted", reviews: reviews})
+	if _, err := CodeDiff(a, gone, repo); err == nil {
+		t.Error("Expected an error for a run with no worktree or branch")
+	}
+}
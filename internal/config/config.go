@@ -3,9 +3,15 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"os"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/philjestin/boatmanmode/internal/pipeline"
+	"github.com/philjestin/boatmanmode/internal/secrets"
 	"github.com/spf13/viper"
 )
 
@@ -14,12 +20,88 @@ type Config struct {
 	// Linear API
 	LinearKey string
 
+	// Source is where ticket IDs are fetched from: "linear" (default),
+	// "jira" or "azure".
+	Source string
+
+	// Jira API, for the jira source
+	Jira JiraConfig
+
+	// Azure DevOps, for the azure source and Azure Repos PRs
+	Azure AzureConfig
+
+	// GitHub API, for opening PRs without the gh CLI
+	GitHub GitHubConfig
+
+	// Bitbucket Cloud, for repos hosted on bitbucket.org
+	Bitbucket BitbucketConfig
+
+	// Sentry API, for the error context of Sentry issues linked in bugs
+	Sentry SentryConfig
+
+	// Specs are the credentials for the spec docs linked in tickets
+	Specs SpecsConfig
+
+	// Ingest controls the screenshots and web pages read from tickets
+	Ingest IngestConfig
+
+	// E2E covers acceptance criteria with Playwright or Cypress specs
+	E2E E2EConfig
+
 	// Workflow settings
 	MaxIterations int
 	BaseBranch    string
 	AutoPR        bool
 	ReviewSkill   string
 
+	// MaxTestFixAttempts is how many times tests that fail after execution
+	// are handed back to Claude to fix before review (0 = review them as
+	// they are).
+	MaxTestFixAttempts int
+
+	// MinCoverage (0-100) is the test coverage each changed file must
+	// keep, measured with the full test suite before and after the
+	// change. 0 disables the gate.
+	MinCoverage float64
+
+	// Pair pauses after execution and each refactor so the operator can
+	// edit the worktree by hand before review continues.
+	Pair bool
+
+	// ReviewPlan stops after planning for the operator to approve or edit
+	// the plan before execution. Without a terminal the run pauses until
+	// boatman resume --approve-plan.
+	ReviewPlan bool
+
+	// RequireApproval stops the run for the operator's approval before it
+	// commits, pushes or opens the PR: "commit", "push" or "pr" ("" runs
+	// unattended). Without a terminal the run pauses until boatman approve.
+	RequireApproval string
+
+	// Retro runs a post-run analysis that distills lessons from the run's
+	// reviews and test results into project memory.
+	Retro bool
+
+	// DryRun stops after review, before the commit, push and PR, leaving
+	// the change in the worktree.
+	DryRun bool
+
+	// Pipeline is the stages runs go through, in order: plan, execute,
+	// lint, test, review, refactor, commit and pr. Empty runs every stage
+	// but lint.
+	Pipeline []string
+
+	// Hooks are shell commands run before or after workflow steps
+	Hooks []HookConfig
+
+	// MinSuccessLikelihood (0-100) declines tasks whose predicted chance
+	// of success without human help is lower, unless forced. 0 disables.
+	MinSuccessLikelihood int
+
+	// MaxCostUSD stops a run, checkpointed, before its next Claude step
+	// once it has spent this many dollars. 0 means no limit.
+	MaxCostUSD float64
+
 	// Review pass criteria
 	Review ReviewConfig
 
@@ -35,11 +117,60 @@ type Config struct {
 	// Token budgets
 	TokenBudget TokenBudgetConfig
 
+	// Git settings
+	Git GitConfig
+
+	// Worktree settings
+	Worktree WorktreeConfig
+
+	// Branch naming
+	Branch BranchConfig
+
+	// Pull request settings
+	PR PRConfig
+
+	// Gerrit settings, for repos that review changes in Gerrit
+	Gerrit GerritConfig
+
+	// Feature flag system of the repo
+	FeatureFlags FeatureFlagConfig
+
+	// Frontend bundle size budgets
+	Bundle BundleConfig
+
+	// Telemetry settings (opt-in)
+	Telemetry TelemetryConfig
+
+	// Webhook notifications of run lifecycle events
+	Notify NotifyConfig
+
+	// Project commands (see `boatman onboard`)
+	Commands CommandsConfig
+
+	// Daemon settings
+	Daemon DaemonConfig
+
+	// Serve configures the webhook server of boatman serve
+	Serve ServeConfig
+
+	// ProtectedPaths are CODEOWNERS-style patterns whose changes are
+	// flagged for human attention.
+	ProtectedPaths []string
+
+	// Network restricts the hosts agent sessions may reach
+	Network NetworkConfig
+
+	// Verify configures the check that refactors fixed the review's issues
+	Verify VerifyConfig
+
 	// Debug enables verbose logging
 	Debug bool
 
 	// EnableTools enables Claude CLI tool capabilities for agents
 	EnableTools bool
+
+	// secretErr is why a credential's secret reference didn't resolve
+	secretErr error
 }
 
 // ReviewConfig holds review pass criteria settings.
@@ -53,8 +184,865 @@ type ReviewConfig struct {
 	// MinVerificationConfidence is the minimum confidence percentage (0-100) for diff verification.
 	MinVerificationConfidence int
 
-	// StrictParsing enables strict keyword matching in natural language review parsing.
-	StrictParsing bool
+	// StrictParsing enables strict keyword matching in natural language review parsing.
+	StrictParsing bool
+
+	// StrictOutput has the reviewer answer with JSON conforming to the
+	// published review schema. Responses that don't conform are sent back
+	// with their violations, up to OutputRetries times, before falling back
+	// to lenient parsing.
+	StrictOutput bool
+
+	// OutputRetries is how many times StrictOutput re-prompts the reviewer
+	// (default 2).
+	OutputRetries int
+
+	// Differential sends follow-up reviews only the changes since the previous
+	// review plus the previous issue list, instead of the cumulative diff.
+	Differential bool
+
+	// NewIssuePatterns are repo-specific patterns flagged in newly added lines
+	// during diff verification. Empty uses the built-in defaults.
+	NewIssuePatterns []IssuePattern
+
+	// Rubric customizes how the reviewer scores changes for this repo.
+	Rubric RubricConfig
+
+	// Timeout bounds each reviewer attempt (0 = no timeout). When an attempt
+	// times out or errors, review falls back to the next option in the chain.
+	Timeout time.Duration
+
+	// FallbackModel is used for the system-prompt fallback review.
+	// Empty uses the preflight model, which is typically the cheapest.
+	FallbackModel string
+
+	// Skills holds extra CLI arguments and environment per review skill,
+	// keyed by skill name.
+	Skills map[string]SkillConfig
+
+	// Ensemble runs several reviewer personas in parallel and merges their
+	// results; pass/fail is decided by the weighted severity of the merged
+	// issues. No reviewers means a single review with ReviewSkill.
+	Ensemble EnsembleConfig
+
+	// Observability requires new endpoints and jobs to be instrumented.
+	Observability ObservabilityConfig
+
+	// RaceCheck runs changed Go packages' tests under the race detector
+	// and scans the diff for racy patterns; findings are critical issues.
+	RaceCheck bool
+
+	// SQL reviews the queries the diff adds or changes.
+	SQL SQLReviewConfig
+
+	// Snapshots limits when the agent may update snapshot tests.
+	Snapshots SnapshotConfig
+
+	// Migrations dry-runs new migrations against an ephemeral database.
+	Migrations MigrationConfig
+
+	// Contracts verifies the provider against its consumers' pacts when
+	// the change touches API handlers.
+	Contracts ContractConfig
+
+	// GraphQL classifies changes to GraphQL schema files and blocks breaking
+	// ones.
+	GraphQL GraphQLConfig
+
+	// Security scans the diff for hard-coded secrets and dangerous code;
+	// critical findings block the commit.
+	Security SecurityConfig
+
+	// AcceptanceCriteria has the reviewer verify each criterion listed under
+	// the ticket's "Acceptance Criteria" heading; review cannot pass while a
+	// required one is unmet.
+	AcceptanceCriteria bool
+}
+
+// VerifyConfig configures the diff verification after each refactor.
+type VerifyConfig struct {
+	// UseLLM has the verifier model judge each issue against the new diff,
+	// with a confidence per verdict, instead of keyword heuristics. The
+	// heuristics remain the fallback when the model fails (default false).
+	UseLLM bool `mapstructure:"use_llm"`
+}
+
+// MigrationConfig configures dry runs of the migrations a change adds.
+type MigrationConfig struct {
+	// Up applies all pending migrations, e.g. "bin/rails db:migrate". It
+	// runs in the worktree with the database URL in DatabaseEnv. Empty
+	// disables dry runs.
+	Up string
+
+	// Down rolls back the new migrations; {count} is replaced with how many
+	// the change adds, e.g. "bin/rails db:rollback STEP={count}".
+	Down string
+
+	// Paths match migration files (CODEOWNERS-style). Empty uses
+	// migrations/ and db/migrate/.
+	Paths []string
+
+	// Image is the Postgres docker image for the ephemeral database
+	// (default postgres:16-alpine).
+	Image string
+
+	// DatabaseEnv is the environment variable the database URL is passed
+	// in (default DATABASE_URL).
+	DatabaseEnv string `mapstructure:"database_env"`
+}
+
+// ContractConfig configures Pact provider verification.
+type ContractConfig struct {
+	// Verify runs provider verification against the consumers' pacts, e.g.
+	// "npm run test:pact" or "bundle exec rake pact:verify". It runs in the
+	// worktree. Empty disables verification.
+	Verify string
+
+	// Handlers match the files that serve the provider's API
+	// (CODEOWNERS-style). Empty uses controllers/, handlers/, routes/ and
+	// api/ at any depth.
+	Handlers []string
+}
+
+// GraphQLConfig configures GraphQL schema change gating.
+type GraphQLConfig struct {
+	// Enabled diffs the schema files a change modifies with graphql-inspector
+	// (default false). Breaking changes are critical issues.
+	Enabled bool
+
+	// Inspector is the graphql-inspector command (default
+	// "npx --yes @graphql-inspector/cli").
+	Inspector string
+
+	// Paths match schema files (CODEOWNERS-style). Empty uses *.graphql and
+	// *.graphqls.
+	Paths []string
+
+	// AllowLabel is the ticket label that allows breaking changes (default
+	// "breaking-change").
+	AllowLabel string
+}
+
+// SecurityConfig configures the security scan of the lines a change adds.
+type SecurityConfig struct {
+	// Enabled scans for hard-coded secrets, by pattern and entropy, and for
+	// eval and SQL built from strings (default true).
+	Enabled bool
+
+	// Tools are the scanners also run when installed: gitleaks for secrets
+	// and trivy for vulnerable dependencies (default both).
+	Tools []string
+
+	// Ignore are path patterns (CODEOWNERS-style) not scanned, e.g. test
+	// fixtures with fake keys.
+	Ignore []string
+}
+
+// SnapshotConfig is the policy for updating snapshot tests.
+type SnapshotConfig struct {
+	// MaxLines is the most lines a snapshot update may change; larger
+	// updates must be fixed in the code instead. 0 disables the policy.
+	MaxLines int `mapstructure:"max_lines"`
+
+	// Patterns match snapshot files (CODEOWNERS-style). Empty uses
+	// __snapshots__/, *.snap, *.ambr and *.golden.
+	Patterns []string
+}
+
+// SQLReviewConfig configures review of added and changed SQL queries.
+type SQLReviewConfig struct {
+	// DatabaseURL of a dev database to EXPLAIN changed queries against with
+	// psql; $VARS are expanded. Queries are planned, never run.
+	DatabaseURL string `mapstructure:"database_url"`
+
+	// Analyzer is a static analyzer command run in the worktree when the
+	// diff touches SQL, e.g. "sqlc vet".
+	Analyzer string
+
+	// MinRows is the estimated row count from which a full table scan is
+	// reported (default 1000).
+	MinRows int `mapstructure:"min_rows"`
+
+	// Severity of the review issues raised (default "major").
+	Severity string
+}
+
+// ObservabilityConfig is the policy for instrumenting new endpoints and jobs.
+type ObservabilityConfig struct {
+	// Require lists the instrumentation new entry points need: logging,
+	// metrics, tracing, or custom kinds with a signal. Empty disables it.
+	Require []string
+
+	// EntryPoints are regexes matching added lines that define an endpoint
+	// or job. Empty uses built-in patterns for Go, Ruby, JS/TS and Python.
+	EntryPoints []string `mapstructure:"entry_points"`
+
+	// Signals maps a kind to a regex matching added lines that provide it,
+	// replacing the built-in one.
+	Signals map[string]string
+
+	// Severity of the review issues raised (default "major").
+	Severity string
+}
+
+// SkillConfig holds invocation options for a review skill.
+type SkillConfig struct {
+	// Args are extra arguments appended to the claude CLI invocation.
+	Args []string
+
+	// Env are KEY=VALUE entries added to the skill's environment.
+	// Values may reference other variables, e.g. RULESET_TOKEN=${MY_TOKEN}.
+	Env []string
+}
+
+// EnsembleConfig configures a multi-reviewer ensemble.
+type EnsembleConfig struct {
+	// Reviewers are the personas run on every review.
+	Reviewers []ReviewerConfig
+
+	// SeverityWeights maps an issue severity to the points it adds to the
+	// review's weighted score (default critical 10, major 3, minor 1).
+	SeverityWeights map[string]float64 `mapstructure:"severity_weights"`
+
+	// Threshold is the weighted score at which review fails (default 10:
+	// one critical issue, or four major ones, from a reviewer of weight 1).
+	Threshold float64
+}
+
+// ReviewerConfig is one persona of a review ensemble.
+type ReviewerConfig struct {
+	// Name identifies the persona, e.g. security, performance or style.
+	Name string
+
+	// Skill is the review skill the persona runs (default ReviewSkill).
+	Skill string
+
+	// Focus is added to the persona's prompt, e.g. "Look only for
+	// performance problems: N+1 queries, allocations in hot loops".
+	Focus string
+
+	// Weight scales the points of the persona's issues (default 1).
+	Weight float64
+}
+
+// RubricConfig defines a repo-specific review rubric.
+type RubricConfig struct {
+	// Weights maps a category (correctness, tests, security, style) to its
+	// relative weight in the overall score. Empty means no rubric.
+	Weights map[string]int
+
+	// MustCheck are items the reviewer must explicitly verify.
+	MustCheck []string `mapstructure:"must_check"`
+}
+
+// IssuePattern maps a regex to an issue severity and message.
+type IssuePattern struct {
+	// Pattern is a case-insensitive regular expression.
+	Pattern string
+
+	// Severity is the issue severity (critical, major, minor).
+	Severity string
+
+	// Message describes the problem when the pattern matches.
+	Message string
+}
+
+// GitConfig holds git behavior settings.
+type GitConfig struct {
+	// OnDiverged controls pushing when the remote branch has commits missing
+	// locally (e.g. a rerun): "rebase", "force-with-lease", or "fail".
+	OnDiverged string
+
+	// IterationTags tags the code of each review iteration with its score
+	// and unresolved issue count, and notes the final commit with the run's
+	// convergence, so the history survives the single final commit.
+	IterationTags bool
+
+	// WIPCommits commits the work in progress after execution and after
+	// each refactor, so the branch records every iteration as it happens.
+	WIPCommits bool
+
+	// History is how the branch's commits are curated before pushing:
+	// "squash" into one commit, one commit per "plan-steps" step the
+	// changed files belong to, or "keep" the work-in-progress commits.
+	History string
+}
+
+// WorktreeConfig holds worktree creation settings.
+type WorktreeConfig struct {
+	// Sparse creates worktrees with cone-mode sparse checkout limited to the
+	// directories the planner identifies. Planning runs against the main repo.
+	Sparse bool
+
+	// SparsePaths are directories always included in sparse worktrees.
+	SparsePaths []string
+
+	// FetchDepth limits history fetched for the base branch when the repo is
+	// a shallow clone (0 = normal fetch).
+	FetchDepth int
+
+	// Root is a dedicated directory for worktrees (e.g. a scratch disk or
+	// tmpfs). Worktrees go under Root/<repo>-<hash>/. Empty = <repo>/.worktrees.
+	Root string
+}
+
+// BranchConfig controls generated branch names (see task.BranchScheme).
+type BranchConfig struct {
+	// Template with {type}, {ticket} and {slug} placeholders, e.g.
+	// "{type}/{ticket}-{slug}". Empty = "{ticket}-{slug}", preferring
+	// Linear's suggested branch name.
+	Template string
+
+	// SlugLength caps the slugified title (default 30).
+	SlugLength int
+
+	// Types maps ticket labels to {type}, e.g. bug: fix. Nil = built-in
+	// bug → fix, feature → feat.
+	Types map[string]string
+
+	// DefaultType is {type} when no label matches (default "feat").
+	DefaultType string
+}
+
+// PRConfig controls generated pull request bodies.
+type PRConfig struct {
+	// Language of the PR body, as a code or name ("ja", "Japanese").
+	// Non-English bodies are translated by the model. Empty = English.
+	Language string
+
+	// Sections the agent must fill in from the run; the PR is not created
+	// while any is empty.
+	Sections []PRSection
+
+	// Provider is where changes are submitted: github, gerrit, azure,
+	// bitbucket, or auto (default), which picks gerrit for repos with a
+	// .gitreview file or a remote on port 29418, azure for Azure Repos
+	// remotes and bitbucket for bitbucket.org remotes.
+	Provider string
+
+	// Draft opens PRs as drafts.
+	Draft bool
+
+	// Labels applied to created GitHub PRs.
+	Labels []string
+
+	// Reviewers requested on created GitHub and Bitbucket PRs
+	Reviewers ReviewersConfig
+
+	// AutoMerge watches created GitHub PRs and merges them once ready
+	AutoMerge AutoMergeConfig
+
+	// PostMerge verifies the base branch once a created GitHub PR merges
+	PostMerge PostMergeConfig
+}
+
+// PostMergeConfig builds and smoke tests the base branch once a created PR
+// merges, alerting and marking the run in history if the merge broke it.
+type PostMergeConfig struct {
+	// Enabled starts a background check for each PR a run creates.
+	Enabled bool
+
+	// Build is the build command. Empty uses commands.build.
+	Build string
+
+	// Smoke is the smoke test command. Empty uses commands.test.
+	Smoke string
+
+	// Interval between checks of whether the PR merged (default 2m).
+	Interval time.Duration
+
+	// Timeout gives up on a PR that hasn't merged (default 72h).
+	Timeout time.Duration
+}
+
+// AutoMergeConfig enables auto-merge on a created PR once its checks pass
+// and it is approved, then closes out the Linear ticket when it merges.
+type AutoMergeConfig struct {
+	// Enabled starts a background watcher for each PR a run creates.
+	Enabled bool
+
+	// Method is squash, rebase or merge. Empty picks the first the
+	// repository allows, in that order.
+	Method string
+
+	// RequireApproval waits for an approving review (default true).
+	RequireApproval bool
+
+	// Interval between checks of the PR (default 2m).
+	Interval time.Duration
+
+	// Timeout gives up watching a PR that hasn't merged (default 72h).
+	Timeout time.Duration
+
+	// DoneState is the Linear state a merged PR's ticket moves to
+	// (default "Done").
+	DoneState string
+}
+
+// ReviewersConfig assigns reviewers to created PRs.
+type ReviewersConfig struct {
+	// Strategy is "round-robin" through Team, or "codeowners" to pick
+	// the code owners of the changed files with the fewest recent
+	// assignments, falling back to Team. Empty assigns no one.
+	Strategy string
+
+	// Team are the GitHub users or org/team slugs, or Bitbucket account
+	// IDs or {UUID}s, to rotate through.
+	Team []string
+
+	// Count is how many reviewers to request (default 1).
+	Count int
+
+	// Exclude are never requested, e.g. the account boatman opens PRs as.
+	Exclude []string
+}
+
+// GerritConfig holds how changes are pushed for review to Gerrit.
+type GerritConfig struct {
+	// Remote to push to (default "origin").
+	Remote string
+
+	// Topic grouping the change. Empty uses the ticket ID.
+	Topic string
+}
+
+// PRSection is a required PR body section.
+type PRSection struct {
+	// Title is the section heading, e.g. "Rollback plan".
+	Title string
+
+	// Prompt tells the agent what the section should cover.
+	Prompt string
+}
+
+// FeatureFlagConfig declares the repo's feature-flag system, so risky new
+// behavior is gated behind a flag and new flags are registered.
+type FeatureFlagConfig struct {
+	// System is "launchdarkly", "flipper", "env", or another name when
+	// Pattern is set. Empty disables flag awareness.
+	System string
+
+	// Registry are files (or globs) where flags must be declared.
+	// Empty skips the registration check.
+	Registry []string
+
+	// Pattern is a regular expression matching a flag's use, with the flag
+	// name in the first group. Empty uses the system's built-in pattern.
+	Pattern string
+}
+
+// JiraConfig holds the Jira connection, for teams tracking work in Jira.
+type JiraConfig struct {
+	// URL of the Jira site, e.g. https://acme.atlassian.net.
+	URL string
+
+	// Email of the Jira Cloud account the token belongs to. Empty sends
+	// Token as a personal access token (Jira Server / Data Center).
+	Email string
+
+	// Token is a Jira API token or personal access token.
+	Token string
+
+	// Transition is the status a ticket moves to when its PR is created
+	// (default "In Review"). Empty leaves the ticket as it is.
+	Transition string
+}
+
+// AzureConfig holds the Azure DevOps connection, for teams tracking work
+// in Azure Boards or hosting code in Azure Repos.
+type AzureConfig struct {
+	// URL of the Azure DevOps server (default https://dev.azure.com).
+	URL string
+
+	// Organization and Project the work items and repository belong to.
+	// Required for the azure source; PRs default to the ones in the Azure
+	// Repos remote URL.
+	Organization string
+	Project      string
+
+	// Repository PRs are opened in. Empty uses the remote's repository.
+	Repository string
+
+	// Token is a personal access token with Work Items (read) and Code
+	// (read & write) scopes.
+	Token string
+}
+
+// GitHubConfig holds the GitHub API connection. With a token, PRs are
+// opened through the API; without one, through the gh CLI.
+type GitHubConfig struct {
+	// Token with pull request (read & write) access.
+	Token string
+
+	// APIURL of the REST API (default https://api.github.com). GitHub
+	// Enterprise Server uses https://<host>/api/v3.
+	APIURL string
+}
+
+// BitbucketConfig holds the Bitbucket Cloud connection PRs are opened
+// through.
+type BitbucketConfig struct {
+	// URL of the 2.0 API (default https://api.bitbucket.org/2.0).
+	URL string
+
+	// Workspace and Repository (slug) PRs are opened in. Empty uses the
+	// ones in the origin remote URL.
+	Workspace  string
+	Repository string
+
+	// Username and Token authenticate with an app password. Without a
+	// username, Token is a repository, project or workspace access token.
+	Username string
+	Token    string
+}
+
+// SentryConfig holds the Sentry connection. Sentry issues linked in bug
+// tickets have their latest event fetched for its stack trace and
+// breadcrumbs.
+type SentryConfig struct {
+	// URL of the Sentry server (default https://sentry.io).
+	URL string
+
+	// Token is an auth token with event:read scope. Empty skips Sentry.
+	Token string
+}
+
+// SpecsConfig holds the credentials for feature specs linked in ticket
+// descriptions (Notion, Google Docs and Confluence pages), which are
+// fetched and summarized into the planning and execution prompts. Links to
+// a service without credentials are skipped.
+type SpecsConfig struct {
+	// MaxTokens is the budget the linked docs are summarized within
+	// (default 4000).
+	MaxTokens int
+
+	// NotionToken is a Notion integration token the pages are shared with.
+	NotionToken string
+
+	// GoogleToken is an OAuth access token with the drive.readonly scope.
+	GoogleToken string
+
+	// Confluence is the Confluence site pages are fetched from.
+	Confluence ConfluenceConfig
+}
+
+// ConfluenceConfig holds a Confluence connection. Only pages on its site
+// are fetched, so the credentials go nowhere else.
+type ConfluenceConfig struct {
+	// URL of the Confluence site, e.g. https://acme.atlassian.net.
+	URL string
+
+	// Email of the Atlassian account the token belongs to. Empty sends
+	// Token as a personal access token (Server / Data Center).
+	Email string
+
+	// Token is an Atlassian API token or personal access token.
+	Token string
+}
+
+// IngestConfig controls what else a ticket description brings into the
+// planning and execution prompts: its screenshots, described by a vision
+// model, and the public web pages it links to.
+type IngestConfig struct {
+	// Images describes the ticket's screenshots (default true).
+	Images bool
+
+	// URLs fetches the pages the ticket links to (default true). Links to
+	// specs, Sentry and uploads are left to their own integrations.
+	URLs bool
+
+	// MaxTokens is the budget the fetched pages share (default 3000).
+	MaxTokens int
+}
+
+// E2EConfig controls end-to-end specs for a ticket's acceptance criteria,
+// in repos that use Playwright or Cypress.
+type E2EConfig struct {
+	// Enabled has an agent write a spec per acceptance criterion after
+	// execution, and runs the specs the change touches headless at each
+	// review; failing tests are major issues (default false).
+	Enabled bool
+
+	// Framework is playwright or cypress. Empty detects it from the
+	// framework's config file.
+	Framework string
+
+	// Dir holds the specs. Empty uses Playwright's testDir, or the
+	// framework's usual directory.
+	Dir string
+
+	// Timeout bounds each E2E run (default 10m).
+	Timeout time.Duration
+}
+
+// BundleConfig sets performance budgets for a web repo's bundle, which is
+// built before and after the change to compare sizes.
+type BundleConfig struct {
+	// Build is the command that builds the bundle, e.g. "npm ci && npm run
+	// build". Empty disables bundle checks.
+	Build string
+
+	// Dir is the build's output directory, e.g. "dist".
+	Dir string
+
+	// Files are patterns, relative to Dir, of the files measured
+	// (default: *.js and *.css).
+	Files []string
+
+	// Gzip compares gzipped sizes, closer to what users download.
+	Gzip bool
+
+	// MaxGrowth is the total growth, in percent, that fails review
+	// (default 5).
+	MaxGrowth float64 `mapstructure:"max_growth"`
+
+	// Budgets cap the total size of the files matching each pattern.
+	Budgets []BundleBudget
+}
+
+// BundleBudget caps the size of some bundle files.
+type BundleBudget struct {
+	// Files is a pattern relative to the output directory, e.g. "main*.js".
+	Files string
+
+	// Max is the largest total size allowed, e.g. "250KB".
+	Max string
+}
+
+// TelemetryConfig controls anonymous usage metrics. Off unless enabled.
+type TelemetryConfig struct {
+	// Enabled opts in to reporting anonymized step durations, iteration
+	// counts, and failure categories. Code and prompts are never sent.
+	Enabled bool
+
+	// Endpoint receives reports as JSON POSTs. Without one, reports are
+	// only written to the local telemetry log.
+	Endpoint string
+}
+
+// DaemonConfig holds the settings of daemon mode, which works through
+// queued tickets.
+type DaemonConfig struct {
+	// State and Label select the Linear tickets that are ready for
+	// boatman, e.g. the "Ready for AI" state. At least one is required;
+	// with both, tickets must match both.
+	State string
+	Label string
+
+	// Team limits polling to one Linear team key, e.g. ENG. Empty polls
+	// every team the API key can see.
+	Team string
+
+	// Interval is how often Linear is polled (default 5m).
+	Interval time.Duration
+
+	// MaxParallel is how many tickets run at once (default 1).
+	MaxParallel int `mapstructure:"max_parallel"`
+
+	// InProgressState is set when a ticket is picked up, so it isn't
+	// polled again (default "In Progress"). ReviewState is set, with a
+	// comment linking the PR, once its PR is open (default "In Review").
+	// FailedState is set when a run fails; empty leaves the ticket where
+	// it is. The failure is commented on either way.
+	InProgressState string `mapstructure:"in_progress_state"`
+	ReviewState     string `mapstructure:"review_state"`
+	FailedState     string `mapstructure:"failed_state"`
+
+	// Schedule decides which queued ticket runs next and when.
+	Schedule ScheduleConfig
+
+	// Tenants are the repositories or teams one daemon works for, each
+	// with its own queue, config, credentials and budget. Empty works on
+	// the repository the daemon is started in.
+	Tenants []TenantConfig
+}
+
+// TenantConfig is one repository or team a multi-tenant daemon works for.
+// Its runs are isolated from other tenants' by their environment, so each
+// sees only its own config, credentials, worktrees and memory.
+type TenantConfig struct {
+	// Name labels the tenant's log lines and metrics, e.g. "payments".
+	Name string
+
+	// Repo is the repository its tickets are worked on in.
+	Repo string
+
+	// Config is a config file merged over the repo's own for its runs,
+	// e.g. a team's models and review settings.
+	Config string
+
+	// Team, State and Label select its tickets, replacing daemon.team,
+	// daemon.state and daemon.label when set.
+	Team  string
+	State string
+	Label string
+
+	// MaxParallel is how many of its tickets run at once (default
+	// daemon.max_parallel).
+	MaxParallel int `mapstructure:"max_parallel"`
+
+	// MaxCostUSD is the spend limit of each of its runs (default
+	// max_cost_usd).
+	MaxCostUSD float64 `mapstructure:"max_cost_usd"`
+
+	// WorktreeRoot and MemoryDir keep its worktrees and learned memory
+	// apart from other tenants' (default worktree.root and
+	// ~/.boatman/memory).
+	WorktreeRoot string `mapstructure:"worktree_root"`
+	MemoryDir    string `mapstructure:"memory_dir"`
+
+	// Env are KEY=VALUE entries added to its runs' environment, e.g. its
+	// own LINEAR_API_KEY and ANTHROPIC_API_KEY. Values may reference
+	// other variables, e.g. LINEAR_API_KEY=${PAYMENTS_LINEAR_KEY}. Its
+	// LINEAR_API_KEY is also used to poll its tickets.
+	Env []string
+}
+
+// ServeConfig configures boatman serve, which starts runs from Linear and
+// GitHub webhooks. Each source is only accepted once its secret is set,
+// since every delivery's signature is checked against it.
+type ServeConfig struct {
+	// Addr is the address to listen on (default ":8080").
+	Addr string
+
+	// Linear webhooks start a run when Label is added to an issue.
+	Linear LinearWebhookConfig
+
+	// GitHub issue comments starting with Command start a run.
+	GitHub GitHubWebhookConfig `mapstructure:"github"`
+}
+
+// LinearWebhookConfig configures the Linear webhook.
+type LinearWebhookConfig struct {
+	// Secret is the webhook's signing secret (or LINEAR_WEBHOOK_SECRET).
+	Secret string
+
+	// Label is the label that starts a run when added (default "boatman").
+	Label string
+}
+
+// GitHubWebhookConfig configures the GitHub webhook.
+type GitHubWebhookConfig struct {
+	// Secret is the webhook's secret (or GITHUB_WEBHOOK_SECRET).
+	Secret string
+
+	// Command starts a comment that asks for a run (default "/boatman
+	// implement"), followed by the ticket ID. Without one, the first
+	// ticket ID in the issue title is used.
+	Command string
+
+	// Associations are the commenter relationships to the repository
+	// allowed to start runs. Empty allows OWNER, MEMBER and COLLABORATOR.
+	Associations []string
+}
+
+// ScheduleConfig orders the daemon's queue. Tickets run by priority
+// (urgent first, unprioritized last), then in the order they were queued.
+type ScheduleConfig struct {
+	// FairShare breaks priority ties in favor of the project that has had
+	// the fewest runs, so one busy project does not starve the others.
+	FairShare bool `mapstructure:"fair_share"`
+
+	// WorkingHours limits when queued tickets are started. Runs under way
+	// when the window closes are not stopped. Unset runs around the clock.
+	WorkingHours WorkingHoursConfig `mapstructure:"working_hours"`
+}
+
+// WorkingHoursConfig is a daily window such as 09:00-18:00 on weekdays.
+type WorkingHoursConfig struct {
+	// Start and End are HH:MM times. An End before Start spans midnight.
+	Start string
+	End   string
+
+	// Days the window applies on, e.g. [mon, tue, wed, thu, fri]. Empty
+	// means every day.
+	Days []string
+
+	// Timezone is an IANA name such as Europe/London. Empty uses the
+	// local time zone.
+	Timezone string
+}
+
+// NotifyConfig lists the webhooks told when runs start and finish, and
+// who gets the daily activity digest.
+type NotifyConfig struct {
+	Webhooks []WebhookConfig
+	Digest   DigestConfig
+}
+
+// DigestConfig sets up the daily email summarizing boatman's runs: runs
+// attempted, PRs created and merged, cost, and failures needing attention.
+type DigestConfig struct {
+	// To are the recipients. Empty disables the digest.
+	To []string
+
+	// From is the sender address.
+	From string
+
+	// At is the local time of day the digest is sent, as HH:MM (default
+	// 09:00). It covers the 24 hours before.
+	At string
+
+	// Provider is smtp (default) or sendgrid.
+	Provider string
+
+	// SMTP server, for the smtp provider.
+	SMTP SMTPConfig
+
+	// SendGridKey is the SendGrid API key, for the sendgrid provider.
+	SendGridKey string
+}
+
+// SMTPConfig is an SMTP server to send mail through.
+type SMTPConfig struct {
+	Host string
+	// Port defaults to 587, with STARTTLS when the server offers it.
+	Port int
+	// Username and Password authenticate with PLAIN auth; empty sends
+	// without authenticating.
+	Username string
+	Password string
+}
+
+// WebhookConfig is a Slack, Discord or generic JSON webhook.
+type WebhookConfig struct {
+	// URL the notifications are POSTed to.
+	URL string
+
+	// Format is slack, discord or json (default json, the event as is).
+	Format string
+
+	// Events to send: started, review_failed, pr_created, failed and
+	// main_broken. Empty sends all of them.
+	Events []string
+}
+
+// HookConfig is a command run before or after a workflow step, in the
+// worktree. It is passed the run's task, plan and diff as JSON in the file
+// named by BOATMAN_CONTEXT.
+type HookConfig struct {
+	// Step is the checkpoint step the hook runs around, such as execution
+	// or create_pr.
+	Step string
+
+	// When is before or after (default after). After hooks only run when
+	// the step succeeds.
+	When string
+
+	// Run is the shell command.
+	Run string
+
+	// OnFailure is fail (default), which fails the step, or warn.
+	OnFailure string `mapstructure:"on_failure"`
+}
+
+// CommandsConfig holds the project's own test, build, and lint commands.
+// They are shown to Claude so it can verify its changes; the test command
+// also replaces framework auto-detection in the test runner.
+type CommandsConfig struct {
+	Test  string
+	Build string
+	Lint  string
 }
 
 // CoordinatorConfig holds coordinator-specific settings.
@@ -86,6 +1074,15 @@ type ClaudeConfig struct {
 	// UseTmux enables tmux for large prompts.
 	UseTmux bool
 
+	// Runner is how agents run claude: "tmux", in a tmux session each, or
+	// "direct", as a child process whose stream-json output is parsed in
+	// Go. Empty picks tmux where it is installed and direct elsewhere,
+	// including Windows.
+	Runner string
+
+	// Headless is the older spelling of Runner "direct", for CI and tests.
+	Headless bool
+
 	// LargePromptThreshold is the character count above which to use tmux.
 	LargePromptThreshold int
 
@@ -95,10 +1092,82 @@ type ClaudeConfig struct {
 	// Model configuration per agent type
 	Models ModelConfig
 
+	// Sampling holds sampling parameters per agent type, keyed like Models
+	// (planner, executor, reviewer, ...). Only API providers apply them;
+	// the Claude CLI has no sampling flags.
+	Sampling map[string]SamplingConfig
+
 	// EnablePromptCaching enables prompt caching for cost reduction.
 	// Note: Requires Claude CLI version that supports --cache-system-prompt flag.
 	// Set to true only if your CLI version supports it.
 	EnablePromptCaching bool
+
+	// Provider selects the model backend (default: the Claude CLI).
+	Provider ProviderConfig
+
+	// CommandPolicy blocks destructive commands agents run with the Bash
+	// tool.
+	CommandPolicy CommandPolicyConfig
+}
+
+// CommandPolicyConfig restricts the commands agents may run with the Bash
+// tool. Blocked attempts are refused and logged.
+type CommandPolicyConfig struct {
+	// Enabled blocks recursive rm outside the worktree, force pushes to and
+	// deletes of protected branches, and downloads piped into a shell
+	// (default true).
+	Enabled bool
+
+	// ProtectedBranches are the branches, or path.Match patterns such as
+	// release/*, that may not be force-pushed or deleted (default main and
+	// master).
+	ProtectedBranches []string `mapstructure:"protected_branches"`
+
+	// Deny are regexes of further commands every agent is refused.
+	Deny []string
+
+	// Roles maps an agent role (executor, refactor, test-fix, ...) to
+	// regexes of commands only that role is refused.
+	Roles map[string][]string
+}
+
+// NetworkConfig is the egress policy of agent sessions. Runs start a
+// local proxy that refuses hosts the policy doesn't allow, and sessions
+// reach the network through it.
+type NetworkConfig struct {
+	// Enabled routes sessions through the egress proxy (default false).
+	Enabled bool
+
+	// Allow are the hosts sessions may reach: host names, *.example.com
+	// for subdomains, or "registries" for the public package registries
+	// (default registries). The model API is always allowed.
+	Allow []string
+
+	// Deny are hosts refused even when allowed.
+	Deny []string
+
+	// Default is "deny" (default) or "allow", for hosts matching neither
+	// list.
+	Default string
+}
+
+// ProviderConfig selects the model backend agents talk to.
+type ProviderConfig struct {
+	// Name is claude-cli (default), anthropic, openai or ollama.
+	// API providers cannot use tools or Claude skills, so the executor asks
+	// for complete files instead of editing the worktree itself.
+	Name string
+
+	// BaseURL overrides the provider's API endpoint, e.g. for an
+	// OpenAI-compatible server or a remote Ollama host.
+	BaseURL string
+
+	// APIKey for the provider; $VARS are expanded. Defaults to
+	// ANTHROPIC_API_KEY or OPENAI_API_KEY.
+	APIKey string
+
+	// MaxTokens caps each reply (default: 8192).
+	MaxTokens int
 }
 
 // ModelConfig holds model selection per agent type.
@@ -122,6 +1191,45 @@ type ModelConfig struct {
 
 	// TestRunner model for test output parsing (empty = CLI default)
 	TestRunner string
+
+	// Retro model for post-run lessons; a cheap model is enough (empty = CLI default)
+	Retro string
+
+	// Verifier model for checking that a refactor's diff addresses the
+	// review issues (empty = CLI default)
+	Verifier string
+
+	// TestFixer model for repairing failing tests (empty = CLI default)
+	TestFixer string
+
+	// Vision model for describing ticket screenshots (empty = CLI default)
+	Vision string
+}
+
+// SamplingRoles are the agent types claude.sampling may configure.
+var SamplingRoles = []string{
+	"planner", "executor", "reviewer", "refactor", "preflight",
+	"retro", "verifier", "test_fixer", "vision",
+}
+
+// SamplingConfig holds an agent's sampling parameters. Unset parameters
+// are left to the provider.
+type SamplingConfig struct {
+	// Temperature, from 0 (most deterministic) to 2; Anthropic accepts
+	// up to 1.
+	Temperature *float64
+
+	// TopP is the nucleus sampling cutoff, from 0 to 1.
+	TopP *float64 `mapstructure:"top_p"`
+
+	// Seed makes sampling repeatable on OpenAI and Ollama. The Anthropic
+	// API has no seed and ignores it.
+	Seed *int
+}
+
+// IsEmpty reports whether no parameter is set.
+func (s SamplingConfig) IsEmpty() bool {
+	return s.Temperature == nil && s.TopP == nil && s.Seed == nil
 }
 
 // TokenBudgetConfig holds context token budget settings.
@@ -138,20 +1246,121 @@ type TokenBudgetConfig struct {
 
 // Load reads configuration from viper and environment variables.
 func Load() (*Config, error) {
+	cfg := LoadUnvalidated()
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// LoadUnvalidated reads configuration without checking required settings.
+// Used by diagnostics that report problems instead of failing on them.
+func LoadUnvalidated() *Config {
 	cfg := &Config{
 		LinearKey:     getEnvOrViper("LINEAR_API_KEY", "linear_key"),
+		Source:        getStringOrDefault("source", "linear"),
+		Jira: JiraConfig{
+			URL:        getEnvOrViper("JIRA_URL", "jira.url"),
+			Email:      getEnvOrViper("JIRA_EMAIL", "jira.email"),
+			Token:      getEnvOrViper("JIRA_API_TOKEN", "jira.token"),
+			Transition: getStringOrDefault("jira.transition", "In Review"),
+		},
+		Azure: AzureConfig{
+			URL:          getStringOrDefault("azure.url", "https://dev.azure.com"),
+			Organization: getStringOrDefault("azure.organization", ""),
+			Project:      getStringOrDefault("azure.project", ""),
+			Repository:   getStringOrDefault("azure.repository", ""),
+			Token:        getEnvOrViper("AZURE_DEVOPS_EXT_PAT", "azure.token"),
+		},
+		GitHub: GitHubConfig{
+			Token:  getEnvOrViper("GITHUB_TOKEN", "github.token"),
+			APIURL: getStringOrDefault("github.api_url", "https://api.github.com"),
+		},
+		Bitbucket: BitbucketConfig{
+			URL:        getStringOrDefault("bitbucket.url", "https://api.bitbucket.org/2.0"),
+			Workspace:  getStringOrDefault("bitbucket.workspace", ""),
+			Repository: getStringOrDefault("bitbucket.repository", ""),
+			Username:   getStringOrDefault("bitbucket.username", ""),
+			Token:      getEnvOrViper("BITBUCKET_TOKEN", "bitbucket.token"),
+		},
+		Sentry: SentryConfig{
+			URL:   getStringOrDefault("sentry.url", "https://sentry.io"),
+			Token: getEnvOrViper("SENTRY_AUTH_TOKEN", "sentry.token"),
+		},
+		Specs: SpecsConfig{
+			MaxTokens:   getIntOrDefault("specs.max_tokens", 4000),
+			NotionToken: getEnvOrViper("NOTION_TOKEN", "specs.notion.token"),
+			GoogleToken: getEnvOrViper("GOOGLE_ACCESS_TOKEN", "specs.google.token"),
+			Confluence: ConfluenceConfig{
+				URL:   getStringOrDefault("specs.confluence.url", ""),
+				Email: getEnvOrViper("CONFLUENCE_EMAIL", "specs.confluence.email"),
+				Token: getEnvOrViper("CONFLUENCE_API_TOKEN", "specs.confluence.token"),
+			},
+		},
+		Ingest: IngestConfig{
+			Images:    getBoolOrDefault("ingest.images", true),
+			URLs:      getBoolOrDefault("ingest.urls", true),
+			MaxTokens: getIntOrDefault("ingest.max_tokens", 3000),
+		},
+		E2E: E2EConfig{
+			Enabled:   getBoolOrDefault("e2e.enabled", false),
+			Framework: getStringOrDefault("e2e.framework", ""),
+			Dir:       getStringOrDefault("e2e.dir", ""),
+			Timeout:   getDurationOrDefault("e2e.timeout", 10*time.Minute),
+		},
 		MaxIterations: getIntOrDefault("max_iterations", 5), // Increased from 3 to 5
 		BaseBranch:    getStringOrDefault("base_branch", "main"),
 		AutoPR:        viper.GetBool("auto_pr"),
 		ReviewSkill:   getStringOrDefault("review_skill", "peer-review"),
+		Pair:          viper.GetBool("pair"),
+		ReviewPlan:    viper.GetBool("review_plan"),
+		Retro:         viper.GetBool("retro"),
+		DryRun:        viper.GetBool("dry_run"),
+		Pipeline:      viper.GetStringSlice("pipeline"),
+		Hooks:         getHooks("hooks"),
 		Debug:         os.Getenv("BOATMAN_DEBUG") == "1",
 		EnableTools:   getBoolOrDefault("enable_tools", true),
 
+		MinSuccessLikelihood: getIntOrDefault("min_success_likelihood", 30),
+		MaxTestFixAttempts:   getIntOrDefault("max_test_fix_attempts", 2),
+		MinCoverage:          viper.GetFloat64("min_coverage"),
+		MaxCostUSD:           viper.GetFloat64("max_cost_usd"),
+		RequireApproval:      viper.GetString("require_approval"),
+
 		Review: ReviewConfig{
 			MaxCriticalIssues:         getIntOrDefault("review.max_critical_issues", 1),    // Allow 1 critical (was 0)
 			MaxMajorIssues:            getIntOrDefault("review.max_major_issues", 3),       // Allow 3 major (was 2)
 			MinVerificationConfidence: getIntOrDefault("review.min_verification_confidence", 50), // 50% confidence threshold
 			StrictParsing:             getBoolOrDefault("review.strict_parsing", false),    // Relaxed by default
+			StrictOutput:              getBoolOrDefault("review.strict_output", false),
+			OutputRetries:             getIntOrDefault("review.output_retries", 2),
+			Differential:              getBoolOrDefault("review.differential", false),
+			NewIssuePatterns:          getIssuePatterns("review.new_issue_patterns"),
+			Rubric:                    getRubric("review.rubric"),
+			Skills:                    getSkills("review.skills"),
+			Ensemble:                  getEnsemble("review.ensemble"),
+			Timeout:                   getDurationOrDefault("review.timeout", 10*time.Minute),
+			FallbackModel:             getStringOrDefault("review.fallback_model", ""),
+			Observability:             getObservability("review.observability"),
+			RaceCheck:                 getBoolOrDefault("review.race_check", false),
+			SQL:                       getSQLReview("review.sql"),
+			Snapshots:                 getSnapshots("review.snapshots"),
+			Migrations:                getMigrations("review.migrations"),
+			Contracts:                 getContracts("review.contracts"),
+			GraphQL: GraphQLConfig{
+				Enabled:    getBoolOrDefault("review.graphql.enabled", false),
+				Inspector:  getStringOrDefault("review.graphql.inspector", "npx --yes @graphql-inspector/cli"),
+				Paths:      viper.GetStringSlice("review.graphql.paths"),
+				AllowLabel: getStringOrDefault("review.graphql.allow_label", "breaking-change"),
+			},
+			Security: SecurityConfig{
+				Enabled: getBoolOrDefault("review.security.enabled", true),
+				Tools:   getStringSliceOrDefault("review.security.tools", []string{"gitleaks", "trivy"}),
+				Ignore:  viper.GetStringSlice("review.security.ignore"),
+			},
+			AcceptanceCriteria:        getBoolOrDefault("review.acceptance_criteria", true),
 		},
 
 		Coordinator: CoordinatorConfig{
@@ -168,6 +1377,8 @@ func Load() (*Config, error) {
 		Claude: ClaudeConfig{
 			Command:              getStringOrDefault("claude.command", "claude"),
 			UseTmux:              viper.GetBool("claude.use_tmux"),
+			Runner:               getStringOrDefault("claude.runner", ""),
+			Headless:             getBoolOrDefault("claude.headless", false),
 			LargePromptThreshold: getIntOrDefault("claude.large_prompt_threshold", 100000),
 			Timeout:              getDurationOrDefault("claude.timeout", 0),
 			EnablePromptCaching:  getBoolOrDefault("claude.enable_prompt_caching", false),
@@ -178,7 +1389,24 @@ func Load() (*Config, error) {
 				Refactor:   getStringOrDefault("claude.models.refactor", ""),   // Empty = use CLI default
 				Preflight:  getStringOrDefault("claude.models.preflight", ""),  // Empty = use CLI default
 				TestRunner: getStringOrDefault("claude.models.test_runner", ""), // Empty = use CLI default
+				Retro:      getStringOrDefault("claude.models.retro", ""),       // Empty = use CLI default
+				Verifier:   getStringOrDefault("claude.models.verifier", ""),    // Empty = use CLI default
+				TestFixer:  getStringOrDefault("claude.models.test_fixer", ""),  // Empty = use CLI default
+				Vision:     getStringOrDefault("claude.models.vision", ""),      // Empty = use CLI default
+			},
+			Provider: ProviderConfig{
+				Name:      getStringOrDefault("provider.name", "claude-cli"),
+				BaseURL:   getStringOrDefault("provider.base_url", ""),
+				APIKey:    getStringOrDefault("provider.api_key", ""),
+				MaxTokens: getIntOrDefault("provider.max_tokens", 8192),
+			},
+			CommandPolicy: CommandPolicyConfig{
+				Enabled:           getBoolOrDefault("claude.command_policy.enabled", true),
+				ProtectedBranches: getStringSliceOrDefault("claude.command_policy.protected_branches", []string{"main", "master"}),
+				Deny:              viper.GetStringSlice("claude.command_policy.deny"),
+				Roles:             getStringSliceMap("claude.command_policy.roles"),
 			},
+			Sampling: getSampling("claude.sampling"),
 		},
 
 		TokenBudget: TokenBudgetConfig{
@@ -186,23 +1414,516 @@ func Load() (*Config, error) {
 			Plan:    getIntOrDefault("token_budget.plan", 2000),
 			Review:  getIntOrDefault("token_budget.review", 4000),
 		},
-	}
 
-	if err := cfg.Validate(); err != nil {
-		return nil, err
+		Git: GitConfig{
+			OnDiverged:    getStringOrDefault("git.on_diverged", "rebase"),
+			IterationTags: getBoolOrDefault("git.iteration_tags", false),
+			WIPCommits:    getBoolOrDefault("git.wip_commits", false),
+			History:       getStringOrDefault("git.history", "squash"),
+		},
+
+		Worktree: WorktreeConfig{
+			Sparse:      getBoolOrDefault("worktree.sparse", false),
+			SparsePaths: viper.GetStringSlice("worktree.sparse_paths"),
+			FetchDepth:  getIntOrDefault("worktree.fetch_depth", 0),
+			Root:        getEnvOrViper(WorktreeRootEnv, "worktree.root"),
+		},
+
+		Branch: BranchConfig{
+			Template:    getStringOrDefault("branch.template", ""),
+			SlugLength:  getIntOrDefault("branch.slug_length", 30),
+			Types:       getStringMapOrNil("branch.types"),
+			DefaultType: getStringOrDefault("branch.default_type", "feat"),
+		},
+
+		PR: PRConfig{
+			Language: getStringOrDefault("pr.language", ""),
+			Sections: getPRSections("pr.sections"),
+			Provider: getStringOrDefault("pr.provider", "auto"),
+			Draft:    getBoolOrDefault("pr.draft", false),
+			Labels:   viper.GetStringSlice("pr.labels"),
+			Reviewers: ReviewersConfig{
+				Strategy: getStringOrDefault("pr.reviewers.strategy", ""),
+				Team:     viper.GetStringSlice("pr.reviewers.team"),
+				Count:    getIntOrDefault("pr.reviewers.count", 1),
+				Exclude:  viper.GetStringSlice("pr.reviewers.exclude"),
+			},
+			AutoMerge: AutoMergeConfig{
+				Enabled:         getBoolOrDefault("pr.auto_merge.enabled", false),
+				Method:          getStringOrDefault("pr.auto_merge.method", ""),
+				RequireApproval: getBoolOrDefault("pr.auto_merge.require_approval", true),
+				Interval:        getDurationOrDefault("pr.auto_merge.interval", 2*time.Minute),
+				Timeout:         getDurationOrDefault("pr.auto_merge.timeout", 72*time.Hour),
+				DoneState:       getStringOrDefault("pr.auto_merge.done_state", "Done"),
+			},
+			PostMerge: PostMergeConfig{
+				Enabled:  getBoolOrDefault("pr.post_merge.enabled", false),
+				Build:    getStringOrDefault("pr.post_merge.build", ""),
+				Smoke:    getStringOrDefault("pr.post_merge.smoke", ""),
+				Interval: getDurationOrDefault("pr.post_merge.interval", 2*time.Minute),
+				Timeout:  getDurationOrDefault("pr.post_merge.timeout", 72*time.Hour),
+			},
+		},
+
+		Gerrit: GerritConfig{
+			Remote: getStringOrDefault("gerrit.remote", "origin"),
+			Topic:  getStringOrDefault("gerrit.topic", ""),
+		},
+
+		FeatureFlags: FeatureFlagConfig{
+			System:   getStringOrDefault("feature_flags.system", ""),
+			Registry: viper.GetStringSlice("feature_flags.registry"),
+			Pattern:  getStringOrDefault("feature_flags.pattern", ""),
+		},
+
+		Bundle: getBundle("bundle"),
+
+		Telemetry: TelemetryConfig{
+			Enabled:  getBoolOrDefault("telemetry.enabled", false),
+			Endpoint: getStringOrDefault("telemetry.endpoint", ""),
+		},
+
+		Notify: NotifyConfig{
+			Webhooks: getWebhooks("notify.webhooks"),
+			Digest: DigestConfig{
+				To:       viper.GetStringSlice("notify.digest.to"),
+				From:     getStringOrDefault("notify.digest.from", ""),
+				At:       getStringOrDefault("notify.digest.at", "09:00"),
+				Provider: getStringOrDefault("notify.digest.provider", "smtp"),
+				SMTP: SMTPConfig{
+					Host:     getStringOrDefault("notify.digest.smtp.host", ""),
+					Port:     getIntOrDefault("notify.digest.smtp.port", 587),
+					Username: getStringOrDefault("notify.digest.smtp.username", ""),
+					Password: getEnvOrViper("SMTP_PASSWORD", "notify.digest.smtp.password"),
+				},
+				SendGridKey: getEnvOrViper("SENDGRID_API_KEY", "notify.digest.sendgrid_key"),
+			},
+		},
+
+		Commands: CommandsConfig{
+			Test:  getStringOrDefault("commands.test", ""),
+			Build: getStringOrDefault("commands.build", ""),
+			Lint:  getStringOrDefault("commands.lint", ""),
+		},
+
+		Daemon: DaemonConfig{
+			State:           getStringOrDefault("daemon.state", ""),
+			Label:           getStringOrDefault("daemon.label", ""),
+			Team:            getStringOrDefault("daemon.team", ""),
+			Interval:        getDurationOrDefault("daemon.interval", 5*time.Minute),
+			MaxParallel:     getIntOrDefault("daemon.max_parallel", 1),
+			InProgressState: getStringOrDefault("daemon.in_progress_state", "In Progress"),
+			ReviewState:     getStringOrDefault("daemon.review_state", "In Review"),
+			FailedState:     getStringOrDefault("daemon.failed_state", ""),
+			Schedule:        getSchedule("daemon.schedule"),
+			Tenants:         getTenants("daemon.tenants"),
+		},
+
+		Serve: ServeConfig{
+			Addr: getStringOrDefault("serve.addr", ":8080"),
+			Linear: LinearWebhookConfig{
+				Secret: getEnvOrViper("LINEAR_WEBHOOK_SECRET", "serve.linear.secret"),
+				Label:  getStringOrDefault("serve.linear.label", "boatman"),
+			},
+			GitHub: GitHubWebhookConfig{
+				Secret:       getEnvOrViper("GITHUB_WEBHOOK_SECRET", "serve.github.secret"),
+				Command:      getStringOrDefault("serve.github.command", "/boatman implement"),
+				Associations: viper.GetStringSlice("serve.github.associations"),
+			},
+		},
+
+		ProtectedPaths: viper.GetStringSlice("protected_paths"),
+
+		Verify: VerifyConfig{
+			UseLLM: getBoolOrDefault("verify.use_llm", false),
+		},
+
+		Network: NetworkConfig{
+			Enabled: getBoolOrDefault("network.enabled", false),
+			Allow:   getStringSliceOrDefault("network.allow", []string{"registries"}),
+			Deny:    viper.GetStringSlice("network.deny"),
+			Default: getStringOrDefault("network.default", "deny"),
+		},
 	}
+	cfg.secretErr = cfg.resolveSecrets()
+	return cfg
+}
 
-	return cfg, nil
+// resolveSecrets replaces the credentials that reference a keychain,
+// Vault or 1Password secret with the secret.
+func (c *Config) resolveSecrets() error {
+	credentials := []struct {
+		key   string
+		value *string
+	}{
+		{"linear_key", &c.LinearKey},
+		{"jira.token", &c.Jira.Token},
+		{"azure.token", &c.Azure.Token},
+		{"github.token", &c.GitHub.Token},
+		{"bitbucket.token", &c.Bitbucket.Token},
+		{"sentry.token", &c.Sentry.Token},
+		{"specs.notion.token", &c.Specs.NotionToken},
+		{"specs.google.token", &c.Specs.GoogleToken},
+		{"specs.confluence.token", &c.Specs.Confluence.Token},
+		{"provider.api_key", &c.Claude.Provider.APIKey},
+		{"notify.digest.smtp.password", &c.Notify.Digest.SMTP.Password},
+		{"notify.digest.sendgrid_key", &c.Notify.Digest.SendGridKey},
+		{"serve.linear.secret", &c.Serve.Linear.Secret},
+		{"serve.github.secret", &c.Serve.GitHub.Secret},
+	}
+	var errs []error
+	for _, cred := range credentials {
+		secret, err := secrets.Resolve(*cred.value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", cred.key, err))
+		}
+		*cred.value = secret
+	}
+	for _, name := range childCredentials {
+		value := os.Getenv(name)
+		if !secrets.IsReference(value) {
+			continue
+		}
+		secret, err := secrets.Resolve(value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+		}
+		os.Setenv(name, secret)
+	}
+	return errors.Join(errs...)
 }
 
+// childCredentials are the variables the tools boatman runs read their
+// credentials from: the Claude CLI, gh and az. References in them are
+// resolved into the process's environment, which the tools inherit.
+var childCredentials = []string{"ANTHROPIC_API_KEY", "GITHUB_TOKEN", "GH_TOKEN", "AZURE_DEVOPS_EXT_PAT"}
+
 // Validate checks that required configuration is present.
 func (c *Config) Validate() error {
-	if c.LinearKey == "" {
-		return errors.New("linear API key is required (set LINEAR_API_KEY or --linear-key)")
+	if c.secretErr != nil {
+		return c.secretErr
+	}
+	switch c.RequireApproval {
+	case "", "commit", "push", "pr":
+	default:
+		return fmt.Errorf("require_approval must be commit, push or pr (got %q)", c.RequireApproval)
+	}
+	switch c.Claude.Runner {
+	case "", "tmux", "direct":
+	default:
+		return fmt.Errorf("claude.runner must be tmux or direct (got %q)", c.Claude.Runner)
+	}
+	switch c.E2E.Framework {
+	case "", "playwright", "cypress":
+	default:
+		return fmt.Errorf("e2e.framework must be playwright or cypress (got %q)", c.E2E.Framework)
+	}
+	if _, err := pipeline.Parse(c.Pipeline); err != nil {
+		return err
+	}
+	if c.MinCoverage < 0 || c.MinCoverage > 100 {
+		return fmt.Errorf("min_coverage must be between 0 and 100 (got %g)", c.MinCoverage)
+	}
+	if err := c.Daemon.Validate(); err != nil {
+		return err
+	}
+	if err := c.Review.Ensemble.Validate(); err != nil {
+		return err
+	}
+	if err := c.Review.ValidateSeverities(); err != nil {
+		return err
+	}
+	if err := c.Claude.CommandPolicy.Validate(); err != nil {
+		return err
+	}
+	if err := c.Network.Validate(); err != nil {
+		return err
+	}
+	if err := c.Claude.ValidateSampling(); err != nil {
+		return err
+	}
+	switch c.Source {
+	case "jira":
+		if c.Jira.URL == "" || c.Jira.Token == "" {
+			return errors.New("jira URL and API token are required (set JIRA_URL and JIRA_API_TOKEN, or jira.url and jira.token)")
+		}
+	case "azure":
+		if c.Azure.Organization == "" || c.Azure.Project == "" || c.Azure.Token == "" {
+			return errors.New("azure organization, project and token are required (set azure.organization, azure.project, and AZURE_DEVOPS_EXT_PAT or azure.token)")
+		}
+	case "linear", "":
+		if c.LinearKey == "" {
+			return errors.New("linear API key is required (set LINEAR_API_KEY or --linear-key)")
+		}
+	default:
+		return fmt.Errorf("source must be linear, jira or azure (got %q)", c.Source)
+	}
+	return nil
+}
+
+// Validate checks the daemon's tenants.
+func (d DaemonConfig) Validate() error {
+	names := make(map[string]bool)
+	for i, t := range d.Tenants {
+		switch {
+		case t.Name == "":
+			return fmt.Errorf("daemon.tenants[%d] needs a name", i)
+		case names[t.Name]:
+			return fmt.Errorf("daemon tenant %q is configured twice", t.Name)
+		case t.Repo == "":
+			return fmt.Errorf("daemon tenant %q needs a repo", t.Name)
+		}
+		names[t.Name] = true
+		for _, entry := range t.Env {
+			key, _, ok := strings.Cut(entry, "=")
+			if !ok || !envKeyPattern.MatchString(key) {
+				return fmt.Errorf("daemon tenant %q: env entry %q must be KEY=VALUE", t.Name, entry)
+			}
+		}
+	}
+	return nil
+}
+
+// Apply returns the daemon settings for the tenant's tickets: d with the
+// tenant's filter and parallelism.
+func (t TenantConfig) Apply(d DaemonConfig) DaemonConfig {
+	if t.Team != "" {
+		d.Team = t.Team
+	}
+	if t.State != "" || t.Label != "" {
+		d.State, d.Label = t.State, t.Label
+	}
+	if t.MaxParallel > 0 {
+		d.MaxParallel = t.MaxParallel
+	}
+	d.Tenants = nil
+	return d
+}
+
+// ExpandedEnv returns the tenant's environment entries with variable
+// references expanded.
+func (t TenantConfig) ExpandedEnv() []string {
+	env := make([]string, 0, len(t.Env))
+	for _, entry := range t.Env {
+		key, value, _ := strings.Cut(entry, "=")
+		env = append(env, key+"="+os.ExpandEnv(value))
+	}
+	return env
+}
+
+// References returns the names of the variables the tenant's environment
+// entries reference.
+func (t TenantConfig) References() []string {
+	var names []string
+	for _, entry := range t.Env {
+		_, value, _ := strings.Cut(entry, "=")
+		os.Expand(value, func(name string) string {
+			names = append(names, name)
+			return ""
+		})
+	}
+	return names
+}
+
+// Getenv returns the expanded value of one of the tenant's environment
+// entries, or "" if it has none.
+func (t TenantConfig) Getenv(key string) string {
+	for _, entry := range t.ExpandedEnv() {
+		if k, value, _ := strings.Cut(entry, "="); k == key {
+			return value
+		}
+	}
+	return ""
+}
+
+// Validate checks that every reviewer has a unique name.
+func (e EnsembleConfig) Validate() error {
+	names := make(map[string]bool)
+	for i, r := range e.Reviewers {
+		switch {
+		case r.Name == "":
+			return fmt.Errorf("review.ensemble.reviewers[%d] needs a name", i)
+		case names[r.Name]:
+			return fmt.Errorf("ensemble reviewer %q is configured twice", r.Name)
+		case r.Weight < 0:
+			return fmt.Errorf("ensemble reviewer %q has a negative weight", r.Name)
+		}
+		names[r.Name] = true
+	}
+	return nil
+}
+
+// Severities are the issue severities review understands.
+var Severities = []string{"critical", "major", "minor"}
+
+// ValidateSeverities checks that every configured issue severity is known,
+// so a typo like "critcal" is rejected instead of never blocking.
+func (r ReviewConfig) ValidateSeverities() error {
+	check := func(key, severity string) error {
+		if severity == "" || slices.Contains(Severities, severity) {
+			return nil
+		}
+		return fmt.Errorf("%s must be critical, major or minor (got %q)", key, severity)
+	}
+	for i, p := range r.NewIssuePatterns {
+		if err := check(fmt.Sprintf("review.new_issue_patterns[%d].severity", i), p.Severity); err != nil {
+			return err
+		}
+	}
+	if err := check("review.sql.severity", r.SQL.Severity); err != nil {
+		return err
+	}
+	if err := check("review.observability.severity", r.Observability.Severity); err != nil {
+		return err
+	}
+	for severity := range r.Ensemble.SeverityWeights {
+		if !slices.Contains(Severities, severity) {
+			return fmt.Errorf("review.ensemble.severity_weights: unknown severity %q (want critical, major or minor)", severity)
+		}
+	}
+	return nil
+}
+
+// SamplingFor returns the sampling parameters of an agent type.
+func (c ClaudeConfig) SamplingFor(role string) SamplingConfig {
+	return c.Sampling[role]
+}
+
+// ValidateSampling checks that the sampling parameters are for known
+// agent types and within the range of the configured provider.
+func (c ClaudeConfig) ValidateSampling() error {
+	// The Anthropic API rejects temperatures above 1; OpenAI and Ollama take up to 2
+	maxTemperature, limit := 2.0, "between 0 and 2"
+	if strings.EqualFold(c.Provider.Name, "anthropic") {
+		maxTemperature, limit = 1, "between 0 and 1 with the anthropic provider"
+	}
+	for role, s := range c.Sampling {
+		if !slices.Contains(SamplingRoles, role) {
+			return fmt.Errorf("claude.sampling.%s: unknown agent type (want one of %s)", role, strings.Join(SamplingRoles, ", "))
+		}
+		switch {
+		case s.Temperature != nil && (*s.Temperature < 0 || *s.Temperature > maxTemperature):
+			return fmt.Errorf("claude.sampling.%s.temperature must be %s (got %g)", role, limit, *s.Temperature)
+		case s.TopP != nil && (*s.TopP < 0 || *s.TopP > 1):
+			return fmt.Errorf("claude.sampling.%s.top_p must be between 0 and 1 (got %g)", role, *s.TopP)
+		case s.Seed != nil && *s.Seed < 0:
+			return fmt.Errorf("claude.sampling.%s.seed must not be negative (got %d)", role, *s.Seed)
+		}
+	}
+	return nil
+}
+
+// Validate checks that the deny patterns are valid regexes.
+func (p CommandPolicyConfig) Validate() error {
+	for _, pattern := range p.Deny {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("claude.command_policy.deny: %w", err)
+		}
+	}
+	for role, patterns := range p.Roles {
+		for _, pattern := range patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("claude.command_policy.roles.%s: %w", role, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Validate checks the default and the host patterns.
+func (n NetworkConfig) Validate() error {
+	switch n.Default {
+	case "", "deny", "allow":
+	default:
+		return fmt.Errorf("network.default must be deny or allow (got %q)", n.Default)
+	}
+	for _, host := range append(append([]string(nil), n.Allow...), n.Deny...) {
+		if host == "*" {
+			continue
+		}
+		if name := strings.TrimPrefix(host, "*."); name == "" || strings.ContainsAny(name, "*/: ") {
+			return fmt.Errorf("network: %q is not a host name or *.domain pattern", host)
+		}
+	}
+	return nil
+}
+
+// Validate checks the git settings.
+func (g GitConfig) Validate() error {
+	switch g.OnDiverged {
+	case "rebase", "force-with-lease", "fail":
+	default:
+		return fmt.Errorf("git.on_diverged must be rebase, force-with-lease, or fail (got %q)", g.OnDiverged)
+	}
+	switch g.History {
+	case "squash", "plan-steps", "keep":
+	default:
+		return fmt.Errorf("git.history must be squash, plan-steps, or keep (got %q)", g.History)
 	}
 	return nil
 }
 
+// SkillOptions returns the invocation options for a review skill.
+// Skill names are matched case-insensitively.
+func (r ReviewConfig) SkillOptions(skill string) SkillConfig {
+	if opts, ok := r.Skills[strings.ToLower(skill)]; ok {
+		return opts
+	}
+	return SkillConfig{}
+}
+
+// reservedSkillFlags are claude CLI flags boatman sets itself for reviews.
+var reservedSkillFlags = map[string]bool{
+	"-p":              true,
+	"--print":         true,
+	"--agent":         true,
+	"--output-format": true,
+	"--model":         true,
+	"--system-prompt": true,
+}
+
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Validate checks the skill options for problems.
+// Returns one error per invalid argument or environment entry.
+func (s SkillConfig) Validate() []error {
+	var errs []error
+	for _, arg := range s.Args {
+		flag, _, _ := strings.Cut(arg, "=")
+		if reservedSkillFlags[flag] {
+			errs = append(errs, fmt.Errorf("arg %q is set by boatman and cannot be overridden", flag))
+		}
+	}
+	for _, entry := range s.Env {
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			errs = append(errs, fmt.Errorf("env entry %q must be KEY=VALUE", entry))
+			continue
+		}
+		if !envKeyPattern.MatchString(key) {
+			errs = append(errs, fmt.Errorf("env key %q is not a valid variable name", key))
+			continue
+		}
+		os.Expand(value, func(name string) string {
+			if _, set := os.LookupEnv(name); !set {
+				errs = append(errs, fmt.Errorf("env %s references unset variable $%s", key, name))
+			}
+			return ""
+		})
+	}
+	return errs
+}
+
+// ExpandedEnv returns the skill's environment entries with variable references expanded.
+func (s SkillConfig) ExpandedEnv() []string {
+	env := make([]string, 0, len(s.Env))
+	for _, entry := range s.Env {
+		key, value, _ := strings.Cut(entry, "=")
+		env = append(env, key+"="+os.ExpandEnv(value))
+	}
+	return env
+}
+
 // getEnvOrViper returns the value from environment variable or viper config.
 func getEnvOrViper(envKey, viperKey string) string {
 	if val := os.Getenv(envKey); val != "" {
@@ -211,6 +1932,14 @@ func getEnvOrViper(envKey, viperKey string) string {
 	return viper.GetString(viperKey)
 }
 
+// getStringSliceOrDefault returns viper string slice value or default if not set.
+func getStringSliceOrDefault(key string, defaultVal []string) []string {
+	if viper.IsSet(key) {
+		return viper.GetStringSlice(key)
+	}
+	return defaultVal
+}
+
 // getIntOrDefault returns viper int value or default if not set.
 func getIntOrDefault(key string, defaultVal int) int {
 	if viper.IsSet(key) {
@@ -235,6 +1964,215 @@ func getDurationOrDefault(key string, defaultVal time.Duration) time.Duration {
 	return defaultVal
 }
 
+// getIssuePatterns returns the configured issue patterns, or nil if not set.
+func getIssuePatterns(key string) []IssuePattern {
+	if !viper.IsSet(key) {
+		return nil
+	}
+	var patterns []IssuePattern
+	if err := viper.UnmarshalKey(key, &patterns); err != nil {
+		return nil
+	}
+	return patterns
+}
+
+// getPRSections returns the configured PR sections, or nil if not set.
+func getPRSections(key string) []PRSection {
+	if !viper.IsSet(key) {
+		return nil
+	}
+	var sections []PRSection
+	if err := viper.UnmarshalKey(key, &sections); err != nil {
+		return nil
+	}
+	return sections
+}
+
+// getObservability returns the observability policy, or an empty one if not set.
+func getObservability(key string) ObservabilityConfig {
+	var policy ObservabilityConfig
+	if viper.IsSet(key) {
+		viper.UnmarshalKey(key, &policy)
+	}
+	return policy
+}
+
+// getBundle returns the bundle budget settings, or empty ones if not set.
+func getBundle(key string) BundleConfig {
+	var bundle BundleConfig
+	if viper.IsSet(key) {
+		viper.UnmarshalKey(key, &bundle)
+	}
+	return bundle
+}
+
+// getSnapshots returns the snapshot policy, or an empty one if not set.
+func getSnapshots(key string) SnapshotConfig {
+	var policy SnapshotConfig
+	if viper.IsSet(key) {
+		viper.UnmarshalKey(key, &policy)
+	}
+	return policy
+}
+
+// getWebhooks returns the configured notification webhooks, or nil if not
+// set.
+func getWebhooks(key string) []WebhookConfig {
+	if !viper.IsSet(key) {
+		return nil
+	}
+	var webhooks []WebhookConfig
+	if err := viper.UnmarshalKey(key, &webhooks); err != nil {
+		return nil
+	}
+	for i := range webhooks {
+		webhooks[i].URL = os.ExpandEnv(webhooks[i].URL)
+	}
+	return webhooks
+}
+
+// getSchedule returns the daemon schedule, or an empty one if not set.
+func getSchedule(key string) ScheduleConfig {
+	var schedule ScheduleConfig
+	if viper.IsSet(key) {
+		viper.UnmarshalKey(key, &schedule)
+	}
+	return schedule
+}
+
+// getTenants returns the daemon's tenants, or nil if not set. Paths may
+// start with ~/.
+func getTenants(key string) []TenantConfig {
+	if !viper.IsSet(key) {
+		return nil
+	}
+	var tenants []TenantConfig
+	if err := viper.UnmarshalKey(key, &tenants); err != nil {
+		return nil
+	}
+	for i := range tenants {
+		t := &tenants[i]
+		t.Repo, t.Config = expandHome(t.Repo), expandHome(t.Config)
+		t.WorktreeRoot, t.MemoryDir = expandHome(t.WorktreeRoot), expandHome(t.MemoryDir)
+	}
+	return tenants
+}
+
+// getMigrations returns the migration dry-run settings, or empty ones if
+// not set.
+func getMigrations(key string) MigrationConfig {
+	var migrations MigrationConfig
+	if viper.IsSet(key) {
+		viper.UnmarshalKey(key, &migrations)
+	}
+	return migrations
+}
+
+// getHooks returns the configured step hooks, or nil if not set.
+func getHooks(key string) []HookConfig {
+	if !viper.IsSet(key) {
+		return nil
+	}
+	var hooks []HookConfig
+	if err := viper.UnmarshalKey(key, &hooks); err != nil {
+		return nil
+	}
+	return hooks
+}
+
+// getContracts returns the Pact verification settings, or empty ones if
+// not set.
+func getContracts(key string) ContractConfig {
+	var contracts ContractConfig
+	if viper.IsSet(key) {
+		viper.UnmarshalKey(key, &contracts)
+	}
+	return contracts
+}
+
+// getEnsemble returns the review ensemble, filling in default severity
+// weights and threshold.
+func getEnsemble(key string) EnsembleConfig {
+	var ensemble EnsembleConfig
+	if viper.IsSet(key) {
+		viper.UnmarshalKey(key, &ensemble)
+	}
+	weights := map[string]float64{"critical": 10, "major": 3, "minor": 1}
+	for severity, weight := range ensemble.SeverityWeights {
+		weights[severity] = weight
+	}
+	ensemble.SeverityWeights = weights
+	if ensemble.Threshold <= 0 {
+		ensemble.Threshold = 10
+	}
+	return ensemble
+}
+
+// getSQLReview returns the SQL review settings, or empty ones if not set.
+func getSQLReview(key string) SQLReviewConfig {
+	var sql SQLReviewConfig
+	if viper.IsSet(key) {
+		viper.UnmarshalKey(key, &sql)
+	}
+	return sql
+}
+
+// getRubric returns the configured review rubric, or an empty rubric if not set.
+func getRubric(key string) RubricConfig {
+	var rubric RubricConfig
+	if viper.IsSet(key) {
+		viper.UnmarshalKey(key, &rubric)
+	}
+	return rubric
+}
+
+// getSkills returns the configured per-skill options, or nil if not set.
+// Viper lowercases map keys, so skill names are stored lowercase.
+func getSkills(key string) map[string]SkillConfig {
+	if !viper.IsSet(key) {
+		return nil
+	}
+	var skills map[string]SkillConfig
+	if err := viper.UnmarshalKey(key, &skills); err != nil {
+		return nil
+	}
+	return skills
+}
+
+// getSampling returns the configured sampling parameters per agent type,
+// or nil if not set.
+func getSampling(key string) map[string]SamplingConfig {
+	if !viper.IsSet(key) {
+		return nil
+	}
+	var sampling map[string]SamplingConfig
+	if err := viper.UnmarshalKey(key, &sampling); err != nil {
+		return nil
+	}
+	return sampling
+}
+
+// getStringSliceMap returns a viper map of string lists, or nil if not set.
+// Viper lowercases map keys.
+func getStringSliceMap(key string) map[string][]string {
+	if !viper.IsSet(key) {
+		return nil
+	}
+	var m map[string][]string
+	if err := viper.UnmarshalKey(key, &m); err != nil {
+		return nil
+	}
+	return m
+}
+
+// getStringMapOrNil returns a viper string map, or nil if not set.
+func getStringMapOrNil(key string) map[string]string {
+	if !viper.IsSet(key) {
+		return nil
+	}
+	return viper.GetStringMapString(key)
+}
+
 // getBoolOrDefault returns viper bool value or default if not set.
 func getBoolOrDefault(key string, defaultVal bool) bool {
 	if viper.IsSet(key) {
@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -94,6 +96,64 @@ func TestConfigValidate(t *testing.T) {
 	if err := cfg.Validate(); err == nil {
 		t.Error("Should error when Linear key is missing")
 	}
+
+	// Jira source needs Jira credentials, not a Linear key
+	cfg = &Config{Source: "jira", Jira: JiraConfig{URL: "https://acme.atlassian.net", Token: "token"}}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Valid Jira config should not error: %v", err)
+	}
+	cfg = &Config{Source: "jira", LinearKey: "test-key"}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "JIRA_URL") {
+		t.Errorf("Should error when Jira settings are missing, got %v", err)
+	}
+	cfg = &Config{Source: "github", LinearKey: "test-key"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Should error on an unknown source")
+	}
+	cfg = &Config{LinearKey: "test-key", RequireApproval: "merge"}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "require_approval") {
+		t.Errorf("Should error on an unknown approval gate, got %v", err)
+	}
+	cfg = &Config{LinearKey: "test-key", Claude: ClaudeConfig{Runner: "docker"}}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "claude.runner") {
+		t.Errorf("Should error on an unknown runner, got %v", err)
+	}
+	cfg = &Config{LinearKey: "test-key", E2E: E2EConfig{Framework: "selenium"}}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "e2e.framework") {
+		t.Errorf("Should error on an unknown E2E framework, got %v", err)
+	}
+	cfg = &Config{LinearKey: "test-key", Pipeline: []string{"execute", "refactor"}}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "requires review") {
+		t.Errorf("Should error on a pipeline missing a dependency, got %v", err)
+	}
+	cfg = &Config{LinearKey: "test-key", Review: ReviewConfig{SQL: SQLReviewConfig{Severity: "critcal"}}}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "review.sql.severity") {
+		t.Errorf("Should error on an unknown severity, got %v", err)
+	}
+	cfg = &Config{LinearKey: "test-key", Review: ReviewConfig{NewIssuePatterns: []IssuePattern{{Pattern: "TODO", Severity: "minor"}, {Pattern: "FIXME", Severity: "blocker"}}}}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "new_issue_patterns[1]") {
+		t.Errorf("Should error on an unknown issue pattern severity, got %v", err)
+	}
+	cfg = &Config{LinearKey: "test-key", Review: ReviewConfig{Ensemble: EnsembleConfig{SeverityWeights: map[string]float64{"critcal": 10}}}}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "severity_weights") {
+		t.Errorf("Should error on an unknown severity weight, got %v", err)
+	}
+	cfg = &Config{LinearKey: "test-key", Pipeline: []string{"execute", "lint", "commit"}}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("A lint stage without commands.lint should detect the linters, got %v", err)
+	}
+}
+
+func TestGitConfigValidate(t *testing.T) {
+	if err := (GitConfig{OnDiverged: "rebase", History: "plan-steps"}).Validate(); err != nil {
+		t.Errorf("Valid git config should not error: %v", err)
+	}
+	if err := (GitConfig{OnDiverged: "merge", History: "squash"}).Validate(); err == nil || !strings.Contains(err.Error(), "on_diverged") {
+		t.Errorf("Expected an on_diverged error, got %v", err)
+	}
+	if err := (GitConfig{OnDiverged: "fail", History: "rebase"}).Validate(); err == nil || !strings.Contains(err.Error(), "git.history") {
+		t.Errorf("Expected a history error, got %v", err)
+	}
 }
 
 func TestConfigDefaultValues(t *testing.T) {
@@ -127,6 +187,9 @@ func TestConfigDefaultValues(t *testing.T) {
 	if cfg.Review.StrictParsing != false {
 		t.Errorf("Expected StrictParsing false, got %v", cfg.Review.StrictParsing)
 	}
+	if cfg.Review.Differential {
+		t.Error("Expected Differential false by default")
+	}
 
 	// Coordinator defaults
 	if cfg.Coordinator.MessageBufferSize != 1000 {
@@ -154,6 +217,12 @@ func TestConfigDefaultValues(t *testing.T) {
 	if cfg.Claude.LargePromptThreshold != 100000 {
 		t.Errorf("Expected Claude.LargePromptThreshold 100000, got %d", cfg.Claude.LargePromptThreshold)
 	}
+	if cfg.Claude.Runner != "" {
+		t.Errorf("Expected Claude.Runner to be picked per machine, got %s", cfg.Claude.Runner)
+	}
+	if !cfg.Ingest.Images || !cfg.Ingest.URLs || cfg.Ingest.MaxTokens != 3000 {
+		t.Errorf("Expected ingest on with 3000 tokens, got %+v", cfg.Ingest)
+	}
 
 	// Token budget defaults
 	if cfg.TokenBudget.Context != 8000 {
@@ -301,3 +370,286 @@ func TestTokenBudgetConfig(t *testing.T) {
 		t.Errorf("Expected 4000, got %d", cfg.Review)
 	}
 }
+
+func TestGetIssuePatterns(t *testing.T) {
+	viper.Reset()
+
+	if patterns := getIssuePatterns("review.new_issue_patterns"); patterns != nil {
+		t.Errorf("Expected nil when unset, got %v", patterns)
+	}
+
+	viper.Set("review.new_issue_patterns", []map[string]any{
+		{"pattern": `console\.log\(`, "severity": "major", "message": "console.log left in code"},
+	})
+	patterns := getIssuePatterns("review.new_issue_patterns")
+	if len(patterns) != 1 {
+		t.Fatalf("Expected 1 pattern, got %d", len(patterns))
+	}
+	if patterns[0].Severity != "major" || patterns[0].Message != "console.log left in code" {
+		t.Errorf("Unexpected pattern: %+v", patterns[0])
+	}
+}
+
+func TestGetSkills(t *testing.T) {
+	viper.Reset()
+
+	if skills := getSkills("review.skills"); skills != nil {
+		t.Errorf("Expected nil skills when not set, got %v", skills)
+	}
+
+	viper.Set("review.skills", map[string]any{
+		"peer-review": map[string]any{
+			"args": []string{"--append-system-prompt", "strict"},
+			"env":  []string{"REVIEW_RULESET=strict"},
+		},
+	})
+
+	review := ReviewConfig{Skills: getSkills("review.skills")}
+	opts := review.SkillOptions("Peer-Review")
+	if len(opts.Args) != 2 || opts.Args[1] != "strict" {
+		t.Errorf("Expected args to be loaded, got %v", opts.Args)
+	}
+	if len(opts.Env) != 1 || opts.Env[0] != "REVIEW_RULESET=strict" {
+		t.Errorf("Expected env to be loaded, got %v", opts.Env)
+	}
+	if got := review.SkillOptions("other"); len(got.Args) != 0 || len(got.Env) != 0 {
+		t.Errorf("Expected empty options for unknown skill, got %+v", got)
+	}
+}
+
+func TestSkillConfigValidate(t *testing.T) {
+	os.Setenv("BOATMAN_TEST_TOKEN", "secret")
+	defer os.Unsetenv("BOATMAN_TEST_TOKEN")
+
+	valid := SkillConfig{
+		Args: []string{"--append-system-prompt", "strict"},
+		Env:  []string{"RULESET=strict", "TOKEN=${BOATMAN_TEST_TOKEN}"},
+	}
+	if errs := valid.Validate(); len(errs) != 0 {
+		t.Errorf("Expected no errors, got %v", errs)
+	}
+	if env := valid.ExpandedEnv(); env[1] != "TOKEN=secret" {
+		t.Errorf("Expected expanded env, got %v", env)
+	}
+
+	invalid := SkillConfig{
+		Args: []string{"--model=opus", "--agent"},
+		Env:  []string{"NOVALUE", "1BAD=x", "TOKEN=${BOATMAN_TEST_UNSET}"},
+	}
+	if errs := invalid.Validate(); len(errs) != 5 {
+		t.Errorf("Expected 5 errors, got %d: %v", len(errs), errs)
+	}
+}
+
+func TestGetTenants(t *testing.T) {
+	viper.Reset()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("BOATMAN_TEST_PAYMENTS_KEY", "lin_payments")
+
+	if tenants := getTenants("daemon.tenants"); tenants != nil {
+		t.Errorf("Expected no tenants when not set, got %v", tenants)
+	}
+	viper.Set("daemon.tenants", []any{
+		map[string]any{
+			"name":         "payments",
+			"repo":         "~/src/payments",
+			"team":         "PAY",
+			"max_parallel": 2,
+			"max_cost_usd": 5.0,
+			"memory_dir":   "~/.boatman/tenants/payments",
+			"env":          []string{"LINEAR_API_KEY=${BOATMAN_TEST_PAYMENTS_KEY}"},
+		},
+	})
+	tenants := getTenants("daemon.tenants")
+	if len(tenants) != 1 {
+		t.Fatalf("Expected one tenant, got %v", tenants)
+	}
+	payments := tenants[0]
+	if payments.Repo != filepath.Join(home, "src", "payments") || payments.MemoryDir != filepath.Join(home, ".boatman", "tenants", "payments") {
+		t.Errorf("Expected paths under home, got %+v", payments)
+	}
+	if payments.MaxCostUSD != 5 || payments.Getenv("LINEAR_API_KEY") != "lin_payments" {
+		t.Errorf("Expected budget and credentials to load, got %+v", payments)
+	}
+
+	d := payments.Apply(DaemonConfig{State: "Ready for AI", Team: "ENG", MaxParallel: 1, Tenants: tenants})
+	if d.Team != "PAY" || d.State != "Ready for AI" || d.MaxParallel != 2 || d.Tenants != nil {
+		t.Errorf("Expected the tenant's team and parallelism, got %+v", d)
+	}
+}
+
+func TestDaemonConfigValidate(t *testing.T) {
+	valid := DaemonConfig{Tenants: []TenantConfig{
+		{Name: "payments", Repo: "/src/payments", Env: []string{"LINEAR_API_KEY=x"}},
+		{Name: "web", Repo: "/src/web"},
+	}}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Valid tenants should not error: %v", err)
+	}
+	for _, tenants := range [][]TenantConfig{
+		{{Repo: "/src/web"}},
+		{{Name: "web", Repo: "/src/web"}, {Name: "web", Repo: "/src/web2"}},
+		{{Name: "web"}},
+		{{Name: "web", Repo: "/src/web", Env: []string{"NOVALUE"}}},
+	} {
+		if err := (DaemonConfig{Tenants: tenants}).Validate(); err == nil {
+			t.Errorf("Expected an error for %+v", tenants)
+		}
+	}
+}
+
+func TestGetEnsemble(t *testing.T) {
+	viper.Reset()
+	ensemble := getEnsemble("review.ensemble")
+	if len(ensemble.Reviewers) != 0 || ensemble.Threshold != 10 || ensemble.SeverityWeights["critical"] != 10 {
+		t.Errorf("Expected no reviewers and the defaults, got %+v", ensemble)
+	}
+
+	viper.Set("review.ensemble", map[string]any{
+		"reviewers": []any{
+			map[string]any{"name": "security", "skill": "security-review", "weight": 2},
+			map[string]any{"name": "style", "focus": "Naming and readability only."},
+		},
+		"severity_weights": map[string]any{"minor": 0.5},
+		"threshold":        12,
+	})
+	ensemble = getEnsemble("review.ensemble")
+	if len(ensemble.Reviewers) != 2 || ensemble.Reviewers[0].Skill != "security-review" || ensemble.Reviewers[0].Weight != 2 || ensemble.Reviewers[1].Focus == "" {
+		t.Errorf("Unexpected reviewers: %+v", ensemble.Reviewers)
+	}
+	if ensemble.Threshold != 12 || ensemble.SeverityWeights["minor"] != 0.5 || ensemble.SeverityWeights["major"] != 3 {
+		t.Errorf("Expected overrides merged with the defaults, got %+v", ensemble)
+	}
+	if err := ensemble.Validate(); err != nil {
+		t.Errorf("Valid ensemble should not error: %v", err)
+	}
+	for _, reviewers := range [][]ReviewerConfig{
+		{{Skill: "peer-review"}},
+		{{Name: "style"}, {Name: "style"}},
+		{{Name: "style", Weight: -1}},
+	} {
+		if err := (EnsembleConfig{Reviewers: reviewers}).Validate(); err == nil {
+			t.Errorf("Expected an error for %+v", reviewers)
+		}
+	}
+}
+
+func TestCommandPolicy(t *testing.T) {
+	viper.Reset()
+	cfg := LoadUnvalidated()
+	policy := cfg.Claude.CommandPolicy
+	if !policy.Enabled || strings.Join(policy.ProtectedBranches, ",") != "main,master" || policy.Roles != nil {
+		t.Errorf("Expected the policy on for main and master by default, got %+v", policy)
+	}
+
+	viper.Set("claude.command_policy.roles", map[string]any{"refactor": []string{`\bnpm\s+publish\b`}})
+	viper.Set("claude.command_policy.deny", []string{"terraform (apply"})
+	cfg = LoadUnvalidated()
+	if got := cfg.Claude.CommandPolicy.Roles["refactor"]; len(got) != 1 {
+		t.Errorf("Expected the refactor role's pattern, got %v", cfg.Claude.CommandPolicy.Roles)
+	}
+	if err := cfg.Claude.CommandPolicy.Validate(); err == nil || !strings.Contains(err.Error(), "claude.command_policy.deny") {
+		t.Errorf("Expected an invalid deny pattern to error, got %v", err)
+	}
+}
+
+func TestNetwork(t *testing.T) {
+	viper.Reset()
+	cfg := LoadUnvalidated()
+	if cfg.Network.Enabled || strings.Join(cfg.Network.Allow, ",") != "registries" || cfg.Network.Default != "deny" {
+		t.Errorf("Expected the policy off, allowing registries, by default, got %+v", cfg.Network)
+	}
+	if err := cfg.Network.Validate(); err != nil {
+		t.Errorf("Expected the defaults to validate, got %v", err)
+	}
+
+	for _, host := range []string{"https://github.com", "*github.com", "github.com:443"} {
+		viper.Set("network.allow", []string{"*.npmjs.org", host})
+		if err := LoadUnvalidated().Network.Validate(); err == nil {
+			t.Errorf("Expected %q to be rejected", host)
+		}
+	}
+	viper.Set("network.allow", []string{"*"})
+	viper.Set("network.default", "block")
+	if err := LoadUnvalidated().Network.Validate(); err == nil || !strings.Contains(err.Error(), "network.default") {
+		t.Errorf("Expected an invalid default to error, got %v", err)
+	}
+}
+
+func TestSampling(t *testing.T) {
+	viper.Reset()
+	if cfg := LoadUnvalidated(); cfg.Claude.Sampling != nil || !cfg.Claude.SamplingFor("reviewer").IsEmpty() {
+		t.Errorf("Expected no sampling parameters by default, got %+v", cfg.Claude.Sampling)
+	}
+
+	viper.Set("claude.sampling", map[string]any{
+		"reviewer": map[string]any{"temperature": 0, "seed": 42},
+		"executor": map[string]any{"temperature": 0.8, "top_p": 0.95},
+	})
+	cfg := LoadUnvalidated()
+	reviewer, executor := cfg.Claude.SamplingFor("reviewer"), cfg.Claude.SamplingFor("executor")
+	if reviewer.Temperature == nil || *reviewer.Temperature != 0 || reviewer.Seed == nil || *reviewer.Seed != 42 || reviewer.TopP != nil {
+		t.Errorf("Unexpected reviewer sampling: %+v", reviewer)
+	}
+	if executor.TopP == nil || *executor.TopP != 0.95 || executor.Seed != nil {
+		t.Errorf("Unexpected executor sampling: %+v", executor)
+	}
+	if err := cfg.Claude.ValidateSampling(); err != nil {
+		t.Errorf("Expected valid sampling, got %v", err)
+	}
+
+	invalid := map[string]string{
+		"critic":   "unknown agent type",
+		"executor": "temperature must be between 0 and 2",
+	}
+	for role, want := range invalid {
+		viper.Set("claude.sampling", map[string]any{role: map[string]any{"temperature": 3}})
+		if err := LoadUnvalidated().Claude.ValidateSampling(); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %q for %s, got %v", want, role, err)
+		}
+	}
+	viper.Set("claude.sampling", map[string]any{"test_runner": map[string]any{"temperature": 0}})
+	if err := LoadUnvalidated().Claude.ValidateSampling(); err == nil || !strings.Contains(err.Error(), "unknown agent type") {
+		t.Errorf("Expected test_runner to be rejected, got %v", err)
+	}
+	viper.Set("claude.sampling", map[string]any{"executor": map[string]any{"temperature": 1.5}})
+	if err := LoadUnvalidated().Claude.ValidateSampling(); err != nil {
+		t.Errorf("Expected a temperature of 1.5 to be valid by default, got %v", err)
+	}
+	viper.Set("provider.name", "anthropic")
+	if err := LoadUnvalidated().Claude.ValidateSampling(); err == nil || !strings.Contains(err.Error(), "between 0 and 1 with the anthropic provider") {
+		t.Errorf("Expected a temperature above 1 to error with the Anthropic API, got %v", err)
+	}
+	viper.Set("provider.name", "")
+	viper.Set("claude.sampling", map[string]any{"planner": map[string]any{"top_p": 1.5}})
+	if err := LoadUnvalidated().Claude.ValidateSampling(); err == nil || !strings.Contains(err.Error(), "claude.sampling.planner.top_p") {
+		t.Errorf("Expected an out of range top_p to error, got %v", err)
+	}
+}
+
+func TestSecretReferences(t *testing.T) {
+	viper.Reset()
+	t.Setenv("LINEAR_API_KEY", "")
+	viper.Set("linear_key", "vault:secret/boatman")
+
+	cfg := LoadUnvalidated()
+	if cfg.LinearKey != "" {
+		t.Errorf("Expected an unresolved reference to leave no key, got %q", cfg.LinearKey)
+	}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "linear_key: vault:secret/boatman needs a path and field") {
+		t.Errorf("Expected the reference's error, got %v", err)
+	}
+
+	// Variables the Claude CLI and gh read are resolved for them
+	viper.Reset()
+	t.Setenv("ANTHROPIC_API_KEY", "vault:secret/anthropic")
+	t.Setenv("GITHUB_TOKEN", "ghp_plain")
+	err := LoadUnvalidated().Validate()
+	if err == nil || !strings.Contains(err.Error(), "ANTHROPIC_API_KEY: vault:secret/anthropic needs a path and field") {
+		t.Errorf("Expected the variable's reference error, got %v", err)
+	}
+	if os.Getenv("ANTHROPIC_API_KEY") != "" || os.Getenv("GITHUB_TOKEN") != "ghp_plain" {
+		t.Errorf("Expected the reference cleared and plain tokens kept, got %q and %q", os.Getenv("ANTHROPIC_API_KEY"), os.Getenv("GITHUB_TOKEN"))
+	}
+}
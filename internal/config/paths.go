@@ -0,0 +1,136 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// RepoConfigFile is the per-repository config file name. Its settings
+// override the user config.
+const RepoConfigFile = ".boatman.yaml"
+
+// A daemon tenant's runs are isolated through their environment:
+// TenantConfigEnv names a config file merged over the repo config, and
+// WorktreeRootEnv overrides worktree.root.
+const (
+	TenantConfigEnv = "BOATMAN_TENANT_CONFIG"
+	WorktreeRootEnv = "BOATMAN_WORKTREE_ROOT"
+)
+
+// UserConfigPath returns the user config file: ~/.boatman/config.yaml,
+// or the legacy ~/.boatman.yaml when only that exists.
+func UserConfigPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(home, ".boatman", "config.yaml")
+	if !fileExists(path) {
+		if legacy := filepath.Join(home, RepoConfigFile); fileExists(legacy) {
+			return legacy, nil
+		}
+	}
+	return path, nil
+}
+
+// Files returns the existing config files to load, lowest precedence
+// first: the user config, dir's repo config, then the daemon tenant's
+// config named by TenantConfigEnv, which must exist.
+func Files(dir string) ([]string, error) {
+	user, err := UserConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	if fileExists(user) {
+		files = append(files, user)
+	}
+	repo, err := filepath.Abs(filepath.Join(dir, RepoConfigFile))
+	if err != nil {
+		return nil, err
+	}
+	if repo != user && fileExists(repo) {
+		files = append(files, repo)
+	}
+	if tenant := os.Getenv(TenantConfigEnv); tenant != "" {
+		if !fileExists(tenant) {
+			return nil, fmt.Errorf("tenant config %s not found", tenant)
+		}
+		files = append(files, tenant)
+	}
+	return files, nil
+}
+
+// expandHome expands a leading ~/ in path to the home directory.
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false
+	}
+	return err == nil && !info.IsDir()
+}
+
+// MatchProtected returns the changed files matching the configured
+// protected path patterns.
+func (c *Config) MatchProtected(files []string) []string {
+	var matched []string
+	for _, file := range files {
+		for _, pattern := range c.ProtectedPaths {
+			if MatchPathPattern(pattern, file) {
+				matched = append(matched, file)
+				break
+			}
+		}
+	}
+	return matched
+}
+
+// MatchPathPattern reports whether a repo-relative path matches a
+// CODEOWNERS-style pattern: "dir/" and "dir/**" match everything below
+// dir, a leading "/" anchors to the repo root, and a pattern without a
+// slash matches a file or directory name at any depth.
+func MatchPathPattern(pattern, file string) bool {
+	file = filepath.ToSlash(strings.TrimPrefix(file, "./"))
+	anchored := strings.HasPrefix(pattern, "/")
+	p := strings.TrimPrefix(pattern, "/")
+	p = strings.TrimSuffix(p, "**")
+	if p == "" {
+		return true
+	}
+
+	if strings.HasSuffix(p, "/") {
+		dir := strings.TrimSuffix(p, "/")
+		if anchored || strings.Contains(dir, "/") {
+			return strings.HasPrefix(file, dir+"/")
+		}
+		return slices.Contains(strings.Split(path.Dir(file), "/"), dir)
+	}
+
+	if !anchored && !strings.Contains(p, "/") {
+		for _, segment := range strings.Split(file, "/") {
+			if ok, _ := path.Match(p, segment); ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	if ok, _ := path.Match(p, file); ok {
+		return true
+	}
+	return strings.HasPrefix(file, p+"/")
+}
@@ -0,0 +1,111 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestUserConfigPath(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	newPath := filepath.Join(home, ".boatman", "config.yaml")
+	legacy := filepath.Join(home, ".boatman.yaml")
+
+	if got, _ := UserConfigPath(); got != newPath {
+		t.Errorf("Expected %s with no config, got %s", newPath, got)
+	}
+
+	os.WriteFile(legacy, []byte("base_branch: main\n"), 0644)
+	if got, _ := UserConfigPath(); got != legacy {
+		t.Errorf("Expected legacy %s when only it exists, got %s", legacy, got)
+	}
+
+	os.MkdirAll(filepath.Dir(newPath), 0755)
+	os.WriteFile(newPath, []byte("base_branch: main\n"), 0644)
+	if got, _ := UserConfigPath(); got != newPath {
+		t.Errorf("Expected %s to take precedence, got %s", newPath, got)
+	}
+}
+
+func TestFiles(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	repo := t.TempDir()
+
+	if files, _ := Files(repo); len(files) != 0 {
+		t.Errorf("Expected no files, got %v", files)
+	}
+
+	user := filepath.Join(home, ".boatman", "config.yaml")
+	os.MkdirAll(filepath.Dir(user), 0755)
+	os.WriteFile(user, []byte("max_iterations: 3\n"), 0644)
+	repoFile := filepath.Join(repo, RepoConfigFile)
+	os.WriteFile(repoFile, []byte("base_branch: develop\n"), 0644)
+
+	files, err := Files(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{user, repoFile}; !reflect.DeepEqual(files, want) {
+		t.Errorf("Expected %v, got %v", want, files)
+	}
+
+	// Running from home with only the legacy file must not load it twice
+	os.RemoveAll(filepath.Dir(user))
+	legacy := filepath.Join(home, RepoConfigFile)
+	os.WriteFile(legacy, []byte("max_iterations: 3\n"), 0644)
+	if files, _ := Files(home); !reflect.DeepEqual(files, []string{legacy}) {
+		t.Errorf("Expected only %s, got %v", legacy, files)
+	}
+}
+
+func TestMatchPathPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"/infra/", "infra/main.tf", true},
+		{"/infra/", "apps/infra/main.tf", false},
+		{"infra/", "apps/infra/main.tf", true},
+		{"db/migrate/**", "db/migrate/001_init.rb", true},
+		{"db/migrate/**", "app/db/migrate/001_init.rb", false},
+		{"*.sql", "schema/users.sql", true},
+		{"*.sql", "schema/users.go", false},
+		{"Gemfile.lock", "Gemfile.lock", true},
+		{"/config/secrets.yml", "config/secrets.yml", true},
+		{"/config/*.yml", "config/app.yml", true},
+		{"/config/*.yml", "config/env/app.yml", false},
+		{"/.github/workflows", ".github/workflows/ci.yml", true},
+	}
+	for _, tt := range tests {
+		if got := MatchPathPattern(tt.pattern, tt.path); got != tt.want {
+			t.Errorf("MatchPathPattern(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestMatchProtected(t *testing.T) {
+	cfg := &Config{ProtectedPaths: []string{"/infra/", "*.sql"}}
+	got := cfg.MatchProtected([]string{"infra/main.tf", "app/user.go", "db/schema.sql"})
+	if want := []string{"infra/main.tf", "db/schema.sql"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestFilesWithTenantConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	repo := t.TempDir()
+	tenant := filepath.Join(t.TempDir(), "payments.yaml")
+
+	t.Setenv(TenantConfigEnv, tenant)
+	if _, err := Files(repo); err == nil {
+		t.Error("Expected an error for a missing tenant config")
+	}
+	os.WriteFile(tenant, []byte("max_iterations: 5\n"), 0644)
+	if files, err := Files(repo); err != nil || !reflect.DeepEqual(files, []string{tenant}) {
+		t.Errorf("Expected tenant config %s, got %v (%v)", tenant, files, err)
+	}
+}
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SetValue sets a dotted key (e.g. "telemetry.enabled") in the YAML config
+// file at path, creating the file and intermediate sections as needed.
+// Comments and other settings in the file are preserved.
+func SetValue(path, key string, value any) error {
+	var doc yaml.Node
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s: top level is not a mapping", path)
+	}
+
+	var valueNode yaml.Node
+	if err := valueNode.Encode(value); err != nil {
+		return err
+	}
+
+	parts := strings.Split(key, ".")
+	node := root
+	for i, part := range parts {
+		child := mappingValue(node, part)
+		if i == len(parts)-1 {
+			if child != nil {
+				valueNode.HeadComment, valueNode.LineComment = child.HeadComment, child.LineComment
+				*child = valueNode
+			} else {
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: part}, &valueNode)
+			}
+			break
+		}
+		if child == nil || child.Kind != yaml.MappingNode {
+			section := &yaml.Node{Kind: yaml.MappingNode}
+			if child != nil {
+				*child = *section
+				section = child
+			} else {
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: part}, section)
+			}
+			child = section
+		}
+		node = child
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return err
+	}
+	enc.Close()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, buf.Bytes(), 0600)
+}
+
+// mappingValue returns the value node for key in a mapping node, or nil.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSetValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".boatman.yaml")
+	os.WriteFile(path, []byte("# Team config\nbase_branch: develop # trunk\ntelemetry:\n  enabled: false\n"), 0644)
+
+	if err := SetValue(path, "telemetry.enabled", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetValue(path, "review.rubric.weights.tests", 3); err != nil {
+		t.Fatal(err)
+	}
+
+	data, _ := os.ReadFile(path)
+	content := string(data)
+	for _, want := range []string{"# Team config", "base_branch: develop # trunk", "enabled: true", "weights:\n      tests: 3"} {
+		if !strings.Contains(content, want) {
+			t.Errorf("Expected %q in:\n%s", want, content)
+		}
+	}
+}
+
+func TestSetValueCreatesFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "config.yaml")
+
+	if err := SetValue(path, "base_branch", "main"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.TrimSpace(string(data)) != "base_branch: main" {
+		t.Errorf("Unexpected content: %q", data)
+	}
+}
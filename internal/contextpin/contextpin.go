@@ -165,6 +165,45 @@ func (cp *ContextPinner) GetDependents(file string) []string {
 	return cp.graph.dependents[file]
 }
 
+// Rename records that a file moved from one path to another. Its edges in
+// the dependency graph and its place in pins move with it, so files that
+// imported the old path stay related to the moved file and are pinned
+// alongside it.
+func (cp *ContextPinner) Rename(from, to string) {
+	g := cp.graph
+	g.mu.Lock()
+	for _, dep := range g.dependencies[from] {
+		g.dependencies[to] = appendUnique(g.dependencies[to], dep)
+		g.dependents[dep] = replacePath(g.dependents[dep], from, to)
+	}
+	for _, dep := range g.dependents[from] {
+		g.dependents[to] = appendUnique(g.dependents[to], dep)
+		g.dependencies[dep] = replacePath(g.dependencies[dep], from, to)
+	}
+	delete(g.dependencies, from)
+	delete(g.dependents, from)
+	if sum, ok := g.checksums[from]; ok {
+		g.checksums[to] = sum
+		delete(g.checksums, from)
+	}
+	g.mu.Unlock()
+
+	cp.pinsMu.Lock()
+	for _, pin := range cp.pins {
+		pin.Files = replacePath(pin.Files, from, to)
+		// Pin-time state stays, so VerifyPin reports a move with edits
+		if sum, ok := pin.Checksums[from]; ok {
+			pin.Checksums[to] = sum
+			delete(pin.Checksums, from)
+		}
+		if content, ok := pin.Contents[from]; ok {
+			pin.Contents[to] = content
+			delete(pin.Contents, from)
+		}
+	}
+	cp.pinsMu.Unlock()
+}
+
 // Pin creates a context pin for a set of files.
 // If lock is true, files are locked for exclusive access.
 func (cp *ContextPinner) Pin(agentID string, files []string, lock bool) (*Pin, error) {
@@ -401,6 +440,18 @@ func appendUnique(slice []string, item string) []string {
 	return append(slice, item)
 }
 
+// replacePath replaces from with to in paths, keeping paths unique.
+func replacePath(paths []string, from, to string) []string {
+	out := make([]string, 0, len(paths))
+	for _, p := range paths {
+		if p == from {
+			p = to
+		}
+		out = appendUnique(out, p)
+	}
+	return out
+}
+
 // checksum computes a simple checksum of content.
 func checksum(content []byte) string {
 	// Simple FNV-1a hash
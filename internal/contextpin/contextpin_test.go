@@ -370,3 +370,42 @@ func TestPinHandoff(t *testing.T) {
 		t.Error("ForTokenBudget() should return content")
 	}
 }
+
+func TestRename(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.MkdirAll(filepath.Join(tmpDir, "lib"), 0755)
+	for _, file := range []string{"app.js", "lib/util.js", "lib/base.js"} {
+		os.WriteFile(filepath.Join(tmpDir, file), []byte("export {}"), 0644)
+	}
+
+	pinner := New(tmpDir)
+	pinner.graph.dependencies["app.js"] = []string{"lib/util.js"}
+	pinner.graph.dependents["lib/util.js"] = []string{"app.js"}
+	pinner.graph.dependencies["lib/util.js"] = []string{"lib/base.js"}
+	pinner.graph.dependents["lib/base.js"] = []string{"lib/util.js"}
+	pinner.Pin("executor", []string{"lib/util.js"}, false)
+
+	os.Rename(filepath.Join(tmpDir, "lib", "util.js"), filepath.Join(tmpDir, "lib", "helpers.js"))
+	pinner.Rename("lib/util.js", "lib/helpers.js")
+
+	if deps := pinner.GetDependents("lib/helpers.js"); len(deps) != 1 || deps[0] != "app.js" {
+		t.Errorf("Expected the old path's importers to follow the move, got %v", deps)
+	}
+	if deps := pinner.GetDependencies("app.js"); len(deps) != 1 || deps[0] != "lib/helpers.js" {
+		t.Errorf("Expected the importer to depend on the new path, got %v", deps)
+	}
+	if deps := pinner.GetDependents("lib/base.js"); len(deps) != 1 || deps[0] != "lib/helpers.js" {
+		t.Errorf("Expected the moved file's imports to follow the move, got %v", deps)
+	}
+	if deps := pinner.GetDependents("lib/util.js"); len(deps) != 0 {
+		t.Errorf("Expected no edges left on the old path, got %v", deps)
+	}
+
+	// An unedited move still verifies
+	if ok, changed := pinner.VerifyPin("executor"); !ok {
+		t.Errorf("Expected the moved file to match its pin, changed: %v", changed)
+	}
+	if content, ok := pinner.GetPinnedContent("executor", "lib/helpers.js"); !ok || content != "export {}" {
+		t.Errorf("Expected pinned content under the new path, got %q", content)
+	}
+}
@@ -0,0 +1,165 @@
+// Package contracts runs Pact provider verification when a change touches
+// the provider's API handlers. Each consumer whose pact breaks becomes a
+// critical review issue, so the PR cannot break a client unnoticed.
+package contracts
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/cmdoutput"
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/scottbott"
+	"github.com/philjestin/boatmanmode/internal/unidiff"
+)
+
+// defaultHandlers match the API handler directories of Rails, Express,
+// Go and most other web frameworks.
+var defaultHandlers = []string{"controllers/", "handlers/", "routes/", "api/"}
+
+// Verifier verifies a worktree's provider against its pacts.
+type Verifier struct {
+	worktreePath string
+	cfg          config.ContractConfig
+}
+
+// New creates a Verifier. Returns nil when no verify command is configured.
+func New(worktreePath string, cfg config.ContractConfig) *Verifier {
+	if cfg.Verify == "" {
+		return nil
+	}
+	if len(cfg.Handlers) == 0 {
+		cfg.Handlers = defaultHandlers
+	}
+	return &Verifier{worktreePath: worktreePath, cfg: cfg}
+}
+
+// Handlers returns the API handler files the diff changes, in diff order.
+func (v *Verifier) Handlers(diff string) []string {
+	var files []string
+	for _, f := range unidiff.Parse(diff) {
+		for _, pattern := range v.cfg.Handlers {
+			if config.MatchPathPattern(pattern, f.Path()) {
+				files = append(files, f.Path())
+				break
+			}
+		}
+	}
+	return files
+}
+
+// Failure is an interaction of a consumer's pact the provider no longer
+// honours.
+type Failure struct {
+	Consumer    string
+	Interaction string
+}
+
+// failureLine matches the failure summaries of pact-js, pact-ruby,
+// pact-jvm and pact_verifier_cli: "1) Verifying a pact between Web and
+// Users - a request for a user ...".
+var failureLine = regexp.MustCompile(`^\s*\d+\)\s+Verifying a pact between (\S+) and \S+\s*(.*)$`)
+
+// Verify runs the verify command, returning the broken interactions. A
+// command that fails without verifying a pact, such as a provider that
+// does not start, is an error.
+func (v *Verifier) Verify(ctx context.Context) ([]Failure, error) {
+	cmd := exec.CommandContext(ctx, "sh", "-c", v.cfg.Verify)
+	cmd.Dir = v.worktreePath
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+	if err == nil {
+		return nil, nil
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	output := out.String()
+	failures := parseFailures(output)
+	if len(failures) > 0 {
+		return failures, nil
+	}
+	if strings.Contains(output, "Verifying a pact between") {
+		return []Failure{{Interaction: cmdoutput.Tail(output, 15)}}, nil
+	}
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		return nil, fmt.Errorf("%s failed without verifying a pact: %s", v.cfg.Verify, cmdoutput.Tail(output, 15))
+	}
+	return nil, fmt.Errorf("%s could not run: %w", v.cfg.Verify, err)
+}
+
+// Check verifies the provider when the diff changes its handlers,
+// returning a critical issue per consumer whose pact breaks.
+func (v *Verifier) Check(ctx context.Context, diff string) ([]scottbott.Issue, error) {
+	handlers := v.Handlers(diff)
+	if len(handlers) == 0 {
+		return nil, nil
+	}
+	failures, err := v.Verify(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return Issues(failures, handlers[0]), nil
+}
+
+// Issues groups failures by consumer into critical issues against file.
+func Issues(failures []Failure, file string) []scottbott.Issue {
+	var consumers []string
+	broken := make(map[string][]string)
+	for _, f := range failures {
+		if _, ok := broken[f.Consumer]; !ok {
+			consumers = append(consumers, f.Consumer)
+		}
+		if f.Interaction != "" {
+			broken[f.Consumer] = append(broken[f.Consumer], f.Interaction)
+		}
+	}
+
+	var issues []scottbott.Issue
+	for _, consumer := range consumers {
+		description := "Breaks a consumer's pact: " + strings.Join(broken[consumer], "; ")
+		suggestion := "Keep the API compatible with what its consumers expect"
+		if consumer != "" {
+			description = fmt.Sprintf("Breaks the pact with %s: %s", consumer, strings.Join(broken[consumer], "; "))
+			suggestion = fmt.Sprintf("Keep the API compatible with what %s expects, or agree a new contract with its owners first", consumer)
+		}
+		issues = append(issues, scottbott.Issue{
+			Severity:    "critical",
+			File:        file,
+			Description: description,
+			Suggestion:  suggestion,
+		})
+	}
+	return issues
+}
+
+// parseFailures reads the numbered failure summary verifiers print at the
+// end of a run.
+func parseFailures(output string) []Failure {
+	var failures []Failure
+	seen := make(map[Failure]bool)
+	for _, line := range strings.Split(output, "\n") {
+		m := failureLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		f := Failure{
+			Consumer:    m[1],
+			Interaction: strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(m[2]), "-")),
+		}
+		if !seen[f] {
+			seen[f] = true
+			failures = append(failures, f)
+		}
+	}
+	return failures
+}
@@ -0,0 +1,111 @@
+package contracts
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+)
+
+const diff = `diff --git a/app/controllers/users_controller.rb b/app/controllers/users_controller.rb
+--- a/app/controllers/users_controller.rb
++++ b/app/controllers/users_controller.rb
+@@ -1 +1 @@
+-render json: { name: user.name }
++render json: { full_name: user.name }
+diff --git a/app/models/user.rb b/app/models/user.rb
+--- a/app/models/user.rb
++++ b/app/models/user.rb
+@@ -1 +1 @@
+-class User
++class User < ApplicationRecord
+`
+
+const verifierOutput = `Verifying a pact between WebApp and UserService
+  a request for a user
+    returns a response which
+      has status code 200 (OK)
+      includes fields
+        "name" with value "Ada" (FAILED)
+
+Failures:
+
+1) Verifying a pact between WebApp and UserService Given a user exists - a request for a user
+    1.1) body: $ Actual map is missing the following keys: name
+2) Verifying a pact between MobileApp and UserService - a request for the current user
+`
+
+func TestNew(t *testing.T) {
+	if v := New(t.TempDir(), config.ContractConfig{Handlers: []string{"api/"}}); v != nil {
+		t.Errorf("New without verify = %v, want nil", v)
+	}
+}
+
+func TestHandlers(t *testing.T) {
+	v := New(t.TempDir(), config.ContractConfig{Verify: "make pact"})
+	if files := v.Handlers(diff); len(files) != 1 || files[0] != "app/controllers/users_controller.rb" {
+		t.Errorf("Handlers = %v", files)
+	}
+	v = New(t.TempDir(), config.ContractConfig{Verify: "make pact", Handlers: []string{"app/models/"}})
+	if files := v.Handlers(diff); len(files) != 1 || files[0] != "app/models/user.rb" {
+		t.Errorf("Handlers with patterns = %v", files)
+	}
+}
+
+func TestParseFailures(t *testing.T) {
+	failures := parseFailures(verifierOutput)
+	if len(failures) != 2 {
+		t.Fatalf("failures = %+v", failures)
+	}
+	if failures[0].Consumer != "WebApp" || failures[0].Interaction != "Given a user exists - a request for a user" {
+		t.Errorf("failures[0] = %+v", failures[0])
+	}
+	if failures[1].Consumer != "MobileApp" || failures[1].Interaction != "a request for the current user" {
+		t.Errorf("failures[1] = %+v", failures[1])
+	}
+}
+
+func TestIssues(t *testing.T) {
+	issues := Issues([]Failure{
+		{Consumer: "WebApp", Interaction: "a request for a user"},
+		{Consumer: "WebApp", Interaction: "a request for users"},
+		{Consumer: "MobileApp", Interaction: "a login"},
+	}, "app/controllers/users_controller.rb")
+	if len(issues) != 2 || issues[0].Severity != "critical" || issues[0].File != "app/controllers/users_controller.rb" {
+		t.Fatalf("issues = %+v", issues)
+	}
+	if issues[0].Description != "Breaks the pact with WebApp: a request for a user; a request for users" {
+		t.Errorf("description = %q", issues[0].Description)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "output"), []byte(verifierOutput), 0644)
+	v := New(dir, config.ContractConfig{Verify: "cat output; exit 1"})
+	issues, err := v.Check(context.Background(), diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 2 || !strings.Contains(issues[1].Description, "MobileApp") {
+		t.Errorf("issues = %+v", issues)
+	}
+
+	// Changes outside the handlers are not verified
+	if issues, err := v.Check(context.Background(), diff[strings.Index(diff, "diff --git a/app/models"):]); err != nil || issues != nil {
+		t.Errorf("Check without handler changes = %+v, %v", issues, err)
+	}
+
+	// A provider that does not start cannot be verified
+	v = New(dir, config.ContractConfig{Verify: "echo 'connection refused'; exit 1"})
+	if _, err := v.Check(context.Background(), diff); err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("err = %v", err)
+	}
+	v = New(dir, config.ContractConfig{Verify: "true"})
+	if issues, err := v.Check(context.Background(), diff); err != nil || len(issues) != 0 {
+		t.Errorf("passing verification = %+v, %v", issues, err)
+	}
+}
@@ -15,6 +15,7 @@ const (
 	CapReview     AgentCapability = "review"
 	CapRefactor   AgentCapability = "refactor"
 	CapTest       AgentCapability = "test"
+	CapLint       AgentCapability = "lint"
 	CapValidate   AgentCapability = "validate"
 	CapVerifyDiff AgentCapability = "verify_diff"
 )
@@ -42,6 +42,7 @@ type StepUsage struct {
 type Tracker struct {
 	steps []StepUsage
 	mu    sync.Mutex
+	onAdd func(step string, usage, total Usage)
 }
 
 // NewTracker creates a new cost tracker.
@@ -51,11 +52,27 @@ func NewTracker() *Tracker {
 	}
 }
 
+// OnAdd sets a function called with each step's usage and the new total
+// after it is recorded.
+func (t *Tracker) OnAdd(fn func(step string, usage, total Usage)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.onAdd = fn
+}
+
 // Add records usage for a named step.
 func (t *Tracker) Add(step string, usage Usage) {
 	t.mu.Lock()
-	defer t.mu.Unlock()
 	t.steps = append(t.steps, StepUsage{Step: step, Usage: usage})
+	fn := t.onAdd
+	var total Usage
+	for _, s := range t.steps {
+		total = total.Add(s.Usage)
+	}
+	t.mu.Unlock()
+	if fn != nil {
+		fn(step, usage, total)
+	}
 }
 
 // Steps returns all recorded step usages.
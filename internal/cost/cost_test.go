@@ -154,6 +154,26 @@ func TestTracker_Total(t *testing.T) {
 	}
 }
 
+func TestTracker_OnAdd(t *testing.T) {
+	tracker := NewTracker()
+	var steps []string
+	var last Usage
+	tracker.OnAdd(func(step string, usage, total Usage) {
+		steps = append(steps, step)
+		last = total
+	})
+
+	tracker.Add("Planning", Usage{InputTokens: 100, TotalCostUSD: 0.01})
+	tracker.Add("Execution", Usage{InputTokens: 200, TotalCostUSD: 0.02})
+
+	if len(steps) != 2 || steps[1] != "Execution" {
+		t.Errorf("OnAdd steps = %v, want [Planning Execution]", steps)
+	}
+	if last.InputTokens != 300 || !floatEquals(last.TotalCostUSD, 0.03) {
+		t.Errorf("OnAdd total = %+v, want 300 tokens and $0.03", last)
+	}
+}
+
 func TestTracker_HasUsage(t *testing.T) {
 	tracker := NewTracker()
 
@@ -0,0 +1,223 @@
+// Package coverage reads per-file test coverage and holds the files a
+// change touches to a minimum, compared with their coverage before the
+// change.
+package coverage
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/scottbott"
+)
+
+// LCOVPaths are where test runs are looked for lcov reports, relative to
+// the project: jest's default, and the usual places for other tools.
+var LCOVPaths = []string{"coverage/lcov.info", "lcov.info", "coverage.lcov"}
+
+// File is a file's coverage, in statements for Go and lines for lcov.
+type File struct {
+	Covered int
+	Total   int
+}
+
+// Percent returns the covered share, 0-100. A file with nothing to cover
+// is fully covered.
+func (f File) Percent() float64 {
+	if f.Total == 0 {
+		return 100
+	}
+	return 100 * float64(f.Covered) / float64(f.Total)
+}
+
+// Profile maps project-relative paths to their coverage.
+type Profile map[string]File
+
+// ParseGo reads a go test -coverprofile file. Paths are import paths,
+// made relative by stripping modulePath.
+func ParseGo(data []byte, modulePath string) Profile {
+	type block struct {
+		file, pos string
+	}
+	blocks := make(map[block]int) // Statements, negative until covered
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "mode:") {
+			continue
+		}
+		// name.go:10.2,12.16 3 1
+		colon := strings.LastIndex(line, ":")
+		fields := strings.Fields(line[colon+1:])
+		if colon < 0 || len(fields) != 3 {
+			continue
+		}
+		statements, err1 := strconv.Atoi(fields[1])
+		count, err2 := strconv.Atoi(fields[2])
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		file := strings.TrimPrefix(strings.TrimPrefix(line[:colon], modulePath), "/")
+		b := block{file, fields[0]}
+		// Blocks repeat once per test binary covering them
+		if count > 0 {
+			blocks[b] = statements
+		} else if _, ok := blocks[b]; !ok {
+			blocks[b] = -statements
+		}
+	}
+
+	profile := make(Profile)
+	for b, statements := range blocks {
+		f := profile[b.file]
+		if statements > 0 {
+			f.Covered += statements
+			f.Total += statements
+		} else {
+			f.Total -= statements
+		}
+		profile[b.file] = f
+	}
+	return profile
+}
+
+// ParseLCOV reads an lcov report. Paths are made relative to root.
+func ParseLCOV(data []byte, root string) Profile {
+	profile := make(Profile)
+	var file string
+	var f File
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		key, value, _ := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+		switch key {
+		case "SF":
+			file, f = relative(value, root), File{}
+		case "LF":
+			f.Total, _ = strconv.Atoi(value)
+		case "LH":
+			f.Covered, _ = strconv.Atoi(value)
+		case "end_of_record":
+			if file != "" {
+				profile[file] = f
+			}
+			file = ""
+		}
+	}
+	return profile
+}
+
+// ModulePath returns the module path in dir's go.mod, or "".
+func ModulePath(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if rest, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+			return strings.Trim(strings.TrimSpace(rest), `"`)
+		}
+	}
+	return ""
+}
+
+// Stamps returns when each lcov report in dir was last written, to tell
+// the reports of a run from ones left by earlier runs.
+func Stamps(dir string) map[string]time.Time {
+	stamps := make(map[string]time.Time)
+	for _, path := range LCOVPaths {
+		if info, err := os.Stat(filepath.Join(dir, path)); err == nil {
+			stamps[path] = info.ModTime()
+		}
+	}
+	return stamps
+}
+
+// FindLCOV reads the first lcov report in dir written since stamps were
+// taken, or returns nil.
+func FindLCOV(dir string, stamps map[string]time.Time) Profile {
+	for _, path := range LCOVPaths {
+		full := filepath.Join(dir, path)
+		info, err := os.Stat(full)
+		if err != nil {
+			continue
+		}
+		if stamp, ok := stamps[path]; ok && info.ModTime().Equal(stamp) {
+			continue
+		}
+		if data, err := os.ReadFile(full); err == nil {
+			return ParseLCOV(data, dir)
+		}
+	}
+	return nil
+}
+
+func relative(path, root string) string {
+	path = filepath.Clean(path)
+	if filepath.IsAbs(path) {
+		if rel, err := filepath.Rel(root, path); err == nil && !strings.HasPrefix(rel, "..") {
+			path = rel
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
+// Finding is a changed file whose coverage is under the minimum.
+type Finding struct {
+	File   string
+	Before float64
+	New    bool // No coverage before the change
+	After  float64
+}
+
+// Check returns the changed files covered below min. A file that was
+// already below it only counts when its coverage dropped, so a change is
+// not held to more than the code it touched had. Files without coverage,
+// such as tests and docs, are skipped.
+func Check(baseline, current Profile, changed []string, min float64) []Finding {
+	var findings []Finding
+	for _, file := range changed {
+		after, ok := current[file]
+		if !ok || after.Percent() >= min {
+			continue
+		}
+		before, existed := baseline[file]
+		if existed && before.Percent() < min && after.Percent() >= before.Percent() {
+			continue
+		}
+		findings = append(findings, Finding{
+			File:   file,
+			Before: before.Percent(),
+			New:    !existed,
+			After:  after.Percent(),
+		})
+	}
+	return findings
+}
+
+// String describes the finding.
+func (f Finding) String() string {
+	if f.New {
+		return fmt.Sprintf("%s is %.1f%% covered", f.File, f.After)
+	}
+	return fmt.Sprintf("%s is %.1f%% covered (was %.1f%%)", f.File, f.After, f.Before)
+}
+
+// Issues returns a major issue per finding, so the refactor loop adds the
+// missing tests.
+func Issues(findings []Finding, min float64) []scottbott.Issue {
+	var issues []scottbott.Issue
+	for _, f := range findings {
+		issues = append(issues, scottbott.Issue{
+			Severity:    "major",
+			File:        f.File,
+			Description: fmt.Sprintf("Test coverage below min_coverage (%.0f%%): %s", min, f),
+			Suggestion:  "Add tests exercising the code this change adds or modifies in " + f.File,
+		})
+	}
+	return issues
+}
@@ -0,0 +1,94 @@
+package coverage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseGo(t *testing.T) {
+	profile := ParseGo([]byte(`mode: set
+example.com/app/pkg/util/util.go:5.28,7.2 1 1
+example.com/app/pkg/util/util.go:9.28,11.2 2 0
+example.com/app/pkg/util/util.go:9.28,11.2 2 1
+example.com/app/pkg/util/util.go:13.28,15.2 1 0
+example.com/app/main.go:3.13,5.2 4 0
+`), "example.com/app")
+	if f := profile["pkg/util/util.go"]; f.Covered != 3 || f.Total != 4 || f.Percent() != 75 {
+		t.Errorf("util.go = %+v", f)
+	}
+	if f := profile["main.go"]; f.Covered != 0 || f.Total != 4 {
+		t.Errorf("main.go = %+v", f)
+	}
+}
+
+func TestParseLCOV(t *testing.T) {
+	root := t.TempDir()
+	profile := ParseLCOV([]byte("TN:\nSF:"+filepath.Join(root, "src/app.js")+"\nDA:1,1\nLF:8\nLH:6\nend_of_record\nSF:lib/util.py\nLF:0\nLH:0\nend_of_record\n"), root)
+	if f := profile["src/app.js"]; f.Covered != 6 || f.Total != 8 {
+		t.Errorf("profile = %+v", profile)
+	}
+	if f, ok := profile["lib/util.py"]; !ok || f.Percent() != 100 {
+		t.Errorf("empty file = %+v", f)
+	}
+}
+
+func TestFindLCOV(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "coverage"), 0755)
+	report := filepath.Join(dir, "coverage/lcov.info")
+	os.WriteFile(report, []byte("SF:a.js\nLF:2\nLH:1\nend_of_record\n"), 0644)
+	stamps := Stamps(dir)
+	if profile := FindLCOV(dir, stamps); profile != nil {
+		t.Errorf("FindLCOV of a stale report = %v", profile)
+	}
+	os.Chtimes(report, time.Now(), time.Now().Add(time.Second))
+	if profile := FindLCOV(dir, stamps); profile["a.js"].Total != 2 {
+		t.Errorf("FindLCOV = %v", profile)
+	}
+}
+
+func TestModulePath(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("// app\nmodule example.com/app\n\ngo 1.23\n"), 0644)
+	if got := ModulePath(dir); got != "example.com/app" {
+		t.Errorf("ModulePath = %q", got)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	baseline := Profile{
+		"kept.go":    {Covered: 9, Total: 10},
+		"dropped.go": {Covered: 9, Total: 10},
+		"legacy.go":  {Covered: 2, Total: 10},
+		"worse.go":   {Covered: 5, Total: 10},
+	}
+	current := Profile{
+		"kept.go":    {Covered: 17, Total: 20},
+		"dropped.go": {Covered: 6, Total: 10},
+		"legacy.go":  {Covered: 3, Total: 10},
+		"worse.go":   {Covered: 4, Total: 10},
+		"new.go":     {Covered: 1, Total: 10},
+		"other.go":   {Covered: 0, Total: 10},
+	}
+	changed := []string{"kept.go", "dropped.go", "legacy.go", "worse.go", "new.go", "README.md"}
+	var got []string
+	for _, f := range Check(baseline, current, changed, 80) {
+		got = append(got, f.String())
+	}
+	want := []string{
+		"dropped.go is 60.0% covered (was 90.0%)",
+		"worse.go is 40.0% covered (was 50.0%)",
+		"new.go is 10.0% covered",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Check = %q, want %q", got, want)
+	}
+
+	issues := Issues(Check(baseline, current, []string{"new.go"}, 80), 80)
+	if len(issues) != 1 || issues[0].Severity != "major" || issues[0].File != "new.go" || !strings.Contains(issues[0].Description, "min_coverage (80%)") {
+		t.Errorf("Issues = %+v", issues)
+	}
+}
@@ -0,0 +1,310 @@
+// Package daemon runs boatman unattended: it polls Linear for tickets that
+// are ready for it, or takes them from webhooks, queues them, works through
+// them a few at a time, and hands each ticket back for review with a link
+// to its PR.
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/linear"
+	"github.com/philjestin/boatmanmode/internal/queue"
+)
+
+// ErrNoFilter is returned when neither a state nor a label selects the
+// tickets to work on.
+var ErrNoFilter = errors.New("no tickets selected (set daemon.state or daemon.label)")
+
+// Tracker is the Linear access the daemon needs.
+type Tracker interface {
+	ListTickets(ctx context.Context, filter linear.Filter) ([]linear.Ticket, error)
+	Comment(ctx context.Context, issue, body string) error
+	MoveToState(ctx context.Context, issue, state string) error
+}
+
+// Runner works on a ticket and returns the URL of the PR it opened.
+type Runner func(ctx context.Context, ticketID string) (prURL string, err error)
+
+// Daemon polls for ready tickets and runs them.
+type Daemon struct {
+	cfg     config.DaemonConfig
+	poll    bool
+	tracker Tracker
+	run     Runner
+	queue   *queue.Queue
+	log     func(format string, args ...any)
+	wait    func(ctx context.Context, d time.Duration, wake <-chan struct{}) error
+	now     func() time.Time
+
+	mu      sync.Mutex
+	active  map[string]bool // Tickets running
+	seen    map[string]bool // Tickets polled since the daemon started
+	stats   Stats
+	running sync.WaitGroup
+	// wake is signalled when a run ends or a ticket is enqueued, so the
+	// next can start
+	wake chan struct{}
+}
+
+// New creates a Daemon that lists tickets from tracker and works on them
+// with run.
+func New(cfg config.DaemonConfig, tracker Tracker, run Runner) (*Daemon, error) {
+	if cfg.State == "" && cfg.Label == "" {
+		return nil, ErrNoFilter
+	}
+	d, err := NewWorker(cfg, tracker, run)
+	if err != nil {
+		return nil, err
+	}
+	d.poll = true
+	return d, nil
+}
+
+// NewWorker creates a Daemon that doesn't poll: it only works on the
+// tickets given to Enqueue, reporting on them through tracker. A nil
+// tracker only logs the outcomes.
+func NewWorker(cfg config.DaemonConfig, tracker Tracker, run Runner) (*Daemon, error) {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 5 * time.Minute
+	}
+	if cfg.MaxParallel <= 0 {
+		cfg.MaxParallel = 1
+	}
+	q, err := queue.New(cfg.Schedule)
+	if err != nil {
+		return nil, err
+	}
+	return &Daemon{
+		cfg:     cfg,
+		tracker: tracker,
+		run:     run,
+		queue:   q,
+		log:     func(format string, args ...any) { fmt.Printf(format+"\n", args...) },
+		wait:    wait,
+		now:     time.Now,
+		active:  make(map[string]bool),
+		seen:    make(map[string]bool),
+		wake:    make(chan struct{}, 1),
+	}, nil
+}
+
+// Stats counts a daemon's tickets.
+type Stats struct {
+	Queued    int `json:"queued"`    // Waiting to start
+	Active    int `json:"active"`    // Running
+	Succeeded int `json:"succeeded"` // Opened a PR since the daemon started
+	Failed    int `json:"failed"`    // Failed since the daemon started
+}
+
+// SetName prefixes the daemon's log lines with name, to tell apart the
+// daemons of several tenants.
+func (d *Daemon) SetName(name string) {
+	log := d.log
+	d.log = func(format string, args ...any) { log("[%s] "+format, append([]any{name}, args...)...) }
+}
+
+// Stats returns the daemon's ticket counts.
+func (d *Daemon) Stats() Stats {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	stats := d.stats
+	stats.Queued = d.queue.Len()
+	stats.Active = len(d.active)
+	return stats
+}
+
+// Run polls and starts tickets until ctx is done, then waits for the
+// tickets already running and returns.
+func (d *Daemon) Run(ctx context.Context) error {
+	if d.poll {
+		d.log("👀 Polling Linear every %s for %s", d.cfg.Interval, d.describeFilter())
+	}
+	defer d.running.Wait()
+	for {
+		if d.poll {
+			if _, err := d.Poll(ctx); err != nil && ctx.Err() == nil {
+				// Linear outages shouldn't stop a long-running daemon
+				d.log("⚠️  Could not poll Linear: %v", err)
+			}
+		}
+		d.Start(ctx)
+
+		delay := d.cfg.Interval
+		if wait := d.queue.Wait(d.now()); wait > 0 && d.queue.Len() > 0 && wait < delay {
+			delay = wait
+		}
+		if err := d.wait(ctx, delay, d.wake); err != nil {
+			d.log("🛑 Stopping; waiting for %d running ticket(s)", d.Active())
+			return nil
+		}
+	}
+}
+
+// Poll queues the ready tickets not seen before and returns how many were
+// queued.
+func (d *Daemon) Poll(ctx context.Context) (int, error) {
+	tickets, err := d.tracker.ListTickets(ctx, linear.Filter{State: d.cfg.State, Label: d.cfg.Label, Team: d.cfg.Team})
+	if err != nil {
+		return 0, err
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	queued := 0
+	for _, t := range tickets {
+		if d.seen[t.Identifier] {
+			continue
+		}
+		d.seen[t.Identifier] = true
+		d.queue.Push(queue.Item{TicketID: t.Identifier, Priority: t.Priority, Queued: d.now()})
+		d.log("📥 Queued %s: %s", t.Identifier, t.Title)
+		queued++
+	}
+	return queued, nil
+}
+
+// Enqueue queues a ticket, e.g. one a webhook asked for. Returns false if
+// it is already queued or running.
+func (d *Daemon) Enqueue(ticketID string, priority int) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.active[ticketID] || !d.queue.Push(queue.Item{TicketID: ticketID, Priority: priority, Queued: d.now()}) {
+		return false
+	}
+	d.log("📥 Queued %s", ticketID)
+	d.signal()
+	return true
+}
+
+// Start starts queued tickets while fewer than MaxParallel are running,
+// and returns how many it started.
+func (d *Daemon) Start(ctx context.Context) int {
+	started := 0
+	for {
+		d.mu.Lock()
+		if len(d.active) >= d.cfg.MaxParallel {
+			d.mu.Unlock()
+			return started
+		}
+		item, ok := d.queue.Next(d.now())
+		if !ok {
+			d.mu.Unlock()
+			return started
+		}
+		d.active[item.TicketID] = true
+		d.mu.Unlock()
+
+		d.running.Add(1)
+		go func() {
+			defer d.running.Done()
+			d.work(ctx, item.TicketID)
+			d.mu.Lock()
+			delete(d.active, item.TicketID)
+			d.signal()
+			d.mu.Unlock()
+		}()
+		started++
+	}
+}
+
+// Active returns the number of tickets running.
+func (d *Daemon) Active() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.active)
+}
+
+// signal wakes Run without blocking.
+func (d *Daemon) signal() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Wait blocks until the running tickets finish.
+func (d *Daemon) Wait() {
+	d.running.Wait()
+}
+
+// work runs one ticket and reports the outcome on it. Runs aren't
+// cancelled with ctx, so a stopping daemon lets them finish and still
+// updates their tickets.
+func (d *Daemon) work(ctx context.Context, ticketID string) {
+	ctx = context.WithoutCancel(ctx)
+	d.log("🚀 Starting %s", ticketID)
+	d.move(ctx, ticketID, d.cfg.InProgressState)
+
+	url, err := d.run(ctx, ticketID)
+	d.mu.Lock()
+	if err != nil {
+		d.stats.Failed++
+	} else {
+		d.stats.Succeeded++
+	}
+	d.mu.Unlock()
+	if err != nil {
+		d.log("❌ %s failed: %v", ticketID, err)
+		d.comment(ctx, ticketID, fmt.Sprintf("🚣 boatman could not finish this ticket: %v", err))
+		d.move(ctx, ticketID, d.cfg.FailedState)
+		return
+	}
+	d.log("✅ %s: %s", ticketID, url)
+	d.comment(ctx, ticketID, fmt.Sprintf("🚣 boatman opened a PR for review: %s", url))
+	d.move(ctx, ticketID, d.cfg.ReviewState)
+}
+
+// move sets the ticket's state, warning on failure. A blank state leaves
+// it where it is.
+func (d *Daemon) move(ctx context.Context, ticketID, state string) {
+	if state == "" || d.tracker == nil {
+		return
+	}
+	if err := d.tracker.MoveToState(ctx, ticketID, state); err != nil {
+		d.log("⚠️  Could not move %s to %s: %v", ticketID, state, err)
+	}
+}
+
+func (d *Daemon) comment(ctx context.Context, ticketID, body string) {
+	if d.tracker == nil {
+		return
+	}
+	if err := d.tracker.Comment(ctx, ticketID, body); err != nil {
+		d.log("⚠️  Could not comment on %s: %v", ticketID, err)
+	}
+}
+
+// describeFilter describes the polled tickets, e.g. `ENG tickets in
+// "Ready for AI"`.
+func (d *Daemon) describeFilter() string {
+	desc := "tickets"
+	if d.cfg.Team != "" {
+		desc = d.cfg.Team + " tickets"
+	}
+	if d.cfg.State != "" {
+		desc += fmt.Sprintf(" in %q", d.cfg.State)
+	}
+	if d.cfg.Label != "" {
+		desc += fmt.Sprintf(" labeled %q", d.cfg.Label)
+	}
+	return desc
+}
+
+// wait sleeps for d, returning early when woken, or with an error once
+// ctx is done.
+func wait(ctx context.Context, d time.Duration, wake <-chan struct{}) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-wake:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,229 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/linear"
+)
+
+// fakeTracker lists tickets and records what is done to them.
+type fakeTracker struct {
+	mu      sync.Mutex
+	tickets []linear.Ticket
+	listErr error
+	filters []linear.Filter
+	actions []string
+}
+
+func (f *fakeTracker) ListTickets(ctx context.Context, filter linear.Filter) ([]linear.Ticket, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.filters = append(f.filters, filter)
+	return f.tickets, f.listErr
+}
+
+func (f *fakeTracker) Comment(ctx context.Context, issue, body string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.actions = append(f.actions, issue+" comment: "+body)
+	return nil
+}
+
+func (f *fakeTracker) MoveToState(ctx context.Context, issue, state string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.actions = append(f.actions, issue+" -> "+state)
+	return nil
+}
+
+func newTestDaemon(t *testing.T, cfg config.DaemonConfig, tracker Tracker, run Runner) *Daemon {
+	t.Helper()
+	d, err := New(cfg, tracker, run)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.log = func(format string, args ...any) {}
+	return d
+}
+
+func TestNewNeedsFilter(t *testing.T) {
+	if _, err := New(config.DaemonConfig{Team: "ENG"}, &fakeTracker{}, nil); !errors.Is(err, ErrNoFilter) {
+		t.Errorf("err = %v, want ErrNoFilter", err)
+	}
+}
+
+func TestPoll(t *testing.T) {
+	tracker := &fakeTracker{tickets: []linear.Ticket{
+		{Identifier: "ENG-1", Priority: 3},
+		{Identifier: "ENG-2", Priority: 1},
+	}}
+	d := newTestDaemon(t, config.DaemonConfig{State: "Ready for AI", Team: "ENG"}, tracker, nil)
+
+	if n, err := d.Poll(context.Background()); err != nil || n != 2 {
+		t.Fatalf("Poll = %d, %v", n, err)
+	}
+	// Tickets already seen aren't queued again
+	tracker.tickets = append(tracker.tickets, linear.Ticket{Identifier: "ENG-3"})
+	if n, err := d.Poll(context.Background()); err != nil || n != 1 {
+		t.Fatalf("second Poll = %d, %v", n, err)
+	}
+	if want := (linear.Filter{State: "Ready for AI", Team: "ENG"}); tracker.filters[0] != want {
+		t.Errorf("filter = %+v, want %+v", tracker.filters[0], want)
+	}
+
+	items := d.queue.Items()
+	if len(items) != 3 || items[0].TicketID != "ENG-2" || items[1].TicketID != "ENG-1" {
+		t.Errorf("queue = %+v, want the urgent ticket first", items)
+	}
+}
+
+func TestStartLimitsParallelRuns(t *testing.T) {
+	tracker := &fakeTracker{tickets: []linear.Ticket{{Identifier: "ENG-1"}, {Identifier: "ENG-2"}, {Identifier: "ENG-3"}}}
+	release := make(chan struct{})
+	var mu sync.Mutex
+	var started []string
+	run := func(ctx context.Context, ticketID string) (string, error) {
+		mu.Lock()
+		started = append(started, ticketID)
+		mu.Unlock()
+		<-release
+		return "https://github.com/acme/web/pull/1", nil
+	}
+	d := newTestDaemon(t, config.DaemonConfig{Label: "boatman", MaxParallel: 2}, tracker, run)
+	d.Poll(context.Background())
+
+	if n := d.Start(context.Background()); n != 2 {
+		t.Fatalf("started %d, want 2", n)
+	}
+	if n := d.Start(context.Background()); n != 0 || d.Active() != 2 {
+		t.Errorf("started %d more with %d active, want none past the limit", n, d.Active())
+	}
+	close(release)
+	d.Wait()
+	if n := d.Start(context.Background()); n != 1 {
+		t.Errorf("started %d after the runs finished, want 1", n)
+	}
+	d.Wait()
+	if len(started) != 3 {
+		t.Errorf("started = %v", started)
+	}
+}
+
+func TestWorkReportsOutcome(t *testing.T) {
+	tracker := &fakeTracker{tickets: []linear.Ticket{{Identifier: "ENG-1"}, {Identifier: "ENG-2"}}}
+	run := func(ctx context.Context, ticketID string) (string, error) {
+		if ticketID == "ENG-2" {
+			return "", errors.New("review did not pass")
+		}
+		return "https://github.com/acme/web/pull/7", nil
+	}
+	d := newTestDaemon(t, config.DaemonConfig{
+		State: "Ready for AI", InProgressState: "In Progress", ReviewState: "In Review", FailedState: "Blocked",
+	}, tracker, run)
+	d.Poll(context.Background())
+
+	for d.Start(context.Background()) > 0 {
+		d.Wait()
+	}
+	want := []string{
+		"ENG-1 -> In Progress",
+		"ENG-1 comment: 🚣 boatman opened a PR for review: https://github.com/acme/web/pull/7",
+		"ENG-1 -> In Review",
+		"ENG-2 -> In Progress",
+		"ENG-2 comment: 🚣 boatman could not finish this ticket: review did not pass",
+		"ENG-2 -> Blocked",
+	}
+	if fmt.Sprint(tracker.actions) != fmt.Sprint(want) {
+		t.Errorf("actions = %q\nwant %q", tracker.actions, want)
+	}
+	if stats := d.Stats(); stats != (Stats{Succeeded: 1, Failed: 1}) {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestSetName(t *testing.T) {
+	release := make(chan struct{})
+	run := func(ctx context.Context, ticketID string) (string, error) {
+		<-release
+		return "https://github.com/acme/web/pull/1", nil
+	}
+	d, err := NewWorker(config.DaemonConfig{}, nil, run)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var logs []string
+	d.log = func(format string, args ...any) { logs = append(logs, fmt.Sprintf(format, args...)) }
+	d.SetName("payments")
+
+	d.Enqueue("PAY-1", 0)
+	d.Enqueue("PAY-2", 0)
+	d.Start(context.Background())
+	if stats := d.Stats(); stats != (Stats{Queued: 1, Active: 1}) {
+		t.Errorf("stats = %+v", stats)
+	}
+	close(release)
+	d.Wait()
+	if logs[0] != "[payments] 📥 Queued PAY-1" {
+		t.Errorf("logs = %q", logs)
+	}
+}
+
+func TestRunStopsWithContext(t *testing.T) {
+	tracker := &fakeTracker{listErr: errors.New("linear is down")}
+	d := newTestDaemon(t, config.DaemonConfig{State: "Ready for AI", Interval: time.Hour}, tracker, nil)
+	var logs []string
+	d.log = func(format string, args ...any) { logs = append(logs, fmt.Sprintf(format, args...)) }
+	polls := 0
+	ctx, cancel := context.WithCancel(context.Background())
+	d.wait = func(ctx context.Context, d time.Duration, wake <-chan struct{}) error {
+		if polls++; polls == 2 {
+			cancel()
+		}
+		return ctx.Err()
+	}
+
+	if err := d.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if len(tracker.filters) != 2 {
+		t.Errorf("polled %d times, want 2", len(tracker.filters))
+	}
+	if len(logs) != 4 || logs[1] != "⚠️  Could not poll Linear: linear is down" {
+		t.Errorf("logs = %q", logs)
+	}
+}
+
+func TestEnqueue(t *testing.T) {
+	release := make(chan struct{})
+	run := func(ctx context.Context, ticketID string) (string, error) {
+		<-release
+		return "https://github.com/acme/web/pull/1", nil
+	}
+	d, err := NewWorker(config.DaemonConfig{}, &fakeTracker{}, run)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.log = func(format string, args ...any) {}
+
+	if !d.Enqueue("ENG-1", 0) || !d.Enqueue("ENG-2", 1) {
+		t.Fatal("Enqueue refused a new ticket")
+	}
+	if d.Enqueue("ENG-1", 0) {
+		t.Error("Enqueue accepted a queued ticket twice")
+	}
+	d.Start(context.Background())
+	if d.Enqueue("ENG-2", 1) {
+		t.Error("Enqueue accepted a running ticket")
+	}
+	close(release)
+	d.Wait()
+	if !d.Enqueue("ENG-2", 1) {
+		t.Error("Enqueue refused a ticket whose run finished")
+	}
+}
@@ -0,0 +1,97 @@
+// Package decisionlog records automated decisions made during a workflow run.
+// Each entry explains what the agent chose to do and why, so fallbacks and
+// overrides can be audited after the fact.
+package decisionlog
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/events"
+)
+
+// Decision is a single recorded decision.
+type Decision struct {
+	Step     string    `json:"step"`     // Workflow step that made the decision, e.g. "review"
+	Decision string    `json:"decision"` // What was decided
+	Reason   string    `json:"reason"`   // Why
+	Time     time.Time `json:"time"`
+}
+
+// Log collects decisions for a workflow run.
+// A nil *Log is valid and discards all records.
+type Log struct {
+	entries []Decision
+	mu      sync.Mutex
+}
+
+// New creates an empty decision log.
+func New() *Log {
+	return &Log{
+		entries: make([]Decision, 0),
+	}
+}
+
+// Record adds a decision and emits it as a "decision" event.
+func (l *Log) Record(step, decision, reason string) {
+	if l == nil {
+		return
+	}
+
+	d := Decision{Step: step, Decision: decision, Reason: reason, Time: time.Now()}
+
+	l.mu.Lock()
+	l.entries = append(l.entries, d)
+	l.mu.Unlock()
+
+	events.Emit(events.Event{
+		Type:    "decision",
+		Name:    step,
+		Message: decision,
+		Data:    map[string]any{"reason": reason},
+	})
+}
+
+// Entries returns a copy of all recorded decisions.
+func (l *Log) Entries() []Decision {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	result := make([]Decision, len(l.entries))
+	copy(result, l.entries)
+	return result
+}
+
+// Len returns the number of recorded decisions.
+func (l *Log) Len() int {
+	if l == nil {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.entries)
+}
+
+// Format returns a human-readable list of decisions.
+// Returns an empty string if nothing was recorded.
+func (l *Log) Format() string {
+	entries := l.Entries()
+	if len(entries) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("   📒 Decisions:\n")
+	for _, d := range entries {
+		sb.WriteString(fmt.Sprintf("      • [%s] %s", d.Step, d.Decision))
+		if d.Reason != "" {
+			sb.WriteString(fmt.Sprintf(" — %s", d.Reason))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,51 @@
+package decisionlog
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+// silenceStdout discards emitted events for the duration of a test.
+func silenceStdout(t *testing.T) {
+	old := os.Stdout
+	devNull, err := os.Open(os.DevNull)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = devNull
+	t.Cleanup(func() {
+		os.Stdout = old
+		devNull.Close()
+	})
+}
+
+func TestRecord(t *testing.T) {
+	silenceStdout(t)
+
+	log := New()
+	log.Record("review", "fell back to system prompt", "skill timed out")
+	log.Record("review", "marked inconclusive", "")
+
+	entries := log.Entries()
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Reason != "skill timed out" || entries[0].Time.IsZero() {
+		t.Errorf("Unexpected entry: %+v", entries[0])
+	}
+
+	out := log.Format()
+	if !strings.Contains(out, "[review] fell back to system prompt — skill timed out") {
+		t.Errorf("Unexpected format:\n%s", out)
+	}
+}
+
+func TestNilLog(t *testing.T) {
+	var log *Log
+	log.Record("review", "ignored", "")
+
+	if log.Len() != 0 || log.Entries() != nil || log.Format() != "" {
+		t.Error("Expected nil log to discard records")
+	}
+}
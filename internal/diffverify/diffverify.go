@@ -5,11 +5,17 @@ package diffverify
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
 	"regexp"
+	"sort"
 	"strings"
 
+	"github.com/philjestin/boatmanmode/internal/claude"
 	"github.com/philjestin/boatmanmode/internal/coordinator"
+	"github.com/philjestin/boatmanmode/internal/cost"
 	"github.com/philjestin/boatmanmode/internal/scottbott"
+	"github.com/philjestin/boatmanmode/internal/unidiff"
 )
 
 // VerificationResult contains the outcome of diff verification.
@@ -21,21 +27,53 @@ type VerificationResult struct {
 	// UnaddressedIssues are issues that remain
 	UnaddressedIssues []UnaddressedIssue
 	// NewIssues are potential new problems introduced
-	NewIssues []string
+	NewIssues []scottbott.Issue
 	// Confidence is how confident we are in the assessment (0-100)
 	Confidence int
+	// LLM is true if the model judged the issues; heuristics judged any it
+	// left out
+	LLM bool
+	// Usage is the model's token usage, with the LLM enabled
+	Usage *cost.Usage
 }
 
 // AddressedIssue represents a fixed issue.
 type AddressedIssue struct {
 	Original    scottbott.Issue
 	FixEvidence string // What in the diff shows this was fixed
+	Heuristic   bool   // True if evidence is only "file was modified", not a content match
+	Confidence  int    // The model's confidence in the verdict (0-100); 0 for heuristic verdicts
 }
 
 // UnaddressedIssue represents an unfixed issue.
 type UnaddressedIssue struct {
-	Original scottbott.Issue
-	Reason   string // Why we think it wasn't fixed
+	Original   scottbott.Issue
+	Reason     string // Why we think it wasn't fixed
+	Confidence int    // The model's confidence in the verdict (0-100); 0 for heuristic verdicts
+}
+
+// Pattern describes a problematic pattern to flag in newly added lines.
+type Pattern struct {
+	// Pattern is a case-insensitive regular expression
+	Pattern string
+	// Severity is the issue severity (critical, major, minor)
+	Severity string
+	// Message describes the problem
+	Message string
+}
+
+// DefaultPatterns are used when no patterns are configured.
+var DefaultPatterns = []Pattern{
+	{Pattern: `fixme:`, Severity: "minor", Message: "New FIXME comment added"},
+	{Pattern: `xxx:`, Severity: "minor", Message: "New XXX marker added"},
+	{Pattern: `binding\.pry`, Severity: "major", Message: "Debug statement left in code"},
+	{Pattern: `debugger;`, Severity: "major", Message: "Debugger statement left in code"},
+}
+
+// compiledPattern is a Pattern with its compiled regex.
+type compiledPattern struct {
+	Pattern
+	re *regexp.Regexp
 }
 
 // Agent verifies diffs against issues.
@@ -44,14 +82,20 @@ type Agent struct {
 	worktreePath          string
 	coord                 *coordinator.Coordinator
 	minConfidenceOverride int // Optional minimum confidence override
+	patterns              []compiledPattern
+	model                 string
+	llm                   *claude.Client // Judges issues when EnableLLM was called
+	out                   io.Writer      // Progress output; stdout when nil
 }
 
 // New creates a new diff verification agent.
 func New(worktreePath string) *Agent {
-	return &Agent{
+	a := &Agent{
 		id:           "diffverify",
 		worktreePath: worktreePath,
 	}
+	a.SetPatterns(DefaultPatterns)
+	return a
 }
 
 // ID returns the agent ID.
@@ -79,6 +123,44 @@ func (a *Agent) SetMinConfidence(minConfidence int) {
 	a.minConfidenceOverride = minConfidence
 }
 
+// SetModel sets the Claude model for verification that asks the model
+// (empty = CLI default). The heuristic checks do not use it.
+func (a *Agent) SetModel(model string) {
+	a.model = model
+}
+
+// SetOutput sends the verifier's progress output, including the model's
+// streamed response, to w.
+func (a *Agent) SetOutput(w io.Writer) {
+	a.out = w
+}
+
+// output returns where progress output goes.
+func (a *Agent) output() io.Writer {
+	if a.out == nil {
+		return os.Stdout
+	}
+	return a.out
+}
+
+// SetPatterns replaces the patterns used to detect new issues.
+// Returns an error if any pattern is not a valid regular expression.
+func (a *Agent) SetPatterns(patterns []Pattern) error {
+	compiled := make([]compiledPattern, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(`(?i)` + p.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid new issue pattern %q: %w", p.Pattern, err)
+		}
+		if p.Severity == "" {
+			p.Severity = "minor"
+		}
+		compiled = append(compiled, compiledPattern{Pattern: p, re: re})
+	}
+	a.patterns = compiled
+	return nil
+}
+
 // Verify checks if the diff addresses the given issues.
 func (a *Agent) Verify(ctx context.Context, issues []scottbott.Issue, oldDiff, newDiff string) (*VerificationResult, error) {
 	// Claim work if coordinated
@@ -98,7 +180,7 @@ func (a *Agent) Verify(ctx context.Context, issues []scottbott.Issue, oldDiff, n
 		AllAddressed:      true,
 		AddressedIssues:   []AddressedIssue{},
 		UnaddressedIssues: []UnaddressedIssue{},
-		NewIssues:         []string{},
+		NewIssues:         []scottbott.Issue{},
 		Confidence:        85, // Default confidence (increased from 80)
 	}
 
@@ -106,14 +188,27 @@ func (a *Agent) Verify(ctx context.Context, issues []scottbott.Issue, oldDiff, n
 	oldChanges := parseDiff(oldDiff)
 	newChanges := parseDiff(newDiff)
 
+	// The model judges the issues when enabled, heuristics any it doesn't
+	verdicts, usage := a.judgeIssues(ctx, issues, newDiff)
+	result.Usage = usage
+	if len(verdicts) > 0 {
+		result.LLM = true
+		result.Confidence = meanConfidence(verdicts)
+	}
+
 	// Check each issue
-	for _, issue := range issues {
-		addressed, evidence, reason := a.checkIssueAddressed(issue, oldChanges, newChanges)
+	for i, issue := range issues {
+		if v, ok := verdicts[i]; ok {
+			result.addVerdict(issue, v, a.minConfidenceOverride)
+			continue
+		}
+		addressed, evidence, heuristic, reason := a.checkIssueAddressed(issue, oldChanges, newChanges)
 		
 		if addressed {
 			result.AddressedIssues = append(result.AddressedIssues, AddressedIssue{
 				Original:    issue,
 				FixEvidence: evidence,
+				Heuristic:   heuristic,
 			})
 		} else {
 			result.AllAddressed = false
@@ -127,12 +222,10 @@ func (a *Agent) Verify(ctx context.Context, issues []scottbott.Issue, oldDiff, n
 	// Check for potential new issues
 	result.NewIssues = a.detectNewIssues(oldChanges, newChanges)
 	if len(result.NewIssues) > 0 {
-		// Only penalize for actual concerning issues, not debug statements
+		// Only penalize for concerning issues, not minor markers
 		concerningIssues := 0
 		for _, issue := range result.NewIssues {
-			if !strings.Contains(strings.ToLower(issue), "console.log") &&
-				!strings.Contains(strings.ToLower(issue), "debug") &&
-				!strings.Contains(strings.ToLower(issue), "print") {
+			if issue.Severity == "critical" || issue.Severity == "major" {
 				concerningIssues++
 			}
 		}
@@ -140,13 +233,18 @@ func (a *Agent) Verify(ctx context.Context, issues []scottbott.Issue, oldDiff, n
 	}
 
 	// Adjust confidence based on coverage with more lenient calculation
-	if len(issues) > 0 {
+	if len(issues) > 0 && !result.LLM {
 		addressedRatio := float64(len(result.AddressedIssues)) / float64(len(issues))
 		// Use a weighted formula that's more forgiving
 		confidenceMultiplier := 0.7 + (addressedRatio * 0.3) // 70% base + 30% based on ratio
 		result.Confidence = int(float64(result.Confidence) * confidenceMultiplier)
 	}
 
+	// Below the confidence threshold, don't trust heuristic-only fixes
+	if !result.MeetsThreshold(a.minConfidenceOverride) {
+		a.demoteHeuristicFixes(result)
+	}
+
 	// Share result via coordinator
 	if a.coord != nil {
 		a.coord.SetContext("diffverify_result", result)
@@ -155,6 +253,32 @@ func (a *Agent) Verify(ctx context.Context, issues []scottbott.Issue, oldDiff, n
 	return result, nil
 }
 
+// MeetsThreshold returns true if confidence is at or above minConfidence.
+// A threshold of zero or less always passes.
+func (r *VerificationResult) MeetsThreshold(minConfidence int) bool {
+	return minConfidence <= 0 || r.Confidence >= minConfidence
+}
+
+// demoteHeuristicFixes moves issues addressed only by heuristics to unaddressed.
+func (a *Agent) demoteHeuristicFixes(result *VerificationResult) {
+	var kept []AddressedIssue
+	for _, addressed := range result.AddressedIssues {
+		if !addressed.Heuristic {
+			kept = append(kept, addressed)
+			continue
+		}
+		result.AllAddressed = false
+		result.UnaddressedIssues = append(result.UnaddressedIssues, UnaddressedIssue{
+			Original: addressed.Original,
+			Reason:   fmt.Sprintf("Low confidence (%d%%): %s", result.Confidence, addressed.FixEvidence),
+		})
+	}
+	if kept == nil {
+		kept = []AddressedIssue{}
+	}
+	result.AddressedIssues = kept
+}
+
 // DiffChange represents a parsed diff chunk.
 type DiffChange struct {
 	File     string
@@ -163,44 +287,25 @@ type DiffChange struct {
 	Context  []string
 }
 
-// parseDiff extracts changes from a unified diff.
+// parseDiff extracts changes from a unified diff, keyed by file path.
+// Deleted files are keyed by their old path.
 func parseDiff(diff string) map[string]*DiffChange {
 	changes := make(map[string]*DiffChange)
-	
-	lines := strings.Split(diff, "\n")
-	var currentFile string
-	var currentChange *DiffChange
-
-	fileRe := regexp.MustCompile(`^\+\+\+ [ab]/(.+)$`)
-	
-	for _, line := range lines {
-		// Detect file header
-		if matches := fileRe.FindStringSubmatch(line); len(matches) > 1 {
-			currentFile = matches[1]
-			currentChange = &DiffChange{File: currentFile}
-			changes[currentFile] = currentChange
-			continue
-		}
-
-		if currentChange == nil {
-			continue
-		}
-
-		// Categorize line
-		if strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++") {
-			currentChange.Added = append(currentChange.Added, strings.TrimPrefix(line, "+"))
-		} else if strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "---") {
-			currentChange.Removed = append(currentChange.Removed, strings.TrimPrefix(line, "-"))
-		} else if strings.HasPrefix(line, " ") {
-			currentChange.Context = append(currentChange.Context, strings.TrimPrefix(line, " "))
+	for _, f := range unidiff.Parse(diff) {
+		changes[f.Path()] = &DiffChange{
+			File:    f.Path(),
+			Added:   f.Added(),
+			Removed: f.Removed(),
+			Context: f.Context(),
 		}
 	}
-
 	return changes
 }
 
 // checkIssueAddressed determines if an issue was fixed.
-func (a *Agent) checkIssueAddressed(issue scottbott.Issue, oldChanges, newChanges map[string]*DiffChange) (bool, string, string) {
+// Returns whether it was addressed, the evidence, whether that evidence is
+// heuristic only, and the reason if not addressed.
+func (a *Agent) checkIssueAddressed(issue scottbott.Issue, oldChanges, newChanges map[string]*DiffChange) (bool, string, bool, string) {
 	// Strategy:
 	// 1. If issue mentions a specific file, look for changes in that file
 	// 2. Look for keywords from the issue in the new diff's added lines
@@ -217,12 +322,12 @@ func (a *Agent) checkIssueAddressed(issue scottbott.Issue, oldChanges, newChange
 		newChange, modified := newChanges[issue.File]
 		if !modified {
 			// File wasn't touched in new diff - issue not addressed
-			return false, "", fmt.Sprintf("File %s was not modified", issue.File)
+			return false, "", false, fmt.Sprintf("File %s was not modified", issue.File)
 		}
 		// File was modified - look for evidence of fix
-		evidence := a.findFixEvidence(newChange, keywords, issue)
+		evidence, heuristic := a.findFixEvidence(newChange, keywords, issue)
 		if evidence != "" {
-			return true, evidence, ""
+			return true, evidence, heuristic, ""
 		}
 		// File was modified but no clear evidence found
 		// Don't give up yet - check if issue might have been addressed elsewhere
@@ -231,22 +336,23 @@ func (a *Agent) checkIssueAddressed(issue scottbott.Issue, oldChanges, newChange
 	// Check all new changes for keyword matches
 	for file, change := range newChanges {
 		// Skip if not in added lines
-		evidence := a.findFixEvidence(change, keywords, issue)
+		evidence, heuristic := a.findFixEvidence(change, keywords, issue)
 		if evidence != "" {
-			return true, fmt.Sprintf("In %s: %s", file, evidence), ""
+			return true, fmt.Sprintf("In %s: %s", file, evidence), heuristic, ""
 		}
 	}
 
 	// Check if problematic pattern was removed
 	if removal := a.checkPatternRemoved(oldChanges, newChanges, issue); removal != "" {
-		return true, removal, ""
+		return true, removal, false, ""
 	}
 
-	return false, "", "No evidence of fix found in diff"
+	return false, "", false, "No evidence of fix found in diff"
 }
 
 // findFixEvidence looks for evidence that an issue was addressed.
-func (a *Agent) findFixEvidence(change *DiffChange, keywords []string, issue scottbott.Issue) string {
+// The boolean is true when the evidence is a size heuristic rather than a keyword match.
+func (a *Agent) findFixEvidence(change *DiffChange, keywords []string, issue scottbott.Issue) (string, bool) {
 	// Count keyword matches in added lines
 	matchCount := 0
 	var matchedLines []string
@@ -266,7 +372,7 @@ func (a *Agent) findFixEvidence(change *DiffChange, keywords []string, issue sco
 
 	// Accept any keyword matches as evidence
 	if matchCount >= 1 {
-		return fmt.Sprintf("Found related changes: %s", strings.Join(matchedLines, "; "))
+		return fmt.Sprintf("Found related changes: %s", strings.Join(matchedLines, "; ")), false
 	}
 
 	// More lenient heuristics based on severity
@@ -274,26 +380,26 @@ func (a *Agent) findFixEvidence(change *DiffChange, keywords []string, issue sco
 	case "critical":
 		// For critical issues, look for significant changes
 		if len(change.Added) > 3 || len(change.Removed) > 2 {
-			return fmt.Sprintf("Significant changes (%d added, %d removed)", len(change.Added), len(change.Removed))
+			return fmt.Sprintf("Significant changes (%d added, %d removed)", len(change.Added), len(change.Removed)), true
 		}
 	case "major":
 		// For major issues, accept even small targeted changes
 		if len(change.Added) > 1 || len(change.Removed) > 0 {
-			return fmt.Sprintf("Targeted changes (%d lines added, %d removed)", len(change.Added), len(change.Removed))
+			return fmt.Sprintf("Targeted changes (%d lines added, %d removed)", len(change.Added), len(change.Removed)), true
 		}
 	case "minor":
 		// For minor issues, any change in the file is likely addressing it
 		if len(change.Added) > 0 || len(change.Removed) > 0 {
-			return fmt.Sprintf("Code changes detected (%d added, %d removed)", len(change.Added), len(change.Removed))
+			return fmt.Sprintf("Code changes detected (%d added, %d removed)", len(change.Added), len(change.Removed)), true
 		}
 	}
 
 	// If the file was modified at all, give benefit of the doubt
 	if len(change.Added) > 0 || len(change.Removed) > 0 {
-		return fmt.Sprintf("File modified with %d additions and %d deletions", len(change.Added), len(change.Removed))
+		return fmt.Sprintf("File modified with %d additions and %d deletions", len(change.Added), len(change.Removed)), true
 	}
 
-	return ""
+	return "", false
 }
 
 // checkPatternRemoved checks if a problematic pattern was removed.
@@ -316,44 +422,38 @@ func (a *Agent) checkPatternRemoved(oldChanges, newChanges map[string]*DiffChang
 }
 
 // detectNewIssues looks for potential new problems introduced.
-func (a *Agent) detectNewIssues(oldChanges, newChanges map[string]*DiffChange) []string {
-	var issues []string
+func (a *Agent) detectNewIssues(oldChanges, newChanges map[string]*DiffChange) []scottbott.Issue {
+	var issues []scottbott.Issue
+	seen := make(map[string]bool)
 
-	// Only flag truly problematic patterns - be more lenient
-	problemPatterns := []struct {
-		pattern string
-		message string
-	}{
-		{`fixme:`, "New FIXME comment added"},
-		{`xxx:`, "New XXX marker added"},
-		{`binding\.pry`, "Debug statement left in code"},
-		{`debugger;`, "Debugger statement left in code"},
+	// Iterate files in sorted order for deterministic output
+	files := make([]string, 0, len(newChanges))
+	for file := range newChanges {
+		files = append(files, file)
 	}
+	sort.Strings(files)
 
-	for file, change := range newChanges {
-		for _, line := range change.Added {
-			lineLower := strings.ToLower(line)
-			for _, p := range problemPatterns {
-				re := regexp.MustCompile(`(?i)` + p.pattern)
-				if re.MatchString(lineLower) {
-					issues = append(issues, fmt.Sprintf("%s in %s", p.message, file))
-					break
+	for _, file := range files {
+		for _, line := range newChanges[file].Added {
+			for _, p := range a.patterns {
+				if !p.re.MatchString(line) {
+					continue
 				}
+				key := p.Message + "|" + file
+				if !seen[key] {
+					seen[key] = true
+					issues = append(issues, scottbott.Issue{
+						Severity:    p.Severity,
+						File:        file,
+						Description: fmt.Sprintf("%s in %s", p.Message, file),
+					})
+				}
+				break
 			}
 		}
 	}
 
-	// Deduplicate
-	seen := make(map[string]bool)
-	var unique []string
-	for _, issue := range issues {
-		if !seen[issue] {
-			seen[issue] = true
-			unique = append(unique, issue)
-		}
-	}
-
-	return unique
+	return issues
 }
 
 // extractKeywords pulls out meaningful words from text.
@@ -526,7 +626,7 @@ func (h *VerificationHandoff) Full() string {
 	if len(r.NewIssues) > 0 {
 		sb.WriteString("## ⚠️ Potential New Issues\n")
 		for _, issue := range r.NewIssues {
-			sb.WriteString(fmt.Sprintf("- %s\n", issue))
+			sb.WriteString(fmt.Sprintf("- [%s] %s\n", issue.Severity, issue.Description))
 		}
 	}
 
@@ -266,3 +266,100 @@ func TestConfidenceCalculation(t *testing.T) {
 		t.Errorf("Confidence should be at least 50, got %d", result.Confidence)
 	}
 }
+
+func TestSetPatternsCustom(t *testing.T) {
+	agent := New("/tmp")
+	err := agent.SetPatterns([]Pattern{
+		{Pattern: `console\.log\(`, Severity: "major", Message: "console.log left in code"},
+		{Pattern: `TODO\(nobody\)`, Message: "Unowned TODO"},
+	})
+	if err != nil {
+		t.Fatalf("SetPatterns failed: %v", err)
+	}
+
+	newChanges := map[string]*DiffChange{
+		"app.js": {
+			File:  "app.js",
+			Added: []string{"console.log(x)", "// TODO(nobody) fix"},
+		},
+	}
+
+	issues := agent.detectNewIssues(nil, newChanges)
+	if len(issues) != 2 {
+		t.Fatalf("Expected 2 issues, got %d", len(issues))
+	}
+	if issues[0].Severity != "major" || issues[0].File != "app.js" {
+		t.Errorf("Unexpected first issue: %+v", issues[0])
+	}
+	if issues[1].Severity != "minor" {
+		t.Errorf("Expected default severity minor, got %s", issues[1].Severity)
+	}
+}
+
+func TestSetPatternsInvalid(t *testing.T) {
+	agent := New("/tmp")
+	if err := agent.SetPatterns([]Pattern{{Pattern: `(unclosed`}}); err == nil {
+		t.Error("Expected error for invalid regex")
+	}
+	// Defaults should remain in place after a failed update
+	if len(agent.patterns) != len(DefaultPatterns) {
+		t.Errorf("Expected %d default patterns, got %d", len(DefaultPatterns), len(agent.patterns))
+	}
+}
+
+func TestNewIssuesLowerConfidence(t *testing.T) {
+	agent := New("/tmp")
+	newDiff := `+++ b/app.js
++debugger;
+`
+	result, err := agent.Verify(context.Background(), nil, "", newDiff)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if len(result.NewIssues) != 1 {
+		t.Fatalf("Expected 1 new issue, got %d", len(result.NewIssues))
+	}
+	if result.Confidence != 80 {
+		t.Errorf("Expected confidence 80 after major penalty, got %d", result.Confidence)
+	}
+}
+
+func TestMeetsThreshold(t *testing.T) {
+	r := &VerificationResult{Confidence: 40}
+	if !r.MeetsThreshold(0) {
+		t.Error("Zero threshold should always pass")
+	}
+	if r.MeetsThreshold(50) {
+		t.Error("40% should not meet 50% threshold")
+	}
+	if !r.MeetsThreshold(40) {
+		t.Error("40% should meet 40% threshold")
+	}
+}
+
+func TestLowConfidenceDemotesHeuristicFixes(t *testing.T) {
+	issues := []scottbott.Issue{
+		{Severity: "minor", Description: "Rename zzz variable", File: "a.go"},
+	}
+	newDiff := `+++ b/a.go
++something unrelated
+`
+
+	// Without a threshold, the heuristic "file modified" fix is trusted
+	agent := New("/tmp")
+	result, _ := agent.Verify(context.Background(), issues, "", newDiff)
+	if len(result.AddressedIssues) != 1 || !result.AddressedIssues[0].Heuristic {
+		t.Fatalf("Expected one heuristic fix, got %+v", result.AddressedIssues)
+	}
+
+	// With a threshold above the computed confidence, it is demoted
+	agent.SetMinConfidence(95)
+	result, _ = agent.Verify(context.Background(), issues, "", newDiff)
+	if result.AllAddressed {
+		t.Error("Should not be all addressed below threshold")
+	}
+	if len(result.AddressedIssues) != 0 || len(result.UnaddressedIssues) != 1 {
+		t.Errorf("Expected heuristic fix demoted, got %d addressed, %d unaddressed",
+			len(result.AddressedIssues), len(result.UnaddressedIssues))
+	}
+}
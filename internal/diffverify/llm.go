@@ -0,0 +1,171 @@
+package diffverify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/claude"
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/cost"
+	"github.com/philjestin/boatmanmode/internal/scottbott"
+)
+
+// maxJudgedDiff caps the diff sent to the model, in characters.
+const maxJudgedDiff = 60000
+
+// Verdict is the model's judgment of whether the new diff fixes an issue.
+type Verdict struct {
+	// Issue is the issue's number in the prompt, from 1
+	Issue int `json:"issue"`
+	// Addressed is true if the diff fixes the issue
+	Addressed bool `json:"addressed"`
+	// Confidence is the model's confidence in the verdict (0-100)
+	Confidence int `json:"confidence"`
+	// Evidence is the change that fixes the issue, or why it doesn't
+	Evidence string `json:"evidence"`
+}
+
+// EnableLLM has Claude judge each issue against the new diff, on the
+// verifier model, instead of relying on keyword heuristics. The heuristic
+// verdicts stand for any issue the model doesn't judge, and for all of
+// them when it fails.
+func (a *Agent) EnableLLM(cfg config.ClaudeConfig) {
+	client := claude.NewReadOnly(a.worktreePath, "verifier")
+	client.Sampling = cfg.SamplingFor("verifier")
+	client.Configure(cfg)
+	a.llm = client
+}
+
+const judgeSystemPrompt = `You are verifying that a refactor fixed the issues a code review raised.
+For each numbered issue, judge from the diff whether the change actually fixes it. Touching the
+file is not enough: the problem described must be gone. You may read the code for context, but
+do not change anything.
+
+Respond with ONLY a JSON object:
+{
+  "verdicts": [
+    {"issue": 1, "addressed": true, "confidence": 90, "evidence": "the change that fixes it, or why it doesn't"}
+  ]
+}
+
+Give one verdict per issue. confidence (0-100) is how sure you are of the verdict.`
+
+// judge asks the model for a verdict on each issue.
+func (a *Agent) judge(ctx context.Context, issues []scottbott.Issue, newDiff string) ([]Verdict, *cost.Usage, error) {
+	a.llm.Model = a.model
+	a.llm.Output = a.out
+
+	var sb strings.Builder
+	sb.WriteString("## Review Issues\n")
+	for i, issue := range issues {
+		location := ""
+		if issue.File != "" {
+			location = " (" + issue.File
+			if issue.Line > 0 {
+				location += fmt.Sprintf(":%d", issue.Line)
+			}
+			location += ")"
+		}
+		sb.WriteString(fmt.Sprintf("%d. [%s] %s%s\n", i+1, issue.Severity, issue.Description, location))
+		if issue.Suggestion != "" {
+			sb.WriteString(fmt.Sprintf("   Suggested fix: %s\n", issue.Suggestion))
+		}
+	}
+	sb.WriteString("\n## Diff After the Refactor\n```diff\n")
+	sb.WriteString(truncate(newDiff, maxJudgedDiff))
+	sb.WriteString("\n```\n")
+
+	response, usage, err := a.llm.Message(ctx, judgeSystemPrompt, sb.String())
+	if err != nil {
+		return nil, usage, err
+	}
+	verdicts, err := parseVerdicts(response, len(issues))
+	return verdicts, usage, err
+}
+
+// parseVerdicts extracts the verdicts from the model's response, dropping
+// those for issues that weren't asked about.
+func parseVerdicts(response string, issues int) ([]Verdict, error) {
+	start := strings.Index(response, "{")
+	end := strings.LastIndex(response, "}")
+	if start < 0 || end <= start {
+		return nil, errors.New("no JSON found in response")
+	}
+	var parsed struct {
+		Verdicts []Verdict `json:"verdicts"`
+	}
+	if err := json.Unmarshal([]byte(response[start:end+1]), &parsed); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	var verdicts []Verdict
+	for _, v := range parsed.Verdicts {
+		if v.Issue >= 1 && v.Issue <= issues {
+			v.Confidence = max(0, min(100, v.Confidence))
+			verdicts = append(verdicts, v)
+		}
+	}
+	if len(verdicts) == 0 {
+		return nil, errors.New("no verdicts in response")
+	}
+	return verdicts, nil
+}
+
+// judgeIssues returns the model's verdicts keyed by issue index, or nil
+// without the model or when it fails.
+func (a *Agent) judgeIssues(ctx context.Context, issues []scottbott.Issue, newDiff string) (map[int]Verdict, *cost.Usage) {
+	if a.llm == nil || len(issues) == 0 {
+		return nil, nil
+	}
+	fmt.Fprintf(a.output(), "   🔍 Asking the verifier model whether %d issue(s) were fixed...\n", len(issues))
+	verdicts, usage, err := a.judge(ctx, issues, newDiff)
+	if err != nil {
+		fmt.Fprintf(a.output(), "   ⚠️  LLM verification failed (%v), using heuristic checks\n", err)
+		return nil, usage
+	}
+	judged := make(map[int]Verdict, len(verdicts))
+	for _, v := range verdicts {
+		if _, dup := judged[v.Issue-1]; !dup {
+			judged[v.Issue-1] = v
+		}
+	}
+	return judged, usage
+}
+
+// addVerdict records the model's verdict on issue. An issue judged fixed
+// with less than minConfidence stays unaddressed.
+func (r *VerificationResult) addVerdict(issue scottbott.Issue, v Verdict, minConfidence int) {
+	switch {
+	case v.Addressed && (minConfidence <= 0 || v.Confidence >= minConfidence):
+		r.AddressedIssues = append(r.AddressedIssues, AddressedIssue{
+			Original:    issue,
+			FixEvidence: v.Evidence,
+			Confidence:  v.Confidence,
+		})
+		return
+	case v.Addressed:
+		r.UnaddressedIssues = append(r.UnaddressedIssues, UnaddressedIssue{
+			Original:   issue,
+			Reason:     fmt.Sprintf("Low confidence (%d%%): %s", v.Confidence, v.Evidence),
+			Confidence: v.Confidence,
+		})
+	default:
+		r.UnaddressedIssues = append(r.UnaddressedIssues, UnaddressedIssue{
+			Original:   issue,
+			Reason:     v.Evidence,
+			Confidence: v.Confidence,
+		})
+	}
+	r.AllAddressed = false
+}
+
+// meanConfidence returns the mean confidence of the verdicts.
+func meanConfidence(verdicts map[int]Verdict) int {
+	total := 0
+	for _, v := range verdicts {
+		total += v.Confidence
+	}
+	return total / len(verdicts)
+}
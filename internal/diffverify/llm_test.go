@@ -0,0 +1,107 @@
+package diffverify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/scottbott"
+)
+
+// judgeServer serves response as ollama, recording the prompt it was sent.
+// An empty response fails the request.
+func judgeServer(t *testing.T, response string, prompt *string) config.ClaudeConfig {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		*prompt = req.Messages[len(req.Messages)-1].Content
+		if response == "" {
+			http.Error(w, "model unavailable", http.StatusInternalServerError)
+			return
+		}
+		body, _ := json.Marshal(map[string]any{"message": map[string]string{"content": response}, "prompt_eval_count": 500, "eval_count": 50})
+		w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return config.ClaudeConfig{Provider: config.ProviderConfig{Name: "ollama", BaseURL: srv.URL}}
+}
+
+func TestVerifyLLM(t *testing.T) {
+	issues := []scottbott.Issue{
+		{Severity: "major", File: "app.go", Line: 12, Description: "Rows are never closed", Suggestion: "defer rows.Close()"},
+		{Severity: "major", File: "app.go", Description: "Error from Scan is ignored"},
+		{Severity: "minor", File: "app.go", Description: "Query string is built inline"},
+		{Severity: "minor", File: "app.go", Description: "Function is too long"},
+	}
+	newDiff := `diff --git a/app.go b/app.go
+--- a/app.go
++++ b/app.go
+@@ -10,3 +10,5 @@
+ 	rows, err := db.Query(q)
++	defer rows.Close()
++	log.Println("querying")
+`
+
+	var prompt string
+	verifier := New(t.TempDir())
+	verifier.SetMinConfidence(50)
+	// Issue 4 is left to the heuristics
+	verifier.EnableLLM(judgeServer(t, `{"verdicts": [
+		{"issue": 1, "addressed": true, "confidence": 95, "evidence": "rows.Close is deferred after the query"},
+		{"issue": 2, "addressed": false, "confidence": 85, "evidence": "Scan's error is still discarded"},
+		{"issue": 3, "addressed": true, "confidence": 30, "evidence": "maybe moved"},
+		{"issue": 9, "addressed": true, "confidence": 100}
+	]}`, &prompt))
+
+	result, err := verifier.Verify(context.Background(), issues, "", newDiff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(prompt, "1. [major] Rows are never closed (app.go:12)\n   Suggested fix: defer rows.Close()") || !strings.Contains(prompt, "+\tdefer rows.Close()") {
+		t.Errorf("prompt is missing the issues or diff:\n%s", prompt)
+	}
+	if !result.LLM || result.AllAddressed || result.Confidence != 70 {
+		t.Errorf("LLM = %v, all addressed = %v, confidence = %d", result.LLM, result.AllAddressed, result.Confidence)
+	}
+	if len(result.AddressedIssues) != 2 || result.AddressedIssues[0].Confidence != 95 || result.AddressedIssues[1].Original.Description != "Function is too long" || !result.AddressedIssues[1].Heuristic {
+		t.Errorf("addressed = %+v", result.AddressedIssues)
+	}
+	if len(result.UnaddressedIssues) != 2 || result.UnaddressedIssues[0].Reason != "Scan's error is still discarded" || !strings.HasPrefix(result.UnaddressedIssues[1].Reason, "Low confidence (30%)") {
+		t.Errorf("unaddressed = %+v", result.UnaddressedIssues)
+	}
+	if result.Usage == nil || result.Usage.InputTokens != 500 {
+		t.Errorf("usage = %+v", result.Usage)
+	}
+
+	// A failed model leaves the heuristic verdicts, which credit any change to the file
+	verifier = New(t.TempDir())
+	verifier.EnableLLM(judgeServer(t, "", &prompt))
+	result, err = verifier.Verify(context.Background(), issues, "", newDiff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.LLM || len(result.AddressedIssues) != 4 {
+		t.Errorf("LLM = %v, addressed = %d, want the heuristic verdicts", result.LLM, len(result.AddressedIssues))
+	}
+}
+
+func TestParseVerdicts(t *testing.T) {
+	verdicts, err := parseVerdicts("Here you go:\n```json\n{\"verdicts\": [{\"issue\": 1, \"addressed\": true, \"confidence\": 140}]}\n```", 1)
+	if err != nil || len(verdicts) != 1 || verdicts[0].Confidence != 100 {
+		t.Errorf("verdicts = %+v, err = %v", verdicts, err)
+	}
+	if _, err := parseVerdicts("All fixed.", 1); err == nil {
+		t.Error("expected an error without JSON")
+	}
+	if _, err := parseVerdicts(`{"verdicts": [{"issue": 2, "addressed": true}]}`, 1); err == nil {
+		t.Error("expected an error without verdicts on the issues asked about")
+	}
+}
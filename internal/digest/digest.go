@@ -0,0 +1,158 @@
+// Package digest summarizes a day of boatman activity for the daily email:
+// runs attempted, PRs created and merged, total cost, and the failures
+// that still need someone to look at them.
+package digest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/checkpoint"
+	"github.com/philjestin/boatmanmode/internal/memory"
+)
+
+// MergeLookback is how far back PRs are checked for having merged during
+// the digest's window.
+const MergeLookback = 30 * 24 * time.Hour
+
+// MergedAtFunc looks up when a PR was merged; the zero time means it is
+// not merged.
+type MergedAtFunc func(url string) (time.Time, error)
+
+// Failure is a failed run no later run of its ticket has fixed.
+type Failure struct {
+	TicketID string
+	RunID    string
+	Step     checkpoint.Step
+	Error    string
+	At       time.Time
+}
+
+// Report is the activity between Since and Until.
+type Report struct {
+	Since, Until time.Time
+
+	// Runs attempted, and how many of them completed.
+	Runs      int
+	Completed int
+
+	// PRsCreated are the URLs of PRs opened; PRsMerged counts PRs, from
+	// up to MergeLookback before, that merged.
+	PRsCreated []string
+	PRsMerged  int
+
+	CostUSD float64
+
+	// Failures need attention: resume, retry or take over by hand.
+	Failures []Failure
+}
+
+// Build summarizes the runs in checkpoints and the PRs recorded in mems
+// between since and until. mergedAt may be nil to skip merge lookups.
+func Build(checkpoints []checkpoint.Checkpoint, mems []*memory.Memory, since, until time.Time, mergedAt MergedAtFunc) Report {
+	r := Report{Since: since, Until: until}
+	in := func(t time.Time) bool { return !t.Before(since) && t.Before(until) }
+
+	checkpoint.SortRecent(checkpoints)
+	fixed := make(map[string]bool)
+	for _, cp := range checkpoints {
+		if cp.CurrentStep == checkpoint.StepComplete {
+			// Newest first: a completed run fixes the older failures of its ticket
+			fixed[cp.TicketID] = true
+		}
+		if !in(cp.CreatedAt) {
+			continue
+		}
+		r.Runs++
+		r.CostUSD += cp.CostUSD
+		switch {
+		case cp.CurrentStep == checkpoint.StepComplete:
+			r.Completed++
+		case cp.Error != "" && !fixed[cp.TicketID]:
+			r.Failures = append(r.Failures, Failure{
+				TicketID: cp.TicketID, RunID: cp.ID, Step: cp.CurrentStep, Error: cp.Error, At: cp.UpdatedAt,
+			})
+			// Only the latest failure of a ticket is reported
+			fixed[cp.TicketID] = true
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, mem := range mems {
+		for _, rec := range mem.ScoreHistory {
+			if rec.PRURL == "" || seen[rec.PRURL] {
+				continue
+			}
+			seen[rec.PRURL] = true
+			if in(rec.RecordedAt) {
+				r.PRsCreated = append(r.PRsCreated, rec.PRURL)
+			}
+			if mergedAt != nil && rec.RecordedAt.After(since.Add(-MergeLookback)) {
+				if at, err := mergedAt(rec.PRURL); err == nil && in(at) {
+					r.PRsMerged++
+				}
+			}
+		}
+	}
+	sort.Strings(r.PRsCreated)
+	return r
+}
+
+// Subject is the email subject line.
+func (r Report) Subject() string {
+	return fmt.Sprintf("boatman digest %s: %d run(s), %d PR(s), $%.2f",
+		r.Until.Format("Jan 2"), r.Runs, len(r.PRsCreated), r.CostUSD)
+}
+
+// Text renders the report as the email body.
+func (r Report) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "boatman activity from %s to %s\n\n", r.Since.Format("Mon Jan 2 15:04"), r.Until.Format("Mon Jan 2 15:04"))
+	fmt.Fprintf(&b, "Runs attempted:  %d (%d completed)\n", r.Runs, r.Completed)
+	fmt.Fprintf(&b, "PRs created:     %d\n", len(r.PRsCreated))
+	fmt.Fprintf(&b, "PRs merged:      %d\n", r.PRsMerged)
+	fmt.Fprintf(&b, "Total cost:      $%.2f\n", r.CostUSD)
+
+	if len(r.PRsCreated) > 0 {
+		b.WriteString("\nNew PRs:\n")
+		for _, url := range r.PRsCreated {
+			b.WriteString("  - " + url + "\n")
+		}
+	}
+
+	if len(r.Failures) == 0 {
+		b.WriteString("\nNo failures need attention.\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "\nNeeds attention (%d):\n", len(r.Failures))
+	for _, f := range r.Failures {
+		fmt.Fprintf(&b, "  - %s failed at %s: %s\n", f.TicketID, f.Step, firstLine(f.Error))
+		fmt.Fprintf(&b, "    boatman resume %s\n", f.RunID)
+	}
+	return b.String()
+}
+
+// firstLine returns the first line of s, shortened for the email.
+func firstLine(s string) string {
+	s, _, _ = strings.Cut(strings.TrimSpace(s), "\n")
+	if len(s) > 200 {
+		s = s[:197] + "..."
+	}
+	return s
+}
+
+// NextSend returns the next time after now that the digest is sent, at
+// the HH:MM time of day in now's location.
+func NextSend(now time.Time, at string) (time.Time, error) {
+	t, err := time.Parse("15:04", at)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("notify.digest.at: want HH:MM, got %q", at)
+	}
+	next := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location())
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next, nil
+}
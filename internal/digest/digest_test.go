@@ -0,0 +1,104 @@
+package digest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/checkpoint"
+	"github.com/philjestin/boatmanmode/internal/memory"
+)
+
+var (
+	since = time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	until = since.Add(24 * time.Hour)
+)
+
+func run(id, ticket string, at time.Time, step checkpoint.Step, errMsg string, cost float64) checkpoint.Checkpoint {
+	return checkpoint.Checkpoint{ID: id, TicketID: ticket, CurrentStep: step, Error: errMsg, CostUSD: cost, CreatedAt: at, UpdatedAt: at.Add(time.Minute)}
+}
+
+func TestBuild(t *testing.T) {
+	checkpoints := []checkpoint.Checkpoint{
+		run("old", "ENG-1", since.Add(-time.Hour), checkpoint.StepComplete, "", 5),
+		run("a", "ENG-2", since.Add(time.Hour), checkpoint.StepComplete, "", 1.25),
+		run("b", "ENG-3", since.Add(2*time.Hour), checkpoint.StepExecution, "claude exited 1", 0.5),
+		run("c", "ENG-3", since.Add(3*time.Hour), checkpoint.StepReview, "review failed\nmore detail", 0.75),
+		run("d", "ENG-4", since.Add(4*time.Hour), checkpoint.StepExecution, "timeout", 0.25),
+		run("e", "ENG-4", since.Add(5*time.Hour), checkpoint.StepComplete, "", 1),
+	}
+	mems := []*memory.Memory{{ScoreHistory: []memory.ScoreRecord{
+		{PRURL: "https://github.com/acme/web/pull/2", RecordedAt: since.Add(time.Hour)},
+		{PRURL: "https://github.com/acme/web/pull/1", RecordedAt: since.Add(-48 * time.Hour)},
+		{PRURL: "https://github.com/acme/web/pull/0", RecordedAt: since.Add(-60 * 24 * time.Hour)},
+		{RecordedAt: since.Add(time.Hour)},
+	}}}
+	var looked []string
+	mergedAt := func(url string) (time.Time, error) {
+		looked = append(looked, url)
+		if strings.HasSuffix(url, "/1") {
+			return since.Add(6 * time.Hour), nil
+		}
+		return time.Time{}, nil
+	}
+
+	r := Build(checkpoints, mems, since, until, mergedAt)
+	if r.Runs != 5 || r.Completed != 2 {
+		t.Errorf("runs = %d (%d completed), want 5 (2)", r.Runs, r.Completed)
+	}
+	if r.CostUSD != 3.75 {
+		t.Errorf("cost = %.2f, want 3.75", r.CostUSD)
+	}
+	if len(r.PRsCreated) != 1 || r.PRsCreated[0] != "https://github.com/acme/web/pull/2" {
+		t.Errorf("PRs created = %v", r.PRsCreated)
+	}
+	if r.PRsMerged != 1 {
+		t.Errorf("PRs merged = %d, want 1", r.PRsMerged)
+	}
+	if len(looked) != 2 {
+		t.Errorf("looked up %v, want the PRs within the merge lookback", looked)
+	}
+	// ENG-4's failure was fixed by a later run; only ENG-3's latest counts
+	if len(r.Failures) != 1 || r.Failures[0].RunID != "c" || r.Failures[0].Step != checkpoint.StepReview {
+		t.Errorf("failures = %+v", r.Failures)
+	}
+}
+
+func TestText(t *testing.T) {
+	r := Report{
+		Since: since, Until: until, Runs: 3, Completed: 1, CostUSD: 2.5,
+		PRsCreated: []string{"https://github.com/acme/web/pull/2"},
+		Failures:   []Failure{{TicketID: "ENG-3", RunID: "c", Step: checkpoint.StepReview, Error: "review failed\nmore detail"}},
+	}
+	text := r.Text()
+	for _, want := range []string{
+		"Runs attempted:  3 (1 completed)",
+		"Total cost:      $2.50",
+		"  - https://github.com/acme/web/pull/2",
+		"Needs attention (1):",
+		"  - ENG-3 failed at review: review failed\n    boatman resume c\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text missing %q:\n%s", want, text)
+		}
+	}
+	if want := "boatman digest Jan 2: 3 run(s), 1 PR(s), $2.50"; r.Subject() != want {
+		t.Errorf("subject = %q, want %q", r.Subject(), want)
+	}
+	if text := (Report{Since: since, Until: until}).Text(); !strings.Contains(text, "No failures need attention.") {
+		t.Errorf("empty report text = %q", text)
+	}
+}
+
+func TestNextSend(t *testing.T) {
+	morning := time.Date(2026, 1, 2, 8, 30, 0, 0, time.UTC)
+	if next, err := NextSend(morning, "09:00"); err != nil || !next.Equal(morning.Add(30*time.Minute)) {
+		t.Errorf("NextSend before 09:00 = %v, %v", next, err)
+	}
+	if next, err := NextSend(morning, "08:30"); err != nil || !next.Equal(morning.Add(24*time.Hour)) {
+		t.Errorf("NextSend at 08:30 = %v, %v", next, err)
+	}
+	if _, err := NextSend(morning, "9am"); err == nil {
+		t.Error("expected an error for a bad time")
+	}
+}
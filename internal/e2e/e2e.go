@@ -0,0 +1,358 @@
+// Package e2e covers a ticket's acceptance criteria with end-to-end specs
+// in repos that use Playwright or Cypress: an agent writes or updates a
+// spec per criterion, and the specs a change touches are run headless,
+// their results read from the framework's JUnit report.
+package e2e
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/cmdoutput"
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/unidiff"
+)
+
+// Supported frameworks.
+const (
+	Playwright = "playwright"
+	Cypress    = "cypress"
+)
+
+// npx runs the frameworks' CLIs; tests replace it.
+var npx = "npx"
+
+// Framework is the E2E framework of a worktree.
+type Framework struct {
+	Name string
+	// Dir holds the specs, relative to the worktree
+	Dir string
+	// Ext is the suffix of spec files, e.g. ".spec.ts"
+	Ext string
+
+	worktreePath string
+	timeout      time.Duration
+}
+
+// configFiles name each framework's config files, which mark a repo as
+// using it.
+var configFiles = map[string][]string{
+	Playwright: {"playwright.config.ts", "playwright.config.js", "playwright.config.mjs", "playwright.config.cjs"},
+	Cypress:    {"cypress.config.ts", "cypress.config.js", "cypress.config.mjs", "cypress.config.cjs", "cypress.json"},
+}
+
+// playwrightTestDir reads testDir from a Playwright config.
+var playwrightTestDir = regexp.MustCompile(`testDir\s*:\s*['"]([^'"]+)['"]`)
+
+// Detect returns the worktree's E2E framework under e2e.*, or nil when it
+// has none. e2e.framework and e2e.dir override what is found.
+func Detect(worktreePath string, cfg config.E2EConfig) *Framework {
+	name, configFile := cfg.Framework, ""
+	for _, candidate := range []string{Playwright, Cypress} {
+		if name != "" && name != candidate {
+			continue
+		}
+		for _, file := range configFiles[candidate] {
+			if _, err := os.Stat(filepath.Join(worktreePath, file)); err == nil {
+				name, configFile = candidate, file
+				break
+			}
+		}
+		if configFile != "" {
+			break
+		}
+	}
+	if configFile == "" && cfg.Framework == "" {
+		return nil
+	}
+
+	f := &Framework{Name: name, Dir: cfg.Dir, worktreePath: worktreePath, timeout: cfg.Timeout}
+	typescript := strings.HasSuffix(configFile, ".ts") || exists(filepath.Join(worktreePath, "tsconfig.json"))
+	switch name {
+	case Playwright:
+		f.Ext = ".spec.js"
+		if typescript {
+			f.Ext = ".spec.ts"
+		}
+		if f.Dir == "" {
+			if data, err := os.ReadFile(filepath.Join(worktreePath, configFile)); err == nil {
+				if m := playwrightTestDir.FindSubmatch(data); m != nil {
+					f.Dir = filepath.ToSlash(filepath.Clean(string(m[1])))
+				}
+			}
+		}
+		if f.Dir == "" {
+			f.Dir = firstDir(worktreePath, "e2e", "tests/e2e", "tests")
+		}
+	case Cypress:
+		f.Ext = ".cy.js"
+		if typescript {
+			f.Ext = ".cy.ts"
+		}
+		if f.Dir == "" {
+			f.Dir = firstDir(worktreePath, "cypress/e2e", "cypress/integration")
+		}
+	}
+	return f
+}
+
+// firstDir returns the first of dirs that exists in the worktree, or the
+// first one if none does.
+func firstDir(worktreePath string, dirs ...string) string {
+	for _, dir := range dirs {
+		if info, err := os.Stat(filepath.Join(worktreePath, dir)); err == nil && info.IsDir() {
+			return dir
+		}
+	}
+	return dirs[0]
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Specs returns the spec files diff adds or changes, in diff order.
+func (f *Framework) Specs(diff string) []string {
+	var specs []string
+	for _, file := range unidiff.Parse(diff) {
+		if file.Status == unidiff.Deleted {
+			continue
+		}
+		path := file.Path()
+		if strings.HasPrefix(path, f.Dir+"/") && isSpec(path) {
+			specs = append(specs, path)
+		}
+	}
+	return specs
+}
+
+// isSpec reports whether path names a Playwright or Cypress spec.
+func isSpec(path string) bool {
+	for _, marker := range []string{".spec.", ".test.", ".cy."} {
+		if strings.Contains(filepath.Base(path), marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// Test is the outcome of one E2E test.
+type Test struct {
+	Name    string
+	File    string
+	Passed  bool
+	Failure string // First line of the failure message
+}
+
+// Result holds the outcome of an E2E run.
+type Result struct {
+	Framework string
+	Tests     []Test
+}
+
+// PassedCount returns how many tests passed.
+func (r *Result) PassedCount() int {
+	n := 0
+	for _, t := range r.Tests {
+		if t.Passed {
+			n++
+		}
+	}
+	return n
+}
+
+// Failed returns the failing tests.
+func (r *Result) Failed() []Test {
+	var failed []Test
+	for _, t := range r.Tests {
+		if !t.Passed {
+			failed = append(failed, t)
+		}
+	}
+	return failed
+}
+
+// criterionTag is how specs name the acceptance criterion a test covers.
+var criterionTag = regexp.MustCompile(`\bAC(\d+)\b`)
+
+// Criterion returns the ID of the acceptance criterion test covers, or 0.
+func (t Test) Criterion() int {
+	if m := criterionTag.FindStringSubmatch(t.Name); m != nil {
+		id, _ := strconv.Atoi(m[1])
+		return id
+	}
+	return 0
+}
+
+// Run runs specs headless. A test failing is not an error; a framework
+// that cannot run or reports nothing is.
+func (f *Framework) Run(ctx context.Context, specs []string) (*Result, error) {
+	if len(specs) == 0 {
+		return &Result{Framework: f.Name}, nil
+	}
+	if f.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, f.timeout)
+		defer cancel()
+	}
+	reports, err := os.MkdirTemp("", "boatman-e2e-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(reports)
+
+	var args []string
+	env := append(os.Environ(), "CI=1")
+	switch f.Name {
+	case Playwright:
+		args = append(append([]string{"playwright", "test"}, specs...), "--reporter=junit")
+		env = append(env, "PLAYWRIGHT_JUNIT_OUTPUT_NAME="+filepath.Join(reports, "results.xml"))
+	case Cypress:
+		args = []string{"cypress", "run", "--headless", "--spec", strings.Join(specs, ","),
+			"--reporter", "junit", "--reporter-options", "mochaFile=" + filepath.Join(reports, "results-[hash].xml")}
+	default:
+		return nil, fmt.Errorf("unsupported E2E framework %q", f.Name)
+	}
+
+	cmd := exec.CommandContext(ctx, npx, args...)
+	cmd.Dir = f.worktreePath
+	cmd.Env = env
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	runErr := cmd.Run()
+
+	result := &Result{Framework: f.Name}
+	files, _ := filepath.Glob(filepath.Join(reports, "*.xml"))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		tests, err := parseJUnit(data)
+		if err != nil {
+			continue
+		}
+		for _, t := range tests {
+			t.File = specPath(t.File, specs)
+			result.Tests = append(result.Tests, t)
+		}
+	}
+	if len(result.Tests) == 0 {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("%s timed out after %s", f.Name, f.timeout)
+		}
+		if runErr != nil {
+			return nil, fmt.Errorf("%s failed: %w: %s", f.Name, runErr, cmdoutput.Tail(out.String(), 10))
+		}
+		return nil, fmt.Errorf("%s reported no tests", f.Name)
+	}
+	return result, nil
+}
+
+type junitReport struct {
+	Suites []junitSuite `xml:"testsuite"`
+	Cases  []junitCase  `xml:"testcase"` // When the root is a single testsuite
+}
+
+type junitSuite struct {
+	Name   string       `xml:"name,attr"`
+	File   string       `xml:"file,attr"`
+	Cases  []junitCase  `xml:"testcase"`
+	Suites []junitSuite `xml:"testsuite"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr"`
+	Failure   *junitFailure `xml:"failure"`
+	Error     *junitFailure `xml:"error"`
+	Skipped   *struct{}     `xml:"skipped"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// parseJUnit reads the tests of a JUnit XML report. Skipped tests are
+// left out.
+func parseJUnit(data []byte) ([]Test, error) {
+	var report junitReport
+	if err := xml.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("invalid JUnit report: %w", err)
+	}
+	var tests []Test
+	var walk func(suites []junitSuite, file string)
+	add := func(cases []junitCase, file string) {
+		for _, c := range cases {
+			if c.Skipped != nil {
+				continue
+			}
+			t := Test{Name: c.Name, File: c.File, Passed: true}
+			if t.File == "" {
+				t.File = file
+			}
+			for _, failure := range []*junitFailure{c.Failure, c.Error} {
+				if failure == nil {
+					continue
+				}
+				t.Passed = false
+				message := failure.Message
+				if strings.TrimSpace(message) == "" {
+					message = failure.Text
+				}
+				t.Failure = firstLine(message)
+				break
+			}
+			tests = append(tests, t)
+		}
+	}
+	walk = func(suites []junitSuite, file string) {
+		for _, s := range suites {
+			suiteFile := file
+			if s.File != "" {
+				suiteFile = s.File
+			} else if isSpec(s.Name) {
+				// Playwright names suites after their spec file
+				suiteFile = s.Name
+			}
+			add(s.Cases, suiteFile)
+			walk(s.Suites, suiteFile)
+		}
+	}
+	add(report.Cases, "")
+	walk(report.Suites, "")
+	return tests, nil
+}
+
+// specPath returns the spec of specs that file, as a report names it,
+// refers to. Reports may name specs relative to the spec directory.
+func specPath(file string, specs []string) string {
+	file = filepath.ToSlash(file)
+	for _, spec := range specs {
+		if spec == file || strings.HasSuffix(spec, "/"+file) {
+			return spec
+		}
+	}
+	return file
+}
+
+func firstLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+	return s
+}
@@ -0,0 +1,122 @@
+package e2e
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+)
+
+func TestDetect(t *testing.T) {
+	dir := t.TempDir()
+	if f := Detect(dir, config.E2EConfig{}); f != nil {
+		t.Errorf("Detect without a framework = %+v", f)
+	}
+
+	os.WriteFile(filepath.Join(dir, "playwright.config.ts"), []byte("export default defineConfig({\n  testDir: './browser',\n})\n"), 0644)
+	f := Detect(dir, config.E2EConfig{})
+	if f == nil || f.Name != Playwright || f.Dir != "browser" || f.Ext != ".spec.ts" {
+		t.Errorf("Detect = %+v", f)
+	}
+
+	cypress := t.TempDir()
+	os.WriteFile(filepath.Join(cypress, "cypress.config.js"), []byte("module.exports = {}\n"), 0644)
+	os.MkdirAll(filepath.Join(cypress, "cypress", "integration"), 0755)
+	f = Detect(cypress, config.E2EConfig{})
+	if f == nil || f.Name != Cypress || f.Dir != "cypress/integration" || f.Ext != ".cy.js" {
+		t.Errorf("Detect = %+v", f)
+	}
+	if f := Detect(cypress, config.E2EConfig{Framework: Playwright, Dir: "e2e"}); f == nil || f.Name != Playwright || f.Dir != "e2e" {
+		t.Errorf("Detect with overrides = %+v", f)
+	}
+}
+
+func TestSpecs(t *testing.T) {
+	diff := `diff --git a/e2e/checkout.spec.ts b/e2e/checkout.spec.ts
+new file mode 100644
+--- /dev/null
++++ b/e2e/checkout.spec.ts
+@@ -0,0 +1 @@
++test('AC1: JPY totals', async () => {})
+diff --git a/e2e/helpers.ts b/e2e/helpers.ts
+--- a/e2e/helpers.ts
++++ b/e2e/helpers.ts
+@@ -1 +1 @@
+-old
++new
+diff --git a/src/round.test.ts b/src/round.test.ts
+--- a/src/round.test.ts
++++ b/src/round.test.ts
+@@ -1 +1 @@
+-old
++new
+`
+	f := &Framework{Name: Playwright, Dir: "e2e", Ext: ".spec.ts"}
+	if specs := f.Specs(diff); len(specs) != 1 || specs[0] != "e2e/checkout.spec.ts" {
+		t.Errorf("Specs = %v", specs)
+	}
+}
+
+const playwrightReport = `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites tests="3" failures="1">
+  <testsuite name="checkout.spec.ts" tests="3">
+    <testcase name="checkout › AC1: JPY totals have no decimals" classname="checkout.spec.ts"></testcase>
+    <testcase name="checkout › AC2: BHD totals have three decimals" classname="checkout.spec.ts">
+      <failure message="expect(received).toHaveText(expected)&#10;Expected: &quot;1.234&quot;" type="FAILURE">stack</failure>
+    </testcase>
+    <testcase name="checkout › AC3: currency switcher" classname="checkout.spec.ts"><skipped/></testcase>
+  </testsuite>
+</testsuites>`
+
+func TestParseJUnit(t *testing.T) {
+	tests, err := parseJUnit([]byte(playwrightReport))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tests) != 2 {
+		t.Fatalf("tests = %+v, want the skipped one left out", tests)
+	}
+	if !tests[0].Passed || tests[0].Criterion() != 1 || tests[0].File != "checkout.spec.ts" {
+		t.Errorf("tests[0] = %+v", tests[0])
+	}
+	if tests[1].Passed || tests[1].Criterion() != 2 || tests[1].Failure != "expect(received).toHaveText(expected)" {
+		t.Errorf("tests[1] = %+v", tests[1])
+	}
+	if _, err := parseJUnit([]byte("not xml <")); err == nil {
+		t.Error("parseJUnit accepted a broken report")
+	}
+}
+
+func TestRun(t *testing.T) {
+	bin := t.TempDir()
+	report := filepath.Join(bin, "report.xml")
+	os.WriteFile(report, []byte(playwrightReport), 0644)
+	// The fake npx writes the report where Playwright is told to
+	script := "#!/bin/sh\necho \"$@\" > " + filepath.Join(bin, "args") + "\ncp " + report + " \"$PLAYWRIGHT_JUNIT_OUTPUT_NAME\"\nexit 1\n"
+	fake := filepath.Join(bin, "npx")
+	os.WriteFile(fake, []byte(script), 0755)
+	defer func(old string) { npx = old }(npx)
+	npx = fake
+
+	f := &Framework{Name: Playwright, Dir: "e2e", Ext: ".spec.ts", worktreePath: t.TempDir()}
+	result, err := f.Run(context.Background(), []string{"e2e/checkout.spec.ts"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.PassedCount() != 1 || len(result.Failed()) != 1 || result.Failed()[0].File != "e2e/checkout.spec.ts" {
+		t.Errorf("result = %+v", result)
+	}
+	args, _ := os.ReadFile(filepath.Join(bin, "args"))
+	if !strings.Contains(string(args), "playwright test e2e/checkout.spec.ts --reporter=junit") {
+		t.Errorf("args = %q", args)
+	}
+
+	// A framework that reports nothing cannot run
+	os.WriteFile(fake, []byte("#!/bin/sh\necho 'browserType.launch: Executable does not exist'\nexit 1\n"), 0755)
+	if _, err := f.Run(context.Background(), []string{"e2e/checkout.spec.ts"}); err == nil || !strings.Contains(err.Error(), "Executable does not exist") {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package e2e
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/acceptance"
+	"github.com/philjestin/boatmanmode/internal/claude"
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/cost"
+)
+
+// ErrNoTools is returned when the configured model can't write files.
+var ErrNoTools = errors.New("writing E2E specs needs the Claude CLI; API providers cannot use tools")
+
+// Writer is a Claude agent that writes E2E specs for acceptance criteria.
+type Writer struct {
+	client    *claude.Client
+	framework *Framework
+}
+
+// NewWriter creates a Writer for the worktree's framework. It runs on the
+// executor model, and may only read the code and write files.
+func NewWriter(worktreePath string, framework *Framework, cfg *config.Config) *Writer {
+	client := claude.NewWithTools(worktreePath, "e2e", []string{"Read", "Grep", "Glob", "Write", "Edit"})
+	if cfg.Claude.Models.Executor != "" {
+		client.Model = cfg.Claude.Models.Executor
+	}
+	client.Sampling = cfg.Claude.SamplingFor("executor")
+	client.EnablePromptCaching = cfg.Claude.EnablePromptCaching
+	client.Configure(cfg.Claude)
+	return &Writer{client: client, framework: framework}
+}
+
+// Write has the agent write or update specs covering each criterion, one
+// test per criterion named after its ID (e.g. "AC2: ..."), so results can
+// be matched back to the checklist. The specs it wrote show in the diff.
+func (w *Writer) Write(ctx context.Context, title string, criteria *acceptance.Checklist) (*cost.Usage, error) {
+	if !w.client.SupportsTools() {
+		return nil, ErrNoTools
+	}
+	systemPrompt := fmt.Sprintf(`You write end-to-end tests with %s for a change another engineer just implemented.
+Look at how the existing specs in %s are written (fixtures, page objects, helpers, base URL) and follow them.
+
+For each acceptance criterion, write one test that exercises it through the UI the way a user would.
+Start every test's title with the criterion's ID, e.g. "AC2: ". Put the tests in a spec file under %s
+named after the feature, ending in %s; update an existing spec for the same feature instead of duplicating it.
+Do not change application code, configuration or other specs. Do not run the tests.
+
+When done, list the spec files you wrote or changed.`, w.framework.Name, w.framework.Dir, w.framework.Dir, w.framework.Ext)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Ticket: %s\n\nAcceptance criteria:\n", title))
+	for _, criterion := range criteria.Criteria {
+		sb.WriteString(fmt.Sprintf("- AC%d: %s\n", criterion.ID, criterion.Text))
+	}
+
+	_, usage, err := w.client.Message(ctx, systemPrompt, sb.String())
+	if err != nil {
+		return usage, fmt.Errorf("E2E spec writer failed: %w", err)
+	}
+	return usage, nil
+}
@@ -0,0 +1,288 @@
+// Package egress confines the network access of agent sessions. A run
+// starts a local HTTP proxy that only lets through requests to hosts the
+// network policy allows, and the sessions that can run commands are
+// pointed at it with the standard proxy variables, which package managers,
+// curl, git and the Claude CLI itself honor.
+package egress
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+)
+
+// Registries is expanded in allow lists to the public package registries.
+const Registries = "registries"
+
+// registryHosts are the hosts of the public package registries.
+var registryHosts = []string{
+	"registry.npmjs.org", "registry.yarnpkg.com",
+	"pypi.org", "files.pythonhosted.org",
+	"rubygems.org", "index.rubygems.org",
+	"proxy.golang.org", "sum.golang.org", "storage.googleapis.com",
+	"crates.io", "index.crates.io", "static.crates.io",
+	"repo.maven.apache.org", "repo1.maven.org", "plugins.gradle.org",
+	"api.nuget.org", "packagist.org", "repo.packagist.org",
+}
+
+// apiHosts are always allowed: the Claude CLI reaches the model through
+// the proxy too.
+var apiHosts = []string{"anthropic.com", "*.anthropic.com", "claude.ai", "*.claude.ai"}
+
+// Policy decides which hosts sessions may reach.
+type Policy struct {
+	allow        []string
+	deny         []string
+	defaultAllow bool
+}
+
+// NewPolicy returns the policy configured by cfg. The model API hosts, and
+// the host of ANTHROPIC_BASE_URL when set, are always allowed.
+func NewPolicy(cfg config.NetworkConfig) *Policy {
+	p := &Policy{deny: cfg.Deny, defaultAllow: cfg.Default == "allow"}
+	p.allow = append(p.allow, apiHosts...)
+	if u, err := url.Parse(os.Getenv("ANTHROPIC_BASE_URL")); err == nil && u.Hostname() != "" {
+		p.allow = append(p.allow, u.Hostname())
+	}
+	for _, host := range cfg.Allow {
+		if host == Registries {
+			p.allow = append(p.allow, registryHosts...)
+		} else {
+			p.allow = append(p.allow, host)
+		}
+	}
+	return p
+}
+
+// Allowed reports whether sessions may reach host. Deny rules win over
+// allow rules; hosts matching neither get the default.
+func (p *Policy) Allowed(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, pattern := range p.deny {
+		if match(pattern, host) {
+			return false
+		}
+	}
+	for _, pattern := range p.allow {
+		if match(pattern, host) {
+			return true
+		}
+	}
+	return p.defaultAllow
+}
+
+// match reports whether host matches pattern: a host name, which also
+// matches its subdomains when written as *.example.com, or * for any host.
+func match(pattern, host string) bool {
+	pattern = strings.ToLower(pattern)
+	switch {
+	case pattern == "*":
+		return true
+	case strings.HasPrefix(pattern, "*."):
+		return strings.HasSuffix(host, pattern[1:])
+	}
+	return host == pattern
+}
+
+// Proxy is a running egress proxy.
+type Proxy struct {
+	policy    *Policy
+	listener  net.Listener
+	server    *http.Server
+	transport *http.Transport
+
+	mu      sync.Mutex
+	blocked []string
+	out     io.Writer             // Progress output; stdout when nil
+	tunnels map[net.Conn]net.Conn // Open tunnels' client and upstream connections
+	closed  bool
+}
+
+// Start starts the egress proxy on a local port. Returns nil when the
+// policy is disabled.
+func Start(cfg config.NetworkConfig) (*Proxy, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	return StartPolicy(NewPolicy(cfg))
+}
+
+// StartPolicy starts an egress proxy enforcing policy.
+func StartPolicy(policy *Policy) (*Proxy, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start egress proxy: %w", err)
+	}
+	p := &Proxy{
+		policy:    policy,
+		listener:  listener,
+		transport: &http.Transport{Proxy: nil, ResponseHeaderTimeout: 5 * time.Minute},
+	}
+	p.server = &http.Server{Handler: p, ReadHeaderTimeout: 30 * time.Second}
+	go p.server.Serve(listener)
+	return p, nil
+}
+
+// URL returns the proxy's URL.
+func (p *Proxy) URL() string {
+	return "http://" + p.listener.Addr().String()
+}
+
+// Env returns the environment pointing a session at the proxy. Local
+// addresses stay direct, for servers the session runs itself.
+func (p *Proxy) Env() map[string]string {
+	env := map[string]string{"NO_PROXY": "localhost,127.0.0.1,::1", "no_proxy": "localhost,127.0.0.1,::1"}
+	for _, key := range []string{"HTTP_PROXY", "HTTPS_PROXY", "ALL_PROXY"} {
+		env[key] = p.URL()
+		env[strings.ToLower(key)] = p.URL()
+	}
+	return env
+}
+
+// Blocked returns the hosts the proxy refused, in the order first refused.
+func (p *Proxy) Blocked() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.blocked...)
+}
+
+// Close stops the proxy, closing open tunnels. The server does not track
+// hijacked connections, so the proxy closes them itself.
+func (p *Proxy) Close() error {
+	p.mu.Lock()
+	p.closed = true
+	for client, upstream := range p.tunnels {
+		client.Close()
+		upstream.Close()
+	}
+	p.mu.Unlock()
+	p.transport.CloseIdleConnections()
+	return p.server.Close()
+}
+
+// ServeHTTP proxies a request: CONNECT tunnels for HTTPS, and plain HTTP
+// requests with absolute URLs.
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	host := r.URL.Hostname()
+	if r.Method == http.MethodConnect {
+		host, _, _ = net.SplitHostPort(r.Host)
+	} else if !r.URL.IsAbs() {
+		http.Error(w, "boatman egress proxy: only proxy requests are served", http.StatusBadRequest)
+		return
+	}
+	if !p.policy.Allowed(host) {
+		p.refuse(host)
+		http.Error(w, fmt.Sprintf("boatman egress policy blocks network access to %s", host), http.StatusForbidden)
+		return
+	}
+
+	if r.Method == http.MethodConnect {
+		p.tunnel(w, r)
+		return
+	}
+	out := r.Clone(r.Context())
+	out.RequestURI = ""
+	for _, header := range []string{"Proxy-Connection", "Proxy-Authorization", "Connection", "Keep-Alive", "Te", "Trailer", "Upgrade"} {
+		out.Header.Del(header)
+	}
+	resp, err := p.transport.RoundTrip(out)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+	for key, values := range resp.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, resp.Body)
+}
+
+// tunnel connects the client to r.Host and copies bytes both ways.
+func (p *Proxy) tunnel(w http.ResponseWriter, r *http.Request) {
+	upstream, err := net.DialTimeout("tcp", r.Host, 30*time.Second)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		upstream.Close()
+		http.Error(w, "boatman egress proxy: tunnels unsupported", http.StatusInternalServerError)
+		return
+	}
+	client, _, err := hijacker.Hijack()
+	if err != nil {
+		upstream.Close()
+		return
+	}
+	if !p.track(client, upstream) {
+		client.Close()
+		upstream.Close()
+		return
+	}
+	defer p.untrack(client)
+	client.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n"))
+	done := make(chan struct{}, 2)
+	go func() { io.Copy(upstream, client); done <- struct{}{} }()
+	go func() { io.Copy(client, upstream); done <- struct{}{} }()
+	<-done
+	client.Close()
+	upstream.Close()
+}
+
+// track records an open tunnel so Close can end it, reporting false once
+// the proxy is closed.
+func (p *Proxy) track(client, upstream net.Conn) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return false
+	}
+	if p.tunnels == nil {
+		p.tunnels = make(map[net.Conn]net.Conn)
+	}
+	p.tunnels[client] = upstream
+	return true
+}
+
+// untrack forgets a tunnel that has ended.
+func (p *Proxy) untrack(client net.Conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.tunnels, client)
+}
+
+// refuse records a refused host, printing it the first time.
+func (p *Proxy) refuse(host string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, h := range p.blocked {
+		if h == host {
+			return
+		}
+	}
+	p.blocked = append(p.blocked, host)
+	out := p.out
+	if out == nil {
+		out = os.Stdout
+	}
+	fmt.Fprintf(out, "   🚫 Network access to %s blocked by the egress policy\n", host)
+}
+
+// SetOutput sends the proxy's reports of refused hosts to w.
+func (p *Proxy) SetOutput(w io.Writer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.out = w
+}
@@ -0,0 +1,152 @@
+package egress
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+)
+
+func TestPolicy(t *testing.T) {
+	t.Setenv("ANTHROPIC_BASE_URL", "https://llm-gateway.internal.example.com/v1")
+	p := NewPolicy(config.NetworkConfig{
+		Allow:   []string{Registries, "*.github.com", "github.com"},
+		Deny:    []string{"gist.github.com"},
+		Default: "deny",
+	})
+
+	allowed := []string{"registry.npmjs.org", "pypi.org", "github.com", "api.github.com", "API.GitHub.com.", "api.anthropic.com", "llm-gateway.internal.example.com"}
+	for _, host := range allowed {
+		if !p.Allowed(host) {
+			t.Errorf("Allowed(%q) = false", host)
+		}
+	}
+	blocked := []string{"gist.github.com", "pastebin.com", "evilgithub.com", "github.com.evil.io", "npmjs.org"}
+	for _, host := range blocked {
+		if p.Allowed(host) {
+			t.Errorf("Allowed(%q) = true", host)
+		}
+	}
+
+	p = NewPolicy(config.NetworkConfig{Deny: []string{"*.pastebin.com"}, Default: "allow"})
+	if !p.Allowed("example.com") || p.Allowed("www.pastebin.com") {
+		t.Error("default allow should let through all but denied hosts")
+	}
+}
+
+func TestProxy(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "hello from "+r.Host)
+	}))
+	defer upstream.Close()
+
+	if p, err := Start(config.NetworkConfig{}); p != nil || err != nil {
+		t.Fatalf("Start = %v, %v with the policy disabled", p, err)
+	}
+	p, err := StartPolicy(NewPolicy(config.NetworkConfig{Allow: []string{"127.0.0.1"}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	proxyURL, _ := url.Parse(p.URL())
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+
+	resp, err := client.Get(upstream.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(string(body), "hello from 127.0.0.1") {
+		t.Errorf("allowed request = %d %q", resp.StatusCode, body)
+	}
+
+	tlsUpstream := httptest.NewTLSServer(upstream.Config.Handler)
+	defer tlsUpstream.Close()
+	tlsClient := tlsUpstream.Client()
+	tlsClient.Transport.(*http.Transport).Proxy = http.ProxyURL(proxyURL)
+	resp, err = tlsClient.Get(tlsUpstream.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("tunneled request = %d", resp.StatusCode)
+	}
+
+	for i := 0; i < 2; i++ {
+		resp, err = client.Get("http://exfil.example.com/upload")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("blocked request = %d, want 403", resp.StatusCode)
+		}
+	}
+	// HTTPS goes through CONNECT, which is refused before dialing
+	if _, err := client.Get("https://exfil.example.com/upload"); err == nil {
+		t.Error("CONNECT to a blocked host succeeded")
+	}
+	if blocked := p.Blocked(); len(blocked) != 1 || blocked[0] != "exfil.example.com" {
+		t.Errorf("Blocked = %v", blocked)
+	}
+
+	env := p.Env()
+	if env["HTTPS_PROXY"] != p.URL() || env["https_proxy"] != p.URL() || !strings.HasPrefix(env["NO_PROXY"], "localhost,") {
+		t.Errorf("Env = %v", env)
+	}
+}
+
+func TestCloseEndsTunnels(t *testing.T) {
+	echo, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer echo.Close()
+	go func() {
+		for {
+			conn, err := echo.Accept()
+			if err != nil {
+				return
+			}
+			go io.Copy(conn, conn)
+		}
+	}()
+
+	p, err := StartPolicy(NewPolicy(config.NetworkConfig{Allow: []string{"127.0.0.1"}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn, err := net.Dial("tcp", p.listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	fmt.Fprintf(conn, "CONNECT %s HTTP/1.1\r\nHost: %[1]s\r\n\r\n", echo.Addr())
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("CONNECT = %v, %v", resp, err)
+	}
+	io.WriteString(conn, "ping")
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(reader, buf); err != nil || string(buf) != "ping" {
+		t.Fatalf("tunnel echo = %q, %v", buf, err)
+	}
+
+	p.Close()
+	if _, err := reader.ReadByte(); err != io.EOF {
+		t.Errorf("Expected the tunnel to be closed with the proxy, got %v", err)
+	}
+}
@@ -0,0 +1,190 @@
+// Package estimate predicts the effort of automating a task from its plan
+// and this repository's run history, before any code is written.
+package estimate
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/memory"
+	"github.com/philjestin/boatmanmode/internal/planner"
+)
+
+// Complexity levels.
+const (
+	ComplexityLow    = "low"
+	ComplexityMedium = "medium"
+	ComplexityHigh   = "high"
+)
+
+// historyWindow is how many recent runs the prediction averages over.
+const historyWindow = 20
+
+// defaultIterations is assumed when the repo has no run history.
+const defaultIterations = 2.0
+
+// Estimate is a structured prediction for one task.
+type Estimate struct {
+	TaskID     string `json:"task_id"`
+	Title      string `json:"title"`
+	Complexity string `json:"complexity"`
+	// ComplexityScore is the raw score behind Complexity
+	ComplexityScore int      `json:"complexity_score"`
+	FilesAffected   []string `json:"files_affected"`
+	DirsAffected    []string `json:"dirs_affected,omitempty"`
+	Steps           int      `json:"steps"`
+	Warnings        []string `json:"warnings,omitempty"`
+
+	PredictedIterations float64 `json:"predicted_iterations"`
+	PredictedCostUSD    float64 `json:"predicted_cost_usd"`
+	PlanningCostUSD     float64 `json:"planning_cost_usd"`
+
+	// HistoryRuns is how many past runs the prediction is based on;
+	// 0 means defaults and the planning cost were used instead.
+	HistoryRuns int `json:"history_runs"`
+	// SuccessRate is the share of past runs that passed review and didn't
+	// break the base branch once merged (0-1).
+	SuccessRate float64 `json:"success_rate,omitempty"`
+	// BrokeMain is how many past runs broke the base branch once merged.
+	BrokeMain int `json:"broke_main,omitempty"`
+
+	// SuccessLikelihood (0-100) and Risks are set by AssessSuccess.
+	SuccessLikelihood int    `json:"success_likelihood"`
+	Risks             []Risk `json:"risks,omitempty"`
+}
+
+// New estimates a task from its plan and the planning cost. mem may be nil.
+func New(taskID, title string, plan *planner.Plan, planningCostUSD float64, mem *memory.Memory, maxIterations int) *Estimate {
+	e := &Estimate{
+		TaskID:          taskID,
+		Title:           title,
+		FilesAffected:   plan.RelevantFiles,
+		DirsAffected:    plan.RelevantDirs,
+		Steps:           len(plan.Approach),
+		Warnings:        plan.Warnings,
+		PlanningCostUSD: planningCostUSD,
+	}
+	e.ComplexityScore = len(plan.RelevantFiles) + len(plan.RelevantDirs) + len(plan.Approach)/2 + 2*len(plan.Warnings)
+	switch {
+	case e.ComplexityScore <= 5:
+		e.Complexity = ComplexityLow
+	case e.ComplexityScore <= 12:
+		e.Complexity = ComplexityMedium
+	default:
+		e.Complexity = ComplexityHigh
+	}
+
+	iterations := defaultIterations
+	var costPerFile float64
+	if records := recentRecords(mem); len(records) > 0 {
+		e.HistoryRuns = len(records)
+		var totalIterations, passed, costed, costedFiles int
+		var totalCost float64
+		for _, r := range records {
+			totalIterations += r.Iterations
+			if r.BrokeMain {
+				e.BrokeMain++
+			} else if r.Passed {
+				passed++
+			}
+			if r.CostUSD > 0 {
+				costed++
+				totalCost += r.CostUSD
+				costedFiles += max(r.FilesChanged, 1)
+			}
+		}
+		iterations = float64(totalIterations) / float64(len(records))
+		e.SuccessRate = float64(passed) / float64(len(records))
+		if costed > 0 {
+			costPerFile = totalCost / float64(costedFiles)
+		}
+	}
+
+	iterations *= complexityFactor(e.Complexity)
+	iterations = math.Max(1, iterations)
+	if maxIterations > 0 {
+		iterations = math.Min(iterations, float64(maxIterations))
+	}
+	e.PredictedIterations = round(iterations, 1)
+
+	if costPerFile > 0 {
+		e.PredictedCostUSD = costPerFile * float64(max(len(plan.RelevantFiles), 1)) * complexityFactor(e.Complexity)
+	} else {
+		// Planning, execution, then a review and refactor per iteration,
+		// each costing roughly what planning did
+		e.PredictedCostUSD = planningCostUSD * (2 + 2*iterations)
+	}
+	e.PredictedCostUSD = round(e.PredictedCostUSD, 2)
+	return e
+}
+
+// recentRecords returns the last historyWindow score records.
+func recentRecords(mem *memory.Memory) []memory.ScoreRecord {
+	if mem == nil {
+		return nil
+	}
+	records := mem.ScoreHistory
+	if len(records) > historyWindow {
+		records = records[len(records)-historyWindow:]
+	}
+	return records
+}
+
+// complexityFactor scales history averages by how hard this task looks.
+func complexityFactor(complexity string) float64 {
+	switch complexity {
+	case ComplexityLow:
+		return 0.75
+	case ComplexityHigh:
+		return 1.5
+	}
+	return 1
+}
+
+func round(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p) / p
+}
+
+// Format returns a human-readable estimate.
+func (e *Estimate) Format() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("📐 Estimate: %s (%s)\n\n", e.Title, e.TaskID))
+	sb.WriteString(fmt.Sprintf("   Complexity:  %s (score %d)\n", e.Complexity, e.ComplexityScore))
+	sb.WriteString(fmt.Sprintf("   Files:       %d", len(e.FilesAffected)))
+	if len(e.DirsAffected) > 0 {
+		sb.WriteString(fmt.Sprintf(" in %d dir(s)", len(e.DirsAffected)))
+	}
+	sb.WriteString(fmt.Sprintf(", %d plan step(s)\n", e.Steps))
+	sb.WriteString(fmt.Sprintf("   Iterations:  ~%.1f\n", e.PredictedIterations))
+	sb.WriteString(fmt.Sprintf("   Cost:        ~$%.2f (planning: $%.2f)\n", e.PredictedCostUSD, e.PlanningCostUSD))
+	if e.SuccessLikelihood > 0 {
+		sb.WriteString(fmt.Sprintf("   Success:     %d%% likely without human help\n", e.SuccessLikelihood))
+	}
+	if e.HistoryRuns > 0 {
+		sb.WriteString(fmt.Sprintf("   Based on:    %d past run(s) in this repo, %.0f%% passed review\n", e.HistoryRuns, e.SuccessRate*100))
+	} else {
+		sb.WriteString("   Based on:    defaults (no run history for this repo yet)\n")
+	}
+
+	if len(e.FilesAffected) > 0 {
+		sb.WriteString("\n   Files affected:\n")
+		for _, f := range e.FilesAffected {
+			sb.WriteString(fmt.Sprintf("      • %s\n", f))
+		}
+	}
+	if len(e.Risks) > 0 {
+		sb.WriteString("\n   Risks:\n")
+		for _, r := range e.Risks {
+			sb.WriteString(fmt.Sprintf("      • %s\n", r.Reason))
+		}
+	}
+	if len(e.Warnings) > 0 {
+		sb.WriteString("\n   Warnings:\n")
+		for _, w := range e.Warnings {
+			sb.WriteString(fmt.Sprintf("      ⚠️  %s\n", w))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,67 @@
+package estimate
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/memory"
+	"github.com/philjestin/boatmanmode/internal/planner"
+)
+
+func TestNewWithoutHistory(t *testing.T) {
+	plan := &planner.Plan{
+		RelevantFiles: []string{"api/handler.go", "api/handler_test.go"},
+		Approach:      []string{"Add handler", "Add test"},
+	}
+
+	e := New("ENG-1", "Add endpoint", plan, 0.10, nil, 5)
+	if e.Complexity != ComplexityLow {
+		t.Errorf("Expected low complexity, got %s (score %d)", e.Complexity, e.ComplexityScore)
+	}
+	if e.PredictedIterations != 1.5 {
+		t.Errorf("Expected 1.5 iterations (default 2 scaled for low complexity), got %.1f", e.PredictedIterations)
+	}
+	// Planning + execution + review and refactor per iteration
+	if e.PredictedCostUSD != 0.5 {
+		t.Errorf("Expected $0.50 from planning cost, got $%.2f", e.PredictedCostUSD)
+	}
+	if e.HistoryRuns != 0 || !strings.Contains(e.Format(), "defaults") {
+		t.Errorf("Expected defaults to be reported:\n%s", e.Format())
+	}
+}
+
+func TestNewFromHistory(t *testing.T) {
+	mem := &memory.Memory{}
+	for _, r := range []memory.ScoreRecord{
+		{Iterations: 2, Passed: true, CostUSD: 1.00, FilesChanged: 4},
+		{Iterations: 4, Passed: false, CostUSD: 3.00, FilesChanged: 4},
+	} {
+		mem.RecordScore(r)
+	}
+
+	plan := &planner.Plan{
+		RelevantFiles: make([]string, 10),
+		RelevantDirs:  []string{"api", "db"},
+		Approach:      []string{"a", "b", "c", "d"},
+		Warnings:      []string{"Touches the schema"},
+	}
+	e := New("ENG-2", "Migrate users", plan, 0.10, mem, 4)
+
+	if e.Complexity != ComplexityHigh {
+		t.Errorf("Expected high complexity, got %s (score %d)", e.Complexity, e.ComplexityScore)
+	}
+	if e.HistoryRuns != 2 || e.SuccessRate != 0.5 {
+		t.Errorf("Expected 2 runs at 50%%, got %d at %.2f", e.HistoryRuns, e.SuccessRate)
+	}
+	// Average of 3 iterations scaled by 1.5, capped at max iterations
+	if e.PredictedIterations != 4 {
+		t.Errorf("Expected iterations capped at 4, got %.1f", e.PredictedIterations)
+	}
+	// $0.50 per file historically, 10 files, scaled by 1.5
+	if e.PredictedCostUSD != 7.5 {
+		t.Errorf("Expected $7.50, got $%.2f", e.PredictedCostUSD)
+	}
+	if out := e.Format(); !strings.Contains(out, "Touches the schema") || !strings.Contains(out, "2 past run(s)") {
+		t.Errorf("Unexpected format:\n%s", out)
+	}
+}
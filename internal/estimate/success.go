@@ -0,0 +1,98 @@
+package estimate
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/onboard"
+	"github.com/philjestin/boatmanmode/internal/testrunner"
+)
+
+// Risk is one factor lowering the likelihood of autonomous success.
+type Risk struct {
+	Reason  string  `json:"reason"`
+	Penalty float64 `json:"penalty"` // Fraction of the likelihood removed (0-1)
+}
+
+const (
+	// baseSuccessRate is assumed for repos without run history.
+	baseSuccessRate = 0.8
+	// priorRuns weighs baseSuccessRate against history, so a handful of
+	// runs doesn't swing the prediction to either extreme.
+	priorRuns = 3
+)
+
+// AssessSuccess predicts the likelihood (0-100) that the task completes
+// without human help, from this repo's history and the plan's risks:
+// size, how many code owners it spans, missing tests, planner warnings.
+// repoPath is read for CODEOWNERS and existing tests; testCommand is the
+// repo's own test command, if it configures one.
+func (e *Estimate) AssessSuccess(repoPath, testCommand string) {
+	runs := float64(e.HistoryRuns)
+	likelihood := (baseSuccessRate*priorRuns + e.SuccessRate*runs) / (priorRuns + runs)
+	e.Risks = nil
+	// Reported for context; already part of the base likelihood
+	if e.BrokeMain > 0 {
+		e.Risks = append(e.Risks, Risk{
+			Reason: fmt.Sprintf("%d of %d past run(s) in this repo broke the base branch once merged", e.BrokeMain, e.HistoryRuns),
+		})
+	} else if e.HistoryRuns > 0 && e.SuccessRate < baseSuccessRate {
+		e.Risks = append(e.Risks, Risk{
+			Reason: fmt.Sprintf("only %.0f%% of %d past run(s) in this repo passed review", e.SuccessRate*100, e.HistoryRuns),
+		})
+	}
+
+	files := len(e.FilesAffected)
+	rules, _ := onboard.LoadCodeOwners(repoPath)
+	owners := onboard.OwnersOf(rules, e.FilesAffected)
+	var sizePenalty float64
+	switch {
+	case files > 30:
+		sizePenalty = 0.35
+	case files > 15:
+		sizePenalty = 0.2
+	case files > 8:
+		sizePenalty = 0.1
+	}
+	if len(owners) > 2 {
+		sizePenalty += math.Min(0.05*float64(len(owners)-2), 0.25)
+	}
+	if sizePenalty > 0 {
+		reason := fmt.Sprintf("touches %d files", files)
+		if len(owners) > 1 {
+			reason += fmt.Sprintf(" across %d owners", len(owners))
+		}
+		e.Risks = append(e.Risks, Risk{Reason: reason, Penalty: sizePenalty})
+	}
+
+	tests := testrunner.New(repoPath)
+	tests.SetCommand(testCommand)
+	// A custom test command doesn't say which files are tests
+	if framework, err := tests.DetectFramework(); err != nil {
+		e.Risks = append(e.Risks, Risk{Reason: "no test framework detected, so changes can't be verified", Penalty: 0.25})
+	} else if framework.Name != "custom" && files > 0 && len(tests.RelatedTests(e.FilesAffected)) == 0 {
+		e.Risks = append(e.Risks, Risk{Reason: "no tests exist for the affected files", Penalty: 0.15})
+	}
+
+	if n := len(e.Warnings); n > 0 {
+		e.Risks = append(e.Risks, Risk{Reason: fmt.Sprintf("planner raised %d warning(s)", n), Penalty: math.Min(0.05*float64(n), 0.2)})
+	}
+	if e.Complexity == ComplexityHigh {
+		e.Risks = append(e.Risks, Risk{Reason: "high complexity", Penalty: 0.1})
+	}
+
+	for _, r := range e.Risks {
+		likelihood *= 1 - r.Penalty
+	}
+	e.SuccessLikelihood = int(math.Round(likelihood * 100))
+}
+
+// RiskSummary joins the risk reasons, or returns "" if there are none.
+func (e *Estimate) RiskSummary() string {
+	reasons := make([]string, 0, len(e.Risks))
+	for _, r := range e.Risks {
+		reasons = append(reasons, r.Reason)
+	}
+	return strings.Join(reasons, "; ")
+}
@@ -0,0 +1,96 @@
+package estimate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/memory"
+	"github.com/philjestin/boatmanmode/internal/planner"
+)
+
+func TestAssessSuccessSmallTestedChange(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module test\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "user_test.go"), []byte("package test\n"), 0644)
+
+	e := New("ENG-1", "Fix user", &planner.Plan{RelevantFiles: []string{"user.go"}}, 0.1, nil, 5)
+	e.AssessSuccess(dir, "")
+
+	if len(e.Risks) != 0 {
+		t.Errorf("Expected no risks, got %+v", e.Risks)
+	}
+	if e.SuccessLikelihood != 80 {
+		t.Errorf("Expected the 80%% base likelihood, got %d", e.SuccessLikelihood)
+	}
+}
+
+func TestAssessSuccessCustomTestCommand(t *testing.T) {
+	files := []string{"src/user.ts"}
+	e := New("ENG-1", "Fix user", &planner.Plan{RelevantFiles: files}, 0.1, nil, 5)
+	e.AssessSuccess(t.TempDir(), "make check")
+
+	if len(e.Risks) != 0 {
+		t.Errorf("Expected no risks with a test command, got %+v", e.Risks)
+	}
+}
+
+func TestAssessSuccessCountsBrokenMain(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module test\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "user_test.go"), []byte("package test\n"), 0644)
+	mem := &memory.Memory{}
+	for _, broke := range []bool{false, true, false, true} {
+		mem.RecordScore(memory.ScoreRecord{Iterations: 1, Passed: true, BrokeMain: broke})
+	}
+
+	e := New("ENG-1", "Fix user", &planner.Plan{RelevantFiles: []string{"user.go"}}, 0.1, mem, 5)
+	e.AssessSuccess(dir, "")
+
+	if e.BrokeMain != 2 || e.SuccessRate != 0.5 {
+		t.Errorf("Expected 2 runs that broke main and a 50%% success rate, got %d at %.2f", e.BrokeMain, e.SuccessRate)
+	}
+	if !strings.Contains(e.RiskSummary(), "2 of 4 past run(s) in this repo broke the base branch") {
+		t.Errorf("Expected the broken merges as a risk, got %q", e.RiskSummary())
+	}
+	// (0.8*3 + 0.5*4) / 7
+	if e.SuccessLikelihood != 63 {
+		t.Errorf("Expected 63%% likelihood, got %d", e.SuccessLikelihood)
+	}
+}
+
+func TestAssessSuccessRiskyChange(t *testing.T) {
+	dir := t.TempDir()
+	var codeowners strings.Builder
+	var files []string
+	for i := 0; i < 40; i++ {
+		svc := fmt.Sprintf("svc%d", i%6)
+		files = append(files, fmt.Sprintf("%s/file%d.rb", svc, i))
+		if i < 6 {
+			codeowners.WriteString(fmt.Sprintf("/%s/ @org/team%d\n", svc, i))
+		}
+	}
+	os.WriteFile(filepath.Join(dir, "CODEOWNERS"), []byte(codeowners.String()), 0644)
+
+	mem := &memory.Memory{}
+	mem.RecordScore(memory.ScoreRecord{Passed: false, Iterations: 5})
+	e := New("ENG-2", "Rename everything", &planner.Plan{RelevantFiles: files}, 0.1, mem, 5)
+	e.AssessSuccess(dir, "")
+
+	summary := e.RiskSummary()
+	for _, want := range []string{
+		"only 0% of 1 past run(s) in this repo passed review",
+		"touches 40 files across 6 owners",
+		"no test framework detected",
+		"high complexity",
+	} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Expected %q in %q", want, summary)
+		}
+	}
+	if e.SuccessLikelihood >= 30 {
+		t.Errorf("Expected a low likelihood, got %d%%", e.SuccessLikelihood)
+	}
+}
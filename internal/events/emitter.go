@@ -1,11 +1,15 @@
 // Package events provides JSON event emission for boatmanapp integration.
 // Events are emitted to stdout as newline-delimited JSON for the desktop app to parse.
+// With SetOutput they go elsewhere, e.g. a file for CI systems to consume.
 package events
 
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"sync"
+	"time"
 )
 
 // Event represents a structured event emitted during workflow execution.
@@ -17,12 +21,34 @@ type Event struct {
 	Status      string         `json:"status,omitempty"`
 	Message     string         `json:"message,omitempty"`
 	Data        map[string]any `json:"data,omitempty"`
+	Time        string         `json:"time,omitempty"`
 }
 
-// Emit writes a JSON event to stdout.
+var (
+	mu  sync.Mutex
+	out io.Writer
+)
+
+// SetOutput sends events to w instead of stdout. nil restores stdout.
+func SetOutput(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	out = w
+}
+
+// Emit writes a JSON event to the output, stdout by default.
 func Emit(event Event) {
+	if event.Time == "" {
+		event.Time = time.Now().UTC().Format(time.RFC3339Nano)
+	}
 	json, _ := json.Marshal(event)
-	fmt.Fprintln(os.Stdout, string(json))
+	mu.Lock()
+	defer mu.Unlock()
+	w := out
+	if w == nil {
+		w = os.Stdout
+	}
+	fmt.Fprintln(w, string(json))
 }
 
 // AgentStarted emits an event when an agent begins execution.
@@ -82,3 +108,83 @@ func Progress(message string) {
 		Message: message,
 	})
 }
+
+// StepStarted emits an event when a workflow step begins.
+func StepStarted(step string) {
+	Emit(Event{
+		Type: "step_started",
+		Name: step,
+	})
+}
+
+// StepCompleted emits an event when a workflow step finishes, with its
+// duration and the run's cost so far.
+func StepCompleted(step, status string, duration time.Duration, costUSD float64) {
+	Emit(Event{
+		Type:   "step_completed",
+		Name:   step,
+		Status: status,
+		Data: map[string]any{
+			"duration_ms": duration.Milliseconds(),
+			"cost_usd":    costUSD,
+		},
+	})
+}
+
+// Cost emits the cost of a Claude call and the run's total so far.
+func Cost(step string, stepUSD, totalUSD float64, inputTokens, outputTokens int) {
+	Emit(Event{
+		Type: "cost",
+		Name: step,
+		Data: map[string]any{
+			"cost_usd":       stepUSD,
+			"total_cost_usd": totalUSD,
+			"input_tokens":   inputTokens,
+			"output_tokens":  outputTokens,
+		},
+	})
+}
+
+// TestResults emits the outcome of a test run.
+func TestResults(passed bool, framework string, total, failed, skipped int, failedNames []string) {
+	status := "passed"
+	if !passed {
+		status = "failed"
+	}
+	Emit(Event{
+		Type:   "test_results",
+		Status: status,
+		Data: map[string]any{
+			"framework":    framework,
+			"total":        total,
+			"failed":       failed,
+			"skipped":      skipped,
+			"failed_tests": failedNames,
+		},
+	})
+}
+
+// ReviewVerdict emits the verdict of a review iteration.
+func ReviewVerdict(iteration int, verdict string, score int, issues map[string]int) {
+	Emit(Event{
+		Type:   "review_verdict",
+		Status: verdict,
+		Data: map[string]any{
+			"iteration": iteration,
+			"score":     score,
+			"issues":    issues,
+		},
+	})
+}
+
+// RunCompleted emits the outcome of a run: "pr_created", "stopped" when it
+// ended without a PR, or "failed".
+func RunCompleted(id, status, message string, data map[string]any) {
+	Emit(Event{
+		Type:    "run_completed",
+		ID:      id,
+		Status:  status,
+		Message: message,
+		Data:    data,
+	})
+}
@@ -4,7 +4,9 @@ import (
 	"bytes"
 	"encoding/json"
 	"os"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestAgentStarted(t *testing.T) {
@@ -160,3 +162,30 @@ func TestProgress(t *testing.T) {
 		t.Errorf("Expected message 'Running tests...', got '%s'", event.Message)
 	}
 }
+
+func TestSetOutput(t *testing.T) {
+	var buf bytes.Buffer
+	SetOutput(&buf)
+	defer SetOutput(nil)
+
+	StepCompleted("execution", "success", 1500*time.Millisecond, 0.25)
+	ReviewVerdict(2, "passed", 90, map[string]int{"minor": 1})
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 events, got %q", buf.String())
+	}
+	var step, review Event
+	if err := json.Unmarshal([]byte(lines[0]), &step); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &review); err != nil {
+		t.Fatal(err)
+	}
+	if step.Type != "step_completed" || step.Name != "execution" || step.Data["duration_ms"] != float64(1500) || step.Time == "" {
+		t.Errorf("Unexpected step event: %+v", step)
+	}
+	if review.Type != "review_verdict" || review.Status != "passed" || review.Data["iteration"] != float64(2) {
+		t.Errorf("Unexpected review event: %+v", review)
+	}
+}
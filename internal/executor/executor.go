@@ -5,66 +5,79 @@ package executor
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/philjestin/boatmanmode/internal/claude"
+	"github.com/philjestin/boatmanmode/internal/cmdoutput"
 	"github.com/philjestin/boatmanmode/internal/config"
 	"github.com/philjestin/boatmanmode/internal/cost"
+	"github.com/philjestin/boatmanmode/internal/gitops"
 	"github.com/philjestin/boatmanmode/internal/handoff"
 	"github.com/philjestin/boatmanmode/internal/linear"
 	"github.com/philjestin/boatmanmode/internal/planner"
+	"github.com/philjestin/boatmanmode/internal/snapshots"
 	"github.com/philjestin/boatmanmode/internal/task"
+	"github.com/philjestin/boatmanmode/internal/testrunner"
 )
 
 // Executor performs AI-powered development tasks.
 type Executor struct {
 	client       *claude.Client
 	worktreePath string
+	git          *gitops.Repo
+	sparse       bool
+	commands     config.CommandsConfig
+	failureModes string    // Historical failure warnings from project memory
+	featureFlags string    // How the repo gates new behavior behind flags
+	snapshots    string    // When snapshot tests may be updated
+	errorContext string    // Distilled errors the ticket links to
+	specs        string    // Summaries of the specs the ticket links
+	media        string    // The ticket's screenshots and linked pages as text
+	base         string    // Revision GetDiff compares against; HEAD when empty
+	out          io.Writer // Progress output; stdout when nil
 }
 
 // ExecutionResult represents the outcome of task execution.
 type ExecutionResult struct {
 	Success      bool
 	FilesChanged []string
-	Summary      string
-	Error        error
+	// Renames maps the new path of each moved file to its old path.
+	// Moved files appear in FilesChanged under their new path only.
+	Renames map[string]string
+	Summary string
+	// SnapshotReasons are the justifications given for snapshot updates,
+	// by file, when a snapshot policy is set.
+	SnapshotReasons map[string]string
+	Error           error
 }
 
 // New creates a new Executor.
 func New(worktreePath string, cfg *config.Config) *Executor {
-	var client *claude.Client
-
-	if cfg.EnableTools {
-		// Full toolset for development: Read, Edit, Bash, Grep, Glob
-		client = claude.NewWithTools(worktreePath, "executor", nil) // nil = allow all tools
-	} else {
-		// Backward compatibility - no tools
-		client = claude.NewWithTmux(worktreePath, "executor")
-	}
-
-	// Configure model if specified
-	if cfg.Claude.Models.Executor != "" {
-		client.Model = cfg.Claude.Models.Executor
-	}
-	client.EnablePromptCaching = cfg.Claude.EnablePromptCaching
-
-	return &Executor{
-		client:       client,
-		worktreePath: worktreePath,
-	}
+	return newExecutor(worktreePath, "executor", cfg.Claude.Models.Executor, cfg.Claude.SamplingFor("executor"), cfg)
 }
 
 // NewRefactorExecutor creates an executor for a refactor iteration.
 func NewRefactorExecutor(worktreePath string, iteration int, cfg *config.Config) *Executor {
-	sessionName := fmt.Sprintf("refactor-%d", iteration)
+	return newExecutor(worktreePath, fmt.Sprintf("refactor-%d", iteration), cfg.Claude.Models.Refactor, cfg.Claude.SamplingFor("refactor"), cfg)
+}
+
+// NewTestFixExecutor creates an executor for an attempt at repairing
+// failing tests, on the claude.models.test_fixer model.
+func NewTestFixExecutor(worktreePath string, attempt int, cfg *config.Config) *Executor {
+	return newExecutor(worktreePath, fmt.Sprintf("test-fix-%d", attempt), cfg.Claude.Models.TestFixer, cfg.Claude.SamplingFor("test_fixer"), cfg)
+}
+
+// newExecutor creates an executor whose Claude session runs on model
+// (empty = CLI default) with the given sampling parameters.
+func newExecutor(worktreePath, sessionName, model string, sampling config.SamplingConfig, cfg *config.Config) *Executor {
 	var client *claude.Client
 
 	if cfg.EnableTools {
-		// Full toolset for refactoring: Read, Edit, Bash, Grep, Glob
+		// Full toolset for development: Read, Edit, Bash, Grep, Glob
 		client = claude.NewWithTools(worktreePath, sessionName, nil) // nil = allow all tools
 	} else {
 		// Backward compatibility - no tools
@@ -72,14 +85,18 @@ func NewRefactorExecutor(worktreePath string, iteration int, cfg *config.Config)
 	}
 
 	// Configure model if specified
-	if cfg.Claude.Models.Refactor != "" {
-		client.Model = cfg.Claude.Models.Refactor
+	if model != "" {
+		client.Model = model
 	}
+	client.Sampling = sampling
 	client.EnablePromptCaching = cfg.Claude.EnablePromptCaching
+	client.Configure(cfg.Claude)
 
 	return &Executor{
 		client:       client,
 		worktreePath: worktreePath,
+		git:          gitops.New(worktreePath),
+		commands:     cfg.Commands,
 	}
 }
 
@@ -96,13 +113,38 @@ func (e *Executor) ExecuteTicket(ctx context.Context, ticket *linear.Ticket) (*E
 // ExecuteWithPlan performs execution with an optional pre-computed plan.
 func (e *Executor) ExecuteWithPlan(ctx context.Context, t task.Task, plan *planner.Plan) (*ExecutionResult, *cost.Usage, error) {
 	// Build prompt with task
-	fmt.Println("   📖 Building execution prompt...")
+	fmt.Fprintln(e.output(), "   📖 Building execution prompt...")
 	prompt := e.buildPrompt(t)
 
 	// Add planning handoff if available
 	if plan != nil {
 		prompt += "\n\n---\n\n" + plan.ToHandoff()
-		fmt.Printf("   📋 Added plan handoff (%d files, %d steps)\n", len(plan.RelevantFiles), len(plan.Approach))
+		fmt.Fprintf(e.output(), "   📋 Added plan handoff (%d files, %d steps)\n", len(plan.RelevantFiles), len(plan.Approach))
+	}
+	if e.specs != "" {
+		prompt += "\n\n---\n\n" + e.specs
+	}
+	if e.media != "" {
+		prompt += "\n\n---\n\n" + e.media
+	}
+	if e.errorContext != "" {
+		prompt += "\n\n---\n\n## Error Context\n\n" + e.errorContext
+	}
+
+	if e.sparse {
+		prompt += "\n\n---\n\n" + sparseCheckoutNote
+	}
+	if note := e.projectCommandsNote(); note != "" {
+		prompt += "\n\n---\n\n" + note
+	}
+	if e.failureModes != "" {
+		prompt += "\n\n---\n\n" + e.failureModes
+	}
+	if e.featureFlags != "" {
+		prompt += "\n\n---\n\n" + e.featureFlags
+	}
+	if e.snapshots != "" {
+		prompt += "\n\n---\n\n" + e.snapshots
 	}
 
 	// Load project rules (like Cursor does)
@@ -125,13 +167,24 @@ Do not ask for permission - just implement the solution immediately.
 You have been given a plan from a planning agent. Follow the approach and read the key files first.
 If implementation already exists, add tests or make improvements as needed.`
 
+	// API providers cannot use tools, so they get the plan's files and
+	// answer with complete files instead
+	if !e.client.SupportsTools() {
+		systemPrompt = fileBlockSystemPrompt
+		if plan != nil && len(plan.RelevantFiles) > 0 {
+			if files, err := e.GetSpecificFiles(plan.RelevantFiles); err == nil && files != "" {
+				prompt += "\n\n---\n\n## Relevant Files\n\n" + files
+			}
+		}
+	}
+
 	if projectRules != "" {
 		systemPrompt = projectRules + "\n\n---\n\n" + systemPrompt
 	}
 
 	// Phase 3: Execute with Claude
-	fmt.Println("   🤖 Phase 3: Executing with Claude...")
-	fmt.Printf("   📝 Prompt size: %d chars\n", len(prompt))
+	fmt.Fprintln(e.output(), "   🤖 Phase 3: Executing with Claude...")
+	fmt.Fprintf(e.output(), "   📝 Prompt size: %d chars\n", len(prompt))
 
 	start := time.Now()
 	response, usage, err := e.client.Message(ctx, systemPrompt, prompt)
@@ -141,26 +194,32 @@ If implementation already exists, add tests or make improvements as needed.`
 		return nil, nil, fmt.Errorf("failed to call Claude: %w", err)
 	}
 
-	fmt.Printf("   ⏱️  Claude responded in %s\n", elapsed.Round(time.Second))
-	fmt.Printf("   📄 Response size: %d chars\n", len(response))
+	fmt.Fprintf(e.output(), "   ⏱️  Claude responded in %s\n", elapsed.Round(time.Second))
+	fmt.Fprintf(e.output(), "   📄 Response size: %d chars\n", len(response))
+
+	if !e.client.SupportsTools() {
+		if _, err := e.parseAndApplyChanges(response); err != nil {
+			return nil, usage, fmt.Errorf("failed to apply changes: %w", err)
+		}
+	}
 
 	// Claude in agentic mode writes files directly - detect what changed via git
-	fmt.Println("   📦 Detecting file changes in worktree...")
-	filesChanged, err := e.detectChangedFiles()
+	fmt.Fprintln(e.output(), "   📦 Detecting file changes in worktree...")
+	filesChanged, renames, err := e.detectChangedFiles()
 	if err != nil {
 		return nil, usage, fmt.Errorf("failed to detect changes: %w", err)
 	}
 
 	if len(filesChanged) == 0 {
 		// No files changed - show Claude's response for debugging
-		fmt.Println("   ⚠️  No files were changed in the worktree!")
-		fmt.Println("   📋 Claude's response:")
+		fmt.Fprintln(e.output(), "   ⚠️  No files were changed in the worktree!")
+		fmt.Fprintln(e.output(), "   📋 Claude's response:")
 		preview := response
 		if len(preview) > 2000 {
 			preview = preview[:2000] + "\n... (truncated)"
 		}
 		for _, line := range strings.Split(preview, "\n") {
-			fmt.Printf("      │ %s\n", line)
+			fmt.Fprintf(e.output(), "      │ %s\n", line)
 		}
 		return &ExecutionResult{
 			Success: false,
@@ -168,69 +227,97 @@ If implementation already exists, add tests or make improvements as needed.`
 		}, usage, nil
 	}
 
-	fmt.Printf("   ✏️  Claude modified %d files:\n", len(filesChanged))
+	fmt.Fprintf(e.output(), "   ✏️  Claude modified %d files:\n", len(filesChanged))
 	for _, f := range filesChanged {
-		fmt.Printf("      • %s\n", f)
+		if from, ok := renames[f]; ok {
+			fmt.Fprintf(e.output(), "      • %s (moved from %s)\n", f, from)
+			continue
+		}
+		fmt.Fprintf(e.output(), "      • %s\n", f)
 	}
 
 	return &ExecutionResult{
-		Success:      true,
-		FilesChanged: filesChanged,
-		Summary:      extractSummary(response),
+		Success:         true,
+		FilesChanged:    filesChanged,
+		Renames:         renames,
+		Summary:         extractSummary(response),
+		SnapshotReasons: e.snapshotReasons(response),
 	}, usage, nil
 }
 
-// detectChangedFiles uses git status to find what files Claude modified.
-func (e *Executor) detectChangedFiles() ([]string, error) {
+// detectChangedFiles uses git to find what files Claude modified, and
+// which of them Claude moved. Untracked files are marked intent-to-add so
+// git can pair them with the deleted files they were moved from.
+func (e *Executor) detectChangedFiles() ([]string, map[string]string, error) {
 	// Get list of changed files (staged, unstaged, and untracked)
-	cmd := exec.Command("git", "status", "--porcelain")
-	cmd.Dir = e.worktreePath
-	output, err := cmd.Output()
+	entries, err := e.git.Status()
 	if err != nil {
-		return nil, fmt.Errorf("git status failed: %w", err)
+		return nil, nil, fmt.Errorf("git status failed: %w", err)
 	}
 
-	var files []string
-	for _, line := range strings.Split(string(output), "\n") {
-		// Don't TrimSpace - it removes the leading space from status like " M file"
-		if len(line) < 4 {
-			continue
+	var untracked []string
+	for _, entry := range entries {
+		if entry.Code == "??" {
+			untracked = append(untracked, entry.Path)
+		}
+	}
+	if len(untracked) > 0 {
+		if err := e.git.IntentToAdd(untracked...); err != nil {
+			fmt.Fprintf(e.output(), "   ⚠️  Could not detect moved files: %v\n", err)
 		}
-		
-		// Format is "XY filename" where XY is 2 status chars + 1 space
-		// Examples: " M packs/file.rb", "A  packs/file.rb", "?? packs/file.rb"
-		file := line[3:]
-		
-		// Handle renamed files: "R  old -> new"
-		if idx := strings.Index(file, " -> "); idx != -1 {
-			file = file[idx+4:]
+	}
+
+	var paths []string
+	var renames map[string]string
+	seen := make(map[string]bool)
+	if changes, err := e.git.ChangesSince("HEAD"); err == nil {
+		for _, change := range changes {
+			paths = append(paths, change.Path)
+			seen[change.Path] = true
+			if change.Status == 'R' {
+				if renames == nil {
+					renames = make(map[string]string)
+				}
+				renames[change.Path] = change.OrigPath
+				seen[change.OrigPath] = true
+			}
 		}
-		
+	}
+	// Anything the diff missed, such as untracked files that could not be
+	// marked, is still reported as changed
+	for _, entry := range entries {
+		if !seen[entry.Path] {
+			paths = append(paths, entry.Path)
+		}
+	}
+
+	var files []string
+	for _, file := range paths {
 		// Skip directories (end with /)
 		if strings.HasSuffix(file, "/") {
 			continue
 		}
-		
+
 		// Verify it's a file, not a directory
 		fullPath := filepath.Join(e.worktreePath, file)
 		if info, err := os.Stat(fullPath); err == nil && info.IsDir() {
 			continue
 		}
-		
+
 		files = append(files, file)
 	}
 
-	return files, nil
+	return files, renames, nil
 }
 
 // Refactor applies feedback from ScottBott to improve the code.
 func (e *Executor) Refactor(ctx context.Context, t task.Task, reviewFeedback string, changedFiles []string) (*ExecutionResult, *cost.Usage, error) {
-	fmt.Println("   📖 Reading changed files...")
+	fmt.Fprintln(e.output(), "   📖 Reading changed files...")
 	currentFiles, err := e.GetSpecificFiles(changedFiles)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to read current files: %w", err)
 	}
-	fmt.Printf("   📁 Loaded %d changed files\n", len(changedFiles))
+	fmt.Fprintf(e.output(), "   📁 Loaded %d changed files\n", len(changedFiles))
 
 	prompt := fmt.Sprintf(`## Original Task
 %s
@@ -257,8 +344,8 @@ Format your response with complete file contents:
 // Full updated file contents
 ` + "```"
 
-	fmt.Println("   🤖 Sending refactor request to Claude...")
-	fmt.Printf("   📝 Prompt size: %d chars\n", len(prompt))
+	fmt.Fprintln(e.output(), "   🤖 Sending refactor request to Claude...")
+	fmt.Fprintf(e.output(), "   📝 Prompt size: %d chars\n", len(prompt))
 
 	start := time.Now()
 	response, usage, err := e.client.Message(ctx, systemPrompt, prompt)
@@ -268,9 +355,9 @@ Format your response with complete file contents:
 		return nil, nil, fmt.Errorf("failed to call Claude: %w", err)
 	}
 
-	fmt.Printf("   ⏱️  Claude responded in %s\n", elapsed.Round(time.Second))
+	fmt.Fprintf(e.output(), "   ⏱️  Claude responded in %s\n", elapsed.Round(time.Second))
 
-	fmt.Println("   📦 Applying refactored changes...")
+	fmt.Fprintln(e.output(), "   📦 Applying refactored changes...")
 	filesChanged, err := e.parseAndApplyChanges(response)
 	if err != nil {
 		return &ExecutionResult{
@@ -279,21 +366,31 @@ Format your response with complete file contents:
 		}, usage, nil
 	}
 
-	fmt.Printf("   ✏️  Updated %d files\n", len(filesChanged))
+	fmt.Fprintf(e.output(), "   ✏️  Updated %d files\n", len(filesChanged))
 	for _, f := range filesChanged {
-		fmt.Printf("      • %s\n", f)
+		fmt.Fprintf(e.output(), "      • %s\n", f)
 	}
 
 	return &ExecutionResult{
-		Success:      true,
-		FilesChanged: filesChanged,
-		Summary:      "Refactored based on review feedback",
+		Success:         true,
+		FilesChanged:    filesChanged,
+		Summary:         "Refactored based on review feedback",
+		SnapshotReasons: e.snapshotReasons(response),
 	}, usage, nil
 }
 
 // RefactorWithHandoff uses a structured handoff for refactoring.
 func (e *Executor) RefactorWithHandoff(ctx context.Context, h *handoff.RefactorHandoff) (*ExecutionResult, *cost.Usage, error) {
 	prompt := h.ToPrompt()
+	if e.sparse {
+		prompt += "\n\n---\n\n" + sparseCheckoutNote
+	}
+	if note := e.projectCommandsNote(); note != "" {
+		prompt += "\n\n---\n\n" + note
+	}
+	if e.snapshots != "" {
+		prompt += "\n\n---\n\n" + e.snapshots
+	}
 
 	// Build system prompt - emphasize following project rules
 	systemPrompt := `You are refactoring code based on peer review feedback.
@@ -311,11 +408,11 @@ Common mistakes to avoid:
 - Missing required fields from the schema
 - Not following the project's code organization conventions`
 
-	fmt.Printf("   📝 Handoff: %d issues, %d files\n", len(h.Issues), len(h.FilesToUpdate))
+	fmt.Fprintf(e.output(), "   📝 Handoff: %d issues, %d files\n", len(h.Issues), len(h.FilesToUpdate))
 	if h.ProjectRules != "" {
-		fmt.Println("   📋 Project rules included in handoff")
+		fmt.Fprintln(e.output(), "   📋 Project rules included in handoff")
 	}
-	fmt.Println("   🤖 Sending refactor request...")
+	fmt.Fprintln(e.output(), "   🤖 Sending refactor request...")
 
 	start := time.Now()
 	response, usage, err := e.client.Message(ctx, systemPrompt, prompt)
@@ -325,25 +422,221 @@ Common mistakes to avoid:
 		return nil, nil, fmt.Errorf("failed to call Claude: %w", err)
 	}
 
-	fmt.Printf("   ⏱️  Completed in %s\n", elapsed.Round(time.Second))
+	fmt.Fprintf(e.output(), "   ⏱️  Completed in %s\n", elapsed.Round(time.Second))
 
 	filesChanged, err := e.parseAndApplyChanges(response)
 	if err != nil {
 		return &ExecutionResult{Success: false, Error: err}, usage, nil
 	}
 
-	fmt.Printf("   ✏️  Updated %d files\n", len(filesChanged))
+	fmt.Fprintf(e.output(), "   ✏️  Updated %d files\n", len(filesChanged))
 	for _, f := range filesChanged {
-		fmt.Printf("      • %s\n", f)
+		fmt.Fprintf(e.output(), "      • %s\n", f)
+	}
+
+	return &ExecutionResult{
+		Success:         true,
+		FilesChanged:    filesChanged,
+		Summary:         "Refactored based on review feedback",
+		SnapshotReasons: e.snapshotReasons(response),
+	}, usage, nil
+}
+
+// testOutputLines is how much of a failing run's output FixTests shows.
+const testOutputLines = 80
+
+// FixTests asks Claude to make failing tests pass. The prompt has the
+// failed test names and the tail of the test output; models without tools
+// also get the changed files and answer with complete files.
+func (e *Executor) FixTests(ctx context.Context, t task.Task, result *testrunner.TestResult, changedFiles []string) (*ExecutionResult, *cost.Usage, error) {
+	var sb strings.Builder
+	sb.WriteString("## Original Task\n")
+	sb.WriteString(e.buildPrompt(t))
+	sb.WriteString("\n\n## Failing Tests\n")
+	if len(result.FailedNames) == 0 {
+		sb.WriteString(fmt.Sprintf("%d test(s) failed; see the output for which.\n", result.FailedTests))
+	}
+	for _, name := range result.FailedNames {
+		sb.WriteString("- " + name + "\n")
+	}
+	sb.WriteString(fmt.Sprintf("\n## Test Output (last %d lines)\n```\n%s\n```\n", testOutputLines, cmdoutput.Tail(result.Output, testOutputLines)))
+	sb.WriteString("\n## Files Changed by This Task\n")
+	for _, f := range changedFiles {
+		sb.WriteString("- " + f + "\n")
+	}
+	if note := e.projectCommandsNote(); note != "" {
+		sb.WriteString("\n---\n\n" + note + "\n")
+	}
+
+	systemPrompt := `You are fixing failing tests after a code change.
+Find why each listed test fails and fix the cause. Usually that is the new code; change a test
+only when it asserts behavior the task explicitly changes. Never delete, skip or weaken a test
+to make it pass. Use your tools to read and edit files, and re-run the failing tests to confirm.`
+	if !e.client.SupportsTools() {
+		files, err := e.GetSpecificFiles(changedFiles)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read current files: %w", err)
+		}
+		sb.WriteString("\n## Current Implementation\n" + files)
+		systemPrompt = `You are fixing failing tests after a code change.
+Find why each listed test fails and fix the cause. Usually that is the new code; change a test
+only when it asserts behavior the task explicitly changes. Never delete, skip or weaken a test.
+
+Format your response with complete file contents:
+
+### FILE: path/to/file.go
+` + "```go" + `
+// Full updated file contents
+` + "```"
+	}
+	if rules := e.LoadProjectRules(); rules != "" {
+		systemPrompt = rules + "\n\n---\n\n" + systemPrompt
+	}
+
+	fmt.Fprintln(e.output(), "   🤖 Sending test fix request...")
+	start := time.Now()
+	response, usage, err := e.client.Message(ctx, systemPrompt, sb.String())
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to call Claude: %w", err)
+	}
+	fmt.Fprintf(e.output(), "   ⏱️  Completed in %s\n", time.Since(start).Round(time.Second))
+
+	if !e.client.SupportsTools() {
+		if _, err := e.parseAndApplyChanges(response); err != nil {
+			return &ExecutionResult{Success: false, Error: err}, usage, nil
+		}
+	}
+	filesChanged, renames, err := e.detectChangedFiles()
+	if err != nil {
+		return nil, usage, fmt.Errorf("failed to detect changes: %w", err)
 	}
 
 	return &ExecutionResult{
 		Success:      true,
 		FilesChanged: filesChanged,
-		Summary:      "Refactored based on review feedback",
+		Renames:      renames,
+		Summary:      extractSummary(response),
 	}, usage, nil
 }
 
+// fileBlockSystemPrompt asks a model without tools for complete files.
+const fileBlockSystemPrompt = `You are an expert software developer. Execute the development task described.
+
+You cannot edit files directly. Write every file you create or change in full, in this format:
+
+### FILE: path/to/file.go
+` + "```go" + `
+// Full file contents
+` + "```" + `
+
+Paths are relative to the repository root. Only files in this format are applied, so never
+elide unchanged code. Follow the plan's approach, then end with a brief summary of the change.`
+
+// sparseCheckoutNote tells Claude how to reach files outside a sparse worktree.
+const sparseCheckoutNote = `## Sparse Checkout
+
+This worktree uses sparse checkout: only the directories from the plan are present.
+If you need a directory that is missing, run ` + "`git sparse-checkout add <dir>`" + ` before reading or editing it.`
+
+// projectCommandsNote lists the configured project commands so Claude can
+// verify its changes the way the project does.
+func (e *Executor) projectCommandsNote() string {
+	var lines []string
+	for _, c := range []struct{ name, command string }{
+		{"Test", e.commands.Test},
+		{"Build", e.commands.Build},
+		{"Lint", e.commands.Lint},
+	} {
+		if c.command != "" {
+			lines = append(lines, fmt.Sprintf("- %s: `%s`", c.name, c.command))
+		}
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return "## Project Commands\n\nUse these to verify your changes:\n" + strings.Join(lines, "\n")
+}
+
+// SetSparseCheckout tells the executor the worktree uses sparse checkout.
+func (e *Executor) SetSparseCheckout(sparse bool) {
+	e.sparse = sparse
+}
+
+// SetFailureModes appends this repo's historical failure points, as
+// formatted by memory.FailureModes, to the execution prompt.
+func (e *Executor) SetFailureModes(note string) {
+	e.failureModes = note
+}
+
+// SetErrorContext appends the distilled exceptions and breadcrumbs of the
+// errors a bug ticket links to, as written by sentry.Event.Distill, to the
+// execution prompt.
+func (e *Executor) SetErrorContext(note string) {
+	e.errorContext = note
+}
+
+// SetSpecs appends the summarized specs the ticket links to, as written by
+// specs.Summarize, to the execution prompt.
+func (e *Executor) SetSpecs(note string) {
+	e.specs = note
+}
+
+// SetMedia appends the ticket's screenshots and linked pages as text, as
+// written by ingest.Render, to the execution prompt.
+func (e *Executor) SetMedia(note string) {
+	e.media = note
+}
+
+// SetFeatureFlags appends the repo's feature-flag guidance, as written by
+// featureflags.Checker.Guidance, to the execution prompt.
+func (e *Executor) SetFeatureFlags(note string) {
+	e.featureFlags = note
+}
+
+// SetSnapshotPolicy appends the snapshot update policy, as written by
+// snapshots.Policy.Guidance, to the execution and refactor prompts, and
+// collects the justifications for snapshot updates from the reply.
+func (e *Executor) SetSnapshotPolicy(note string) {
+	e.snapshots = note
+}
+
+// SetProxy points the executor's Claude session at the egress proxy with
+// its environment variables, refusing the session's commands that bypass
+// it. Does nothing with no variables.
+func (e *Executor) SetProxy(env map[string]string) {
+	if len(env) == 0 {
+		return
+	}
+	for k, v := range env {
+		e.client.Env[k] = v
+	}
+	e.client.ConfineToProxy()
+}
+
+// SetOutput sends the executor's progress output, including the agent's
+// streamed response, to w.
+func (e *Executor) SetOutput(w io.Writer) {
+	e.out = w
+	e.client.Output = w
+}
+
+// output returns where progress output goes.
+func (e *Executor) output() io.Writer {
+	if e.out == nil {
+		return os.Stdout
+	}
+	return e.out
+}
+
+// snapshotReasons returns the reply's snapshot justifications, if a
+// snapshot policy is set.
+func (e *Executor) snapshotReasons(response string) map[string]string {
+	if e.snapshots == "" {
+		return nil
+	}
+	return snapshots.ParseJustifications(response)
+}
+
 // GetSpecificFiles reads specific files from the worktree (exported for handoff).
 func (e *Executor) GetSpecificFiles(files []string) (string, error) {
 	return e.getSpecificFiles(files)
@@ -440,7 +733,13 @@ func (e *Executor) getSpecificFiles(files []string) (string, error) {
 		fullPath := filepath.Join(e.worktreePath, relPath)
 		content, err := os.ReadFile(fullPath)
 		if err != nil {
-			fmt.Printf("   ⚠️  Could not read %s: %v\n", relPath, err)
+			fmt.Fprintf(e.output(), "   ⚠️  Could not read %s: %v\n", relPath, err)
+			continue
+		}
+
+		// LFS pointers and binaries are useless (and costly) as prompt context
+		if gitops.IsLFSPointer(content) || gitops.IsBinary(content) {
+			sb.WriteString(fmt.Sprintf("### FILE: %s\n(binary or Git LFS file, content omitted)\n\n", relPath))
 			continue
 		}
 
@@ -450,54 +749,76 @@ func (e *Executor) getSpecificFiles(files []string) (string, error) {
 	return sb.String(), nil
 }
 
+// SetBaseCommit makes GetDiff compare against rev rather than HEAD, for
+// branches that gain work-in-progress commits during the run.
+func (e *Executor) SetBaseCommit(rev string) {
+	e.base = rev
+}
+
 // GetDiff returns the git diff for the worktree.
 func (e *Executor) GetDiff() (string, error) {
-	// First try diff against HEAD
-	cmd := exec.Command("git", "diff", "HEAD")
-	cmd.Dir = e.worktreePath
-	output, err := cmd.Output()
+	base := e.base
+	if base == "" {
+		base = "HEAD"
+	}
+	// First try diff against the base
+	output, err := e.git.Diff(base)
 	if err == nil && len(output) > 0 {
-		return string(output), nil
+		return gitops.FilterLFSDiff(output), nil
 	}
 
 	// Try diff of staged changes
-	cmd = exec.Command("git", "diff", "--cached")
-	cmd.Dir = e.worktreePath
-	output, err = cmd.Output()
+	output, err = e.git.Diff("--cached")
 	if err == nil && len(output) > 0 {
-		return string(output), nil
+		return gitops.FilterLFSDiff(output), nil
 	}
 
 	// Try diff of unstaged changes
-	cmd = exec.Command("git", "diff")
-	cmd.Dir = e.worktreePath
-	output, err = cmd.Output()
+	output, err = e.git.Diff()
 	if err != nil {
 		return "", fmt.Errorf("failed to get diff: %w", err)
 	}
 
-	return string(output), nil
+	return gitops.FilterLFSDiff(output), nil
+}
+
+// SnapshotTree writes the current index as a tree object and returns its hash.
+// Used to diff against a later state without creating commits.
+func (e *Executor) SnapshotTree() (string, error) {
+	tree, err := e.git.WriteTree()
+	if err != nil {
+		return "", fmt.Errorf("failed to snapshot tree: %w", err)
+	}
+	return tree, nil
+}
+
+// DiffSinceTree returns the staged diff relative to a tree from SnapshotTree.
+func (e *Executor) DiffSinceTree(tree string) (string, error) {
+	output, err := e.git.Diff("--cached", tree)
+	if err != nil {
+		return "", fmt.Errorf("failed to diff since %s: %w", tree, err)
+	}
+	return gitops.FilterLFSDiff(output), nil
 }
 
 // StageChanges stages all changes in the worktree.
 func (e *Executor) StageChanges() error {
-	cmd := exec.Command("git", "add", "-A")
-	cmd.Dir = e.worktreePath
-	return cmd.Run()
+	return e.git.AddAll()
 }
 
 // Commit creates a commit with the given message.
 func (e *Executor) Commit(message string) error {
-	cmd := exec.Command("git", "commit", "-m", message)
-	cmd.Dir = e.worktreePath
-	return cmd.Run()
+	return e.git.Commit(message)
 }
 
 // Push pushes the branch to origin.
 func (e *Executor) Push(branchName string) error {
-	cmd := exec.Command("git", "push", "-u", "origin", branchName)
-	cmd.Dir = e.worktreePath
-	return cmd.Run()
+	return e.git.Push("origin", branchName)
+}
+
+// Git returns the git operations for the worktree.
+func (e *Executor) Git() *gitops.Repo {
+	return e.git
 }
 
 // isSourceFile checks if the file extension indicates source code.
@@ -588,7 +909,7 @@ func (e *Executor) LoadProjectRules() string {
 	}
 
 	if rulesCount > 0 {
-		fmt.Printf("   📋 Loaded %d project rule file(s) (%d KB)\n", rulesCount, rules.Len()/1024)
+		fmt.Fprintf(e.output(), "   📋 Loaded %d project rule file(s) (%d KB)\n", rulesCount, rules.Len()/1024)
 	}
 
 	return strings.TrimSpace(rules.String())
@@ -0,0 +1,182 @@
+// Package featureflags makes runs aware of the repo's feature-flag system:
+// it tells the executor to gate risky behavior behind a flag, finds the
+// flags a diff uses, and checks new ones are registered.
+package featureflags
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/scottbott"
+	"github.com/philjestin/boatmanmode/internal/unidiff"
+)
+
+// Flag systems with built-in usage patterns.
+const (
+	LaunchDarkly = "launchdarkly"
+	Flipper      = "flipper"
+	Env          = "env"
+)
+
+// patterns match flag usage per system; the first group is the flag name.
+var patterns = map[string]string{
+	// client.BoolVariation("new-checkout", ...), ldClient.variation('new-checkout', ...)
+	LaunchDarkly: `\b\w*[Vv]ariation\w*\(\s*["'` + "`" + `]([\w.-]+)`,
+	// Flipper.enabled?(:new_checkout), Flipper[:new_checkout]
+	Flipper: `Flipper(?:\.(?:enabled\?|enable|disable)\(\s*|\[\s*)[:"']([\w-]+)`,
+	// FEATURE_NEW_CHECKOUT, ENABLE_NEW_CHECKOUT
+	Env: `\b((?:FEATURE|FF|FLAG|ENABLE)_[A-Z0-9_]+)\b`,
+}
+
+// Checker finds and checks feature flags in a worktree's changes.
+type Checker struct {
+	worktreePath string
+	cfg          config.FeatureFlagConfig
+	pattern      *regexp.Regexp
+}
+
+// New creates a Checker. Returns nil when no flag system is configured.
+func New(worktreePath string, cfg config.FeatureFlagConfig) (*Checker, error) {
+	if cfg.System == "" {
+		return nil, nil
+	}
+	expr := cfg.Pattern
+	if expr == "" {
+		expr = patterns[strings.ToLower(cfg.System)]
+	}
+	if expr == "" {
+		return nil, fmt.Errorf("feature_flags.pattern is required for flag system %q", cfg.System)
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid feature_flags.pattern: %w", err)
+	}
+	if re.NumSubexp() < 1 {
+		return nil, fmt.Errorf("feature_flags.pattern must capture the flag name in a group")
+	}
+	return &Checker{worktreePath: worktreePath, cfg: cfg, pattern: re}, nil
+}
+
+// Guidance tells the executor how the repo gates new behavior.
+func (c *Checker) Guidance() string {
+	var sb strings.Builder
+	sb.WriteString("## Feature Flags\n\n")
+	sb.WriteString(fmt.Sprintf("This repository uses %s feature flags. Gate risky new behavior behind a new flag, "+
+		"defaulting to off: user-facing changes, new integrations, changed data flows, and anything hard to roll back. "+
+		"Pure refactors, tests and internal fixes need no flag.\n", c.cfg.System))
+	if len(c.cfg.Registry) > 0 {
+		sb.WriteString(fmt.Sprintf("Register every new flag in %s, following the existing entries.\n",
+			strings.Join(quote(c.cfg.Registry), ", ")))
+	}
+	sb.WriteString("State the flag name in your summary.")
+	return sb.String()
+}
+
+// Flags returns the flag names used in the diff's added lines, sorted.
+func (c *Checker) Flags(diff string) []string {
+	seen := map[string]bool{}
+	for _, f := range unidiff.Parse(diff) {
+		if c.isRegistry(f.Path()) {
+			continue
+		}
+		for _, line := range f.Added() {
+			for _, m := range c.pattern.FindAllStringSubmatch(line, -1) {
+				seen[m[1]] = true
+			}
+		}
+	}
+	flags := make([]string, 0, len(seen))
+	for flag := range seen {
+		flags = append(flags, flag)
+	}
+	sort.Strings(flags)
+	return flags
+}
+
+// Unregistered returns the flags used in the diff that no registry file
+// mentions. Without a configured registry every flag counts as registered.
+func (c *Checker) Unregistered(diff string) []string {
+	if len(c.cfg.Registry) == 0 {
+		return nil
+	}
+	var registry strings.Builder
+	for _, path := range c.registryFiles() {
+		if data, err := os.ReadFile(filepath.Join(c.worktreePath, path)); err == nil {
+			registry.Write(data)
+			registry.WriteString("\n")
+		}
+	}
+	var missing []string
+	for _, flag := range c.Flags(diff) {
+		if !strings.Contains(registry.String(), flag) {
+			missing = append(missing, flag)
+		}
+	}
+	return missing
+}
+
+// Issues returns a major review issue for each unregistered flag.
+func (c *Checker) Issues(diff string) []scottbott.Issue {
+	var issues []scottbott.Issue
+	for _, flag := range c.Unregistered(diff) {
+		issues = append(issues, scottbott.Issue{
+			Severity:    "major",
+			File:        c.cfg.Registry[0],
+			Description: fmt.Sprintf("Feature flag %q is used but not registered", flag),
+			Suggestion:  fmt.Sprintf("Add %q to %s", flag, strings.Join(c.cfg.Registry, " or ")),
+		})
+	}
+	return issues
+}
+
+// Markdown lists the diff's flags for the PR body.
+func (c *Checker) Markdown(diff string) string {
+	flags := c.Flags(diff)
+	if len(flags) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("### 🚩 Feature Flags\n")
+	sb.WriteString(fmt.Sprintf("This change is gated by %s flag(s):\n", c.cfg.System))
+	for _, flag := range flags {
+		sb.WriteString(fmt.Sprintf("- `%s`\n", flag))
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// registryFiles expands the configured registry paths and globs.
+func (c *Checker) registryFiles() []string {
+	var files []string
+	for _, entry := range c.cfg.Registry {
+		matches, _ := filepath.Glob(filepath.Join(c.worktreePath, entry))
+		for _, m := range matches {
+			if rel, err := filepath.Rel(c.worktreePath, m); err == nil {
+				files = append(files, filepath.ToSlash(rel))
+			}
+		}
+	}
+	return files
+}
+
+func (c *Checker) isRegistry(path string) bool {
+	for _, entry := range c.cfg.Registry {
+		if ok, _ := filepath.Match(entry, path); ok || entry == path {
+			return true
+		}
+	}
+	return false
+}
+
+func quote(paths []string) []string {
+	quoted := make([]string, len(paths))
+	for i, p := range paths {
+		quoted[i] = "`" + p + "`"
+	}
+	return quoted
+}
@@ -0,0 +1,88 @@
+package featureflags
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+)
+
+const diff = `diff --git a/app/checkout.rb b/app/checkout.rb
+--- a/app/checkout.rb
++++ b/app/checkout.rb
+@@ -1,2 +1,4 @@
+ def checkout
++  return new_flow if Flipper.enabled?(:new_checkout)
++  return fast_path if Flipper[:fast_path]
+   old_flow
+diff --git a/config/flags.yml b/config/flags.yml
+--- a/config/flags.yml
++++ b/config/flags.yml
+@@ -1 +1,2 @@
+ legacy: true
++new_checkout: false
+`
+
+func TestFlagsAndRegistration(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "config"), 0755)
+	os.WriteFile(filepath.Join(dir, "config", "flags.yml"), []byte("legacy: true\nnew_checkout: false\n"), 0644)
+
+	c, err := New(dir, config.FeatureFlagConfig{System: "Flipper", Registry: []string{"config/*.yml"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flags := c.Flags(diff); strings.Join(flags, ",") != "fast_path,new_checkout" {
+		t.Errorf("Unexpected flags: %v", flags)
+	}
+	if missing := c.Unregistered(diff); len(missing) != 1 || missing[0] != "fast_path" {
+		t.Errorf("Expected fast_path unregistered, got %v", missing)
+	}
+	issues := c.Issues(diff)
+	if len(issues) != 1 || issues[0].Severity != "major" || !strings.Contains(issues[0].Description, "fast_path") {
+		t.Errorf("Unexpected issues: %+v", issues)
+	}
+	if md := c.Markdown(diff); !strings.Contains(md, "- `new_checkout`") || !strings.Contains(md, "Flipper flag(s)") {
+		t.Errorf("Unexpected PR section:\n%s", md)
+	}
+	if !strings.Contains(c.Guidance(), "`config/*.yml`") {
+		t.Errorf("Expected the registry in the guidance:\n%s", c.Guidance())
+	}
+}
+
+func TestBuiltinPatterns(t *testing.T) {
+	for system, line := range map[string]string{
+		LaunchDarkly: `on, _ := client.BoolVariation("new-checkout", ctx, false)`,
+		Env:          `if os.Getenv("FEATURE_NEW_CHECKOUT") == "1" {`,
+	} {
+		c, err := New("", config.FeatureFlagConfig{System: system})
+		if err != nil {
+			t.Fatal(err)
+		}
+		d := "--- a/x\n+++ b/x\n@@ -0,0 +1 @@\n+" + line + "\n"
+		if flags := c.Flags(d); len(flags) != 1 {
+			t.Errorf("%s: expected one flag in %q, got %v", system, line, flags)
+		}
+		if c.Unregistered(d) != nil {
+			t.Errorf("%s: expected no registration check without a registry", system)
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	if c, err := New("", config.FeatureFlagConfig{}); c != nil || err != nil {
+		t.Errorf("Expected no checker without a system, got %v, %v", c, err)
+	}
+	if _, err := New("", config.FeatureFlagConfig{System: "unleash"}); err == nil {
+		t.Error("Expected an error for an unknown system without a pattern")
+	}
+	if _, err := New("", config.FeatureFlagConfig{System: "unleash", Pattern: `isEnabled\("[\w-]+"`}); err == nil {
+		t.Error("Expected an error for a pattern without a group")
+	}
+	if _, err := New("", config.FeatureFlagConfig{System: "unleash", Pattern: `isEnabled\("([\w-]+)"`}); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/gitops"
 )
 
 // Summary represents a summarized view of a file.
@@ -70,6 +72,15 @@ func (s *Summarizer) SummarizeFile(filePath string) (*Summary, error) {
 		return nil, err
 	}
 
+	// Binary and Git LFS pointer files have nothing useful to summarize
+	if gitops.IsLFSPointer(content) || gitops.IsBinary(content) {
+		return &Summary{
+			Path:        filePath,
+			Language:    "binary",
+			FullContent: "(binary or Git LFS file, content omitted)",
+		}, nil
+	}
+
 	lines := strings.Split(string(content), "\n")
 	ext := filepath.Ext(filePath)
 	lang := detectLanguage(ext)
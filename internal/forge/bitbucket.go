@@ -0,0 +1,253 @@
+package forge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/gitops"
+	"github.com/philjestin/boatmanmode/internal/retry"
+)
+
+// Bitbucket opens and updates PRs through the Bitbucket Cloud 2.0 API.
+type Bitbucket struct {
+	cfg        config.BitbucketConfig
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewBitbucket creates a Bitbucket forge. The URL defaults to
+// https://api.bitbucket.org/2.0.
+func NewBitbucket(cfg config.BitbucketConfig) *Bitbucket {
+	baseURL := strings.TrimSuffix(cfg.URL, "/")
+	if baseURL == "" {
+		baseURL = "https://api.bitbucket.org/2.0"
+	}
+	return &Bitbucket{
+		cfg:        cfg,
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// bitbucketPR is the part of a Bitbucket pull request boatman reads and
+// writes.
+type bitbucketPR struct {
+	ID          int                 `json:"id,omitempty"`
+	Title       string              `json:"title"`
+	Description string              `json:"description,omitempty"`
+	Source      *bitbucketRef       `json:"source,omitempty"`
+	Destination *bitbucketRef       `json:"destination,omitempty"`
+	Draft       bool                `json:"draft,omitempty"`
+	Reviewers   []bitbucketReviewer `json:"reviewers"`
+	Links       *struct {
+		HTML struct {
+			Href string `json:"href"`
+		} `json:"html"`
+	} `json:"links,omitempty"`
+}
+
+type bitbucketRef struct {
+	Branch struct {
+		Name string `json:"name"`
+	} `json:"branch"`
+}
+
+// bitbucketReviewer is a user, by the UUID or account ID Bitbucket
+// identifies users with.
+type bitbucketReviewer struct {
+	UUID      string `json:"uuid,omitempty"`
+	AccountID string `json:"account_id,omitempty"`
+}
+
+func branchRef(name string) *bitbucketRef {
+	ref := &bitbucketRef{}
+	ref.Branch.Name = name
+	return ref
+}
+
+// Repo returns the workspace and repository PRs are opened in for the
+// repository checked out in workDir: the configured ones, with blanks
+// filled in from the origin remote.
+func (c *Bitbucket) Repo(ctx context.Context, workDir string) (Repo, error) {
+	repo := Repo{Owner: c.cfg.Workspace, Name: c.cfg.Repository}
+	if repo.Owner == "" || repo.Name == "" {
+		remote, _ := gitops.New(workDir).WithContext(ctx).RemoteURL("origin")
+		if origin, ok := ParseRemote(remote); ok {
+			if repo.Owner == "" {
+				repo.Owner = origin.Owner
+			}
+			if repo.Name == "" {
+				repo.Name = origin.Name
+			}
+		}
+	}
+	if repo.Owner == "" || repo.Name == "" {
+		return Repo{}, fmt.Errorf("bitbucket workspace and repository are required (set bitbucket.workspace and bitbucket.repository, or use a bitbucket.org origin)")
+	}
+	return repo, nil
+}
+
+// CreatePR opens pr in the workspace and repository of workDir.
+func (c *Bitbucket) CreatePR(ctx context.Context, workDir string, pr NewPR) (*PRResult, error) {
+	repo, err := c.Repo(ctx, workDir)
+	if err != nil {
+		return nil, err
+	}
+	body := bitbucketPR{
+		Title:       pr.Title,
+		Description: pr.Body,
+		Source:      branchRef(pr.Head),
+		Destination: branchRef(pr.Base),
+		Draft:       pr.Draft,
+		Reviewers:   []bitbucketReviewer{},
+	}
+	resp, err := c.execute(ctx, http.MethodPost, fmt.Sprintf("/repositories/%s/%s/pullrequests", repo.Owner, repo.Name), body)
+	if err != nil {
+		return nil, err
+	}
+	var created bitbucketPR
+	if err := json.Unmarshal(resp, &created); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if created.Links == nil {
+		return nil, fmt.Errorf("PR #%d was created without a link", created.ID)
+	}
+	return &PRResult{URL: created.Links.HTML.Href}, nil
+}
+
+// RequestReviewers adds reviewers to the PR at url. Bitbucket identifies
+// users by account ID or {UUID}, not by name.
+func (c *Bitbucket) RequestReviewers(ctx context.Context, url string, reviewers []string) error {
+	path, err := bitbucketPRPath(url)
+	if err != nil {
+		return err
+	}
+	resp, err := c.execute(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+	var pr bitbucketPR
+	if err := json.Unmarshal(resp, &pr); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	// Updating a PR replaces its reviewers, so keep the current ones
+	seen := map[string]bool{}
+	update := bitbucketPR{Title: pr.Title, Reviewers: []bitbucketReviewer{}}
+	for _, r := range pr.Reviewers {
+		seen[r.UUID] = true
+		seen[r.AccountID] = true
+		update.Reviewers = append(update.Reviewers, bitbucketReviewer{UUID: r.UUID})
+	}
+	for _, r := range reviewers {
+		if seen[r] {
+			continue
+		}
+		if strings.HasPrefix(r, "{") {
+			update.Reviewers = append(update.Reviewers, bitbucketReviewer{UUID: r})
+		} else {
+			update.Reviewers = append(update.Reviewers, bitbucketReviewer{AccountID: r})
+		}
+	}
+	_, err = c.execute(ctx, http.MethodPut, path, update)
+	return err
+}
+
+// AddLabels fails with ErrUnsupported: Bitbucket PRs have no labels.
+func (c *Bitbucket) AddLabels(ctx context.Context, url string, labels []string) error {
+	return fmt.Errorf("bitbucket labels: %w", ErrUnsupported)
+}
+
+// execute performs a REST request with retry logic.
+func (c *Bitbucket) execute(ctx context.Context, method, path string, body any) ([]byte, error) {
+	var jsonBody []byte
+	if body != nil {
+		var err error
+		if jsonBody, err = json.Marshal(body); err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+	}
+
+	var result []byte
+	err := retry.Do(ctx, retry.APIConfig(), "Bitbucket API request", func() error {
+		req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(jsonBody))
+		if err != nil {
+			return retry.Permanent(fmt.Errorf("failed to create request: %w", err))
+		}
+		req.Header.Set("Accept", "application/json")
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if c.cfg.Username != "" {
+			req.SetBasicAuth(c.cfg.Username, c.cfg.Token)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+c.cfg.Token)
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("request failed: %w", err) // Retryable
+		}
+		defer resp.Body.Close()
+
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			return fmt.Errorf("API returned status %d: %s", resp.StatusCode, bitbucketError(respBody))
+		}
+		if resp.StatusCode >= 400 {
+			return retry.Permanent(fmt.Errorf("API returned status %d: %s", resp.StatusCode, bitbucketError(respBody)))
+		}
+
+		result = respBody
+		return nil
+	})
+	return result, err
+}
+
+// bitbucketError extracts Bitbucket's error message from a response body.
+func bitbucketError(body []byte) string {
+	var result struct {
+		Error struct {
+			Message string         `json:"message"`
+			Fields  map[string]any `json:"fields"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &result) != nil || result.Error.Message == "" {
+		return string(body)
+	}
+	msg := result.Error.Message
+	fields := make([]string, 0, len(result.Error.Fields))
+	for field := range result.Error.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		msg += fmt.Sprintf(" (%s: %v)", field, result.Error.Fields[field])
+	}
+	return msg
+}
+
+// bitbucketPRURL matches https://bitbucket.org/workspace/repo/pull-requests/N.
+var bitbucketPRURL = regexp.MustCompile(`^https?://[^/]+/([^/]+)/([^/]+)/pull-requests/(\d+)`)
+
+// bitbucketPRPath returns the API path of the PR at url.
+func bitbucketPRPath(url string) (string, error) {
+	m := bitbucketPRURL.FindStringSubmatch(url)
+	if m == nil {
+		return "", fmt.Errorf("not a Bitbucket PR URL: %s", url)
+	}
+	return fmt.Sprintf("/repositories/%s/%s/pullrequests/%s", m[1], m[2], m[3]), nil
+}
@@ -0,0 +1,116 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+)
+
+func TestBitbucketCreatePR(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repositories/acme/web/pullrequests" {
+			t.Errorf("request = %s %s", r.Method, r.URL.Path)
+		}
+		if user, pass, ok := r.BasicAuth(); !ok || user != "jo" || pass != "app-pw" {
+			t.Errorf("basic auth = %q, %q, %v", user, pass, ok)
+		}
+		var body bitbucketPR
+		json.NewDecoder(r.Body).Decode(&body)
+		if body.Title != "Export" || body.Description != "Adds export" || !body.Draft ||
+			body.Source.Branch.Name != "eng-1-export" || body.Destination.Branch.Name != "main" {
+			t.Errorf("body = %+v", body)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id": 7, "links": {"html": {"href": "https://bitbucket.org/acme/web/pull-requests/7"}}}`))
+	}))
+	defer srv.Close()
+
+	bb := NewBitbucket(config.BitbucketConfig{URL: srv.URL + "/", Workspace: "acme", Repository: "web", Username: "jo", Token: "app-pw"})
+	pr, err := bb.CreatePR(context.Background(), t.TempDir(), NewPR{
+		Title: "Export", Body: "Adds export", Base: "main", Head: "eng-1-export", Draft: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr.URL != "https://bitbucket.org/acme/web/pull-requests/7" {
+		t.Errorf("URL = %s", pr.URL)
+	}
+}
+
+func TestBitbucketRequestReviewers(t *testing.T) {
+	var update bitbucketPR
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repositories/acme/web/pullrequests/7" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer bb-token" {
+			t.Errorf("Authorization = %q", got)
+		}
+		switch r.Method {
+		case http.MethodGet:
+			w.Write([]byte(`{"id": 7, "title": "Export", "reviewers": [{"uuid": "{aaa}", "account_id": "557058:aaa"}]}`))
+		case http.MethodPut:
+			json.NewDecoder(r.Body).Decode(&update)
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer srv.Close()
+
+	bb := NewBitbucket(config.BitbucketConfig{URL: srv.URL, Token: "bb-token"})
+	err := bb.RequestReviewers(context.Background(), "https://bitbucket.org/acme/web/pull-requests/7",
+		[]string{"557058:aaa", "{bbb}", "557058:ccc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []bitbucketReviewer{{UUID: "{aaa}"}, {UUID: "{bbb}"}, {AccountID: "557058:ccc"}}
+	if update.Title != "Export" || len(update.Reviewers) != len(want) {
+		t.Fatalf("update = %+v", update)
+	}
+	for i, r := range want {
+		if update.Reviewers[i] != r {
+			t.Errorf("reviewer %d = %+v, want %+v", i, update.Reviewers[i], r)
+		}
+	}
+}
+
+func TestBitbucketErrors(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"type": "error", "error": {"message": "Bad request", "fields": {"source": ["Branch not found"]}}}`))
+	}))
+	defer srv.Close()
+
+	bb := NewBitbucket(config.BitbucketConfig{URL: srv.URL, Workspace: "acme", Repository: "web"})
+	_, err := bb.CreatePR(context.Background(), t.TempDir(), NewPR{Title: "x", Base: "main", Head: "x"})
+	if err == nil || calls != 1 {
+		t.Fatalf("err = %v after %d call(s), want one failed call", err, calls)
+	}
+	if want := "API returned status 400: Bad request (source: [Branch not found])"; err.Error() != want {
+		t.Errorf("err = %q, want %q", err, want)
+	}
+
+	if err := bb.AddLabels(context.Background(), "https://bitbucket.org/acme/web/pull-requests/7", []string{"x"}); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("AddLabels err = %v, want ErrUnsupported", err)
+	}
+	if err := bb.RequestReviewers(context.Background(), "https://github.com/acme/web/pull/7", []string{"x"}); err == nil {
+		t.Error("expected an error for a GitHub PR URL")
+	}
+}
+
+func TestBitbucketRepo(t *testing.T) {
+	bb := NewBitbucket(config.BitbucketConfig{})
+	if _, err := bb.Repo(context.Background(), t.TempDir()); err == nil {
+		t.Error("expected an error without a workspace or bitbucket.org origin")
+	}
+	bb = NewBitbucket(config.BitbucketConfig{Workspace: "acme", Repository: "web"})
+	if repo, err := bb.Repo(context.Background(), t.TempDir()); err != nil || repo != (Repo{Owner: "acme", Name: "web"}) {
+		t.Errorf("Repo = %+v, %v", repo, err)
+	}
+}
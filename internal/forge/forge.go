@@ -0,0 +1,86 @@
+// Package forge opens and updates pull requests on the code host a repo
+// lives on: GitHub, through the gh CLI or the REST API when a token is
+// configured, or Bitbucket Cloud, through its 2.0 API.
+package forge
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// Providers, as named by pr.provider.
+const (
+	ProviderGitHub    = "github"
+	ProviderBitbucket = "bitbucket"
+)
+
+// ErrUnsupported is returned for operations a forge has no equivalent of.
+var ErrUnsupported = errors.New("not supported by this forge")
+
+// Forge is a code host PRs are opened on.
+type Forge interface {
+	// CreatePR opens pr for the repository checked out in workDir.
+	CreatePR(ctx context.Context, workDir string, pr NewPR) (*PRResult, error)
+
+	// RequestReviewers requests reviews of the PR at url.
+	RequestReviewers(ctx context.Context, url string, reviewers []string) error
+
+	// AddLabels applies labels to the PR at url.
+	AddLabels(ctx context.Context, url string, labels []string) error
+}
+
+// PRResult represents the result of PR creation.
+type PRResult struct {
+	URL string
+}
+
+// NewPR describes a pull request to open.
+type NewPR struct {
+	Title string
+	Body  string
+	Base  string
+	// Head is the branch with the changes.
+	Head  string
+	Draft bool
+}
+
+// Repo is a repository on a forge: a GitHub owner or Bitbucket workspace,
+// and the repository's name or slug.
+type Repo struct {
+	Owner string
+	Name  string
+}
+
+var (
+	// https://github.com/owner/repo(.git), with or without user@
+	httpsRemote = regexp.MustCompile(`^https?://(?:[^@/]+@)?([^/]+)/([^/]+)/([^/]+?)(?:\.git)?/?$`)
+	// git@github.com:owner/repo(.git) and ssh://git@github.com/owner/repo
+	sshRemote = regexp.MustCompile(`^(?:ssh://)?[^@]+@([^:/]+)[:/]([^/]+)/([^/]+?)(?:\.git)?/?$`)
+)
+
+// ParseRemote returns the repository a git remote URL points at.
+func ParseRemote(remoteURL string) (Repo, bool) {
+	_, repo, ok := parseRemote(remoteURL)
+	return repo, ok
+}
+
+// Detect returns the provider hosting the repository at remoteURL:
+// ProviderBitbucket for bitbucket.org, ProviderGitHub otherwise.
+func Detect(remoteURL string) string {
+	if host, _, ok := parseRemote(remoteURL); ok && host == "bitbucket.org" {
+		return ProviderBitbucket
+	}
+	return ProviderGitHub
+}
+
+func parseRemote(remoteURL string) (host string, repo Repo, ok bool) {
+	remoteURL = strings.TrimSpace(remoteURL)
+	for _, re := range []*regexp.Regexp{httpsRemote, sshRemote} {
+		if m := re.FindStringSubmatch(remoteURL); m != nil {
+			return strings.ToLower(m[1]), Repo{Owner: m[2], Name: m[3]}, true
+		}
+	}
+	return "", Repo{}, false
+}
@@ -0,0 +1,35 @@
+package forge
+
+import "testing"
+
+func TestParseRemote(t *testing.T) {
+	want := Repo{Owner: "acme", Name: "web"}
+	for _, url := range []string{
+		"https://github.com/acme/web.git",
+		"https://github.com/acme/web",
+		"https://x-access-token@github.com/acme/web.git",
+		"git@github.com:acme/web.git",
+		"git@bitbucket.org:acme/web.git",
+		"ssh://git@github.example.com/acme/web",
+	} {
+		if got, ok := ParseRemote(url); !ok || got != want {
+			t.Errorf("ParseRemote(%q) = %+v, %v", url, got, ok)
+		}
+	}
+	if _, ok := ParseRemote("https://dev.azure.com/acme/shop/_git/web"); ok {
+		t.Error("Azure Repos remote should not parse")
+	}
+}
+
+func TestDetect(t *testing.T) {
+	for url, want := range map[string]string{
+		"git@bitbucket.org:acme/web.git":          ProviderBitbucket,
+		"https://jo@bitbucket.org/acme/web.git":   ProviderBitbucket,
+		"git@github.com:acme/web.git":             ProviderGitHub,
+		"https://github.example.com/acme/web.git": ProviderGitHub,
+	} {
+		if got := Detect(url); got != want {
+			t.Errorf("Detect(%q) = %s, want %s", url, got, want)
+		}
+	}
+}
@@ -0,0 +1,295 @@
+package forge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/gitops"
+)
+
+// CreatePR creates a pull request using the gh CLI.
+// Deprecated: Use CreatePRInDir instead for explicit working directory.
+func CreatePR(ctx context.Context, title, body, baseBranch string) (*PRResult, error) {
+	return CreatePRInDir(ctx, "", title, body, baseBranch)
+}
+
+// CreatePRInDir creates a pull request using the gh CLI in the specified directory.
+func CreatePRInDir(ctx context.Context, workDir, title, body, baseBranch string) (*PRResult, error) {
+	return createWithGH(ctx, workDir, NewPR{Title: title, Body: body, Base: baseBranch})
+}
+
+// createWithGH opens pr with gh pr create in workDir.
+func createWithGH(ctx context.Context, workDir string, pr NewPR) (*PRResult, error) {
+	// Use gh CLI which is already authenticated
+	args := []string{"pr", "create",
+		"--title", pr.Title,
+		"--body", pr.Body,
+		"--base", pr.Base,
+	}
+	if pr.Head != "" {
+		args = append(args, "--head", pr.Head)
+	}
+	if pr.Draft {
+		args = append(args, "--draft")
+	}
+	cmd := exec.CommandContext(ctx, "gh", args...)
+
+	if workDir != "" {
+		cmd.Dir = workDir
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("gh pr create failed: %w\nstderr: %s", err, stderr.String())
+	}
+
+	// gh pr create outputs the PR URL
+	prURL := strings.TrimSpace(stdout.String())
+
+	return &PRResult{
+		URL: prURL,
+	}, nil
+}
+
+// GitHub opens and updates PRs through the GitHub API when a token is
+// configured, and through the gh CLI otherwise.
+type GitHub struct {
+	api *GitHubAPI
+}
+
+// NewGitHub creates a GitHub forge for the GitHub settings.
+func NewGitHub(cfg config.GitHubConfig) *GitHub {
+	if cfg.Token == "" {
+		return &GitHub{}
+	}
+	return &GitHub{api: NewGitHubAPI(cfg)}
+}
+
+// UsesAPI reports whether requests go to the GitHub API rather than gh.
+func (c *GitHub) UsesAPI() bool {
+	return c.api != nil
+}
+
+// CreatePR opens pr for the repository checked out in workDir, whose
+// origin remote names the GitHub repository for the API.
+func (c *GitHub) CreatePR(ctx context.Context, workDir string, pr NewPR) (*PRResult, error) {
+	if c.api == nil {
+		return createWithGH(ctx, workDir, pr)
+	}
+	remote, err := gitops.New(workDir).WithContext(ctx).RemoteURL("origin")
+	if err != nil {
+		return nil, err
+	}
+	repo, ok := ParseRemote(remote)
+	if !ok {
+		return nil, fmt.Errorf("origin %s is not a GitHub repository", remote)
+	}
+	return c.api.CreatePR(ctx, repo, pr)
+}
+
+// RequestReviewers requests reviews of the PR at url from reviewers, GitHub
+// users or org/team slugs.
+func (c *GitHub) RequestReviewers(ctx context.Context, url string, reviewers []string) error {
+	if c.api != nil {
+		return c.api.RequestReviewers(ctx, url, reviewers)
+	}
+	return editWithGH(ctx, url, "--add-reviewer", strings.Join(reviewers, ","))
+}
+
+// AddLabels applies labels to the PR at url.
+func (c *GitHub) AddLabels(ctx context.Context, url string, labels []string) error {
+	if c.api != nil {
+		return c.api.AddLabels(ctx, url, labels)
+	}
+	return editWithGH(ctx, url, "--add-label", strings.Join(labels, ","))
+}
+
+// editWithGH runs gh pr edit on the PR at url.
+func editWithGH(ctx context.Context, url string, args ...string) error {
+	cmd := exec.CommandContext(ctx, "gh", append([]string{"pr", "edit", url}, args...)...)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("gh pr edit failed: %w\nstderr: %s", err, stderr.String())
+	}
+	return nil
+}
+
+// PR states reported by PRState.
+const (
+	PRStateOpen   = "OPEN"
+	PRStateMerged = "MERGED"
+	PRStateClosed = "CLOSED"
+)
+
+// PRState returns the state of the PR at url: PRStateOpen, PRStateMerged
+// or PRStateClosed.
+func PRState(ctx context.Context, url string) (string, error) {
+	cmd := exec.CommandContext(ctx, "gh", "pr", "view", url, "--json", "state", "--jq", ".state")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("gh pr view failed: %w\nstderr: %s", err, stderr.String())
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// PRMergedAt returns when the PR at url was merged, or the zero time if it
+// is not merged.
+func PRMergedAt(ctx context.Context, url string) (time.Time, error) {
+	cmd := exec.CommandContext(ctx, "gh", "pr", "view", url, "--json", "mergedAt", "--jq", ".mergedAt")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return time.Time{}, fmt.Errorf("gh pr view failed: %w\nstderr: %s", err, stderr.String())
+	}
+	out := strings.TrimSpace(stdout.String())
+	if out == "" || out == "null" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, out)
+}
+
+// PRInfo describes an existing PR.
+type PRInfo struct {
+	Number     int    `json:"number"`
+	Title      string `json:"title"`
+	URL        string `json:"url"`
+	State      string `json:"state"`
+	BaseBranch string `json:"baseRefName"`
+	// MergeCommit is set once the PR is merged.
+	MergeCommit *struct {
+		OID string `json:"oid"`
+	} `json:"mergeCommit"`
+}
+
+// ViewPR returns the PR with the given number or URL in the repository
+// checked out in workDir.
+func ViewPR(ctx context.Context, workDir, pr string) (*PRInfo, error) {
+	cmd := exec.CommandContext(ctx, "gh", "pr", "view", pr, "--json", "number,title,url,state,baseRefName,mergeCommit")
+	cmd.Dir = workDir
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("gh pr view failed: %w\nstderr: %s", err, stderr.String())
+	}
+	var info PRInfo
+	if err := json.Unmarshal(stdout.Bytes(), &info); err != nil {
+		return nil, fmt.Errorf("failed to parse gh pr view output: %w", err)
+	}
+	return &info, nil
+}
+
+// PRStatus is what a PR needs to merge: its state, reviews and checks.
+type PRStatus struct {
+	State string `json:"state"`
+	// ReviewDecision is APPROVED, CHANGES_REQUESTED, REVIEW_REQUIRED, or
+	// empty when the repository doesn't require reviews.
+	ReviewDecision string  `json:"reviewDecision"`
+	Checks         []Check `json:"statusCheckRollup"`
+	// AutoMerge is set once auto-merge is enabled.
+	AutoMerge *struct {
+		MergeMethod string `json:"mergeMethod"`
+	} `json:"autoMergeRequest"`
+}
+
+// Check is a check run or commit status on a PR. Check runs report
+// Status and Conclusion; commit statuses report State.
+type Check struct {
+	Name       string `json:"name"`
+	Context    string `json:"context"`
+	Status     string `json:"status"`
+	Conclusion string `json:"conclusion"`
+	State      string `json:"state"`
+}
+
+// GetPRStatus returns the state, review decision and checks of the PR at url.
+func GetPRStatus(ctx context.Context, url string) (*PRStatus, error) {
+	cmd := exec.CommandContext(ctx, "gh", "pr", "view", url, "--json", "state,reviewDecision,statusCheckRollup,autoMergeRequest")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("gh pr view failed: %w\nstderr: %s", err, stderr.String())
+	}
+	var status PRStatus
+	if err := json.Unmarshal(stdout.Bytes(), &status); err != nil {
+		return nil, fmt.Errorf("failed to parse gh pr view output: %w", err)
+	}
+	return &status, nil
+}
+
+// Merge methods, in the order MergeMethod prefers them.
+const (
+	MergeSquash = "squash"
+	MergeRebase = "rebase"
+	MergeCommit = "merge"
+)
+
+// MergeMethod returns the first of squash, rebase and merge that the
+// repository of the PR at url allows.
+func MergeMethod(ctx context.Context, url string) (string, error) {
+	repo, _, _ := strings.Cut(url, "/pull/")
+	cmd := exec.CommandContext(ctx, "gh", "repo", "view", repo, "--json", "squashMergeAllowed,rebaseMergeAllowed,mergeCommitAllowed")
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("gh repo view failed: %w\nstderr: %s", err, stderr.String())
+	}
+	var allowed struct {
+		Squash bool `json:"squashMergeAllowed"`
+		Rebase bool `json:"rebaseMergeAllowed"`
+		Merge  bool `json:"mergeCommitAllowed"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &allowed); err != nil {
+		return "", fmt.Errorf("failed to parse gh repo view output: %w", err)
+	}
+	switch {
+	case allowed.Squash:
+		return MergeSquash, nil
+	case allowed.Rebase:
+		return MergeRebase, nil
+	case allowed.Merge:
+		return MergeCommit, nil
+	}
+	return "", fmt.Errorf("%s allows no merge method", repo)
+}
+
+// EnableAutoMerge turns on auto-merge for the PR at url with method, so
+// GitHub merges it as soon as branch protection allows.
+func EnableAutoMerge(ctx context.Context, url, method string) error {
+	cmd := exec.CommandContext(ctx, "gh", "pr", "merge", url, "--auto", "--"+method)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("gh pr merge failed: %w\nstderr: %s", err, stderr.String())
+	}
+	return nil
+}
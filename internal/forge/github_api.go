@@ -0,0 +1,207 @@
+package forge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/retry"
+)
+
+// maxRetryAfter caps how long a rate-limited request waits before retrying.
+const maxRetryAfter = time.Minute
+
+// GitHubAPI opens and updates PRs through the GitHub REST API.
+type GitHubAPI struct {
+	token      string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewGitHubAPI creates a GitHub API client. The URL defaults to
+// https://api.github.com; GitHub Enterprise uses https://<host>/api/v3.
+func NewGitHubAPI(cfg config.GitHubConfig) *GitHubAPI {
+	baseURL := strings.TrimSuffix(cfg.APIURL, "/")
+	if baseURL == "" {
+		baseURL = "https://api.github.com"
+	}
+	return &GitHubAPI{
+		token:      cfg.Token,
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// CreatePR opens a pull request in repo.
+func (c *GitHubAPI) CreatePR(ctx context.Context, repo Repo, pr NewPR) (*PRResult, error) {
+	body := map[string]any{
+		"title": pr.Title,
+		"body":  pr.Body,
+		"head":  pr.Head,
+		"base":  pr.Base,
+		"draft": pr.Draft,
+	}
+	resp, err := c.execute(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/%s/pulls", repo.Owner, repo.Name), body)
+	if err != nil {
+		return nil, err
+	}
+	var created struct {
+		HTMLURL string `json:"html_url"`
+	}
+	if err := json.Unmarshal(resp, &created); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return &PRResult{URL: created.HTMLURL}, nil
+}
+
+// RequestReviewers requests reviews of the PR at url from users and, for
+// org/team slugs, teams.
+func (c *GitHubAPI) RequestReviewers(ctx context.Context, url string, reviewers []string) error {
+	repo, number, err := ParsePRURL(url)
+	if err != nil {
+		return err
+	}
+	users, teams := []string{}, []string{}
+	for _, r := range reviewers {
+		if _, team, ok := strings.Cut(r, "/"); ok {
+			teams = append(teams, team)
+		} else {
+			users = append(users, r)
+		}
+	}
+	body := map[string]any{"reviewers": users, "team_reviewers": teams}
+	_, err = c.execute(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/%s/pulls/%d/requested_reviewers", repo.Owner, repo.Name, number), body)
+	return err
+}
+
+// AddLabels applies labels to the PR at url.
+func (c *GitHubAPI) AddLabels(ctx context.Context, url string, labels []string) error {
+	repo, number, err := ParsePRURL(url)
+	if err != nil {
+		return err
+	}
+	_, err = c.execute(ctx, http.MethodPost, fmt.Sprintf("/repos/%s/%s/issues/%d/labels", repo.Owner, repo.Name, number),
+		map[string]any{"labels": labels})
+	return err
+}
+
+// execute performs a REST request with retry logic. Rate-limited requests
+// wait out Retry-After (up to maxRetryAfter) before the next attempt.
+func (c *GitHubAPI) execute(ctx context.Context, method, path string, body any) ([]byte, error) {
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	var result []byte
+	err = retry.Do(ctx, retry.APIConfig(), "GitHub API request", func() error {
+		req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(jsonBody))
+		if err != nil {
+			return retry.Permanent(fmt.Errorf("failed to create request: %w", err))
+		}
+		req.Header.Set("Accept", "application/vnd.github+json")
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+c.token)
+		req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("request failed: %w", err) // Retryable
+		}
+		defer resp.Body.Close()
+
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+
+		if rateLimited(resp, respBody) {
+			if wait := retryAfter(resp); wait > 0 {
+				timer := time.NewTimer(wait)
+				defer timer.Stop()
+				select {
+				case <-timer.C:
+				case <-ctx.Done():
+					return retry.Permanent(ctx.Err())
+				}
+			}
+			return fmt.Errorf("API rate limited (status %d): %s", resp.StatusCode, errorMessage(respBody))
+		}
+		if resp.StatusCode >= 500 {
+			return fmt.Errorf("API returned status %d: %s", resp.StatusCode, errorMessage(respBody))
+		}
+		if resp.StatusCode >= 400 {
+			return retry.Permanent(fmt.Errorf("API returned status %d: %s", resp.StatusCode, errorMessage(respBody)))
+		}
+
+		result = respBody
+		return nil
+	})
+	return result, err
+}
+
+// rateLimited reports whether a response is GitHub's primary or secondary
+// rate limit, which come as 429 or as 403 with a rate limit message.
+func rateLimited(resp *http.Response, body []byte) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusForbidden:
+		return resp.Header.Get("Retry-After") != "" || resp.Header.Get("X-RateLimit-Remaining") == "0" ||
+			strings.Contains(strings.ToLower(string(body)), "rate limit")
+	}
+	return false
+}
+
+// retryAfter returns how long GitHub asked to wait, from Retry-After or
+// the rate limit reset time, capped at maxRetryAfter.
+func retryAfter(resp *http.Response) time.Duration {
+	var wait time.Duration
+	if secs, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		wait = time.Duration(secs) * time.Second
+	} else if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		wait = time.Until(time.Unix(reset, 0))
+	}
+	return min(max(wait, 0), maxRetryAfter)
+}
+
+// errorMessage extracts GitHub's error message from a response body.
+func errorMessage(body []byte) string {
+	var result struct {
+		Message string `json:"message"`
+		Errors  []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if json.Unmarshal(body, &result) != nil || result.Message == "" {
+		return string(body)
+	}
+	msg := result.Message
+	for _, e := range result.Errors {
+		if e.Message != "" {
+			msg += ": " + e.Message
+		}
+	}
+	return msg
+}
+
+// prURL matches GitHub PR URLs.
+var prURL = regexp.MustCompile(`^https?://[^/]+/([^/]+)/([^/]+)/pull/(\d+)`)
+
+// ParsePRURL returns the repository and number of a GitHub PR URL.
+func ParsePRURL(url string) (Repo, int, error) {
+	m := prURL.FindStringSubmatch(url)
+	if m == nil {
+		return Repo{}, 0, fmt.Errorf("not a GitHub PR URL: %s", url)
+	}
+	number, _ := strconv.Atoi(m[3])
+	return Repo{Owner: m[1], Name: m[2]}, number, nil
+}
@@ -0,0 +1,125 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+)
+
+func TestGitHubAPICreatePR(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/acme/web/pulls" {
+			t.Errorf("request = %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer ghp_x" {
+			t.Errorf("Authorization = %q", got)
+		}
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["head"] != "eng-1-export" || body["base"] != "main" || body["draft"] != true || body["title"] != "Export" {
+			t.Errorf("body = %v", body)
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"number": 7, "html_url": "https://github.com/acme/web/pull/7"}`))
+	}))
+	defer srv.Close()
+
+	api := NewGitHubAPI(config.GitHubConfig{Token: "ghp_x", APIURL: srv.URL + "/"})
+	pr, err := api.CreatePR(context.Background(), Repo{Owner: "acme", Name: "web"}, NewPR{
+		Title: "Export", Body: "Adds export", Base: "main", Head: "eng-1-export", Draft: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr.URL != "https://github.com/acme/web/pull/7" {
+		t.Errorf("URL = %s", pr.URL)
+	}
+}
+
+func TestGitHubAPIReviewersAndLabels(t *testing.T) {
+	requests := map[string]map[string][]string{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string][]string
+		json.NewDecoder(r.Body).Decode(&body)
+		requests[r.URL.Path] = body
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	api := NewGitHubAPI(config.GitHubConfig{Token: "ghp_x", APIURL: srv.URL})
+	url := "https://github.com/acme/web/pull/7"
+	if err := api.RequestReviewers(context.Background(), url, []string{"alice", "acme/payments"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := api.AddLabels(context.Background(), url, []string{"boatman"}); err != nil {
+		t.Fatal(err)
+	}
+
+	reviewers := requests["/repos/acme/web/pulls/7/requested_reviewers"]
+	if len(reviewers["reviewers"]) != 1 || reviewers["reviewers"][0] != "alice" ||
+		len(reviewers["team_reviewers"]) != 1 || reviewers["team_reviewers"][0] != "payments" {
+		t.Errorf("reviewers request = %v", reviewers)
+	}
+	if labels := requests["/repos/acme/web/issues/7/labels"]; len(labels["labels"]) != 1 || labels["labels"][0] != "boatman" {
+		t.Errorf("labels request = %v", labels)
+	}
+}
+
+func TestGitHubAPISecondaryRateLimit(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message": "You have exceeded a secondary rate limit."}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"html_url": "https://github.com/acme/web/pull/8"}`))
+	}))
+	defer srv.Close()
+
+	api := NewGitHubAPI(config.GitHubConfig{Token: "ghp_x", APIURL: srv.URL})
+	pr, err := api.CreatePR(context.Background(), Repo{Owner: "acme", Name: "web"}, NewPR{Title: "x", Base: "main", Head: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 || pr.URL != "https://github.com/acme/web/pull/8" {
+		t.Errorf("calls = %d, pr = %+v", calls, pr)
+	}
+}
+
+func TestGitHubAPIPermanentError(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		w.Write([]byte(`{"message": "Validation Failed", "errors": [{"message": "A pull request already exists for acme:x."}]}`))
+	}))
+	defer srv.Close()
+
+	api := NewGitHubAPI(config.GitHubConfig{Token: "ghp_x", APIURL: srv.URL})
+	_, err := api.CreatePR(context.Background(), Repo{Owner: "acme", Name: "web"}, NewPR{Title: "x", Base: "main", Head: "x"})
+	if err == nil || calls != 1 {
+		t.Fatalf("err = %v after %d call(s), want one failed call", err, calls)
+	}
+	if want := "API returned status 422: Validation Failed: A pull request already exists for acme:x."; err.Error() != want {
+		t.Errorf("err = %q, want %q", err, want)
+	}
+}
+
+func TestParsePRURL(t *testing.T) {
+	repo, number, err := ParsePRURL("https://github.com/acme/web/pull/42/files")
+	if err != nil || repo != (Repo{Owner: "acme", Name: "web"}) || number != 42 {
+		t.Errorf("ParsePRURL = %+v, %d, %v", repo, number, err)
+	}
+	if _, _, err := ParsePRURL("https://github.com/acme/web/issues/42"); err == nil {
+		t.Error("expected an error for an issue URL")
+	}
+}
@@ -0,0 +1,106 @@
+// Package gerrit submits changes for review to Gerrit instead of opening a
+// GitHub PR: the commit carries a Change-Id trailer and is pushed to
+// refs/for/<branch> with a topic.
+package gerrit
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/gitops"
+	"github.com/philjestin/boatmanmode/internal/retry"
+)
+
+// Detect reports whether the repository at dir reviews changes in Gerrit:
+// it has a .gitreview file or its remote uses Gerrit's SSH port, 29418.
+func Detect(dir, remoteURL string) bool {
+	if _, err := os.Stat(filepath.Join(dir, ".gitreview")); err == nil {
+		return true
+	}
+	return strings.Contains(remoteURL, ":29418/") || strings.HasSuffix(remoteURL, ":29418")
+}
+
+// ChangeID returns the Change-Id of a ticket's branch. It is stable so
+// that pushing the branch again uploads a new patch set of the same change.
+func ChangeID(ticketID, branch string) string {
+	sum := sha1.Sum([]byte("boatman\x00" + ticketID + "\x00" + branch))
+	return "I" + hex.EncodeToString(sum[:])
+}
+
+var changeIDTrailer = regexp.MustCompile(`(?m)^Change-Id: I[0-9a-f]{40}\s*$`)
+
+// WithChangeID adds the Change-Id trailer to a commit message that does
+// not have one.
+func WithChangeID(message, changeID string) string {
+	if changeIDTrailer.MatchString(message) {
+		return message
+	}
+	return strings.TrimRight(message, "\n") + "\n\nChange-Id: " + changeID + "\n"
+}
+
+// Ref returns the refspec that pushes for review on base, with a topic.
+func Ref(base, topic string) string {
+	ref := "refs/for/" + base
+	if topic = sanitizeTopic(topic); topic != "" {
+		ref += "%topic=" + topic
+	}
+	return ref
+}
+
+// sanitizeTopic drops the characters push options cannot carry.
+func sanitizeTopic(topic string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', ',', '%', '\t', '\n':
+			return '-'
+		}
+		return r
+	}, strings.TrimSpace(topic))
+}
+
+// changeURL matches the change links Gerrit prints for a push.
+var changeURL = regexp.MustCompile(`(?m)^remote:\s+(https?://\S+)`)
+
+// Push pushes HEAD in dir to remote for review on base and returns the
+// URL of the change, or "" if Gerrit did not print one. Transient network
+// failures are retried; failures are a *gitops.Error.
+func Push(ctx context.Context, dir, remote, base, topic string) (string, error) {
+	// Gerrit reports the change on stderr, which gitops only keeps on failure
+	args := []string{"push", remote, "HEAD:" + Ref(base, topic)}
+	var out bytes.Buffer
+	err := retry.Do(ctx, retry.CLIConfig(), "git push for review", func() error {
+		out.Reset()
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = dir
+		var stdout bytes.Buffer
+		cmd.Stdout = &stdout
+		cmd.Stderr = &out
+		if err := cmd.Run(); err != nil {
+			err = &gitops.Error{Args: args, Stdout: strings.TrimSpace(stdout.String()), Stderr: strings.TrimSpace(out.String()), Err: err}
+			if !gitops.IsTransient(err) {
+				return retry.Permanent(err)
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return ParseChangeURL(out.String()), nil
+}
+
+// ParseChangeURL returns the first change URL in a push's output.
+func ParseChangeURL(output string) string {
+	if m := changeURL.FindStringSubmatch(output); m != nil {
+		return m[1]
+	}
+	return ""
+}
@@ -0,0 +1,94 @@
+package gerrit
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	dir := t.TempDir()
+	if Detect(dir, "git@github.com:acme/app.git") {
+		t.Error("Detect matched a GitHub remote")
+	}
+	if !Detect(dir, "ssh://dev@review.acme.com:29418/app") {
+		t.Error("Detect missed a Gerrit SSH remote")
+	}
+	os.WriteFile(filepath.Join(dir, ".gitreview"), []byte("[gerrit]\nhost=review.acme.com\n"), 0644)
+	if !Detect(dir, "https://review.acme.com/app") {
+		t.Error("Detect missed .gitreview")
+	}
+}
+
+func TestChangeID(t *testing.T) {
+	id := ChangeID("ENG-123", "feat/eng-123-login")
+	if len(id) != 41 || id[0] != 'I' || id != ChangeID("ENG-123", "feat/eng-123-login") {
+		t.Errorf("ChangeID = %q, want a stable I + 40 hex digits", id)
+	}
+	if id == ChangeID("ENG-124", "feat/eng-124-login") {
+		t.Error("different branches got the same Change-Id")
+	}
+
+	msg := WithChangeID("feat(ENG-123): Add login\n\nSummary\n", id)
+	if !strings.HasSuffix(msg, "Summary\n\nChange-Id: "+id+"\n") {
+		t.Errorf("WithChangeID = %q", msg)
+	}
+	if again := WithChangeID(msg, ChangeID("x", "y")); again != msg {
+		t.Errorf("WithChangeID replaced an existing trailer: %q", again)
+	}
+}
+
+func TestRef(t *testing.T) {
+	if ref := Ref("main", "ENG-123 login, v2"); ref != "refs/for/main%topic=ENG-123-login--v2" {
+		t.Errorf("Ref = %q", ref)
+	}
+	if ref := Ref("main", ""); ref != "refs/for/main" {
+		t.Errorf("Ref without topic = %q", ref)
+	}
+}
+
+func TestParseChangeURL(t *testing.T) {
+	out := `remote: Processing changes: new: 1, done
+remote:
+remote: SUCCESS
+remote:
+remote:   https://review.acme.com/c/app/+/4711 feat(ENG-123): Add login [NEW]
+remote:
+To ssh://review.acme.com:29418/app
+ * [new reference]   HEAD -> refs/for/main%topic=ENG-123
+`
+	if url := ParseChangeURL(out); url != "https://review.acme.com/c/app/+/4711" {
+		t.Errorf("ParseChangeURL = %q", url)
+	}
+}
+
+func TestPush(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	remote := t.TempDir()
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "--bare", remote},
+		{"-C", dir, "init"},
+		{"-C", dir, "-c", "user.name=t", "-c", "user.email=t@t", "commit", "--allow-empty", "-m", "change"},
+	} {
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	url, err := Push(context.Background(), dir, remote, "main", "ENG-123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != "" {
+		t.Errorf("url = %q from a remote that prints none", url)
+	}
+	if out, err := exec.Command("git", "-C", remote, "show-ref").Output(); err != nil || !strings.Contains(string(out), "refs/for/main%topic=ENG-123") {
+		t.Errorf("remote refs = %s (%v)", out, err)
+	}
+}
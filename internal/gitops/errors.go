@@ -0,0 +1,88 @@
+package gitops
+
+import (
+	"errors"
+	"strings"
+)
+
+// Common git failures, matched against an *Error with errors.Is so callers
+// don't have to parse git's output, which varies between git versions.
+var (
+	// ErrNotRepository is returned when the directory is not in a repository.
+	ErrNotRepository = errors.New("not a git repository")
+	// ErrUnknownRevision is returned when a revision or ref doesn't exist.
+	ErrUnknownRevision = errors.New("unknown revision")
+	// ErrNothingToCommit is returned by Commit when nothing is staged.
+	ErrNothingToCommit = errors.New("nothing to commit")
+	// ErrConflict is returned when a merge, rebase or checkout conflicts.
+	ErrConflict = errors.New("conflict")
+	// ErrRejected is returned when the remote rejects a push.
+	ErrRejected = errors.New("push rejected")
+	// ErrAuth is returned when the remote refuses the credentials.
+	ErrAuth = errors.New("authentication failed")
+	// ErrTransient is returned for network failures worth retrying.
+	ErrTransient = errors.New("transient network error")
+)
+
+// errorMarkers are lowercase output fragments identifying each failure.
+var errorMarkers = map[error][]string{
+	ErrNotRepository: {"not a git repository"},
+	ErrUnknownRevision: {
+		"unknown revision",
+		"bad revision",
+		"not a valid object name",
+		"needed a single revision",
+		"invalid reference",
+		"did not match any",
+	},
+	ErrNothingToCommit: {"nothing to commit", "nothing added to commit", "no changes added to commit"},
+	ErrConflict: {
+		"conflict (",
+		"could not apply",
+		"fix conflicts",
+		"would be overwritten by",
+		"you have unmerged paths",
+	},
+	ErrRejected: {"[rejected]", "[remote rejected]", "non-fast-forward", "failed to push some refs"},
+	ErrAuth: {
+		"authentication failed",
+		"permission denied",
+		"could not read username",
+		"invalid username or password",
+		"403 forbidden",
+	},
+	ErrTransient: {
+		"could not resolve host",
+		"connection timed out",
+		"connection reset",
+		"connection refused",
+		"operation timed out",
+		"the remote end hung up unexpectedly",
+		"early eof",
+		"rpc failed",
+		"unable to access",
+		"internal server error",
+		"502 bad gateway",
+		"503 service unavailable",
+	},
+}
+
+// Is reports whether the failure is one of the sentinel errors above.
+func (e *Error) Is(target error) bool {
+	markers, ok := errorMarkers[target]
+	if !ok {
+		return false
+	}
+	output := strings.ToLower(e.Stderr + "\n" + e.Stdout)
+	for _, marker := range markers {
+		if strings.Contains(output, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTransient reports whether a git error looks like a transient network failure.
+func IsTransient(err error) bool {
+	return errors.Is(err, ErrTransient)
+}
@@ -0,0 +1,70 @@
+package gitops
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func TestErrorIs(t *testing.T) {
+	tests := []struct {
+		stderr, stdout string
+		want           error
+	}{
+		{stderr: "fatal: not a git repository (or any of the parent directories): .git", want: ErrNotRepository},
+		{stderr: "fatal: ambiguous argument 'nope': unknown revision or path not in the working tree.", want: ErrUnknownRevision},
+		{stdout: "On branch main\nnothing to commit, working tree clean", want: ErrNothingToCommit},
+		{stdout: "CONFLICT (content): Merge conflict in a.go", stderr: "error: could not apply 1a2b3c... Add a", want: ErrConflict},
+		{stderr: " ! [rejected]        main -> main (non-fast-forward)", want: ErrRejected},
+		{stderr: "remote: Invalid username or password.\nfatal: Authentication failed for 'https://github.com/x/y.git/'", want: ErrAuth},
+		{stderr: "fatal: unable to access 'https://github.com/x/y.git/': Could not resolve host: github.com", want: ErrTransient},
+	}
+	sentinels := []error{ErrNotRepository, ErrUnknownRevision, ErrNothingToCommit, ErrConflict, ErrRejected, ErrAuth, ErrTransient}
+	for _, tt := range tests {
+		err := error(&Error{Args: []string{"x"}, Stdout: tt.stdout, Stderr: tt.stderr, Err: errors.New("exit status 1")})
+		for _, sentinel := range sentinels {
+			if got := errors.Is(err, sentinel); got != (sentinel == tt.want) {
+				t.Errorf("errors.Is(%q, %v) = %v", tt.stderr+tt.stdout, sentinel, got)
+			}
+		}
+	}
+	if errors.Is(errors.New("not a git repository"), ErrNotRepository) {
+		t.Error("only *Error should match the sentinels")
+	}
+}
+
+func TestExecRunnerErrorKinds(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	if _, err := New(dir).RevParse("HEAD"); !errors.Is(err, ErrNotRepository) {
+		t.Errorf("rev-parse outside a repo = %v, want ErrNotRepository", err)
+	}
+
+	repo := New(dir)
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test"},
+	} {
+		if _, err := repo.Run(args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a\n"), 0644)
+	if err := repo.AddAll(); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Commit("first"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Commit("again"); !errors.Is(err, ErrNothingToCommit) {
+		t.Errorf("empty commit = %v, want ErrNothingToCommit", err)
+	}
+	if _, err := repo.RevParse("--verify", "nope"); !errors.Is(err, ErrUnknownRevision) {
+		t.Errorf("rev-parse nope = %v, want ErrUnknownRevision", err)
+	}
+}
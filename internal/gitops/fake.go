@@ -0,0 +1,54 @@
+package gitops
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// FakeRunner is a CommandRunner for tests. It records every call and
+// returns canned responses keyed by the space-joined arguments.
+type FakeRunner struct {
+	mu        sync.Mutex
+	responses map[string]fakeResponse
+	calls     [][]string
+}
+
+type fakeResponse struct {
+	output string
+	err    error
+}
+
+// NewFakeRunner creates an empty FakeRunner.
+// Commands without a canned response succeed with no output.
+func NewFakeRunner() *FakeRunner {
+	return &FakeRunner{responses: make(map[string]fakeResponse)}
+}
+
+// On sets the response for a command, e.g. On("status --porcelain", " M a.go\n", nil).
+func (f *FakeRunner) On(args string, output string, err error) *FakeRunner {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses[args] = fakeResponse{output: output, err: err}
+	return f
+}
+
+// Run records the call and returns the canned response.
+func (f *FakeRunner) Run(ctx context.Context, dir string, args ...string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, append([]string(nil), args...))
+	resp := f.responses[strings.Join(args, " ")]
+	return resp.output, resp.err
+}
+
+// Calls returns the recorded commands as space-joined strings.
+func (f *FakeRunner) Calls() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	calls := make([]string, len(f.calls))
+	for i, c := range f.calls {
+		calls[i] = strings.Join(c, " ")
+	}
+	return calls
+}
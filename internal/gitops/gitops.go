@@ -0,0 +1,453 @@
+// Package gitops provides git operations behind a mockable command runner.
+// All git invocations go through a CommandRunner so errors are reported
+// consistently (with stderr) and callers can be unit tested without a repo.
+package gitops
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// CommandRunner runs a git command in a directory and returns its stdout.
+type CommandRunner interface {
+	Run(ctx context.Context, dir string, args ...string) (string, error)
+}
+
+// ExecRunner runs git using os/exec.
+type ExecRunner struct {
+	// Binary is the git executable (default: "git").
+	Binary string
+}
+
+// Run executes git with the given arguments.
+// On failure the returned error is an *Error carrying git's output.
+func (r ExecRunner) Run(ctx context.Context, dir string, args ...string) (string, error) {
+	bin := r.Binary
+	if bin == "" {
+		bin = "git"
+	}
+
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Dir = dir
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return stdout.String(), &Error{
+			Args:   args,
+			Stdout: strings.TrimSpace(stdout.String()),
+			Stderr: strings.TrimSpace(stderr.String()),
+			Err:    err,
+		}
+	}
+	return stdout.String(), nil
+}
+
+// Error is returned when a git command fails. Match it against the
+// sentinel errors, e.g. errors.Is(err, ErrConflict), to tell failures apart.
+type Error struct {
+	Args   []string
+	Stdout string
+	Stderr string
+	Err    error
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("git %s: %v", strings.Join(e.Args, " "), e.Err)
+	detail := e.Stderr
+	if detail == "" {
+		// Some failures, e.g. nothing to commit, are only explained on stdout
+		detail = e.Stdout
+	}
+	if detail != "" {
+		msg += ": " + detail
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Repo runs git operations in a repository or worktree directory.
+type Repo struct {
+	dir    string
+	runner CommandRunner
+	ctx    context.Context
+}
+
+// New creates a Repo that runs real git commands in dir.
+func New(dir string) *Repo {
+	return NewWithRunner(dir, ExecRunner{})
+}
+
+// NewWithRunner creates a Repo that runs commands through runner.
+func NewWithRunner(dir string, runner CommandRunner) *Repo {
+	return &Repo{
+		dir:    dir,
+		runner: runner,
+		ctx:    context.Background(),
+	}
+}
+
+// WithContext returns a copy of the Repo whose commands are bound to ctx.
+func (r *Repo) WithContext(ctx context.Context) *Repo {
+	c := *r
+	c.ctx = ctx
+	return &c
+}
+
+// Dir returns the directory commands run in.
+func (r *Repo) Dir() string {
+	return r.dir
+}
+
+// Run executes an arbitrary git command and returns its stdout.
+func (r *Repo) Run(args ...string) (string, error) {
+	return r.runner.Run(r.ctx, r.dir, args...)
+}
+
+// exec runs a git command, discarding its output.
+func (r *Repo) exec(args ...string) error {
+	_, err := r.Run(args...)
+	return err
+}
+
+// Diff returns the output of git diff with the given arguments.
+// Changes inside submodules are always shown as content diffs rather than
+// bare commit hashes, so diffs look the same with or without submodules.
+func (r *Repo) Diff(args ...string) (string, error) {
+	return r.Run(append([]string{"diff", "--submodule=diff"}, args...)...)
+}
+
+// StatusEntry is one line of git status --porcelain output.
+type StatusEntry struct {
+	Code     string // Two-character status code, e.g. " M", "A ", "??"
+	Path     string
+	OrigPath string // Source path for renames/copies
+}
+
+// Status returns the working tree status.
+func (r *Repo) Status() ([]StatusEntry, error) {
+	out, err := r.Run("status", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+	return ParseStatus(out), nil
+}
+
+// ParseStatus parses git status --porcelain output.
+func ParseStatus(out string) []StatusEntry {
+	var entries []StatusEntry
+	for _, line := range strings.Split(out, "\n") {
+		// Format is "XY path" - don't trim, the leading space is significant
+		if len(line) < 4 {
+			continue
+		}
+		entry := StatusEntry{Code: line[:2], Path: line[3:]}
+		if idx := strings.Index(entry.Path, " -> "); idx != -1 {
+			entry.OrigPath = entry.Path[:idx]
+			entry.Path = entry.Path[idx+4:]
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// Change is a file changed relative to a commit, as listed by
+// git diff --name-status.
+type Change struct {
+	Status   byte // Status letter, e.g. 'M', 'A', 'D', 'R'
+	Path     string
+	OrigPath string // Source path for renames/copies
+}
+
+// ChangesSince lists the files changed between rev and the working tree,
+// pairing deleted and added files into renames when their contents are
+// similar. Untracked files are only seen once staged or marked with
+// IntentToAdd.
+func (r *Repo) ChangesSince(rev string) ([]Change, error) {
+	out, err := r.Run("diff", "--name-status", "-z", "-M", rev)
+	if err != nil {
+		return nil, err
+	}
+	return ParseNameStatus(out), nil
+}
+
+// ParseNameStatus parses git diff --name-status -z output.
+func ParseNameStatus(out string) []Change {
+	var changes []Change
+	fields := strings.Split(out, "\x00")
+	for i := 0; i+1 < len(fields); i += 2 {
+		if fields[i] == "" {
+			continue
+		}
+		// Renames and copies carry a similarity score and both paths
+		change := Change{Status: fields[i][0], Path: fields[i+1]}
+		if (change.Status == 'R' || change.Status == 'C') && i+2 < len(fields) {
+			change.OrigPath, change.Path = fields[i+1], fields[i+2]
+			i++
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// IntentToAdd records untracked paths in the index without their
+// contents, so diffs against a commit include them.
+func (r *Repo) IntentToAdd(paths ...string) error {
+	return r.exec(append([]string{"add", "--intent-to-add", "--"}, paths...)...)
+}
+
+// HasChanges reports whether the working tree has uncommitted changes.
+func (r *Repo) HasChanges() (bool, error) {
+	out, err := r.Run("status", "--porcelain")
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(out) != "", nil
+}
+
+// AddAll stages all changes, including untracked files.
+func (r *Repo) AddAll() error {
+	return r.exec("add", "-A")
+}
+
+// Stage stages the changes to paths, including deletions.
+func (r *Repo) Stage(paths ...string) error {
+	return r.exec(append([]string{"add", "-A", "--"}, paths...)...)
+}
+
+// HasStagedChanges reports whether the index differs from HEAD.
+func (r *Repo) HasStagedChanges() bool {
+	return r.exec("diff", "--cached", "--quiet") != nil
+}
+
+// ResetSoft moves the branch to rev, keeping the index and working tree,
+// so the changes of the commits after rev are left staged.
+func (r *Repo) ResetSoft(rev string) error {
+	return r.exec("reset", "--soft", rev)
+}
+
+// Unstage resets the index to HEAD, keeping the working tree.
+func (r *Repo) Unstage() error {
+	return r.exec("reset", "-q")
+}
+
+// Commit creates a commit with the given message.
+func (r *Repo) Commit(message string) error {
+	return r.exec("commit", "-m", message)
+}
+
+// Push pushes branch to remote and sets upstream.
+// Extra flags (e.g. --force-with-lease) are inserted before the remote.
+func (r *Repo) Push(remote, branch string, flags ...string) error {
+	args := append([]string{"push", "-u"}, flags...)
+	return r.exec(append(args, remote, branch)...)
+}
+
+// Fetch fetches refs from remote.
+func (r *Repo) Fetch(remote string, refs ...string) error {
+	return r.exec(append([]string{"fetch", remote}, refs...)...)
+}
+
+// Rebase rebases the current branch onto upstream.
+// A failed rebase is aborted so the worktree is left unchanged.
+func (r *Repo) Rebase(upstream string) error {
+	if err := r.exec("rebase", upstream); err != nil {
+		r.exec("rebase", "--abort")
+		return err
+	}
+	return nil
+}
+
+// Revert commits the reverse of commit. Merge commits are reverted
+// against their first parent. A failed revert is aborted so the worktree
+// is left unchanged.
+func (r *Repo) Revert(commit string) error {
+	args := []string{"revert", "--no-edit"}
+	if _, err := r.RevParse("--verify", "--quiet", commit+"^2"); err == nil {
+		args = append(args, "-m", "1")
+	}
+	if err := r.exec(append(args, commit)...); err != nil {
+		r.exec("revert", "--abort")
+		return err
+	}
+	return nil
+}
+
+// WriteTree writes the index as a tree object and returns its hash.
+func (r *Repo) WriteTree() (string, error) {
+	out, err := r.Run("write-tree")
+	return strings.TrimSpace(out), err
+}
+
+// CommitTree creates a commit of tree with the given parent, without
+// moving any branch, and returns its hash.
+func (r *Repo) CommitTree(tree, parent, message string) (string, error) {
+	out, err := r.Run("commit-tree", tree, "-p", parent, "-m", message)
+	return strings.TrimSpace(out), err
+}
+
+// Tag points a lightweight tag at target, replacing any tag of that name.
+func (r *Repo) Tag(name, target string) error {
+	return r.exec("tag", "-f", name, target)
+}
+
+// AddNote attaches message to target under refs/notes/<ref>, replacing
+// any note target already has there.
+func (r *Repo) AddNote(ref, target, message string) error {
+	return r.exec("notes", "--ref", ref, "add", "-f", "-m", message, target)
+}
+
+// RevParse resolves a revision to its value.
+func (r *Repo) RevParse(args ...string) (string, error) {
+	out, err := r.Run(append([]string{"rev-parse"}, args...)...)
+	return strings.TrimSpace(out), err
+}
+
+// CurrentBranch returns the checked-out branch name.
+func (r *Repo) CurrentBranch() (string, error) {
+	return r.RevParse("--abbrev-ref", "HEAD")
+}
+
+// RefExists reports whether a fully-qualified ref exists, e.g. refs/heads/main.
+func (r *Repo) RefExists(ref string) bool {
+	return r.exec("show-ref", "--verify", "--quiet", ref) == nil
+}
+
+// RemoteURL returns the URL of a remote.
+func (r *Repo) RemoteURL(remote string) (string, error) {
+	out, err := r.Run("remote", "get-url", remote)
+	return strings.TrimSpace(out), err
+}
+
+// DeleteBranch deletes a local branch.
+func (r *Repo) DeleteBranch(name string, force bool) error {
+	flag := "-d"
+	if force {
+		flag = "-D"
+	}
+	return r.exec("branch", flag, name)
+}
+
+// WorktreeEntry is a worktree from git worktree list.
+type WorktreeEntry struct {
+	Path   string
+	Head   string
+	Branch string // Short branch name; empty if detached
+}
+
+// WorktreeAdd creates a worktree at path.
+// If newBranch is true, branch is created from startPoint; otherwise the
+// existing branch is checked out. Flags (e.g. --no-checkout) precede the path.
+func (r *Repo) WorktreeAdd(path, branch string, newBranch bool, startPoint string, flags ...string) error {
+	args := append([]string{"worktree", "add"}, flags...)
+	if newBranch {
+		return r.exec(append(args, "-b", branch, path, startPoint)...)
+	}
+	return r.exec(append(args, path, branch)...)
+}
+
+// WorktreeRemove removes the worktree at path.
+func (r *Repo) WorktreeRemove(path string, force bool) error {
+	args := []string{"worktree", "remove", path}
+	if force {
+		args = append(args, "--force")
+	}
+	return r.exec(args...)
+}
+
+// WorktreeList returns all worktrees of the repository.
+func (r *Repo) WorktreeList() ([]WorktreeEntry, error) {
+	out, err := r.Run("worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []WorktreeEntry
+	var current WorktreeEntry
+	for _, line := range strings.Split(out+"\n", "\n") {
+		switch {
+		case strings.HasPrefix(line, "worktree "):
+			current.Path = strings.TrimPrefix(line, "worktree ")
+		case strings.HasPrefix(line, "HEAD "):
+			current.Head = strings.TrimPrefix(line, "HEAD ")
+		case strings.HasPrefix(line, "branch "):
+			current.Branch = strings.TrimPrefix(line, "branch refs/heads/")
+		case line == "" && current.Path != "":
+			entries = append(entries, current)
+			current = WorktreeEntry{}
+		}
+	}
+	return entries, nil
+}
+
+// Checkout runs git checkout with the given arguments.
+func (r *Repo) Checkout(args ...string) error {
+	return r.exec(append([]string{"checkout"}, args...)...)
+}
+
+// HasSubmodules reports whether the repository declares submodules.
+func (r *Repo) HasSubmodules() bool {
+	_, err := os.Stat(filepath.Join(r.dir, ".gitmodules"))
+	return err == nil
+}
+
+// SubmoduleUpdate initializes and checks out all submodules recursively.
+func (r *Repo) SubmoduleUpdate() error {
+	return r.exec("submodule", "update", "--init", "--recursive")
+}
+
+// SubmodulePaths returns the paths of submodules declared in .gitmodules.
+func (r *Repo) SubmodulePaths() ([]string, error) {
+	out, err := r.Run("config", "--file", ".gitmodules", "--get-regexp", `^submodule\..*\.path$`)
+	if err != nil {
+		return nil, err
+	}
+	var paths []string
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		if _, path, ok := strings.Cut(line, " "); ok {
+			paths = append(paths, path)
+		}
+	}
+	return paths, nil
+}
+
+// SparseCheckoutSet enables cone-mode sparse checkout limited to dirs.
+// With no dirs, only files at the repository root are checked out.
+func (r *Repo) SparseCheckoutSet(dirs ...string) error {
+	return r.exec(append([]string{"sparse-checkout", "set", "--cone"}, dirs...)...)
+}
+
+// SparseCheckoutAdd adds dirs to an existing sparse checkout.
+func (r *Repo) SparseCheckoutAdd(dirs ...string) error {
+	if len(dirs) == 0 {
+		return nil
+	}
+	return r.exec(append([]string{"sparse-checkout", "add"}, dirs...)...)
+}
+
+// SparseCheckoutDisable restores a full checkout.
+func (r *Repo) SparseCheckoutDisable() error {
+	return r.exec("sparse-checkout", "disable")
+}
+
+// IsSparse reports whether sparse checkout is enabled.
+func (r *Repo) IsSparse() bool {
+	out, err := r.Run("config", "--get", "core.sparseCheckout")
+	return err == nil && strings.TrimSpace(out) == "true"
+}
+
+// IsShallow reports whether the repository is a shallow clone.
+func (r *Repo) IsShallow() bool {
+	out, err := r.RevParse("--is-shallow-repository")
+	return err == nil && out == "true"
+}
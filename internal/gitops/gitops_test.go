@@ -0,0 +1,177 @@
+package gitops
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestParseStatus(t *testing.T) {
+	out := " M internal/a.go\nA  internal/b.go\n?? new.txt\nR  old.go -> renamed.go\n"
+
+	entries := ParseStatus(out)
+
+	if len(entries) != 4 {
+		t.Fatalf("Expected 4 entries, got %d", len(entries))
+	}
+	if entries[0].Code != " M" || entries[0].Path != "internal/a.go" {
+		t.Errorf("Unexpected first entry: %+v", entries[0])
+	}
+	if entries[2].Code != "??" || entries[2].Path != "new.txt" {
+		t.Errorf("Unexpected untracked entry: %+v", entries[2])
+	}
+	if entries[3].Path != "renamed.go" || entries[3].OrigPath != "old.go" {
+		t.Errorf("Unexpected rename entry: %+v", entries[3])
+	}
+}
+
+func TestParseNameStatus(t *testing.T) {
+	out := "M\x00internal/a.go\x00R087\x00old/b.go\x00new/b.go\x00D\x00gone.go\x00A\x00with space.go\x00"
+
+	changes := ParseNameStatus(out)
+
+	if len(changes) != 4 {
+		t.Fatalf("Expected 4 changes, got %+v", changes)
+	}
+	if changes[0].Status != 'M' || changes[0].Path != "internal/a.go" {
+		t.Errorf("Unexpected first change: %+v", changes[0])
+	}
+	if changes[1].Status != 'R' || changes[1].OrigPath != "old/b.go" || changes[1].Path != "new/b.go" {
+		t.Errorf("Unexpected rename: %+v", changes[1])
+	}
+	if changes[2].Status != 'D' || changes[3].Path != "with space.go" {
+		t.Errorf("Unexpected trailing changes: %+v", changes[2:])
+	}
+}
+
+func TestRepoCommands(t *testing.T) {
+	runner := NewFakeRunner().
+		On("rev-parse --abbrev-ref HEAD", "feature/x\n", nil).
+		On("write-tree", "abc123\n", nil)
+	repo := NewWithRunner("/repo", runner)
+
+	branch, err := repo.CurrentBranch()
+	if err != nil || branch != "feature/x" {
+		t.Errorf("Expected feature/x, got %q (%v)", branch, err)
+	}
+	if tree, _ := repo.WriteTree(); tree != "abc123" {
+		t.Errorf("Expected trimmed tree hash, got %q", tree)
+	}
+
+	repo.Diff("--cached")
+	repo.AddAll()
+	repo.Commit("msg")
+	repo.Push("origin", "feature/x", "--force-with-lease")
+	repo.WorktreeAdd("/wt", "feature/y", true, "origin/main")
+	repo.WorktreeRemove("/wt", true)
+	repo.Tag("boatman/run/iter-1", "def456")
+	repo.AddNote("boatman", "HEAD", "note")
+
+	want := []string{
+		"rev-parse --abbrev-ref HEAD",
+		"write-tree",
+		"diff --submodule=diff --cached",
+		"add -A",
+		"commit -m msg",
+		"push -u --force-with-lease origin feature/x",
+		"worktree add -b feature/y /wt origin/main",
+		"worktree remove /wt --force",
+		"tag -f boatman/run/iter-1 def456",
+		"notes --ref boatman add -f -m note HEAD",
+	}
+	calls := runner.Calls()
+	if strings.Join(calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected calls:\n%s", strings.Join(calls, "\n"))
+	}
+}
+
+func TestSubmodulePaths(t *testing.T) {
+	runner := NewFakeRunner().
+		On(`config --file .gitmodules --get-regexp ^submodule\..*\.path$`,
+			"submodule.vendor/lib.path vendor/lib\nsubmodule.docs.path docs\n", nil)
+	repo := NewWithRunner("/repo", runner)
+
+	paths, err := repo.SubmodulePaths()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(paths, ",") != "vendor/lib,docs" {
+		t.Errorf("Unexpected submodule paths: %v", paths)
+	}
+}
+
+func TestRebaseAbortsOnFailure(t *testing.T) {
+	runner := NewFakeRunner().On("rebase origin/main", "", errors.New("conflict"))
+	repo := NewWithRunner("/repo", runner)
+
+	if err := repo.Rebase("origin/main"); err == nil {
+		t.Fatal("Expected rebase error")
+	}
+	calls := runner.Calls()
+	if len(calls) != 2 || calls[1] != "rebase --abort" {
+		t.Errorf("Expected rebase --abort after failure, got %v", calls)
+	}
+}
+
+func TestRevert(t *testing.T) {
+	runner := NewFakeRunner().
+		On("rev-parse --verify --quiet abc^2", "", errors.New("not a merge")).
+		On("revert --no-edit -m 1 def", "", errors.New("conflict"))
+	repo := NewWithRunner("/repo", runner)
+
+	if err := repo.Revert("abc"); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Revert("def"); err == nil {
+		t.Fatal("Expected revert error")
+	}
+	want := []string{
+		"rev-parse --verify --quiet abc^2",
+		"revert --no-edit abc",
+		"rev-parse --verify --quiet def^2",
+		"revert --no-edit -m 1 def",
+		"revert --abort",
+	}
+	if calls := runner.Calls(); strings.Join(calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("Unexpected calls:\n%s", strings.Join(calls, "\n"))
+	}
+}
+
+func TestWorktreeList(t *testing.T) {
+	out := "worktree /repo\nHEAD aaa\nbranch refs/heads/main\n\nworktree /repo/.worktrees/x\nHEAD bbb\nbranch refs/heads/feature/x\n"
+	repo := NewWithRunner("/repo", NewFakeRunner().On("worktree list --porcelain", out, nil))
+
+	entries, err := repo.WorktreeList()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 worktrees, got %d", len(entries))
+	}
+	if entries[1].Path != "/repo/.worktrees/x" || entries[1].Branch != "feature/x" || entries[1].Head != "bbb" {
+		t.Errorf("Unexpected entry: %+v", entries[1])
+	}
+}
+
+func TestExecRunnerError(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir, err := os.MkdirTemp("", "gitops-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	_, err = New(dir).Run("rev-parse", "HEAD")
+
+	var gitErr *Error
+	if !errors.As(err, &gitErr) {
+		t.Fatalf("Expected *Error, got %T: %v", err, err)
+	}
+	if gitErr.Stderr == "" || !strings.Contains(err.Error(), "git rev-parse HEAD") {
+		t.Errorf("Expected stderr and command in error, got %q", err.Error())
+	}
+}
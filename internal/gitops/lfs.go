@@ -0,0 +1,130 @@
+package gitops
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// lfsPointerPrefix starts every Git LFS pointer file.
+const lfsPointerPrefix = "version https://git-lfs.github.com/spec/v1"
+
+// ParseLFSPatterns returns the patterns in .gitattributes content that are
+// tracked by Git LFS (filter=lfs).
+func ParseLFSPatterns(content string) []string {
+	var patterns []string
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		for _, attr := range fields[1:] {
+			if attr == "filter=lfs" {
+				patterns = append(patterns, fields[0])
+				break
+			}
+		}
+	}
+	return patterns
+}
+
+// LFSPatterns returns the LFS-tracked patterns from the root .gitattributes.
+func (r *Repo) LFSPatterns() []string {
+	content, err := os.ReadFile(filepath.Join(r.dir, ".gitattributes"))
+	if err != nil {
+		return nil
+	}
+	return ParseLFSPatterns(string(content))
+}
+
+// LFSTracked returns the subset of paths whose filter attribute is lfs.
+// Unlike LFSPatterns this honors nested .gitattributes files.
+func (r *Repo) LFSTracked(paths ...string) ([]string, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	out, err := r.Run(append([]string{"check-attr", "filter", "--"}, paths...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lines look like "path: filter: lfs"
+	var tracked []string
+	for _, line := range strings.Split(out, "\n") {
+		if path, ok := strings.CutSuffix(strings.TrimSpace(line), ": filter: lfs"); ok {
+			tracked = append(tracked, path)
+		}
+	}
+	return tracked, nil
+}
+
+// LFSAvailable reports whether the git-lfs extension is installed.
+func (r *Repo) LFSAvailable() bool {
+	_, err := r.Run("lfs", "version")
+	return err == nil
+}
+
+// LFSInstall configures the LFS filters for the repository and replaces
+// pointer files in the working tree with their content.
+func (r *Repo) LFSInstall() error {
+	if err := r.exec("lfs", "install", "--local"); err != nil {
+		return err
+	}
+	return r.exec("lfs", "pull")
+}
+
+// IsLFSPointer reports whether content is a Git LFS pointer file.
+func IsLFSPointer(content []byte) bool {
+	return bytes.HasPrefix(content, []byte(lfsPointerPrefix))
+}
+
+// IsBinary reports whether content looks binary (contains a NUL byte in
+// the first 8000 bytes, the same heuristic git uses).
+func IsBinary(content []byte) bool {
+	if len(content) > 8000 {
+		content = content[:8000]
+	}
+	return bytes.IndexByte(content, 0) >= 0
+}
+
+// FilterLFSDiff replaces the hunks of files whose diff is an LFS pointer
+// change with a short note, so pointer noise never reaches prompts.
+func FilterLFSDiff(diff string) string {
+	if !strings.Contains(diff, lfsPointerPrefix) {
+		return diff
+	}
+
+	var sb strings.Builder
+	for _, section := range splitDiffSections(diff) {
+		if !strings.Contains(section, lfsPointerPrefix) {
+			sb.WriteString(section)
+			continue
+		}
+		// Keep the header lines, drop the pointer hunks
+		for _, line := range strings.SplitAfter(section, "\n") {
+			if strings.HasPrefix(line, "@@") {
+				break
+			}
+			sb.WriteString(line)
+		}
+		sb.WriteString("Git LFS object changed (content omitted)\n")
+	}
+	return sb.String()
+}
+
+// splitDiffSections splits a unified diff into per-file sections.
+func splitDiffSections(diff string) []string {
+	var sections []string
+	start := 0
+	for start+1 < len(diff) {
+		idx := strings.Index(diff[start+1:], "\ndiff --git ")
+		if idx < 0 {
+			break
+		}
+		end := start + 1 + idx + 1
+		sections = append(sections, diff[start:end])
+		start = end
+	}
+	return append(sections, diff[start:])
+}
@@ -0,0 +1,80 @@
+package gitops
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseLFSPatterns(t *testing.T) {
+	content := "# Assets\n*.psd filter=lfs diff=lfs merge=lfs -text\n*.go text eol=lf\nvideos/** filter=lfs diff=lfs merge=lfs -text\n"
+
+	patterns := ParseLFSPatterns(content)
+
+	if len(patterns) != 2 || patterns[0] != "*.psd" || patterns[1] != "videos/**" {
+		t.Errorf("Unexpected patterns: %v", patterns)
+	}
+}
+
+func TestLFSTracked(t *testing.T) {
+	runner := NewFakeRunner().
+		On("check-attr filter -- a.psd main.go", "a.psd: filter: lfs\nmain.go: filter: unspecified\n", nil)
+	repo := NewWithRunner("/repo", runner)
+
+	tracked, err := repo.LFSTracked("a.psd", "main.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracked) != 1 || tracked[0] != "a.psd" {
+		t.Errorf("Expected [a.psd], got %v", tracked)
+	}
+}
+
+func TestIsLFSPointerAndBinary(t *testing.T) {
+	pointer := []byte("version https://git-lfs.github.com/spec/v1\noid sha256:abc\nsize 12\n")
+	if !IsLFSPointer(pointer) {
+		t.Error("Expected pointer to be detected")
+	}
+	if IsLFSPointer([]byte("package main\n")) {
+		t.Error("Expected source not to be a pointer")
+	}
+	if !IsBinary([]byte{0x89, 'P', 'N', 'G', 0x00, 0x01}) {
+		t.Error("Expected binary to be detected")
+	}
+	if IsBinary([]byte("plain text")) {
+		t.Error("Expected text not to be binary")
+	}
+}
+
+func TestFilterLFSDiff(t *testing.T) {
+	diff := `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -1 +1 @@
+-package old
++package main
+diff --git a/logo.psd b/logo.psd
+--- a/logo.psd
++++ b/logo.psd
+@@ -1,3 +1,3 @@
+ version https://git-lfs.github.com/spec/v1
+-oid sha256:aaa
++oid sha256:bbb
+ size 12
+`
+
+	filtered := FilterLFSDiff(diff)
+
+	if !strings.Contains(filtered, "+package main") {
+		t.Error("Expected regular file diff to be kept")
+	}
+	if strings.Contains(filtered, "oid sha256") {
+		t.Error("Expected LFS pointer hunks to be removed")
+	}
+	if !strings.Contains(filtered, "diff --git a/logo.psd") || !strings.Contains(filtered, "Git LFS object changed") {
+		t.Errorf("Expected LFS file header and note, got:\n%s", filtered)
+	}
+
+	if FilterLFSDiff("diff --git a/x b/x\n+hi\n") != "diff --git a/x b/x\n+hi\n" {
+		t.Error("Expected diff without pointers to be unchanged")
+	}
+}
@@ -0,0 +1,145 @@
+package gitops
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/retry"
+)
+
+// Divergence strategies for PushOptions.OnDiverged.
+const (
+	// DivergedRebase rebases local commits onto the remote branch.
+	DivergedRebase = "rebase"
+	// DivergedForceWithLease overwrites the remote branch if it hasn't moved since fetch.
+	DivergedForceWithLease = "force-with-lease"
+	// DivergedFail returns an error without pushing.
+	DivergedFail = "fail"
+)
+
+// PushOptions configures SafePush.
+type PushOptions struct {
+	Remote     string
+	Branch     string
+	OnDiverged string       // DivergedRebase (default), DivergedForceWithLease or DivergedFail
+	Retry      retry.Config // Backoff for transient network errors
+}
+
+// PushResult describes what SafePush did.
+type PushResult struct {
+	// Diverged is true if the remote branch had commits missing locally.
+	Diverged bool
+	// Action is how the push was performed: "push", "rebase", or "force-with-lease".
+	Action string
+}
+
+// ErrDiverged is returned when the remote branch diverged and could not be reconciled.
+var ErrDiverged = errors.New("remote branch has diverged")
+
+// SafePush pushes a branch, handling a pre-existing remote branch.
+// If the remote branch has commits not present locally (e.g. a rerun of the
+// same ticket), it either rebases onto it or force-pushes with a lease,
+// per opts.OnDiverged. Fetch and push are retried on transient network errors.
+func (r *Repo) SafePush(opts PushOptions) (*PushResult, error) {
+	if opts.Remote == "" {
+		opts.Remote = "origin"
+	}
+	if opts.OnDiverged == "" {
+		opts.OnDiverged = DivergedRebase
+	}
+	if opts.Retry.MaxAttempts == 0 {
+		opts.Retry = retry.CLIConfig()
+	}
+
+	result := &PushResult{Action: "push"}
+
+	exists, err := r.remoteBranchExists(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var flags []string
+	if exists {
+		remoteRef := fmt.Sprintf("refs/remotes/%s/%s", opts.Remote, opts.Branch)
+		if err := r.withRetry(opts, "git fetch", func() error {
+			return r.Fetch(opts.Remote, fmt.Sprintf("+refs/heads/%s:%s", opts.Branch, remoteRef))
+		}); err != nil {
+			return nil, err
+		}
+
+		_, behind, err := r.Divergence("HEAD", remoteRef)
+		if err != nil {
+			return nil, err
+		}
+
+		if behind > 0 {
+			result.Diverged = true
+			switch opts.OnDiverged {
+			case DivergedRebase:
+				if err := r.Rebase(remoteRef); err != nil {
+					return result, fmt.Errorf("%w: rebase onto %s failed (set git.on_diverged to %q to overwrite): %v",
+						ErrDiverged, remoteRef, DivergedForceWithLease, err)
+				}
+				result.Action = "rebase"
+			case DivergedForceWithLease:
+				remoteSHA, err := r.RevParse(remoteRef)
+				if err != nil {
+					return nil, err
+				}
+				flags = append(flags, fmt.Sprintf("--force-with-lease=%s:%s", opts.Branch, remoteSHA))
+				result.Action = "force-with-lease"
+			default:
+				return result, fmt.Errorf("%w: %s/%s is %d commit(s) ahead of local", ErrDiverged, opts.Remote, opts.Branch, behind)
+			}
+		}
+	}
+
+	if err := r.withRetry(opts, "git push", func() error {
+		return r.Push(opts.Remote, opts.Branch, flags...)
+	}); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// Divergence returns how many commits local is ahead of and behind upstream.
+func (r *Repo) Divergence(local, upstream string) (ahead, behind int, err error) {
+	out, err := r.Run("rev-list", "--left-right", "--count", local+"..."+upstream)
+	if err != nil {
+		return 0, 0, err
+	}
+	fields := strings.Fields(out)
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("unexpected rev-list output: %q", out)
+	}
+	ahead, _ = strconv.Atoi(fields[0])
+	behind, _ = strconv.Atoi(fields[1])
+	return ahead, behind, nil
+}
+
+// remoteBranchExists checks whether the branch exists on the remote.
+func (r *Repo) remoteBranchExists(opts PushOptions) (bool, error) {
+	var out string
+	err := r.withRetry(opts, "git ls-remote", func() error {
+		var err error
+		out, err = r.Run("ls-remote", "--heads", opts.Remote, "refs/heads/"+opts.Branch)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(out) != "", nil
+}
+
+// withRetry retries fn on transient network errors only.
+func (r *Repo) withRetry(opts PushOptions, operation string, fn func() error) error {
+	return retry.Do(r.ctx, opts.Retry, operation, func() error {
+		err := fn()
+		if err != nil && !IsTransient(err) {
+			return retry.Permanent(err)
+		}
+		return err
+	})
+}
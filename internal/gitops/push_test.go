@@ -0,0 +1,116 @@
+package gitops
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/retry"
+)
+
+var fastRetry = retry.Config{MaxAttempts: 3, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, Multiplier: 1}
+
+func TestSafePushNewBranch(t *testing.T) {
+	runner := NewFakeRunner()
+	repo := NewWithRunner("/repo", runner)
+
+	result, err := repo.SafePush(PushOptions{Branch: "feature/x", Retry: fastRetry})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Diverged || result.Action != "push" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	calls := runner.Calls()
+	if calls[len(calls)-1] != "push -u origin feature/x" {
+		t.Errorf("Expected plain push, got %v", calls)
+	}
+}
+
+func divergedRunner() *FakeRunner {
+	return NewFakeRunner().
+		On("ls-remote --heads origin refs/heads/feature/x", "abc\trefs/heads/feature/x\n", nil).
+		On("rev-list --left-right --count HEAD...refs/remotes/origin/feature/x", "1\t2\n", nil).
+		On("rev-parse refs/remotes/origin/feature/x", "abc\n", nil)
+}
+
+func TestSafePushDivergedRebase(t *testing.T) {
+	runner := divergedRunner()
+	repo := NewWithRunner("/repo", runner)
+
+	result, err := repo.SafePush(PushOptions{Branch: "feature/x", Retry: fastRetry})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Diverged || result.Action != "rebase" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	joined := strings.Join(runner.Calls(), "\n")
+	if !strings.Contains(joined, "rebase refs/remotes/origin/feature/x") {
+		t.Errorf("Expected rebase, got:\n%s", joined)
+	}
+}
+
+func TestSafePushDivergedForceWithLease(t *testing.T) {
+	runner := divergedRunner()
+	repo := NewWithRunner("/repo", runner)
+
+	result, err := repo.SafePush(PushOptions{Branch: "feature/x", OnDiverged: DivergedForceWithLease, Retry: fastRetry})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Action != "force-with-lease" {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	calls := runner.Calls()
+	if calls[len(calls)-1] != "push -u --force-with-lease=feature/x:abc origin feature/x" {
+		t.Errorf("Expected leased force push, got %v", calls[len(calls)-1])
+	}
+}
+
+func TestSafePushDivergedFail(t *testing.T) {
+	repo := NewWithRunner("/repo", divergedRunner())
+
+	_, err := repo.SafePush(PushOptions{Branch: "feature/x", OnDiverged: DivergedFail, Retry: fastRetry})
+	if !errors.Is(err, ErrDiverged) {
+		t.Errorf("Expected ErrDiverged, got %v", err)
+	}
+}
+
+func TestSafePushRetriesTransient(t *testing.T) {
+	transient := &Error{Args: []string{"push"}, Stderr: "fatal: unable to access 'https://github.com/x': Could not resolve host", Err: errors.New("exit status 128")}
+	runner := NewFakeRunner().On("push -u origin feature/x", "", transient)
+	repo := NewWithRunner("/repo", runner)
+
+	if _, err := repo.SafePush(PushOptions{Branch: "feature/x", Retry: fastRetry}); err == nil {
+		t.Fatal("Expected error")
+	}
+	pushes := 0
+	for _, c := range runner.Calls() {
+		if strings.HasPrefix(c, "push") {
+			pushes++
+		}
+	}
+	if pushes != 3 {
+		t.Errorf("Expected 3 push attempts, got %d", pushes)
+	}
+}
+
+func TestSafePushNoRetryOnRejection(t *testing.T) {
+	rejected := &Error{Args: []string{"push"}, Stderr: "! [rejected] feature/x -> feature/x (non-fast-forward)", Err: errors.New("exit status 1")}
+	runner := NewFakeRunner().On("push -u origin feature/x", "", rejected)
+	repo := NewWithRunner("/repo", runner)
+
+	repo.SafePush(PushOptions{Branch: "feature/x", Retry: fastRetry})
+
+	pushes := 0
+	for _, c := range runner.Calls() {
+		if strings.HasPrefix(c, "push") {
+			pushes++
+		}
+	}
+	if pushes != 1 {
+		t.Errorf("Expected 1 push attempt, got %d", pushes)
+	}
+}
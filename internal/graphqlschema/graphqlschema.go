@@ -0,0 +1,240 @@
+// Package graphqlschema classifies the changes a diff makes to GraphQL
+// schema files with graphql-inspector. Breaking changes are critical
+// review issues unless the ticket allows them; every change is listed in
+// the PR.
+package graphqlschema
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/cmdoutput"
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/gitops"
+	"github.com/philjestin/boatmanmode/internal/scottbott"
+	"github.com/philjestin/boatmanmode/internal/unidiff"
+)
+
+// defaultPaths match GraphQL SDL files.
+var defaultPaths = []string{"*.graphql", "*.graphqls"}
+
+// Level is how a schema change affects clients.
+type Level string
+
+const (
+	Breaking  Level = "breaking"  // Breaks clients, e.g. a removed field
+	Dangerous Level = "dangerous" // May break clients, e.g. a new enum value
+	Safe      Level = "safe"
+)
+
+// Change is a change to a schema file.
+type Change struct {
+	File    string
+	Level   Level
+	Message string
+}
+
+// Report lists a diff's schema changes.
+type Report struct {
+	Changes []Change
+
+	// Allowed is set when the ticket carries the label allowing breaking
+	// changes.
+	Allowed    bool
+	AllowLabel string
+}
+
+// Checker diffs a worktree's schema files against the base commit.
+type Checker struct {
+	worktreePath string
+	cfg          config.GraphQLConfig
+}
+
+// New creates a Checker. Returns nil when schema gating is disabled.
+func New(worktreePath string, cfg config.GraphQLConfig) *Checker {
+	if !cfg.Enabled || cfg.Inspector == "" {
+		return nil
+	}
+	if len(cfg.Paths) == 0 {
+		cfg.Paths = defaultPaths
+	}
+	return &Checker{worktreePath: worktreePath, cfg: cfg}
+}
+
+// Schemas returns the schema files the diff modifies or renames. New
+// schema files cannot break clients, and removed ones are left to review.
+func (c *Checker) Schemas(diff string) []*unidiff.File {
+	var files []*unidiff.File
+	for _, f := range unidiff.Parse(diff) {
+		if f.Status == unidiff.Added || f.Status == unidiff.Deleted {
+			continue
+		}
+		for _, pattern := range c.cfg.Paths {
+			if config.MatchPathPattern(pattern, f.Path()) {
+				files = append(files, f)
+				break
+			}
+		}
+	}
+	return files
+}
+
+// Check diffs each schema file the diff changes with its version at
+// baseCommit. Errors mean graphql-inspector could not run. A nil report
+// means no schema file changed.
+func (c *Checker) Check(ctx context.Context, baseCommit, diff string) (*Report, error) {
+	schemas := c.Schemas(diff)
+	if len(schemas) == 0 {
+		return nil, nil
+	}
+	tmp, err := os.MkdirTemp("", "boatman-graphql-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+
+	report := &Report{AllowLabel: c.cfg.AllowLabel}
+	for i, f := range schemas {
+		oldPath := f.OldPath
+		if oldPath == "" {
+			oldPath = f.Path()
+		}
+		old, err := gitops.New(c.worktreePath).WithContext(ctx).Run("show", baseCommit+":"+oldPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s at %s: %w", oldPath, baseCommit, err)
+		}
+		oldFile := filepath.Join(tmp, fmt.Sprintf("%d-%s", i, filepath.Base(oldPath)))
+		if err := os.WriteFile(oldFile, []byte(old), 0644); err != nil {
+			return nil, err
+		}
+		changes, err := c.diff(ctx, oldFile, f.Path())
+		if err != nil {
+			return nil, err
+		}
+		report.Changes = append(report.Changes, changes...)
+	}
+	return report, nil
+}
+
+// diff runs graphql-inspector diff on the two versions of file.
+func (c *Checker) diff(ctx context.Context, oldFile, file string) ([]Change, error) {
+	command := fmt.Sprintf("%s diff %s %s", c.cfg.Inspector, shellQuote(oldFile), shellQuote(file))
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = c.worktreePath
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+
+	output := ansi.ReplaceAllString(out.String(), "")
+	changes := parseChanges(output, file)
+	if err == nil || len(changes) > 0 || strings.Contains(output, "No changes detected") {
+		return changes, nil
+	}
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		return nil, fmt.Errorf("graphql-inspector failed on %s: %s", file, cmdoutput.Tail(output, 10))
+	}
+	return nil, fmt.Errorf("graphql-inspector could not run: %w", err)
+}
+
+// ansi matches terminal color codes.
+var ansi = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// parseChanges reads graphql-inspector's change lines, which start with ✖
+// for breaking, ⚠ for dangerous and ✔ for safe changes.
+func parseChanges(output, file string) []Change {
+	var changes []Change
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "[log]"))
+		var level Level
+		switch {
+		case strings.HasPrefix(line, "✖"):
+			level = Breaking
+		case strings.HasPrefix(line, "⚠"):
+			level = Dangerous
+		case strings.HasPrefix(line, "✔"):
+			level = Safe
+		default:
+			continue
+		}
+		_, message, _ := strings.Cut(line, " ")
+		message = strings.TrimSpace(strings.TrimPrefix(message, "\uFE0F"))
+		if message == "" {
+			continue
+		}
+		changes = append(changes, Change{File: file, Level: level, Message: message})
+	}
+	return changes
+}
+
+// Breaking returns the breaking changes.
+func (r *Report) Breaking() []Change {
+	var breaking []Change
+	for _, c := range r.Changes {
+		if c.Level == Breaking {
+			breaking = append(breaking, c)
+		}
+	}
+	return breaking
+}
+
+// Issues returns a critical issue per breaking change, unless the ticket
+// allows them.
+func (r *Report) Issues() []scottbott.Issue {
+	if r.Allowed {
+		return nil
+	}
+	var issues []scottbott.Issue
+	for _, c := range r.Breaking() {
+		issues = append(issues, scottbott.Issue{
+			Severity:    "critical",
+			File:        c.File,
+			Description: "Breaking GraphQL schema change: " + c.Message,
+			Suggestion: fmt.Sprintf("Make the change additive (deprecate instead of removing or retyping), "+
+				"or label the ticket %q if clients are ready for it", r.AllowLabel),
+		})
+	}
+	return issues
+}
+
+// Markdown lists the schema changes for the PR body, breaking ones first.
+// Empty when the schema did not change.
+func (r *Report) Markdown() string {
+	if len(r.Changes) == 0 {
+		return ""
+	}
+	counts := map[Level]int{}
+	for _, c := range r.Changes {
+		counts[c.Level]++
+	}
+	var sb strings.Builder
+	sb.WriteString("### 🧬 GraphQL Schema Changes\n")
+	sb.WriteString(fmt.Sprintf("%d breaking, %d dangerous, %d safe", counts[Breaking], counts[Dangerous], counts[Safe]))
+	if r.Allowed && counts[Breaking] > 0 {
+		sb.WriteString(fmt.Sprintf(" (breaking changes allowed by the `%s` label)", r.AllowLabel))
+	}
+	sb.WriteString("\n\n")
+	icons := map[Level]string{Breaking: "✖", Dangerous: "⚠️", Safe: "✔"}
+	for _, level := range []Level{Breaking, Dangerous, Safe} {
+		for _, c := range r.Changes {
+			if c.Level == level {
+				sb.WriteString(fmt.Sprintf("- %s `%s`: %s\n", icons[level], c.File, c.Message))
+			}
+		}
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// shellQuote quotes s for sh.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,124 @@
+package graphqlschema
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/gitops"
+)
+
+const inspectorOutput = "[log] Detected the following changes (3) between schemas:\n\n" +
+	"[log] \x1b[31m✖\x1b[39m  Field name was removed from object type User\n" +
+	"[log] \x1b[33m⚠\x1b[39m  Enum value ADMIN was added to enum Role\n" +
+	"[log] \x1b[32m✔\x1b[39m  Field email was added to object type User\n" +
+	"[error] Detected 1 breaking change\n"
+
+const diff = `diff --git a/schema.graphql b/schema.graphql
+--- a/schema.graphql
++++ b/schema.graphql
+@@ -1,3 +1,3 @@
+ type User {
+-  name: String
++  email: String
+ }
+diff --git a/api/new.graphql b/api/new.graphql
+new file mode 100644
+--- /dev/null
++++ b/api/new.graphql
+@@ -0,0 +1 @@
++type Order { id: ID }
+`
+
+func TestParseChanges(t *testing.T) {
+	changes := parseChanges(ansi.ReplaceAllString(inspectorOutput, ""), "schema.graphql")
+	if len(changes) != 3 {
+		t.Fatalf("changes = %+v", changes)
+	}
+	if changes[0].Level != Breaking || changes[0].Message != "Field name was removed from object type User" {
+		t.Errorf("changes[0] = %+v", changes[0])
+	}
+	if changes[1].Level != Dangerous || changes[2].Level != Safe {
+		t.Errorf("changes = %+v", changes)
+	}
+}
+
+func TestReport(t *testing.T) {
+	r := &Report{AllowLabel: "breaking-change", Changes: []Change{
+		{File: "schema.graphql", Level: Safe, Message: "Field email was added to object type User"},
+		{File: "schema.graphql", Level: Breaking, Message: "Field name was removed from object type User"},
+	}}
+	issues := r.Issues()
+	if len(issues) != 1 || issues[0].Severity != "critical" || !strings.Contains(issues[0].Suggestion, `"breaking-change"`) {
+		t.Errorf("issues = %+v", issues)
+	}
+	md := r.Markdown()
+	if !strings.Contains(md, "1 breaking, 0 dangerous, 1 safe\n") || strings.Index(md, "removed") > strings.Index(md, "added") {
+		t.Errorf("markdown = %q", md)
+	}
+
+	r.Allowed = true
+	if issues := r.Issues(); len(issues) != 0 {
+		t.Errorf("allowed issues = %+v", issues)
+	}
+	if md := r.Markdown(); !strings.Contains(md, "allowed by the `breaking-change` label") {
+		t.Errorf("allowed markdown = %q", md)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=t", "-c", "user.email=t@t"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	os.WriteFile(filepath.Join(dir, "schema.graphql"), []byte("type User {\n  name: String\n}\n"), 0644)
+	git("add", ".")
+	git("commit", "-qm", "schema")
+	os.WriteFile(filepath.Join(dir, "schema.graphql"), []byte("type User {\n  email: String\n}\n"), 0644)
+
+	// The fake inspector checks it was given the old schema
+	bin := t.TempDir()
+	os.WriteFile(filepath.Join(bin, "output"), []byte(inspectorOutput), 0644)
+	script := "#!/bin/sh\ngrep -q name \"$2\" || exit 2\ncat " + filepath.Join(bin, "output") + "\nexit 1\n"
+	os.WriteFile(filepath.Join(bin, "inspector"), []byte(script), 0755)
+
+	if c := New(dir, config.GraphQLConfig{Inspector: "inspector"}); c != nil {
+		t.Errorf("New when disabled = %v", c)
+	}
+	c := New(dir, config.GraphQLConfig{Enabled: true, Inspector: filepath.Join(bin, "inspector"), AllowLabel: "breaking-change"})
+	if schemas := c.Schemas(diff); len(schemas) != 1 || schemas[0].Path() != "schema.graphql" {
+		t.Errorf("Schemas = %v", schemas)
+	}
+	report, err := c.Check(context.Background(), "HEAD", diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Changes) != 3 || len(report.Breaking()) != 1 || report.Changes[0].File != "schema.graphql" {
+		t.Errorf("report = %+v", report)
+	}
+
+	// An inspector that fails without reporting changes cannot run
+	os.WriteFile(filepath.Join(bin, "inspector"), []byte("#!/bin/sh\necho 'npm ERR! 404'\nexit 1\n"), 0755)
+	if _, err := c.Check(context.Background(), "HEAD", diff); err == nil || !strings.Contains(err.Error(), "npm ERR! 404") {
+		t.Errorf("err = %v", err)
+	}
+
+	// A base commit without the schema is a git error, with git's stderr
+	var gitErr *gitops.Error
+	if _, err := c.Check(context.Background(), "no-such-commit", diff); !errors.As(err, &gitErr) || !strings.Contains(err.Error(), "fatal:") {
+		t.Errorf("err = %v, want a *gitops.Error", err)
+	}
+}
@@ -418,3 +418,14 @@ func CompressHandoff(h Handoff, maxTokens int) string {
 
 	return compressor.Compress(blocks)
 }
+
+// CompressText compresses a document, such as a linked spec or page, to
+// its key points when it is over maxTokens.
+func CompressText(text string, maxTokens int) string {
+	if EstimateTokens(text) <= maxTokens {
+		return text
+	}
+	compressor := NewDynamicCompressor(maxTokens)
+	compressor.MinTokens = 0
+	return compressor.Compress([]ContentBlock{{Type: "requirements", Content: text}})
+}
@@ -49,6 +49,9 @@ func EstimateTokens(s string) int {
 // TruncateToTokens truncates a string to fit within a token budget.
 func TruncateToTokens(s string, maxTokens int) string {
 	maxChars := maxTokens * 4
+	if maxChars < 0 {
+		maxChars = 0
+	}
 	if len(s) <= maxChars {
 		return s
 	}
@@ -134,8 +137,31 @@ type ReviewHandoff struct {
 	Requirements string // Concise summary of what was requested
 	Diff         string // The actual code changes
 	FilesChanged []string
+	// Renames maps the new path of each moved file to its old path.
+	Renames map[string]string
+
+	// FocusIssues are previously raised issues not yet verified as fixed.
+	// When set, the reviewer is asked to concentrate on these.
+	FocusIssues []string
+	// ResolvedIssues are previously raised issues verified as fixed.
+	ResolvedIssues []string
+
+	// Differential indicates Diff contains only changes since the previous
+	// review rather than the cumulative diff.
+	Differential bool
+	// PreviousIssues is the reviewer's issue list from its previous review.
+	PreviousIssues []string
+
+	// HumanEdited lists files the operator edited by hand (pair mode).
+	HumanEdited []string
+
+	// TestResults is the outcome of the latest test run, if available.
+	TestResults Handoff
 }
 
+// testResultTokens caps the test results section of a review handoff.
+const testResultTokens = 1000
+
 // NewReviewHandoff creates a handoff for code review.
 func NewReviewHandoff(t task.Task, diff string, filesChanged []string) *ReviewHandoff {
 	return &ReviewHandoff{
@@ -152,17 +178,74 @@ func NewReviewHandoffFromTicket(ticket *linear.Ticket, diff string, filesChanged
 	return NewReviewHandoff(task.NewLinearTask(ticket), diff, filesChanged)
 }
 
+// writeFocus writes the previous-iteration issue sections, if any.
+func (h *ReviewHandoff) writeFocus(sb *strings.Builder) {
+	if h.Differential {
+		sb.WriteString("\n\n## Previous Review\n\n")
+		sb.WriteString("This is a follow-up review. The diff below shows only what changed since your previous review.\n")
+		sb.WriteString("Check whether your previous issues were resolved and whether the new changes introduce problems.\n")
+		if len(h.PreviousIssues) > 0 {
+			sb.WriteString("\nYour previous issues:\n")
+			for _, issue := range h.PreviousIssues {
+				sb.WriteString(fmt.Sprintf("- %s\n", issue))
+			}
+		}
+	}
+	if len(h.FocusIssues) > 0 {
+		sb.WriteString("\n\n## Focus: Unaddressed Issues From Previous Review\n\n")
+		sb.WriteString("Verify whether each of these is now fixed. Concentrate your review here.\n\n")
+		for _, issue := range h.FocusIssues {
+			sb.WriteString(fmt.Sprintf("- %s\n", issue))
+		}
+	}
+	if len(h.ResolvedIssues) > 0 {
+		sb.WriteString("\n\n## Already Verified As Fixed\n\n")
+		sb.WriteString("Do not re-raise these unless the fix introduced a regression.\n\n")
+		for _, issue := range h.ResolvedIssues {
+			sb.WriteString(fmt.Sprintf("- %s\n", issue))
+		}
+	}
+	if len(h.HumanEdited) > 0 {
+		sb.WriteString("\n\n## Edited By Hand\n\n")
+		sb.WriteString("A human edited these files directly. Review their edits as part of the change.\n\n")
+		for _, f := range h.HumanEdited {
+			sb.WriteString(fmt.Sprintf("- %s\n", f))
+		}
+	}
+}
+
+// writeFiles lists the changed files, noting where moved files came from.
+func (h *ReviewHandoff) writeFiles(sb *strings.Builder) {
+	for _, f := range h.FilesChanged {
+		if from, ok := h.Renames[f]; ok {
+			sb.WriteString(fmt.Sprintf("- %s (moved from %s)\n", f, from))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("- %s\n", f))
+	}
+}
+
+// writeTests writes the latest test results, if any.
+func (h *ReviewHandoff) writeTests(sb *strings.Builder, maxTokens int) {
+	if h.TestResults == nil {
+		return
+	}
+	sb.WriteString("\n\n")
+	sb.WriteString(strings.TrimSpace(h.TestResults.ForTokenBudget(maxTokens)))
+	sb.WriteString("\n\nFailing tests are blocking: do not pass the review while tests fail.\n")
+}
+
 // Full returns the complete review context.
 func (h *ReviewHandoff) Full() string {
 	var sb strings.Builder
 	sb.WriteString(fmt.Sprintf("# Review: %s (%s)\n\n", h.Title, h.TicketID))
 	sb.WriteString("## Requirements Summary\n\n")
 	sb.WriteString(h.Requirements)
+	h.writeFocus(&sb)
+	h.writeTests(&sb, testResultTokens)
 	sb.WriteString("\n\n## Files Changed\n\n")
-	for _, f := range h.FilesChanged {
-		sb.WriteString(fmt.Sprintf("- %s\n", f))
-	}
-	sb.WriteString("\n## Diff\n\n```diff\n")
+	h.writeFiles(&sb)
+	sb.WriteString(fmt.Sprintf("\n## %s\n\n```diff\n", h.diffHeading()))
 	sb.WriteString(h.Diff)
 	sb.WriteString("\n```\n")
 	return sb.String()
@@ -174,11 +257,11 @@ func (h *ReviewHandoff) Concise() string {
 	sb.WriteString(fmt.Sprintf("# Review: %s (%s)\n\n", h.Title, h.TicketID))
 	sb.WriteString("## Requirements\n\n")
 	sb.WriteString(h.Requirements)
+	h.writeFocus(&sb)
+	h.writeTests(&sb, testResultTokens)
 	sb.WriteString(fmt.Sprintf("\n\n## Changes: %d files, %d lines\n", 
 		len(h.FilesChanged), strings.Count(h.Diff, "\n")))
-	for _, f := range h.FilesChanged {
-		sb.WriteString(fmt.Sprintf("- %s\n", f))
-	}
+	h.writeFiles(&sb)
 	return sb.String()
 }
 
@@ -194,22 +277,30 @@ func (h *ReviewHandoff) ForTokenBudget(maxTokens int) string {
 	sb.WriteString(fmt.Sprintf("# Review: %s (%s)\n\n", h.Title, h.TicketID))
 	sb.WriteString("## Requirements Summary\n\n")
 	sb.WriteString(h.Requirements)
+	h.writeFocus(&sb)
+	h.writeTests(&sb, testResultTokens)
 	sb.WriteString("\n\n## Files Changed\n\n")
-	for _, f := range h.FilesChanged {
-		sb.WriteString(fmt.Sprintf("- %s\n", f))
-	}
+	h.writeFiles(&sb)
 	
 	// Calculate remaining budget for diff
 	headerTokens := EstimateTokens(sb.String())
 	diffBudget := maxTokens - headerTokens - 100 // Reserve 100 for formatting
 	
-	sb.WriteString("\n## Diff (truncated)\n\n```diff\n")
+	sb.WriteString(fmt.Sprintf("\n## %s (truncated)\n\n```diff\n", h.diffHeading()))
 	sb.WriteString(TruncateToTokens(h.Diff, diffBudget))
 	sb.WriteString("\n```\n")
 	
 	return sb.String()
 }
 
+// diffHeading returns the section heading for the diff.
+func (h *ReviewHandoff) diffHeading() string {
+	if h.Differential {
+		return "Changes Since Previous Review"
+	}
+	return "Diff"
+}
+
 // Type returns the handoff type.
 func (h *ReviewHandoff) Type() string {
 	return "review"
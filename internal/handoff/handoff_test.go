@@ -0,0 +1,115 @@
+package handoff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReviewHandoffFocusIssues(t *testing.T) {
+	h := &ReviewHandoff{
+		TicketID:     "ENG-1",
+		Title:        "Add feature",
+		Requirements: "Do the thing",
+		Diff:         "+new line",
+		FilesChanged: []string{"a.go"},
+	}
+
+	if strings.Contains(h.Full(), "Focus:") {
+		t.Error("Focus section should be omitted when no focus issues are set")
+	}
+
+	h.FocusIssues = []string{"[major] Missing error check (a.go:10)"}
+	h.ResolvedIssues = []string{"[minor] Typo in comment"}
+
+	for name, out := range map[string]string{
+		"Full":           h.Full(),
+		"Concise":        h.Concise(),
+		"ForTokenBudget": h.ForTokenBudget(10),
+	} {
+		if !strings.Contains(out, "Missing error check") {
+			t.Errorf("%s: expected focus issue in output", name)
+		}
+		if !strings.Contains(out, "Already Verified As Fixed") {
+			t.Errorf("%s: expected resolved section in output", name)
+		}
+	}
+}
+
+func TestReviewHandoffDifferential(t *testing.T) {
+	h := &ReviewHandoff{
+		TicketID:       "ENG-1",
+		Title:          "Add feature",
+		Diff:           "+fix",
+		Differential:   true,
+		PreviousIssues: []string{"[major] Missing nil check"},
+	}
+
+	full := h.Full()
+	if !strings.Contains(full, "## Changes Since Previous Review") {
+		t.Error("Expected differential diff heading")
+	}
+	if !strings.Contains(full, "Missing nil check") {
+		t.Error("Expected previous issues in output")
+	}
+
+	h.Differential = false
+	if strings.Contains(h.Full(), "Previous Review") {
+		t.Error("Non-differential handoff should not mention previous review")
+	}
+}
+
+// stubHandoff is a minimal Handoff for embedding in other handoffs.
+type stubHandoff struct{ full, concise string }
+
+func (h stubHandoff) Full() string                        { return h.full }
+func (h stubHandoff) Concise() string                     { return h.concise }
+func (h stubHandoff) ForTokenBudget(maxTokens int) string { return h.full }
+func (h stubHandoff) Type() string                        { return "stub" }
+
+func TestReviewHandoffRenames(t *testing.T) {
+	h := &ReviewHandoff{
+		TicketID:     "ENG-1",
+		Title:        "Move billing",
+		Diff:         "+package billing",
+		FilesChanged: []string{"billing/invoice.go", "main.go"},
+		Renames:      map[string]string{"billing/invoice.go": "invoice.go"},
+	}
+
+	for name, out := range map[string]string{
+		"Full":           h.Full(),
+		"Concise":        h.Concise(),
+		"ForTokenBudget": h.ForTokenBudget(10),
+	} {
+		if !strings.Contains(out, "- billing/invoice.go (moved from invoice.go)\n- main.go\n") {
+			t.Errorf("%s: expected the move in the file list:\n%s", name, out)
+		}
+	}
+}
+
+func TestReviewHandoffTestResults(t *testing.T) {
+	h := &ReviewHandoff{
+		TicketID:     "ENG-1",
+		Title:        "Add widget",
+		Requirements: "Add a widget",
+		Diff:         "+widget",
+		TestResults:  stubHandoff{full: "# Test Results\n\n❌ **TESTS FAILED**\n- TestWidget"},
+	}
+
+	for name, out := range map[string]string{
+		"Full":           h.Full(),
+		"Concise":        h.Concise(),
+		"ForTokenBudget": h.ForTokenBudget(50),
+	} {
+		if !strings.Contains(out, "TestWidget") {
+			t.Errorf("%s: expected test failures in review context:\n%s", name, out)
+		}
+		if !strings.Contains(out, "Failing tests are blocking") {
+			t.Errorf("%s: expected blocking-tests instruction", name)
+		}
+	}
+
+	h.TestResults = nil
+	if strings.Contains(h.Full(), "Test Results") {
+		t.Error("expected no test section without results")
+	}
+}
@@ -62,8 +62,15 @@ func DefaultDependencies() []Dependency {
 			Name:        "tmux",
 			Command:     "tmux",
 			Args:        []string{"-V"},
-			Required:    false, // Optional, only needed for large prompts
-			Description: "Terminal multiplexer for large prompts",
+			Required:    false, // Optional, not needed with claude.runner: direct
+			Description: "Terminal multiplexer for the tmux runner",
+		},
+		{
+			Name:        "git-lfs",
+			Command:     "git",
+			Args:        []string{"lfs", "version"},
+			Required:    false, // Optional, only needed for repos that track files with LFS
+			Description: "Git LFS for large file support",
 		},
 	}
 }
@@ -0,0 +1,177 @@
+// Package hooks runs the repo's own commands around workflow steps, such as
+// an autoformatter after execution or a scanner before the PR. Each hook
+// gets the run's task, plan and diff as JSON, and a failing hook either
+// fails its step or only warns.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/checkpoint"
+	"github.com/philjestin/boatmanmode/internal/cmdoutput"
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/planner"
+)
+
+// When a hook runs relative to its step.
+const (
+	Before = "before"
+	After  = "after"
+)
+
+// steps are the steps hooks can run around: each step the workflow runs
+// on its own.
+var steps = []checkpoint.Step{
+	checkpoint.StepFetchTicket, checkpoint.StepCreateWorktree, checkpoint.StepPlanning,
+	checkpoint.StepPlanReview, checkpoint.StepValidation, checkpoint.StepExecution,
+	checkpoint.StepLint, checkpoint.StepTesting, checkpoint.StepReview,
+	checkpoint.StepCommit, checkpoint.StepPush, checkpoint.StepCreatePR,
+}
+
+// Task is the ticket a run implements.
+type Task struct {
+	ID          string   `json:"id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Labels      []string `json:"labels,omitempty"`
+}
+
+// Context is what a hook is told about the run, as JSON in the file named
+// by BOATMAN_CONTEXT.
+type Context struct {
+	Step       string        `json:"step"`
+	When       string        `json:"when"`
+	Worktree   string        `json:"worktree,omitempty"`
+	Branch     string        `json:"branch,omitempty"`
+	BaseCommit string        `json:"base_commit,omitempty"`
+	Task       Task          `json:"task"`
+	Plan       *planner.Plan `json:"plan,omitempty"`
+	Diff       string        `json:"diff,omitempty"` // The change so far, against the base commit
+}
+
+// Runner runs the configured hooks.
+type Runner struct {
+	hooks []config.HookConfig
+	out   io.Writer // Progress output; stdout when nil
+}
+
+// New checks the configured hooks. Returns nil when there are none.
+func New(hooks []config.HookConfig) (*Runner, error) {
+	if len(hooks) == 0 {
+		return nil, nil
+	}
+	for i, h := range hooks {
+		if !slices.Contains(steps, checkpoint.Step(h.Step)) {
+			return nil, fmt.Errorf("hooks[%d]: unknown step %q", i, h.Step)
+		}
+		switch h.When {
+		case "":
+			hooks[i].When = After
+		case Before, After:
+		default:
+			return nil, fmt.Errorf("hooks[%d]: when must be before or after (got %q)", i, h.When)
+		}
+		switch h.OnFailure {
+		case "", "fail", "warn":
+		default:
+			return nil, fmt.Errorf("hooks[%d]: on_failure must be fail or warn (got %q)", i, h.OnFailure)
+		}
+		if strings.TrimSpace(h.Run) == "" {
+			return nil, fmt.Errorf("hooks[%d]: run is required", i)
+		}
+	}
+	return &Runner{hooks: hooks}, nil
+}
+
+// SetOutput sends the runner's progress output to w.
+func (r *Runner) SetOutput(w io.Writer) {
+	r.out = w
+}
+
+// output returns where progress output goes.
+func (r *Runner) output() io.Writer {
+	if r.out == nil {
+		return os.Stdout
+	}
+	return r.out
+}
+
+// Has reports whether any hook runs when around step, so callers can skip
+// building its context.
+func (r *Runner) Has(step checkpoint.Step, when string) bool {
+	for _, h := range r.hooks {
+		if h.Step == string(step) && h.When == when {
+			return true
+		}
+	}
+	return false
+}
+
+// Run runs the hooks for when around step in dir, in configured order. It
+// returns the first failure of a hook that fails its step; warning hooks
+// only print theirs.
+func (r *Runner) Run(ctx context.Context, step checkpoint.Step, when, dir string, hc Context) error {
+	if !r.Has(step, when) {
+		return nil
+	}
+	hc.Step, hc.When = string(step), when
+	data, err := json.MarshalIndent(hc, "", "  ")
+	if err != nil {
+		return err
+	}
+	file, err := os.CreateTemp("", "boatman-hook-*.json")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, h := range r.hooks {
+		if h.Step != string(step) || h.When != when {
+			continue
+		}
+		fmt.Fprintf(r.output(), "   🪝 %s %s hook: %s\n", strings.ToUpper(when[:1])+when[1:], step, h.Run)
+		cmd := exec.CommandContext(ctx, "sh", "-c", h.Run)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"BOATMAN_CONTEXT="+file.Name(),
+			"BOATMAN_STEP="+string(step),
+			"BOATMAN_WHEN="+when,
+			"BOATMAN_WORKTREE="+hc.Worktree,
+		)
+		var out bytes.Buffer
+		cmd.Stdout = &out
+		cmd.Stderr = &out
+		err := cmd.Run()
+		if err == nil {
+			continue
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		failure := fmt.Errorf("%s hook %q failed: %v", when, h.Run, err)
+		if output := cmdoutput.Tail(out.String(), 20); output != "" {
+			failure = fmt.Errorf("%s hook %q failed: %s", when, h.Run, output)
+		}
+		if h.OnFailure == "warn" {
+			fmt.Fprintf(r.output(), "   ⚠️  %v\n", failure)
+			continue
+		}
+		return failure
+	}
+	return nil
+}
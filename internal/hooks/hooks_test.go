@@ -0,0 +1,76 @@
+package hooks
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/checkpoint"
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/planner"
+)
+
+func TestNew(t *testing.T) {
+	if r, err := New(nil); r != nil || err != nil {
+		t.Errorf("New(nil) = %v, %v", r, err)
+	}
+	for _, h := range []config.HookConfig{
+		{Step: "deploy", Run: "true"},
+		{Step: "execution", When: "during", Run: "true"},
+		{Step: "execution", OnFailure: "ignore", Run: "true"},
+		{Step: "execution"},
+	} {
+		if _, err := New([]config.HookConfig{h}); err == nil {
+			t.Errorf("New(%+v) succeeded", h)
+		}
+	}
+	r, err := New([]config.HookConfig{{Step: "execution", Run: "true"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Has(checkpoint.StepExecution, After) || r.Has(checkpoint.StepExecution, Before) {
+		t.Errorf("hooks = %+v, want an after hook by default", r.hooks)
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	r, err := New([]config.HookConfig{
+		{Step: "execution", Run: `cp "$BOATMAN_CONTEXT" context.json && echo "$BOATMAN_STEP $BOATMAN_WHEN" > env`},
+		{Step: "execution", Run: "echo 'style offences'; exit 1", OnFailure: "warn"},
+		{Step: "create_pr", When: "before", Run: "echo 'leaked key in config.yml'; exit 1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hc := Context{
+		Worktree: dir,
+		Task:     Task{ID: "ENG-1", Title: "Add login"},
+		Plan:     &planner.Plan{Summary: "Add a login form"},
+		Diff:     "diff --git a/login.rb b/login.rb\n",
+	}
+
+	// A warning hook does not fail the step
+	if err := r.Run(context.Background(), checkpoint.StepExecution, After, dir, hc); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, "context.json"))
+	for _, want := range []string{`"step": "execution"`, `"id": "ENG-1"`, `"summary": "Add a login form"`, `"diff": "diff --git`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("context missing %s:\n%s", want, data)
+		}
+	}
+	if env, _ := os.ReadFile(filepath.Join(dir, "env")); string(env) != "execution after\n" {
+		t.Errorf("env = %q", env)
+	}
+
+	err = r.Run(context.Background(), checkpoint.StepCreatePR, Before, dir, hc)
+	if err == nil || !strings.Contains(err.Error(), "leaked key") {
+		t.Errorf("err = %v, want the failing hook's output", err)
+	}
+	if err := r.Run(context.Background(), checkpoint.StepCreatePR, After, dir, hc); err != nil {
+		t.Errorf("step without hooks = %v", err)
+	}
+}
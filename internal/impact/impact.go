@@ -0,0 +1,399 @@
+// Package impact builds a static "blast radius" report for a diff.
+// It finds the functions and types touched by a change and lists their
+// direct callers so human reviewers can see impact at a glance.
+package impact
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/unidiff"
+)
+
+// Symbol represents a function or type touched by the diff.
+type Symbol struct {
+	Name    string
+	Kind    string // func, method, type
+	File    string
+	Line    int
+	Callers []Caller
+}
+
+// Caller represents a direct reference to a changed symbol.
+type Caller struct {
+	File     string
+	Line     int
+	Function string // Enclosing function, if known
+}
+
+// Report is the result of impact analysis.
+type Report struct {
+	Symbols []Symbol
+}
+
+// Analyzer computes impact reports for a worktree.
+type Analyzer struct {
+	worktreePath string
+	// MaxSymbols limits how many changed symbols are reported
+	MaxSymbols int
+	// MaxCallers limits how many callers are listed per symbol
+	MaxCallers int
+}
+
+// New creates a new Analyzer with defaults.
+func New(worktreePath string) *Analyzer {
+	return &Analyzer{
+		worktreePath: worktreePath,
+		MaxSymbols:   20,
+		MaxCallers:   10,
+	}
+}
+
+// skipDirs are directories never scanned for callers.
+var skipDirs = map[string]bool{
+	".git":         true,
+	".worktrees":   true,
+	"node_modules": true,
+	"vendor":       true,
+	"dist":         true,
+	"build":        true,
+}
+
+// Analyze finds changed symbols in the diff and their direct callers.
+func (a *Analyzer) Analyze(diff string) (*Report, error) {
+	changed := changedLines(diff)
+	report := &Report{}
+
+	var files []string
+	for file := range changed {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		var symbols []Symbol
+		if strings.HasSuffix(file, ".go") {
+			symbols = a.goSymbols(file, changed[file])
+		} else {
+			symbols = a.textSymbols(file, changed[file])
+		}
+		for _, sym := range symbols {
+			if len(report.Symbols) >= a.MaxSymbols {
+				break
+			}
+			report.Symbols = append(report.Symbols, sym)
+		}
+	}
+
+	var goFiles []parsedFile
+	for i := range report.Symbols {
+		sym := &report.Symbols[i]
+		if strings.HasSuffix(sym.File, ".go") {
+			if goFiles == nil {
+				goFiles = a.parseGoFiles()
+			}
+			sym.Callers = a.goCallers(goFiles, sym)
+		} else {
+			sym.Callers = a.textCallers(sym)
+		}
+	}
+
+	return report, nil
+}
+
+// changedLines maps each file in the diff to the new-file line numbers it touches.
+// Removed lines are attributed to the line at which they were removed.
+// Deleted files are skipped: they have no symbols left to analyze.
+func changedLines(diff string) map[string][]int {
+	result := make(map[string][]int)
+	for _, f := range unidiff.Parse(diff) {
+		if f.NewPath == "" {
+			continue
+		}
+		for _, l := range f.Lines() {
+			if l.Kind != unidiff.LineContext {
+				result[f.NewPath] = append(result[f.NewPath], l.NewLine)
+			}
+		}
+	}
+	return result
+}
+
+// goSymbols uses the Go AST to find declarations overlapping changed lines.
+func (a *Analyzer) goSymbols(file string, lines []int) []Symbol {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, filepath.Join(a.worktreePath, file), nil, parser.SkipObjectResolution)
+	if err != nil {
+		return nil
+	}
+
+	touches := func(start, end token.Pos) bool {
+		s, e := fset.Position(start).Line, fset.Position(end).Line
+		for _, l := range lines {
+			if l >= s && l <= e {
+				return true
+			}
+		}
+		return false
+	}
+
+	var symbols []Symbol
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if !touches(d.Pos(), d.End()) {
+				continue
+			}
+			kind := "func"
+			if d.Recv != nil {
+				kind = "method"
+			}
+			symbols = append(symbols, Symbol{
+				Name: d.Name.Name,
+				Kind: kind,
+				File: file,
+				Line: fset.Position(d.Pos()).Line,
+			})
+		case *ast.GenDecl:
+			if d.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range d.Specs {
+				ts := spec.(*ast.TypeSpec)
+				if !touches(ts.Pos(), ts.End()) {
+					continue
+				}
+				symbols = append(symbols, Symbol{
+					Name: ts.Name.Name,
+					Kind: "type",
+					File: file,
+					Line: fset.Position(ts.Pos()).Line,
+				})
+			}
+		}
+	}
+
+	return symbols
+}
+
+// defPatterns match function/type definitions in non-Go languages.
+var defPatterns = []struct {
+	re   *regexp.Regexp
+	kind string
+}{
+	{regexp.MustCompile(`^\s*(?:async\s+)?def\s+(\w+)`), "func"},
+	{regexp.MustCompile(`^\s*(?:export\s+)?(?:default\s+)?(?:async\s+)?function\s*\*?\s+(\w+)`), "func"},
+	{regexp.MustCompile(`^\s*(?:export\s+)?(?:const|let)\s+(\w+)\s*=\s*(?:async\s+)?\([^)]*\)\s*=>`), "func"},
+	{regexp.MustCompile(`^\s*(?:export\s+)?(?:abstract\s+)?(?:class|interface|module)\s+(\w+)`), "type"},
+}
+
+// textSymbols finds the nearest enclosing definition for each changed line.
+func (a *Analyzer) textSymbols(file string, lines []int) []Symbol {
+	content, err := os.ReadFile(filepath.Join(a.worktreePath, file))
+	if err != nil {
+		return nil
+	}
+	src := strings.Split(string(content), "\n")
+
+	seen := make(map[string]bool)
+	var symbols []Symbol
+	for _, l := range lines {
+		for i := min(l, len(src)) - 1; i >= 0; i-- {
+			name, kind := matchDefinition(src[i])
+			if name == "" {
+				continue
+			}
+			if !seen[name] {
+				seen[name] = true
+				symbols = append(symbols, Symbol{Name: name, Kind: kind, File: file, Line: i + 1})
+			}
+			break
+		}
+	}
+
+	return symbols
+}
+
+// matchDefinition returns the symbol name and kind defined on a line, if any.
+func matchDefinition(line string) (string, string) {
+	for _, p := range defPatterns {
+		if m := p.re.FindStringSubmatch(line); m != nil {
+			return m[1], p.kind
+		}
+	}
+	return "", ""
+}
+
+// parsedFile is a parsed Go source file with its relative path.
+type parsedFile struct {
+	rel  string
+	fset *token.FileSet
+	file *ast.File
+}
+
+// parseGoFiles parses every Go file in the worktree.
+func (a *Analyzer) parseGoFiles() []parsedFile {
+	var files []parsedFile
+	filepath.WalkDir(a.worktreePath, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if skipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") {
+			return nil
+		}
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(a.worktreePath, path)
+		files = append(files, parsedFile{rel: rel, fset: fset, file: f})
+		return nil
+	})
+	return files
+}
+
+// goCallers finds call sites and type references for a Go symbol.
+func (a *Analyzer) goCallers(files []parsedFile, sym *Symbol) []Caller {
+	var callers []Caller
+
+	for _, pf := range files {
+		for _, decl := range pf.file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			if pf.rel == sym.File && fn.Name.Name == sym.Name {
+				continue // Skip the definition itself
+			}
+			ast.Inspect(fn.Body, func(n ast.Node) bool {
+				if len(callers) >= a.MaxCallers {
+					return false
+				}
+				var ident *ast.Ident
+				switch x := n.(type) {
+				case *ast.CallExpr:
+					if sym.Kind == "type" {
+						return true
+					}
+					switch fun := x.Fun.(type) {
+					case *ast.Ident:
+						ident = fun
+					case *ast.SelectorExpr:
+						ident = fun.Sel
+					}
+				case *ast.CompositeLit:
+					if sym.Kind != "type" {
+						return true
+					}
+					switch t := x.Type.(type) {
+					case *ast.Ident:
+						ident = t
+					case *ast.SelectorExpr:
+						ident = t.Sel
+					}
+				}
+				if ident != nil && ident.Name == sym.Name {
+					callers = append(callers, Caller{
+						File:     pf.rel,
+						Line:     pf.fset.Position(ident.Pos()).Line,
+						Function: fn.Name.Name,
+					})
+				}
+				return true
+			})
+		}
+		if len(callers) >= a.MaxCallers {
+			break
+		}
+	}
+
+	return callers
+}
+
+// textCallers searches same-language files for references to a symbol.
+func (a *Analyzer) textCallers(sym *Symbol) []Caller {
+	var callers []Caller
+	ext := filepath.Ext(sym.File)
+	pattern := regexp.MustCompile(`\b` + regexp.QuoteMeta(sym.Name) + `\b`)
+
+	filepath.WalkDir(a.worktreePath, func(path string, d os.DirEntry, err error) error {
+		if err != nil || len(callers) >= a.MaxCallers {
+			return nil
+		}
+		if d.IsDir() {
+			if skipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ext {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(a.worktreePath, path)
+		enclosing := ""
+		for i, line := range strings.Split(string(content), "\n") {
+			if name, _ := matchDefinition(line); name != "" {
+				enclosing = name
+				if name == sym.Name {
+					continue // Skip the definition itself
+				}
+			}
+			if pattern.MatchString(line) {
+				callers = append(callers, Caller{File: rel, Line: i + 1, Function: enclosing})
+				if len(callers) >= a.MaxCallers {
+					break
+				}
+			}
+		}
+		return nil
+	})
+
+	return callers
+}
+
+// Markdown renders the report as a PR body section.
+// Returns an empty string if no symbols were changed.
+func (r *Report) Markdown() string {
+	if r == nil || len(r.Symbols) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("### Impact\n")
+	for _, sym := range r.Symbols {
+		sb.WriteString(fmt.Sprintf("- `%s` (%s, %s:%d)", sym.Name, sym.Kind, sym.File, sym.Line))
+		if len(sym.Callers) == 0 {
+			sb.WriteString(" — no direct callers found\n")
+			continue
+		}
+		sb.WriteString(fmt.Sprintf(" — %d direct caller(s):\n", len(sym.Callers)))
+		for _, c := range sym.Callers {
+			location := fmt.Sprintf("%s:%d", c.File, c.Line)
+			if c.Function != "" {
+				sb.WriteString(fmt.Sprintf("  - `%s` (%s)\n", c.Function, location))
+			} else {
+				sb.WriteString(fmt.Sprintf("  - %s\n", location))
+			}
+		}
+	}
+
+	return sb.String()
+}
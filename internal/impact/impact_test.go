@@ -0,0 +1,189 @@
+package impact
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFile(t *testing.T, dir, rel, content string) {
+	t.Helper()
+	path := filepath.Join(dir, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestChangedLines(t *testing.T) {
+	diff := `diff --git a/a.go b/a.go
+--- a/a.go
++++ b/a.go
+@@ -1,4 +1,5 @@
+ package a
+
++// added
+ func A() {
+-	old()
++	new()
+ }
+`
+	lines := changedLines(diff)
+	got := lines["a.go"]
+	if len(got) != 3 {
+		t.Fatalf("Expected 3 changed lines, got %v", got)
+	}
+	if got[0] != 3 {
+		t.Errorf("Expected first changed line 3, got %d", got[0])
+	}
+}
+
+func TestAnalyzeGo(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "pkg/lib.go", `package pkg
+
+// Helper does things.
+func Helper() int {
+	return 2
+}
+
+type Widget struct {
+	Name string
+}
+`)
+	writeFile(t, dir, "main.go", `package main
+
+import "example/pkg"
+
+func main() {
+	_ = pkg.Helper()
+	_ = pkg.Widget{Name: "x"}
+}
+
+func other() {
+	pkg.Helper()
+}
+`)
+
+	diff := `--- a/pkg/lib.go
++++ b/pkg/lib.go
+@@ -4,3 +4,3 @@ func Helper() int {
+ func Helper() int {
+-	return 1
++	return 2
+ }
+`
+
+	report, err := New(dir).Analyze(diff)
+	if err != nil {
+		t.Fatalf("Analyze failed: %v", err)
+	}
+	if len(report.Symbols) != 1 {
+		t.Fatalf("Expected 1 symbol, got %d", len(report.Symbols))
+	}
+	sym := report.Symbols[0]
+	if sym.Name != "Helper" || sym.Kind != "func" {
+		t.Errorf("Unexpected symbol: %+v", sym)
+	}
+	if len(sym.Callers) != 2 {
+		t.Fatalf("Expected 2 callers, got %d", len(sym.Callers))
+	}
+	if sym.Callers[0].Function != "main" {
+		t.Errorf("Expected caller main, got %s", sym.Callers[0].Function)
+	}
+}
+
+func TestAnalyzeGoType(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "lib.go", `package lib
+
+type Widget struct {
+	Name string
+	Size int
+}
+
+func Build() Widget {
+	return Widget{Name: "w"}
+}
+`)
+
+	diff := `--- a/lib.go
++++ b/lib.go
+@@ -3,3 +3,4 @@
+ type Widget struct {
+ 	Name string
++	Size int
+ }
+`
+
+	report, _ := New(dir).Analyze(diff)
+	if len(report.Symbols) != 1 || report.Symbols[0].Kind != "type" {
+		t.Fatalf("Expected one type symbol, got %+v", report.Symbols)
+	}
+	if len(report.Symbols[0].Callers) != 1 || report.Symbols[0].Callers[0].Function != "Build" {
+		t.Errorf("Expected Build to reference Widget, got %+v", report.Symbols[0].Callers)
+	}
+}
+
+func TestAnalyzeText(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "lib.py", `def compute(x):
+    return x * 2
+`)
+	writeFile(t, dir, "app.py", `from lib import compute
+
+def run():
+    return compute(3)
+`)
+
+	diff := `--- a/lib.py
++++ b/lib.py
+@@ -1,2 +1,2 @@
+ def compute(x):
+-    return x
++    return x * 2
+`
+
+	report, _ := New(dir).Analyze(diff)
+	if len(report.Symbols) != 1 || report.Symbols[0].Name != "compute" {
+		t.Fatalf("Expected compute symbol, got %+v", report.Symbols)
+	}
+
+	var found bool
+	for _, c := range report.Symbols[0].Callers {
+		if c.File == "app.py" && c.Function == "run" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected run() in app.py as caller, got %+v", report.Symbols[0].Callers)
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	var empty *Report
+	if empty.Markdown() != "" {
+		t.Error("Nil report should render empty")
+	}
+
+	r := &Report{Symbols: []Symbol{
+		{Name: "Helper", Kind: "func", File: "lib.go", Line: 4, Callers: []Caller{
+			{File: "main.go", Line: 6, Function: "main"},
+		}},
+		{Name: "Unused", Kind: "func", File: "lib.go", Line: 10},
+	}}
+	md := r.Markdown()
+
+	if !strings.HasPrefix(md, "### Impact") {
+		t.Error("Expected Impact header")
+	}
+	if !strings.Contains(md, "`main` (main.go:6)") {
+		t.Error("Expected caller entry")
+	}
+	if !strings.Contains(md, "no direct callers found") {
+		t.Error("Expected no-callers note")
+	}
+}
@@ -0,0 +1,163 @@
+package impact
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ReferencingTests returns the test files that reference a function or
+// type changed in diff, in path order. isTest says which files are tests.
+// Tests are found by what they use rather than by their names, so they
+// are found even when test files don't mirror source files one to one.
+func (a *Analyzer) ReferencingTests(diff string, isTest func(path string) bool) []string {
+	changed := changedLines(diff)
+	var files []string
+	for file := range changed {
+		// Changed tests are selected already; their helpers would only add noise
+		if !isTest(file) {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+
+	var symbols []Symbol
+	for _, file := range files {
+		if strings.HasSuffix(file, ".go") {
+			symbols = append(symbols, a.goSymbols(file, changed[file])...)
+		} else {
+			symbols = append(symbols, a.textSymbols(file, changed[file])...)
+		}
+	}
+	if len(symbols) > a.MaxSymbols {
+		symbols = symbols[:a.MaxSymbols]
+	}
+	if len(symbols) == 0 {
+		return nil
+	}
+
+	patterns := textPatterns(symbols)
+	var tests []string
+	filepath.WalkDir(a.worktreePath, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if skipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, _ := filepath.Rel(a.worktreePath, path)
+		if !isTest(rel) {
+			return nil
+		}
+		var refs bool
+		if strings.HasSuffix(rel, ".go") {
+			refs = goReferences(path, rel, symbols)
+		} else {
+			refs = textReferences(path, patterns[language(rel)])
+		}
+		if refs {
+			tests = append(tests, rel)
+		}
+		return nil
+	})
+	return tests
+}
+
+// goReferences reports whether a Go test file uses any of the Go symbols:
+// by bare name from the symbol's own package directory, or qualified by
+// package name from a file importing that directory.
+func goReferences(path, rel string, symbols []Symbol) bool {
+	f, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.SkipObjectResolution)
+	if err != nil {
+		return false
+	}
+	idents := make(map[string]bool)
+	selectors := make(map[string]bool)
+	ast.Inspect(f, func(n ast.Node) bool {
+		switch x := n.(type) {
+		case *ast.SelectorExpr:
+			selectors[x.Sel.Name] = true
+		case *ast.Ident:
+			idents[x.Name] = true
+		}
+		return true
+	})
+
+	dir := filepath.ToSlash(filepath.Dir(rel))
+	for _, sym := range symbols {
+		if !strings.HasSuffix(sym.File, ".go") {
+			continue
+		}
+		symDir := filepath.ToSlash(filepath.Dir(sym.File))
+		if symDir == dir {
+			if idents[sym.Name] {
+				return true
+			}
+			continue
+		}
+		if selectors[sym.Name] && importsDir(f, symDir) {
+			return true
+		}
+	}
+	return false
+}
+
+// importsDir reports whether a file imports the package in dir, judged by
+// the import path's trailing directories since the module path is unknown.
+func importsDir(f *ast.File, dir string) bool {
+	if dir == "." {
+		return false
+	}
+	for _, imp := range f.Imports {
+		path, err := strconv.Unquote(imp.Path.Value)
+		if err == nil && (path == dir || strings.HasSuffix(path, "/"+dir)) {
+			return true
+		}
+	}
+	return false
+}
+
+// textPatterns returns, per language, a regex matching any of the non-Go
+// symbols as a whole word, so it is compiled once rather than per file.
+func textPatterns(symbols []Symbol) map[string]*regexp.Regexp {
+	names := make(map[string][]string)
+	for _, sym := range symbols {
+		if lang := language(sym.File); lang != ".go" {
+			names[lang] = append(names[lang], regexp.QuoteMeta(sym.Name))
+		}
+	}
+	patterns := make(map[string]*regexp.Regexp, len(names))
+	for lang, n := range names {
+		patterns[lang] = regexp.MustCompile(`\b(?:` + strings.Join(n, "|") + `)\b`)
+	}
+	return patterns
+}
+
+// textReferences reports whether a non-Go test file matches the pattern
+// of the symbols defined in its language.
+func textReferences(path string, pattern *regexp.Regexp) bool {
+	if pattern == nil {
+		return false
+	}
+	content, err := os.ReadFile(path)
+	return err == nil && pattern.Match(content)
+}
+
+// language groups file extensions whose files can use each other's code.
+func language(file string) string {
+	switch ext := filepath.Ext(file); ext {
+	case ".js", ".jsx", ".mjs", ".cjs", ".ts", ".tsx":
+		return "js"
+	default:
+		return ext
+	}
+}
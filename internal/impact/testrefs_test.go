@@ -0,0 +1,117 @@
+package impact
+
+import (
+	"strings"
+	"testing"
+)
+
+func isTest(path string) bool {
+	return strings.HasSuffix(path, "_test.go") || strings.Contains(path, ".test.")
+}
+
+func TestReferencingTestsGo(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "pkg/pricing/discount.go", `package pricing
+
+func Discount(total int) int {
+	return total / 10
+}
+
+func Tax(total int) int {
+	return total / 5
+}
+`)
+	// Named after neither file, in the same package
+	writeFile(t, dir, "pkg/pricing/checkout_test.go", `package pricing
+
+import "testing"
+
+func TestCheckout(t *testing.T) {
+	_ = Discount(100)
+}
+`)
+	writeFile(t, dir, "pkg/pricing/tax_test.go", `package pricing
+
+import "testing"
+
+func TestTax(t *testing.T) {
+	_ = Tax(100)
+}
+`)
+	// In another package, through the import
+	writeFile(t, dir, "internal/cart/cart_test.go", `package cart
+
+import (
+	"testing"
+
+	"example.com/shop/pkg/pricing"
+)
+
+func TestCart(t *testing.T) {
+	_ = pricing.Discount(5)
+}
+`)
+	// Another package's function of the same name doesn't count
+	writeFile(t, dir, "internal/promo/promo_test.go", `package promo
+
+import "testing"
+
+func TestPromo(t *testing.T) {
+	_ = Discount(5)
+}
+
+func Discount(n int) int { return n }
+`)
+
+	diff := `--- a/pkg/pricing/discount.go
++++ b/pkg/pricing/discount.go
+@@ -3,3 +3,3 @@
+ func Discount(total int) int {
+-	return total / 20
++	return total / 10
+ }
+`
+	got := New(dir).ReferencingTests(diff, isTest)
+	want := "internal/cart/cart_test.go pkg/pricing/checkout_test.go"
+	if strings.Join(got, " ") != want {
+		t.Errorf("Expected %s, got %v", want, got)
+	}
+}
+
+func TestReferencingTestsText(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "src/format.ts", `export function formatPrice(cents: number) {
+  return (cents / 100).toFixed(2)
+}
+`)
+	writeFile(t, dir, "tests/receipt.test.js", `import { formatPrice } from '../src/format'
+test('receipt', () => expect(formatPrice(100)).toBe('1.00'))
+`)
+	writeFile(t, dir, "tests/other.test.js", `test('other', () => {})
+`)
+
+	diff := `--- a/src/format.ts
++++ b/src/format.ts
+@@ -1,3 +1,3 @@
+ export function formatPrice(cents: number) {
+-  return cents / 100
++  return (cents / 100).toFixed(2)
+ }
+`
+	got := New(dir).ReferencingTests(diff, isTest)
+	if len(got) != 1 || got[0] != "tests/receipt.test.js" {
+		t.Errorf("Expected the test using formatPrice, got %v", got)
+	}
+
+	// Changes to tests themselves select nothing more
+	testDiff := `--- a/tests/receipt.test.js
++++ b/tests/receipt.test.js
+@@ -1,2 +1,2 @@
+ import { formatPrice } from '../src/format'
+-test('receipt', () => {})
++test('receipt', () => expect(formatPrice(100)).toBe('1.00'))
+`
+	if got := New(dir).ReferencingTests(testDiff, isTest); got != nil {
+		t.Errorf("Expected no tests for a test-only change, got %v", got)
+	}
+}
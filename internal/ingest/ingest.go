@@ -0,0 +1,251 @@
+// Package ingest turns the screenshots and web pages a ticket links to into
+// text for the planning and execution prompts: images are described by a
+// vision-capable model, and pages are reduced to their main content by a
+// readability extractor.
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/handoff"
+	"github.com/philjestin/boatmanmode/internal/retry"
+)
+
+// maxDownload caps the size of a fetched image or page.
+const maxDownload = 10 << 20
+
+// Image is an image embedded in a ticket description.
+type Image struct {
+	URL string
+	Alt string
+}
+
+// Page is a fetched web page, reduced to its main content.
+type Page struct {
+	URL   string
+	Title string
+	Text  string
+}
+
+var (
+	// ![Broken layout](https://example.com/shot.png)
+	markdownImage = regexp.MustCompile(`!\[([^\]]*)\]\((https?://[^\s)]+)\)`)
+	webLink       = regexp.MustCompile("https?://[^\\s()\\[\\]<>\"'`]+")
+)
+
+// imageExtensions are the image types the model can read, by content type.
+var imageExtensions = map[string]string{
+	"image/png":  ".png",
+	"image/jpeg": ".jpg",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// Images returns the images embedded in text as markdown, each once.
+func Images(text string) []Image {
+	var images []Image
+	seen := make(map[string]bool)
+	for _, m := range markdownImage.FindAllStringSubmatch(text, -1) {
+		if !seen[m[2]] {
+			seen[m[2]] = true
+			images = append(images, Image{URL: m[2], Alt: m[1]})
+		}
+	}
+	return images
+}
+
+// Links returns the links in text that are not embedded images, each once.
+func Links(text string) []string {
+	images := make(map[string]bool)
+	for _, img := range Images(text) {
+		images[img.URL] = true
+	}
+	var links []string
+	seen := make(map[string]bool)
+	for _, link := range webLink.FindAllString(text, -1) {
+		link = strings.TrimRight(link, ".,;:!?*_")
+		if images[link] || seen[link] {
+			continue
+		}
+		seen[link] = true
+		links = append(links, link)
+	}
+	return links
+}
+
+// ImageExtension returns the file extension for data if it is an image the
+// model can read, and "" otherwise.
+func ImageExtension(data []byte) string {
+	return imageExtensions[http.DetectContentType(data)]
+}
+
+// ErrPrivateAddress is returned for URLs that resolve to a loopback,
+// private, link-local or otherwise non-public address.
+var ErrPrivateAddress = errors.New("refusing to fetch a non-public address")
+
+// nonPublic are the ranges netip doesn't classify that are not reachable
+// on the internet: shared address space (carrier-grade NAT), IETF protocol
+// assignments, benchmarking, "this network" and NAT64.
+var nonPublic = []netip.Prefix{
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// Fetcher downloads images and pages. It sends no credentials and only
+// connects to public addresses, so links in ticket text cannot reach the
+// machine's own services, the local network or cloud metadata endpoints.
+type Fetcher struct {
+	httpClient *http.Client
+}
+
+// New creates a fetcher.
+func New() *Fetcher {
+	return newFetcher(publicOnly)
+}
+
+// newFetcher creates a fetcher whose connections are checked by control.
+// Proxies are not used, so control sees the real destination.
+func newFetcher(control func(network, address string, c syscall.RawConn) error) *Fetcher {
+	dialer := &net.Dialer{Timeout: 10 * time.Second, Control: control}
+	transport := &http.Transport{
+		DialContext:         dialer.DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+	}
+	return &Fetcher{httpClient: &http.Client{Timeout: 30 * time.Second, Transport: transport}}
+}
+
+// publicOnly refuses connections to non-public addresses. It runs after
+// DNS resolution on every connection, redirects included.
+func publicOnly(network, address string, _ syscall.RawConn) error {
+	addr, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrPrivateAddress, address)
+	}
+	if !publicAddr(addr.Addr()) {
+		return fmt.Errorf("%w (%s)", ErrPrivateAddress, addr.Addr())
+	}
+	return nil
+}
+
+// publicAddr reports whether addr is a public unicast address.
+func publicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublic {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// Download fetches rawURL, up to maxDownload bytes of it, and returns the
+// body with its media type.
+func (f *Fetcher) Download(ctx context.Context, rawURL string) ([]byte, string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") {
+		return nil, "", fmt.Errorf("not a web URL: %s", rawURL)
+	}
+
+	var body []byte
+	var mediaType string
+	err = retry.Do(ctx, retry.APIConfig(), "URL fetch", func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+		if err != nil {
+			return retry.Permanent(fmt.Errorf("failed to create request: %w", err))
+		}
+		req.Header.Set("User-Agent", "boatman")
+
+		resp, err := f.httpClient.Do(req)
+		if errors.Is(err, ErrPrivateAddress) {
+			return retry.Permanent(err)
+		}
+		if err != nil {
+			return fmt.Errorf("request failed: %w", err) // Retryable
+		}
+		defer resp.Body.Close()
+
+		data, err := io.ReadAll(io.LimitReader(resp.Body, maxDownload))
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+
+		// 429 is retryable; other 4xx errors are permanent (client errors)
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			return fmt.Errorf("status %d", resp.StatusCode)
+		}
+		if resp.StatusCode >= 400 {
+			return retry.Permanent(fmt.Errorf("status %d", resp.StatusCode))
+		}
+
+		body = data
+		mediaType, _, _ = mime.ParseMediaType(resp.Header.Get("Content-Type"))
+		if mediaType == "" {
+			mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(data))
+		}
+		return nil
+	})
+	return body, mediaType, err
+}
+
+// Page fetches the page at rawURL as text. HTML is reduced to its main
+// content; other types than HTML and plain text are refused.
+func (f *Fetcher) Page(ctx context.Context, rawURL string) (*Page, error) {
+	body, mediaType, err := f.Download(ctx, rawURL)
+	if err != nil {
+		return nil, err
+	}
+	switch mediaType {
+	case "text/html", "application/xhtml+xml":
+		title, text := Readable(string(body))
+		return &Page{URL: rawURL, Title: title, Text: text}, nil
+	case "text/plain", "text/markdown":
+		return &Page{URL: rawURL, Text: strings.TrimSpace(string(body))}, nil
+	}
+	return nil, fmt.Errorf("unsupported content type %q", mediaType)
+}
+
+// Render writes the screenshot descriptions and pages for a prompt, with
+// the pages sharing maxTokens. Pages over their share are compressed to
+// their key points.
+func Render(screenshots string, pages []*Page, maxTokens int) string {
+	if screenshots == "" && len(pages) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("## Ticket Media\n")
+	sb.WriteString("The ticket includes these screenshots and links, transcribed as text.\n")
+	if screenshots != "" {
+		sb.WriteString("\n### Screenshots\n\n")
+		sb.WriteString(strings.TrimSpace(screenshots) + "\n")
+	}
+	if len(pages) > 0 {
+		share := maxTokens / len(pages)
+		for _, page := range pages {
+			title := page.Title
+			if title == "" {
+				title = page.URL
+			}
+			body := handoff.CompressText(page.Text, share)
+			sb.WriteString(fmt.Sprintf("\n### %s\n%s\n\n%s\n", title, page.URL, body))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,172 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/handoff"
+)
+
+func TestImagesAndLinks(t *testing.T) {
+	text := `The total overlaps the button: ![Checkout on mobile](https://i.imgur.com/abc123.png)
+Repro steps in https://gist.github.com/jo/42. See also [the design](https://www.figma.com/file/xyz).
+Same gist again: https://gist.github.com/jo/42`
+
+	images := Images(text)
+	if len(images) != 1 || images[0].URL != "https://i.imgur.com/abc123.png" || images[0].Alt != "Checkout on mobile" {
+		t.Errorf("Images = %+v", images)
+	}
+
+	links := Links(text)
+	want := []string{"https://gist.github.com/jo/42", "https://www.figma.com/file/xyz"}
+	if strings.Join(links, " ") != strings.Join(want, " ") {
+		t.Errorf("Links = %v, want %v", links, want)
+	}
+}
+
+func TestImageExtension(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	if ext := ImageExtension(png); ext != ".png" {
+		t.Errorf("ImageExtension(png) = %q", ext)
+	}
+	if ext := ImageExtension([]byte("panic: runtime error\n")); ext != "" {
+		t.Errorf("ImageExtension(log) = %q", ext)
+	}
+}
+
+const articlePage = `<!DOCTYPE html>
+<html><head><title>Rounding rules &amp; currencies</title><script>track()</script></head>
+<body>
+<nav><a href="/">Home</a> <a href="/docs">Docs</a></nav>
+<article>
+  <h1>Rounding rules</h1>
+  <p>Amounts are rounded half to even, per currency, before taxes are applied.</p>
+  <ul><li>JPY has no minor unit</li><li>BHD has three</li></ul>
+  <p><a href="/edit">Edit</a></p>
+</article>
+<footer>Copyright 2026 and some other footer text here</footer>
+</body></html>`
+
+func TestReadable(t *testing.T) {
+	title, text := Readable(articlePage)
+	if title != "Rounding rules & currencies" {
+		t.Errorf("title = %q", title)
+	}
+	want := "# Rounding rules\n\nAmounts are rounded half to even, per currency, before taxes are applied.\n\n- JPY has no minor unit\n- BHD has three"
+	if text != want {
+		t.Errorf("text = %q, want %q", text, want)
+	}
+}
+
+func TestPage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rules":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(articlePage))
+		case "/notes.txt":
+			w.Header().Set("Content-Type", "text/plain")
+			w.Write([]byte("Round before tax.\n"))
+		case "/export.zip":
+			w.Header().Set("Content-Type", "application/zip")
+			w.Write([]byte("PK"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	// The test server is on loopback, which New refuses
+	f := newFetcher(nil)
+	page, err := f.Page(context.Background(), srv.URL+"/rules")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Title != "Rounding rules & currencies" || !strings.Contains(page.Text, "half to even") {
+		t.Errorf("page = %+v", page)
+	}
+	if page, err := f.Page(context.Background(), srv.URL+"/notes.txt"); err != nil || page.Text != "Round before tax." {
+		t.Errorf("page = %+v, err = %v", page, err)
+	}
+	if _, err := f.Page(context.Background(), srv.URL+"/export.zip"); err == nil {
+		t.Error("Page accepted a zip file")
+	}
+	if _, err := f.Page(context.Background(), srv.URL+"/missing"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("err = %v", err)
+	}
+	if _, err := f.Page(context.Background(), "file:///etc/passwd"); err == nil {
+		t.Error("Page fetched a file URL")
+	}
+}
+
+func TestPrivateAddresses(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("internal"))
+	}))
+	defer srv.Close()
+	redirect := httptest.NewServer(http.RedirectHandler(srv.URL, http.StatusFound))
+	defer redirect.Close()
+
+	f := New()
+	for _, url := range []string{srv.URL, strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)} {
+		if _, _, err := f.Download(context.Background(), url); !errors.Is(err, ErrPrivateAddress) {
+			t.Errorf("Download(%s) err = %v, want ErrPrivateAddress", url, err)
+		}
+	}
+	// A redirect to a private address is refused too
+	f = newFetcher(func(network, address string, c syscall.RawConn) error {
+		if "http://"+address == redirect.URL {
+			return nil
+		}
+		return publicOnly(network, address, c)
+	})
+	if _, _, err := f.Download(context.Background(), redirect.URL); !errors.Is(err, ErrPrivateAddress) {
+		t.Errorf("redirect err = %v, want ErrPrivateAddress", err)
+	}
+
+	for addr, public := range map[string]bool{
+		"93.184.216.34":   true,
+		"2606:4700::1111": true,
+		"127.0.0.1":       false,
+		"10.1.2.3":        false,
+		"172.16.0.1":      false,
+		"192.168.1.1":     false,
+		"169.254.169.254": false,
+		"100.64.0.1":      false,
+		"0.0.0.0":         false,
+		"::1":             false,
+		"fd00::1":         false,
+		"fe80::1":         false,
+		"::ffff:10.0.0.1": false,
+	} {
+		if got := publicAddr(netip.MustParseAddr(addr)); got != public {
+			t.Errorf("publicAddr(%s) = %v, want %v", addr, got, public)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	long := strings.Repeat("Background on how rounding used to work in the old billing system.\n\n", 200)
+	pages := []*Page{
+		{URL: "https://example.com/a", Title: "Rules", Text: "- Round half to even"},
+		{URL: "https://example.com/b", Text: long},
+	}
+	out := Render("#### screenshot-1.png\nThe total overlaps the Pay button.", pages, 1000)
+	for _, want := range []string{"## Ticket Media", "### Screenshots", "overlaps the Pay button", "### Rules\nhttps://example.com/a", "### https://example.com/b"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Render missing %q:\n%s", want, out)
+		}
+	}
+	if tokens := handoff.EstimateTokens(out); tokens > 1200 {
+		t.Errorf("Render is %d tokens, want about 1000", tokens)
+	}
+	if Render("", nil, 1000) != "" {
+		t.Error("Render with nothing is not empty")
+	}
+}
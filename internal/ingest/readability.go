@@ -0,0 +1,86 @@
+package ingest
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// boilerplate are the elements dropped before looking for a page's text:
+// code, styling, and the navigation around the content.
+var boilerplate = func() []*regexp.Regexp {
+	var res []*regexp.Regexp
+	for _, tag := range []string{"script", "style", "noscript", "template", "svg", "nav", "header", "footer", "aside", "form"} {
+		res = append(res, regexp.MustCompile(`(?is)<`+tag+`\b.*?</`+tag+`>`))
+	}
+	return res
+}()
+
+// contentElements hold a page's main content, most specific first.
+var contentElements = []*regexp.Regexp{
+	regexp.MustCompile(`(?is)<article\b[^>]*>(.*)</article>`),
+	regexp.MustCompile(`(?is)<main\b[^>]*>(.*)</main>`),
+	regexp.MustCompile(`(?is)<[^>]+role=["']main["'][^>]*>(.*)`),
+	regexp.MustCompile(`(?is)<body\b[^>]*>(.*)</body>`),
+}
+
+var (
+	htmlTitle     = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+	htmlComment   = regexp.MustCompile(`(?s)<!--.*?-->`)
+	htmlHeading   = regexp.MustCompile(`(?i)<h([1-6])[^>]*>`)
+	htmlListItem  = regexp.MustCompile(`(?i)<li[^>]*>`)
+	htmlBreak     = regexp.MustCompile(`(?i)<br\s*/?>|</(?:p|div|section|h[1-6]|tr|pre|blockquote|ul|ol|table)>`)
+	htmlTag       = regexp.MustCompile(`<[^>]+>`)
+	htmlBlankRuns = regexp.MustCompile(`\n{3,}`)
+	spaceRuns     = regexp.MustCompile(`[ \t\r\f\v]+`)
+)
+
+// minLineWords is the fewest words a line of text needs to be kept, unless
+// it is a heading or list item: shorter lines are mostly leftover links and
+// buttons.
+const minLineWords = 4
+
+// Readable extracts the title and main text of an HTML page, roughly the
+// way reader views do: scripts and navigation are dropped, the article or
+// main element is preferred over the whole body, and short link-like lines
+// are left out. Headings and list items are kept as markdown.
+func Readable(page string) (title, text string) {
+	if m := htmlTitle.FindStringSubmatch(page); m != nil {
+		title = strings.TrimSpace(html.UnescapeString(spaceRuns.ReplaceAllString(m[1], " ")))
+	}
+
+	page = htmlComment.ReplaceAllString(page, "")
+	for _, re := range boilerplate {
+		page = re.ReplaceAllString(page, "")
+	}
+	for _, re := range contentElements {
+		if m := re.FindStringSubmatch(page); m != nil {
+			page = m[1]
+			break
+		}
+	}
+
+	page = htmlHeading.ReplaceAllStringFunc(page, func(tag string) string {
+		level := htmlHeading.FindStringSubmatch(tag)[1]
+		return "\n\n" + strings.Repeat("#", int(level[0]-'0')) + " "
+	})
+	page = htmlListItem.ReplaceAllString(page, "\n- ")
+	page = htmlBreak.ReplaceAllString(page, "\n")
+	page = htmlTag.ReplaceAllString(page, "")
+	page = html.UnescapeString(page)
+
+	var lines []string
+	for _, line := range strings.Split(page, "\n") {
+		line = strings.TrimSpace(spaceRuns.ReplaceAllString(line, " "))
+		structural := strings.HasPrefix(line, "#") || strings.HasPrefix(line, "- ")
+		if line != "" && !structural && len(strings.Fields(line)) < minLineWords {
+			continue
+		}
+		if line == "-" {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	text = strings.TrimSpace(htmlBlankRuns.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+	return title, text
+}
@@ -0,0 +1,63 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/claude"
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/cost"
+)
+
+// ErrNoVision is returned when the configured model can't read images.
+var ErrNoVision = errors.New("describing screenshots needs the Claude CLI to read them; API providers cannot use tools")
+
+// Describer describes screenshots with a vision-capable model.
+type Describer struct {
+	client *claude.Client
+}
+
+// NewDescriber creates a Describer for the images saved in dir. It may only
+// read files.
+func NewDescriber(dir string, cfg *config.Config) *Describer {
+	client := claude.NewWithTools(dir, "vision", []string{"Read"})
+	if cfg.Claude.Models.Vision != "" {
+		client.Model = cfg.Claude.Models.Vision
+	}
+	client.Sampling = cfg.Claude.SamplingFor("vision")
+	client.Configure(cfg.Claude)
+	return &Describer{client: client}
+}
+
+// Describe returns a description of each image, named by its file in the
+// Describer's directory, as it bears on the ticket. alts are the captions
+// the ticket gave the images, if any.
+func (d *Describer) Describe(ctx context.Context, title string, files, alts []string) (string, *cost.Usage, error) {
+	if !d.client.SupportsTools() {
+		return "", nil, ErrNoVision
+	}
+	systemPrompt := `You transcribe screenshots attached to a development ticket for an engineer who cannot see them.
+Read each image with the Read tool, then describe what matters for working on the ticket:
+which screen or component it shows, its state, any visible text and error messages verbatim,
+and anything that looks broken or is highlighted. Do not guess at causes or fixes.
+
+Write one section per image, headed "#### <file name>", in the order given. Be concise.`
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Ticket: %s\n\nScreenshots:\n", title))
+	for i, file := range files {
+		sb.WriteString("- " + file)
+		if i < len(alts) && alts[i] != "" {
+			sb.WriteString(fmt.Sprintf(" (captioned %q)", alts[i]))
+		}
+		sb.WriteString("\n")
+	}
+
+	response, usage, err := d.client.Message(ctx, systemPrompt, sb.String())
+	if err != nil {
+		return "", nil, fmt.Errorf("vision agent failed: %w", err)
+	}
+	return strings.TrimSpace(response), usage, nil
+}
@@ -0,0 +1,254 @@
+// Package jira provides a client for the Jira REST API.
+package jira
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/retry"
+)
+
+// Client is a Jira API client.
+type Client struct {
+	baseURL    string
+	email      string
+	token      string
+	httpClient *http.Client
+}
+
+// Ticket represents a Jira issue.
+type Ticket struct {
+	Key         string   `json:"key"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	State       string   `json:"state"`
+	Priority    string   `json:"priority"`
+	Type        string   `json:"type"`
+	Labels      []string `json:"labels"`
+	URL         string   `json:"url"`
+}
+
+// New creates a new Jira client.
+func New(cfg config.JiraConfig) *Client {
+	return &Client{
+		baseURL:    strings.TrimSuffix(cfg.URL, "/"),
+		email:      cfg.Email,
+		token:      cfg.Token,
+		httpClient: &http.Client{},
+	}
+}
+
+// issueFields are the fields of an issue the client reads.
+type issueFields struct {
+	Summary     string   `json:"summary"`
+	Description string   `json:"description"`
+	Labels      []string `json:"labels"`
+	Status      struct {
+		Name string `json:"name"`
+	} `json:"status"`
+	Priority *struct {
+		Name string `json:"name"`
+	} `json:"priority"`
+	IssueType struct {
+		Name string `json:"name"`
+	} `json:"issuetype"`
+}
+
+type issue struct {
+	Key    string      `json:"key"`
+	Fields issueFields `json:"fields"`
+}
+
+const fieldList = "summary,description,labels,status,priority,issuetype"
+
+// GetTicket fetches a ticket by its key (e.g., "PROJ-123").
+func (c *Client) GetTicket(ctx context.Context, key string) (*Ticket, error) {
+	resp, err := c.execute(ctx, http.MethodGet, "/rest/api/2/issue/"+url.PathEscape(key)+"?fields="+fieldList, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result issue
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return c.ticket(result), nil
+}
+
+// AssignedTickets returns up to limit open tickets assigned to the token's
+// user, most recently updated first.
+func (c *Client) AssignedTickets(ctx context.Context, limit int) ([]Ticket, error) {
+	query := url.Values{
+		"jql":        {"assignee = currentUser() AND statusCategory != Done ORDER BY updated DESC"},
+		"fields":     {fieldList},
+		"maxResults": {fmt.Sprint(limit)},
+	}
+	resp, err := c.execute(ctx, http.MethodGet, "/rest/api/2/search?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Issues []issue `json:"issues"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	tickets := make([]Ticket, len(result.Issues))
+	for i, is := range result.Issues {
+		tickets[i] = *c.ticket(is)
+	}
+	return tickets, nil
+}
+
+// Transition moves a ticket to the named status, matching the transition
+// or its target status case-insensitively.
+func (c *Client) Transition(ctx context.Context, key, status string) error {
+	path := "/rest/api/2/issue/" + url.PathEscape(key) + "/transitions"
+	resp, err := c.execute(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return err
+	}
+
+	var result struct {
+		Transitions []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+			To   struct {
+				Name string `json:"name"`
+			} `json:"to"`
+		} `json:"transitions"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	var available []string
+	for _, t := range result.Transitions {
+		if strings.EqualFold(t.Name, status) || strings.EqualFold(t.To.Name, status) {
+			body := map[string]interface{}{"transition": map[string]string{"id": t.ID}}
+			_, err := c.execute(ctx, http.MethodPost, path, body)
+			return err
+		}
+		available = append(available, t.To.Name)
+	}
+	return fmt.Errorf("no transition to %q from the ticket's status (available: %s)", status, strings.Join(available, ", "))
+}
+
+// Viewer returns the display name of the user the token belongs to. It is
+// a cheap way to check that credentials are valid.
+func (c *Client) Viewer(ctx context.Context) (string, error) {
+	resp, err := c.execute(ctx, http.MethodGet, "/rest/api/2/myself", nil)
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		DisplayName string `json:"displayName"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	return result.DisplayName, nil
+}
+
+// ticket converts an API issue.
+func (c *Client) ticket(is issue) *Ticket {
+	t := &Ticket{
+		Key:         is.Key,
+		Title:       is.Fields.Summary,
+		Description: is.Fields.Description,
+		State:       is.Fields.Status.Name,
+		Type:        is.Fields.IssueType.Name,
+		Labels:      is.Fields.Labels,
+		URL:         c.baseURL + "/browse/" + is.Key,
+	}
+	if is.Fields.Priority != nil {
+		t.Priority = is.Fields.Priority.Name
+	}
+	return t
+}
+
+// execute performs a REST request to Jira with retry logic.
+func (c *Client) execute(ctx context.Context, method, path string, body interface{}) ([]byte, error) {
+	if c.baseURL == "" {
+		return nil, retry.Permanent(fmt.Errorf("jira URL is not configured"))
+	}
+
+	var jsonBody []byte
+	if body != nil {
+		var err error
+		jsonBody, err = json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+	}
+
+	var result []byte
+
+	err := retry.Do(ctx, retry.APIConfig(), "Jira API request", func() error {
+		req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(jsonBody))
+		if err != nil {
+			return retry.Permanent(fmt.Errorf("failed to create request: %w", err))
+		}
+
+		req.Header.Set("Accept", "application/json")
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		if c.email != "" {
+			req.SetBasicAuth(c.email, c.token)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("request failed: %w", err) // Retryable
+		}
+		defer resp.Body.Close()
+
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+
+		// 429 is retryable; other 4xx errors are permanent (client errors)
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			return fmt.Errorf("API returned status %d: %s", resp.StatusCode, errorMessage(respBody))
+		}
+		if resp.StatusCode >= 400 {
+			return retry.Permanent(fmt.Errorf("API returned status %d: %s", resp.StatusCode, errorMessage(respBody)))
+		}
+
+		result = respBody
+		return nil
+	})
+
+	return result, err
+}
+
+// errorMessage extracts Jira's error messages from a response body.
+func errorMessage(body []byte) string {
+	var result struct {
+		ErrorMessages []string          `json:"errorMessages"`
+		Errors        map[string]string `json:"errors"`
+	}
+	if json.Unmarshal(body, &result) == nil {
+		messages := result.ErrorMessages
+		for field, msg := range result.Errors {
+			messages = append(messages, field+": "+msg)
+		}
+		if len(messages) > 0 {
+			return strings.Join(messages, "; ")
+		}
+	}
+	return string(body)
+}
@@ -0,0 +1,119 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+)
+
+func TestGetTicket(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/2/issue/PROJ-42" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if user, pass, ok := r.BasicAuth(); !ok || user != "dev@acme.com" || pass != "token" {
+			t.Errorf("basic auth = %q, %q, %v", user, pass, ok)
+		}
+		w.Write([]byte(`{"key": "PROJ-42", "fields": {
+			"summary": "Add CSV export",
+			"description": "Users want to export reports.",
+			"labels": ["reports"],
+			"status": {"name": "To Do"},
+			"priority": {"name": "High"},
+			"issuetype": {"name": "Story"}}}`))
+	}))
+	defer srv.Close()
+
+	c := New(config.JiraConfig{URL: srv.URL + "/", Email: "dev@acme.com", Token: "token"})
+	ticket, err := c.GetTicket(context.Background(), "PROJ-42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Ticket{
+		Key: "PROJ-42", Title: "Add CSV export", Description: "Users want to export reports.",
+		State: "To Do", Priority: "High", Type: "Story", Labels: []string{"reports"},
+		URL: srv.URL + "/browse/PROJ-42",
+	}
+	if ticket.Key != want.Key || ticket.Title != want.Title || ticket.Description != want.Description ||
+		ticket.State != want.State || ticket.Priority != want.Priority || ticket.Type != want.Type ||
+		len(ticket.Labels) != 1 || ticket.URL != want.URL {
+		t.Errorf("ticket = %+v, want %+v", ticket, want)
+	}
+}
+
+func TestGetTicketNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"errorMessages": ["Issue does not exist or you do not have permission to see it."]}`))
+	}))
+	defer srv.Close()
+
+	c := New(config.JiraConfig{URL: srv.URL, Token: "pat"})
+	_, err := c.GetTicket(context.Background(), "PROJ-404")
+	if err == nil || !strings.Contains(err.Error(), "Issue does not exist") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestAssignedTickets(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer pat" {
+			t.Errorf("Authorization = %q, want bearer token without email", r.Header.Get("Authorization"))
+		}
+		if jql := r.URL.Query().Get("jql"); !strings.Contains(jql, "assignee = currentUser()") {
+			t.Errorf("jql = %q", jql)
+		}
+		w.Write([]byte(`{"issues": [
+			{"key": "PROJ-1", "fields": {"summary": "First", "status": {"name": "In Progress"}}},
+			{"key": "PROJ-2", "fields": {"summary": "Second", "status": {"name": "To Do"}}}]}`))
+	}))
+	defer srv.Close()
+
+	c := New(config.JiraConfig{URL: srv.URL, Token: "pat"})
+	tickets, err := c.AssignedTickets(context.Background(), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tickets) != 2 || tickets[0].Key != "PROJ-1" || tickets[1].Title != "Second" {
+		t.Errorf("tickets = %+v", tickets)
+	}
+}
+
+func TestTransition(t *testing.T) {
+	var posted string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			var body struct {
+				Transition struct {
+					ID string `json:"id"`
+				} `json:"transition"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			posted = body.Transition.ID
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Write([]byte(`{"transitions": [
+			{"id": "11", "name": "Start progress", "to": {"name": "In Progress"}},
+			{"id": "21", "name": "Submit for review", "to": {"name": "In Review"}}]}`))
+	}))
+	defer srv.Close()
+
+	c := New(config.JiraConfig{URL: srv.URL, Token: "pat"})
+	if err := c.Transition(context.Background(), "PROJ-1", "in review"); err != nil {
+		t.Fatal(err)
+	}
+	if posted != "21" {
+		t.Errorf("posted transition %q, want 21", posted)
+	}
+
+	err := c.Transition(context.Background(), "PROJ-1", "Done")
+	if err == nil || !strings.Contains(err.Error(), "available: In Progress, In Review") {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"regexp"
 	"strings"
 
 	"github.com/philjestin/boatmanmode/internal/retry"
@@ -15,9 +16,18 @@ import (
 
 const apiURL = "https://api.linear.app/graphql"
 
+// uploadsURL is where files uploaded to Linear are served from; tests
+// override it.
+var uploadsURL = "https://uploads.linear.app/"
+
+// maxDownload is the largest upload Download reads; screenshots are often
+// over a megabyte.
+const maxDownload = 10 << 20
+
 // Client is a Linear API client.
 type Client struct {
 	apiKey     string
+	url        string
 	httpClient *http.Client
 }
 
@@ -31,14 +41,39 @@ type Ticket struct {
 	Priority    int      `json:"priority"`
 	Labels      []string `json:"labels"`
 	BranchName  string   `json:"branchName"`
+	// Attachments are the links attached to the issue (uploads, Sentry
+	// issues, PRs)
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+// Attachment is a link attached to an issue.
+type Attachment struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithURL points the client at another API endpoint, such as a test
+// server.
+func WithURL(url string) Option {
+	return func(c *Client) {
+		c.url = url
+	}
 }
 
 // New creates a new Linear client.
-func New(apiKey string) *Client {
-	return &Client{
+func New(apiKey string, opts ...Option) *Client {
+	c := &Client{
 		apiKey:     apiKey,
+		url:        apiURL,
 		httpClient: &http.Client{},
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 // GetTicket fetches a ticket by its identifier (e.g., "ENG-123").
@@ -60,6 +95,12 @@ func (c *Client) GetTicket(ctx context.Context, identifier string) (*Ticket, err
 						name
 					}
 				}
+				attachments {
+					nodes {
+						title
+						url
+					}
+				}
 			}
 		}
 	`
@@ -90,6 +131,9 @@ func (c *Client) GetTicket(ctx context.Context, identifier string) (*Ticket, err
 						Name string `json:"name"`
 					} `json:"nodes"`
 				} `json:"labels"`
+				Attachments struct {
+					Nodes []Attachment `json:"nodes"`
+				} `json:"attachments"`
 			} `json:"issue"`
 		} `json:"data"`
 		Errors []struct {
@@ -120,9 +164,312 @@ func (c *Client) GetTicket(ctx context.Context, identifier string) (*Ticket, err
 		Priority:    issue.Priority,
 		Labels:      labels,
 		BranchName:  issue.BranchName,
+		Attachments: issue.Attachments.Nodes,
 	}, nil
 }
 
+var uploadLink = regexp.MustCompile(`https://uploads\.linear\.app/[^\s()\[\]<>"']+`)
+
+// UploadURLs returns the files uploaded to the ticket: attachments
+// hosted by Linear and uploads linked from the description.
+func (t *Ticket) UploadURLs() []string {
+	var urls []string
+	seen := make(map[string]bool)
+	add := func(url string) {
+		if strings.HasPrefix(url, uploadsURL) && !seen[url] {
+			seen[url] = true
+			urls = append(urls, url)
+		}
+	}
+	for _, a := range t.Attachments {
+		add(a.URL)
+	}
+	for _, url := range uploadLink.FindAllString(t.Description, -1) {
+		add(url)
+	}
+	return urls
+}
+
+// Download fetches a file uploaded to Linear, up to 10MB of it. Uploads
+// need the API key, so other hosts are refused rather than sent it.
+func (c *Client) Download(ctx context.Context, url string) ([]byte, error) {
+	if !strings.HasPrefix(url, uploadsURL) {
+		return nil, fmt.Errorf("not a Linear upload: %s", url)
+	}
+	var data []byte
+	err := retry.Do(ctx, retry.APIConfig(), "Linear upload download", func() error {
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return retry.Permanent(fmt.Errorf("failed to create request: %w", err))
+		}
+		req.Header.Set("Authorization", c.apiKey)
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("request failed: %w", err)
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxDownload))
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+			return retry.Permanent(fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body)))
+		}
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+		}
+		data = body
+		return nil
+	})
+	return data, err
+}
+
+// Viewer returns the name of the user the API key belongs to. It is a
+// cheap way to check that a key is valid.
+func (c *Client) Viewer(ctx context.Context) (string, error) {
+	resp, err := c.execute(ctx, `query { viewer { name } }`, nil)
+	if err != nil {
+		return "", err
+	}
+
+	var result struct {
+		Data struct {
+			Viewer struct {
+				Name string `json:"name"`
+			} `json:"viewer"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		return "", fmt.Errorf("linear API error: %s", result.Errors[0].Message)
+	}
+	return result.Data.Viewer.Name, nil
+}
+
+// Filter selects tickets to list. Empty fields match every ticket.
+type Filter struct {
+	State string // Workflow state name, ignoring case
+	Label string // Label name, ignoring case
+	Team  string // Team key, e.g. ENG
+}
+
+// maxListed is how many tickets ListTickets returns.
+const maxListed = 50
+
+// ListTickets returns up to 50 tickets matching filter, most recently
+// updated first.
+func (c *Client) ListTickets(ctx context.Context, filter Filter) ([]Ticket, error) {
+	query := `
+		query ListIssues($filter: IssueFilter, $first: Int) {
+			issues(filter: $filter, first: $first) {
+				nodes {
+					id
+					identifier
+					title
+					description
+					branchName
+					priority
+					state {
+						name
+					}
+					labels {
+						nodes {
+							name
+						}
+					}
+				}
+			}
+		}
+	`
+
+	issueFilter := map[string]interface{}{}
+	if filter.State != "" {
+		issueFilter["state"] = map[string]interface{}{"name": map[string]interface{}{"eqIgnoreCase": filter.State}}
+	}
+	if filter.Label != "" {
+		issueFilter["labels"] = map[string]interface{}{"some": map[string]interface{}{"name": map[string]interface{}{"eqIgnoreCase": filter.Label}}}
+	}
+	if filter.Team != "" {
+		issueFilter["team"] = map[string]interface{}{"key": map[string]interface{}{"eq": filter.Team}}
+	}
+
+	resp, err := c.execute(ctx, query, map[string]interface{}{"filter": issueFilter, "first": maxListed})
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data struct {
+			Issues struct {
+				Nodes []struct {
+					ID          string `json:"id"`
+					Identifier  string `json:"identifier"`
+					Title       string `json:"title"`
+					Description string `json:"description"`
+					BranchName  string `json:"branchName"`
+					Priority    int    `json:"priority"`
+					State       struct {
+						Name string `json:"name"`
+					} `json:"state"`
+					Labels struct {
+						Nodes []struct {
+							Name string `json:"name"`
+						} `json:"nodes"`
+					} `json:"labels"`
+				} `json:"nodes"`
+			} `json:"issues"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		return nil, fmt.Errorf("linear API error: %s", result.Errors[0].Message)
+	}
+
+	tickets := make([]Ticket, 0, len(result.Data.Issues.Nodes))
+	for _, issue := range result.Data.Issues.Nodes {
+		labels := make([]string, len(issue.Labels.Nodes))
+		for i, l := range issue.Labels.Nodes {
+			labels[i] = l.Name
+		}
+		tickets = append(tickets, Ticket{
+			ID:          issue.ID,
+			Identifier:  issue.Identifier,
+			Title:       issue.Title,
+			Description: issue.Description,
+			State:       issue.State.Name,
+			Priority:    issue.Priority,
+			Labels:      labels,
+			BranchName:  issue.BranchName,
+		})
+	}
+	return tickets, nil
+}
+
+// Comment adds a markdown comment to the issue (ID or identifier).
+func (c *Client) Comment(ctx context.Context, issue, body string) error {
+	query := `
+		mutation Comment($issueId: String!, $body: String!) {
+			commentCreate(input: {issueId: $issueId, body: $body}) {
+				success
+			}
+		}
+	`
+	resp, err := c.execute(ctx, query, map[string]interface{}{"issueId": issue, "body": body})
+	if err != nil {
+		return err
+	}
+	return mutationError(resp, "commentCreate")
+}
+
+// MoveToState moves the issue (ID or identifier) to the workflow state of
+// its team with the given name, ignoring case.
+func (c *Client) MoveToState(ctx context.Context, issue, state string) error {
+	query := `
+		query IssueStates($identifier: String!) {
+			issue(id: $identifier) {
+				id
+				team {
+					states {
+						nodes {
+							id
+							name
+						}
+					}
+				}
+			}
+		}
+	`
+	resp, err := c.execute(ctx, query, map[string]interface{}{"identifier": issue})
+	if err != nil {
+		return err
+	}
+
+	var result struct {
+		Data struct {
+			Issue struct {
+				ID   string `json:"id"`
+				Team struct {
+					States struct {
+						Nodes []struct {
+							ID   string `json:"id"`
+							Name string `json:"name"`
+						} `json:"nodes"`
+					} `json:"states"`
+				} `json:"team"`
+			} `json:"issue"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("linear API error: %s", result.Errors[0].Message)
+	}
+
+	var stateID string
+	var names []string
+	for _, s := range result.Data.Issue.Team.States.Nodes {
+		if strings.EqualFold(s.Name, state) {
+			stateID = s.ID
+		}
+		names = append(names, s.Name)
+	}
+	if stateID == "" {
+		return fmt.Errorf("%s has no state %q (states: %s)", issue, state, strings.Join(names, ", "))
+	}
+
+	update := `
+		mutation MoveIssue($id: String!, $stateId: String!) {
+			issueUpdate(id: $id, input: {stateId: $stateId}) {
+				success
+			}
+		}
+	`
+	resp, err = c.execute(ctx, update, map[string]interface{}{"id": result.Data.Issue.ID, "stateId": stateID})
+	if err != nil {
+		return err
+	}
+	return mutationError(resp, "issueUpdate")
+}
+
+// mutationError returns the error of a mutation response, or of a
+// mutation that reports it didn't succeed.
+func mutationError(resp []byte, mutation string) error {
+	var result struct {
+		Data map[string]struct {
+			Success bool `json:"success"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("linear API error: %s", result.Errors[0].Message)
+	}
+	if !result.Data[mutation].Success {
+		return fmt.Errorf("linear %s did not succeed", mutation)
+	}
+	return nil
+}
+
 // execute performs a GraphQL request to Linear with retry logic.
 func (c *Client) execute(ctx context.Context, query string, variables map[string]interface{}) ([]byte, error) {
 	body := map[string]interface{}{
@@ -138,7 +485,7 @@ func (c *Client) execute(ctx context.Context, query string, variables map[string
 	var result []byte
 
 	err = retry.Do(ctx, retry.APIConfig(), "Linear API request", func() error {
-		req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewReader(jsonBody))
+		req, err := http.NewRequestWithContext(ctx, "POST", c.url, bytes.NewReader(jsonBody))
 		if err != nil {
 			return retry.Permanent(fmt.Errorf("failed to create request: %w", err))
 		}
@@ -0,0 +1,94 @@
+package linear
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestListTickets(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "lin_key" {
+			t.Errorf("Authorization = %q", got)
+		}
+		var req struct {
+			Variables struct {
+				Filter map[string]any `json:"filter"`
+				First  int            `json:"first"`
+			} `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		filter, _ := json.Marshal(req.Variables.Filter)
+		want := `{"labels":{"some":{"name":{"eqIgnoreCase":"boatman"}}},"state":{"name":{"eqIgnoreCase":"Ready for AI"}}}`
+		if string(filter) != want || req.Variables.First != maxListed {
+			t.Errorf("filter = %s, first = %d", filter, req.Variables.First)
+		}
+		w.Write([]byte(`{"data": {"issues": {"nodes": [
+			{"id": "uuid-1", "identifier": "ENG-1", "title": "Add export", "priority": 2,
+			 "state": {"name": "Ready for AI"}, "labels": {"nodes": [{"name": "boatman"}]}}
+		]}}}`))
+	}))
+	defer srv.Close()
+	tickets, err := New("lin_key", WithURL(srv.URL)).ListTickets(context.Background(), Filter{State: "Ready for AI", Label: "boatman"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tickets) != 1 {
+		t.Fatalf("tickets = %+v", tickets)
+	}
+	if got := tickets[0]; got.Identifier != "ENG-1" || got.Priority != 2 || got.State != "Ready for AI" ||
+		len(got.Labels) != 1 || got.Labels[0] != "boatman" {
+		t.Errorf("ticket = %+v", got)
+	}
+}
+
+func TestListTicketsError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"errors": [{"message": "Argument Validation Error"}]}`))
+	}))
+	defer srv.Close()
+	_, err := New("lin_key", WithURL(srv.URL)).ListTickets(context.Background(), Filter{Team: "ENG"})
+	if err == nil || err.Error() != "linear API error: Argument Validation Error" {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestUploadURLs(t *testing.T) {
+	ticket := &Ticket{
+		Description: "Crashes on export, log: [crash.log](https://uploads.linear.app/ws/abc/crash.log)\n" +
+			"See also https://example.com/trace.txt",
+		Attachments: []Attachment{
+			{Title: "trace.txt", URL: "https://uploads.linear.app/ws/def/trace.txt"},
+			{Title: "Sentry issue", URL: "https://sentry.io/issues/1"},
+			{Title: "crash.log", URL: "https://uploads.linear.app/ws/abc/crash.log"},
+		},
+	}
+	want := []string{"https://uploads.linear.app/ws/def/trace.txt", "https://uploads.linear.app/ws/abc/crash.log"}
+	got := ticket.UploadURLs()
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("UploadURLs = %v, want %v", got, want)
+	}
+}
+
+func TestDownload(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "lin_key" {
+			t.Errorf("Authorization = %q", got)
+		}
+		w.Write([]byte("panic: boom\n"))
+	}))
+	defer srv.Close()
+	defer func(orig string) { uploadsURL = orig }(uploadsURL)
+	uploadsURL = srv.URL + "/"
+
+	data, err := New("lin_key").Download(context.Background(), srv.URL+"/ws/abc/crash.log")
+	if err != nil || string(data) != "panic: boom\n" {
+		t.Errorf("Download = %q, %v", data, err)
+	}
+	// The key is only sent to Linear
+	if _, err := New("lin_key").Download(context.Background(), "https://example.com/crash.log"); err == nil {
+		t.Error("expected a non-Linear URL to be refused")
+	}
+}
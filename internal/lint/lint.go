@@ -0,0 +1,504 @@
+// Package lint provides lint checking capabilities.
+// It detects the project's linters and runs them on the changed files.
+package lint
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/cmdoutput"
+	"github.com/philjestin/boatmanmode/internal/coordinator"
+	"github.com/philjestin/boatmanmode/internal/scottbott"
+)
+
+// maxIssues is the most violations reported as separate review issues;
+// the rest are summed up in one.
+const maxIssues = 20
+
+// Violation is a problem a linter reports in a changed file.
+type Violation struct {
+	Linter  string
+	File    string
+	Line    int
+	Column  int
+	Rule    string
+	Message string
+	Warning bool // Reported as a warning; does not fail the linter
+}
+
+// Result contains the outcome of a lint run.
+type Result struct {
+	Passed     bool
+	Linters    []string
+	Violations []Violation
+	Failure    string   // Output of a command that failed without reporting violations
+	Skipped    []string // Linters that could not run, and why
+	Duration   time.Duration
+}
+
+// Agent runs the project's linters.
+type Agent struct {
+	id           string
+	worktreePath string
+	command      string
+	coord        *coordinator.Coordinator
+}
+
+// New creates a new lint agent.
+func New(worktreePath string) *Agent {
+	return &Agent{
+		id:           "linter",
+		worktreePath: worktreePath,
+	}
+}
+
+// ID returns the agent ID.
+func (a *Agent) ID() string {
+	return a.id
+}
+
+// Name returns the human-readable name.
+func (a *Agent) Name() string {
+	return "Linter"
+}
+
+// Capabilities returns what this agent can do.
+func (a *Agent) Capabilities() []coordinator.AgentCapability {
+	return []coordinator.AgentCapability{coordinator.CapLint}
+}
+
+// SetCoordinator sets the coordinator for communication.
+func (a *Agent) SetCoordinator(c *coordinator.Coordinator) {
+	a.coord = c
+}
+
+// SetCommand replaces linter detection with the project's own lint
+// command, run via sh -c over the whole project. Violations are read from
+// its file:line: message output.
+func (a *Agent) SetCommand(command string) {
+	a.command = command
+}
+
+// Linter represents a detected linter.
+type Linter struct {
+	Name    string
+	Command string
+	Args    []string
+	// Extensions of the files the linter checks
+	Extensions []string
+	// Packages runs the linter on the directories of the changed files
+	// rather than the files, for linters that type-check whole packages
+	Packages bool
+	parse    func(output string) ([]Violation, error)
+}
+
+// DetectLinters figures out which linters the project uses. A linter is
+// used when the project configures it, or for golangci-lint, when it is
+// installed in a Go module.
+func (a *Agent) DetectLinters() []*Linter {
+	if a.command != "" {
+		return []*Linter{{
+			Name:    "custom",
+			Command: "sh",
+			Args:    []string{"-c", a.command},
+			parse:   parseText,
+		}}
+	}
+
+	var linters []*Linter
+
+	// Check for Go
+	if a.exists("go.mod") {
+		if _, err := exec.LookPath("golangci-lint"); err == nil {
+			linters = append(linters, &Linter{
+				Name:       "golangci-lint",
+				Command:    "golangci-lint",
+				Args:       []string{"run"},
+				Extensions: []string{".go"},
+				Packages:   true,
+				parse:      parseText,
+			})
+		}
+	}
+
+	// Check for ESLint
+	if a.exists("package.json") {
+		pkgJSON, _ := os.ReadFile(filepath.Join(a.worktreePath, "package.json"))
+		if strings.Contains(string(pkgJSON), `"eslintConfig"`) || a.exists(
+			"eslint.config.js", "eslint.config.mjs", "eslint.config.cjs", "eslint.config.ts",
+			".eslintrc", ".eslintrc.js", ".eslintrc.cjs", ".eslintrc.json", ".eslintrc.yml", ".eslintrc.yaml") {
+			linters = append(linters, &Linter{
+				Name:       "eslint",
+				Command:    "npx",
+				Args:       []string{"--no-install", "eslint", "--format", "json"},
+				Extensions: []string{".js", ".jsx", ".ts", ".tsx", ".mjs", ".cjs"},
+				parse:      parseESLint,
+			})
+		}
+	}
+
+	// Check for RuboCop
+	gemfile, _ := os.ReadFile(filepath.Join(a.worktreePath, "Gemfile"))
+	if bundled := strings.Contains(string(gemfile), "rubocop"); bundled || a.exists(".rubocop.yml") {
+		linter := &Linter{
+			Name:       "rubocop",
+			Command:    "rubocop",
+			Args:       []string{"--format", "json", "--force-exclusion"},
+			Extensions: []string{".rb", ".rake"},
+			parse:      parseRuboCop,
+		}
+		if bundled {
+			linter.Command = "bundle"
+			linter.Args = append([]string{"exec", "rubocop"}, linter.Args...)
+		}
+		linters = append(linters, linter)
+	}
+
+	// Check for Ruff
+	pyproject, _ := os.ReadFile(filepath.Join(a.worktreePath, "pyproject.toml"))
+	if strings.Contains(string(pyproject), "[tool.ruff") || a.exists("ruff.toml", ".ruff.toml") {
+		linters = append(linters, &Linter{
+			Name:       "ruff",
+			Command:    "ruff",
+			Args:       []string{"check", "--output-format", "json", "--force-exclude"},
+			Extensions: []string{".py", ".pyi"},
+			parse:      parseRuff,
+		})
+	}
+
+	return linters
+}
+
+// Run lints the changed files with each detected linter. Only violations
+// in changedFiles count. Linters that cannot run are skipped and listed in
+// the result.
+func (a *Agent) Run(ctx context.Context, changedFiles []string) (*Result, error) {
+	start := time.Now()
+	result := &Result{Passed: true}
+	for _, linter := range a.DetectLinters() {
+		args := a.targetArgs(linter, changedFiles)
+		if args == nil {
+			continue
+		}
+		result.Linters = append(result.Linters, linter.Name)
+		violations, failure, err := a.runLinter(ctx, linter, args)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: %v", linter.Name, err))
+			continue
+		}
+		if failure != "" && result.Failure == "" {
+			result.Failure = failure
+		}
+		for _, v := range violations {
+			v.Linter = linter.Name
+			v.File = a.relative(v.File)
+			if slices.Contains(changedFiles, v.File) {
+				result.Violations = append(result.Violations, v)
+			}
+		}
+	}
+	for _, v := range result.Violations {
+		if !v.Warning {
+			result.Passed = false
+		}
+	}
+	if result.Failure != "" {
+		result.Passed = false
+	}
+	result.Duration = time.Since(start)
+	return result, nil
+}
+
+// targetArgs returns the linter's arguments for the changed files it
+// checks, or nil if there are none. The custom command lints everything.
+func (a *Agent) targetArgs(linter *Linter, changedFiles []string) []string {
+	if linter.Extensions == nil {
+		if len(changedFiles) == 0 {
+			return nil
+		}
+		return linter.Args
+	}
+	var targets []string
+	for _, file := range changedFiles {
+		if !slices.Contains(linter.Extensions, filepath.Ext(file)) || !a.exists(file) {
+			continue
+		}
+		target := file
+		if linter.Packages {
+			target = "./" + filepath.ToSlash(filepath.Dir(file))
+		}
+		if !slices.Contains(targets, target) {
+			targets = append(targets, target)
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+	return append(slices.Clone(linter.Args), targets...)
+}
+
+// runLinter runs a linter, returning its violations. A command that fails
+// without reporting any returns the end of its output as failure; a linter
+// that cannot run at all is an error.
+func (a *Agent) runLinter(ctx context.Context, linter *Linter, args []string) ([]Violation, string, error) {
+	cmd := exec.CommandContext(ctx, linter.Command, args...)
+	cmd.Dir = a.worktreePath
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	runErr := cmd.Run()
+
+	output := stdout.String()
+	if linter.Name == "custom" || linter.Name == "golangci-lint" {
+		output += stderr.String()
+	}
+	if runErr == nil && linter.Name == "custom" {
+		// The project's own command decides what passes
+		return nil, "", nil
+	}
+	violations, parseErr := linter.parse(output)
+	var exit *exec.ExitError
+	if runErr != nil && !errors.As(runErr, &exit) {
+		return nil, "", runErr
+	}
+	if parseErr == nil && (runErr == nil || len(violations) > 0) {
+		return violations, "", nil
+	}
+
+	combined := cmdoutput.Tail(stdout.String()+stderr.String(), 20)
+	if linter.Name == "custom" {
+		if combined == "" {
+			combined = runErr.Error()
+		}
+		return nil, combined, nil
+	}
+	if combined == "" && parseErr != nil {
+		return nil, "", fmt.Errorf("unreadable output: %w", parseErr)
+	}
+	if combined == "" {
+		return nil, "", runErr
+	}
+	return nil, "", errors.New(cmdoutput.Tail(combined, 5))
+}
+
+func (a *Agent) exists(names ...string) bool {
+	for _, name := range names {
+		if _, err := os.Stat(filepath.Join(a.worktreePath, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// relative returns file relative to the worktree, as linters given
+// relative paths may still report absolute ones.
+func (a *Agent) relative(file string) string {
+	file = strings.TrimPrefix(file, "./")
+	if !filepath.IsAbs(file) {
+		return filepath.ToSlash(file)
+	}
+	for _, root := range []string{a.worktreePath, evalSymlinks(a.worktreePath)} {
+		if rel, err := filepath.Rel(root, file); err == nil && !strings.HasPrefix(rel, "..") {
+			return filepath.ToSlash(rel)
+		}
+	}
+	return file
+}
+
+func evalSymlinks(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return path
+}
+
+// textLine matches the file:line:col: message lines of golangci-lint and
+// most other linters.
+var textLine = regexp.MustCompile(`^(\S[^:]*):(\d+):(?:(\d+):)?\s+(.+)$`)
+
+// textRule matches the "(linter)" golangci-lint ends a message with.
+var textRule = regexp.MustCompile(`^(.*?)\s+\(([\w-]+)\)$`)
+
+// parseText reads file:line: message output.
+func parseText(output string) ([]Violation, error) {
+	var violations []Violation
+	for _, line := range strings.Split(output, "\n") {
+		m := textLine.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if m == nil {
+			continue
+		}
+		v := Violation{File: m[1], Message: m[4]}
+		v.Line, _ = strconv.Atoi(m[2])
+		v.Column, _ = strconv.Atoi(m[3])
+		if rm := textRule.FindStringSubmatch(v.Message); rm != nil {
+			v.Message, v.Rule = rm[1], rm[2]
+		}
+		violations = append(violations, v)
+	}
+	return violations, nil
+}
+
+// parseESLint reads eslint --format json output.
+func parseESLint(output string) ([]Violation, error) {
+	var files []struct {
+		FilePath string `json:"filePath"`
+		Messages []struct {
+			RuleID   string `json:"ruleId"`
+			Severity int    `json:"severity"`
+			Message  string `json:"message"`
+			Line     int    `json:"line"`
+			Column   int    `json:"column"`
+		} `json:"messages"`
+	}
+	if err := json.Unmarshal([]byte(jsonStart(output, '[')), &files); err != nil {
+		return nil, err
+	}
+	var violations []Violation
+	for _, f := range files {
+		for _, m := range f.Messages {
+			violations = append(violations, Violation{
+				File: f.FilePath, Line: m.Line, Column: m.Column,
+				Rule: m.RuleID, Message: m.Message, Warning: m.Severity < 2,
+			})
+		}
+	}
+	return violations, nil
+}
+
+// parseRuboCop reads rubocop --format json output.
+func parseRuboCop(output string) ([]Violation, error) {
+	var report struct {
+		Files []struct {
+			Path     string `json:"path"`
+			Offenses []struct {
+				CopName  string `json:"cop_name"`
+				Message  string `json:"message"`
+				Location struct {
+					Line   int `json:"line"`
+					Column int `json:"column"`
+				} `json:"location"`
+			} `json:"offenses"`
+		} `json:"files"`
+	}
+	if err := json.Unmarshal([]byte(jsonStart(output, '{')), &report); err != nil {
+		return nil, err
+	}
+	var violations []Violation
+	for _, f := range report.Files {
+		for _, o := range f.Offenses {
+			violations = append(violations, Violation{
+				File: f.Path, Line: o.Location.Line, Column: o.Location.Column,
+				Rule: o.CopName, Message: strings.TrimPrefix(o.Message, o.CopName+": "),
+			})
+		}
+	}
+	return violations, nil
+}
+
+// parseRuff reads ruff check --output-format json output.
+func parseRuff(output string) ([]Violation, error) {
+	var diagnostics []struct {
+		Code     string `json:"code"`
+		Message  string `json:"message"`
+		Filename string `json:"filename"`
+		Location struct {
+			Row    int `json:"row"`
+			Column int `json:"column"`
+		} `json:"location"`
+	}
+	if err := json.Unmarshal([]byte(jsonStart(output, '[')), &diagnostics); err != nil {
+		return nil, err
+	}
+	var violations []Violation
+	for _, d := range diagnostics {
+		violations = append(violations, Violation{
+			File: d.Filename, Line: d.Location.Row, Column: d.Location.Column,
+			Rule: d.Code, Message: d.Message,
+		})
+	}
+	return violations, nil
+}
+
+// jsonStart skips anything a wrapper such as bundler prints before the
+// JSON document.
+func jsonStart(output string, open byte) string {
+	if i := strings.IndexByte(output, open); i > 0 {
+		return output[i:]
+	}
+	return output
+}
+
+// Issues returns a review issue per violation, errors as major and
+// warnings as minor, and one for a command that failed without reporting
+// any. Past maxIssues the rest are summed up.
+func (r *Result) Issues() []scottbott.Issue {
+	var issues []scottbott.Issue
+	if r.Failure != "" {
+		issues = append(issues, scottbott.Issue{
+			Severity:    "major",
+			Description: "Lint fails: " + r.Failure,
+			Suggestion:  "Fix what the linter reports",
+		})
+	}
+	for i, v := range r.Violations {
+		if i == maxIssues {
+			issues = append(issues, scottbott.Issue{
+				Severity:    "major",
+				Description: fmt.Sprintf("%d more lint violations", len(r.Violations)-maxIssues),
+				Suggestion:  "Run the linters on the changed files and fix everything they report",
+			})
+			break
+		}
+		severity := "major"
+		if v.Warning {
+			severity = "minor"
+		}
+		description := fmt.Sprintf("%s: %s", v.Linter, v.Message)
+		if v.Rule != "" {
+			description = fmt.Sprintf("%s (%s): %s", v.Linter, v.Rule, v.Message)
+		}
+		issues = append(issues, scottbott.Issue{
+			Severity:    severity,
+			File:        v.File,
+			Line:        v.Line,
+			Description: description,
+			Suggestion:  "Fix the code rather than disabling the rule",
+		})
+	}
+	return issues
+}
+
+// Summary returns a one-line summary of the run.
+func (r *Result) Summary() string {
+	if len(r.Linters) == 0 {
+		return "no linter for the changed files"
+	}
+	linters := strings.Join(r.Linters, ", ")
+	if r.Passed && len(r.Violations) == 0 {
+		return linters + ": clean"
+	}
+	if r.Failure != "" && len(r.Violations) == 0 {
+		return linters + ": failed"
+	}
+	warnings := 0
+	for _, v := range r.Violations {
+		if v.Warning {
+			warnings++
+		}
+	}
+	return fmt.Sprintf("%s: %d violation(s), %d warning(s)", linters, len(r.Violations)-warnings, warnings)
+}
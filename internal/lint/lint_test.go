@@ -0,0 +1,154 @@
+package lint
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectLinters(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"name": "web"}`), 0644)
+	os.WriteFile(filepath.Join(dir, "eslint.config.js"), []byte("export default []\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "Gemfile"), []byte("gem 'rubocop', require: false\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "pyproject.toml"), []byte("[tool.ruff]\nline-length = 100\n"), 0644)
+
+	var names []string
+	for _, l := range New(dir).DetectLinters() {
+		names = append(names, l.Command+" "+strings.Join(l.Args, " "))
+	}
+	want := []string{
+		"npx --no-install eslint --format json",
+		"bundle exec rubocop --format json --force-exclusion",
+		"ruff check --output-format json --force-exclude",
+	}
+	if strings.Join(names, "\n") != strings.Join(want, "\n") {
+		t.Errorf("DetectLinters = %q, want %q", names, want)
+	}
+
+	a := New(dir)
+	a.SetCommand("make lint")
+	if linters := a.DetectLinters(); len(linters) != 1 || linters[0].Name != "custom" {
+		t.Errorf("DetectLinters with command = %+v", linters)
+	}
+	if linters := New(t.TempDir()).DetectLinters(); len(linters) != 0 {
+		t.Errorf("DetectLinters for unlinted project = %+v", linters)
+	}
+}
+
+func TestParseText(t *testing.T) {
+	output := `pkg/util/util.go:12:2: ineffectual assignment to err (ineffassign)
+	err = run()
+	^
+util.go:3: exported function Add should have comment
+1 issues:
+`
+	violations, _ := parseText(output)
+	if len(violations) != 2 {
+		t.Fatalf("violations = %+v", violations)
+	}
+	if v := violations[0]; v.File != "pkg/util/util.go" || v.Line != 12 || v.Column != 2 || v.Rule != "ineffassign" || v.Message != "ineffectual assignment to err" {
+		t.Errorf("violations[0] = %+v", v)
+	}
+	if v := violations[1]; v.Line != 3 || v.Rule != "" {
+		t.Errorf("violations[1] = %+v", v)
+	}
+}
+
+func TestParseJSON(t *testing.T) {
+	eslint := `[{"filePath":"/src/app.ts","messages":[
+		{"ruleId":"no-unused-vars","severity":2,"message":"'x' is defined but never used.","line":3,"column":7},
+		{"ruleId":"eqeqeq","severity":1,"message":"Expected '===' and instead saw '=='.","line":5,"column":9}]}]`
+	violations, err := parseESLint(eslint)
+	if err != nil || len(violations) != 2 || violations[0].Warning || !violations[1].Warning || violations[0].Rule != "no-unused-vars" {
+		t.Errorf("parseESLint = %+v, %v", violations, err)
+	}
+
+	rubocop := `Resolving dependencies...
+{"files":[{"path":"app/models/user.rb","offenses":[{"severity":"convention","message":"Style/StringLiterals: Prefer single-quoted strings.","cop_name":"Style/StringLiterals","location":{"line":2,"column":10}}]}]}`
+	violations, err = parseRuboCop(rubocop)
+	if err != nil || len(violations) != 1 || violations[0].Message != "Prefer single-quoted strings." || violations[0].Line != 2 {
+		t.Errorf("parseRuboCop = %+v, %v", violations, err)
+	}
+
+	ruff := `[{"code":"F401","message":"` + "`os`" + ` imported but unused","filename":"/repo/app.py","location":{"row":1,"column":8}}]`
+	violations, err = parseRuff(ruff)
+	if err != nil || len(violations) != 1 || violations[0].Rule != "F401" || violations[0].File != "/repo/app.py" {
+		t.Errorf("parseRuff = %+v, %v", violations, err)
+	}
+
+	if _, err := parseRuff("error: unexpected argument '--output-format'"); err == nil {
+		t.Error("parseRuff accepted non-JSON output")
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "ruff.toml"), []byte("line-length = 100\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "app.py"), []byte("import os\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "old.py"), []byte("import sys\n"), 0644)
+
+	// A fake ruff reporting a violation in a changed file and one in an
+	// untouched file
+	bin := t.TempDir()
+	output := `[{"code":"F401","message":"os imported but unused","filename":"` + filepath.Join(dir, "app.py") + `","location":{"row":1,"column":8}},` +
+		`{"code":"F401","message":"sys imported but unused","filename":"` + filepath.Join(dir, "old.py") + `","location":{"row":1,"column":8}}]`
+	os.WriteFile(filepath.Join(bin, "output"), []byte(output), 0644)
+	os.WriteFile(filepath.Join(bin, "ruff"), []byte("#!/bin/sh\ncat "+filepath.Join(bin, "output")+"\nexit 1\n"), 0755)
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	result, err := New(dir).Run(context.Background(), []string{"app.py", "README.md"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Passed || len(result.Violations) != 1 || result.Violations[0].File != "app.py" {
+		t.Fatalf("result = %+v", result)
+	}
+	issues := result.Issues()
+	if len(issues) != 1 || issues[0].Severity != "major" || issues[0].Description != "ruff (F401): os imported but unused" || issues[0].Line != 1 {
+		t.Errorf("issues = %+v", issues)
+	}
+
+	// Files the linters do not check are not linted
+	result, _ = New(dir).Run(context.Background(), []string{"README.md"})
+	if !result.Passed || len(result.Linters) != 0 {
+		t.Errorf("result without lintable files = %+v", result)
+	}
+
+	// A linter that cannot run is skipped
+	os.WriteFile(filepath.Join(bin, "ruff"), []byte("#!/bin/sh\necho 'ruff: unknown flag' >&2\nexit 2\n"), 0755)
+	result, _ = New(dir).Run(context.Background(), []string{"app.py"})
+	if !result.Passed || len(result.Skipped) != 1 || !strings.Contains(result.Skipped[0], "unknown flag") {
+		t.Errorf("result of broken linter = %+v", result)
+	}
+}
+
+func TestRunCommand(t *testing.T) {
+	dir := t.TempDir()
+	a := New(dir)
+	a.SetCommand("echo 'main.go:4:1: missing comment (revive)'; echo 'other.go:1:1: unused (unused)'; exit 1")
+	result, err := a.Run(context.Background(), []string{"main.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Passed || len(result.Violations) != 1 || result.Violations[0].Rule != "revive" {
+		t.Errorf("result = %+v", result)
+	}
+
+	// Failing without file:line output, the command's output is the issue
+	a.SetCommand("echo 'config: unknown linter foo'; exit 3")
+	result, _ = a.Run(context.Background(), []string{"main.go"})
+	if result.Passed || !strings.Contains(result.Failure, "unknown linter foo") {
+		t.Errorf("result = %+v", result)
+	}
+	if issues := result.Issues(); len(issues) != 1 || !strings.HasPrefix(issues[0].Description, "Lint fails: ") {
+		t.Errorf("issues = %+v", issues)
+	}
+
+	a.SetCommand("echo 'main.go:4: looks fine'")
+	if result, _ := a.Run(context.Background(), []string{"main.go"}); !result.Passed || len(result.Violations) != 0 {
+		t.Errorf("passing command = %+v", result)
+	}
+}
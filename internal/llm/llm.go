@@ -0,0 +1,152 @@
+// Package llm lets agents talk to model backends other than the Claude CLI:
+// the Anthropic API, OpenAI (or any OpenAI-compatible server) and local
+// Ollama models. Backends are plain completions: they cannot use tools, so
+// agents that edit files ask for complete files in their reply instead.
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/cost"
+	"github.com/philjestin/boatmanmode/internal/secrets"
+)
+
+// Provider names for provider.name.
+const (
+	ClaudeCLI = "claude-cli"
+	Anthropic = "anthropic"
+	OpenAI    = "openai"
+	Ollama    = "ollama"
+)
+
+// DefaultMaxTokens caps replies when provider.max_tokens is unset.
+const DefaultMaxTokens = 8192
+
+// Request is a single-turn completion request.
+type Request struct {
+	// Model to use; empty uses the provider's default.
+	Model     string
+	System    string
+	Prompt    string
+	MaxTokens int
+	// Sampling parameters; unset ones are left to the provider.
+	Sampling config.SamplingConfig
+}
+
+// Provider completes prompts with a model backend.
+type Provider interface {
+	// Name identifies the provider in logs and errors.
+	Name() string
+
+	// Complete returns the model's reply and the usage it reported.
+	Complete(ctx context.Context, req Request) (string, *cost.Usage, error)
+}
+
+// New returns the provider cfg selects, or nil for the Claude CLI, which
+// agents run themselves.
+func New(cfg config.ProviderConfig) (Provider, error) {
+	maxTokens := cfg.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = DefaultMaxTokens
+	}
+	switch strings.ToLower(cfg.Name) {
+	case "", ClaudeCLI:
+		return nil, nil
+	case Anthropic:
+		key, err := apiKey(cfg, "ANTHROPIC_API_KEY")
+		if err != nil {
+			return nil, err
+		}
+		if key == "" {
+			return nil, fmt.Errorf("provider anthropic needs provider.api_key or ANTHROPIC_API_KEY")
+		}
+		return &anthropicProvider{http: newHTTP(cfg.BaseURL, "https://api.anthropic.com"), key: key, maxTokens: maxTokens}, nil
+	case OpenAI:
+		key, err := apiKey(cfg, "OPENAI_API_KEY")
+		if err != nil {
+			return nil, err
+		}
+		if key == "" && cfg.BaseURL == "" {
+			return nil, fmt.Errorf("provider openai needs provider.api_key or OPENAI_API_KEY")
+		}
+		return &openAIProvider{http: newHTTP(cfg.BaseURL, "https://api.openai.com"), key: key, maxTokens: cfg.MaxTokens}, nil
+	case Ollama:
+		return &ollamaProvider{http: newHTTP(cfg.BaseURL, "http://localhost:11434")}, nil
+	default:
+		return nil, fmt.Errorf("unknown provider %q (want %s, %s, %s or %s)", cfg.Name, ClaudeCLI, Anthropic, OpenAI, Ollama)
+	}
+}
+
+// apiKey returns the provider's key, resolving one kept in a secret store.
+func apiKey(cfg config.ProviderConfig, env string) (string, error) {
+	key := os.Getenv(env)
+	if cfg.APIKey != "" {
+		key = os.ExpandEnv(cfg.APIKey)
+	}
+	secret, err := secrets.Resolve(key)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the provider API key: %w", err)
+	}
+	return secret, nil
+}
+
+// httpClient posts JSON to a provider's API.
+type httpClient struct {
+	baseURL string
+	client  *http.Client
+}
+
+func newHTTP(baseURL, defaultURL string) httpClient {
+	if baseURL == "" {
+		baseURL = defaultURL
+	}
+	return httpClient{baseURL: strings.TrimSuffix(baseURL, "/"), client: http.DefaultClient}
+}
+
+// post sends body to path and decodes the JSON reply into out.
+func (h httpClient) post(ctx context.Context, path string, headers map[string]string, body, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.baseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: %s", resp.Status, truncate(strings.TrimSpace(string(respBody)), 500))
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+	return nil
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + "..."
+}
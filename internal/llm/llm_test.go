@@ -0,0 +1,249 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/cost"
+)
+
+// server records the request it receives and replies with reply.
+func server(t *testing.T, path, reply string, got *map[string]any, header *http.Header) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != path {
+			t.Errorf("path = %s, want %s", r.URL.Path, path)
+		}
+		if header != nil {
+			*header = r.Header.Clone()
+		}
+		if err := json.NewDecoder(r.Body).Decode(got); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		w.Write([]byte(reply))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestNew(t *testing.T) {
+	t.Setenv("ANTHROPIC_API_KEY", "")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	for _, name := range []string{"", "claude-cli"} {
+		p, err := New(config.ProviderConfig{Name: name})
+		if err != nil || p != nil {
+			t.Errorf("New(%q) = %v, %v; want nil, nil", name, p, err)
+		}
+	}
+	if _, err := New(config.ProviderConfig{Name: "anthropic"}); err == nil || !strings.Contains(err.Error(), "ANTHROPIC_API_KEY") {
+		t.Errorf("anthropic without key: err = %v", err)
+	}
+	if _, err := New(config.ProviderConfig{Name: "openai"}); err == nil {
+		t.Error("openai without key or base URL should fail")
+	}
+	if p, err := New(config.ProviderConfig{Name: "openai", BaseURL: "http://localhost:8000"}); err != nil || p.Name() != OpenAI {
+		t.Errorf("openai-compatible server: %v, %v", p, err)
+	}
+	if p, err := New(config.ProviderConfig{Name: "Ollama"}); err != nil || p.Name() != Ollama {
+		t.Errorf("ollama: %v, %v", p, err)
+	}
+	if _, err := New(config.ProviderConfig{Name: "gemini"}); err == nil || !strings.Contains(err.Error(), "unknown provider") {
+		t.Errorf("unknown provider: err = %v", err)
+	}
+
+	t.Setenv("MY_KEY", "sk-env")
+	p, err := New(config.ProviderConfig{Name: "anthropic", APIKey: "$MY_KEY"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key := p.(*anthropicProvider).key; key != "sk-env" {
+		t.Errorf("key = %q, want expanded $MY_KEY", key)
+	}
+}
+
+func TestAnthropic(t *testing.T) {
+	var got map[string]any
+	var header http.Header
+	srv := server(t, "/v1/messages",
+		`{"content":[{"type":"text","text":" Hello "}],"usage":{"input_tokens":12,"output_tokens":3,"cache_read_input_tokens":4}}`,
+		&got, &header)
+
+	p, err := New(config.ProviderConfig{Name: "anthropic", BaseURL: srv.URL + "/", APIKey: "sk-test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, usage, err := p.Complete(context.Background(), Request{System: "Be brief", Prompt: "Hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "Hello" {
+		t.Errorf("text = %q", text)
+	}
+	if usage.InputTokens != 12 || usage.OutputTokens != 3 || usage.CacheReadTokens != 4 {
+		t.Errorf("usage = %+v", usage)
+	}
+	// claude-sonnet-4-5: (12 + 0.1*4) input tokens at $3/M, 3 output at $15/M
+	if want := 82.2e-6; math.Abs(usage.TotalCostUSD-want) > 1e-12 {
+		t.Errorf("cost = %g, want %g", usage.TotalCostUSD, want)
+	}
+	if header.Get("x-api-key") != "sk-test" || header.Get("anthropic-version") == "" {
+		t.Errorf("headers = %v", header)
+	}
+	if got["model"] != defaultAnthropicModel || got["system"] != "Be brief" || got["max_tokens"] != float64(DefaultMaxTokens) {
+		t.Errorf("request = %v", got)
+	}
+}
+
+func TestOpenAI(t *testing.T) {
+	var got map[string]any
+	var header http.Header
+	srv := server(t, "/v1/chat/completions",
+		`{"choices":[{"message":{"content":"Done"}}],"usage":{"prompt_tokens":20,"completion_tokens":5}}`,
+		&got, &header)
+
+	p, err := New(config.ProviderConfig{Name: "openai", BaseURL: srv.URL, APIKey: "sk-test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, usage, err := p.Complete(context.Background(), Request{Model: "gpt-4.1", System: "sys", Prompt: "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "Done" || usage.InputTokens != 20 || usage.OutputTokens != 5 {
+		t.Errorf("text = %q, usage = %+v", text, usage)
+	}
+	if want := 80e-6; math.Abs(usage.TotalCostUSD-want) > 1e-12 {
+		t.Errorf("cost = %g, want %g", usage.TotalCostUSD, want)
+	}
+	if header.Get("Authorization") != "Bearer sk-test" {
+		t.Errorf("Authorization = %q", header.Get("Authorization"))
+	}
+	messages, _ := got["messages"].([]any)
+	if got["model"] != "gpt-4.1" || len(messages) != 2 {
+		t.Errorf("request = %v", got)
+	}
+	if _, ok := got["max_tokens"]; ok {
+		t.Error("max_tokens sent without provider.max_tokens")
+	}
+}
+
+func TestOllama(t *testing.T) {
+	var got map[string]any
+	srv := server(t, "/api/chat",
+		`{"message":{"role":"assistant","content":"Local"},"prompt_eval_count":7,"eval_count":2}`,
+		&got, nil)
+
+	p, err := New(config.ProviderConfig{Name: "ollama", BaseURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, usage, err := p.Complete(context.Background(), Request{Prompt: "Hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "Local" || usage.InputTokens != 7 || usage.OutputTokens != 2 || usage.TotalCostUSD != 0 {
+		t.Errorf("text = %q, usage = %+v", text, usage)
+	}
+	if got["stream"] != false || got["model"] != defaultOllamaModel {
+		t.Errorf("request = %v", got)
+	}
+}
+
+func TestCost(t *testing.T) {
+	usage := cost.Usage{InputTokens: 1_000_000, OutputTokens: 1_000_000}
+	for model, want := range map[string]float64{
+		"claude-sonnet-4-5-20250929": 18,
+		"claude-opus-4-1":            90,
+		"claude-opus-4-5":            30,
+		"gpt-4o-mini":                0.75,
+		"gpt-4o-2024-08-06":          12.5,
+	} {
+		if got, ok := Cost(model, usage); !ok || got != want {
+			t.Errorf("Cost(%s) = %g, %v; want %g", model, got, ok, want)
+		}
+	}
+	if got, ok := Cost("qwen2.5-coder", usage); ok || got != 0 {
+		t.Errorf("Cost of an unpriced model = %g, %v; want 0, false", got, ok)
+	}
+
+	for _, tt := range []struct {
+		provider, model string
+		want            bool
+	}{
+		{"", "", true},
+		{"anthropic", "", true},
+		{"openai", "gpt-4.1-mini", true},
+		{"openai", "mistral-large", false},
+		{"ollama", "llama3.1", false},
+	} {
+		if got := Priced(tt.provider, tt.model); got != tt.want {
+			t.Errorf("Priced(%q, %q) = %v, want %v", tt.provider, tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestSampling(t *testing.T) {
+	temperature, topP, seed := 0.0, 0.9, 7
+	sampling := config.SamplingConfig{Temperature: &temperature, TopP: &topP, Seed: &seed}
+	providers := []struct{ name, path, reply string }{
+		{"anthropic", "/v1/messages", `{"content":[]}`},
+		{"openai", "/v1/chat/completions", `{"choices":[{"message":{"content":""}}]}`},
+		{"ollama", "/api/chat", `{"message":{}}`},
+	}
+	for _, tc := range providers {
+		var got map[string]any
+		srv := server(t, tc.path, tc.reply, &got, nil)
+		p, err := New(config.ProviderConfig{Name: tc.name, BaseURL: srv.URL, APIKey: "sk-test"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := p.Complete(context.Background(), Request{Prompt: "Hi", Sampling: sampling}); err != nil {
+			t.Fatal(err)
+		}
+		params := got
+		if tc.name == "ollama" {
+			params, _ = got["options"].(map[string]any)
+		}
+		if params["temperature"] != 0.0 || params["top_p"] != 0.9 {
+			t.Errorf("%s: request = %v", tc.name, got)
+		}
+		// Anthropic has no seed
+		if _, ok := params["seed"]; ok != (tc.name != "anthropic") {
+			t.Errorf("%s: seed sent = %v", tc.name, ok)
+		}
+	}
+
+	// Unset parameters are left out
+	var got map[string]any
+	srv := server(t, "/api/chat", `{"message":{}}`, &got, nil)
+	p, _ := New(config.ProviderConfig{Name: "ollama", BaseURL: srv.URL})
+	if _, _, err := p.Complete(context.Background(), Request{Prompt: "Hi"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := got["options"]; ok {
+		t.Errorf("options sent without sampling parameters: %v", got)
+	}
+}
+
+func TestCompleteError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":{"message":"invalid x-api-key"}}`, http.StatusUnauthorized)
+	}))
+	defer srv.Close()
+
+	p, err := New(config.ProviderConfig{Name: "anthropic", BaseURL: srv.URL, APIKey: "bad"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = p.Complete(context.Background(), Request{Prompt: "Hi"})
+	if err == nil || !strings.Contains(err.Error(), "401") || !strings.Contains(err.Error(), "invalid x-api-key") {
+		t.Errorf("err = %v", err)
+	}
+}
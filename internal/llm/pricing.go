@@ -0,0 +1,98 @@
+package llm
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/cost"
+)
+
+// price is a model's list price in USD per million tokens.
+type price struct {
+	input, output float64
+}
+
+// prices are the list prices of the API models, keyed by model name prefix
+// so dated snapshots such as claude-sonnet-4-5-20250929 match. The longest
+// matching prefix wins. Ollama models run locally and are not priced.
+var prices = map[string]price{
+	"claude-opus-4":     {15, 75},
+	"claude-opus-4-5":   {5, 25},
+	"claude-sonnet-4":   {3, 15},
+	"claude-3-7-sonnet": {3, 15},
+	"claude-3-5-sonnet": {3, 15},
+	"claude-haiku-4":    {1, 5},
+	"claude-3-5-haiku":  {0.8, 4},
+	"claude-3-haiku":    {0.25, 1.25},
+	"gpt-4o":            {2.5, 10},
+	"gpt-4o-mini":       {0.15, 0.6},
+	"gpt-4.1":           {2, 8},
+	"gpt-4.1-mini":      {0.4, 1.6},
+	"gpt-4.1-nano":      {0.1, 0.4},
+	"o3":                {2, 8},
+	"o3-mini":           {1.1, 4.4},
+	"o4-mini":           {1.1, 4.4},
+}
+
+// lookupPrice returns the price of model by its longest matching prefix.
+func lookupPrice(model string) (price, bool) {
+	var best string
+	for prefix := range prices {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+	p, ok := prices[best]
+	return p, ok
+}
+
+// Cost returns what u costs on model at list price, with cache reads at a
+// tenth and cache writes at 1.25 times the input price. ok is false when
+// the model's price is unknown, and the cost is then 0.
+func Cost(model string, u cost.Usage) (usd float64, ok bool) {
+	p, ok := lookupPrice(model)
+	if !ok {
+		return 0, false
+	}
+	input := float64(u.InputTokens) + 0.1*float64(u.CacheReadTokens) + 1.25*float64(u.CacheWriteTokens)
+	return (input*p.input + float64(u.OutputTokens)*p.output) / 1e6, true
+}
+
+// Priced reports whether the provider reports what model costs, so a cost
+// budget can be enforced with it. An empty model is the provider's default.
+func Priced(provider, model string) bool {
+	switch strings.ToLower(provider) {
+	case "", ClaudeCLI:
+		return true // The CLI reports the cost itself
+	case Anthropic:
+		model = orDefault(model, defaultAnthropicModel)
+	case OpenAI:
+		model = orDefault(model, defaultOpenAIModel)
+	default:
+		return false
+	}
+	_, ok := lookupPrice(model)
+	return ok
+}
+
+// UnpricedModels returns the models cfg runs agents on whose cost its
+// provider does not report; spend on them does not count toward
+// max_cost_usd. The provider's default model is "default".
+func UnpricedModels(cfg *config.Config) []string {
+	m := cfg.Claude.Models
+	var unpriced []string
+	for _, model := range []string{m.Planner, m.Executor, m.Reviewer, m.Refactor, m.Preflight,
+		m.Retro, m.Verifier, m.TestFixer, m.Vision, cfg.Review.FallbackModel} {
+		if Priced(cfg.Claude.Provider.Name, model) {
+			continue
+		}
+		if model == "" {
+			model = "default"
+		}
+		if !slices.Contains(unpriced, model) {
+			unpriced = append(unpriced, model)
+		}
+	}
+	return unpriced
+}
@@ -0,0 +1,175 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/cost"
+)
+
+// Default models, used when an agent has no model configured.
+const (
+	defaultAnthropicModel = "claude-sonnet-4-5"
+	defaultOpenAIModel    = "gpt-4o"
+	defaultOllamaModel    = "llama3.1"
+)
+
+type anthropicProvider struct {
+	http      httpClient
+	key       string
+	maxTokens int
+}
+
+func (p *anthropicProvider) Name() string { return Anthropic }
+
+func (p *anthropicProvider) Complete(ctx context.Context, req Request) (string, *cost.Usage, error) {
+	model := orDefault(req.Model, defaultAnthropicModel)
+	body := map[string]any{
+		"model":      model,
+		"max_tokens": orDefaultInt(req.MaxTokens, p.maxTokens),
+		"messages":   []map[string]string{{"role": "user", "content": req.Prompt}},
+	}
+	if req.System != "" {
+		body["system"] = req.System
+	}
+	// The Messages API has no seed
+	setSampling(body, req.Sampling, false)
+	var resp struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		Usage cost.Usage `json:"usage"`
+	}
+	headers := map[string]string{"x-api-key": p.key, "anthropic-version": "2023-06-01"}
+	if err := p.http.post(ctx, "/v1/messages", headers, body, &resp); err != nil {
+		return "", nil, fmt.Errorf("anthropic request failed: %w", err)
+	}
+	var text strings.Builder
+	for _, block := range resp.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	// The Messages API reports tokens only, so the cost is priced here
+	resp.Usage.TotalCostUSD, _ = Cost(model, resp.Usage)
+	return strings.TrimSpace(text.String()), &resp.Usage, nil
+}
+
+type openAIProvider struct {
+	http      httpClient
+	key       string
+	maxTokens int // 0 leaves the limit to the server
+}
+
+func (p *openAIProvider) Name() string { return OpenAI }
+
+func (p *openAIProvider) Complete(ctx context.Context, req Request) (string, *cost.Usage, error) {
+	model := orDefault(req.Model, defaultOpenAIModel)
+	body := map[string]any{
+		"model":    model,
+		"messages": chatMessages(req),
+	}
+	if n := orDefaultInt(req.MaxTokens, p.maxTokens); n > 0 {
+		body["max_tokens"] = n
+	}
+	setSampling(body, req.Sampling, true)
+	var resp struct {
+		Choices []struct {
+			Message struct {
+				Content string `json:"content"`
+			} `json:"message"`
+		} `json:"choices"`
+		Usage struct {
+			PromptTokens     int `json:"prompt_tokens"`
+			CompletionTokens int `json:"completion_tokens"`
+		} `json:"usage"`
+	}
+	var headers map[string]string
+	if p.key != "" {
+		headers = map[string]string{"Authorization": "Bearer " + p.key}
+	}
+	if err := p.http.post(ctx, "/v1/chat/completions", headers, body, &resp); err != nil {
+		return "", nil, fmt.Errorf("openai request failed: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return "", nil, fmt.Errorf("openai returned no choices")
+	}
+	usage := &cost.Usage{InputTokens: resp.Usage.PromptTokens, OutputTokens: resp.Usage.CompletionTokens}
+	usage.TotalCostUSD, _ = Cost(model, *usage)
+	return strings.TrimSpace(resp.Choices[0].Message.Content), usage, nil
+}
+
+type ollamaProvider struct {
+	http httpClient
+}
+
+func (p *ollamaProvider) Name() string { return Ollama }
+
+func (p *ollamaProvider) Complete(ctx context.Context, req Request) (string, *cost.Usage, error) {
+	body := map[string]any{
+		"model":    orDefault(req.Model, defaultOllamaModel),
+		"messages": chatMessages(req),
+		"stream":   false,
+	}
+	if options := map[string]any{}; setSampling(options, req.Sampling, true) {
+		body["options"] = options
+	}
+	var resp struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+		PromptEvalCount int `json:"prompt_eval_count"`
+		EvalCount       int `json:"eval_count"`
+	}
+	if err := p.http.post(ctx, "/api/chat", nil, body, &resp); err != nil {
+		return "", nil, fmt.Errorf("ollama request failed: %w", err)
+	}
+	// Local models cost nothing
+	usage := &cost.Usage{InputTokens: resp.PromptEvalCount, OutputTokens: resp.EvalCount}
+	return strings.TrimSpace(resp.Message.Content), usage, nil
+}
+
+// setSampling sets the sampling parameters that are set in params, the
+// seed only if withSeed, reporting whether any was.
+func setSampling(params map[string]any, s config.SamplingConfig, withSeed bool) bool {
+	set := false
+	if s.Temperature != nil {
+		params["temperature"] = *s.Temperature
+		set = true
+	}
+	if s.TopP != nil {
+		params["top_p"] = *s.TopP
+		set = true
+	}
+	if s.Seed != nil && withSeed {
+		params["seed"] = *s.Seed
+		set = true
+	}
+	return set
+}
+
+// chatMessages builds the system and user messages of a chat request.
+func chatMessages(req Request) []map[string]string {
+	var messages []map[string]string
+	if req.System != "" {
+		messages = append(messages, map[string]string{"role": "system", "content": req.System})
+	}
+	return append(messages, map[string]string{"role": "user", "content": req.Prompt})
+}
+
+func orDefault(s, def string) string {
+	if s != "" {
+		return s
+	}
+	return def
+}
+
+func orDefaultInt(n, def int) int {
+	if n > 0 {
+		return n
+	}
+	return def
+}
@@ -0,0 +1,202 @@
+// Package localize writes the text boatman produces for people, such as PR
+// bodies, in the team's language: headings come from built-in translations
+// and everything else is translated by the model.
+package localize
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/claude"
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/cost"
+)
+
+// Labels are the fixed strings of a PR body.
+type Labels struct {
+	Ticket           string
+	Task             string
+	Description      string
+	Changes          string
+	Quality          string
+	ReviewIterations string
+	Tests            string
+	Coverage         string
+	E2E              string
+	// PromptTask and FileTask describe tasks without a ticket
+	PromptTask string
+	FileTask   string
+	Footer     string
+}
+
+var english = Labels{
+	Ticket:           "Ticket",
+	Task:             "Task",
+	Description:      "Description",
+	Changes:          "Changes",
+	Quality:          "Quality",
+	ReviewIterations: "Review iterations",
+	Tests:            "Tests",
+	Coverage:         "Coverage",
+	E2E:              "E2E tests",
+	PromptTask:       "Prompt-based task",
+	FileTask:         "File-based task",
+	Footer:           "Automated by BoatmanMode 🚣",
+}
+
+// builtin holds translated labels by language code.
+var builtin = map[string]Labels{
+	"en": english,
+	"ja": {
+		Ticket:           "チケット",
+		Task:             "タスク",
+		Description:      "説明",
+		Changes:          "変更内容",
+		Quality:          "品質",
+		ReviewIterations: "レビュー回数",
+		Tests:            "テスト",
+		Coverage:         "カバレッジ",
+		E2E:              "E2Eテスト",
+		PromptTask:       "プロンプトによるタスク",
+		FileTask:         "ファイルによるタスク",
+		Footer:           "BoatmanMode による自動作成 🚣",
+	},
+	"es": {
+		Ticket:           "Ticket",
+		Task:             "Tarea",
+		Description:      "Descripción",
+		Changes:          "Cambios",
+		Quality:          "Calidad",
+		ReviewIterations: "Iteraciones de revisión",
+		Tests:            "Pruebas",
+		Coverage:         "Cobertura",
+		E2E:              "Pruebas E2E",
+		PromptTask:       "Tarea desde un prompt",
+		FileTask:         "Tarea desde un archivo",
+		Footer:           "Automatizado por BoatmanMode 🚣",
+	},
+	"fr": {
+		Ticket:           "Ticket",
+		Task:             "Tâche",
+		Description:      "Description",
+		Changes:          "Modifications",
+		Quality:          "Qualité",
+		ReviewIterations: "Itérations de revue",
+		Tests:            "Tests",
+		Coverage:         "Couverture",
+		E2E:              "Tests E2E",
+		PromptTask:       "Tâche issue d'un prompt",
+		FileTask:         "Tâche issue d'un fichier",
+		Footer:           "Automatisé par BoatmanMode 🚣",
+	},
+	"de": {
+		Ticket:           "Ticket",
+		Task:             "Aufgabe",
+		Description:      "Beschreibung",
+		Changes:          "Änderungen",
+		Quality:          "Qualität",
+		ReviewIterations: "Review-Durchläufe",
+		Tests:            "Tests",
+		Coverage:         "Abdeckung",
+		E2E:              "E2E-Tests",
+		PromptTask:       "Aufgabe aus einem Prompt",
+		FileTask:         "Aufgabe aus einer Datei",
+		Footer:           "Automatisiert von BoatmanMode 🚣",
+	},
+}
+
+// names maps language names people write in config to codes.
+var names = map[string]string{
+	"english":  "en",
+	"japanese": "ja",
+	"日本語":      "ja",
+	"spanish":  "es",
+	"español":  "es",
+	"french":   "fr",
+	"français": "fr",
+	"german":   "de",
+	"deutsch":  "de",
+}
+
+// Code normalizes a configured language ("Japanese", "ja-JP", "ja") to its
+// lowercase base code. Languages it does not know are returned lowercased.
+func Code(language string) string {
+	lang := strings.ToLower(strings.TrimSpace(language))
+	if code, ok := names[lang]; ok {
+		return code
+	}
+	if base, _, ok := strings.Cut(strings.ReplaceAll(lang, "_", "-"), "-"); ok && len(base) == 2 {
+		return base
+	}
+	return lang
+}
+
+// IsEnglish reports whether language is English or unset.
+func IsEnglish(language string) bool {
+	code := Code(language)
+	return code == "" || code == "en"
+}
+
+// LabelsFor returns the labels for language, falling back to English ones
+// for languages without built-in labels; the translator covers those.
+func LabelsFor(language string) Labels {
+	if labels, ok := builtin[Code(language)]; ok {
+		return labels
+	}
+	return english
+}
+
+// Translator is a Claude agent that translates markdown.
+type Translator struct {
+	client *claude.Client
+}
+
+// New creates a Translator. Translation is cheap work, so it runs on the
+// preflight model.
+func New(worktreePath string, cfg *config.Config) *Translator {
+	client := claude.NewWithTmux(worktreePath, "translate")
+	if cfg.Claude.Models.Preflight != "" {
+		client.Model = cfg.Claude.Models.Preflight
+	}
+	client.Sampling = cfg.Claude.SamplingFor("preflight")
+	client.Configure(cfg.Claude)
+	return &Translator{client: client}
+}
+
+// Translate returns markdown translated into language, with its structure,
+// links, code and identifiers unchanged.
+func (t *Translator) Translate(ctx context.Context, markdown, language string) (string, *cost.Usage, error) {
+	systemPrompt := fmt.Sprintf(`You translate pull request descriptions into %s.
+
+Translate all prose, headings included, into natural %s as a developer on the team would write it.
+Keep unchanged: the markdown structure, links and URLs, code spans and blocks, file paths,
+identifiers, ticket IDs, numbers, and emoji.
+
+Output only the translated markdown, with no commentary.`, language, language)
+
+	response, usage, err := t.client.Message(ctx, systemPrompt, markdown)
+	if err != nil {
+		return "", usage, fmt.Errorf("translator failed: %w", err)
+	}
+	translated := unfence(response)
+	if translated == "" {
+		return "", usage, fmt.Errorf("translator returned nothing")
+	}
+	return translated, usage, nil
+}
+
+var fencePattern = regexp.MustCompile("(?s)^```(?:markdown|md)?\\s*\\n(.*?)\\n?```$")
+
+// unfence strips a code fence the model wrapped its whole answer in.
+func unfence(response string) string {
+	response = strings.TrimSpace(response)
+	if m := fencePattern.FindStringSubmatch(response); m != nil {
+		return strings.TrimSpace(m[1]) + "\n"
+	}
+	if response == "" {
+		return ""
+	}
+	return response + "\n"
+}
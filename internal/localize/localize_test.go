@@ -0,0 +1,43 @@
+package localize
+
+import "testing"
+
+func TestCode(t *testing.T) {
+	for in, want := range map[string]string{
+		"":         "",
+		"ja":       "ja",
+		"ja-JP":    "ja",
+		"pt_BR":    "pt",
+		"Japanese": "ja",
+		"Deutsch":  "de",
+		"klingon":  "klingon",
+	} {
+		if got := Code(in); got != want {
+			t.Errorf("Code(%q) = %q, want %q", in, got, want)
+		}
+	}
+	if !IsEnglish("") || !IsEnglish("en-GB") || IsEnglish("ja") {
+		t.Error("Unexpected IsEnglish results")
+	}
+}
+
+func TestLabelsFor(t *testing.T) {
+	if got := LabelsFor("ja-JP").Description; got != "説明" {
+		t.Errorf("Expected Japanese labels, got %q", got)
+	}
+	if got := LabelsFor("Italian").Description; got != "Description" {
+		t.Errorf("Expected English labels for a language without built-ins, got %q", got)
+	}
+}
+
+func TestUnfence(t *testing.T) {
+	for in, want := range map[string]string{
+		"```markdown\n## 説明\n本文\n```": "## 説明\n本文\n",
+		"## 説明\n本文\n\n":               "## 説明\n本文\n",
+		"  ":                          "",
+	} {
+		if got := unfence(in); got != want {
+			t.Errorf("unfence(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,253 @@
+// Package manifest records the conditions a run worked under: the boatman
+// version, the settings, the models, the repo's rules files and the base
+// commit. Each run saves its manifest, and its PR carries it as a hidden
+// HTML comment, so the run can be audited or reproduced later.
+package manifest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+)
+
+// version is recorded in each manifest; set by the CLI at startup.
+var version = "dev"
+
+// SetVersion sets the boatman version recorded in manifests.
+func SetVersion(v string) {
+	version = v
+}
+
+// marker opens the HTML comment carrying a manifest in a PR body.
+const marker = "boatman-manifest"
+
+// rulesFiles are the patterns, relative to the worktree, of the files that
+// steer the agents: project rules and Claude agents and skills.
+var rulesFiles = []string{
+	"CLAUDE.md", "AGENTS.md", ".cursorrules",
+	"packs/*/CLAUDE.md",
+	".claude/agents/*.md",
+	".claude/skills/*/SKILL.md",
+}
+
+// credential matches the names of settings left out of the config hash, so
+// runs with the same settings hash alike whoever's credentials they use.
+var credential = regexp.MustCompile(`(?i)(key|token|secret|password)$`)
+
+// Manifest is the record of one run's conditions.
+type Manifest struct {
+	RunID      string              `json:"run_id"`
+	Ticket     string              `json:"ticket"`
+	CreatedAt  time.Time           `json:"created_at"`
+	Version    string              `json:"boatman_version"`
+	ConfigHash string              `json:"config_hash"`
+	Provider   string              `json:"provider"`
+	ClaudeCLI  string              `json:"claude_cli,omitempty"`
+	Models     map[string]string   `json:"models"`
+	Sampling   map[string]Sampling `json:"sampling,omitempty"` // Only API providers apply it
+	Rules      map[string]string   `json:"rules,omitempty"`    // Path → SHA-256
+	BaseBranch string              `json:"base_branch"`
+	BaseCommit string              `json:"base_commit"`
+}
+
+// Sampling is a role's sampling parameters; unset ones were left to the
+// provider.
+type Sampling struct {
+	Temperature *float64 `json:"temperature,omitempty"`
+	TopP        *float64 `json:"top_p,omitempty"`
+	Seed        *int     `json:"seed,omitempty"`
+}
+
+// New records the conditions of run runID of ticket, working in worktree
+// from baseCommit.
+func New(cfg *config.Config, runID, ticket, worktree, baseCommit string) *Manifest {
+	m := &Manifest{
+		RunID:      runID,
+		Ticket:     ticket,
+		CreatedAt:  time.Now().UTC(),
+		Version:    version,
+		ConfigHash: ConfigHash(cfg),
+		Provider:   cfg.Claude.Provider.Name,
+		Models:     models(cfg),
+		Sampling:   sampling(cfg),
+		Rules:      HashRules(worktree),
+		BaseBranch: cfg.BaseBranch,
+		BaseCommit: baseCommit,
+	}
+	if m.Provider == "" || m.Provider == "claude-cli" {
+		m.ClaudeCLI = cliVersion(cfg.Claude.Command)
+	}
+	return m
+}
+
+// ConfigHash returns the SHA-256 of the effective settings, without
+// credentials.
+func ConfigHash(cfg *config.Config) string {
+	data, _ := json.Marshal(cfg)
+	var settings any
+	json.Unmarshal(data, &settings)
+	data, _ = json.Marshal(withoutCredentials(settings))
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// withoutCredentials drops the credential settings from decoded JSON.
+func withoutCredentials(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if credential.MatchString(key) {
+				delete(v, key)
+			} else {
+				v[key] = withoutCredentials(value)
+			}
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = withoutCredentials(value)
+		}
+	}
+	return v
+}
+
+// models returns the model of each role, "default" for the CLI's.
+func models(cfg *config.Config) map[string]string {
+	m := cfg.Claude.Models
+	roles := map[string]string{
+		"planner":         m.Planner,
+		"executor":        m.Executor,
+		"reviewer":        m.Reviewer,
+		"refactor":        m.Refactor,
+		"preflight":       m.Preflight,
+		"test_runner":     m.TestRunner,
+		"retro":           m.Retro,
+		"verifier":        m.Verifier,
+		"test_fixer":      m.TestFixer,
+		"vision":          m.Vision,
+		"review_fallback": cfg.Review.FallbackModel,
+	}
+	for role, model := range roles {
+		if model == "" {
+			roles[role] = "default"
+		}
+	}
+	if roles["review_fallback"] == "default" {
+		roles["review_fallback"] = roles["preflight"]
+	}
+	return roles
+}
+
+// sampling returns the sampling parameters set for each role.
+func sampling(cfg *config.Config) map[string]Sampling {
+	var roles map[string]Sampling
+	for role, s := range cfg.Claude.Sampling {
+		if s.IsEmpty() {
+			continue
+		}
+		if roles == nil {
+			roles = make(map[string]Sampling)
+		}
+		roles[role] = Sampling{Temperature: s.Temperature, TopP: s.TopP, Seed: s.Seed}
+	}
+	return roles
+}
+
+// cliVersion returns the output of claude --version, or "" if it fails.
+func cliVersion(command string) string {
+	if command == "" {
+		command = "claude"
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, command, "--version").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// HashRules returns the SHA-256 of each rules file in worktree, keyed by
+// its slash-separated path.
+func HashRules(worktree string) map[string]string {
+	hashes := make(map[string]string)
+	for _, pattern := range rulesFiles {
+		matches, _ := filepath.Glob(filepath.Join(worktree, filepath.FromSlash(pattern)))
+		for _, path := range matches {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			rel, _ := filepath.Rel(worktree, path)
+			sum := sha256.Sum256(data)
+			hashes[filepath.ToSlash(rel)] = hex.EncodeToString(sum[:])
+		}
+	}
+	if len(hashes) == 0 {
+		return nil
+	}
+	return hashes
+}
+
+// Dir returns the directory manifests are saved in, ~/.boatman/manifests.
+func Dir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".boatman", "manifests")
+}
+
+// Save writes the manifest to <run ID>.json in dir (default: Dir()),
+// returning its path.
+func (m *Manifest) Save(dir string) (string, error) {
+	if dir == "" {
+		dir = Dir()
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create manifest directory: %w", err)
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, m.RunID+".json")
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return "", fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return path, nil
+}
+
+// Load reads the manifest run runID saved in dir (default: Dir()).
+func Load(dir, runID string) (*Manifest, error) {
+	if dir == "" {
+		dir = Dir()
+	}
+	data, err := os.ReadFile(filepath.Join(dir, runID+".json"))
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	return &m, nil
+}
+
+// Comment returns the manifest as a hidden HTML comment for a PR body.
+func (m *Manifest) Comment() string {
+	data, _ := json.MarshalIndent(m, "", "  ")
+	// "--" would end the comment early; it can only appear in strings,
+	// where the escaped form decodes the same
+	text := strings.ReplaceAll(string(data), "--", `-\u002d`)
+	return fmt.Sprintf("<!-- %s\n%s\n-->\n", marker, text)
+}
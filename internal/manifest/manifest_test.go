@@ -0,0 +1,94 @@
+package manifest
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+)
+
+func TestConfigHash(t *testing.T) {
+	cfg := &config.Config{BaseBranch: "main", LinearKey: "lin_api_one"}
+	hash := ConfigHash(cfg)
+	if hash != ConfigHash(cfg) {
+		t.Fatal("hash is not stable")
+	}
+
+	cfg.LinearKey = "lin_api_two"
+	if ConfigHash(cfg) != hash {
+		t.Error("changing a credential changed the hash")
+	}
+	cfg.Claude.Models.Executor = "claude-opus-4"
+	if ConfigHash(cfg) == hash {
+		t.Error("changing a model left the hash unchanged")
+	}
+}
+
+func TestHashRules(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, ".claude", "agents"), 0755)
+	os.WriteFile(filepath.Join(dir, "CLAUDE.md"), []byte("Use tabs."), 0644)
+	os.WriteFile(filepath.Join(dir, ".claude", "agents", "reviewer.md"), []byte("Be strict."), 0644)
+	os.WriteFile(filepath.Join(dir, "README.md"), []byte("Not a rules file."), 0644)
+
+	rules := HashRules(dir)
+	if len(rules) != 2 || rules["CLAUDE.md"] == "" || rules[".claude/agents/reviewer.md"] == "" {
+		t.Errorf("rules = %v", rules)
+	}
+	if HashRules(t.TempDir()) != nil {
+		t.Error("expected no rules in an empty worktree")
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.Config{BaseBranch: "main"}
+	cfg.Claude.Provider.Name = "ollama"
+	m := New(cfg, "run-1", "ENG-1", t.TempDir(), "abc123")
+	if m.ClaudeCLI != "" || m.Models["executor"] != "default" || m.Models["review_fallback"] != "default" || m.Sampling != nil {
+		t.Errorf("manifest = %+v", m)
+	}
+
+	temperature, seed := 0.0, 42
+	cfg.Claude.Sampling = map[string]config.SamplingConfig{
+		"reviewer": {Temperature: &temperature, Seed: &seed},
+		"executor": {},
+	}
+	m = New(cfg, "run-1", "ENG-1", t.TempDir(), "abc123")
+	if len(m.Sampling) != 1 || *m.Sampling["reviewer"].Temperature != 0 || *m.Sampling["reviewer"].Seed != 42 {
+		t.Errorf("sampling = %+v", m.Sampling)
+	}
+
+	if _, err := m.Save(dir); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(dir, "run-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.ConfigHash != m.ConfigHash || loaded.BaseCommit != "abc123" || loaded.Ticket != "ENG-1" || loaded.Sampling["reviewer"].Seed == nil {
+		t.Errorf("loaded = %+v", loaded)
+	}
+	if _, err := Load(dir, "run-2"); err == nil {
+		t.Error("expected an error for a missing manifest")
+	}
+}
+
+func TestComment(t *testing.T) {
+	m := &Manifest{RunID: "run-1", Ticket: "ENG--1", Rules: map[string]string{"CLAUDE.md": "abc"}}
+	comment := m.Comment()
+	if !strings.HasPrefix(comment, "<!-- boatman-manifest\n") || !strings.HasSuffix(comment, "\n-->\n") {
+		t.Fatalf("comment = %q", comment)
+	}
+	body := strings.TrimSuffix(strings.TrimPrefix(comment, "<!-- boatman-manifest\n"), "\n-->\n")
+	if strings.Contains(body, "--") {
+		t.Errorf("comment body would end the comment early: %s", body)
+	}
+	var decoded Manifest
+	if err := json.Unmarshal([]byte(body), &decoded); err != nil || decoded.Ticket != "ENG--1" {
+		t.Errorf("decoded = %+v, err = %v", decoded, err)
+	}
+}
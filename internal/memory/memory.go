@@ -20,6 +20,9 @@ type Memory struct {
 	// ProjectID identifies the project (usually repo path hash)
 	ProjectID string `json:"project_id"`
 
+	// ProjectPath is the repo path ProjectID was derived from
+	ProjectPath string `json:"project_path,omitempty"`
+
 	// Patterns stores code patterns learned from successful PRs
 	Patterns []Pattern `json:"patterns"`
 
@@ -38,6 +41,12 @@ type Memory struct {
 	// Stats tracks success rates and timing
 	Stats SessionStats `json:"stats"`
 
+	// ScoreHistory tracks final review scores per session for trend reporting
+	ScoreHistory []ScoreRecord `json:"score_history,omitempty"`
+
+	// FailurePoints tracks where sessions failed, most frequent first
+	FailurePoints []FailurePoint `json:"failure_points,omitempty"`
+
 	// LastUpdated is when memory was last modified
 	LastUpdated time.Time `json:"last_updated"`
 
@@ -112,6 +121,58 @@ type SessionStats struct {
 	CommonFailurePoints []string      `json:"common_failure_points"`
 }
 
+// ScoreRecord is the final review score from a single session.
+type ScoreRecord struct {
+	TaskID     string         `json:"task_id,omitempty"`
+	Score      int            `json:"score"`
+	Breakdown  map[string]int `json:"breakdown,omitempty"` // Per-category scores (correctness, tests, etc.)
+	Passed     bool           `json:"passed"`
+	Iterations int            `json:"iterations"`
+	// CostUSD and FilesChanged size the session, for estimates
+	CostUSD      float64 `json:"cost_usd,omitempty"`
+	FilesChanged int     `json:"files_changed,omitempty"`
+	// PRURL is set once the session's PR is created
+	PRURL string `json:"pr_url,omitempty"`
+	// BrokeMain is set when the base branch failed its post-merge
+	// checks after the PR merged
+	BrokeMain  bool      `json:"broke_main,omitempty"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// FailurePoint is a workflow step where sessions in this project failed.
+type FailurePoint struct {
+	Step      string    `json:"step"`                // Workflow step, e.g. "execution" or "review"
+	Iteration int       `json:"iteration,omitempty"` // Review iteration of the latest failure
+	Reason    string    `json:"reason"`
+	Count     int       `json:"count"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// String describes the failure point, e.g. "review (iteration 3): tests failed".
+func (f FailurePoint) String() string {
+	s := f.Step
+	if f.Iteration > 0 {
+		s += fmt.Sprintf(" (iteration %d)", f.Iteration)
+	}
+	return s + ": " + f.Reason
+}
+
+// ScoreTrend compares recent average scores for a category with the window before.
+type ScoreTrend struct {
+	Category string  `json:"category"` // "overall" or a breakdown category
+	Recent   float64 `json:"recent"`
+	Previous float64 `json:"previous"` // 0 if there is not enough history
+	Samples  int     `json:"samples"`  // Records contributing to Recent
+}
+
+// Delta returns the change from the previous window to the recent one.
+func (t ScoreTrend) Delta() float64 {
+	if t.Previous == 0 {
+		return 0
+	}
+	return t.Recent - t.Previous
+}
+
 // Store manages memory persistence.
 type Store struct {
 	baseDir string
@@ -119,8 +180,16 @@ type Store struct {
 	mu      sync.RWMutex
 }
 
-// NewStore creates a new memory store.
+// DirEnv overrides the default memory directory, e.g. for a daemon
+// tenant whose memory is kept apart from other tenants'.
+const DirEnv = "BOATMAN_MEMORY_DIR"
+
+// NewStore creates a new memory store. An empty baseDir uses DirEnv, or
+// ~/.boatman/memory.
 func NewStore(baseDir string) (*Store, error) {
+	if baseDir == "" {
+		baseDir = os.Getenv(DirEnv)
+	}
 	if baseDir == "" {
 		homeDir, err := os.UserHomeDir()
 		if err != nil {
@@ -181,6 +250,8 @@ func (s *Store) Get(projectPath string) (*Memory, error) {
 		}
 	}
 
+	mem.ProjectPath = projectPath
+
 	s.mu.Lock()
 	s.cache[projectID] = mem
 	s.mu.Unlock()
@@ -188,6 +259,28 @@ func (s *Store) Get(projectPath string) (*Memory, error) {
 	return mem, nil
 }
 
+// List loads the memory of every project in the store.
+func (s *Store) List() ([]*Memory, error) {
+	paths, err := filepath.Glob(filepath.Join(s.baseDir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var mems []*Memory
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		mem := &Memory{path: path}
+		if err := json.Unmarshal(data, mem); err != nil {
+			continue // Corrupted file
+		}
+		mems = append(mems, mem)
+	}
+	return mems, nil
+}
+
 // Save persists memory to disk.
 func (s *Store) Save(mem *Memory) error {
 	mem.mu.Lock()
@@ -317,6 +410,206 @@ func (mem *Memory) UpdateStats(successful bool, iterations int, duration time.Du
 	}
 }
 
+// RecordScore appends a review score to the history.
+func (mem *Memory) RecordScore(rec ScoreRecord) {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	if rec.RecordedAt.IsZero() {
+		rec.RecordedAt = time.Now()
+	}
+	mem.ScoreHistory = append(mem.ScoreHistory, rec)
+
+	// Keep the most recent 100 records
+	if len(mem.ScoreHistory) > 100 {
+		mem.ScoreHistory = mem.ScoreHistory[len(mem.ScoreHistory)-100:]
+	}
+}
+
+// SetPRURL attaches a PR to the most recent score record for taskID.
+func (mem *Memory) SetPRURL(taskID, url string) {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	for i := len(mem.ScoreHistory) - 1; i >= 0; i-- {
+		if mem.ScoreHistory[i].TaskID == taskID {
+			mem.ScoreHistory[i].PRURL = url
+			return
+		}
+	}
+}
+
+// MarkBrokeMain records that merging the PR at url broke the base branch,
+// on the session that created it. Returns false if no session did.
+func (mem *Memory) MarkBrokeMain(url string) bool {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	for i := len(mem.ScoreHistory) - 1; i >= 0; i-- {
+		if mem.ScoreHistory[i].PRURL == url {
+			mem.ScoreHistory[i].BrokeMain = true
+			return true
+		}
+	}
+	return false
+}
+
+// RecordFailure records that a session failed at step, merging it with a
+// similar earlier failure, and refreshes Stats.CommonFailurePoints.
+func (mem *Memory) RecordFailure(step string, iteration int, reason string) {
+	mem.mu.Lock()
+	defer mem.mu.Unlock()
+
+	reason = truncate(strings.TrimSpace(reason), 200)
+	found := false
+	for i := range mem.FailurePoints {
+		f := &mem.FailurePoints[i]
+		if f.Step == step && similar(f.Reason, reason) {
+			f.Count++
+			f.Iteration = iteration
+			f.Reason = reason
+			f.LastSeen = time.Now()
+			found = true
+			break
+		}
+	}
+	if !found {
+		mem.FailurePoints = append(mem.FailurePoints, FailurePoint{
+			Step:      step,
+			Iteration: iteration,
+			Reason:    reason,
+			Count:     1,
+			LastSeen:  time.Now(),
+		})
+	}
+
+	sort.SliceStable(mem.FailurePoints, func(i, j int) bool {
+		return mem.FailurePoints[i].Count > mem.FailurePoints[j].Count
+	})
+	// Keep the 20 most frequent
+	if len(mem.FailurePoints) > 20 {
+		mem.FailurePoints = mem.FailurePoints[:20]
+	}
+
+	mem.Stats.CommonFailurePoints = nil
+	for i, f := range mem.FailurePoints {
+		if i == 5 {
+			break
+		}
+		mem.Stats.CommonFailurePoints = append(mem.Stats.CommonFailurePoints, f.String())
+	}
+}
+
+// FailureModes formats the most frequent failure points as warnings for
+// agent prompts, or returns "" if no session has failed.
+func (mem *Memory) FailureModes(limit int) string {
+	mem.mu.RLock()
+	defer mem.mu.RUnlock()
+
+	if len(mem.FailurePoints) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## Historical Failure Modes\n\n")
+	sb.WriteString("Past runs in this repository failed at these points. Plan and work to avoid repeating them:\n")
+	for i, f := range mem.FailurePoints {
+		if i == limit {
+			break
+		}
+		sb.WriteString(fmt.Sprintf("- %s", f))
+		if f.Count > 1 {
+			sb.WriteString(fmt.Sprintf(" (%d times)", f.Count))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// ScoreTrends returns per-category trends comparing the last window records
+// with the window before. The overall score is always listed first.
+func (mem *Memory) ScoreTrends(window int) []ScoreTrend {
+	mem.mu.RLock()
+	defer mem.mu.RUnlock()
+
+	if window <= 0 || len(mem.ScoreHistory) == 0 {
+		return nil
+	}
+
+	n := len(mem.ScoreHistory)
+	recent := mem.ScoreHistory[max(0, n-window):]
+	var previous []ScoreRecord
+	if n > window {
+		previous = mem.ScoreHistory[max(0, n-2*window) : n-window]
+	}
+
+	categorySet := make(map[string]bool)
+	for _, rec := range recent {
+		for category := range rec.Breakdown {
+			categorySet[category] = true
+		}
+	}
+	var categories []string
+	for category := range categorySet {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	overall := func(rec ScoreRecord) (int, bool) { return rec.Score, true }
+	trends := []ScoreTrend{buildTrend("overall", recent, previous, overall)}
+	for _, category := range categories {
+		category := category
+		get := func(rec ScoreRecord) (int, bool) {
+			v, ok := rec.Breakdown[category]
+			return v, ok
+		}
+		trends = append(trends, buildTrend(category, recent, previous, get))
+	}
+	return trends
+}
+
+// buildTrend averages a score across the recent and previous windows.
+func buildTrend(category string, recent, previous []ScoreRecord, get func(ScoreRecord) (int, bool)) ScoreTrend {
+	avg := func(records []ScoreRecord) (float64, int) {
+		total, count := 0, 0
+		for _, rec := range records {
+			if v, ok := get(rec); ok {
+				total += v
+				count++
+			}
+		}
+		if count == 0 {
+			return 0, 0
+		}
+		return float64(total) / float64(count), count
+	}
+
+	t := ScoreTrend{Category: category}
+	t.Recent, t.Samples = avg(recent)
+	t.Previous, _ = avg(previous)
+	return t
+}
+
+// FormatScoreTrends returns a formatted score trend report.
+// Returns an empty string if there is no score history.
+func (mem *Memory) FormatScoreTrends(window int) string {
+	trends := mem.ScoreTrends(window)
+	if len(trends) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Review score trends (last %d sessions):\n", window))
+	for _, t := range trends {
+		line := fmt.Sprintf("  %-12s %5.1f", t.Category, t.Recent)
+		if t.Previous > 0 {
+			line += fmt.Sprintf(" (%+.1f)", t.Delta())
+		}
+		sb.WriteString(line + "\n")
+	}
+	return sb.String()
+}
+
 // GetPatternsForFile returns patterns applicable to a file path.
 func (mem *Memory) GetPatternsForFile(filePath string) []Pattern {
 	mem.mu.RLock()
@@ -466,7 +759,7 @@ func (mem *Memory) FormatStats() string {
 		successRate = float64(s.SuccessfulSessions) / float64(s.TotalSessions) * 100
 	}
 
-	return fmt.Sprintf(
+	out := fmt.Sprintf(
 		"Sessions: %d (%d successful, %.1f%% rate)\n"+
 			"Avg iterations per PR: %.1f\n"+
 			"Avg duration: %s\n"+
@@ -478,6 +771,13 @@ func (mem *Memory) FormatStats() string {
 		len(mem.Patterns),
 		len(mem.CommonIssues),
 	)
+	if len(s.CommonFailurePoints) > 0 {
+		out += "\nCommon failure points:"
+		for _, f := range s.CommonFailurePoints {
+			out += "\n  - " + f
+		}
+	}
+	return out
 }
 
 // Helper functions
@@ -3,6 +3,7 @@ package memory
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -22,6 +23,14 @@ func TestNewStore(t *testing.T) {
 	if store.baseDir != tmpDir {
 		t.Errorf("Expected baseDir %s, got %s", tmpDir, store.baseDir)
 	}
+
+	// A tenant's memory directory replaces the default
+	tenantDir := filepath.Join(tmpDir, "payments")
+	t.Setenv(DirEnv, tenantDir)
+	store, err = NewStore("")
+	if err != nil || store.baseDir != tenantDir {
+		t.Errorf("Expected baseDir %s from %s, got %v (%v)", tenantDir, DirEnv, store, err)
+	}
 }
 
 func TestGetMemory(t *testing.T) {
@@ -291,6 +300,77 @@ func TestFormatStats(t *testing.T) {
 	}
 }
 
+func TestStoreListAndSetPRURL(t *testing.T) {
+	store, _ := NewStore(t.TempDir())
+
+	for _, path := range []string{"/src/api", "/src/web"} {
+		mem, _ := store.Get(path)
+		mem.RecordScore(ScoreRecord{TaskID: "ENG-1", Score: 70})
+		mem.RecordScore(ScoreRecord{TaskID: "ENG-1", Score: 90, Passed: true})
+		mem.SetPRURL("ENG-1", "https://github.com/o/r/pull/1")
+		if err := store.Save(mem); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	mems, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mems) != 2 {
+		t.Fatalf("Expected 2 projects, got %d", len(mems))
+	}
+	for _, mem := range mems {
+		if mem.ProjectPath != "/src/api" && mem.ProjectPath != "/src/web" {
+			t.Errorf("Expected the project path to be stored, got %q", mem.ProjectPath)
+		}
+		if mem.ScoreHistory[0].PRURL != "" || mem.ScoreHistory[1].PRURL == "" {
+			t.Errorf("Expected the PR on the latest record only, got %+v", mem.ScoreHistory)
+		}
+	}
+
+	mem := mems[0]
+	if !mem.MarkBrokeMain("https://github.com/o/r/pull/1") || !mem.ScoreHistory[1].BrokeMain || mem.ScoreHistory[0].BrokeMain {
+		t.Errorf("Expected the PR's record to be marked as breaking main, got %+v", mem.ScoreHistory)
+	}
+	if mem.MarkBrokeMain("https://github.com/o/r/pull/2") {
+		t.Error("Expected no record for a PR boatman didn't open")
+	}
+}
+
+func TestRecordFailure(t *testing.T) {
+	mem := &Memory{}
+	if mem.FailureModes(5) != "" {
+		t.Error("Expected no failure modes without failures")
+	}
+
+	mem.RecordFailure("execution", 0, "execution failed: claude did not produce any file changes")
+	mem.RecordFailure("review", 3, "review did not pass after 3 iteration(s): missing migration")
+	mem.RecordFailure("review", 2, "review did not pass after 2 iteration(s): missing migration")
+
+	if len(mem.FailurePoints) != 2 {
+		t.Fatalf("Expected similar review failures merged into 2 points, got %+v", mem.FailurePoints)
+	}
+	top := mem.FailurePoints[0]
+	if top.Step != "review" || top.Count != 2 || top.Iteration != 2 {
+		t.Errorf("Expected the repeated review failure first, got %+v", top)
+	}
+	if len(mem.Stats.CommonFailurePoints) != 2 || mem.Stats.CommonFailurePoints[0] != top.String() {
+		t.Errorf("Expected CommonFailurePoints to follow FailurePoints, got %v", mem.Stats.CommonFailurePoints)
+	}
+
+	modes := mem.FailureModes(1)
+	if !strings.Contains(modes, "review (iteration 2): review did not pass") || !strings.Contains(modes, "(2 times)") {
+		t.Errorf("Unexpected failure modes:\n%s", modes)
+	}
+	if strings.Contains(modes, "execution") {
+		t.Errorf("Expected limit to drop the execution failure:\n%s", modes)
+	}
+	if !strings.Contains(mem.FormatStats(), "Common failure points:") {
+		t.Error("Expected FormatStats to list failure points")
+	}
+}
+
 func TestHashPath(t *testing.T) {
 	hash1 := hashPath("/path/to/project")
 	hash2 := hashPath("/path/to/project")
@@ -435,3 +515,49 @@ func TestDefaultStore(t *testing.T) {
 		t.Errorf("Expected default dir %s, got %s", expectedDir, store.baseDir)
 	}
 }
+
+func TestScoreTrends(t *testing.T) {
+	mem := &Memory{}
+
+	if trends := mem.ScoreTrends(3); trends != nil {
+		t.Errorf("expected no trends without history, got %v", trends)
+	}
+
+	for _, score := range []int{60, 60, 60, 80, 90, 100} {
+		mem.RecordScore(ScoreRecord{
+			Score:     score,
+			Breakdown: map[string]int{"tests": score - 10},
+		})
+	}
+
+	trends := mem.ScoreTrends(3)
+	if len(trends) != 2 {
+		t.Fatalf("expected overall + tests trends, got %d", len(trends))
+	}
+	if trends[0].Category != "overall" || trends[0].Recent != 90 || trends[0].Previous != 60 {
+		t.Errorf("unexpected overall trend: %+v", trends[0])
+	}
+	if trends[0].Delta() != 30 {
+		t.Errorf("expected delta 30, got %.1f", trends[0].Delta())
+	}
+	if trends[1].Category != "tests" || trends[1].Recent != 80 {
+		t.Errorf("unexpected tests trend: %+v", trends[1])
+	}
+
+	if out := mem.FormatScoreTrends(3); !strings.Contains(out, "+30.0") {
+		t.Errorf("expected delta in formatted trends:\n%s", out)
+	}
+}
+
+func TestScoreHistoryLimit(t *testing.T) {
+	mem := &Memory{}
+	for i := 0; i < 120; i++ {
+		mem.RecordScore(ScoreRecord{Score: i})
+	}
+	if len(mem.ScoreHistory) != 100 {
+		t.Errorf("expected 100 records, got %d", len(mem.ScoreHistory))
+	}
+	if mem.ScoreHistory[0].Score != 20 {
+		t.Errorf("expected oldest records dropped, first is %d", mem.ScoreHistory[0].Score)
+	}
+}
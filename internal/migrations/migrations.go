@@ -0,0 +1,193 @@
+// Package migrations dry-runs the migrations a change adds: it starts an
+// ephemeral Postgres container, applies the migrations, rolls the new ones
+// back and applies them again. Failures and irreversible migrations become
+// critical review issues.
+package migrations
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/cmdoutput"
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/scottbott"
+	"github.com/philjestin/boatmanmode/internal/unidiff"
+)
+
+const (
+	// DefaultImage is the database image used when review.migrations.image
+	// is not set.
+	DefaultImage = "postgres:16-alpine"
+
+	password = "boatman"
+	database = "boatman"
+)
+
+// defaultPaths match the migration directories of Rails, Django, Alembic,
+// golang-migrate, Knex and most other frameworks.
+var defaultPaths = []string{"migrations/", "db/migrate/"}
+
+// readyTimeout bounds how long the database may take to accept connections.
+var readyTimeout = 60 * time.Second
+
+// Runner dry-runs a worktree's migrations.
+type Runner struct {
+	worktreePath string
+	cfg          config.MigrationConfig
+	docker       string
+}
+
+// New creates a Runner. Returns nil when no up command is configured.
+func New(worktreePath string, cfg config.MigrationConfig) *Runner {
+	if cfg.Up == "" {
+		return nil
+	}
+	if len(cfg.Paths) == 0 {
+		cfg.Paths = defaultPaths
+	}
+	if cfg.Image == "" {
+		cfg.Image = DefaultImage
+	}
+	if cfg.DatabaseEnv == "" {
+		cfg.DatabaseEnv = "DATABASE_URL"
+	}
+	return &Runner{worktreePath: worktreePath, cfg: cfg, docker: "docker"}
+}
+
+// NewMigrations returns the migration files the diff adds, in diff order.
+func (r *Runner) NewMigrations(diff string) []string {
+	var files []string
+	for _, f := range unidiff.Parse(diff) {
+		if f.Status != unidiff.Added {
+			continue
+		}
+		for _, pattern := range r.cfg.Paths {
+			if config.MatchPathPattern(pattern, f.Path()) {
+				files = append(files, f.Path())
+				break
+			}
+		}
+	}
+	return files
+}
+
+// irreversible matches the errors frameworks raise for a migration without
+// a down step.
+var irreversible = regexp.MustCompile(`(?i)irreversible|not reversible|cannot be reversed|no down migration|downgrade (is )?not (supported|implemented)`)
+
+// Check applies the migrations to a fresh database, rolls back the new
+// ones and applies them again, returning a critical issue for the first
+// step that fails. Errors mean the database could not be started.
+func (r *Runner) Check(ctx context.Context, diff string) ([]scottbott.Issue, error) {
+	added := r.NewMigrations(diff)
+	if len(added) == 0 {
+		return nil, nil
+	}
+	url, stop, err := r.startDatabase(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer stop()
+
+	file := added[0]
+	if out, err := r.run(ctx, r.cfg.Up, url); err != nil {
+		return []scottbott.Issue{{
+			Severity:    "critical",
+			File:        file,
+			Description: fmt.Sprintf("Migrations fail to apply to an empty database: %s", out),
+			Suggestion:  "Fix the migration so it applies cleanly on top of the existing ones",
+		}}, nil
+	}
+	if r.cfg.Down == "" {
+		return nil, nil
+	}
+	down := strings.ReplaceAll(r.cfg.Down, "{count}", strconv.Itoa(len(added)))
+	if out, err := r.run(ctx, down, url); err != nil {
+		description := fmt.Sprintf("New migrations fail to roll back: %s", out)
+		if irreversible.MatchString(out) {
+			description = fmt.Sprintf("New migrations are irreversible: %s", out)
+		}
+		return []scottbott.Issue{{
+			Severity:    "critical",
+			File:        file,
+			Description: description,
+			Suggestion:  "Give every new migration a working down step that undoes it",
+		}}, nil
+	}
+	if out, err := r.run(ctx, r.cfg.Up, url); err != nil {
+		return []scottbott.Issue{{
+			Severity:    "critical",
+			File:        file,
+			Description: fmt.Sprintf("Migrations fail to re-apply after rolling back, so the down step leaves changes behind: %s", out),
+			Suggestion:  "Make the down step undo everything the migration creates",
+		}}, nil
+	}
+	return nil, nil
+}
+
+// run runs a migration command in the worktree against the database,
+// returning the tail of its output when it fails.
+func (r *Runner) run(ctx context.Context, command, url string) (string, error) {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = r.worktreePath
+	cmd.Env = append(os.Environ(), r.cfg.DatabaseEnv+"="+url)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+	return cmdoutput.Tail(out.String(), 15), err
+}
+
+// startDatabase starts the ephemeral database and waits until it accepts
+// connections. stop removes the container.
+func (r *Runner) startDatabase(ctx context.Context) (string, func(), error) {
+	if _, err := exec.LookPath(r.docker); err != nil {
+		return "", nil, fmt.Errorf("migration dry runs need docker: %w", err)
+	}
+	out, err := exec.CommandContext(ctx, r.docker, "run", "-d", "--rm",
+		"-e", "POSTGRES_PASSWORD="+password, "-e", "POSTGRES_DB="+database,
+		"-p", "127.0.0.1::5432", r.cfg.Image).Output()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to start %s: %w", r.cfg.Image, commandError(err))
+	}
+	id := strings.TrimSpace(string(out))
+	stop := func() {
+		exec.Command(r.docker, "rm", "-f", id).Run()
+	}
+
+	out, err = exec.CommandContext(ctx, r.docker, "port", id, "5432/tcp").Output()
+	if err != nil {
+		stop()
+		return "", nil, fmt.Errorf("failed to find database port: %w", commandError(err))
+	}
+	addr := strings.TrimSpace(strings.SplitN(string(out), "\n", 2)[0])
+
+	deadline := time.Now().Add(readyTimeout)
+	for {
+		ready := exec.CommandContext(ctx, r.docker, "exec", id, "pg_isready", "-h", "127.0.0.1", "-U", "postgres", "-d", database)
+		if ready.Run() == nil {
+			break
+		}
+		if ctx.Err() != nil || time.Now().After(deadline) {
+			stop()
+			return "", nil, fmt.Errorf("database in %s did not become ready", r.cfg.Image)
+		}
+		time.Sleep(time.Second)
+	}
+	return fmt.Sprintf("postgres://postgres:%s@%s/%s?sslmode=disable", password, addr, database), stop, nil
+}
+
+// commandError adds a failed command's stderr to its error.
+func commandError(err error) error {
+	if exit, ok := err.(*exec.ExitError); ok && len(exit.Stderr) > 0 {
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exit.Stderr)))
+	}
+	return err
+}
@@ -0,0 +1,122 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+)
+
+// sendGridURL is SendGrid's mail send endpoint.
+const sendGridURL = "https://api.sendgrid.com/v3/mail/send"
+
+// Mailer sends plain-text email through SMTP or SendGrid.
+type Mailer struct {
+	cfg     config.DigestConfig
+	http    *http.Client
+	sendURL string
+}
+
+// NewMailer creates a Mailer for the digest settings. Returns nil when no
+// recipients are configured.
+func NewMailer(cfg config.DigestConfig) (*Mailer, error) {
+	if len(cfg.To) == 0 {
+		return nil, nil
+	}
+	if cfg.From == "" {
+		return nil, errors.New("notify.digest.from is required")
+	}
+	switch strings.ToLower(cfg.Provider) {
+	case "", "smtp":
+		if cfg.SMTP.Host == "" {
+			return nil, errors.New("notify.digest.smtp.host is required for the smtp provider")
+		}
+	case "sendgrid":
+		if cfg.SendGridKey == "" {
+			return nil, errors.New("a SendGrid API key is required (set SENDGRID_API_KEY or notify.digest.sendgrid_key)")
+		}
+	default:
+		return nil, fmt.Errorf("unknown notify.digest.provider %q (want smtp or sendgrid)", cfg.Provider)
+	}
+	return &Mailer{cfg: cfg, http: &http.Client{Timeout: 30 * time.Second}, sendURL: sendGridURL}, nil
+}
+
+// Send emails subject and body to the configured recipients.
+func (m *Mailer) Send(ctx context.Context, subject, body string) error {
+	if strings.EqualFold(m.cfg.Provider, "sendgrid") {
+		return m.sendGrid(ctx, subject, body)
+	}
+	return m.sendSMTP(subject, body)
+}
+
+func (m *Mailer) sendSMTP(subject, body string) error {
+	port := m.cfg.SMTP.Port
+	if port == 0 {
+		port = 587
+	}
+	addr := net.JoinHostPort(m.cfg.SMTP.Host, strconv.Itoa(port))
+	var auth smtp.Auth
+	if m.cfg.SMTP.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.SMTP.Username, m.cfg.SMTP.Password, m.cfg.SMTP.Host)
+	}
+	if err := smtp.SendMail(addr, auth, m.cfg.From, m.cfg.To, message(m.cfg.From, m.cfg.To, subject, body)); err != nil {
+		return fmt.Errorf("smtp %s: %w", addr, err)
+	}
+	return nil
+}
+
+func (m *Mailer) sendGrid(ctx context.Context, subject, body string) error {
+	to := make([]map[string]string, len(m.cfg.To))
+	for i, addr := range m.cfg.To {
+		to[i] = map[string]string{"email": addr}
+	}
+	payload := map[string]any{
+		"personalizations": []map[string]any{{"to": to}},
+		"from":             map[string]string{"email": m.cfg.From},
+		"subject":          subject,
+		"content":          []map[string]string{{"type": "text/plain", "value": body}},
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.sendURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+m.cfg.SendGridKey)
+	resp, err := m.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("sendgrid: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("sendgrid returned %s", resp.Status)
+	}
+	return nil
+}
+
+// message formats a plain-text email with its headers.
+func message(from string, to []string, subject, body string) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + strings.Join(to, ", ") + "\r\n")
+	b.WriteString("Subject: " + mime.QEncoding.Encode("UTF-8", subject) + "\r\n")
+	b.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n"))
+	return []byte(b.String())
+}
@@ -0,0 +1,129 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+)
+
+func TestNewMailer(t *testing.T) {
+	if m, err := NewMailer(config.DigestConfig{}); m != nil || err != nil {
+		t.Errorf("NewMailer without recipients = %v, %v, want nil", m, err)
+	}
+	for _, cfg := range []config.DigestConfig{
+		{To: []string{"team@acme.com"}, SMTP: config.SMTPConfig{Host: "smtp.acme.com"}},
+		{To: []string{"team@acme.com"}, From: "bot@acme.com", Provider: "smtp"},
+		{To: []string{"team@acme.com"}, From: "bot@acme.com", Provider: "sendgrid"},
+		{To: []string{"team@acme.com"}, From: "bot@acme.com", Provider: "pigeon"},
+	} {
+		if _, err := NewMailer(cfg); err == nil {
+			t.Errorf("NewMailer(%+v) succeeded, want an error", cfg)
+		}
+	}
+}
+
+func TestMailerSendGrid(t *testing.T) {
+	var body struct {
+		Personalizations []struct {
+			To []map[string]string `json:"to"`
+		} `json:"personalizations"`
+		Subject string              `json:"subject"`
+		Content []map[string]string `json:"content"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer SG.key" {
+			t.Errorf("Authorization = %q", r.Header.Get("Authorization"))
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	m, err := NewMailer(config.DigestConfig{To: []string{"team@acme.com"}, From: "bot@acme.com", Provider: "sendgrid", SendGridKey: "SG.key"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m.sendURL = srv.URL
+	if err := m.Send(context.Background(), "Digest", "3 runs"); err != nil {
+		t.Fatal(err)
+	}
+	if body.Subject != "Digest" || len(body.Content) != 1 || body.Content[0]["value"] != "3 runs" ||
+		len(body.Personalizations) != 1 || body.Personalizations[0].To[0]["email"] != "team@acme.com" {
+		t.Errorf("body = %+v", body)
+	}
+}
+
+func TestMailerSMTP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	received := make(chan string, 1)
+	go fakeSMTP(ln, received)
+
+	m, err := NewMailer(config.DigestConfig{
+		To: []string{"team@acme.com"}, From: "bot@acme.com",
+		SMTP: config.SMTPConfig{Host: "127.0.0.1", Port: ln.Addr().(*net.TCPAddr).Port},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Send(context.Background(), "Digest ✅", "3 runs\n1 PR"); err != nil {
+		t.Fatal(err)
+	}
+	data := <-received
+	if !strings.Contains(data, "To: team@acme.com\r\n") || !strings.Contains(data, "Subject: =?UTF-8?q?Digest_=E2=9C=85?=\r\n") ||
+		!strings.HasSuffix(data, "\r\n3 runs\r\n1 PR\r\n") {
+		t.Errorf("message = %q", data)
+	}
+}
+
+// fakeSMTP accepts one message without extensions and passes its data to received.
+func fakeSMTP(ln net.Listener, received chan<- string) {
+	conn, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+	reply("220 fake ESMTP")
+	var data strings.Builder
+	inData := false
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		if inData {
+			if line == ".\r\n" {
+				inData = false
+				received <- data.String()
+				reply("250 queued")
+				continue
+			}
+			data.WriteString(line)
+			continue
+		}
+		switch cmd := strings.ToUpper(strings.Fields(line)[0]); cmd {
+		case "EHLO", "HELO", "MAIL", "RCPT":
+			reply("250 ok")
+		case "DATA":
+			inData = true
+			reply("354 go ahead")
+		case "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 unsupported")
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// Package notify posts run lifecycle events to Slack, Discord and generic
+// JSON webhooks, so team channels hear when boatman starts a ticket, gives
+// up on it or opens its PR.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+)
+
+// Event types.
+const (
+	Started      = "started"
+	ReviewFailed = "review_failed"
+	PRCreated    = "pr_created"
+	Failed       = "failed"
+	MainBroken   = "main_broken"
+)
+
+// Event is one notification about a run.
+type Event struct {
+	Type       string  `json:"type"`
+	RunID      string  `json:"run_id,omitempty"`
+	TicketID   string  `json:"ticket_id"`
+	Title      string  `json:"title,omitempty"`
+	Message    string  `json:"message,omitempty"`
+	PRURL      string  `json:"pr_url,omitempty"`
+	Iterations int     `json:"iterations,omitempty"`
+	CostUSD    float64 `json:"cost_usd,omitempty"`
+}
+
+// Text renders the event as a one-line chat message.
+func (e Event) Text() string {
+	ticket := e.TicketID
+	if e.Title != "" {
+		ticket += " " + e.Title
+	}
+	var text string
+	switch e.Type {
+	case Started:
+		text = fmt.Sprintf("🚣 Started %s", ticket)
+	case ReviewFailed:
+		text = fmt.Sprintf("🔁 Review did not pass for %s", ticket)
+	case PRCreated:
+		text = fmt.Sprintf("✅ PR created for %s: %s", ticket, e.PRURL)
+	case Failed:
+		text = fmt.Sprintf("❌ Run failed for %s", ticket)
+	case MainBroken:
+		text = fmt.Sprintf("🚨 Merging %s broke the base branch: %s", ticket, e.PRURL)
+	default:
+		text = fmt.Sprintf("%s: %s", e.Type, ticket)
+	}
+	if e.Message != "" && e.Type != PRCreated {
+		text += ": " + e.Message
+	}
+	if e.Type != Started && e.Type != MainBroken {
+		text += fmt.Sprintf(" (%d iterations, $%.2f)", e.Iterations, e.CostUSD)
+	}
+	return text
+}
+
+// Notifier sends events to the configured webhooks.
+type Notifier struct {
+	webhooks []config.WebhookConfig
+	http     *http.Client
+}
+
+// New creates a Notifier. Returns nil when no webhooks are configured.
+func New(cfg config.NotifyConfig) *Notifier {
+	if len(cfg.Webhooks) == 0 {
+		return nil
+	}
+	return &Notifier{webhooks: cfg.Webhooks, http: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Send posts the event to every webhook that wants it, returning the
+// errors of those that failed. A nil Notifier sends nothing.
+func (n *Notifier) Send(ctx context.Context, e Event) error {
+	if n == nil {
+		return nil
+	}
+	var errs []error
+	for _, hook := range n.webhooks {
+		if len(hook.Events) > 0 && !slices.Contains(hook.Events, e.Type) {
+			continue
+		}
+		if err := n.post(ctx, hook, e); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", redact(hook.URL), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (n *Notifier) post(ctx context.Context, hook config.WebhookConfig, e Event) error {
+	var payload any
+	switch strings.ToLower(hook.Format) {
+	case "slack":
+		payload = map[string]string{"text": e.Text()}
+	case "discord":
+		payload = map[string]string{"content": e.Text()}
+	case "", "json":
+		payload = e
+	default:
+		return fmt.Errorf("unknown format %q (want slack, discord or json)", hook.Format)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := n.http.Do(req)
+	if err != nil {
+		// Its message repeats the URL
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			return urlErr.Err
+		}
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// redact drops the path of a webhook URL, which is its secret for Slack
+// and Discord.
+func redact(raw string) string {
+	if i := strings.Index(raw, "://"); i >= 0 {
+		if j := strings.Index(raw[i+3:], "/"); j >= 0 {
+			return raw[:i+3+j] + "/…"
+		}
+	}
+	return raw
+}
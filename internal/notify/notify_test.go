@@ -0,0 +1,97 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+)
+
+func TestNew(t *testing.T) {
+	if n := New(config.NotifyConfig{}); n != nil {
+		t.Errorf("New without webhooks = %v, want nil", n)
+	}
+	var n *Notifier
+	if err := n.Send(context.Background(), Event{Type: Started}); err != nil {
+		t.Errorf("nil Notifier Send = %v", err)
+	}
+}
+
+func TestSend(t *testing.T) {
+	bodies := map[string]map[string]any{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies[r.URL.Path] = body
+	}))
+	defer srv.Close()
+
+	n := New(config.NotifyConfig{Webhooks: []config.WebhookConfig{
+		{URL: srv.URL + "/slack", Format: "slack"},
+		{URL: srv.URL + "/discord", Format: "discord", Events: []string{PRCreated}},
+		{URL: srv.URL + "/json"},
+		{URL: srv.URL + "/failures", Events: []string{Failed}},
+	}})
+	e := Event{Type: PRCreated, TicketID: "ENG-123", Title: "Add login", PRURL: "https://github.com/acme/app/pull/7", Iterations: 2, CostUSD: 1.5}
+	if err := n.Send(context.Background(), e); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "✅ PR created for ENG-123 Add login: https://github.com/acme/app/pull/7 (2 iterations, $1.50)"
+	if got := bodies["/slack"]["text"]; got != want {
+		t.Errorf("slack text = %q, want %q", got, want)
+	}
+	if got := bodies["/discord"]["content"]; got != want {
+		t.Errorf("discord content = %q, want %q", got, want)
+	}
+	if got := bodies["/json"]; got["type"] != PRCreated || got["pr_url"] != e.PRURL || got["cost_usd"] != 1.5 {
+		t.Errorf("json body = %v", got)
+	}
+	if _, ok := bodies["/failures"]; ok {
+		t.Error("webhook subscribed to failures got a PR notification")
+	}
+}
+
+func TestSendErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	n := New(config.NotifyConfig{Webhooks: []config.WebhookConfig{
+		{URL: srv.URL + "/services/T000/B000/secret", Format: "slack"},
+		{URL: srv.URL + "/teams", Format: "teams"},
+	}})
+	err := n.Send(context.Background(), Event{Type: Failed, TicketID: "ENG-123", Message: "tests failed"})
+	if err == nil {
+		t.Fatal("Send succeeded against a failing webhook")
+	}
+	msg := err.Error()
+	if !strings.Contains(msg, "404") || !strings.Contains(msg, `unknown format "teams"`) {
+		t.Errorf("err = %v, want both failures", err)
+	}
+	if strings.Contains(msg, "secret") {
+		t.Errorf("err = %v leaks the webhook path", err)
+	}
+}
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		event Event
+		want  string
+	}{
+		{Event{Type: Started, TicketID: "ENG-1", Title: "Fix it"}, "🚣 Started ENG-1 Fix it"},
+		{Event{Type: ReviewFailed, TicketID: "ENG-1", Message: "Review did not pass after max iterations", Iterations: 3, CostUSD: 2}, "🔁 Review did not pass for ENG-1: Review did not pass after max iterations (3 iterations, $2.00)"},
+		{Event{Type: Failed, TicketID: "ENG-1", Message: "push rejected"}, "❌ Run failed for ENG-1: push rejected (0 iterations, $0.00)"},
+		{Event{Type: MainBroken, TicketID: "ENG-1", PRURL: "https://github.com/acme/web/pull/7", Message: "smoke tests failed on abc1234"}, "🚨 Merging ENG-1 broke the base branch: https://github.com/acme/web/pull/7: smoke tests failed on abc1234"},
+	}
+	for _, tt := range tests {
+		if got := tt.event.Text(); got != tt.want {
+			t.Errorf("Text() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+// Package observability enforces the review policy that new endpoints and
+// jobs come instrumented with the logging, metrics and tracing the repo
+// expects.
+package observability
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/memory"
+	"github.com/philjestin/boatmanmode/internal/scottbott"
+	"github.com/philjestin/boatmanmode/internal/unidiff"
+)
+
+// Instrumentation kinds a policy can require.
+const (
+	Logging = "logging"
+	Metrics = "metrics"
+	Tracing = "tracing"
+)
+
+// defaultEntryPoints match added lines that define an endpoint or a job.
+var defaultEntryPoints = []string{
+	// Go
+	`\bhttp\.Handle(Func)?\(`,
+	`\.(GET|POST|PUT|PATCH|DELETE|Handle|HandleFunc)\(\s*"`,
+	`^func (\([^)]*\) )?\w+\(\s*\w+ http\.ResponseWriter`,
+	// Ruby
+	`^\s*(get|post|put|patch|delete|match) ['"]`,
+	`class \w+ < (ApplicationJob|ActiveJob::Base)`,
+	`include Sidekiq::(Worker|Job)`,
+	// JavaScript / TypeScript
+	`\b(app|router)\.(get|post|put|patch|delete|all)\(\s*['"` + "`" + `]`,
+	// Python
+	`@(app|router|bp|blueprint)\.(get|post|put|patch|delete|route)\(`,
+	`@(shared_task|celery\.task|app\.task)\b`,
+}
+
+// defaultSignals match added lines that instrument code, per kind.
+var defaultSignals = map[string]string{
+	Logging: `(?i)\b(log|logger|slog|zap|logrus|Rails\.logger|logging)\b\s*\.?\s*\w*\(|console\.(log|info|warn|error)\(`,
+	Metrics: `(?i)\b(metrics?|statsd|prometheus|promauto|datadog|dogstatsd)\b|\.(Inc|Observe|increment|histogram|gauge|timing)\(`,
+	Tracing: `(?i)\b(tracer|tracing|opentelemetry|otel|span|ddtrace|newrelic)\b|StartSpan|start_span|start_as_current_span`,
+}
+
+// keywords find examples of each kind in project rules and memory.
+var keywords = map[string]*regexp.Regexp{
+	Logging: regexp.MustCompile(`(?i)\blog(s|ging|ger)?\b`),
+	Metrics: regexp.MustCompile(`(?i)\bmetrics?\b|statsd|prometheus|counter|histogram`),
+	Tracing: regexp.MustCompile(`(?i)\btrac(e|es|ing|er)\b|\bspans?\b|opentelemetry`),
+}
+
+// Checker finds new entry points in a diff that lack instrumentation.
+type Checker struct {
+	require     []string
+	severity    string
+	entryPoints []*regexp.Regexp
+	signals     map[string]*regexp.Regexp
+	examples    map[string]string
+}
+
+// New creates a Checker. Returns nil when the policy requires nothing.
+func New(cfg config.ObservabilityConfig) (*Checker, error) {
+	if len(cfg.Require) == 0 {
+		return nil, nil
+	}
+	c := &Checker{severity: cfg.Severity, signals: map[string]*regexp.Regexp{}, examples: map[string]string{}}
+	if c.severity == "" {
+		c.severity = "major"
+	}
+	for _, kind := range cfg.Require {
+		kind = strings.ToLower(kind)
+		expr := cfg.Signals[kind]
+		if expr == "" {
+			expr = defaultSignals[kind]
+		}
+		if expr == "" {
+			return nil, fmt.Errorf("review.observability.signals.%s is required for a custom kind", kind)
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signal pattern for %s: %w", kind, err)
+		}
+		c.require = append(c.require, kind)
+		c.signals[kind] = re
+	}
+	entryPoints := cfg.EntryPoints
+	if len(entryPoints) == 0 {
+		entryPoints = defaultEntryPoints
+	}
+	for _, expr := range entryPoints {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid entry point pattern %q: %w", expr, err)
+		}
+		c.entryPoints = append(c.entryPoints, re)
+	}
+	return c, nil
+}
+
+// SetExamples picks an example of each required kind from the project's
+// rules and learned patterns, to show in the issues. Learned patterns win
+// over rules, since they were taken from code the reviewer accepted.
+func (c *Checker) SetExamples(rules string, patterns []memory.Pattern) {
+	for _, kind := range c.require {
+		re := keywords[kind]
+		if re == nil {
+			continue
+		}
+		for _, p := range patterns {
+			if re.MatchString(p.Description) || re.MatchString(p.Example) {
+				example := p.Description
+				if p.Example != "" {
+					example += ", e.g. `" + p.Example + "`"
+				}
+				c.examples[kind] = example
+				break
+			}
+		}
+		if c.examples[kind] != "" {
+			continue
+		}
+		for _, line := range strings.Split(rules, "\n") {
+			line = strings.TrimSpace(strings.TrimLeft(line, "-*#> "))
+			if line != "" && len(line) <= 200 && re.MatchString(line) {
+				c.examples[kind] = line
+				break
+			}
+		}
+	}
+}
+
+// Issues returns an issue for each changed file that adds an endpoint or
+// job without the required instrumentation somewhere in its added lines.
+func (c *Checker) Issues(diff string) []scottbott.Issue {
+	var issues []scottbott.Issue
+	for _, f := range unidiff.Parse(diff) {
+		if isTestFile(f.Path()) {
+			continue
+		}
+		added := f.Added()
+		entry := c.firstEntryPoint(added)
+		if entry == "" {
+			continue
+		}
+		var missing []string
+		for _, kind := range c.require {
+			if !anyMatch(c.signals[kind], added) {
+				missing = append(missing, kind)
+			}
+		}
+		if len(missing) == 0 {
+			continue
+		}
+		issues = append(issues, scottbott.Issue{
+			Severity:    c.severity,
+			File:        f.Path(),
+			Description: fmt.Sprintf("New endpoint or job `%s` has no %s", truncate(entry, 80), strings.Join(missing, " or ")),
+			Suggestion:  c.suggestion(missing),
+		})
+	}
+	return issues
+}
+
+func (c *Checker) suggestion(missing []string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Instrument it with %s following the repo's conventions", strings.Join(missing, ", ")))
+	for _, kind := range missing {
+		if example := c.examples[kind]; example != "" {
+			sb.WriteString(fmt.Sprintf("; %s: %s", kind, example))
+		}
+	}
+	return sb.String()
+}
+
+func (c *Checker) firstEntryPoint(lines []string) string {
+	for _, line := range lines {
+		for _, re := range c.entryPoints {
+			if re.MatchString(line) {
+				return strings.TrimSpace(line)
+			}
+		}
+	}
+	return ""
+}
+
+func anyMatch(re *regexp.Regexp, lines []string) bool {
+	for _, line := range lines {
+		if re.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+func isTestFile(path string) bool {
+	return strings.Contains(path, "_test.") || strings.Contains(path, "_spec.") ||
+		strings.Contains(path, ".test.") || strings.Contains(path, ".spec.") ||
+		strings.HasPrefix(path, "test/") || strings.HasPrefix(path, "spec/") ||
+		strings.Contains(path, "/test/") || strings.Contains(path, "/spec/")
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max-3] + "..."
+}
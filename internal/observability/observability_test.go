@@ -0,0 +1,89 @@
+package observability
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/memory"
+)
+
+const diff = `diff --git a/api/orders.go b/api/orders.go
+--- a/api/orders.go
++++ b/api/orders.go
+@@ -1,3 +1,9 @@
+ package api
++
++func ListOrders(w http.ResponseWriter, r *http.Request) {
++	ctx, span := tracer.Start(r.Context(), "ListOrders")
++	defer span.End()
++	slog.InfoContext(ctx, "listing orders")
++}
+diff --git a/jobs/cleanup_job.rb b/jobs/cleanup_job.rb
+--- /dev/null
++++ b/jobs/cleanup_job.rb
+@@ -0,0 +1,5 @@
++class CleanupJob < ApplicationJob
++  def perform
++    Order.stale.delete_all
++  end
++end
+diff --git a/api/orders_test.go b/api/orders_test.go
+--- a/api/orders_test.go
++++ b/api/orders_test.go
+@@ -1 +1,2 @@
+ package api
++func TestHandler(w http.ResponseWriter, r *http.Request) {}
+`
+
+func TestIssues(t *testing.T) {
+	c, err := New(config.ObservabilityConfig{Require: []string{"logging", "metrics", "tracing"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetExamples("# Conventions\n- Emit metrics with `metrics.Count(name, 1)` for every job run", []memory.Pattern{
+		{Description: "Log with slog and request context", Example: `slog.InfoContext(ctx, "msg")`},
+	})
+
+	issues := c.Issues(diff)
+	if len(issues) != 2 {
+		t.Fatalf("Expected issues for both new entry points, got %+v", issues)
+	}
+	if issues[0].File != "api/orders.go" || issues[0].Description != "New endpoint or job `func ListOrders(w http.ResponseWriter, r *http.Request) {` has no metrics" {
+		t.Errorf("Unexpected endpoint issue: %+v", issues[0])
+	}
+	if issues[1].File != "jobs/cleanup_job.rb" || !strings.HasSuffix(issues[1].Description, "has no logging or metrics or tracing") {
+		t.Errorf("Unexpected job issue: %+v", issues[1])
+	}
+	if issues[1].Severity != "major" {
+		t.Errorf("Expected major issues by default, got %q", issues[1].Severity)
+	}
+	for _, want := range []string{"logging: Log with slog and request context, e.g. `slog.InfoContext(ctx, \"msg\")`", "metrics: Emit metrics with `metrics.Count(name, 1)` for every job run"} {
+		if !strings.Contains(issues[1].Suggestion, want) {
+			t.Errorf("Expected %q in suggestion: %s", want, issues[1].Suggestion)
+		}
+	}
+}
+
+func TestCustomPolicy(t *testing.T) {
+	c, err := New(config.ObservabilityConfig{
+		Require:     []string{"audit"},
+		EntryPoints: []string{`class \w+ < ApplicationJob`},
+		Signals:     map[string]string{"audit": `AuditLog\.record`},
+		Severity:    "critical",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	issues := c.Issues(diff)
+	if len(issues) != 1 || issues[0].Severity != "critical" || !strings.HasSuffix(issues[0].Description, "has no audit") {
+		t.Errorf("Unexpected issues: %+v", issues)
+	}
+
+	if c, err := New(config.ObservabilityConfig{}); c != nil || err != nil {
+		t.Errorf("Expected no checker without requirements, got %v, %v", c, err)
+	}
+	if _, err := New(config.ObservabilityConfig{Require: []string{"audit"}}); err == nil {
+		t.Error("Expected an error for a custom kind without a signal")
+	}
+}
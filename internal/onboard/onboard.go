@@ -0,0 +1,402 @@
+// Package onboard analyzes a repository and suggests a boatman config:
+// detected frameworks, test/build/lint commands, lint configs, AI rules
+// files, and CODEOWNERS-derived protected paths.
+package onboard
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Commands are the detected project commands.
+type Commands struct {
+	Test  string `yaml:"test,omitempty"`
+	Build string `yaml:"build,omitempty"`
+	Lint  string `yaml:"lint,omitempty"`
+}
+
+// Report is the result of analyzing a repository.
+type Report struct {
+	Frameworks     []string // Languages and notable frameworks, e.g. "go", "rails", "react"
+	Commands       Commands
+	LintConfigs    []string // Linter/formatter config files found
+	RulesFiles     []string // AI assistant rules files found (CLAUDE.md, .cursorrules, ...)
+	CodeOwners     string   // CODEOWNERS file, if any
+	ProtectedPaths []string
+}
+
+// lintConfigFiles are linter and formatter config files worth reporting.
+var lintConfigFiles = []string{
+	".golangci.yml", ".golangci.yaml", ".golangci.toml",
+	".eslintrc", ".eslintrc.js", ".eslintrc.cjs", ".eslintrc.json", ".eslintrc.yml", ".eslintrc.yaml",
+	"eslint.config.js", "eslint.config.mjs", "eslint.config.cjs", "eslint.config.ts",
+	"biome.json", ".prettierrc", ".prettierrc.json", ".prettierrc.js", "prettier.config.js",
+	".rubocop.yml", "ruff.toml", ".ruff.toml", ".flake8", ".pylintrc", "clippy.toml",
+}
+
+// rulesFiles are AI assistant instruction files.
+var rulesFiles = []string{
+	"CLAUDE.md", "AGENTS.md", ".cursorrules", ".cursor/rules", ".windsurfrules",
+	".github/copilot-instructions.md",
+}
+
+// codeOwnersFiles are the locations GitHub and GitLab look for CODEOWNERS.
+var codeOwnersFiles = []string{"CODEOWNERS", ".github/CODEOWNERS", "docs/CODEOWNERS", ".gitlab/CODEOWNERS"}
+
+// Analyze scans the repository at dir.
+func Analyze(dir string) (*Report, error) {
+	if info, err := os.Stat(dir); err != nil {
+		return nil, err
+	} else if !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+
+	r := &Report{}
+	a := analyzer{dir: dir, report: r}
+
+	// Same precedence as testrunner framework detection
+	a.detectGo()
+	a.detectRuby()
+	a.detectNode()
+	a.detectPython()
+	a.detectRust()
+	a.detectMakefile()
+
+	for _, name := range lintConfigFiles {
+		if a.exists(name) {
+			r.LintConfigs = append(r.LintConfigs, name)
+		}
+	}
+	for _, name := range rulesFiles {
+		if a.exists(name) {
+			r.RulesFiles = append(r.RulesFiles, name)
+		}
+	}
+
+	for _, name := range codeOwnersFiles {
+		if a.exists(name) {
+			r.CodeOwners = name
+			data, err := os.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				return nil, err
+			}
+			r.ProtectedPaths = ParseCodeOwners(string(data))
+			break
+		}
+	}
+	if a.exists(".github/workflows") {
+		r.ProtectedPaths = appendUnique(r.ProtectedPaths, "/.github/workflows/")
+	}
+
+	return r, nil
+}
+
+// ParseCodeOwners returns the path patterns with owners in a CODEOWNERS
+// file. A catch-all "*" is skipped: protecting everything flags nothing.
+func ParseCodeOwners(content string) []string {
+	var patterns []string
+	for _, rule := range ParseCodeOwnerRules(content) {
+		if rule.Pattern == "*" || rule.Pattern == "/*" || rule.Pattern == "**" {
+			continue
+		}
+		patterns = appendUnique(patterns, rule.Pattern)
+	}
+	return patterns
+}
+
+// CodeOwnerRule is one CODEOWNERS entry.
+type CodeOwnerRule struct {
+	Pattern string
+	Owners  []string
+}
+
+// ParseCodeOwnerRules returns the CODEOWNERS entries that have owners, in
+// file order.
+func ParseCodeOwnerRules(content string) []CodeOwnerRule {
+	var rules []CodeOwnerRule
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[") {
+			continue // Blank, comment, or GitLab section header
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		rules = append(rules, CodeOwnerRule{Pattern: fields[0], Owners: fields[1:]})
+	}
+	return rules
+}
+
+// LoadCodeOwners reads the repository's CODEOWNERS rules, if it has any.
+func LoadCodeOwners(dir string) ([]CodeOwnerRule, error) {
+	for _, name := range codeOwnersFiles {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return ParseCodeOwnerRules(string(data)), nil
+	}
+	return nil, nil
+}
+
+// OwnersOf returns the distinct owners of files. As on GitHub, the last
+// matching rule decides a file's owners.
+func OwnersOf(rules []CodeOwnerRule, files []string) []string {
+	var owners []string
+	for _, file := range files {
+		for i := len(rules) - 1; i >= 0; i-- {
+			if config.MatchPathPattern(rules[i].Pattern, file) {
+				for _, owner := range rules[i].Owners {
+					owners = appendUnique(owners, owner)
+				}
+				break
+			}
+		}
+	}
+	return owners
+}
+
+// YAML renders the report as a suggested .boatman.yaml.
+func (r *Report) YAML() (string, error) {
+	suggested := struct {
+		Commands       *Commands `yaml:"commands,omitempty"`
+		ProtectedPaths []string  `yaml:"protected_paths,omitempty"`
+	}{ProtectedPaths: r.ProtectedPaths}
+	if r.Commands != (Commands{}) {
+		suggested.Commands = &r.Commands
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("# Suggested by `boatman onboard`; review before committing.\n")
+	writeList(&buf, "Detected", r.Frameworks)
+	writeList(&buf, "Lint config", r.LintConfigs)
+	writeList(&buf, "Rules files", r.RulesFiles)
+	if r.CodeOwners != "" {
+		fmt.Fprintf(&buf, "# Protected paths from %s\n", r.CodeOwners)
+	}
+	if suggested.Commands == nil && len(suggested.ProtectedPaths) == 0 {
+		buf.WriteString("# No commands or protected paths detected\n")
+		return buf.String(), nil
+	}
+	buf.WriteString("\n")
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(suggested); err != nil {
+		return "", err
+	}
+	enc.Close()
+	return buf.String(), nil
+}
+
+func writeList(buf *bytes.Buffer, label string, items []string) {
+	if len(items) > 0 {
+		fmt.Fprintf(buf, "# %s: %s\n", label, strings.Join(items, ", "))
+	}
+}
+
+// analyzer accumulates detections into a report.
+type analyzer struct {
+	dir    string
+	report *Report
+}
+
+func (a *analyzer) exists(name string) bool {
+	_, err := os.Stat(filepath.Join(a.dir, name))
+	return err == nil
+}
+
+func (a *analyzer) read(name string) string {
+	data, _ := os.ReadFile(filepath.Join(a.dir, name))
+	return string(data)
+}
+
+func (a *analyzer) framework(names ...string) {
+	for _, name := range names {
+		a.report.Frameworks = appendUnique(a.report.Frameworks, name)
+	}
+}
+
+// suggest sets each command not already detected by a higher-precedence
+// ecosystem.
+func (a *analyzer) suggest(c Commands) {
+	cmds := &a.report.Commands
+	if cmds.Test == "" {
+		cmds.Test = c.Test
+	}
+	if cmds.Build == "" {
+		cmds.Build = c.Build
+	}
+	if cmds.Lint == "" {
+		cmds.Lint = c.Lint
+	}
+}
+
+func (a *analyzer) detectGo() {
+	if !a.exists("go.mod") {
+		return
+	}
+	a.framework("go")
+	lint := "go vet ./..."
+	if a.exists(".golangci.yml") || a.exists(".golangci.yaml") || a.exists(".golangci.toml") {
+		lint = "golangci-lint run"
+	}
+	a.suggest(Commands{Test: "go test ./...", Build: "go build ./...", Lint: lint})
+}
+
+func (a *analyzer) detectRuby() {
+	if !a.exists("Gemfile") {
+		return
+	}
+	gemfile := a.read("Gemfile")
+	a.framework("ruby")
+	if hasGem(gemfile, "rails") {
+		a.framework("rails")
+	}
+
+	c := Commands{Test: "bundle exec rake test"}
+	if hasGem(gemfile, "rspec") || hasGem(gemfile, "rspec-rails") {
+		a.framework("rspec")
+		c.Test = "bundle exec rspec"
+	}
+	if hasGem(gemfile, "rubocop") || a.exists(".rubocop.yml") {
+		c.Lint = "bundle exec rubocop"
+	}
+	a.suggest(c)
+}
+
+func hasGem(gemfile, name string) bool {
+	return regexp.MustCompile(`(?m)^\s*gem\s+['"]` + regexp.QuoteMeta(name) + `['"]`).MatchString(gemfile)
+}
+
+// nodeFrameworks maps package.json dependencies worth reporting to names.
+var nodeFrameworks = []struct{ dep, name string }{
+	{"next", "next"}, {"react", "react"}, {"vue", "vue"}, {"svelte", "svelte"},
+	{"@angular/core", "angular"}, {"express", "express"}, {"typescript", "typescript"},
+	{"jest", "jest"}, {"vitest", "vitest"}, {"mocha", "mocha"},
+}
+
+func (a *analyzer) detectNode() {
+	if !a.exists("package.json") {
+		return
+	}
+	var pkg struct {
+		Scripts         map[string]string `json:"scripts"`
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if err := json.Unmarshal([]byte(a.read("package.json")), &pkg); err != nil {
+		return
+	}
+	a.framework("node")
+	for _, f := range nodeFrameworks {
+		_, dep := pkg.Dependencies[f.dep]
+		_, devDep := pkg.DevDependencies[f.dep]
+		if dep || devDep {
+			a.framework(f.name)
+		}
+	}
+
+	pm := "npm"
+	switch {
+	case a.exists("pnpm-lock.yaml"):
+		pm = "pnpm"
+	case a.exists("yarn.lock"):
+		pm = "yarn"
+	case a.exists("bun.lockb"), a.exists("bun.lock"):
+		pm = "bun"
+	}
+	script := func(name string) string {
+		if _, ok := pkg.Scripts[name]; !ok {
+			return ""
+		}
+		if name == "test" && pm != "bun" {
+			return pm + " test"
+		}
+		return pm + " run " + name
+	}
+	a.suggest(Commands{Test: script("test"), Build: script("build"), Lint: script("lint")})
+}
+
+func (a *analyzer) detectPython() {
+	var manifest string
+	for _, name := range []string{"pyproject.toml", "setup.py", "setup.cfg", "requirements.txt", "Pipfile"} {
+		if a.exists(name) {
+			manifest += a.read(name) + "\n"
+		}
+	}
+	if manifest == "" && !a.exists("pytest.ini") {
+		return
+	}
+	a.framework("python")
+	lower := strings.ToLower(manifest)
+	for _, name := range []string{"django", "flask", "fastapi"} {
+		if strings.Contains(lower, name) {
+			a.framework(name)
+		}
+	}
+
+	c := Commands{Test: "pytest"}
+	switch {
+	case a.exists("ruff.toml") || a.exists(".ruff.toml") || strings.Contains(manifest, "[tool.ruff"):
+		c.Lint = "ruff check ."
+	case a.exists(".flake8") || strings.Contains(manifest, "[flake8]"):
+		c.Lint = "flake8"
+	}
+	a.suggest(c)
+}
+
+func (a *analyzer) detectRust() {
+	if !a.exists("Cargo.toml") {
+		return
+	}
+	a.framework("rust")
+	a.suggest(Commands{Test: "cargo test", Build: "cargo build", Lint: "cargo clippy"})
+}
+
+// makeTarget matches a Makefile rule name at the start of a line.
+var makeTarget = regexp.MustCompile(`(?m)^([A-Za-z0-9_.-]+)\s*:`)
+
+// detectMakefile prefers the project's own make targets when present.
+func (a *analyzer) detectMakefile() {
+	if !a.exists("Makefile") {
+		return
+	}
+	targets := make(map[string]bool)
+	for _, m := range makeTarget.FindAllStringSubmatch(a.read("Makefile"), -1) {
+		targets[m[1]] = true
+	}
+	cmds := &a.report.Commands
+	if targets["test"] {
+		cmds.Test = "make test"
+	}
+	if targets["build"] {
+		cmds.Build = "make build"
+	}
+	if targets["lint"] {
+		cmds.Lint = "make lint"
+	}
+}
+
+func appendUnique(list []string, item string) []string {
+	if item == "" || slices.Contains(list, item) {
+		return list
+	}
+	return append(list, item)
+}
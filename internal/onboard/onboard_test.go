@@ -0,0 +1,184 @@
+package onboard
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAnalyzeGo(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod":        "module example.com/app\n",
+		".golangci.yml": "linters: {}\n",
+		"CLAUDE.md":     "# Rules\n",
+	})
+
+	r, err := Analyze(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Commands{Test: "go test ./...", Build: "go build ./...", Lint: "golangci-lint run"}
+	if r.Commands != want {
+		t.Errorf("Expected %+v, got %+v", want, r.Commands)
+	}
+	if !reflect.DeepEqual(r.Frameworks, []string{"go"}) {
+		t.Errorf("Unexpected frameworks: %v", r.Frameworks)
+	}
+	if !reflect.DeepEqual(r.LintConfigs, []string{".golangci.yml"}) || !reflect.DeepEqual(r.RulesFiles, []string{"CLAUDE.md"}) {
+		t.Errorf("Unexpected lint configs %v / rules files %v", r.LintConfigs, r.RulesFiles)
+	}
+}
+
+func TestAnalyzeNode(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"package.json": `{
+			"scripts": {"test": "vitest run", "build": "next build", "lint": "eslint ."},
+			"dependencies": {"next": "14.0.0", "react": "18.2.0"},
+			"devDependencies": {"vitest": "1.0.0", "@angular/core": "17.0.0"}
+		}`,
+		"pnpm-lock.yaml": "",
+	})
+
+	r, err := Analyze(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Commands{Test: "pnpm test", Build: "pnpm run build", Lint: "pnpm run lint"}
+	if r.Commands != want {
+		t.Errorf("Expected %+v, got %+v", want, r.Commands)
+	}
+	if want := []string{"node", "next", "react", "angular", "vitest"}; !reflect.DeepEqual(r.Frameworks, want) {
+		t.Errorf("Expected frameworks %v, got %v", want, r.Frameworks)
+	}
+}
+
+func TestAnalyzeRails(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"Gemfile":      "source 'https://rubygems.org'\ngem 'rails', '~> 7.1'\ngroup :test do\n  gem \"rspec-rails\"\nend\n",
+		".rubocop.yml": "AllCops: {}\n",
+	})
+
+	r, err := Analyze(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Commands{Test: "bundle exec rspec", Lint: "bundle exec rubocop"}
+	if r.Commands != want {
+		t.Errorf("Expected %+v, got %+v", want, r.Commands)
+	}
+	if want := []string{"ruby", "rails", "rspec"}; !reflect.DeepEqual(r.Frameworks, want) {
+		t.Errorf("Expected frameworks %v, got %v", want, r.Frameworks)
+	}
+}
+
+func TestAnalyzePrefersMakeTargets(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"pyproject.toml": "[project]\ndependencies = [\"fastapi\"]\n\n[tool.ruff]\nline-length = 100\n",
+		"Makefile":       ".PHONY: test\ntest:\n\tpytest -q\n",
+	})
+
+	r, err := Analyze(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Commands{Test: "make test", Lint: "ruff check ."}
+	if r.Commands != want {
+		t.Errorf("Expected %+v, got %+v", want, r.Commands)
+	}
+	if want := []string{"python", "fastapi"}; !reflect.DeepEqual(r.Frameworks, want) {
+		t.Errorf("Expected frameworks %v, got %v", want, r.Frameworks)
+	}
+}
+
+func TestParseCodeOwners(t *testing.T) {
+	content := `# Default owners
+*       @org/everyone
+
+/infra/        @org/platform
+db/migrate/**  @org/dba @alice
+*.sql          @org/dba
+
+[Security]
+/config/secrets.yml @org/security
+/infra/        @org/sre
+unowned-entry
+`
+	want := []string{"/infra/", "db/migrate/**", "*.sql", "/config/secrets.yml"}
+	if got := ParseCodeOwners(content); !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestReportYAML(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod":                   "module example.com/app\n",
+		".github/CODEOWNERS":       "/infra/ @org/platform\n",
+		".github/workflows/ci.yml": "on: push\n",
+	})
+
+	r, err := Analyze(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := r.YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# Detected: go",
+		"# Protected paths from .github/CODEOWNERS",
+		"commands:\n  test: go test ./...\n",
+		"protected_paths:\n  - /infra/\n  - /.github/workflows/\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in:\n%s", want, out)
+		}
+	}
+}
+
+func TestReportYAMLEmptyRepo(t *testing.T) {
+	r, err := Analyze(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := r.YAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out, "commands:") || strings.Contains(out, "protected_paths:") {
+		t.Errorf("Expected no settings for an empty repo:\n%s", out)
+	}
+}
+
+func TestOwnersOf(t *testing.T) {
+	rules := ParseCodeOwnerRules(`*            @org/everyone
+/api/        @org/api
+/api/auth/   @org/security @alice
+*.sql        @org/dba
+`)
+	got := OwnersOf(rules, []string{"api/users.go", "api/auth/login.go", "db/schema.sql", "README.md"})
+	want := []string{"@org/api", "@org/security", "@alice", "@org/dba", "@org/everyone"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
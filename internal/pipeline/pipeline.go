@@ -0,0 +1,97 @@
+// Package pipeline defines the stages a run goes through, as configured
+// with pipeline: in .boatman.yaml.
+package pipeline
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Stage is a configurable part of the workflow.
+type Stage string
+
+const (
+	Plan     Stage = "plan"     // Planning, plan review and pre-flight validation
+	Execute  Stage = "execute"  // Claude implements the task
+	Lint     Stage = "lint"     // Run commands.lint
+	Test     Stage = "test"     // Run the tests for the changed files
+	Review   Stage = "review"   // Initial review
+	Refactor Stage = "refactor" // Review and refactor loop
+	Commit   Stage = "commit"   // Commit the change
+	PR       Stage = "pr"       // Push and open the PR
+)
+
+// order is the order stages run in.
+var order = []Stage{Plan, Execute, Lint, Test, Review, Refactor, Commit, PR}
+
+// requires lists the stages each stage cannot run without.
+var requires = map[Stage][]Stage{
+	Lint:     {Execute},
+	Test:     {Execute},
+	Review:   {Execute},
+	Refactor: {Review},
+	Commit:   {Execute},
+	PR:       {Commit},
+}
+
+// Default is the pipeline of runs that configure none.
+var Default = Pipeline{Plan, Execute, Test, Review, Refactor, Commit, PR}
+
+// Pipeline is the stages of a run, in order.
+type Pipeline []Stage
+
+// Parse validates a configured pipeline. Stages must be known, listed once
+// and in workflow order, with the stages they need; empty is Default.
+func Parse(names []string) (Pipeline, error) {
+	if len(names) == 0 {
+		return Default, nil
+	}
+	var p Pipeline
+	for _, name := range names {
+		stage := Stage(strings.ToLower(strings.TrimSpace(name)))
+		if !slices.Contains(order, stage) {
+			return nil, fmt.Errorf("unknown pipeline stage %q (stages: %s)", name, Pipeline(order))
+		}
+		if p.Has(stage) {
+			return nil, fmt.Errorf("pipeline lists %s twice", stage)
+		}
+		if len(p) > 0 && slices.Index(order, stage) < slices.Index(order, p[len(p)-1]) {
+			return nil, fmt.Errorf("pipeline stage %s must come before %s", stage, p[len(p)-1])
+		}
+		p = append(p, stage)
+	}
+	if !p.Has(Execute) {
+		return nil, fmt.Errorf("pipeline must include %s", Execute)
+	}
+	for _, stage := range p {
+		for _, needed := range requires[stage] {
+			if !p.Has(needed) {
+				return nil, fmt.Errorf("pipeline stage %s requires %s", stage, needed)
+			}
+		}
+	}
+	return p, nil
+}
+
+// Has reports whether the pipeline runs stage.
+func (p Pipeline) Has(stage Stage) bool {
+	return slices.Contains(p, stage)
+}
+
+// Last returns the stage the pipeline ends with.
+func (p Pipeline) Last() Stage {
+	if len(p) == 0 {
+		return ""
+	}
+	return p[len(p)-1]
+}
+
+// String lists the stages, comma-separated.
+func (p Pipeline) String() string {
+	names := make([]string, len(p))
+	for i, stage := range p {
+		names[i] = string(stage)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,38 @@
+package pipeline
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	p, err := Parse(nil)
+	if err != nil || p.String() != Default.String() {
+		t.Errorf("Parse(nil) = %v, %v", p, err)
+	}
+
+	p, err = Parse([]string{"execute", "Lint", "test"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.Has(Lint) || p.Has(Plan) || p.Has(Review) || p.Last() != Test {
+		t.Errorf("Parse = %v", p)
+	}
+
+	tests := []struct {
+		names []string
+		err   string
+	}{
+		{[]string{"plan", "execute", "deploy"}, `unknown pipeline stage "deploy"`},
+		{[]string{"execute", "test", "test"}, "pipeline lists test twice"},
+		{[]string{"execute", "review", "test"}, "pipeline stage test must come before review"},
+		{[]string{"plan", "test"}, "pipeline must include execute"},
+		{[]string{"execute", "refactor", "commit"}, "pipeline stage refactor requires review"},
+		{[]string{"execute", "test", "pr"}, "pipeline stage pr requires commit"},
+	}
+	for _, tt := range tests {
+		if _, err := Parse(tt.names); err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("Parse(%v) error = %v, want %q", tt.names, err, tt.err)
+		}
+	}
+}
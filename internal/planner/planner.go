@@ -6,7 +6,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
@@ -14,6 +17,7 @@ import (
 	"github.com/philjestin/boatmanmode/internal/config"
 	"github.com/philjestin/boatmanmode/internal/cost"
 	"github.com/philjestin/boatmanmode/internal/linear"
+	"github.com/philjestin/boatmanmode/internal/stacktrace"
 	"github.com/philjestin/boatmanmode/internal/task"
 )
 
@@ -45,6 +49,11 @@ type Plan struct {
 type Planner struct {
 	client       *claude.Client
 	worktreePath string
+	failureModes string                // Historical failure warnings from project memory
+	locations    []stacktrace.Location // From the ticket's stack traces and logs
+	specs        string                // Summaries of the specs the ticket links
+	media        string                // The ticket's screenshots and linked pages as text
+	out          io.Writer             // Progress output; stdout when nil
 }
 
 // New creates a new Planner agent.
@@ -63,9 +72,11 @@ func New(worktreePath string, cfg *config.Config) *Planner {
 	if cfg.Claude.Models.Planner != "" {
 		client.Model = cfg.Claude.Models.Planner
 	}
+	client.Sampling = cfg.Claude.SamplingFor("planner")
 
 	// Note: Prompt caching is automatically handled by Claude CLI
 	client.EnablePromptCaching = cfg.Claude.EnablePromptCaching
+	client.Configure(cfg.Claude)
 
 	return &Planner{
 		client:       client,
@@ -73,9 +84,49 @@ func New(worktreePath string, cfg *config.Config) *Planner {
 	}
 }
 
+// SetOutput sends the planner's progress output, including the agent's
+// streamed response, to w.
+func (p *Planner) SetOutput(w io.Writer) {
+	p.out = w
+	p.client.Output = w
+}
+
+// output returns where progress output goes.
+func (p *Planner) output() io.Writer {
+	if p.out == nil {
+		return os.Stdout
+	}
+	return p.out
+}
+
+// SetFailureModes gives the planner the points where past runs in this
+// repo failed, so its plan can warn about them.
+func (p *Planner) SetFailureModes(note string) {
+	p.failureModes = note
+}
+
+// SetStackLocations gives the planner the repo locations the ticket's
+// stack traces and logs point at. They are where it starts exploring, and
+// lead the plan's relevant files.
+func (p *Planner) SetStackLocations(locations []stacktrace.Location) {
+	p.locations = locations
+}
+
+// SetSpecs gives the planner the summarized specs the ticket links to, as
+// written by specs.Summarize.
+func (p *Planner) SetSpecs(note string) {
+	p.specs = note
+}
+
+// SetMedia gives the planner the ticket's screenshots and linked pages as
+// text, as written by ingest.Render.
+func (p *Planner) SetMedia(note string) {
+	p.media = note
+}
+
 // Analyze runs the planning agent to understand the task.
 func (p *Planner) Analyze(ctx context.Context, t task.Task) (*Plan, *cost.Usage, error) {
-	fmt.Println("   🧠 Running planning agent...")
+	fmt.Fprintln(p.output(), "   🧠 Running planning agent...")
 
 	systemPrompt := `You are a senior software architect planning a development task.
 Your job is to analyze the task and codebase to create a focused execution plan.
@@ -128,8 +179,23 @@ Analyze this task and explore the codebase to create an execution plan.
 Focus on understanding existing patterns before proposing new code.`,
 		t.GetTitle(),
 		t.GetDescription())
+	if p.failureModes != "" {
+		prompt += "\n\n" + p.failureModes + "\nInclude warnings in your plan for any of these that apply to this task."
+	}
+	if p.specs != "" {
+		prompt += "\n\n" + p.specs
+	}
+	if p.media != "" {
+		prompt += "\n\n" + p.media
+	}
+	if len(p.locations) > 0 {
+		prompt += "\n\n## Stack Trace Locations\nThe ticket's stack traces and logs point at these locations; start exploring there:\n"
+		for _, l := range p.locations {
+			prompt += "- " + l.String() + "\n"
+		}
+	}
 
-	fmt.Println("   📝 Analyzing task and exploring codebase...")
+	fmt.Fprintln(p.output(), "   📝 Analyzing task and exploring codebase...")
 
 	start := time.Now()
 	response, usage, err := p.client.Message(ctx, systemPrompt, prompt)
@@ -139,29 +205,45 @@ Focus on understanding existing patterns before proposing new code.`,
 		return nil, nil, fmt.Errorf("planning agent failed: %w", err)
 	}
 
-	fmt.Printf("   ⏱️  Planning completed in %s\n", elapsed.Round(time.Second))
+	fmt.Fprintf(p.output(), "   ⏱️  Planning completed in %s\n", elapsed.Round(time.Second))
 
 	// Parse the JSON plan from response
 	plan, err := p.parsePlan(response)
 	if err != nil {
-		fmt.Printf("   ⚠️  Could not parse plan JSON: %v\n", err)
+		fmt.Fprintf(p.output(), "   ⚠️  Could not parse plan JSON: %v\n", err)
 		// Return a basic plan from the response
 		return &Plan{
-			Summary:  "Planning agent explored codebase",
-			Approach: []string{"See planning agent output for details"},
+			Summary:       "Planning agent explored codebase",
+			Approach:      []string{"See planning agent output for details"},
+			RelevantFiles: stacktrace.Files(p.locations),
 		}, usage, nil
 	}
+	plan.seedFiles(stacktrace.Files(p.locations))
 
 	// Display plan summary
-	fmt.Printf("   📋 Plan: %s\n", plan.Summary)
-	fmt.Printf("   📁 Found %d relevant files\n", len(plan.RelevantFiles))
+	fmt.Fprintf(p.output(), "   📋 Plan: %s\n", plan.Summary)
+	fmt.Fprintf(p.output(), "   📁 Found %d relevant files\n", len(plan.RelevantFiles))
 	if len(plan.Approach) > 0 {
-		fmt.Printf("   📝 Approach: %d steps\n", len(plan.Approach))
+		fmt.Fprintf(p.output(), "   📝 Approach: %d steps\n", len(plan.Approach))
 	}
 
 	return plan, usage, nil
 }
 
+// seedFiles puts files first in the plan's relevant files.
+func (plan *Plan) seedFiles(files []string) {
+	if len(files) == 0 {
+		return
+	}
+	seeded := append([]string{}, files...)
+	for _, f := range plan.RelevantFiles {
+		if !slices.Contains(seeded, f) {
+			seeded = append(seeded, f)
+		}
+	}
+	plan.RelevantFiles = seeded
+}
+
 // AnalyzeTicket is a backward-compatibility wrapper for Linear tickets.
 func (p *Planner) AnalyzeTicket(ctx context.Context, ticket *linear.Ticket) (*Plan, *cost.Usage, error) {
 	return p.Analyze(ctx, task.NewLinearTask(ticket))
@@ -0,0 +1,190 @@
+// Package postmerge verifies the base branch once a boatman PR merges: the
+// merge commit is built and smoke tested in a scratch worktree, so a PR
+// that passed review but broke main is caught and attributed to its run.
+package postmerge
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/automerge"
+	"github.com/philjestin/boatmanmode/internal/cmdoutput"
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/forge"
+	"github.com/philjestin/boatmanmode/internal/gitops"
+	"github.com/philjestin/boatmanmode/internal/retry"
+)
+
+// outputLines is how much of a failing command's output is kept.
+const outputLines = 30
+
+// Steps of the verification.
+const (
+	StepBuild = "build"
+	StepSmoke = "smoke"
+)
+
+// ErrNothingToRun is returned when neither a build nor a smoke command is
+// configured.
+var ErrNothingToRun = errors.New("no build or smoke command configured (set pr.post_merge.build/smoke or commands.build/test)")
+
+// GitHub is the PR access the checker needs.
+type GitHub interface {
+	ViewPR(ctx context.Context, workDir, pr string) (*forge.PRInfo, error)
+}
+
+// GH is the GitHub implementation backed by the gh CLI.
+type GH struct{}
+
+// ViewPR runs gh pr view.
+func (GH) ViewPR(ctx context.Context, workDir, pr string) (*forge.PRInfo, error) {
+	return forge.ViewPR(ctx, workDir, pr)
+}
+
+// Result is the outcome of verifying a merge commit.
+type Result struct {
+	Commit string
+	// Failed is the step that failed, or "" if both passed.
+	Failed string
+	// Output is the end of the failing command's output.
+	Output string
+}
+
+// Passed reports whether the base branch built and passed its smoke tests.
+func (r *Result) Passed() bool {
+	return r.Failed == ""
+}
+
+// Checker waits for a PR to merge and verifies its merge commit.
+type Checker struct {
+	cfg      config.PostMergeConfig
+	github   GitHub
+	repoPath string
+	git      *gitops.Repo
+	log      func(format string, args ...any)
+	sleep    func(ctx context.Context, d time.Duration) error
+	run      func(ctx context.Context, dir, command string) (string, error)
+}
+
+// New creates a Checker for the repository at repoPath. Blank build and
+// smoke commands fall back to the project's build and test commands.
+func New(cfg config.PostMergeConfig, commands config.CommandsConfig, gh GitHub, repoPath string) *Checker {
+	if cfg.Build == "" {
+		cfg.Build = commands.Build
+	}
+	if cfg.Smoke == "" {
+		cfg.Smoke = commands.Test
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = 2 * time.Minute
+	}
+	return &Checker{
+		cfg:      cfg,
+		github:   gh,
+		repoPath: repoPath,
+		git:      gitops.New(repoPath),
+		log:      func(format string, args ...any) { fmt.Printf(format+"\n", args...) },
+		sleep:    retry.Sleep,
+		run:      run,
+	}
+}
+
+// WaitForMerge polls the PR at url until it merges, is closed, or the
+// timeout passes, and returns the merged PR.
+func (c *Checker) WaitForMerge(ctx context.Context, url string) (*forge.PRInfo, error) {
+	if c.cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.cfg.Timeout)
+		defer cancel()
+	}
+
+	for {
+		pr, err := c.github.ViewPR(ctx, c.repoPath, url)
+		if err != nil {
+			// gh hiccups shouldn't end a wait that may last days
+			c.log("⚠️  Could not check %s: %v", url, err)
+		} else {
+			switch pr.State {
+			case forge.PRStateMerged:
+				if pr.MergeCommit != nil {
+					return pr, nil
+				}
+			case forge.PRStateClosed:
+				return nil, fmt.Errorf("%s: %w", url, automerge.ErrClosed)
+			}
+		}
+
+		if err := c.sleep(ctx, c.cfg.Interval); err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				return nil, fmt.Errorf("%s: %w after %s", url, automerge.ErrTimeout, c.cfg.Timeout)
+			}
+			return nil, err
+		}
+	}
+}
+
+// Verify builds and smoke tests the merge commit of pr in a scratch
+// worktree, which is removed afterwards.
+func (c *Checker) Verify(ctx context.Context, pr *forge.PRInfo) (*Result, error) {
+	if c.cfg.Build == "" && c.cfg.Smoke == "" {
+		return nil, ErrNothingToRun
+	}
+	commit := pr.MergeCommit.OID
+	git := c.git.WithContext(ctx)
+	if err := git.Fetch("origin", pr.BaseBranch); err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", pr.BaseBranch, err)
+	}
+	dir, err := os.MkdirTemp("", "boatman-postmerge-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	if err := git.WorktreeAdd(dir, commit, false, "", "--detach"); err != nil {
+		return nil, fmt.Errorf("failed to check out %s: %w", commit, err)
+	}
+	defer git.WorktreeRemove(dir, true)
+
+	result := &Result{Commit: commit}
+	for _, step := range []struct{ name, command string }{
+		{StepBuild, c.cfg.Build},
+		{StepSmoke, c.cfg.Smoke},
+	} {
+		if step.command == "" {
+			continue
+		}
+		c.log("🔨 Running %s: %s", step.name, step.command)
+		if out, err := c.run(ctx, dir, step.command); err != nil {
+			result.Failed = step.name
+			result.Output = cmdoutput.Tail(out, outputLines)
+			break
+		}
+	}
+	return result, nil
+}
+
+// Describe summarizes the result, e.g. "smoke failed on abc1234".
+func (r *Result) Describe() string {
+	commit := r.Commit
+	if len(commit) > 7 {
+		commit = commit[:7]
+	}
+	if r.Passed() {
+		return "passed on " + commit
+	}
+	return fmt.Sprintf("%s failed on %s", r.Failed, commit)
+}
+
+func run(ctx context.Context, dir, command string) (string, error) {
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = dir
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	err := cmd.Run()
+	return out.String(), err
+}
@@ -0,0 +1,136 @@
+package postmerge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/automerge"
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/forge"
+	"github.com/philjestin/boatmanmode/internal/gitops"
+)
+
+// fakeGitHub returns each PR in turn, repeating the last.
+type fakeGitHub struct {
+	prs   []*forge.PRInfo
+	polls int
+}
+
+func (f *fakeGitHub) ViewPR(ctx context.Context, workDir, pr string) (*forge.PRInfo, error) {
+	info := f.prs[min(f.polls, len(f.prs)-1)]
+	f.polls++
+	if info == nil {
+		return nil, errors.New("gh: network down")
+	}
+	return info, nil
+}
+
+func merged(commit string) *forge.PRInfo {
+	pr := &forge.PRInfo{State: forge.PRStateMerged, BaseBranch: "main"}
+	pr.MergeCommit = &struct {
+		OID string `json:"oid"`
+	}{commit}
+	return pr
+}
+
+func newTestChecker(cfg config.PostMergeConfig, commands config.CommandsConfig, gh GitHub) (*Checker, *[]string) {
+	c := New(cfg, commands, gh, "/repo")
+	var logs []string
+	c.log = func(format string, args ...any) { logs = append(logs, fmt.Sprintf(format, args...)) }
+	c.sleep = func(ctx context.Context, d time.Duration) error { return ctx.Err() }
+	return c, &logs
+}
+
+func TestWaitForMerge(t *testing.T) {
+	gh := &fakeGitHub{prs: []*forge.PRInfo{
+		{State: forge.PRStateOpen},
+		nil,
+		merged("0123456789"),
+	}}
+	c, logs := newTestChecker(config.PostMergeConfig{}, config.CommandsConfig{}, gh)
+
+	pr, err := c.WaitForMerge(context.Background(), "https://github.com/acme/web/pull/7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr.MergeCommit.OID != "0123456789" || gh.polls != 3 {
+		t.Errorf("pr = %+v after %d polls", pr, gh.polls)
+	}
+	if len(*logs) != 1 || !strings.Contains((*logs)[0], "network down") {
+		t.Errorf("logs = %q", *logs)
+	}
+}
+
+func TestWaitForMergeGivesUp(t *testing.T) {
+	c, _ := newTestChecker(config.PostMergeConfig{}, config.CommandsConfig{},
+		&fakeGitHub{prs: []*forge.PRInfo{{State: forge.PRStateClosed}}})
+	if _, err := c.WaitForMerge(context.Background(), "https://github.com/acme/web/pull/7"); !errors.Is(err, automerge.ErrClosed) {
+		t.Errorf("err = %v, want ErrClosed", err)
+	}
+
+	c, _ = newTestChecker(config.PostMergeConfig{Timeout: time.Nanosecond}, config.CommandsConfig{},
+		&fakeGitHub{prs: []*forge.PRInfo{{State: forge.PRStateOpen}}})
+	if _, err := c.WaitForMerge(context.Background(), "https://github.com/acme/web/pull/7"); !errors.Is(err, automerge.ErrTimeout) {
+		t.Errorf("err = %v, want ErrTimeout", err)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name    string
+		failing string
+		want    string
+	}{
+		{"passes", "", "passed on 0123456"},
+		{"build fails", "make", "build failed on 0123456"},
+		{"smoke fails", "make smoke", "smoke failed on 0123456"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Smoke falls back to the test command
+			c, _ := newTestChecker(config.PostMergeConfig{Build: "make"}, config.CommandsConfig{Test: "make smoke"}, nil)
+			runner := gitops.NewFakeRunner()
+			c.git = gitops.NewWithRunner("/repo", runner)
+			var ran []string
+			c.run = func(ctx context.Context, dir, command string) (string, error) {
+				ran = append(ran, command)
+				if command == tt.failing {
+					return "compiling\nundefined: Foo\n", errors.New("exit status 1")
+				}
+				return "ok\n", nil
+			}
+
+			result, err := c.Verify(context.Background(), merged("0123456789"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := result.Describe(); got != tt.want {
+				t.Errorf("Describe() = %q, want %q", got, tt.want)
+			}
+			if tt.failing != "" && result.Output != "compiling\nundefined: Foo" {
+				t.Errorf("Output = %q", result.Output)
+			}
+			if tt.failing == "make" && len(ran) != 1 {
+				t.Errorf("smoke tests ran after the build failed: %v", ran)
+			}
+
+			calls := runner.Calls()
+			if len(calls) != 3 || calls[0] != "fetch origin main" ||
+				!strings.HasPrefix(calls[1], "worktree add --detach ") || !strings.HasSuffix(calls[1], " 0123456789") ||
+				!strings.HasPrefix(calls[2], "worktree remove ") {
+				t.Errorf("git calls = %q", calls)
+			}
+		})
+	}
+}
+
+func TestVerifyWithoutCommands(t *testing.T) {
+	c, _ := newTestChecker(config.PostMergeConfig{}, config.CommandsConfig{}, nil)
+	if _, err := c.Verify(context.Background(), merged("0123456789")); !errors.Is(err, ErrNothingToRun) {
+		t.Errorf("err = %v, want ErrNothingToRun", err)
+	}
+}
@@ -0,0 +1,194 @@
+// Package prsections fills the PR body sections a team requires (rollback
+// plan, feature flag, security considerations, ...) from the run, and
+// checks none is left empty before the PR is created.
+package prsections
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/claude"
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/cost"
+)
+
+// maxDiff caps the diff shown to the writer, in characters.
+const maxDiff = 20000
+
+// Run is what the writer sees of a finished run.
+type Run struct {
+	Title         string
+	Description   string
+	PlanSummary   string
+	FilesChanged  []string
+	Diff          string
+	ReviewSummary string
+	TestSummary   string
+}
+
+// Writer is a Claude agent that writes the required sections.
+type Writer struct {
+	client *claude.Client
+}
+
+// New creates a Writer. It runs on the reviewer model, which has already
+// judged the same changes.
+func New(worktreePath string, cfg *config.Config) *Writer {
+	client := claude.NewWithTmux(worktreePath, "pr-sections")
+	if cfg.Claude.Models.Reviewer != "" {
+		client.Model = cfg.Claude.Models.Reviewer
+	}
+	client.Sampling = cfg.Claude.SamplingFor("reviewer")
+	client.Configure(cfg.Claude)
+	return &Writer{client: client}
+}
+
+// Write fills every section from the run, asking once more for any the
+// first answer left empty. Returns an error naming the sections still
+// empty after that.
+func (w *Writer) Write(ctx context.Context, sections []config.PRSection, run Run) (map[string]string, *cost.Usage, error) {
+	filled := map[string]string{}
+	total := &cost.Usage{}
+	pending := sections
+	for attempt := 0; attempt < 2 && len(pending) > 0; attempt++ {
+		response, usage, err := w.client.Message(ctx, systemPrompt(pending), run.Prompt())
+		if usage != nil {
+			*total = total.Add(*usage)
+		}
+		if err != nil {
+			return nil, total, fmt.Errorf("PR section writer failed: %w", err)
+		}
+		answer, err := parseSections(response)
+		if err != nil && attempt == 0 {
+			continue
+		}
+		for title, content := range answer {
+			if strings.TrimSpace(content) != "" {
+				filled[title] = content
+			}
+		}
+		pending = Missing(sections, filled)
+	}
+	if len(pending) > 0 {
+		titles := make([]string, len(pending))
+		for i, s := range pending {
+			titles[i] = s.Title
+		}
+		return nil, total, fmt.Errorf("required PR sections left empty: %s", strings.Join(titles, ", "))
+	}
+	return filled, total, nil
+}
+
+func systemPrompt(sections []config.PRSection) string {
+	var sb strings.Builder
+	sb.WriteString(`You are writing required sections of a pull request description for an automated development run.
+Write each section below from the run's task, plan, diff, review and test results. Be specific to this
+change and concise; use markdown lists where they help. If a section does not apply, say so and why
+in one sentence - never leave it empty.
+
+Sections:
+`)
+	for _, s := range sections {
+		sb.WriteString("- " + s.Title)
+		if s.Prompt != "" {
+			sb.WriteString(": " + s.Prompt)
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(`
+Output a JSON block in this exact format, keyed by the section titles above:
+
+` + "```json" + `
+{"sections": {"Section title": "markdown content"}}
+` + "```")
+	return sb.String()
+}
+
+// Prompt formats the run for the writer.
+func (run Run) Prompt() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Run: %s\n\n", run.Title))
+	if run.Description != "" {
+		sb.WriteString(fmt.Sprintf("## Task\n%s\n\n", run.Description))
+	}
+	if run.PlanSummary != "" {
+		sb.WriteString(fmt.Sprintf("## Plan\n%s\n\n", run.PlanSummary))
+	}
+	if len(run.FilesChanged) > 0 {
+		sb.WriteString("## Files Changed\n")
+		for _, f := range run.FilesChanged {
+			sb.WriteString(fmt.Sprintf("- %s\n", f))
+		}
+		sb.WriteString("\n")
+	}
+	if run.ReviewSummary != "" {
+		sb.WriteString(fmt.Sprintf("## Review\n%s\n\n", run.ReviewSummary))
+	}
+	if run.TestSummary != "" {
+		sb.WriteString(fmt.Sprintf("## Tests\n%s\n\n", run.TestSummary))
+	}
+	if run.Diff != "" {
+		diff := run.Diff
+		if len(diff) > maxDiff {
+			diff = diff[:maxDiff] + "\n... (diff truncated)"
+		}
+		sb.WriteString("## Diff\n```diff\n" + diff + "\n```\n")
+	}
+	return sb.String()
+}
+
+var jsonBlock = regexp.MustCompile("```(?:json)?\\s*\\n?([\\s\\S]*?)\\n?```")
+
+// parseSections extracts the section contents from the writer's response.
+func parseSections(response string) (map[string]string, error) {
+	jsonStr := response
+	if m := jsonBlock.FindStringSubmatch(response); len(m) > 1 {
+		jsonStr = m[1]
+	} else if start, end := strings.Index(response, "{"), strings.LastIndex(response, "}"); start >= 0 && end > start {
+		jsonStr = response[start : end+1]
+	}
+
+	var answer struct {
+		Sections map[string]string `json:"sections"`
+	}
+	if err := json.Unmarshal([]byte(jsonStr), &answer); err != nil {
+		return nil, fmt.Errorf("invalid PR sections JSON: %w", err)
+	}
+	return answer.Sections, nil
+}
+
+// Missing returns the sections with no content in filled. Titles match
+// case-insensitively.
+func Missing(sections []config.PRSection, filled map[string]string) []config.PRSection {
+	var missing []config.PRSection
+	for _, s := range sections {
+		if strings.TrimSpace(lookup(filled, s.Title)) == "" {
+			missing = append(missing, s)
+		}
+	}
+	return missing
+}
+
+// Markdown renders the filled sections in configured order.
+func Markdown(sections []config.PRSection, filled map[string]string) string {
+	var sb strings.Builder
+	for _, s := range sections {
+		sb.WriteString(fmt.Sprintf("### %s\n%s\n\n", s.Title, strings.TrimSpace(lookup(filled, s.Title))))
+	}
+	return sb.String()
+}
+
+func lookup(filled map[string]string, title string) string {
+	if content, ok := filled[title]; ok {
+		return content
+	}
+	for t, content := range filled {
+		if strings.EqualFold(strings.TrimSpace(t), title) {
+			return content
+		}
+	}
+	return ""
+}
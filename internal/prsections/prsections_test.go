@@ -0,0 +1,56 @@
+package prsections
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+)
+
+func TestParseSections(t *testing.T) {
+	response := "Here are the sections:\n\n```json\n" +
+		`{"sections": {"Rollback plan": "Revert the commit.", "feature flag": "- multiply_enabled"}}` + "\n```"
+
+	filled, err := parseSections(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filled["Rollback plan"] != "Revert the commit." {
+		t.Errorf("Unexpected sections: %+v", filled)
+	}
+	if _, err := parseSections("no sections"); err == nil {
+		t.Error("Expected an error without JSON")
+	}
+}
+
+func TestMissingAndMarkdown(t *testing.T) {
+	sections := []config.PRSection{{Title: "Rollback plan"}, {Title: "Feature flag"}, {Title: "Security considerations"}}
+	filled := map[string]string{"Rollback plan": "Revert the commit.", "feature flag": "- multiply_enabled", "Security considerations": "  "}
+
+	missing := Missing(sections, filled)
+	if len(missing) != 1 || missing[0].Title != "Security considerations" {
+		t.Errorf("Expected only the blank section missing, got %+v", missing)
+	}
+
+	md := Markdown(sections[:2], filled)
+	want := "### Rollback plan\nRevert the commit.\n\n### Feature flag\n- multiply_enabled\n\n"
+	if md != want {
+		t.Errorf("Unexpected markdown:\n%s", md)
+	}
+}
+
+func TestRunPrompt(t *testing.T) {
+	run := Run{
+		Title:        "Add Multiply",
+		PlanSummary:  "Add Multiply to util",
+		FilesChanged: []string{"pkg/util/util.go"},
+		Diff:         strings.Repeat("+x\n", maxDiff),
+	}
+
+	prompt := run.Prompt()
+	for _, want := range []string{"# Run: Add Multiply", "## Plan\nAdd Multiply to util", "- pkg/util/util.go", "(diff truncated)"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected %q in prompt", want)
+		}
+	}
+}
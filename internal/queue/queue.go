@@ -0,0 +1,236 @@
+// Package queue orders the tickets waiting in daemon mode. Tickets are
+// taken by Linear priority, optionally sharing runs fairly between
+// projects, and only during the configured working hours.
+package queue
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+)
+
+// Item is a queued ticket.
+type Item struct {
+	TicketID string
+
+	// Project groups tickets for fair sharing. Empty uses the ticket ID's
+	// team key, e.g. ENG for ENG-123.
+	Project string
+
+	// Priority is Linear's: 1 urgent, 2 high, 3 medium, 4 low and 0 none.
+	Priority int
+
+	// Queued is when the ticket was queued. Zero uses the time of Push.
+	Queued time.Time
+}
+
+// Queue holds tickets until they are scheduled. It is safe for concurrent
+// use.
+type Queue struct {
+	mu        sync.Mutex
+	items     []Item
+	fairShare bool
+	window    *Window
+	runs      map[string]int
+}
+
+// New creates a Queue with the given scheduling policy.
+func New(cfg config.ScheduleConfig) (*Queue, error) {
+	window, err := NewWindow(cfg.WorkingHours)
+	if err != nil {
+		return nil, err
+	}
+	return &Queue{fairShare: cfg.FairShare, window: window, runs: make(map[string]int)}, nil
+}
+
+// Push queues a ticket. Returns false if the ticket is already queued.
+func (q *Queue) Push(item Item) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, queued := range q.items {
+		if queued.TicketID == item.TicketID {
+			return false
+		}
+	}
+	if item.Project == "" {
+		item.Project = Project(item.TicketID)
+	}
+	if item.Queued.IsZero() {
+		item.Queued = time.Now()
+	}
+	q.items = append(q.items, item)
+	return true
+}
+
+// Next removes and returns the ticket to run now. Returns false when the
+// queue is empty or now is outside working hours.
+func (q *Queue) Next(now time.Time) (Item, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.items) == 0 || !q.window.Open(now) {
+		return Item{}, false
+	}
+	sort.SliceStable(q.items, func(i, j int) bool {
+		return q.before(q.items[i], q.items[j])
+	})
+	item := q.items[0]
+	q.items = q.items[1:]
+	q.runs[item.Project]++
+	return item, true
+}
+
+// before reports whether a should run before b.
+func (q *Queue) before(a, b Item) bool {
+	if ra, rb := rank(a.Priority), rank(b.Priority); ra != rb {
+		return ra < rb
+	}
+	if q.fairShare {
+		if ra, rb := q.runs[a.Project], q.runs[b.Project]; ra != rb {
+			return ra < rb
+		}
+	}
+	return a.Queued.Before(b.Queued)
+}
+
+// rank orders Linear priorities, which put "no priority" at 0.
+func rank(priority int) int {
+	if priority <= 0 {
+		return 5
+	}
+	return priority
+}
+
+// Len returns the number of queued tickets.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
+
+// Items returns the queued tickets in the order they would run.
+func (q *Queue) Items() []Item {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	items := append([]Item(nil), q.items...)
+	sort.SliceStable(items, func(i, j int) bool {
+		return q.before(items[i], items[j])
+	})
+	return items
+}
+
+// Wait returns how long until tickets may start, 0 during working hours.
+func (q *Queue) Wait(now time.Time) time.Duration {
+	if q.window.Open(now) {
+		return 0
+	}
+	return q.window.NextOpen(now).Sub(now)
+}
+
+// Project returns the team key of a ticket ID, e.g. ENG for ENG-123.
+func Project(ticketID string) string {
+	if i := strings.LastIndex(ticketID, "-"); i > 0 {
+		return ticketID[:i]
+	}
+	return ticketID
+}
+
+// Window is a daily working-hours window. A nil Window is always open.
+type Window struct {
+	start, end int // minutes since midnight
+	days       map[time.Weekday]bool
+	loc        *time.Location
+}
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// NewWindow parses working hours. Returns nil when no hours are set.
+func NewWindow(cfg config.WorkingHoursConfig) (*Window, error) {
+	if cfg.Start == "" && cfg.End == "" {
+		return nil, nil
+	}
+	start, err := parseClock(cfg.Start)
+	if err != nil {
+		return nil, fmt.Errorf("daemon.schedule.working_hours.start: %w", err)
+	}
+	end, err := parseClock(cfg.End)
+	if err != nil {
+		return nil, fmt.Errorf("daemon.schedule.working_hours.end: %w", err)
+	}
+	if start == end {
+		return nil, fmt.Errorf("daemon.schedule.working_hours: start and end are both %s", cfg.Start)
+	}
+	w := &Window{start: start, end: end, loc: time.Local}
+	if cfg.Timezone != "" {
+		if w.loc, err = time.LoadLocation(cfg.Timezone); err != nil {
+			return nil, fmt.Errorf("daemon.schedule.working_hours.timezone: %w", err)
+		}
+	}
+	if len(cfg.Days) > 0 {
+		w.days = make(map[time.Weekday]bool)
+		for _, day := range cfg.Days {
+			day := strings.ToLower(day)
+			wd, ok := weekdays[day[:min(3, len(day))]]
+			if !ok {
+				return nil, fmt.Errorf("daemon.schedule.working_hours.days: unknown day %q", day)
+			}
+			w.days[wd] = true
+		}
+	}
+	return w, nil
+}
+
+// parseClock parses an HH:MM time into minutes since midnight.
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("want HH:MM, got %q", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Open reports whether t is within working hours. A window that spans
+// midnight belongs to the day it starts on.
+func (w *Window) Open(t time.Time) bool {
+	if w == nil {
+		return true
+	}
+	t = t.In(w.loc)
+	minute := t.Hour()*60 + t.Minute()
+	switch {
+	case w.start < w.end:
+		return w.day(t.Weekday()) && minute >= w.start && minute < w.end
+	case minute >= w.start:
+		return w.day(t.Weekday())
+	default:
+		return minute < w.end && w.day(t.AddDate(0, 0, -1).Weekday())
+	}
+}
+
+func (w *Window) day(wd time.Weekday) bool {
+	return w.days == nil || w.days[wd]
+}
+
+// NextOpen returns when the window next opens after t, or t if it is
+// open.
+func (w *Window) NextOpen(t time.Time) time.Time {
+	if w.Open(t) {
+		return t
+	}
+	local := t.In(w.loc)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, w.loc)
+	for i := 0; i <= 7; i++ {
+		day := midnight.AddDate(0, 0, i)
+		open := time.Date(day.Year(), day.Month(), day.Day(), w.start/60, w.start%60, 0, 0, w.loc)
+		if open.After(t) && w.day(open.Weekday()) {
+			return open
+		}
+	}
+	return t
+}
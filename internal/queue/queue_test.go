@@ -0,0 +1,145 @@
+package queue
+
+import (
+	"testing"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+)
+
+var base = time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC) // a Monday
+
+func order(q *Queue) []string {
+	var ids []string
+	for {
+		item, ok := q.Next(base)
+		if !ok {
+			return ids
+		}
+		ids = append(ids, item.TicketID)
+	}
+}
+
+func TestNextByPriority(t *testing.T) {
+	q, _ := New(config.ScheduleConfig{})
+	q.Push(Item{TicketID: "ENG-1", Priority: 0, Queued: base})
+	q.Push(Item{TicketID: "ENG-2", Priority: 4, Queued: base.Add(time.Minute)})
+	q.Push(Item{TicketID: "ENG-3", Priority: 1, Queued: base.Add(2 * time.Minute)})
+	q.Push(Item{TicketID: "ENG-4", Priority: 4, Queued: base.Add(-time.Minute)})
+	if q.Push(Item{TicketID: "ENG-3", Priority: 2}) {
+		t.Error("Push accepted a ticket already queued")
+	}
+
+	got := order(q)
+	want := []string{"ENG-3", "ENG-4", "ENG-2", "ENG-1"}
+	if len(got) != len(want) {
+		t.Fatalf("order = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("order = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestNextFairShare(t *testing.T) {
+	q, _ := New(config.ScheduleConfig{FairShare: true})
+	q.Push(Item{TicketID: "ENG-1", Priority: 3, Queued: base})
+	q.Push(Item{TicketID: "ENG-2", Priority: 3, Queued: base.Add(time.Minute)})
+	q.Push(Item{TicketID: "OPS-1", Priority: 3, Queued: base.Add(2 * time.Minute)})
+	q.Push(Item{TicketID: "OPS-2", Priority: 1, Queued: base.Add(3 * time.Minute)})
+
+	got := order(q)
+	want := []string{"OPS-2", "ENG-1", "ENG-2", "OPS-1"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("order = %v, want %v", got, want)
+		}
+	}
+
+	q.Push(Item{TicketID: "ENG-3", Priority: 3, Queued: base})
+	q.Push(Item{TicketID: "WEB-1", Priority: 3, Queued: base.Add(time.Hour)})
+	if item, _ := q.Next(base); item.TicketID != "WEB-1" {
+		t.Errorf("next = %s, want WEB-1 from the project with no runs", item.TicketID)
+	}
+}
+
+func TestWindow(t *testing.T) {
+	w, err := NewWindow(config.WorkingHoursConfig{Start: "09:00", End: "18:00", Days: []string{"mon", "tue", "wed", "thu", "fri"}, Timezone: "UTC"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		at   time.Time
+		open bool
+		next time.Time
+	}{
+		{base, true, base},
+		{base.Add(-2 * time.Hour), false, base.Add(-time.Hour)},
+		{base.Add(8 * time.Hour), false, base.Add(23 * time.Hour)},
+		{base.AddDate(0, 0, 4).Add(9 * time.Hour), false, base.AddDate(0, 0, 7).Add(-time.Hour)}, // Friday night
+	}
+	for _, tt := range tests {
+		if got := w.Open(tt.at); got != tt.open {
+			t.Errorf("Open(%s) = %v, want %v", tt.at, got, tt.open)
+		}
+		if got := w.NextOpen(tt.at); !got.Equal(tt.next) {
+			t.Errorf("NextOpen(%s) = %s, want %s", tt.at, got, tt.next)
+		}
+	}
+}
+
+func TestWindowOvernight(t *testing.T) {
+	w, _ := NewWindow(config.WorkingHoursConfig{Start: "22:00", End: "06:00", Days: []string{"mon"}, Timezone: "UTC"})
+	monday := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	if !w.Open(monday.Add(23*time.Hour)) || !w.Open(monday.Add(29*time.Hour)) {
+		t.Error("window should be open from Monday 22:00 to Tuesday 06:00")
+	}
+	if w.Open(monday.Add(3 * time.Hour)) {
+		t.Error("Monday 03:00 belongs to Sunday's window, which is off")
+	}
+}
+
+func TestQueueOutsideHours(t *testing.T) {
+	q, err := New(config.ScheduleConfig{WorkingHours: config.WorkingHoursConfig{Start: "09:00", End: "18:00", Timezone: "UTC"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	q.Push(Item{TicketID: "ENG-1"})
+	night := base.Add(17 * time.Hour) // 03:00
+	if _, ok := q.Next(night); ok {
+		t.Error("Next scheduled a ticket at 03:00")
+	}
+	if wait := q.Wait(night); wait != 6*time.Hour {
+		t.Errorf("Wait = %s, want 6h", wait)
+	}
+	if _, ok := q.Next(base); !ok || q.Len() != 0 {
+		t.Error("Next did not schedule the ticket during working hours")
+	}
+}
+
+func TestNewWindowErrors(t *testing.T) {
+	for _, cfg := range []config.WorkingHoursConfig{
+		{Start: "9am", End: "18:00"},
+		{Start: "09:00", End: "09:00"},
+		{Start: "09:00", End: "18:00", Days: []string{"someday"}},
+		{Start: "09:00", End: "18:00", Days: []string{"ẞ"}},
+		{Start: "09:00", End: "18:00", Timezone: "Mars/Olympus"},
+	} {
+		if _, err := NewWindow(cfg); err == nil {
+			t.Errorf("NewWindow(%+v) succeeded", cfg)
+		}
+	}
+	if w, err := NewWindow(config.WorkingHoursConfig{}); w != nil || err != nil {
+		t.Errorf("NewWindow without hours = %v, %v", w, err)
+	}
+}
+
+func TestProject(t *testing.T) {
+	if p := Project("ENG-123"); p != "ENG" {
+		t.Errorf("Project = %q", p)
+	}
+	if p := Project("prompt-abc"); p != "prompt" {
+		t.Errorf("Project = %q", p)
+	}
+}
@@ -0,0 +1,286 @@
+// Package racecheck looks for concurrency bugs in changed Go code, a common
+// failure mode of generated concurrent code: it runs the affected packages'
+// tests under the race detector and scans the diff for racy patterns.
+// Races and definite racy patterns are critical review issues; likely ones
+// are minor.
+package racecheck
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/scottbott"
+	"github.com/philjestin/boatmanmode/internal/unidiff"
+)
+
+// DefaultTimeout bounds a race detector run across all affected packages.
+const DefaultTimeout = 10 * time.Minute
+
+// Checker checks a worktree's Go changes for data races.
+type Checker struct {
+	worktreePath string
+	timeout      time.Duration
+}
+
+// New creates a Checker for the worktree.
+func New(worktreePath string) *Checker {
+	return &Checker{worktreePath: worktreePath, timeout: DefaultTimeout}
+}
+
+// SetTimeout bounds the race detector run (0 = DefaultTimeout).
+func (c *Checker) SetTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		c.timeout = timeout
+	}
+}
+
+// Check runs the race detector on the packages the diff touches and scans
+// the diff, returning the issues found. Only a race detector that cannot
+// run at all is an error; failing tests are the test runner's business.
+func (c *Checker) Check(ctx context.Context, diff string) ([]scottbott.Issue, error) {
+	issues := Scan(c.worktreePath, diff)
+	raceIssues, err := c.RunRace(ctx, diff)
+	return append(raceIssues, issues...), err
+}
+
+// RunRace runs `go test -race` for each Go package the diff changes and
+// returns a critical issue per distinct race reported.
+func (c *Checker) RunRace(ctx context.Context, diff string) ([]scottbott.Issue, error) {
+	modules := c.affectedPackages(diff)
+	if len(modules) == 0 {
+		return nil, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var issues []scottbott.Issue
+	seen := map[string]bool{}
+	roots := make([]string, 0, len(modules))
+	for root := range modules {
+		roots = append(roots, root)
+	}
+	sort.Strings(roots)
+	for _, root := range roots {
+		args := append([]string{"test", "-race", "-count=1"}, modules[root]...)
+		cmd := exec.CommandContext(ctx, "go", args...)
+		cmd.Dir = filepath.Join(c.worktreePath, root)
+		var out bytes.Buffer
+		cmd.Stdout = &out
+		cmd.Stderr = &out
+		runErr := cmd.Run()
+		output := out.String()
+		if runErr != nil && !strings.Contains(output, raceBanner) && !strings.Contains(output, "FAIL") {
+			return issues, fmt.Errorf("go test -race failed in %s: %s", filepath.Join(".", root), firstLine(output, runErr))
+		}
+		for _, issue := range ParseRaces(output, c.worktreePath) {
+			key := issue.File + "\x00" + issue.Description
+			if !seen[key] {
+				seen[key] = true
+				issues = append(issues, issue)
+			}
+		}
+	}
+	return issues, nil
+}
+
+// affectedPackages groups the changed Go packages, as ./relative patterns,
+// by the module root (relative to the worktree) they belong to.
+func (c *Checker) affectedPackages(diff string) map[string][]string {
+	modules := map[string][]string{}
+	seen := map[string]bool{}
+	for _, f := range unidiff.Parse(diff) {
+		path := f.NewPath
+		if path == "" || !strings.HasSuffix(path, ".go") {
+			continue
+		}
+		dir := filepath.Dir(filepath.FromSlash(path))
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		root, ok := c.moduleRoot(dir)
+		if !ok {
+			continue
+		}
+		rel, _ := filepath.Rel(root, dir)
+		modules[root] = append(modules[root], "./"+filepath.ToSlash(rel))
+	}
+	for root := range modules {
+		sort.Strings(modules[root])
+	}
+	return modules
+}
+
+// moduleRoot finds the directory of the go.mod governing dir.
+func (c *Checker) moduleRoot(dir string) (string, bool) {
+	for {
+		if _, err := os.Stat(filepath.Join(c.worktreePath, dir, "go.mod")); err == nil {
+			return dir, true
+		}
+		if dir == "." || dir == string(filepath.Separator) {
+			return "", false
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+const raceBanner = "WARNING: DATA RACE"
+
+var (
+	raceAccess = regexp.MustCompile(`^(Read|Write|Previous read|Previous write) at 0x[0-9a-f]+ by (goroutine \d+|main goroutine):`)
+	raceFrame  = regexp.MustCompile(`^\s+(\S+\.go):(\d+)`)
+	raceFunc   = regexp.MustCompile(`^\s+(\S+)\(.*\)$`)
+)
+
+// ParseRaces turns race detector reports in go test output into critical
+// issues located at the first frame inside the worktree.
+func ParseRaces(output, worktreePath string) []scottbott.Issue {
+	var issues []scottbott.Issue
+	for _, report := range strings.Split(output, raceBanner)[1:] {
+		if end := strings.Index(report, "=================="); end >= 0 {
+			report = report[:end]
+		}
+		var accesses []string
+		var file, fn, lastFunc string
+		var lineNo int
+		for _, l := range strings.Split(report, "\n") {
+			if m := raceAccess.FindStringSubmatch(l); m != nil {
+				accesses = append(accesses, strings.ToLower(m[1]))
+				continue
+			}
+			if m := raceFunc.FindStringSubmatch(l); m != nil && !strings.Contains(l, ".go:") {
+				lastFunc = m[1]
+				continue
+			}
+			if m := raceFrame.FindStringSubmatch(l); m != nil && file == "" {
+				rel, err := filepath.Rel(worktreePath, m[1])
+				if err != nil || strings.HasPrefix(rel, "..") {
+					continue
+				}
+				file, fn = filepath.ToSlash(rel), lastFunc
+				lineNo, _ = strconv.Atoi(m[2])
+			}
+		}
+		if file == "" {
+			continue
+		}
+		description := fmt.Sprintf("Data race at %s:%d", file, lineNo)
+		if fn != "" {
+			description += " in " + fn
+		}
+		if len(accesses) > 0 {
+			description += fmt.Sprintf(" (%s)", strings.Join(accesses, " vs "))
+		}
+		issues = append(issues, scottbott.Issue{
+			Severity:    "critical",
+			File:        file,
+			Line:        lineNo,
+			Description: description,
+			Suggestion:  "Synchronize the shared state with a mutex, channel or atomic, or stop sharing it between goroutines",
+		})
+	}
+	return issues
+}
+
+var (
+	syncByValue = regexp.MustCompile(`\bfunc\b.*\(\s*[^)]*\b\w+\s+sync\.(Mutex|RWMutex|WaitGroup|Once|Cond)\b`)
+	lockCall    = regexp.MustCompile(`\b(\w+(?:\.\w+)*)\.(R?Lock)\(\)`)
+	goFunc      = regexp.MustCompile(`^\s*go\s+func\b`)
+	addCall     = regexp.MustCompile(`\b(\w+(?:\.\w+)*)\.Add\(`)
+)
+
+// Scan returns issues for racy patterns in the diff's added Go lines: sync
+// values passed by value, WaitGroup.Add inside the goroutine it counts, and
+// locks taken with no unlock in the file. Files are read from worktreePath;
+// one that can't be is judged by the hunks' lines. Hits that are only
+// likely, such as an Add on something never waited on, or a lock whose
+// unlock may be outside the hunks, are minor; the rest are critical.
+func Scan(worktreePath, diff string) []scottbott.Issue {
+	var issues []scottbott.Issue
+	for _, f := range unidiff.Parse(diff) {
+		path := f.Path()
+		if !strings.HasSuffix(path, ".go") || f.NewPath == "" {
+			continue
+		}
+		var added []unidiff.Line
+		var visible []string // Added and context lines: the code after the change
+		for _, l := range f.Lines() {
+			if l.Kind == unidiff.LineAdded {
+				added = append(added, l)
+			}
+			if l.Kind != unidiff.LineRemoved {
+				visible = append(visible, l.Content)
+			}
+		}
+		after, whole := strings.Join(visible, "\n"), false
+		if data, err := os.ReadFile(filepath.Join(worktreePath, path)); err == nil {
+			after, whole = string(data), true
+		}
+
+		for i, l := range added {
+			if syncByValue.MatchString(l.Content) {
+				issues = append(issues, issueAt(path, l.NewLine, "critical",
+					"Passes a sync value by value, so each copy locks or waits on its own state",
+					"Pass a pointer to the sync value"))
+			}
+			if goFunc.MatchString(l.Content) {
+				for j := i + 1; j < len(added) && j <= i+3; j++ {
+					m := addCall.FindStringSubmatch(added[j].Content)
+					if m == nil {
+						continue
+					}
+					// Only a WaitGroup is waited on
+					severity := "minor"
+					if strings.Contains(after, m[1]+".Wait()") {
+						severity = "critical"
+					}
+					issues = append(issues, issueAt(path, added[j].NewLine, severity,
+						fmt.Sprintf("Calls %s.Add inside the goroutine it counts, racing with Wait", m[1]),
+						"Call Add before starting the goroutine"))
+					break
+				}
+			}
+			if m := lockCall.FindStringSubmatch(l.Content); m != nil {
+				unlock := m[1] + "." + strings.Replace(m[2], "Lock", "Unlock", 1) + "()"
+				if !strings.Contains(after, unlock) {
+					severity := "minor"
+					if whole {
+						severity = "critical"
+					}
+					issues = append(issues, issueAt(path, l.NewLine, severity,
+						fmt.Sprintf("Takes %s.%s() without a matching %s", m[1], m[2], unlock),
+						fmt.Sprintf("Add `defer %s` right after taking the lock", unlock)))
+				}
+			}
+		}
+	}
+	return issues
+}
+
+func issueAt(file string, line int, severity, message, suggestion string) scottbott.Issue {
+	return scottbott.Issue{
+		Severity:    severity,
+		File:        file,
+		Line:        line,
+		Description: message,
+		Suggestion:  suggestion,
+	}
+}
+
+func firstLine(output string, err error) string {
+	for _, l := range strings.Split(output, "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			return l
+		}
+	}
+	return err.Error()
+}
@@ -0,0 +1,187 @@
+package racecheck
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScan(t *testing.T) {
+	diff := `diff --git a/cache/cache.go b/cache/cache.go
+--- a/cache/cache.go
++++ b/cache/cache.go
+@@ -1,6 +1,20 @@
+ package cache
++
++func flush(mu sync.Mutex) {}
++
++func (c *Cache) Get(k string) string {
++	c.mu.RLock()
++	return c.items[k]
++}
++
++func (c *Cache) Set(k, v string) {
++	c.mu.Lock()
++	defer c.mu.Unlock()
++	c.items[k] = v
++}
++
++func (c *Cache) Warm(keys []string) {
++	for _, k := range keys {
++		go func() {
++			c.wg.Add(1)
++			c.load(k)
++		}()
++	}
++	c.wg.Wait()
++}
+`
+	issues := Scan(t.TempDir(), diff)
+
+	// The unlock may be outside the hunks, so the lock is only minor
+	want := map[int]string{
+		3:  "critical: Passes a sync value by value",
+		6:  "minor: Takes c.mu.RLock() without a matching c.mu.RUnlock()",
+		19: "critical: Calls c.wg.Add inside the goroutine",
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("Expected %d issues, got %+v", len(want), issues)
+	}
+	for _, issue := range issues {
+		if issue.File != "cache/cache.go" || !strings.HasPrefix(issue.Severity+": "+issue.Description, want[issue.Line]) {
+			t.Errorf("Unexpected issue: %+v", issue)
+		}
+	}
+}
+
+func TestScanReadsFile(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "cache"), 0755)
+	file := `package cache
+
+func (c *Cache) Get(k string) string {
+	c.mu.Lock()
+	v := c.items[k]
+	c.mu.Unlock()
+	return v
+}
+
+func (c *Cache) Count(keys []string) {
+	for range keys {
+		go func() {
+			c.hits.Add(1)
+		}()
+	}
+}
+`
+	os.WriteFile(filepath.Join(dir, "cache", "cache.go"), []byte(file), 0644)
+	diff := `diff --git a/cache/cache.go b/cache/cache.go
+--- a/cache/cache.go
++++ b/cache/cache.go
+@@ -3,3 +3,3 @@ package cache
+ func (c *Cache) Get(k string) string {
+-	v := c.items[k]
++	c.mu.Lock()
++	v := c.items[k]
+@@ -10,4 +11,6 @@
+ func (c *Cache) Count(keys []string) {
+ 	for range keys {
++		go func() {
++			c.hits.Add(1)
++		}()
+ 	}
+`
+	issues := Scan(dir, diff)
+
+	// The unlock is in the file; the counter is never waited on
+	if len(issues) != 1 || issues[0].Severity != "minor" || !strings.Contains(issues[0].Description, "c.hits.Add") {
+		t.Errorf("Expected only a minor Add finding, got %+v", issues)
+	}
+}
+
+func TestParseRaces(t *testing.T) {
+	output := `==================
+WARNING: DATA RACE
+Write at 0x00c0000a4010 by goroutine 8:
+  example.com/app/counter.(*Counter).Inc()
+      /work/repo/counter/counter.go:9 +0x44
+  example.com/app/counter.TestInc.func1()
+      /work/repo/counter/counter_test.go:12 +0x30
+
+Previous read at 0x00c0000a4010 by goroutine 7:
+  example.com/app/counter.(*Counter).Inc()
+      /work/repo/counter/counter.go:9 +0x3a
+
+Goroutine 8 (running) created at:
+  testing.tRunner()
+      /usr/local/go/src/testing/testing.go:1689 +0x21e
+==================
+--- FAIL: TestInc (0.00s)
+    testing.go:1398: race detected during execution of test
+FAIL
+`
+	issues := ParseRaces(output, "/work/repo")
+
+	if len(issues) != 1 {
+		t.Fatalf("Expected one race, got %+v", issues)
+	}
+	want := "Data race at counter/counter.go:9 in example.com/app/counter.(*Counter).Inc (write vs previous read)"
+	if issues[0].Description != want || issues[0].Line != 9 || issues[0].Severity != "critical" {
+		t.Errorf("Unexpected issue: %+v", issues[0])
+	}
+}
+
+func TestRunRace(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds with the race detector")
+	}
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go toolchain not available")
+	}
+	if out, _ := exec.Command("go", "env", "CGO_ENABLED").Output(); strings.TrimSpace(string(out)) != "1" {
+		t.Skip("race detector needs cgo")
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.21\n",
+		"counter/counter.go": `package counter
+
+type Counter struct{ n int }
+
+func (c *Counter) Inc() { c.n++ }
+`,
+		"counter/counter_test.go": `package counter
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestInc(t *testing.T) {
+	var c Counter
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() { defer wg.Done(); c.Inc() }()
+	}
+	wg.Wait()
+}
+`,
+	}
+	for path, content := range files {
+		os.MkdirAll(filepath.Dir(filepath.Join(dir, path)), 0755)
+		os.WriteFile(filepath.Join(dir, path), []byte(content), 0644)
+	}
+	diff := "--- a/counter/counter.go\n+++ b/counter/counter.go\n@@ -4,0 +5 @@\n+func (c *Counter) Inc() { c.n++ }\n"
+
+	issues, err := New(dir).RunRace(context.Background(), diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) == 0 || issues[0].File != "counter/counter.go" {
+		t.Errorf("Expected a race in counter.go, got %+v", issues)
+	}
+}
@@ -0,0 +1,233 @@
+// Package retro analyzes a finished run and distills lessons from it into
+// project memory: conventions the reviewer enforced and recurring issues
+// with how they were fixed.
+package retro
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/claude"
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/cost"
+	"github.com/philjestin/boatmanmode/internal/memory"
+	"github.com/philjestin/boatmanmode/internal/scottbott"
+)
+
+// Run is what the retro sees of a finished run.
+type Run struct {
+	Title        string
+	Description  string
+	FilesChanged []string
+	// Reviews holds every review of the run, in order
+	Reviews []*scottbott.ReviewResult
+	// Resolved lists issues verified as fixed by refactors
+	Resolved    []string
+	TestSummary string
+	Passed      bool
+	Iterations  int
+}
+
+// Lessons are distilled from a run for memory.
+type Lessons struct {
+	Patterns []PatternLesson `json:"patterns"`
+	Issues   []IssueLesson   `json:"issues"`
+}
+
+// PatternLesson is a convention future runs should follow.
+type PatternLesson struct {
+	Type        string `json:"type"` // "naming", "structure", "testing", "api", etc.
+	Description string `json:"description"`
+	Example     string `json:"example,omitempty"`
+	FileMatcher string `json:"file_matcher,omitempty"`
+}
+
+// IssueLesson is a mistake future runs should avoid, with its fix.
+type IssueLesson struct {
+	Type        string `json:"type"` // "style", "logic", "security", "performance", etc.
+	Description string `json:"description"`
+	Solution    string `json:"solution"`
+	FileMatcher string `json:"file_matcher,omitempty"`
+}
+
+// lessonWeight is high enough for retro patterns to be included in
+// Memory.ToContext.
+const lessonWeight = 0.8
+
+// Retro is a Claude agent that reviews finished runs.
+type Retro struct {
+	client *claude.Client
+}
+
+// New creates a Retro agent. It needs no tools: everything it analyzes is
+// in the prompt.
+func New(worktreePath string, cfg *config.Config) *Retro {
+	client := claude.NewWithTmux(worktreePath, "retro")
+	if cfg.Claude.Models.Retro != "" {
+		client.Model = cfg.Claude.Models.Retro
+	}
+	client.Sampling = cfg.Claude.SamplingFor("retro")
+	client.Configure(cfg.Claude)
+	return &Retro{client: client}
+}
+
+// Analyze distills lessons from the run.
+func (r *Retro) Analyze(ctx context.Context, run Run) (*Lessons, *cost.Usage, error) {
+	systemPrompt := `You are running a retrospective on an automated development run.
+Distill lessons that will help future runs in this repository pass review on the first try.
+
+Only record lessons that generalize beyond this task: project conventions the reviewer
+enforced, and mistakes that were made along with how they were fixed. Skip anything
+specific to this ticket. Return empty lists if there is nothing worth remembering.
+
+Output a JSON block in this exact format:
+
+` + "```json" + `
+{
+  "patterns": [
+    {"type": "testing", "description": "Convention to follow", "example": "short example", "file_matcher": "*.go"}
+  ],
+  "issues": [
+    {"type": "logic", "description": "Mistake that was made", "solution": "How it was fixed", "file_matcher": "*.go"}
+  ]
+}
+` + "```"
+
+	response, usage, err := r.client.Message(ctx, systemPrompt, run.Prompt())
+	if err != nil {
+		return nil, nil, fmt.Errorf("retro agent failed: %w", err)
+	}
+	lessons, err := parseLessons(response)
+	if err != nil {
+		return nil, usage, err
+	}
+	return lessons, usage, nil
+}
+
+// Prompt formats the run for the retro agent.
+func (run Run) Prompt() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# Run: %s\n\n", run.Title))
+	if run.Description != "" {
+		sb.WriteString(fmt.Sprintf("## Task\n%s\n\n", truncate(run.Description, 2000)))
+	}
+
+	outcome := "did not pass review"
+	if run.Passed {
+		outcome = "passed review"
+	}
+	sb.WriteString(fmt.Sprintf("## Outcome\nThe run %s after %d iteration(s).\n", outcome, run.Iterations))
+	if run.TestSummary != "" {
+		sb.WriteString(fmt.Sprintf("Tests: %s\n", run.TestSummary))
+	}
+	sb.WriteString("\n")
+
+	if len(run.FilesChanged) > 0 {
+		sb.WriteString("## Files Changed\n")
+		for _, f := range run.FilesChanged {
+			sb.WriteString(fmt.Sprintf("- %s\n", f))
+		}
+		sb.WriteString("\n")
+	}
+
+	for i, review := range run.Reviews {
+		if review == nil {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("## Review %d (score %d)\n", i+1, review.Score))
+		if review.Summary != "" {
+			sb.WriteString(review.Summary + "\n")
+		}
+		for _, issue := range review.Issues {
+			sb.WriteString(fmt.Sprintf("- [%s] %s", issue.Severity, issue.Description))
+			if issue.File != "" {
+				sb.WriteString(fmt.Sprintf(" (%s)", issue.File))
+			}
+			if issue.Suggestion != "" {
+				sb.WriteString(fmt.Sprintf(" → %s", issue.Suggestion))
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(run.Resolved) > 0 {
+		sb.WriteString("## Fixed During Refactoring\n")
+		for _, issue := range run.Resolved {
+			sb.WriteString(fmt.Sprintf("- %s\n", issue))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// jsonBlock matches a fenced JSON block in the agent's response.
+var jsonBlock = regexp.MustCompile("```(?:json)?\\s*\\n?([\\s\\S]*?)\\n?```")
+
+// parseLessons extracts the lessons JSON from the agent's response.
+func parseLessons(response string) (*Lessons, error) {
+	jsonStr := response
+	if m := jsonBlock.FindStringSubmatch(response); len(m) > 1 {
+		jsonStr = m[1]
+	} else if start, end := strings.Index(response, "{"), strings.LastIndex(response, "}"); start >= 0 && end > start {
+		jsonStr = response[start : end+1]
+	}
+
+	var lessons Lessons
+	if err := json.Unmarshal([]byte(jsonStr), &lessons); err != nil {
+		return nil, fmt.Errorf("invalid retro JSON: %w", err)
+	}
+	return &lessons, nil
+}
+
+// Apply stores the lessons in mem and returns how many were recorded.
+// Patterns are keyed by their description, so a lesson learned again
+// reinforces the existing pattern instead of adding a duplicate.
+func (l *Lessons) Apply(mem *memory.Memory) int {
+	n := 0
+	for _, p := range l.Patterns {
+		if strings.TrimSpace(p.Description) == "" {
+			continue
+		}
+		mem.LearnPattern(memory.Pattern{
+			ID:          lessonID("pattern", p.Description),
+			Type:        p.Type,
+			Description: p.Description,
+			Example:     p.Example,
+			FileMatcher: p.FileMatcher,
+			Weight:      lessonWeight,
+		})
+		n++
+	}
+	for _, issue := range l.Issues {
+		if strings.TrimSpace(issue.Description) == "" {
+			continue
+		}
+		mem.LearnIssue(memory.CommonIssue{
+			ID:          lessonID("issue", issue.Description),
+			Type:        issue.Type,
+			Description: issue.Description,
+			Solution:    issue.Solution,
+			FileMatcher: issue.FileMatcher,
+		})
+		n++
+	}
+	return n
+}
+
+func lessonID(kind, description string) string {
+	h := fnv.New32a()
+	h.Write([]byte(strings.ToLower(strings.TrimSpace(description))))
+	return fmt.Sprintf("retro_%s_%08x", kind, h.Sum32())
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + "..."
+}
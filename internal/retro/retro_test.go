@@ -0,0 +1,87 @@
+package retro
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/memory"
+	"github.com/philjestin/boatmanmode/internal/scottbott"
+)
+
+func TestRunPrompt(t *testing.T) {
+	run := Run{
+		Title:        "Add retries",
+		FilesChanged: []string{"upload.go"},
+		Reviews: []*scottbott.ReviewResult{
+			{Score: 55, Issues: []scottbott.Issue{{Severity: "major", File: "upload.go", Description: "No backoff", Suggestion: "Use retry.Backoff"}}},
+			nil,
+			{Score: 90, Passed: true},
+		},
+		Resolved:    []string{"No backoff"},
+		TestSummary: "12 passed",
+		Passed:      true,
+		Iterations:  2,
+	}
+
+	prompt := run.Prompt()
+	for _, want := range []string{
+		"The run passed review after 2 iteration(s).",
+		"Tests: 12 passed",
+		"## Review 1 (score 55)",
+		"- [major] No backoff (upload.go) → Use retry.Backoff",
+		"## Review 3 (score 90)",
+		"## Fixed During Refactoring\n- No backoff",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("Expected %q in prompt:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestParseLessons(t *testing.T) {
+	response := "Looking at the run...\n\n```json\n" + `{
+  "patterns": [{"type": "testing", "description": "Table-driven tests for handlers", "file_matcher": "*_test.go"}],
+  "issues": [{"type": "logic", "description": "Retries without backoff", "solution": "Use retry.Backoff"}]
+}` + "\n```"
+
+	lessons, err := parseLessons(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lessons.Patterns) != 1 || lessons.Patterns[0].FileMatcher != "*_test.go" {
+		t.Errorf("Unexpected patterns: %+v", lessons.Patterns)
+	}
+	if len(lessons.Issues) != 1 || lessons.Issues[0].Solution != "Use retry.Backoff" {
+		t.Errorf("Unexpected issues: %+v", lessons.Issues)
+	}
+
+	if _, err := parseLessons("nothing to learn"); err == nil {
+		t.Error("Expected an error without JSON")
+	}
+}
+
+func TestLessonsApply(t *testing.T) {
+	mem := &memory.Memory{}
+	lessons := &Lessons{
+		Patterns: []PatternLesson{
+			{Type: "testing", Description: "Table-driven tests for handlers"},
+			{Type: "naming", Description: "  "},
+		},
+		Issues: []IssueLesson{{Type: "logic", Description: "Retries without backoff", Solution: "Use retry.Backoff"}},
+	}
+
+	if n := lessons.Apply(mem); n != 2 {
+		t.Errorf("Expected 2 lessons applied, got %d", n)
+	}
+	lessons.Apply(mem)
+
+	if len(mem.Patterns) != 1 || mem.Patterns[0].UsageCount != 2 {
+		t.Errorf("Expected a relearned pattern to be reinforced, got %+v", mem.Patterns)
+	}
+	if len(mem.CommonIssues) != 1 || mem.CommonIssues[0].Frequency != 2 {
+		t.Errorf("Expected a relearned issue to be counted, got %+v", mem.CommonIssues)
+	}
+	if !strings.Contains(mem.ToContext(1000), "Table-driven tests for handlers") {
+		t.Error("Expected retro patterns in memory context")
+	}
+}
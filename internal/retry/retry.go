@@ -113,6 +113,18 @@ func Do(ctx context.Context, cfg Config, operation string, fn func() error) erro
 	return fmt.Errorf("%s failed after %d attempts: %w", operation, cfg.MaxAttempts, lastErr)
 }
 
+// Sleep waits for d or until ctx is done.
+func Sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // delayForAttempt calculates the delay for a given attempt number.
 func (c Config) delayForAttempt(attempt int) time.Duration {
 	delay := float64(c.InitialDelay) * math.Pow(c.Multiplier, float64(attempt-1))
@@ -0,0 +1,99 @@
+// Package revert builds the branch, title and body of a PR reverting a
+// merged boatman PR, linked to the original ticket and the incident that
+// prompted it.
+package revert
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/forge"
+	"github.com/philjestin/boatmanmode/internal/memory"
+	"github.com/philjestin/boatmanmode/internal/testrunner"
+)
+
+// maxFailedNames caps how many failing tests the PR body lists.
+const maxFailedNames = 10
+
+// Revert is a revert of a merged PR.
+type Revert struct {
+	Original *forge.PRInfo
+	// Ticket is the task the original PR was opened for.
+	Ticket string
+	// Incident is the incident ID or URL behind the revert.
+	Incident string
+	// Tests is the test suite's result on the revert; nil if not run.
+	Tests *testrunner.TestResult
+}
+
+// Ticket returns the task boatman opened the PR at url for, from the
+// project's run history, or "" if boatman didn't open it.
+func Ticket(mem *memory.Memory, url string) string {
+	for i := len(mem.ScoreHistory) - 1; i >= 0; i-- {
+		if rec := mem.ScoreHistory[i]; rec.PRURL == url {
+			return rec.TaskID
+		}
+	}
+	return ""
+}
+
+// Branch is the name of the revert branch.
+func (r *Revert) Branch() string {
+	return fmt.Sprintf("revert/pr-%d", r.Original.Number)
+}
+
+// Title is the title of the revert PR, and the subject git gives the
+// revert commit.
+func (r *Revert) Title() string {
+	return fmt.Sprintf("Revert %q", r.Original.Title)
+}
+
+// Body is the description of the revert PR.
+func (r *Revert) Body() string {
+	var b strings.Builder
+	b.WriteString("## Revert\n\n")
+	fmt.Fprintf(&b, "Reverts #%d (%s)", r.Original.Number, r.Original.URL)
+	if r.Original.MergeCommit != nil {
+		fmt.Fprintf(&b, ", merged as %s", shortSHA(r.Original.MergeCommit.OID))
+	}
+	b.WriteString(".\n\n")
+	if r.Ticket != "" {
+		fmt.Fprintf(&b, "- **Ticket:** %s\n", r.Ticket)
+	}
+	fmt.Fprintf(&b, "- **Incident:** %s\n", r.Incident)
+
+	b.WriteString("\n## Tests\n\n")
+	b.WriteString(r.TestSummary())
+	b.WriteString("\n")
+	if r.Tests != nil && !r.Tests.Passed && len(r.Tests.FailedNames) > 0 {
+		b.WriteString("\n")
+		for i, name := range r.Tests.FailedNames {
+			if i == maxFailedNames {
+				fmt.Fprintf(&b, "- ...and %d more\n", len(r.Tests.FailedNames)-maxFailedNames)
+				break
+			}
+			fmt.Fprintf(&b, "- `%s`\n", name)
+		}
+	}
+
+	b.WriteString("\n---\n*Revert opened with `boatman revert`*\n")
+	return b.String()
+}
+
+// TestSummary describes the test suite's result on the revert.
+func (r *Revert) TestSummary() string {
+	switch {
+	case r.Tests == nil:
+		return "⚠️ Tests were not run"
+	case r.Tests.Passed:
+		return fmt.Sprintf("✅ %d passed", r.Tests.PassedTests)
+	}
+	return fmt.Sprintf("❌ %d failed, %d passed", r.Tests.FailedTests, r.Tests.PassedTests)
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,89 @@
+package revert
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/forge"
+	"github.com/philjestin/boatmanmode/internal/memory"
+	"github.com/philjestin/boatmanmode/internal/testrunner"
+)
+
+func TestTicket(t *testing.T) {
+	mem := &memory.Memory{ScoreHistory: []memory.ScoreRecord{
+		{TaskID: "ENG-1", PRURL: "https://github.com/acme/web/pull/7"},
+		{TaskID: "ENG-2", PRURL: "https://github.com/acme/web/pull/8"},
+		{TaskID: "ENG-3"},
+	}}
+	if got := Ticket(mem, "https://github.com/acme/web/pull/8"); got != "ENG-2" {
+		t.Errorf("Ticket = %q, want ENG-2", got)
+	}
+	if got := Ticket(mem, "https://github.com/acme/web/pull/9"); got != "" {
+		t.Errorf("Ticket of a PR boatman didn't open = %q", got)
+	}
+}
+
+func original() *forge.PRInfo {
+	pr := &forge.PRInfo{Number: 7, Title: "Add rate limiting", URL: "https://github.com/acme/web/pull/7"}
+	pr.MergeCommit = &struct {
+		OID string `json:"oid"`
+	}{"0123456789abcdef"}
+	return pr
+}
+
+func TestRevert(t *testing.T) {
+	r := &Revert{
+		Original: original(),
+		Ticket:   "ENG-1",
+		Incident: "INC-42",
+		Tests:    &testrunner.TestResult{Passed: true, PassedTests: 12},
+	}
+
+	if r.Branch() != "revert/pr-7" {
+		t.Errorf("Branch = %q", r.Branch())
+	}
+	if r.Title() != `Revert "Add rate limiting"` {
+		t.Errorf("Title = %q", r.Title())
+	}
+	body := r.Body()
+	for _, s := range []string{
+		"Reverts #7 (https://github.com/acme/web/pull/7), merged as 0123456.",
+		"**Ticket:** ENG-1",
+		"**Incident:** INC-42",
+		"✅ 12 passed",
+	} {
+		if !strings.Contains(body, s) {
+			t.Errorf("Body missing %q:\n%s", s, body)
+		}
+	}
+}
+
+func TestRevertFailingTests(t *testing.T) {
+	var names []string
+	for i := range 12 {
+		names = append(names, fmt.Sprintf("TestCase%d", i))
+	}
+	r := &Revert{
+		Original: original(),
+		Incident: "https://status.acme.com/incidents/42",
+		Tests:    &testrunner.TestResult{FailedTests: 12, PassedTests: 3, FailedNames: names},
+	}
+
+	body := r.Body()
+	if strings.Contains(body, "Ticket") {
+		t.Errorf("Body lists a ticket it doesn't have:\n%s", body)
+	}
+	for _, s := range []string{"❌ 12 failed, 3 passed", "- `TestCase9`", "...and 2 more"} {
+		if !strings.Contains(body, s) {
+			t.Errorf("Body missing %q:\n%s", s, body)
+		}
+	}
+	if strings.Contains(body, "TestCase10") {
+		t.Errorf("Body lists more than %d failing tests", maxFailedNames)
+	}
+
+	if got := (&Revert{}).TestSummary(); !strings.Contains(got, "not run") {
+		t.Errorf("TestSummary without tests = %q", got)
+	}
+}
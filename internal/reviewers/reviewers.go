@@ -0,0 +1,211 @@
+// Package reviewers picks who reviews the PRs boatman opens: round-robin
+// through a team, or the code owners of the changed files balanced by how
+// many reviews each was assigned recently. Assignments are recorded locally
+// so the rotation carries over between runs.
+package reviewers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/onboard"
+)
+
+// Strategies for pr.reviewers.strategy.
+const (
+	StrategyRoundRobin = "round-robin"
+	StrategyCodeOwners = "codeowners"
+)
+
+// LoadWindow is how far back assignments count toward a reviewer's load.
+const LoadWindow = 14 * 24 * time.Hour
+
+// historyLimit caps the assignments kept on disk.
+const historyLimit = 500
+
+// Assignment is a review requested from Reviewer.
+type Assignment struct {
+	Reviewer string    `json:"reviewer"`
+	PRURL    string    `json:"pr_url,omitempty"`
+	At       time.Time `json:"at"`
+}
+
+// History is the record of past assignments, oldest first.
+type History struct {
+	path        string
+	Assignments []Assignment `json:"assignments"`
+}
+
+// LoadHistory reads the assignment history from dir (default:
+// ~/.boatman/reviewers). A missing history is empty.
+func LoadHistory(dir string) (*History, error) {
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		dir = filepath.Join(home, ".boatman", "reviewers")
+	}
+	h := &History{path: filepath.Join(dir, "assignments.json")}
+	data, err := os.ReadFile(h.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return h, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, h); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", h.path, err)
+	}
+	return h, nil
+}
+
+// Record adds the reviewers requested on prURL at the given time.
+func (h *History) Record(prURL string, reviewers []string, at time.Time) {
+	for _, r := range reviewers {
+		h.Assignments = append(h.Assignments, Assignment{Reviewer: r, PRURL: prURL, At: at})
+	}
+	if len(h.Assignments) > historyLimit {
+		h.Assignments = h.Assignments[len(h.Assignments)-historyLimit:]
+	}
+}
+
+// Save writes the history back to disk.
+func (h *History) Save() error {
+	if err := os.MkdirAll(filepath.Dir(h.path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := h.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, h.path)
+}
+
+// lastAssigned returns when each reviewer was last assigned.
+func (h *History) lastAssigned() map[string]time.Time {
+	last := make(map[string]time.Time)
+	for _, a := range h.Assignments {
+		if a.At.After(last[a.Reviewer]) {
+			last[a.Reviewer] = a.At
+		}
+	}
+	return last
+}
+
+// load counts each reviewer's assignments since the given time.
+func (h *History) load(since time.Time) map[string]int {
+	counts := make(map[string]int)
+	for _, a := range h.Assignments {
+		if !a.At.Before(since) {
+			counts[a.Reviewer]++
+		}
+	}
+	return counts
+}
+
+// RoundRobin returns the n members of team assigned longest ago, those
+// never assigned first in team order.
+func (h *History) RoundRobin(team []string, n int) []string {
+	last := h.lastAssigned()
+	return pick(team, n, func(a, b string) int {
+		return last[a].Compare(last[b])
+	})
+}
+
+// LeastLoaded returns the n candidates with the fewest assignments in the
+// LoadWindow before now, ties going to who was assigned longest ago.
+func (h *History) LeastLoaded(candidates []string, n int, now time.Time) []string {
+	counts := h.load(now.Add(-LoadWindow))
+	last := h.lastAssigned()
+	return pick(candidates, n, func(a, b string) int {
+		if counts[a] != counts[b] {
+			return counts[a] - counts[b]
+		}
+		return last[a].Compare(last[b])
+	})
+}
+
+// pick returns the first n distinct candidates ordered by cmp, keeping
+// candidate order for ties.
+func pick(candidates []string, n int, cmp func(a, b string) int) []string {
+	var sorted []string
+	for _, c := range candidates {
+		if !slices.Contains(sorted, c) {
+			sorted = append(sorted, c)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return cmp(sorted[i], sorted[j]) < 0 })
+	if len(sorted) > n {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+// Select returns the reviewers to request for a PR changing files in the
+// repository at repoPath, per cfg. The PR's author should be in
+// cfg.Exclude; they cannot review their own PR.
+func Select(cfg config.ReviewersConfig, h *History, repoPath string, files []string, now time.Time) ([]string, error) {
+	n := cfg.Count
+	if n <= 0 {
+		n = 1
+	}
+	team := without(cfg.Team, cfg.Exclude)
+	switch cfg.Strategy {
+	case "":
+		return nil, nil
+	case StrategyRoundRobin:
+		return h.RoundRobin(team, n), nil
+	case StrategyCodeOwners:
+		rules, err := onboard.LoadCodeOwners(repoPath)
+		if err != nil {
+			return nil, err
+		}
+		owners := without(Handles(onboard.OwnersOf(rules, files)), cfg.Exclude)
+		if len(owners) == 0 {
+			return h.RoundRobin(team, n), nil
+		}
+		return h.LeastLoaded(owners, n, now), nil
+	default:
+		return nil, fmt.Errorf("unknown pr.reviewers.strategy %q (want %s or %s)", cfg.Strategy, StrategyRoundRobin, StrategyCodeOwners)
+	}
+}
+
+// Handles turns CODEOWNERS owners into the names GitHub accepts as
+// reviewers: "@alice" becomes "alice" and "@acme/web" "acme/web". Owners
+// given by email can't be requested and are dropped.
+func Handles(owners []string) []string {
+	var handles []string
+	for _, owner := range owners {
+		if handle, ok := strings.CutPrefix(owner, "@"); ok && handle != "" {
+			handles = append(handles, handle)
+		}
+	}
+	return handles
+}
+
+// without returns names minus those in exclude, ignoring case and a
+// leading "@".
+func without(names, exclude []string) []string {
+	var kept []string
+	for _, name := range names {
+		if !slices.ContainsFunc(exclude, func(e string) bool {
+			return strings.EqualFold(strings.TrimPrefix(e, "@"), strings.TrimPrefix(name, "@"))
+		}) {
+			kept = append(kept, strings.TrimPrefix(name, "@"))
+		}
+	}
+	return kept
+}
@@ -0,0 +1,105 @@
+package reviewers
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+)
+
+var now = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func TestRoundRobin(t *testing.T) {
+	h := &History{}
+	team := []string{"alice", "bob", "carol"}
+	var got []string
+	for i := 0; i < 4; i++ {
+		picked := h.RoundRobin(team, 1)
+		got = append(got, picked...)
+		h.Record("pr", picked, now.Add(time.Duration(i)*time.Minute))
+	}
+	if want := []string{"alice", "bob", "carol", "alice"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("rotation = %v, want %v", got, want)
+	}
+	if got := h.RoundRobin(team, 5); !reflect.DeepEqual(got, []string{"bob", "carol", "alice"}) {
+		t.Errorf("RoundRobin(5) = %v", got)
+	}
+}
+
+func TestLeastLoaded(t *testing.T) {
+	h := &History{}
+	h.Record("pr/1", []string{"alice", "bob"}, now.Add(-time.Hour))
+	h.Record("pr/2", []string{"alice"}, now.Add(-2*time.Hour))
+	// Outside the window, so doesn't count toward carol's load
+	h.Record("pr/0", []string{"carol", "carol"}, now.Add(-LoadWindow-time.Hour))
+
+	if got := h.LeastLoaded([]string{"alice", "bob", "carol"}, 2, now); !reflect.DeepEqual(got, []string{"carol", "bob"}) {
+		t.Errorf("LeastLoaded = %v, want [carol bob]", got)
+	}
+}
+
+func TestSelectCodeOwners(t *testing.T) {
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, ".github"), 0755)
+	os.WriteFile(filepath.Join(dir, ".github", "CODEOWNERS"), []byte("* @acme/core\n/billing/ @alice @bob ops@acme.com @boatman-bot\n"), 0644)
+
+	h := &History{}
+	h.Record("pr/1", []string{"alice"}, now.Add(-time.Hour))
+	cfg := config.ReviewersConfig{Strategy: StrategyCodeOwners, Team: []string{"carol"}, Exclude: []string{"@boatman-bot"}}
+
+	got, err := Select(cfg, h, dir, []string{"billing/invoice.go"}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, []string{"bob"}) {
+		t.Errorf("owners of billing = %v, want [bob]", got)
+	}
+
+	got, _ = Select(cfg, h, dir, []string{"README.md"}, now)
+	if !reflect.DeepEqual(got, []string{"acme/core"}) {
+		t.Errorf("owners of README = %v, want [acme/core]", got)
+	}
+
+	// Without CODEOWNERS, the team rotates
+	got, _ = Select(cfg, h, t.TempDir(), []string{"main.go"}, now)
+	if !reflect.DeepEqual(got, []string{"carol"}) {
+		t.Errorf("fallback = %v, want [carol]", got)
+	}
+}
+
+func TestSelect(t *testing.T) {
+	h := &History{}
+	if got, err := Select(config.ReviewersConfig{Team: []string{"alice"}}, h, "", nil, now); got != nil || err != nil {
+		t.Errorf("no strategy = %v, %v", got, err)
+	}
+	cfg := config.ReviewersConfig{Strategy: StrategyRoundRobin, Team: []string{"@alice", "bob", "carol"}, Count: 2, Exclude: []string{"BOB"}}
+	if got, _ := Select(cfg, h, "", nil, now); !reflect.DeepEqual(got, []string{"alice", "carol"}) {
+		t.Errorf("round-robin = %v, want [alice carol]", got)
+	}
+	if _, err := Select(config.ReviewersConfig{Strategy: "random"}, h, "", nil, now); err == nil {
+		t.Error("expected an error for an unknown strategy")
+	}
+}
+
+func TestHistorySave(t *testing.T) {
+	dir := t.TempDir()
+	h, err := LoadHistory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Record("https://github.com/acme/web/pull/1", []string{"alice", "bob"}, now)
+	if err := h.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadHistory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded.Assignments) != 2 || loaded.Assignments[1].Reviewer != "bob" || !loaded.Assignments[1].At.Equal(now) {
+		t.Errorf("loaded = %+v", loaded.Assignments)
+	}
+}
@@ -0,0 +1,208 @@
+package scottbott
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/philjestin/boatmanmode/internal/acceptance"
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/cost"
+)
+
+// reviewEnsemble runs every configured reviewer in parallel and merges
+// their results. Each reviewer runs the full fallback chain on its own.
+func (s *ScottBott) reviewEnsemble(ctx context.Context, ticketContext, diff string) (*ReviewResult, *cost.Usage, error) {
+	ensemble := s.cfg.Review.Ensemble
+	fmt.Fprintf(s.output(), "   👥 Running %d reviewers in parallel...\n", len(ensemble.Reviewers))
+
+	results := make([]*ReviewResult, len(ensemble.Reviewers))
+	usages := make([]*cost.Usage, len(ensemble.Reviewers))
+	var wg sync.WaitGroup
+	for i, reviewer := range ensemble.Reviewers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, usage, err := s.persona(reviewer).review(ctx, ticketContext, diff)
+			if err != nil {
+				fmt.Fprintf(s.output(), "   ⚠️  %s reviewer %v\n", reviewer.Name, err)
+				return
+			}
+			results[i], usages[i] = result, usage
+		}()
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return nil, nil, ctx.Err()
+	}
+
+	var usage *cost.Usage
+	for _, u := range usages {
+		if u == nil {
+			continue
+		}
+		if usage == nil {
+			usage = &cost.Usage{}
+		}
+		*usage = usage.Add(*u)
+	}
+
+	result, points := mergeReviews(ensemble, results)
+	switch {
+	case result.Inconclusive:
+		s.decisions.Record("review", "marked ensemble review inconclusive; human approval required",
+			"no reviewer produced a verdict")
+	case result.Passed:
+		s.decisions.Record("review", "passed ensemble review",
+			fmt.Sprintf("weighted severity %g is under the threshold of %g", points, ensemble.Threshold))
+	default:
+		s.decisions.Record("review", "failed ensemble review",
+			fmt.Sprintf("weighted severity %g reaches the threshold of %g", points, ensemble.Threshold))
+	}
+	return result, usage, nil
+}
+
+// persona returns a copy of s that reviews as reviewer, in its own session.
+func (s *ScottBott) persona(reviewer config.ReviewerConfig) *ScottBott {
+	p := *s
+	p.sessionName = s.sessionName + "-" + reviewer.Name
+	if reviewer.Skill != "" {
+		p.skill = reviewer.Skill
+	}
+	p.focus = fmt.Sprintf("## Review Focus\n\nYou are the %s reviewer of a review panel; other reviewers cover other concerns.", reviewer.Name)
+	if reviewer.Focus != "" {
+		p.focus += "\n" + reviewer.Focus
+	}
+	return &p
+}
+
+// severityRank orders severities from most to least serious.
+var severityRank = map[string]int{"critical": 3, "major": 2, "minor": 1}
+
+// mergeReviews merges the ensemble reviewers' results, aligned with
+// ensemble.Reviewers; nil results are reviewers that failed to run.
+// Issues reported by several reviewers are merged, keeping the highest
+// severity, and each counts once at the highest weight among them. Review
+// passes while the weighted severity points stay under the threshold.
+// A reviewer that fell back to heuristic checks and found blocking issues
+// fails the review, as heuristics can only reject changes. Returns the
+// merged result and its points.
+func mergeReviews(ensemble config.EnsembleConfig, results []*ReviewResult) (*ReviewResult, float64) {
+	merged := &ReviewResult{Issues: []Issue{}, Praise: []string{}}
+	// reported is a merged issue and the highest weight of its reviewers
+	type reported struct {
+		issue  Issue
+		weight float64
+	}
+	var (
+		issues    []*reported
+		byKey     = make(map[string]*reported)
+		praised   = make(map[string]bool)
+		summaries []string
+		guidance  []string
+		criteria  = make(map[int]acceptance.Check)
+		breakdown = make(map[string]float64)
+		weights   = make(map[string]float64)
+
+		score, totalWeight float64
+		conclusive         int
+		rejected           bool
+	)
+	for i, result := range results {
+		if result == nil || result.Inconclusive {
+			continue
+		}
+		reviewer := ensemble.Reviewers[i]
+		conclusive++
+		if result.heuristic && countBlocking(result.Issues) > 0 {
+			rejected = true
+		}
+
+		weight := reviewerWeight(reviewer)
+		score += float64(result.Score) * weight
+		totalWeight += weight
+		for category, s := range result.Breakdown {
+			breakdown[category] += float64(s) * weight
+			weights[category] += weight
+		}
+
+		for _, issue := range result.Issues {
+			key := fmt.Sprintf("%s:%d:%s", issue.File, issue.Line, strings.ToLower(issue.Description[:min(50, len(issue.Description))]))
+			r, ok := byKey[key]
+			if !ok {
+				issue.Reviewer = reviewer.Name
+				r = &reported{issue: issue, weight: weight}
+				byKey[key] = r
+				issues = append(issues, r)
+				continue
+			}
+			if !strings.Contains(","+r.issue.Reviewer+",", ","+reviewer.Name+",") {
+				r.issue.Reviewer += "," + reviewer.Name
+			}
+			if severityRank[issue.Severity] > severityRank[r.issue.Severity] {
+				r.issue.Severity = issue.Severity
+				r.issue.Description, r.issue.Suggestion = issue.Description, issue.Suggestion
+			}
+			r.weight = max(r.weight, weight)
+		}
+
+		for _, p := range result.Praise {
+			if !praised[p] {
+				praised[p] = true
+				merged.Praise = append(merged.Praise, p)
+			}
+		}
+		if result.Summary != "" {
+			summaries = append(summaries, fmt.Sprintf("%s: %s", reviewer.Name, result.Summary))
+		}
+		if result.Guidance != "" {
+			guidance = append(guidance, fmt.Sprintf("%s: %s", reviewer.Name, result.Guidance))
+		}
+		// A criterion is met only if every reviewer that checked it agrees
+		for _, check := range result.Criteria {
+			if prev, ok := criteria[check.ID]; !ok || prev.Met && !check.Met {
+				criteria[check.ID] = check
+			}
+		}
+	}
+
+	if conclusive == 0 {
+		merged.Inconclusive = true
+		merged.Summary = "No reviewer produced a verdict; human approval required."
+		return merged, 0
+	}
+
+	var points float64
+	for _, r := range issues {
+		merged.Issues = append(merged.Issues, r.issue)
+		points += ensemble.SeverityWeights[r.issue.Severity] * r.weight
+	}
+	sort.SliceStable(merged.Issues, func(i, j int) bool {
+		return severityRank[merged.Issues[i].Severity] > severityRank[merged.Issues[j].Severity]
+	})
+	merged.Passed = !rejected && points < ensemble.Threshold
+	merged.Score = int(score / totalWeight)
+	merged.Summary = strings.Join(summaries, " ")
+	merged.Guidance = strings.Join(guidance, "\n\n")
+	if len(breakdown) > 0 {
+		merged.Breakdown = make(map[string]int)
+		for category, total := range breakdown {
+			merged.Breakdown[category] = int(total / weights[category])
+		}
+	}
+	for _, check := range criteria {
+		merged.Criteria = append(merged.Criteria, check)
+	}
+	sort.Slice(merged.Criteria, func(i, j int) bool { return merged.Criteria[i].ID < merged.Criteria[j].ID })
+	return merged, points
+}
+
+// reviewerWeight returns the reviewer's weight, defaulting to 1.
+func reviewerWeight(reviewer config.ReviewerConfig) float64 {
+	if reviewer.Weight <= 0 {
+		return 1
+	}
+	return reviewer.Weight
+}
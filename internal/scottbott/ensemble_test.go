@@ -0,0 +1,121 @@
+package scottbott
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/decisionlog"
+)
+
+// ensemble returns an ensemble of reviewers with the default severity
+// weights and threshold.
+func ensemble(reviewers ...config.ReviewerConfig) config.EnsembleConfig {
+	return config.EnsembleConfig{
+		Reviewers:       reviewers,
+		SeverityWeights: map[string]float64{"critical": 10, "major": 3, "minor": 1},
+		Threshold:       10,
+	}
+}
+
+func TestReviewEnsemble(t *testing.T) {
+	var mu sync.Mutex
+	prompts := make(map[string]string)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		prompt := req.Messages[len(req.Messages)-1].Content
+		response := `{"passed": true, "score": 90, "summary": "Tidy.", "issues": [{"severity": "minor", "file": "app.go", "line": 3, "description": "Query result is never closed"}]}`
+		if strings.Contains(prompt, "You are the security reviewer") {
+			mu.Lock()
+			prompts["security"] = prompt
+			mu.Unlock()
+			response = `{"passed": true, "score": 70, "summary": "Leaks rows.", "issues": [{"severity": "major", "file": "app.go", "line": 3, "description": "Query result is never closed"}]}`
+		}
+		body, _ := json.Marshal(map[string]any{"message": map[string]string{"content": response}, "prompt_eval_count": 100, "eval_count": 10})
+		w.Write(body)
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{ReviewSkill: "peer-review"}
+	cfg.Claude.Provider = config.ProviderConfig{Name: "ollama", BaseURL: srv.URL}
+	cfg.Review.Ensemble = ensemble(
+		config.ReviewerConfig{Name: "security", Focus: "Look for injection and leaked resources.", Weight: 3},
+		config.ReviewerConfig{Name: "style"},
+	)
+	s := NewWithWorkDir(t.TempDir(), 1, cfg)
+	decisions := decisionlog.New()
+	s.SetDecisionLog(decisions)
+
+	result, usage, err := s.Review(context.Background(), "Add a report", "+code")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(prompts["security"], "Look for injection and leaked resources.") {
+		t.Errorf("security prompt is missing its focus:\n%s", prompts["security"])
+	}
+	// One major issue from a reviewer of weight 3 is 9 points, under 10
+	if !result.Passed || len(result.Issues) != 1 {
+		t.Fatalf("result = %+v", result)
+	}
+	if issue := result.Issues[0]; issue.Severity != "major" || issue.Reviewer != "security,style" {
+		t.Errorf("issue = %+v, want the major one reported by both", issue)
+	}
+	if result.Score != 75 || result.Summary != "security: Leaks rows. style: Tidy." {
+		t.Errorf("score = %d, summary = %q", result.Score, result.Summary)
+	}
+	if usage == nil || usage.InputTokens != 200 {
+		t.Errorf("usage = %+v, want both reviewers' usage", usage)
+	}
+	last := decisions.Entries()[decisions.Len()-1]
+	if last.Decision != "passed ensemble review" || last.Reason != "weighted severity 9 is under the threshold of 10" {
+		t.Errorf("decision = %+v", last)
+	}
+}
+
+func TestMergeReviews(t *testing.T) {
+	e := ensemble(config.ReviewerConfig{Name: "security"}, config.ReviewerConfig{Name: "performance"}, config.ReviewerConfig{Name: "style"})
+	major := func(file string) Issue { return Issue{Severity: "major", File: file, Description: "N+1 query"} }
+
+	// The reviewers each pass their own review, but four major issues
+	// between them reach the threshold
+	result, points := mergeReviews(e, []*ReviewResult{
+		{Passed: true, Score: 80, Issues: []Issue{major("a.rb"), major("b.rb")}},
+		{Passed: true, Score: 80, Issues: []Issue{major("c.rb")}},
+		{Passed: true, Score: 80, Issues: []Issue{major("d.rb"), {Severity: "minor", File: "d.rb", Description: "Long method"}}},
+	})
+	if result.Passed || points != 13 || len(result.Issues) != 5 || result.Issues[4].Severity != "minor" {
+		t.Errorf("points = %g, result = %+v", points, result)
+	}
+
+	// A reviewer that failed to run or was inconclusive has no say
+	result, _ = mergeReviews(e, []*ReviewResult{
+		nil,
+		{Inconclusive: true, Issues: []Issue{}},
+		{Passed: false, Score: 60, Issues: []Issue{major("a.rb")}},
+	})
+	if !result.Passed || result.Score != 60 || result.Issues[0].Reviewer != "style" {
+		t.Errorf("result = %+v", result)
+	}
+
+	// Heuristic findings can only reject
+	heuristic := heuristicReview("diff --git a/k.go b/k.go\n--- a/k.go\n+++ b/k.go\n@@ -1,0 +1,1 @@\n+debugger;\n")
+	result, _ = mergeReviews(e, []*ReviewResult{heuristic, {Passed: true, Score: 90}, {Passed: true, Score: 90}})
+	if result.Passed {
+		t.Errorf("result = %+v, want heuristic findings to fail review", result)
+	}
+
+	result, _ = mergeReviews(e, []*ReviewResult{nil, {Inconclusive: true}, nil})
+	if !result.Inconclusive || result.Passed {
+		t.Errorf("result = %+v, want inconclusive", result)
+	}
+}
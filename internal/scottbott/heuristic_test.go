@@ -0,0 +1,69 @@
+package scottbott
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHeuristicReviewBlocking(t *testing.T) {
+	diff := `diff --git a/app/auth.rb b/app/auth.rb
+--- a/app/auth.rb
++++ b/app/auth.rb
+@@ -10,3 +10,5 @@ class Auth
+   def login
++    binding.pry
++    api_key = "sk_live_abcdefgh1234"
+     authenticate
+   end`
+
+	result := heuristicReview(diff)
+
+	if result.Passed || result.Inconclusive {
+		t.Fatalf("Expected failed review, got %+v", result)
+	}
+	if len(result.Issues) != 2 {
+		t.Fatalf("Expected 2 issues, got %d: %+v", len(result.Issues), result.Issues)
+	}
+	if result.Issues[0].File != "app/auth.rb" || result.Issues[0].Line != 11 {
+		t.Errorf("Expected app/auth.rb:11, got %s:%d", result.Issues[0].File, result.Issues[0].Line)
+	}
+	if result.Issues[1].Line != 12 {
+		t.Errorf("Expected line 12, got %d", result.Issues[1].Line)
+	}
+}
+
+func TestHeuristicReviewInconclusive(t *testing.T) {
+	diff := `--- a/main.go
++++ b/main.go
+@@ -1,1 +1,2 @@
+ package main
++// FIXME: tidy up`
+
+	result := heuristicReview(diff)
+
+	if !result.Inconclusive || result.Passed {
+		t.Fatalf("Expected inconclusive review, got %+v", result)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].Severity != "minor" {
+		t.Errorf("Expected one minor issue, got %+v", result.Issues)
+	}
+	if !strings.Contains(result.FormatReview(), "INCONCLUSIVE") {
+		t.Error("Expected inconclusive banner")
+	}
+}
+
+func TestAttemptError(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Nanosecond)
+	defer cancel()
+	<-ctx.Done()
+
+	if err := attemptError(ctx, errors.New("killed"), 3*time.Second); !strings.Contains(err.Error(), "timed out after 3s") {
+		t.Errorf("Expected timeout error, got %v", err)
+	}
+	if err := attemptError(context.Background(), errors.New("exit status 1"), time.Second); !strings.Contains(err.Error(), "failed: exit status 1") {
+		t.Errorf("Expected failure error, got %v", err)
+	}
+}
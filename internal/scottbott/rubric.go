@@ -0,0 +1,189 @@
+package scottbott
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+)
+
+// DefaultCategories are the score breakdown categories requested from reviewers.
+var DefaultCategories = []string{"correctness", "tests", "security", "style"}
+
+// rubricCategories returns the categories to score, in a stable order.
+// Rubric categories come first (by descending weight), followed by any
+// default categories the rubric doesn't mention.
+func rubricCategories(rubric config.RubricConfig) []string {
+	var categories []string
+	for category := range rubric.Weights {
+		categories = append(categories, category)
+	}
+	sort.Slice(categories, func(i, j int) bool {
+		wi, wj := rubric.Weights[categories[i]], rubric.Weights[categories[j]]
+		if wi != wj {
+			return wi > wj
+		}
+		return categories[i] < categories[j]
+	})
+	for _, category := range DefaultCategories {
+		if _, ok := rubric.Weights[category]; !ok {
+			categories = append(categories, category)
+		}
+	}
+	return categories
+}
+
+// formatRubric renders the rubric as a prompt section.
+// Returns an empty string if the rubric is empty.
+func formatRubric(rubric config.RubricConfig) string {
+	if len(rubric.Weights) == 0 && len(rubric.MustCheck) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## Review Rubric\n\n")
+
+	if len(rubric.Weights) > 0 {
+		total := 0
+		for _, w := range rubric.Weights {
+			total += w
+		}
+		sb.WriteString("Score each category from 0-100. The overall score is weighted as follows:\n")
+		for _, category := range rubricCategories(rubric) {
+			w, ok := rubric.Weights[category]
+			if !ok {
+				continue
+			}
+			pct := 0
+			if total > 0 {
+				pct = w * 100 / total
+			}
+			sb.WriteString(fmt.Sprintf("- %s: %d%%\n", category, pct))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(rubric.MustCheck) > 0 {
+		sb.WriteString("You MUST explicitly verify each of these. Report any that fail as a major issue:\n")
+		for _, item := range rubric.MustCheck {
+			sb.WriteString(fmt.Sprintf("- [ ] %s\n", item))
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString(fmt.Sprintf("Include a \"breakdown\" object in your response with a 0-100 score for: %s.\n",
+		strings.Join(rubricCategories(rubric), ", ")))
+
+	return sb.String()
+}
+
+// ApplyRubric recomputes Score as the weighted average of the breakdown.
+// Categories missing from the breakdown are excluded from the average.
+// Does nothing if there are no weights or no matching breakdown scores.
+func (r *ReviewResult) ApplyRubric(rubric config.RubricConfig) {
+	if len(rubric.Weights) == 0 || len(r.Breakdown) == 0 {
+		return
+	}
+
+	weighted, totalWeight := 0, 0
+	for category, w := range rubric.Weights {
+		score, ok := r.Breakdown[category]
+		if !ok || w <= 0 {
+			continue
+		}
+		weighted += score * w
+		totalWeight += w
+	}
+	if totalWeight == 0 {
+		return
+	}
+	r.Score = weighted / totalWeight
+}
+
+// categoryAliases maps alternate spellings in reviewer output to categories.
+var categoryAliases = map[string]string{
+	"test":          "tests",
+	"testing":       "tests",
+	"test coverage": "tests",
+	"code style":    "style",
+	"readability":   "style",
+	"correct":       "correctness",
+}
+
+// breakdownLine matches lines like "Correctness: 85/100", "- **Tests**: 7/10" or "Security - 90".
+var breakdownLine = regexp.MustCompile(`(?im)^[\s\-*•|]*\**([a-z][a-z ]{1,30}?)\**\s*[:\-|]\s*\**(\d{1,3})\s*(?:/\s*(\d{1,3}))?`)
+
+// parseBreakdown extracts per-category scores from a natural language review.
+// Only known categories (defaults, aliases and any extra names) are kept.
+// Scores out of a different maximum (e.g. 7/10) are scaled to 0-100.
+func parseBreakdown(response string, extra []string) map[string]int {
+	known := make(map[string]bool)
+	for _, category := range DefaultCategories {
+		known[category] = true
+	}
+	for _, category := range extra {
+		known[strings.ToLower(category)] = true
+	}
+
+	breakdown := make(map[string]int)
+	for _, m := range breakdownLine.FindAllStringSubmatch(response, -1) {
+		category := strings.ToLower(strings.TrimSpace(m[1]))
+		if alias, ok := categoryAliases[category]; ok {
+			category = alias
+		}
+		if !known[category] {
+			continue
+		}
+		if _, seen := breakdown[category]; seen {
+			continue
+		}
+
+		score, _ := strconv.Atoi(m[2])
+		if m[3] != "" {
+			outOf, _ := strconv.Atoi(m[3])
+			if outOf <= 0 {
+				continue
+			}
+			score = score * 100 / outOf
+		}
+		if score > 100 {
+			continue
+		}
+		breakdown[category] = score
+	}
+
+	if len(breakdown) == 0 {
+		return nil
+	}
+	return breakdown
+}
+
+// breakdownCategories returns breakdown keys with default categories first.
+func breakdownCategories(breakdown map[string]int) []string {
+	var categories []string
+	for _, category := range DefaultCategories {
+		if _, ok := breakdown[category]; ok {
+			categories = append(categories, category)
+		}
+	}
+	var rest []string
+	for category := range breakdown {
+		if !contains(DefaultCategories, category) {
+			rest = append(rest, category)
+		}
+	}
+	sort.Strings(rest)
+	return append(categories, rest...)
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
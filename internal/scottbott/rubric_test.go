@@ -0,0 +1,102 @@
+package scottbott
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+)
+
+func TestFormatRubricEmpty(t *testing.T) {
+	if got := formatRubric(config.RubricConfig{}); got != "" {
+		t.Errorf("expected empty rubric section, got %q", got)
+	}
+}
+
+func TestFormatRubric(t *testing.T) {
+	rubric := config.RubricConfig{
+		Weights:   map[string]int{"correctness": 3, "security": 1},
+		MustCheck: []string{"No N+1 queries"},
+	}
+
+	got := formatRubric(rubric)
+
+	for _, want := range []string{"correctness: 75%", "security: 25%", "- [ ] No N+1 queries", "\"breakdown\""} {
+		if !strings.Contains(got, want) {
+			t.Errorf("rubric section missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Index(got, "correctness") > strings.Index(got, "security") {
+		t.Error("expected heavier categories first")
+	}
+}
+
+func TestApplyRubric(t *testing.T) {
+	rubric := config.RubricConfig{Weights: map[string]int{"correctness": 3, "tests": 1, "style": 1}}
+
+	result := &ReviewResult{
+		Score:     50,
+		Breakdown: map[string]int{"correctness": 90, "tests": 50},
+	}
+	result.ApplyRubric(rubric)
+
+	// style is missing from the breakdown so only correctness and tests count
+	if result.Score != 80 {
+		t.Errorf("expected weighted score 80, got %d", result.Score)
+	}
+}
+
+func TestApplyRubricNoBreakdown(t *testing.T) {
+	rubric := config.RubricConfig{Weights: map[string]int{"correctness": 1}}
+
+	result := &ReviewResult{Score: 72}
+	result.ApplyRubric(rubric)
+
+	if result.Score != 72 {
+		t.Errorf("expected score unchanged, got %d", result.Score)
+	}
+}
+
+func TestParseBreakdown(t *testing.T) {
+	response := `## Scores
+- **Correctness**: 85/100
+- Testing: 7/10
+Security - 90
+Style: 120
+Performance: 60`
+
+	got := parseBreakdown(response, []string{"performance"})
+
+	want := map[string]int{"correctness": 85, "tests": 70, "security": 90, "performance": 60}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for category, score := range want {
+		if got[category] != score {
+			t.Errorf("%s: expected %d, got %d", category, score, got[category])
+		}
+	}
+}
+
+func TestParseBreakdownNone(t *testing.T) {
+	if got := parseBreakdown("LGTM, ship it.", nil); got != nil {
+		t.Errorf("expected nil breakdown, got %v", got)
+	}
+}
+
+func TestFormatReviewBreakdown(t *testing.T) {
+	result := &ReviewResult{
+		Passed:    true,
+		Score:     88,
+		Breakdown: map[string]int{"security": 95, "correctness": 80},
+	}
+
+	out := result.FormatReview()
+
+	if !strings.Contains(out, "correctness") || !strings.Contains(out, "95") {
+		t.Errorf("expected breakdown in output:\n%s", out)
+	}
+	if strings.Index(out, "correctness") > strings.Index(out, "security") {
+		t.Error("expected default category order in breakdown")
+	}
+}
@@ -0,0 +1,194 @@
+package scottbott
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/cost"
+)
+
+// Schema is the JSON Schema reviews must conform to under
+// review.strict_output.
+//
+//go:embed review.schema.json
+var Schema string
+
+// reviewSchema is Schema, parsed.
+var reviewSchema = func() map[string]any {
+	var schema map[string]any
+	if err := json.Unmarshal([]byte(Schema), &schema); err != nil {
+		panic("scottbott: invalid review schema: " + err.Error())
+	}
+	return schema
+}()
+
+// askFunc sends the reviewer a prompt and returns its response.
+type askFunc func(ctx context.Context, prompt string) (string, *cost.Usage, error)
+
+// strict reports whether reviews must conform to Schema.
+func (s *ScottBott) strict() bool {
+	return s.cfg != nil && s.cfg.Review.StrictOutput
+}
+
+// schemaPrompt returns the output contract appended to review prompts
+// under strict output.
+func schemaPrompt() string {
+	return "## Output Format\n\nRespond with ONLY a JSON object conforming to this JSON Schema, with no prose or markdown around it:\n\n" + Schema
+}
+
+// conform re-prompts the reviewer, under strict output, with the schema
+// violations of its response until it conforms or review.output_retries
+// retries are spent. Returns the response to parse and the retries'
+// usage; a response that never conforms is left to the lenient parser.
+func (s *ScottBott) conform(ctx context.Context, prompt, response string, ask askFunc) (string, *cost.Usage) {
+	if !s.strict() {
+		return response, nil
+	}
+	var usage *cost.Usage
+	violations := ValidateReview(response)
+	for retry := 1; len(violations) > 0 && retry <= s.cfg.Review.OutputRetries; retry++ {
+		fmt.Fprintf(s.output(), "   ⚠️  Review doesn't match the schema (%s), re-prompting (%d/%d)...\n",
+			violations[0], retry, s.cfg.Review.OutputRetries)
+		attemptCtx, cancel := s.attemptContext(ctx)
+		retried, retryUsage, err := ask(attemptCtx, retryPrompt(prompt, response, violations))
+		cancel()
+		if retryUsage != nil {
+			if usage == nil {
+				usage = &cost.Usage{}
+			}
+			*usage = usage.Add(*retryUsage)
+		}
+		if err != nil {
+			fmt.Fprintf(s.output(), "   ⚠️  Re-prompt failed: %v\n", err)
+			break
+		}
+		response = retried
+		violations = ValidateReview(response)
+	}
+	if len(violations) > 0 {
+		s.decisions.Record("review", "fell back to lenient parsing of the review",
+			fmt.Sprintf("response still violated the review schema: %s", strings.Join(violations, "; ")))
+	}
+	return response, usage
+}
+
+// retryPrompt asks the reviewer to correct a response that violated the
+// schema.
+func retryPrompt(prompt, response string, violations []string) string {
+	return fmt.Sprintf(`%s
+
+## Your Previous Response
+%s
+
+## Schema Violations
+Your previous response did not conform to the schema:
+- %s
+
+Respond again with ONLY the corrected JSON object.`, prompt, response, strings.Join(violations, "\n- "))
+}
+
+// ValidateReview validates a reviewer's response against Schema,
+// returning the violations, or nil when it conforms. A response wrapped
+// in a markdown code fence is accepted.
+func ValidateReview(response string) []string {
+	var value any
+	if err := json.Unmarshal([]byte(extractJSON(response)), &value); err != nil {
+		return []string{fmt.Sprintf("response is not a JSON object: %v", err)}
+	}
+	return validate(reviewSchema, value, "$")
+}
+
+// validate checks value against the subset of JSON Schema the review
+// schema uses: type, enum, minimum, maximum, required, properties,
+// additionalProperties and items.
+func validate(schema map[string]any, value any, path string) []string {
+	if typ, ok := schema["type"].(string); ok && !hasType(value, typ) {
+		return []string{fmt.Sprintf("%s must be %s %s", path, article(typ), typ)}
+	}
+	var violations []string
+	if enum, ok := schema["enum"].([]any); ok && !slices.Contains(enum, value) {
+		var allowed []string
+		for _, v := range enum {
+			allowed = append(allowed, fmt.Sprint(v))
+		}
+		violations = append(violations, fmt.Sprintf("%s must be one of %s (got %v)", path, strings.Join(allowed, ", "), value))
+	}
+	if n, ok := value.(float64); ok {
+		if lo, ok := schema["minimum"].(float64); ok && n < lo {
+			violations = append(violations, fmt.Sprintf("%s must be at least %g (got %g)", path, lo, n))
+		}
+		if hi, ok := schema["maximum"].(float64); ok && n > hi {
+			violations = append(violations, fmt.Sprintf("%s must be at most %g (got %g)", path, hi, n))
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		if required, ok := schema["required"].([]any); ok {
+			for _, key := range required {
+				if _, ok := v[key.(string)]; !ok {
+					violations = append(violations, fmt.Sprintf("%s.%s is required", path, key))
+				}
+			}
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if sub, ok := properties[key].(map[string]any); ok {
+				violations = append(violations, validate(sub, v[key], path+"."+key)...)
+			} else if sub, ok := schema["additionalProperties"].(map[string]any); ok {
+				violations = append(violations, validate(sub, v[key], path+"."+key)...)
+			}
+		}
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				violations = append(violations, validate(items, item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	}
+	return violations
+}
+
+// hasType reports whether value is of the JSON Schema type typ.
+func hasType(value any, typ string) bool {
+	switch typ {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	}
+	return true
+}
+
+// article returns the indefinite article for a type name.
+func article(typ string) string {
+	if strings.ContainsRune("aeiou", rune(typ[0])) {
+		return "an"
+	}
+	return "a"
+}
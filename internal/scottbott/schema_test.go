@@ -0,0 +1,114 @@
+package scottbott
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/decisionlog"
+)
+
+func TestValidateReview(t *testing.T) {
+	valid := []string{
+		`{"passed": true, "score": 90, "summary": "Good.", "issues": []}`,
+		"```json\n{\"passed\": false, \"score\": 40, \"summary\": \"Broken.\", \"issues\": [{\"severity\": \"critical\", \"file\": \"a.go\", \"line\": 3, \"description\": \"Nil dereference\"}], \"breakdown\": {\"tests\": 50}, \"criteria\": [{\"id\": 1, \"met\": false}]}\n```",
+	}
+	for _, response := range valid {
+		if violations := ValidateReview(response); violations != nil {
+			t.Errorf("ValidateReview(%q) = %v", response, violations)
+		}
+	}
+
+	invalid := map[string]string{
+		"The code looks good, approved.":                                                                     "response is not a JSON object",
+		`{"passed": true, "score": 90, "summary": "Good."}`:                                                  "$.issues is required",
+		`{"passed": "yes", "score": 90, "summary": "Good.", "issues": []}`:                                   "$.passed must be a boolean",
+		`{"passed": true, "score": 120, "summary": "Good.", "issues": []}`:                                   "$.score must be at most 100 (got 120)",
+		`{"passed": true, "score": 87.5, "summary": "Good.", "issues": []}`:                                  "$.score must be an integer",
+		`{"passed": true, "score": 90, "summary": "", "issues": [{"severity": "High", "description": "x"}]}`: "$.issues[0].severity must be one of critical, major, minor (got High)",
+		`{"passed": true, "score": 90, "summary": "", "issues": [{"severity": "minor"}]}`:                    "$.issues[0].description is required",
+		`{"passed": true, "score": 90, "summary": "", "issues": [], "breakdown": {"tests": "good"}}`:         "$.breakdown.tests must be an integer",
+	}
+	for response, want := range invalid {
+		violations := ValidateReview(response)
+		if len(violations) == 0 || !strings.HasPrefix(violations[0], want) {
+			t.Errorf("ValidateReview(%q) = %v, want %q", response, violations, want)
+		}
+	}
+}
+
+// reviewServer serves the given review responses in turn, as ollama,
+// recording the prompts it was sent.
+func reviewServer(t *testing.T, responses ...string) (*httptest.Server, *[]string) {
+	var prompts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		prompts = append(prompts, req.Messages[len(req.Messages)-1].Content)
+		response := responses[min(len(prompts), len(responses))-1]
+		body, _ := json.Marshal(map[string]any{"message": map[string]string{"content": response}, "prompt_eval_count": 100, "eval_count": 10})
+		w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &prompts
+}
+
+func TestStrictOutput(t *testing.T) {
+	srv, prompts := reviewServer(t,
+		"Looks fine to me, though the error is swallowed in app.go.",
+		`{"passed": true, "score": 80, "summary": "Fine.", "issues": [{"severity": "Major", "file": "app.go", "description": "Error is swallowed"}]}`,
+		`{"passed": false, "score": 60, "summary": "Fine.", "issues": [{"severity": "major", "file": "app.go", "description": "Error is swallowed"}]}`,
+	)
+	cfg := &config.Config{}
+	cfg.Claude.Provider = config.ProviderConfig{Name: "ollama", BaseURL: srv.URL}
+	cfg.Review.StrictOutput = true
+	cfg.Review.OutputRetries = 2
+	s := NewWithWorkDir(t.TempDir(), 1, cfg)
+
+	result, usage, err := s.Review(context.Background(), "Handle errors", "+code")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*prompts) != 3 {
+		t.Fatalf("sent %d prompts, want the review and 2 re-prompts", len(*prompts))
+	}
+	if !strings.Contains((*prompts)[0], `"title": "Boatman review result"`) {
+		t.Error("review prompt is missing the schema")
+	}
+	if p := (*prompts)[2]; !strings.Contains(p, "$.issues[0].severity must be one of critical, major, minor (got Major)") || !strings.Contains(p, `"severity": "Major"`) {
+		t.Errorf("re-prompt is missing the previous response or its violations:\n%s", p)
+	}
+	if result.Passed || result.Score != 60 || len(result.Issues) != 1 || result.Issues[0].Severity != "major" {
+		t.Errorf("result = %+v", result)
+	}
+	if usage == nil || usage.InputTokens != 300 {
+		t.Errorf("usage = %+v, want the re-prompts' usage too", usage)
+	}
+
+	// A reviewer that never conforms is parsed leniently
+	srv, prompts = reviewServer(t, "The change is approved. LGTM.")
+	cfg.Claude.Provider.BaseURL = srv.URL
+	cfg.Review.OutputRetries = 1
+	s = NewWithWorkDir(t.TempDir(), 1, cfg)
+	decisions := decisionlog.New()
+	s.SetDecisionLog(decisions)
+	result, _, err = s.Review(context.Background(), "Handle errors", "+code")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*prompts) != 2 || result == nil {
+		t.Fatalf("sent %d prompts, result = %+v", len(*prompts), result)
+	}
+	last := decisions.Entries()[decisions.Len()-1]
+	if last.Decision != "fell back to lenient parsing of the review" || !strings.Contains(last.Reason, "response is not a JSON object") {
+		t.Errorf("decision = %+v", last)
+	}
+}
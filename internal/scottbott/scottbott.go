@@ -6,14 +6,19 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/philjestin/boatmanmode/internal/acceptance"
+	"github.com/philjestin/boatmanmode/internal/claude"
 	"github.com/philjestin/boatmanmode/internal/config"
 	"github.com/philjestin/boatmanmode/internal/cost"
+	"github.com/philjestin/boatmanmode/internal/decisionlog"
+	"github.com/philjestin/boatmanmode/internal/llm"
 )
 
 // ReviewResult represents the outcome of a code review.
@@ -24,6 +29,17 @@ type ReviewResult struct {
 	Issues   []Issue  `json:"issues"`
 	Praise   []string `json:"praise"`
 	Guidance string   `json:"guidance"`
+	// Breakdown holds per-category scores (0-100), e.g. correctness, tests
+	Breakdown map[string]int `json:"breakdown,omitempty"`
+	// Inconclusive is set when no reviewer could produce a verdict.
+	// The changes need human approval before they can be merged.
+	Inconclusive bool `json:"inconclusive,omitempty"`
+	// Criteria holds the reviewer's verdict on each acceptance criterion
+	// it was asked to verify.
+	Criteria []acceptance.Check `json:"criteria,omitempty"`
+
+	// heuristic is set when the result comes from heuristic static checks.
+	heuristic bool
 }
 
 // Issue represents a specific problem found during review.
@@ -33,6 +49,9 @@ type Issue struct {
 	Line        int    `json:"line"`
 	Description string `json:"description"`
 	Suggestion  string `json:"suggestion"`
+	// Reviewer names the ensemble reviewers that reported the issue,
+	// comma-separated.
+	Reviewer string `json:"reviewer,omitempty"`
 }
 
 // ScottBott invokes the review skill.
@@ -44,35 +63,47 @@ type ScottBott struct {
 	model               string
 	enablePromptCaching bool
 	cfg                 *config.Config
+	decisions           *decisionlog.Log
+	criteria            *acceptance.Checklist
+	focus               string
+	out                 io.Writer    // Progress output; stdout when nil
+	provider            llm.Provider // API provider; nil for the Claude CLI
+	providerErr         error        // Invalid provider settings, returned by Review
+}
+
+// withProvider builds s's API provider once from its config.
+func withProvider(s *ScottBott) *ScottBott {
+	s.provider, s.providerErr = llm.New(s.cfg.Claude.Provider)
+	return s
 }
 
 // New creates a new ScottBott instance.
 func New(cfg *config.Config) *ScottBott {
-	return &ScottBott{
+	return withProvider(&ScottBott{
 		sessionName:         "reviewer",
 		outputDir:           filepath.Join(os.TempDir(), "boatman-sessions"),
 		skill:               cfg.ReviewSkill,
 		model:               cfg.Claude.Models.Reviewer,
 		enablePromptCaching: cfg.Claude.EnablePromptCaching,
 		cfg:                 cfg,
-	}
+	})
 }
 
 // NewForIteration creates a ScottBott for a specific review iteration.
 func NewForIteration(iteration int, cfg *config.Config) *ScottBott {
-	return &ScottBott{
+	return withProvider(&ScottBott{
 		sessionName:         fmt.Sprintf("reviewer-%d", iteration),
 		outputDir:           filepath.Join(os.TempDir(), "boatman-sessions"),
 		skill:               cfg.ReviewSkill,
 		model:               cfg.Claude.Models.Reviewer,
 		enablePromptCaching: cfg.Claude.EnablePromptCaching,
 		cfg:                 cfg,
-	}
+	})
 }
 
 // NewWithWorkDir creates a ScottBott that runs in a specific directory.
 func NewWithWorkDir(workDir string, iteration int, cfg *config.Config) *ScottBott {
-	return &ScottBott{
+	return withProvider(&ScottBott{
 		workDir:             workDir,
 		sessionName:         fmt.Sprintf("reviewer-%d", iteration),
 		outputDir:           filepath.Join(os.TempDir(), "boatman-sessions"),
@@ -80,7 +111,7 @@ func NewWithWorkDir(workDir string, iteration int, cfg *config.Config) *ScottBot
 		model:               cfg.Claude.Models.Reviewer,
 		enablePromptCaching: cfg.Claude.EnablePromptCaching,
 		cfg:                 cfg,
-	}
+	})
 }
 
 // NewWithSkill creates a ScottBott with a specific skill/agent for review.
@@ -88,7 +119,7 @@ func NewWithSkill(workDir string, iteration int, skill string, cfg *config.Confi
 	if skill == "" {
 		skill = "peer-review"
 	}
-	return &ScottBott{
+	return withProvider(&ScottBott{
 		workDir:             workDir,
 		sessionName:         fmt.Sprintf("reviewer-%d", iteration),
 		outputDir:           filepath.Join(os.TempDir(), "boatman-sessions"),
@@ -96,27 +127,171 @@ func NewWithSkill(workDir string, iteration int, skill string, cfg *config.Confi
 		model:               cfg.Claude.Models.Reviewer,
 		enablePromptCaching: cfg.Claude.EnablePromptCaching,
 		cfg:                 cfg,
+	})
+}
+
+// SetDecisionLog sets the log that records fallbacks taken during review.
+func (s *ScottBott) SetDecisionLog(log *decisionlog.Log) {
+	s.decisions = log
+}
+
+// SetOutput sends the reviewer's progress output to w.
+func (s *ScottBott) SetOutput(w io.Writer) {
+	s.out = w
+}
+
+// output returns where progress output goes.
+func (s *ScottBott) output() io.Writer {
+	if s.out == nil {
+		return os.Stdout
 	}
+	return s.out
+}
+
+// SetCriteria sets the ticket's acceptance criteria for the reviewer to
+// verify, reporting each in ReviewResult.Criteria.
+func (s *ScottBott) SetCriteria(criteria *acceptance.Checklist) {
+	s.criteria = criteria
 }
 
 // Review performs a code review using the peer-review Claude skill.
+// If the skill errors or times out, it falls back in order to a cheaper model
+// with a system prompt, then heuristic static checks, and finally marks the
+// review inconclusive. Each fallback is recorded in the decision log.
+// Invalid provider settings are an error rather than a fallback.
+// With an ensemble configured, every reviewer runs this chain in parallel
+// and their results are merged.
 // Note: Usage data is not available when using the skill/agent mode as it uses text output.
 func (s *ScottBott) Review(ctx context.Context, ticketContext, diff string) (*ReviewResult, *cost.Usage, error) {
+	if s.providerErr != nil {
+		return nil, nil, s.providerErr
+	}
+	if s.cfg != nil && len(s.cfg.Review.Ensemble.Reviewers) > 0 {
+		return s.reviewEnsemble(ctx, ticketContext, diff)
+	}
+	return s.review(ctx, ticketContext, diff)
+}
+
+// review runs one reviewer's fallback chain.
+func (s *ScottBott) review(ctx context.Context, ticketContext, diff string) (*ReviewResult, *cost.Usage, error) {
+	// API providers cannot run skills, so the system-prompt review is the
+	// primary review there, on the reviewer model
+	fallbackModel := s.fallbackModel()
+	var result *ReviewResult
+	var err error
+	if s.provider != nil {
+		fallbackModel = s.model
+		err = fmt.Errorf("is unavailable with the %s provider", s.provider.Name())
+	} else {
+		result, err = s.reviewWithSkill(ctx, ticketContext, diff)
+		if err == nil {
+			return result, nil, nil
+		}
+		if ctx.Err() != nil {
+			return nil, nil, ctx.Err()
+		}
+	}
+
+	fmt.Fprintf(s.output(), "   ⚠️  %s skill %v, using fallback...\n", s.skill, err)
+	s.decisions.Record("review", fmt.Sprintf("fell back to system-prompt review (%s)", modelName(fallbackModel)),
+		fmt.Sprintf("%s skill %v", s.skill, err))
+
+	result, usage, err := s.reviewWithFallback(ctx, ticketContext, diff, fallbackModel)
+	if err == nil {
+		return result, usage, nil
+	}
+	if ctx.Err() != nil {
+		return nil, nil, ctx.Err()
+	}
+
+	fmt.Fprintf(s.output(), "   ⚠️  Fallback review %v, running heuristic checks...\n", err)
+	s.decisions.Record("review", "fell back to heuristic static checks", fmt.Sprintf("fallback review %v", err))
+
+	result = heuristicReview(diff)
+	if result.Inconclusive {
+		s.decisions.Record("review", "marked review inconclusive; human approval required",
+			"heuristic checks found no blocking issues but cannot approve changes")
+	} else {
+		s.decisions.Record("review", "failed review on heuristic findings",
+			fmt.Sprintf("%d blocking issue(s) found by static checks", countBlocking(result.Issues)))
+	}
+	return result, nil, nil
+}
+
+// sampling returns the reviewer's sampling parameters.
+func (s *ScottBott) sampling() config.SamplingConfig {
+	if s.cfg == nil {
+		return config.SamplingConfig{}
+	}
+	return s.cfg.Claude.SamplingFor("reviewer")
+}
+
+// fallbackModel returns the model for the system-prompt fallback review.
+func (s *ScottBott) fallbackModel() string {
+	if s.cfg == nil {
+		return ""
+	}
+	if s.cfg.Review.FallbackModel != "" {
+		return s.cfg.Review.FallbackModel
+	}
+	return s.cfg.Claude.Models.Preflight
+}
+
+// attemptContext bounds a single reviewer attempt by the configured timeout.
+func (s *ScottBott) attemptContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if s.cfg != nil && s.cfg.Review.Timeout > 0 {
+		return context.WithTimeout(ctx, s.cfg.Review.Timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// attemptError describes why a reviewer attempt failed.
+func attemptError(attemptCtx context.Context, err error, elapsed time.Duration) error {
+	if attemptCtx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("timed out after %s", elapsed.Round(time.Second))
+	}
+	return fmt.Errorf("failed: %w", err)
+}
+
+// command returns the configured claude command, run with the reviewer's
+// session name in its environment. The reviewer explores the code but
+// never changes it, so it is limited to the read-only tools.
+func (s *ScottBott) command(ctx context.Context, args ...string) *exec.Cmd {
+	name := "claude"
+	if s.cfg != nil && s.cfg.Claude.Command != "" {
+		name = s.cfg.Claude.Command
+	}
+	args = append(claude.ReadOnlyArgs(), args...)
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Env = append(os.Environ(), claude.SessionEnv+"="+s.sessionName)
+	return cmd
+}
+
+// modelName returns a display name for a model, handling the CLI default.
+func modelName(model string) string {
+	if model == "" {
+		return "default model"
+	}
+	return model
+}
+
+// reviewWithSkill invokes the configured review skill/agent.
+func (s *ScottBott) reviewWithSkill(ctx context.Context, ticketContext, diff string) (*ReviewResult, error) {
 	os.MkdirAll(s.outputDir, 0755)
 
 	// Write the review prompt to a file
 	promptFile := filepath.Join(s.outputDir, fmt.Sprintf("%s-prompt.txt", s.sessionName))
-	prompt := formatReviewPrompt(ticketContext, diff)
+	prompt := s.formatReviewPrompt(ticketContext, diff)
 	if err := os.WriteFile(promptFile, []byte(prompt), 0644); err != nil {
-		return nil, nil, fmt.Errorf("failed to write prompt: %w", err)
+		return nil, fmt.Errorf("failed to write prompt: %w", err)
 	}
 	defer os.Remove(promptFile)
 
 	// Output file for capturing response
 	outputFile := filepath.Join(s.outputDir, fmt.Sprintf("%s.out", s.sessionName))
 
-	fmt.Printf("   📏 Review: %d chars context, %d chars diff\n", len(ticketContext), len(diff))
-	fmt.Printf("   🔍 Invoking %s skill...\n", s.skill)
+	fmt.Fprintf(s.output(), "   📏 Review: %d chars context, %d chars diff\n", len(ticketContext), len(diff))
+	fmt.Fprintf(s.output(), "   🔍 Invoking %s skill...\n", s.skill)
 
 	start := time.Now()
 
@@ -136,39 +311,52 @@ func (s *ScottBott) Review(ctx context.Context, ticketContext, diff string) (*Re
 	// Note: Prompt caching is automatically handled by Claude CLI when using system prompts
 	// No explicit flag needed in current version (2.1.39+)
 
-	cmd := exec.CommandContext(ctx, "claude", args...)
+	// Add per-skill arguments and environment from config
+	var skillOpts config.SkillConfig
+	if s.cfg != nil {
+		skillOpts = s.cfg.Review.SkillOptions(s.skill)
+	}
+	args = append(args, skillOpts.Args...)
+
+	attemptCtx, cancel := s.attemptContext(ctx)
+	defer cancel()
+
+	ask := func(ctx context.Context, prompt string) (string, *cost.Usage, error) {
+		cmd := s.command(ctx, args...)
+		cmd.Env = append(cmd.Env, skillOpts.ExpandedEnv()...)
 
-	// Pipe the prompt via stdin
-	promptContent, _ := os.ReadFile(promptFile)
-	cmd.Stdin = strings.NewReader(string(promptContent))
+		// Pipe the prompt via stdin
+		cmd.Stdin = strings.NewReader(prompt)
 
-	if s.workDir != "" {
-		cmd.Dir = s.workDir
+		if s.workDir != "" {
+			cmd.Dir = s.workDir
+		}
+
+		output, err := cmd.Output()
+		if err == nil {
+			// Save output for debugging
+			os.WriteFile(outputFile, output, 0644)
+		}
+		return strings.TrimSpace(string(output)), nil, err
 	}
 
-	output, err := cmd.Output()
+	response, _, err := ask(attemptCtx, prompt)
 	elapsed := time.Since(start)
 
 	if err != nil {
-		// If skill doesn't exist, fall back to system prompt
-		fmt.Printf("   ⚠️  %s skill not found, using fallback...\n", s.skill)
-		return s.reviewWithFallback(ctx, ticketContext, diff)
+		// Skill missing, erroring or hung - caller falls back
+		return nil, attemptError(attemptCtx, err, elapsed)
 	}
 
-	fmt.Printf("   ⏱️  Review completed in %s\n", elapsed.Round(time.Second))
-
-	// Save output for debugging
-	os.WriteFile(outputFile, output, 0644)
+	fmt.Fprintf(s.output(), "   ⏱️  Review completed in %s\n", elapsed.Round(time.Second))
 
 	// Parse the response
-	response := strings.TrimSpace(string(output))
-	result, err := s.parseReviewResponse(response)
-	// Text output format doesn't include usage data
-	return result, nil, err
+	response, _ = s.conform(ctx, prompt, response, ask)
+	return s.parseReviewResponse(response)
 }
 
 // reviewWithFallback uses a system prompt if peer-review skill isn't available.
-func (s *ScottBott) reviewWithFallback(ctx context.Context, ticketContext, diff string) (*ReviewResult, *cost.Usage, error) {
+func (s *ScottBott) reviewWithFallback(ctx context.Context, ticketContext, diff, model string) (*ReviewResult, *cost.Usage, error) {
 	systemPrompt := `You are a senior staff engineer conducting a peer code review.
 Be thorough, constructive, and focused on correctness, security, and maintainability.
 
@@ -179,12 +367,17 @@ Respond with ONLY a JSON object:
   "summary": "2-3 sentence summary",
   "issues": [{"severity": "critical|major|minor", "file": "path", "line": 0, "description": "what's wrong", "suggestion": "how to fix"}],
   "praise": ["good things"],
-  "guidance": "guidance for fixing if failed"
+  "guidance": "guidance for fixing if failed",
+  "breakdown": {"correctness": number, "tests": number, "security": number, "style": number}
 }
 
 Pass if: no critical issues, ≤2 major issues, code meets requirements.`
 
-	prompt := formatReviewPrompt(ticketContext, diff)
+	prompt := s.formatReviewPrompt(ticketContext, diff)
+
+	if s.provider != nil {
+		return s.reviewWithProvider(ctx, s.provider, systemPrompt, prompt, model)
+	}
 
 	promptFile := filepath.Join(s.outputDir, fmt.Sprintf("%s-fallback-prompt.txt", s.sessionName))
 	sysFile := filepath.Join(s.outputDir, fmt.Sprintf("%s-fallback-system.txt", s.sessionName))
@@ -196,40 +389,108 @@ Pass if: no critical issues, ≤2 major issues, code meets requirements.`
 
 	start := time.Now()
 
-	cmd := exec.CommandContext(ctx, "claude",
+	args := []string{
 		"-p",
 		"--output-format", "text",
 		"--system-prompt", systemPrompt,
-	)
-	cmd.Stdin = strings.NewReader(prompt)
+	}
+	if model != "" {
+		args = append(args, "--model", model)
+	}
+
+	attemptCtx, cancel := s.attemptContext(ctx)
+	defer cancel()
+
+	ask := func(ctx context.Context, prompt string) (string, *cost.Usage, error) {
+		cmd := s.command(ctx, args...)
+		cmd.Stdin = strings.NewReader(prompt)
 
-	if s.workDir != "" {
-		cmd.Dir = s.workDir
+		if s.workDir != "" {
+			cmd.Dir = s.workDir
+		}
+
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			// Save output for debugging
+			os.WriteFile(filepath.Join(s.outputDir, fmt.Sprintf("%s-fallback.out", s.sessionName)), output, 0644)
+		}
+		return strings.TrimSpace(string(output)), nil, err
 	}
 
-	output, err := cmd.CombinedOutput()
+	response, _, err := ask(attemptCtx, prompt)
 	elapsed := time.Since(start)
 
 	if err != nil {
-		return nil, nil, fmt.Errorf("review failed: %w\nOutput: %s", err, string(output))
+		return nil, nil, attemptError(attemptCtx, err, elapsed)
 	}
 
-	fmt.Printf("   ⏱️  Review completed in %s\n", elapsed.Round(time.Second))
+	fmt.Fprintf(s.output(), "   ⏱️  Review completed in %s\n", elapsed.Round(time.Second))
 
-	result, err := s.parseReviewResponse(strings.TrimSpace(string(output)))
+	response, _ = s.conform(ctx, prompt, response, ask)
+	result, err := s.parseReviewResponse(response)
 	// Text output format doesn't include usage data
 	return result, nil, err
 }
 
+// reviewWithProvider runs the system-prompt review on an API provider.
+func (s *ScottBott) reviewWithProvider(ctx context.Context, provider llm.Provider, systemPrompt, prompt, model string) (*ReviewResult, *cost.Usage, error) {
+	fmt.Fprintf(s.output(), "   🔍 Reviewing with %s (%s)...\n", provider.Name(), modelName(model))
+
+	attemptCtx, cancel := s.attemptContext(ctx)
+	defer cancel()
+
+	ask := func(ctx context.Context, prompt string) (string, *cost.Usage, error) {
+		return provider.Complete(ctx, llm.Request{Model: model, System: systemPrompt, Prompt: prompt, Sampling: s.sampling()})
+	}
+
+	start := time.Now()
+	response, usage, err := ask(attemptCtx, prompt)
+	elapsed := time.Since(start)
+	if err != nil {
+		return nil, nil, attemptError(attemptCtx, err, elapsed)
+	}
+
+	fmt.Fprintf(s.output(), "   ⏱️  Review completed in %s\n", elapsed.Round(time.Second))
+
+	response, retryUsage := s.conform(ctx, prompt, response, ask)
+	if retryUsage != nil {
+		if usage == nil {
+			usage = &cost.Usage{}
+		}
+		*usage = usage.Add(*retryUsage)
+	}
+	result, err := s.parseReviewResponse(response)
+	return result, usage, err
+}
+
 // formatReviewPrompt creates the prompt for code review.
-func formatReviewPrompt(ticketContext, diff string) string {
+// Includes the repo's review rubric when one is configured, and the
+// ticket's acceptance criteria when it has any.
+func (s *ScottBott) formatReviewPrompt(ticketContext, diff string) string {
+	rubric := ""
+	if s.cfg != nil {
+		if section := formatRubric(s.cfg.Review.Rubric); section != "" {
+			rubric = "\n\n" + section
+		}
+	}
+	if s.criteria != nil {
+		if section := s.criteria.Prompt(); section != "" {
+			rubric += "\n\n" + section
+		}
+	}
+	if s.focus != "" {
+		rubric += "\n\n" + s.focus
+	}
+	if s.strict() {
+		rubric += "\n\n" + schemaPrompt()
+	}
 	return fmt.Sprintf(`## Ticket Context
 %s
 
 ## Code Changes
-%s
+%s%s
 
-Review these changes against the requirements. Provide your assessment.`, ticketContext, diff)
+Review these changes against the requirements. Provide your assessment.`, ticketContext, diff, rubric)
 }
 
 // parseReviewResponse extracts ReviewResult from Claude's response.
@@ -239,11 +500,23 @@ func (s *ScottBott) parseReviewResponse(response string) (*ReviewResult, error)
 
 	var result ReviewResult
 	if err := json.Unmarshal([]byte(jsonStr), &result); err == nil {
+		s.applyRubric(&result)
 		return &result, nil
 	}
 
 	// If JSON parsing fails, parse natural language response
-	return s.parseNaturalLanguageReview(response)
+	nlResult, err := s.parseNaturalLanguageReview(response)
+	if nlResult != nil {
+		s.applyRubric(nlResult)
+	}
+	return nlResult, err
+}
+
+// applyRubric applies the configured rubric weights to a parsed result.
+func (s *ScottBott) applyRubric(result *ReviewResult) {
+	if s.cfg != nil {
+		result.ApplyRubric(s.cfg.Review.Rubric)
+	}
 }
 
 // parseNaturalLanguageReview extracts review info from a natural language response.
@@ -416,6 +689,15 @@ func (s *ScottBott) parseNaturalLanguageReview(response string) (*ReviewResult,
 		result.Guidance = issueGuidance.String()
 	}
 
+	// Extract per-category scores if the reviewer listed them
+	var extra []string
+	if s.cfg != nil {
+		for category := range s.cfg.Review.Rubric.Weights {
+			extra = append(extra, category)
+		}
+	}
+	result.Breakdown = parseBreakdown(response, extra)
+
 	return result, nil
 }
 
@@ -443,14 +725,22 @@ func (r *ReviewResult) FormatReview() string {
 	var sb strings.Builder
 
 	sb.WriteString("   ┌─────────────────────────────────────────┐\n")
-	if r.Passed {
+	if r.Inconclusive {
+		sb.WriteString("   │  ❔ REVIEW INCONCLUSIVE                 │\n")
+	} else if r.Passed {
 		sb.WriteString("   │  ✅ REVIEW PASSED                       │\n")
 	} else {
 		sb.WriteString("   │  ❌ REVIEW FAILED                       │\n")
 	}
 	sb.WriteString("   └─────────────────────────────────────────┘\n")
 
-	sb.WriteString(fmt.Sprintf("   📊 Score: %d/100\n\n", r.Score))
+	sb.WriteString(fmt.Sprintf("   📊 Score: %d/100\n", r.Score))
+	if len(r.Breakdown) > 0 {
+		for _, category := range breakdownCategories(r.Breakdown) {
+			sb.WriteString(fmt.Sprintf("      • %-12s %3d\n", category, r.Breakdown[category]))
+		}
+	}
+	sb.WriteString("\n")
 	sb.WriteString(fmt.Sprintf("   📝 Summary:\n      %s\n\n", r.Summary))
 
 	if len(r.Praise) > 0 {
@@ -485,6 +775,9 @@ func (r *ReviewResult) FormatReview() string {
 			if issue.Suggestion != "" {
 				sb.WriteString(fmt.Sprintf("         💡 %s\n", issue.Suggestion))
 			}
+			if issue.Reviewer != "" {
+				sb.WriteString(fmt.Sprintf("         👥 %s\n", strings.ReplaceAll(issue.Reviewer, ",", ", ")))
+			}
 			sb.WriteString("\n")
 		}
 	}
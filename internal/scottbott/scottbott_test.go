@@ -0,0 +1,98 @@
+package scottbott
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/acceptance"
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/decisionlog"
+)
+
+func TestReviewWithProvider(t *testing.T) {
+	var model string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Model string `json:"model"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		model = req.Model
+		w.Write([]byte(`{"message":{"content":"{\"passed\": true, \"score\": 90, \"summary\": \"Looks good\"}"},"prompt_eval_count":100,"eval_count":10}`))
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{ReviewSkill: "peer-review"}
+	cfg.Claude.Command = "/nonexistent/claude" // The CLI must not be needed
+	cfg.Claude.Models.Reviewer = "qwen2.5-coder"
+	cfg.Claude.Provider = config.ProviderConfig{Name: "ollama", BaseURL: srv.URL}
+	s := NewWithWorkDir(t.TempDir(), 1, cfg)
+	decisions := decisionlog.New()
+	s.SetDecisionLog(decisions)
+
+	result, usage, err := s.Review(context.Background(), "Add a feature", "+code")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Passed || result.Score != 90 {
+		t.Errorf("result = %+v", result)
+	}
+	if usage == nil || usage.InputTokens != 100 {
+		t.Errorf("usage = %+v", usage)
+	}
+	if model != "qwen2.5-coder" {
+		t.Errorf("model = %q, want the reviewer model", model)
+	}
+	if decisions.Len() != 1 {
+		t.Errorf("decisions = %+v, want the skipped skill recorded", decisions.Entries())
+	}
+}
+
+func TestReviewInvalidProvider(t *testing.T) {
+	cfg := &config.Config{ReviewSkill: "peer-review"}
+	cfg.Claude.Command = "/nonexistent/claude"
+	cfg.Claude.Provider = config.ProviderConfig{Name: "antropic"}
+	s := NewWithWorkDir(t.TempDir(), 1, cfg)
+
+	_, _, err := s.Review(context.Background(), "Add a feature", "+code")
+	if err == nil || !strings.Contains(err.Error(), "antropic") {
+		t.Errorf("Expected the provider error, got %v", err)
+	}
+}
+
+func TestReviewCriteria(t *testing.T) {
+	var prompt string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages []struct {
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		for _, m := range req.Messages {
+			prompt += m.Content
+		}
+		w.Write([]byte(`{"message":{"content":"{\"passed\": false, \"score\": 60, \"criteria\": [{\"id\": 1, \"met\": true}, {\"id\": 2, \"met\": false, \"evidence\": \"no BHD case\"}]}"}}`))
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{ReviewSkill: "peer-review"}
+	cfg.Claude.Provider = config.ProviderConfig{Name: "ollama", BaseURL: srv.URL}
+	s := NewWithWorkDir(t.TempDir(), 1, cfg)
+	s.SetCriteria(acceptance.Parse("## Acceptance Criteria\n- JPY has no decimals\n- BHD has three decimals"))
+
+	result, _, err := s.Review(context.Background(), "Round totals", "+code")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(prompt, "2. [required] BHD has three decimals") {
+		t.Errorf("prompt is missing the criteria:\n%s", prompt)
+	}
+	want := []acceptance.Check{{ID: 1, Met: true}, {ID: 2, Met: false, Evidence: "no BHD case"}}
+	if len(result.Criteria) != 2 || result.Criteria[0] != want[0] || result.Criteria[1] != want[1] {
+		t.Errorf("Criteria = %+v, want %+v", result.Criteria, want)
+	}
+}
@@ -0,0 +1,142 @@
+// Package secrets resolves credentials kept in a secret store instead of
+// in config files or plain environment variables. A setting such as
+// linear_key, or a variable such as LINEAR_API_KEY, holds a reference:
+//
+//	keychain:<service>/<account>  macOS Keychain, or the Secret Service
+//	                              (secret-tool) on Linux
+//	vault:<path>#<field>          a HashiCorp Vault KV secret
+//	op://<vault>/<item>/<field>   a 1Password item field
+//
+// Each reference is looked up once per process with the store's CLI.
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reference prefixes.
+const (
+	Keychain    = "keychain:"
+	Vault       = "vault:"
+	OnePassword = "op://"
+)
+
+// timeout bounds a lookup, which may wait on an unlock prompt.
+const timeout = 30 * time.Second
+
+// IsReference reports whether value names a secret rather than holding one.
+func IsReference(value string) bool {
+	return strings.HasPrefix(value, Keychain) || strings.HasPrefix(value, Vault) || strings.HasPrefix(value, OnePassword)
+}
+
+// Resolver looks up references and caches what they resolve to.
+type Resolver struct {
+	goos string
+	run  func(ctx context.Context, name string, args ...string) (string, error)
+
+	mu    sync.Mutex
+	cache map[string]string
+}
+
+// New creates a Resolver that runs the stores' CLIs.
+func New() *Resolver {
+	return &Resolver{goos: runtime.GOOS, run: run, cache: make(map[string]string)}
+}
+
+var defaultResolver = New()
+
+// Resolve resolves value with a Resolver shared by the whole process, so
+// each secret is looked up once.
+func Resolve(value string) (string, error) {
+	return defaultResolver.Resolve(value)
+}
+
+// Resolve returns the secret value references, or value itself if it is
+// not a reference.
+func (r *Resolver) Resolve(value string) (string, error) {
+	if !IsReference(value) {
+		return value, nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if secret, ok := r.cache[value]; ok {
+		return secret, nil
+	}
+
+	name, args, err := r.command(value)
+	if err != nil {
+		return "", err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	secret, err := r.run(ctx, name, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", value, err)
+	}
+	secret = strings.TrimRight(secret, "\r\n")
+	if secret == "" {
+		return "", fmt.Errorf("%s is empty", value)
+	}
+	r.cache[value] = secret
+	return secret, nil
+}
+
+// command returns the CLI invocation printing the referenced secret.
+func (r *Resolver) command(ref string) (string, []string, error) {
+	switch {
+	case strings.HasPrefix(ref, Keychain):
+		service, account, _ := strings.Cut(strings.TrimPrefix(ref, Keychain), "/")
+		if service == "" {
+			return "", nil, fmt.Errorf("%s needs a service, e.g. keychain:boatman/linear", ref)
+		}
+		switch r.goos {
+		case "darwin":
+			args := []string{"find-generic-password", "-s", service, "-w"}
+			if account != "" {
+				args = append(args, "-a", account)
+			}
+			return "security", args, nil
+		case "linux", "freebsd", "openbsd":
+			args := []string{"lookup", "service", service}
+			if account != "" {
+				args = append(args, "account", account)
+			}
+			return "secret-tool", args, nil
+		}
+		return "", nil, fmt.Errorf("%s: keychain references need macOS or secret-tool", ref)
+	case strings.HasPrefix(ref, Vault):
+		path, field, _ := strings.Cut(strings.TrimPrefix(ref, Vault), "#")
+		if path == "" || field == "" {
+			return "", nil, fmt.Errorf("%s needs a path and field, e.g. vault:secret/boatman#linear_key", ref)
+		}
+		return "vault", []string{"kv", "get", "-field=" + field, path}, nil
+	default:
+		return "op", []string{"read", "--no-newline", ref}, nil
+	}
+}
+
+// run runs a store's CLI and returns what it printed.
+func run(ctx context.Context, name string, args ...string) (string, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return "", fmt.Errorf("%s is not installed", name)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", errors.New(msg)
+		}
+		return "", err
+	}
+	return stdout.String(), nil
+}
@@ -0,0 +1,86 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fakeStore records the lookups it is asked for.
+type fakeStore struct {
+	calls  []string
+	output string
+	err    error
+}
+
+func (f *fakeStore) resolver(goos string) *Resolver {
+	r := New()
+	r.goos = goos
+	r.run = func(ctx context.Context, name string, args ...string) (string, error) {
+		f.calls = append(f.calls, name+" "+strings.Join(args, " "))
+		return f.output, f.err
+	}
+	return r
+}
+
+func TestResolve(t *testing.T) {
+	store := &fakeStore{output: "lin_api_123\n"}
+	r := store.resolver("darwin")
+
+	if got, err := r.Resolve("lin_plain"); got != "lin_plain" || err != nil || len(store.calls) != 0 {
+		t.Errorf("Resolve(plain) = %q, %v after %q", got, err, store.calls)
+	}
+	for range 2 {
+		got, err := r.Resolve("keychain:boatman/linear")
+		if got != "lin_api_123" || err != nil {
+			t.Errorf("Resolve = %q, %v", got, err)
+		}
+	}
+	// Cached after the first lookup
+	if want := []string{"security find-generic-password -s boatman -w -a linear"}; strings.Join(store.calls, "\n") != strings.Join(want, "\n") {
+		t.Errorf("calls = %q, want %q", store.calls, want)
+	}
+}
+
+func TestCommands(t *testing.T) {
+	tests := []struct {
+		goos, ref, want string
+	}{
+		{"linux", "keychain:boatman/linear", "secret-tool lookup service boatman account linear"},
+		{"darwin", "keychain:boatman", "security find-generic-password -s boatman -w"},
+		{"linux", "vault:secret/boatman#linear_key", "vault kv get -field=linear_key secret/boatman"},
+		{"windows", "op://Engineering/Linear/credential", "op read --no-newline op://Engineering/Linear/credential"},
+	}
+	for _, tt := range tests {
+		store := &fakeStore{output: "s3cret"}
+		if _, err := store.resolver(tt.goos).Resolve(tt.ref); err != nil || len(store.calls) != 1 || store.calls[0] != tt.want {
+			t.Errorf("Resolve(%s) on %s ran %q (%v), want %q", tt.ref, tt.goos, store.calls, err, tt.want)
+		}
+	}
+}
+
+func TestResolveErrors(t *testing.T) {
+	for _, ref := range []string{"keychain:", "vault:secret/boatman", "vault:#field"} {
+		if _, err := (&fakeStore{output: "x"}).resolver("linux").Resolve(ref); err == nil {
+			t.Errorf("Resolve(%s) succeeded", ref)
+		}
+	}
+	if _, err := (&fakeStore{output: "x"}).resolver("windows").Resolve("keychain:boatman"); err == nil {
+		t.Error("keychain reference resolved without a keychain")
+	}
+
+	store := &fakeStore{err: errors.New("The specified item could not be found in the keychain.")}
+	r := store.resolver("darwin")
+	if _, err := r.Resolve("keychain:boatman/linear"); err == nil || !strings.Contains(err.Error(), "could not be found") {
+		t.Errorf("err = %v, want the store's error", err)
+	}
+	// Failures aren't cached
+	store.err, store.output = nil, ""
+	if _, err := r.Resolve("keychain:boatman/linear"); err == nil || !strings.Contains(err.Error(), "is empty") {
+		t.Errorf("err = %v, want an empty secret error", err)
+	}
+	if len(store.calls) != 2 {
+		t.Errorf("calls = %q", store.calls)
+	}
+}
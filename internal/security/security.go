@@ -0,0 +1,542 @@
+// Package security scans the lines a change adds for hard-coded secrets,
+// by pattern and by entropy, and for dangerous code such as eval and SQL
+// built from strings. gitleaks and trivy add their findings where they are
+// installed. Secrets are critical findings, which block the commit.
+package security
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/scottbott"
+	"github.com/philjestin/boatmanmode/internal/unidiff"
+)
+
+// maxIssues is the most findings reported as separate review issues; the
+// rest are summed up in one.
+const maxIssues = 20
+
+// Finding is a security problem in a line the change adds, or for trivy,
+// in a dependency it adds or bumps.
+type Finding struct {
+	Tool     string // "boatman", "gitleaks" or "trivy"
+	Rule     string
+	Severity string // critical, major or minor
+	File     string
+	Line     int
+	Message  string // Never includes the secret itself
+}
+
+// String describes the finding, e.g. "config/app.rb:3: AWS access key".
+func (f Finding) String() string {
+	if f.Line > 0 {
+		return fmt.Sprintf("%s:%d: %s", f.File, f.Line, f.Message)
+	}
+	return fmt.Sprintf("%s: %s", f.File, f.Message)
+}
+
+// Result is the outcome of a scan.
+type Result struct {
+	Findings []Finding
+	Tools    []string // External scanners that ran
+	Skipped  []string // External scanners that could not run, and why
+}
+
+// Critical returns the findings that block the commit.
+func (r *Result) Critical() []Finding {
+	var critical []Finding
+	for _, f := range r.Findings {
+		if f.Severity == "critical" {
+			critical = append(critical, f)
+		}
+	}
+	return critical
+}
+
+// Summary counts the findings by severity, e.g. "1 critical, 2 major".
+func (r *Result) Summary() string {
+	counts := make(map[string]int)
+	for _, f := range r.Findings {
+		counts[f.Severity]++
+	}
+	var parts []string
+	for _, severity := range []string{"critical", "major", "minor"} {
+		if counts[severity] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[severity], severity))
+		}
+	}
+	if len(parts) == 0 {
+		return "no findings"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Issues returns the findings as review issues, so the refactor loop
+// removes them. Past maxIssues the rest are summed up in one issue.
+func (r *Result) Issues() []scottbott.Issue {
+	var issues []scottbott.Issue
+	for i, f := range r.Findings {
+		if i == maxIssues {
+			issues = append(issues, scottbott.Issue{
+				Severity:    r.Findings[i].Severity,
+				Description: fmt.Sprintf("%d more security finding(s)", len(r.Findings)-maxIssues),
+			})
+			break
+		}
+		issues = append(issues, scottbott.Issue{
+			Severity:    f.Severity,
+			File:        f.File,
+			Line:        f.Line,
+			Description: "Security: " + f.Message,
+			Suggestion:  suggestion(f),
+		})
+	}
+	return issues
+}
+
+func suggestion(f Finding) string {
+	switch {
+	case f.Tool == "trivy":
+		return "Upgrade the dependency to a fixed version"
+	case strings.HasPrefix(f.Rule, "sql"):
+		return "Pass values as bound query parameters instead of building the SQL string"
+	case strings.HasPrefix(f.Rule, "eval"):
+		return "Avoid evaluating code built at runtime; dispatch on known values instead"
+	}
+	return "Remove the secret and read it from the environment or a secret store; rotate it if it was real"
+}
+
+// Scanner scans a worktree's diff.
+type Scanner struct {
+	worktreePath string
+	cfg          config.SecurityConfig
+}
+
+// New creates a Scanner. Returns nil when the scan is disabled.
+func New(worktreePath string, cfg config.SecurityConfig) *Scanner {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &Scanner{worktreePath: worktreePath, cfg: cfg}
+}
+
+// Scan checks the lines diff adds, then runs the configured external
+// scanners that are installed, keeping what they find in added lines.
+func (s *Scanner) Scan(ctx context.Context, diff string) (*Result, error) {
+	result := &Result{}
+	added := make(map[string]map[int]string) // File to added line to content
+	for _, f := range unidiff.Parse(diff) {
+		if f.Status == unidiff.Deleted || s.ignored(f.Path()) {
+			continue
+		}
+		lines := make(map[int]string)
+		for _, l := range f.Lines() {
+			if l.Kind == unidiff.LineAdded {
+				lines[l.NewLine] = l.Content
+			}
+		}
+		added[f.Path()] = lines
+		result.Findings = append(result.Findings, ScanLines(f.Path(), f.Lines())...)
+	}
+	if len(added) == 0 {
+		return result, nil
+	}
+
+	// External scanners report the whole worktree; only added lines count
+	for _, tool := range s.cfg.Tools {
+		var findings []Finding
+		var err error
+		switch tool {
+		case "gitleaks":
+			findings, err = s.gitleaks(ctx, added)
+		case "trivy":
+			findings, err = s.trivy(ctx, added)
+		default:
+			err = errors.New("unknown scanner (want gitleaks or trivy)")
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if errors.Is(err, errNotInstalled) {
+			continue
+		}
+		if err != nil {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("%s: %v", tool, err))
+			continue
+		}
+		result.Tools = append(result.Tools, tool)
+		result.Findings = merge(result.Findings, findings)
+	}
+	// Most severe first, so they are reported when issues are capped
+	slices.SortStableFunc(result.Findings, func(a, b Finding) int {
+		return rank[a.Severity] - rank[b.Severity]
+	})
+	return result, nil
+}
+
+var rank = map[string]int{"critical": 0, "major": 1, "minor": 2}
+
+func (s *Scanner) ignored(file string) bool {
+	for _, pattern := range s.cfg.Ignore {
+		if config.MatchPathPattern(pattern, file) {
+			return true
+		}
+	}
+	return false
+}
+
+// merge adds findings not already reported on the same line.
+func merge(findings, more []Finding) []Finding {
+	seen := make(map[string]bool)
+	for _, f := range findings {
+		seen[fmt.Sprintf("%s:%d", f.File, f.Line)] = true
+	}
+	for _, f := range more {
+		if key := fmt.Sprintf("%s:%d", f.File, f.Line); f.Line == 0 || !seen[key] {
+			seen[key] = true
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// secretRule matches a known credential format.
+type secretRule struct {
+	id      string
+	pattern *regexp.Regexp
+	name    string
+}
+
+var secretRules = []secretRule{
+	{"private-key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`), "private key"},
+	{"aws-access-key", regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`), "AWS access key"},
+	{"github-token", regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,}\b|\bgithub_pat_[A-Za-z0-9_]{22,}`), "GitHub token"},
+	{"slack-token", regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`), "Slack token"},
+	{"stripe-key", regexp.MustCompile(`\b[rs]k_live_[A-Za-z0-9]{16,}`), "Stripe live key"},
+	{"google-api-key", regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`), "Google API key"},
+	{"anthropic-key", regexp.MustCompile(`\bsk-ant-[A-Za-z0-9_-]{20,}`), "Anthropic API key"},
+	{"openai-key", regexp.MustCompile(`\bsk-(proj-)?[A-Za-z0-9]{32,}`), "OpenAI API key"},
+	{"linear-key", regexp.MustCompile(`\blin_api_[A-Za-z0-9]{32,}`), "Linear API key"},
+}
+
+var (
+	// assignment is a quoted value given to a secret-like name.
+	assignment = regexp.MustCompile(`(?i)\b([a-z0-9_.-]*(?:password|passwd|secret|api_?key|access_?key|auth_?token|private_?key|token))["']?\s*(?::=|=>|=|:)\s*["'` + "`" + `]([^"'` + "`" + `\s]{8,})["'` + "`" + `]`)
+	// notSecretName is a secret-like name for something else, e.g. token_url.
+	notSecretName = regexp.MustCompile(`(?i)(url|uri|path|file|name|env|header|type|prefix|field|length|count)$`)
+	// placeholder is a value standing in for a secret.
+	placeholder = regexp.MustCompile(`(?i)example|changeme|placeholder|dummy|your[_-]|xxxx|\*\*\*\*|^[A-Z0-9_]+$|\$\{|\{\{|^<|^\$|^(keychain|vault):|^op://`)
+	// token is a quoted string that could be a generated credential.
+	token = regexp.MustCompile(`["'` + "`" + `]([A-Za-z0-9+/=_.-]{32,})["'` + "`" + `]`)
+	// checksum is a line holding subresource integrity hashes.
+	checksum = regexp.MustCompile(`(?i)\bintegrity\b|\bsha(?:256|384|512)-`)
+	// fixtureDir holds test data, whose random-looking strings are rarely
+	// credentials.
+	fixtureDir = regexp.MustCompile(`(?:^|/)(?:testdata|fixtures|__fixtures__)/`)
+)
+
+// lockfiles hold checksums, which look like secrets but aren't.
+var lockfiles = map[string]bool{
+	"go.sum": true, "package-lock.json": true, "yarn.lock": true, "pnpm-lock.yaml": true,
+	"Gemfile.lock": true, "poetry.lock": true, "Pipfile.lock": true, "Cargo.lock": true, "composer.lock": true,
+}
+
+// codeRule matches dangerous code in files with one of exts.
+type codeRule struct {
+	id      string
+	exts    []string
+	pattern *regexp.Regexp
+	message string
+}
+
+var (
+	scriptExts = []string{".js", ".jsx", ".ts", ".tsx", ".mjs", ".cjs", ".py", ".rb", ".php"}
+	jsExts     = []string{".js", ".jsx", ".ts", ".tsx", ".mjs", ".cjs"}
+)
+
+var codeRules = []codeRule{
+	{"eval", scriptExts, regexp.MustCompile(`(^|[^\w.$])eval\s*\(`), "eval of code built at runtime"},
+	{"eval-exec", []string{".py"}, regexp.MustCompile(`(^|[^\w.])exec\s*\(`), "exec of code built at runtime"},
+	{"eval-function", jsExts, regexp.MustCompile(`\bnew\s+Function\s*\(`), "Function constructor builds code from strings"},
+	{"sql-concat", nil, inSQL("\"'`", ``, `Q\s*(\+|\.\s|<<)\s*[\w$@(]`), "SQL built by string concatenation"},
+	{"sql-interpolation", nil, inSQL("`", ``, `\$\{`), "SQL built by string interpolation"},
+	{"sql-interpolation", []string{".rb"}, inSQL(`"`, ``, `#\{`), "SQL built by string interpolation"},
+	{"sql-fstring", []string{".py"}, inSQL(`"'`, `\bf`, `\{`), "SQL built with an f-string"},
+	{"sql-format", nil, inSQL("\"`", `\b(?:Sprintf|format)\(\s*`, `%[sv]`), "SQL built with a format string"},
+	{"sql-format", []string{".py"}, inSQL(`"'`, ``, `%s[^Q]*Q\s*%\s*[\w(]`), "SQL built with % formatting"},
+}
+
+// inSQL matches before, then a string quoted with one of quotes that holds
+// a SQL statement (as opposed to prose such as "Select a file"), then
+// after, in which Q stands for the quote.
+func inSQL(quotes, before, after string) *regexp.Regexp {
+	var alternatives []string
+	for _, q := range quotes {
+		quote := regexp.QuoteMeta(string(q))
+		statement := `(?i:SELECT\s[^` + quote + `]*\bFROM|INSERT\s+INTO|UPDATE\s+[\w.]+\s+SET|DELETE\s+FROM)\b[^` + quote + `]*`
+		alternatives = append(alternatives, before+quote+statement+strings.ReplaceAll(after, "Q", quote))
+	}
+	return regexp.MustCompile(strings.Join(alternatives, "|"))
+}
+
+// ScanLines checks the lines added to file (a project-relative path) for
+// secrets and dangerous code.
+func ScanLines(file string, lines []unidiff.Line) []Finding {
+	var findings []Finding
+	ext := strings.ToLower(path.Ext(file))
+	for _, l := range lines {
+		if l.Kind != unidiff.LineAdded {
+			continue
+		}
+		finding := func(rule, severity, message string) {
+			findings = append(findings, Finding{Tool: "boatman", Rule: rule, Severity: severity, File: file, Line: l.NewLine, Message: message})
+		}
+		if rule, message, severity := secret(file, l.Content); rule != "" {
+			finding(rule, severity, message)
+		}
+		if isComment(l.Content) || ext == ".sql" || ext == ".md" {
+			continue
+		}
+		for _, rule := range codeRules {
+			if (rule.exts == nil || slices.Contains(rule.exts, ext)) && rule.pattern.MatchString(l.Content) {
+				finding(rule.id, "major", rule.message)
+				break
+			}
+		}
+	}
+	return findings
+}
+
+// secret returns the rule, message and severity of a secret in line, or
+// an empty rule.
+func secret(file, line string) (string, string, string) {
+	for _, rule := range secretRules {
+		if rule.pattern.MatchString(line) {
+			return rule.id, "Hard-coded " + rule.name, "critical"
+		}
+	}
+	for _, m := range assignment.FindAllStringSubmatch(line, -1) {
+		name, value := m[1], m[2]
+		if notSecretName.MatchString(name) || placeholder.MatchString(value) || Entropy(value) < 3 {
+			continue
+		}
+		return "generic-secret", fmt.Sprintf("Hard-coded secret assigned to %s", name), "critical"
+	}
+	if lockfiles[path.Base(file)] || fixtureDir.MatchString(file) || checksum.MatchString(line) {
+		return "", "", ""
+	}
+	for _, m := range token.FindAllStringSubmatch(line, -1) {
+		if value := m[1]; mixedCase(value) && Entropy(value) >= 4.5 {
+			return "high-entropy", "High-entropy string, possibly a secret", "minor"
+		}
+	}
+	return "", "", ""
+}
+
+// Entropy returns the Shannon entropy of s in bits per character.
+// Generated keys score over 4.5; words and identifiers under 4.
+func Entropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	for _, r := range s {
+		counts[r]++
+	}
+	n := float64(len([]rune(s)))
+	var entropy float64
+	for _, c := range counts {
+		p := float64(c) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// mixedCase reports whether s has upper and lower case letters and
+// digits, as generated keys do and hex digests don't.
+func mixedCase(s string) bool {
+	var upper, lower, digit bool
+	for _, r := range s {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= '0' && r <= '9':
+			digit = true
+		}
+	}
+	return upper && lower && digit
+}
+
+func isComment(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	for _, prefix := range []string{"//", "#", "/*", "*", "--"} {
+		if strings.HasPrefix(trimmed, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// errNotInstalled skips a scanner that isn't on PATH.
+var errNotInstalled = errors.New("not installed")
+
+// runTool runs an external scanner in the worktree and returns its
+// output.
+func (s *Scanner) runTool(ctx context.Context, name string, args ...string) ([]byte, error) {
+	if _, err := exec.LookPath(name); err != nil {
+		return nil, errNotInstalled
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = s.worktreePath
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, errors.New(lastLine(msg))
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+// gitleaks scans the worktree with gitleaks, keeping the leaks in added
+// lines. Secrets are redacted from its report.
+func (s *Scanner) gitleaks(ctx context.Context, added map[string]map[int]string) ([]Finding, error) {
+	report, err := os.CreateTemp("", "boatman-gitleaks-*.json")
+	if err != nil {
+		return nil, err
+	}
+	report.Close()
+	defer os.Remove(report.Name())
+
+	if _, err := s.runTool(ctx, "gitleaks", "detect", "--no-git", "--no-banner", "--redact",
+		"--source", ".", "--report-format", "json", "--report-path", report.Name(), "--exit-code", "0"); err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(report.Name())
+	if err != nil {
+		return nil, err
+	}
+	var leaks []struct {
+		RuleID      string
+		Description string
+		File        string
+		StartLine   int
+	}
+	if err := json.Unmarshal(data, &leaks); err != nil {
+		return nil, fmt.Errorf("unreadable report: %w", err)
+	}
+	var findings []Finding
+	for _, leak := range leaks {
+		file := s.relative(leak.File)
+		if _, ok := added[file][leak.StartLine]; !ok {
+			continue
+		}
+		findings = append(findings, Finding{
+			Tool:     "gitleaks",
+			Rule:     leak.RuleID,
+			Severity: "critical",
+			File:     file,
+			Line:     leak.StartLine,
+			Message:  fmt.Sprintf("Hard-coded secret: %s (gitleaks %s)", leak.Description, leak.RuleID),
+		})
+	}
+	return findings, nil
+}
+
+// manifests are the dependency files trivy reads.
+var manifests = map[string]bool{
+	"go.mod": true, "go.sum": true, "package.json": true, "package-lock.json": true, "yarn.lock": true,
+	"pnpm-lock.yaml": true, "Gemfile.lock": true, "requirements.txt": true, "poetry.lock": true,
+	"Pipfile.lock": true, "Cargo.lock": true, "composer.lock": true, "pom.xml": true,
+}
+
+// trivy scans the worktree's dependencies with trivy when the change
+// touches a dependency file, keeping the vulnerabilities of packages the
+// change adds or bumps there.
+func (s *Scanner) trivy(ctx context.Context, added map[string]map[int]string) ([]Finding, error) {
+	touched := false
+	for file := range added {
+		touched = touched || manifests[path.Base(file)]
+	}
+	if !touched {
+		return nil, nil
+	}
+	output, err := s.runTool(ctx, "trivy", "fs", "--scanners", "vuln", "--format", "json", "--quiet", "--exit-code", "0", ".")
+	if err != nil {
+		return nil, err
+	}
+	var report struct {
+		Results []struct {
+			Target          string
+			Vulnerabilities []struct {
+				VulnerabilityID  string
+				PkgName          string
+				InstalledVersion string
+				FixedVersion     string
+				Severity         string
+				Title            string
+			}
+		}
+	}
+	if err := json.Unmarshal(output, &report); err != nil {
+		return nil, fmt.Errorf("unreadable report: %w", err)
+	}
+	var findings []Finding
+	for _, r := range report.Results {
+		file := s.relative(r.Target)
+		lines, ok := added[file]
+		if !ok {
+			continue
+		}
+		var text strings.Builder
+		for _, line := range lines {
+			text.WriteString(line + "\n")
+		}
+		for _, v := range r.Vulnerabilities {
+			severity := map[string]string{"CRITICAL": "critical", "HIGH": "major", "MEDIUM": "minor"}[v.Severity]
+			if severity == "" || !strings.Contains(text.String(), v.PkgName) {
+				continue
+			}
+			message := fmt.Sprintf("%s in %s %s", v.VulnerabilityID, v.PkgName, v.InstalledVersion)
+			if v.FixedVersion != "" {
+				message += " (fixed in " + v.FixedVersion + ")"
+			}
+			if v.Title != "" {
+				message += ": " + v.Title
+			}
+			findings = append(findings, Finding{Tool: "trivy", Rule: v.VulnerabilityID, Severity: severity, File: file, Message: message})
+		}
+	}
+	return findings, nil
+}
+
+// relative makes a scanner's path relative to the worktree.
+func (s *Scanner) relative(file string) string {
+	if filepath.IsAbs(file) {
+		if rel, err := filepath.Rel(s.worktreePath, file); err == nil {
+			file = rel
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(file))
+}
+
+func lastLine(s string) string {
+	lines := strings.Split(s, "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
@@ -0,0 +1,328 @@
+// Package selfupdate updates the boatman binary from GitHub releases.
+// Release assets follow the GoReleaser archive and checksum naming in
+// .goreleaser.yml; checksums.txt is verified before the binary is swapped.
+package selfupdate
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Release channels.
+const (
+	ChannelStable = "stable" // Full releases only
+	ChannelBeta   = "beta"   // Also pre-releases (-beta, -rc, ...)
+)
+
+// DefaultRepo is the GitHub repository releases are published to.
+const DefaultRepo = "philjestin/boatmanmode"
+
+// ChecksumsAsset is the GoReleaser checksum file name.
+const ChecksumsAsset = "checksums.txt"
+
+// ErrNoRelease is returned when no release matches the channel.
+var ErrNoRelease = errors.New("no matching release found")
+
+// Asset is a downloadable release file.
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
+}
+
+// Release is a GitHub release.
+type Release struct {
+	TagName    string  `json:"tag_name"`
+	Draft      bool    `json:"draft"`
+	Prerelease bool    `json:"prerelease"`
+	Assets     []Asset `json:"assets"`
+}
+
+// Version returns the release version without the leading "v".
+func (r *Release) Version() string {
+	return strings.TrimPrefix(r.TagName, "v")
+}
+
+// Asset returns the named asset, or nil.
+func (r *Release) Asset(name string) *Asset {
+	for i := range r.Assets {
+		if r.Assets[i].Name == name {
+			return &r.Assets[i]
+		}
+	}
+	return nil
+}
+
+// Client talks to the GitHub releases API.
+type Client struct {
+	HTTP    *http.Client
+	BaseURL string // API base URL (default: https://api.github.com)
+	Repo    string // owner/name (default: DefaultRepo)
+}
+
+// New creates a Client with defaults.
+func New() *Client {
+	return &Client{
+		HTTP:    &http.Client{Timeout: 2 * time.Minute},
+		BaseURL: "https://api.github.com",
+		Repo:    DefaultRepo,
+	}
+}
+
+// Latest returns the newest release on the channel.
+func (c *Client) Latest(ctx context.Context, channel string) (*Release, error) {
+	if channel != ChannelStable && channel != ChannelBeta {
+		return nil, fmt.Errorf("unknown channel %q (want %s or %s)", channel, ChannelStable, ChannelBeta)
+	}
+
+	data, err := c.get(ctx, fmt.Sprintf("%s/repos/%s/releases?per_page=30", c.BaseURL, c.Repo))
+	if err != nil {
+		return nil, err
+	}
+	var releases []Release
+	if err := json.Unmarshal(data, &releases); err != nil {
+		return nil, fmt.Errorf("failed to parse releases: %w", err)
+	}
+
+	var latest *Release
+	for i := range releases {
+		r := &releases[i]
+		if r.Draft || (r.Prerelease && channel == ChannelStable) {
+			continue
+		}
+		if latest == nil || CompareVersions(r.Version(), latest.Version()) > 0 {
+			latest = r
+		}
+	}
+	if latest == nil {
+		return nil, ErrNoRelease
+	}
+	return latest, nil
+}
+
+// Download fetches an asset's content.
+func (c *Client) Download(ctx context.Context, asset *Asset) ([]byte, error) {
+	return c.get(ctx, asset.URL)
+}
+
+func (c *Client) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" && strings.HasPrefix(url, c.BaseURL) {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// AssetName returns the GoReleaser archive name for a platform.
+func AssetName(version, goos, goarch string) string {
+	arch := goarch
+	if goarch == "amd64" {
+		arch = "x86_64"
+	}
+	ext := "tar.gz"
+	if goos == "windows" {
+		ext = "zip"
+	}
+	osName := strings.ToUpper(goos[:1]) + goos[1:]
+	return fmt.Sprintf("boatmanmode_%s_%s_%s.%s", version, osName, arch, ext)
+}
+
+// CurrentAssetName returns the archive name for the running platform.
+func CurrentAssetName(version string) string {
+	return AssetName(version, runtime.GOOS, runtime.GOARCH)
+}
+
+// VerifyChecksum checks data against its entry in a sha256 checksums file.
+func VerifyChecksum(data []byte, checksums, name string) error {
+	sum := sha256.Sum256(data)
+	got := hex.EncodeToString(sum[:])
+
+	for _, line := range strings.Split(checksums, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[1] == name {
+			if !strings.EqualFold(fields[0], got) {
+				return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", name, fields[0], got)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("no checksum for %s", name)
+}
+
+// ExtractBinary returns the boatman executable from a release archive.
+func ExtractBinary(archive []byte, assetName string) ([]byte, error) {
+	if strings.HasSuffix(assetName, ".zip") {
+		return extractZip(archive, "boatman.exe")
+	}
+	return extractTarGz(archive, "boatman")
+}
+
+func extractTarGz(archive []byte, binary string) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive: %w", err)
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid archive: %w", err)
+		}
+		if hdr.Typeflag == tar.TypeReg && filepath.Base(hdr.Name) == binary {
+			return io.ReadAll(tr)
+		}
+	}
+	return nil, fmt.Errorf("%s not found in archive", binary)
+}
+
+func extractZip(archive []byte, binary string) ([]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid archive: %w", err)
+	}
+	for _, f := range zr.File {
+		if filepath.Base(f.Name) != binary {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		return io.ReadAll(rc)
+	}
+	return nil, fmt.Errorf("%s not found in archive", binary)
+}
+
+// ReplaceExecutable atomically swaps the binary at path for data.
+// The old binary is moved aside first, which also works for a running
+// executable on Windows, and restored if the swap fails.
+func ReplaceExecutable(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, ".boatman-update-*")
+	if err != nil {
+		return fmt.Errorf("cannot write to %s: %w", dir, err)
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath)
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmpPath, 0755); err != nil {
+		return err
+	}
+
+	oldPath := path + ".old"
+	os.Remove(oldPath)
+	if err := os.Rename(path, oldPath); err != nil {
+		return fmt.Errorf("failed to move current binary aside: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Rename(oldPath, path)
+		return fmt.Errorf("failed to install new binary: %w", err)
+	}
+	os.Remove(oldPath) // Fails harmlessly on Windows while the old binary runs
+	return nil
+}
+
+// CompareVersions compares semantic versions (with or without "v"),
+// returning -1, 0 or 1. A pre-release sorts before its release.
+func CompareVersions(a, b string) int {
+	aCore, aPre, _ := strings.Cut(strings.TrimPrefix(a, "v"), "-")
+	bCore, bPre, _ := strings.Cut(strings.TrimPrefix(b, "v"), "-")
+
+	aParts := strings.Split(aCore, ".")
+	bParts := strings.Split(bCore, ".")
+	for i := 0; i < 3; i++ {
+		if c := compareInt(part(aParts, i), part(bParts, i)); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case aPre == bPre:
+		return 0
+	case aPre == "":
+		return 1
+	case bPre == "":
+		return -1
+	}
+	return comparePrerelease(aPre, bPre)
+}
+
+// comparePrerelease compares dot-separated pre-release identifiers,
+// numerically where both are numbers (so beta.10 > beta.2).
+func comparePrerelease(a, b string) int {
+	aIDs := strings.Split(a, ".")
+	bIDs := strings.Split(b, ".")
+	for i := 0; i < len(aIDs) && i < len(bIDs); i++ {
+		an, aErr := strconv.Atoi(aIDs[i])
+		bn, bErr := strconv.Atoi(bIDs[i])
+		var c int
+		if aErr == nil && bErr == nil {
+			c = compareInt(an, bn)
+		} else {
+			c = strings.Compare(aIDs[i], bIDs[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return compareInt(len(aIDs), len(bIDs))
+}
+
+func part(parts []string, i int) int {
+	if i >= len(parts) {
+		return 0
+	}
+	n, _ := strconv.Atoi(parts[i])
+	return n
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,132 @@
+package selfupdate
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"v1.10.0", "1.9.9", 1},
+		{"1.2.0", "1.2.0-beta.1", 1},
+		{"1.2.0-beta.2", "1.2.0-beta.10", -1},
+		{"1.2.0-rc.1", "1.2.0-beta.3", 1},
+		{"2.0", "1.99.99", 1},
+	}
+	for _, tt := range tests {
+		if got := CompareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestAssetName(t *testing.T) {
+	if got := AssetName("1.4.0", "darwin", "arm64"); got != "boatmanmode_1.4.0_Darwin_arm64.tar.gz" {
+		t.Errorf("Unexpected darwin asset: %s", got)
+	}
+	if got := AssetName("1.4.0", "linux", "amd64"); got != "boatmanmode_1.4.0_Linux_x86_64.tar.gz" {
+		t.Errorf("Unexpected linux asset: %s", got)
+	}
+	if got := AssetName("1.4.0", "windows", "amd64"); got != "boatmanmode_1.4.0_Windows_x86_64.zip" {
+		t.Errorf("Unexpected windows asset: %s", got)
+	}
+}
+
+func TestLatestByChannel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[
+			{"tag_name": "v2.0.0", "draft": true},
+			{"tag_name": "v1.3.0-beta.1", "prerelease": true},
+			{"tag_name": "v1.2.0"},
+			{"tag_name": "v1.10.0-rc.1", "prerelease": true},
+			{"tag_name": "v1.1.0"}
+		]`))
+	}))
+	defer server.Close()
+
+	client := New()
+	client.BaseURL = server.URL
+
+	stable, err := client.Latest(context.Background(), ChannelStable)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stable.TagName != "v1.2.0" {
+		t.Errorf("Expected stable v1.2.0, got %s", stable.TagName)
+	}
+
+	beta, err := client.Latest(context.Background(), ChannelBeta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if beta.TagName != "v1.10.0-rc.1" {
+		t.Errorf("Expected beta v1.10.0-rc.1, got %s", beta.TagName)
+	}
+
+	if _, err := client.Latest(context.Background(), "nightly"); err == nil {
+		t.Error("Expected error for unknown channel")
+	}
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	data := []byte("archive")
+	sum := sha256.Sum256(data)
+	checksums := hex.EncodeToString(sum[:]) + "  boatmanmode_1.0.0_Linux_x86_64.tar.gz\n"
+
+	if err := VerifyChecksum(data, checksums, "boatmanmode_1.0.0_Linux_x86_64.tar.gz"); err != nil {
+		t.Errorf("Expected checksum to verify: %v", err)
+	}
+	if err := VerifyChecksum([]byte("tampered"), checksums, "boatmanmode_1.0.0_Linux_x86_64.tar.gz"); err == nil {
+		t.Error("Expected checksum mismatch")
+	}
+	if err := VerifyChecksum(data, checksums, "other.tar.gz"); err == nil {
+		t.Error("Expected missing checksum error")
+	}
+}
+
+func TestExtractAndReplace(t *testing.T) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	content := []byte("#!/bin/sh\necho new\n")
+	tw.WriteHeader(&tar.Header{Name: "README.md", Mode: 0644, Size: 2, Typeflag: tar.TypeReg})
+	tw.Write([]byte("hi"))
+	tw.WriteHeader(&tar.Header{Name: "boatman", Mode: 0755, Size: int64(len(content)), Typeflag: tar.TypeReg})
+	tw.Write(content)
+	tw.Close()
+	gz.Close()
+
+	binary, err := ExtractBinary(buf.Bytes(), "boatmanmode_1.0.0_Linux_x86_64.tar.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(binary, content) {
+		t.Errorf("Unexpected binary content: %q", binary)
+	}
+
+	exe := filepath.Join(t.TempDir(), "boatman")
+	os.WriteFile(exe, []byte("old"), 0755)
+	if err := ReplaceExecutable(exe, binary); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := os.ReadFile(exe)
+	if !bytes.Equal(got, content) {
+		t.Errorf("Expected binary to be replaced, got %q", got)
+	}
+	if _, err := os.Stat(exe + ".old"); !os.IsNotExist(err) {
+		t.Error("Expected old binary to be removed")
+	}
+}
@@ -0,0 +1,57 @@
+package selfupdate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Signature assets produced by the cosign keyless signing step in .goreleaser.yml.
+const (
+	SignatureAsset   = ChecksumsAsset + ".sig"
+	CertificateAsset = ChecksumsAsset + ".pem"
+)
+
+// ErrCosignUnavailable is returned when the cosign CLI isn't installed.
+var ErrCosignUnavailable = errors.New("cosign not installed")
+
+// VerifySignature verifies the keyless cosign signature of checksums,
+// requiring it to come from this repository's GitHub Actions release workflow.
+func VerifySignature(ctx context.Context, repo string, checksums, signature, certificate []byte) error {
+	if _, err := exec.LookPath("cosign"); err != nil {
+		return ErrCosignUnavailable
+	}
+
+	dir, err := os.MkdirTemp("", "boatman-verify-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string][]byte{
+		ChecksumsAsset:   checksums,
+		SignatureAsset:   signature,
+		CertificateAsset: certificate,
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			return err
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, "cosign", "verify-blob",
+		"--certificate", filepath.Join(dir, CertificateAsset),
+		"--signature", filepath.Join(dir, SignatureAsset),
+		"--certificate-identity-regexp", fmt.Sprintf("^https://github.com/%s/", repo),
+		"--certificate-oidc-issuer", "https://token.actions.githubusercontent.com",
+		filepath.Join(dir, ChecksumsAsset),
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("signature verification failed: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
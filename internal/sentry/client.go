@@ -0,0 +1,304 @@
+// Package sentry provides a client for the Sentry API, used to add the
+// error context of Sentry issues linked in bug tickets to the agents'
+// prompts: the exception, its stack trace, and the breadcrumbs leading up
+// to it.
+package sentry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/retry"
+)
+
+// Client is a Sentry API client.
+type Client struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// New creates a new Sentry client.
+func New(cfg config.SentryConfig) *Client {
+	return &Client{
+		baseURL:    strings.TrimSuffix(cfg.URL, "/"),
+		token:      cfg.Token,
+		httpClient: &http.Client{},
+	}
+}
+
+// Configured reports whether the client has a token to call the API with.
+func (c *Client) Configured() bool {
+	return c.token != ""
+}
+
+// Issue is a Sentry issue linked from a ticket.
+type Issue struct {
+	ID  string
+	Org string // Empty when the link doesn't name the organization
+	URL string
+}
+
+// issueLink matches links to Sentry issues, e.g.
+// https://acme.sentry.io/issues/4711/ or
+// https://sentry.io/organizations/acme/issues/4711/.
+var issueLink = regexp.MustCompile(`https?://[\w.-]+(?::\d+)?/(?:organizations/([\w-]+)/)?issues/(\d+)`)
+
+// Issues returns the Sentry issues linked in text, each once. Links count
+// when they are to sentry.io or to the client's server.
+func (c *Client) Issues(text string) []Issue {
+	var issues []Issue
+	seen := make(map[string]bool)
+	for _, m := range issueLink.FindAllStringSubmatch(text, -1) {
+		u, err := url.Parse(m[0])
+		if err != nil || !c.isSentry(u.Host) {
+			continue
+		}
+		org := m[1]
+		if org == "" && strings.HasSuffix(u.Hostname(), ".sentry.io") {
+			// Organization subdomain, e.g. acme.sentry.io
+			org = strings.TrimSuffix(u.Hostname(), ".sentry.io")
+		}
+		if seen[m[2]] {
+			continue
+		}
+		seen[m[2]] = true
+		issues = append(issues, Issue{ID: m[2], Org: org, URL: m[0]})
+	}
+	return issues
+}
+
+func (c *Client) isSentry(host string) bool {
+	if host == "sentry.io" || strings.HasSuffix(host, ".sentry.io") {
+		return true
+	}
+	base, err := url.Parse(c.baseURL)
+	return err == nil && base.Host != "" && strings.EqualFold(host, base.Host)
+}
+
+// Event is an occurrence of a Sentry issue.
+type Event struct {
+	Title       string
+	Culprit     string
+	Environment string
+	Release     string
+	// Exceptions are the chained exceptions, the one raised last first.
+	Exceptions  []Exception
+	Breadcrumbs []Breadcrumb
+}
+
+// Exception is an exception of an event with its stack trace.
+type Exception struct {
+	Type  string
+	Value string
+	// Frames are the stack frames, the innermost (where it was raised)
+	// first.
+	Frames []Frame
+}
+
+// Frame is a stack frame of an exception.
+type Frame struct {
+	File     string
+	Line     int
+	Function string
+	InApp    bool // In the project's own code rather than a dependency
+}
+
+// Breadcrumb is an event recorded before the error, such as a request,
+// query or log message.
+type Breadcrumb struct {
+	Timestamp string
+	Category  string
+	Level     string
+	Message   string
+}
+
+// apiEvent is an event as the API returns it.
+type apiEvent struct {
+	Title   string `json:"title"`
+	Culprit string `json:"culprit"`
+	Tags    []struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+	} `json:"tags"`
+	Entries []struct {
+		Type string          `json:"type"`
+		Data json.RawMessage `json:"data"`
+	} `json:"entries"`
+}
+
+type apiException struct {
+	Values []struct {
+		Type       string `json:"type"`
+		Value      string `json:"value"`
+		Stacktrace *struct {
+			Frames []struct {
+				Filename string `json:"filename"`
+				AbsPath  string `json:"absPath"`
+				LineNo   int    `json:"lineNo"`
+				Function string `json:"function"`
+				InApp    bool   `json:"inApp"`
+			} `json:"frames"`
+		} `json:"stacktrace"`
+	} `json:"values"`
+}
+
+type apiBreadcrumbs struct {
+	Values []struct {
+		Timestamp string         `json:"timestamp"`
+		Category  string         `json:"category"`
+		Level     string         `json:"level"`
+		Message   string         `json:"message"`
+		Data      map[string]any `json:"data"`
+	} `json:"values"`
+}
+
+// LatestEvent fetches the most recent event of issue.
+func (c *Client) LatestEvent(ctx context.Context, issue Issue) (*Event, error) {
+	path := "/issues/" + url.PathEscape(issue.ID) + "/events/latest/"
+	if issue.Org != "" {
+		path = "/organizations/" + url.PathEscape(issue.Org) + path
+	}
+	resp, err := c.execute(ctx, "/api/0"+path)
+	if err != nil {
+		return nil, err
+	}
+
+	var result apiEvent
+	if err := json.Unmarshal(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return parseEvent(result), nil
+}
+
+func parseEvent(result apiEvent) *Event {
+	e := &Event{Title: result.Title, Culprit: result.Culprit}
+	for _, tag := range result.Tags {
+		switch tag.Key {
+		case "environment":
+			e.Environment = tag.Value
+		case "release":
+			e.Release = tag.Value
+		}
+	}
+
+	for _, entry := range result.Entries {
+		switch entry.Type {
+		case "exception":
+			var data apiException
+			if json.Unmarshal(entry.Data, &data) != nil {
+				continue
+			}
+			// Sentry lists chained exceptions and frames oldest first
+			for i := len(data.Values) - 1; i >= 0; i-- {
+				v := data.Values[i]
+				ex := Exception{Type: v.Type, Value: v.Value}
+				if v.Stacktrace != nil {
+					for j := len(v.Stacktrace.Frames) - 1; j >= 0; j-- {
+						f := v.Stacktrace.Frames[j]
+						file := f.Filename
+						if file == "" {
+							file = f.AbsPath
+						}
+						ex.Frames = append(ex.Frames, Frame{File: file, Line: f.LineNo, Function: f.Function, InApp: f.InApp})
+					}
+				}
+				e.Exceptions = append(e.Exceptions, ex)
+			}
+		case "breadcrumbs":
+			var data apiBreadcrumbs
+			if json.Unmarshal(entry.Data, &data) != nil {
+				continue
+			}
+			for _, b := range data.Values {
+				e.Breadcrumbs = append(e.Breadcrumbs, Breadcrumb{
+					Timestamp: b.Timestamp,
+					Category:  b.Category,
+					Level:     b.Level,
+					Message:   breadcrumbMessage(b.Message, b.Data),
+				})
+			}
+		}
+	}
+	return e
+}
+
+// breadcrumbMessage is the message of a breadcrumb, or for requests
+// without one, the request and its status.
+func breadcrumbMessage(message string, data map[string]any) string {
+	if message != "" || data == nil {
+		return message
+	}
+	target, _ := data["url"].(string)
+	if target == "" {
+		return ""
+	}
+	msg := target
+	if method, ok := data["method"].(string); ok {
+		msg = method + " " + msg
+	}
+	if status, ok := data["status_code"]; ok {
+		msg += fmt.Sprintf(" → %v", status)
+	}
+	return msg
+}
+
+// execute performs a GET request to the Sentry API with retry logic.
+func (c *Client) execute(ctx context.Context, path string) ([]byte, error) {
+	if c.token == "" {
+		return nil, retry.Permanent(fmt.Errorf("sentry auth token is not configured"))
+	}
+
+	var result []byte
+
+	err := retry.Do(ctx, retry.APIConfig(), "Sentry API request", func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+		if err != nil {
+			return retry.Permanent(fmt.Errorf("failed to create request: %w", err))
+		}
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("Authorization", "Bearer "+c.token)
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("request failed: %w", err) // Retryable
+		}
+		defer resp.Body.Close()
+
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+
+		// 429 is retryable; other 4xx errors are permanent (client errors)
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			return fmt.Errorf("API returned status %d: %s", resp.StatusCode, errorMessage(respBody))
+		}
+		if resp.StatusCode >= 400 {
+			return retry.Permanent(fmt.Errorf("API returned status %d: %s", resp.StatusCode, errorMessage(respBody)))
+		}
+
+		result = respBody
+		return nil
+	})
+
+	return result, err
+}
+
+// errorMessage extracts Sentry's error detail from a response body.
+func errorMessage(body []byte) string {
+	var result struct {
+		Detail string `json:"detail"`
+	}
+	if json.Unmarshal(body, &result) == nil && result.Detail != "" {
+		return result.Detail
+	}
+	return string(body)
+}
@@ -0,0 +1,110 @@
+package sentry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+)
+
+func TestIssues(t *testing.T) {
+	c := New(config.SentryConfig{URL: "https://sentry.acme.com/", Token: "token"})
+	text := `Export crashes, see https://acme.sentry.io/issues/4711/?project=2
+and https://sentry.io/organizations/acme/issues/4712/ (same as https://acme.sentry.io/issues/4711/).
+Self-hosted: https://sentry.acme.com/organizations/ops/issues/99/
+Not Sentry: https://github.com/acme/app/issues/12`
+
+	got := c.Issues(text)
+	want := []Issue{
+		{ID: "4711", Org: "acme", URL: "https://acme.sentry.io/issues/4711"},
+		{ID: "4712", Org: "acme", URL: "https://sentry.io/organizations/acme/issues/4712"},
+		{ID: "99", Org: "ops", URL: "https://sentry.acme.com/organizations/ops/issues/99"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Issues = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Issues[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+const latestEvent = `{
+	"title": "TypeError: amount is None",
+	"culprit": "app/billing/invoice.py in total",
+	"tags": [{"key": "environment", "value": "production"}, {"key": "release", "value": "2026.3.1"}],
+	"entries": [
+		{"type": "exception", "data": {"values": [
+			{"type": "KeyError", "value": "'amount'", "stacktrace": {"frames": [
+				{"filename": "app/billing/parse.py", "lineNo": 8, "function": "parse", "inApp": true}]}},
+			{"type": "TypeError", "value": "amount is None", "stacktrace": {"frames": [
+				{"filename": "django/core/handlers/base.py", "lineNo": 113, "function": "get_response", "inApp": false},
+				{"filename": "app/billing/views.py", "lineNo": 30, "function": "export", "inApp": true},
+				{"filename": "app/billing/invoice.py", "lineNo": 42, "function": "total", "inApp": true}]}}]}},
+		{"type": "breadcrumbs", "data": {"values": [
+			{"timestamp": "2026-03-02T10:00:00Z", "category": "http", "level": "info", "data": {"method": "GET", "url": "/billing/export", "status_code": 500}},
+			{"timestamp": "2026-03-02T10:00:01Z", "category": "query", "level": "info", "message": "SELECT * FROM invoices WHERE id = 7"},
+			{"timestamp": "2026-03-02T10:00:02Z", "category": "log", "level": "warning", "message": "invoice 7 has no amount"}]}}
+	]
+}`
+
+func TestLatestEvent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/0/organizations/acme/issues/4711/events/latest/" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer token" {
+			t.Errorf("Authorization = %q", got)
+		}
+		w.Write([]byte(latestEvent))
+	}))
+	defer srv.Close()
+
+	c := New(config.SentryConfig{URL: srv.URL, Token: "token"})
+	issue := Issue{ID: "4711", Org: "acme", URL: "https://acme.sentry.io/issues/4711"}
+	event, err := c.LatestEvent(context.Background(), issue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event.Environment != "production" || event.Release != "2026.3.1" || len(event.Exceptions) != 2 || len(event.Breadcrumbs) != 3 {
+		t.Fatalf("event = %+v", event)
+	}
+	if ex := event.Exceptions[0]; ex.Type != "TypeError" || ex.Frames[0].File != "app/billing/invoice.py" {
+		t.Errorf("exceptions are not innermost first: %+v", ex)
+	}
+
+	distilled := event.Distill(issue)
+	for _, want := range []string{
+		"**Sentry issue:** https://acme.sentry.io/issues/4711",
+		"**TypeError**: amount is None\n- app/billing/invoice.py:42 in total\n- app/billing/views.py:30 in export\n",
+		"**KeyError**",
+		"[http] GET /billing/export → 500",
+		"[log] WARNING invoice 7 has no amount",
+	} {
+		if !strings.Contains(distilled, want) {
+			t.Errorf("Distill() missing %q:\n%s", want, distilled)
+		}
+	}
+	// Frames outside the project's code are left out
+	if strings.Contains(distilled, "django") {
+		t.Errorf("Distill() lists dependency frames:\n%s", distilled)
+	}
+}
+
+func TestLatestEventNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"detail": "The requested resource does not exist"}`))
+	}))
+	defer srv.Close()
+
+	c := New(config.SentryConfig{URL: srv.URL, Token: "token"})
+	_, err := c.LatestEvent(context.Background(), Issue{ID: "1"})
+	if err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Errorf("err = %v", err)
+	}
+}
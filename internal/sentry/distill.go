@@ -0,0 +1,107 @@
+package sentry
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// maxFrames caps the frames listed per exception; the innermost are
+	// where the problem usually is.
+	maxFrames = 12
+	// maxBreadcrumbs caps the breadcrumbs listed, the last before the
+	// error.
+	maxBreadcrumbs = 15
+	// maxMessage caps the length of an exception value or breadcrumb.
+	maxMessage = 200
+)
+
+// Distill summarizes the event for a prompt: the exceptions with their
+// frames in the project's code (all frames if none are), and the last
+// breadcrumbs before the error. Frames are written as file:line.
+func (e *Event) Distill(issue Issue) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("### %s\n\n", e.Title))
+	sb.WriteString(fmt.Sprintf("**Sentry issue:** %s\n", issue.URL))
+	if e.Culprit != "" {
+		sb.WriteString(fmt.Sprintf("**Culprit:** %s\n", e.Culprit))
+	}
+	if e.Environment != "" {
+		sb.WriteString(fmt.Sprintf("**Environment:** %s\n", e.Environment))
+	}
+	if e.Release != "" {
+		sb.WriteString(fmt.Sprintf("**Release:** %s\n", e.Release))
+	}
+
+	for _, ex := range e.Exceptions {
+		sb.WriteString(fmt.Sprintf("\n**%s**: %s\n", ex.Type, truncate(ex.Value)))
+		frames := inApp(ex.Frames)
+		for i, f := range frames {
+			if i == maxFrames {
+				sb.WriteString(fmt.Sprintf("- ... %d more frames\n", len(frames)-maxFrames))
+				break
+			}
+			sb.WriteString("- " + f.File)
+			if f.Line > 0 {
+				sb.WriteString(fmt.Sprintf(":%d", f.Line))
+			}
+			if f.Function != "" {
+				sb.WriteString(" in " + f.Function)
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	crumbs := e.Breadcrumbs
+	if len(crumbs) > maxBreadcrumbs {
+		crumbs = crumbs[len(crumbs)-maxBreadcrumbs:]
+	}
+	if len(crumbs) > 0 {
+		sb.WriteString("\n**Breadcrumbs** (oldest first):\n")
+		for _, b := range crumbs {
+			if b.Message == "" {
+				continue
+			}
+			sb.WriteString("- ")
+			if b.Timestamp != "" {
+				sb.WriteString(b.Timestamp + " ")
+			}
+			if b.Category != "" {
+				sb.WriteString("[" + b.Category + "] ")
+			}
+			if b.Level != "" && b.Level != "info" {
+				sb.WriteString(strings.ToUpper(b.Level) + " ")
+			}
+			sb.WriteString(truncate(b.Message) + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// inApp returns the frames in the project's code, or all of them when
+// Sentry marked none.
+func inApp(frames []Frame) []Frame {
+	var own []Frame
+	for _, f := range frames {
+		if f.InApp {
+			own = append(own, f)
+		}
+	}
+	if len(own) == 0 {
+		return frames
+	}
+	return own
+}
+
+func truncate(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) <= maxMessage {
+		return s
+	}
+	cut := maxMessage
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "..."
+}
@@ -0,0 +1,17 @@
+package sentry
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncate(t *testing.T) {
+	s := truncate(strings.Repeat("é", maxMessage))
+	if !utf8.ValidString(s) || !strings.HasSuffix(s, "...") || len(s) > maxMessage+3 {
+		t.Errorf("truncate cut a rune or kept too much: %q", s)
+	}
+	if s := truncate("short   message\n"); s != "short message" {
+		t.Errorf("truncate = %q", s)
+	}
+}
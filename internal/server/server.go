@@ -0,0 +1,282 @@
+// Package server receives the webhooks of boatman serve: a Linear issue
+// gaining the trigger label, or a GitHub issue comment asking boatman to
+// implement a ticket, queues a run for that ticket. Every delivery's
+// signature is checked against the source's secret.
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+)
+
+// maxBody is the largest webhook payload read.
+const maxBody = 1 << 20
+
+// maxAge is how old a Linear delivery may be, to refuse replays.
+const maxAge = 5 * time.Minute
+
+// ErrNoSecrets is returned when no webhook source has a secret, so no
+// delivery could be verified.
+var ErrNoSecrets = errors.New("no webhook secret configured (set LINEAR_WEBHOOK_SECRET or GITHUB_WEBHOOK_SECRET)")
+
+// defaultAssociations may ask for runs from GitHub comments.
+var defaultAssociations = []string{"OWNER", "MEMBER", "COLLABORATOR"}
+
+// ticketID matches a tracker ticket ID such as ENG-123.
+var ticketID = regexp.MustCompile(`\b[A-Z][A-Z0-9]*-\d+\b`)
+
+// Jobs queues the runs webhooks ask for.
+type Jobs interface {
+	// Enqueue returns false if the ticket is already queued or running.
+	Enqueue(ticketID string, priority int) bool
+}
+
+// Server handles webhook deliveries.
+type Server struct {
+	cfg  config.ServeConfig
+	jobs Jobs
+	log  func(format string, args ...any)
+	now  func() time.Time
+}
+
+// New creates a Server that queues runs on jobs.
+func New(cfg config.ServeConfig, jobs Jobs) (*Server, error) {
+	if cfg.Linear.Secret == "" && cfg.GitHub.Secret == "" {
+		return nil, ErrNoSecrets
+	}
+	if cfg.Linear.Label == "" {
+		cfg.Linear.Label = "boatman"
+	}
+	if cfg.GitHub.Command == "" {
+		cfg.GitHub.Command = "/boatman implement"
+	}
+	if len(cfg.GitHub.Associations) == 0 {
+		cfg.GitHub.Associations = defaultAssociations
+	}
+	return &Server{
+		cfg:  cfg,
+		jobs: jobs,
+		log:  func(format string, args ...any) { fmt.Printf(format+"\n", args...) },
+		now:  time.Now,
+	}, nil
+}
+
+// Handler returns the server's routes: POST /webhooks/linear, POST
+// /webhooks/github and GET /healthz.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /webhooks/linear", s.handleLinear)
+	mux.HandleFunc("POST /webhooks/github", s.handleGitHub)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok\n"))
+	})
+	return mux
+}
+
+// linearEvent is the part of a Linear webhook delivery boatman reads.
+type linearEvent struct {
+	Action string `json:"action"`
+	Type   string `json:"type"`
+	Data   struct {
+		Identifier string `json:"identifier"`
+		Priority   int    `json:"priority"`
+		Labels     []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"labels"`
+	} `json:"data"`
+	UpdatedFrom struct {
+		LabelIDs *[]string `json:"labelIds"`
+	} `json:"updatedFrom"`
+	// WebhookTimestamp is when Linear sent the delivery, in milliseconds
+	WebhookTimestamp int64 `json:"webhookTimestamp"`
+}
+
+func (s *Server) handleLinear(w http.ResponseWriter, r *http.Request) {
+	body, ok := s.verify(w, r, s.cfg.Linear.Secret, "Linear-Signature", "")
+	if !ok {
+		return
+	}
+	var event linearEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	if event.WebhookTimestamp > 0 {
+		if age := s.now().Sub(time.UnixMilli(event.WebhookTimestamp)); age > maxAge || age < -maxAge {
+			http.Error(w, "stale delivery", http.StatusUnauthorized)
+			return
+		}
+	}
+	if event.Type != "Issue" || !labelAdded(event, s.cfg.Linear.Label) {
+		ignore(w, fmt.Sprintf("not a %q label being added to an issue", s.cfg.Linear.Label))
+		return
+	}
+	s.enqueue(w, "Linear", event.Data.Identifier, event.Data.Priority)
+}
+
+// labelAdded reports whether the event adds the label to the issue: it is
+// created with it, or updated to have it when it didn't before.
+func labelAdded(event linearEvent, label string) bool {
+	var id string
+	for _, l := range event.Data.Labels {
+		if strings.EqualFold(l.Name, label) {
+			id = l.ID
+		}
+	}
+	switch {
+	case id == "":
+		return false
+	case event.Action == "create":
+		return true
+	case event.Action != "update" || event.UpdatedFrom.LabelIDs == nil:
+		// An update that didn't touch the labels
+		return false
+	}
+	for _, before := range *event.UpdatedFrom.LabelIDs {
+		if before == id {
+			return false
+		}
+	}
+	return true
+}
+
+// githubComment is the part of a GitHub issue_comment delivery boatman
+// reads.
+type githubComment struct {
+	Action string `json:"action"`
+	Issue  struct {
+		Number int    `json:"number"`
+		Title  string `json:"title"`
+	} `json:"issue"`
+	Comment struct {
+		Body              string `json:"body"`
+		AuthorAssociation string `json:"author_association"`
+		User              struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	} `json:"comment"`
+}
+
+func (s *Server) handleGitHub(w http.ResponseWriter, r *http.Request) {
+	body, ok := s.verify(w, r, s.cfg.GitHub.Secret, "X-Hub-Signature-256", "sha256=")
+	if !ok {
+		return
+	}
+	switch event := r.Header.Get("X-GitHub-Event"); event {
+	case "ping":
+		ignore(w, "pong")
+		return
+	case "issue_comment":
+	default:
+		ignore(w, fmt.Sprintf("%s events aren't handled", event))
+		return
+	}
+
+	var event githubComment
+	if err := json.Unmarshal(body, &event); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	arg, isCommand := strings.CutPrefix(strings.TrimSpace(event.Comment.Body), s.cfg.GitHub.Command)
+	if arg != "" && !unicode.IsSpace(rune(arg[0])) {
+		isCommand = false
+	}
+	if event.Action != "created" || !isCommand {
+		ignore(w, fmt.Sprintf("not a new %s comment", s.cfg.GitHub.Command))
+		return
+	}
+	if !s.allowed(event.Comment.AuthorAssociation) {
+		s.log("⛔ Ignoring %s from %s (%s)", s.cfg.GitHub.Command, event.Comment.User.Login, event.Comment.AuthorAssociation)
+		ignore(w, fmt.Sprintf("%s may not start runs", event.Comment.User.Login))
+		return
+	}
+
+	// The ticket named after the command, else the one in the issue title
+	ticket := ticketID.FindString(strings.SplitN(strings.TrimSpace(arg), "\n", 2)[0])
+	if ticket == "" {
+		ticket = ticketID.FindString(event.Issue.Title)
+	}
+	if ticket == "" {
+		ignore(w, fmt.Sprintf("no ticket ID in the comment or the title of issue #%d", event.Issue.Number))
+		return
+	}
+	s.enqueue(w, "GitHub", ticket, 0)
+}
+
+func (s *Server) allowed(association string) bool {
+	for _, a := range s.cfg.GitHub.Associations {
+		if strings.EqualFold(a, association) {
+			return true
+		}
+	}
+	return false
+}
+
+// verify reads the request body and checks its HMAC-SHA256 signature in
+// header, after prefix. It writes the error response and returns false
+// if the source has no secret or the signature doesn't match.
+func (s *Server) verify(w http.ResponseWriter, r *http.Request, secret, header, prefix string) ([]byte, bool) {
+	if secret == "" {
+		http.NotFound(w, r)
+		return nil, false
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBody))
+	if err != nil {
+		http.Error(w, "could not read body", http.StatusBadRequest)
+		return nil, false
+	}
+	signature, ok := strings.CutPrefix(r.Header.Get(header), prefix)
+	if !ok || !validSignature(body, secret, signature) {
+		s.log("⛔ Rejected a delivery to %s with a bad signature", r.URL.Path)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return nil, false
+	}
+	return body, true
+}
+
+// validSignature reports whether signature is the hex HMAC-SHA256 of body.
+func validSignature(body []byte, secret, signature string) bool {
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return false
+	}
+	want, _ := hex.DecodeString(sign(body, secret))
+	return hmac.Equal(got, want)
+}
+
+// sign returns the hex HMAC-SHA256 of body, as webhook senders sign it.
+func sign(body []byte, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *Server) enqueue(w http.ResponseWriter, source, ticket string, priority int) {
+	queued := s.jobs.Enqueue(ticket, priority)
+	if queued {
+		s.log("🔔 %s asked for %s", source, ticket)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]any{"ticket": ticket, "queued": queued})
+}
+
+// ignore acknowledges a delivery that doesn't start a run, so the sender
+// doesn't retry it.
+func ignore(w http.ResponseWriter, reason string) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"ignored": reason})
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+)
+
+// fakeJobs records the tickets enqueued.
+type fakeJobs struct {
+	tickets []string
+}
+
+func (f *fakeJobs) Enqueue(ticketID string, priority int) bool {
+	f.tickets = append(f.tickets, fmt.Sprintf("%s/%d", ticketID, priority))
+	return true
+}
+
+var now = time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+
+func newTestServer(t *testing.T, cfg config.ServeConfig) (*Server, *fakeJobs) {
+	t.Helper()
+	jobs := &fakeJobs{}
+	s, err := New(cfg, jobs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.log = func(format string, args ...any) {}
+	s.now = func() time.Time { return now }
+	return s, jobs
+}
+
+func deliver(s *Server, path, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+	return rec
+}
+
+func linearIssue(action, updatedFrom string, ts time.Time) string {
+	return fmt.Sprintf(`{"action": %q, "type": "Issue", "webhookTimestamp": %d,
+		"data": {"identifier": "ENG-1", "priority": 2, "labels": [{"id": "l1", "name": "Boatman"}, {"id": "l2", "name": "bug"}]},
+		"updatedFrom": %s}`, action, ts.UnixMilli(), updatedFrom)
+}
+
+func TestNewNeedsSecret(t *testing.T) {
+	if _, err := New(config.ServeConfig{}, &fakeJobs{}); err != ErrNoSecrets {
+		t.Errorf("err = %v, want ErrNoSecrets", err)
+	}
+}
+
+func TestLinearWebhook(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		queued bool
+	}{
+		{"label added", linearIssue("update", `{"labelIds": ["l2"]}`, now), http.StatusAccepted, true},
+		{"created with label", linearIssue("create", `{}`, now), http.StatusAccepted, true},
+		{"label already there", linearIssue("update", `{"labelIds": ["l1"]}`, now), http.StatusOK, false},
+		{"labels untouched", linearIssue("update", `{"title": "Old"}`, now), http.StatusOK, false},
+		{"replayed", linearIssue("update", `{"labelIds": []}`, now.Add(-time.Hour)), http.StatusUnauthorized, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, jobs := newTestServer(t, config.ServeConfig{Linear: config.LinearWebhookConfig{Secret: "lin-secret"}})
+			rec := deliver(s, "/webhooks/linear", tt.body, map[string]string{"Linear-Signature": sign([]byte(tt.body), "lin-secret")})
+			if rec.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", rec.Code, tt.status, rec.Body)
+			}
+			if queued := len(jobs.tickets) == 1 && jobs.tickets[0] == "ENG-1/2"; queued != tt.queued {
+				t.Errorf("tickets = %v, want queued %v", jobs.tickets, tt.queued)
+			}
+		})
+	}
+}
+
+func TestSignatureRequired(t *testing.T) {
+	s, jobs := newTestServer(t, config.ServeConfig{Linear: config.LinearWebhookConfig{Secret: "lin-secret"}})
+	body := linearIssue("create", `{}`, now)
+
+	if rec := deliver(s, "/webhooks/linear", body, map[string]string{"Linear-Signature": sign([]byte(body), "wrong")}); rec.Code != http.StatusUnauthorized {
+		t.Errorf("bad signature: status = %d", rec.Code)
+	}
+	if rec := deliver(s, "/webhooks/linear", body, nil); rec.Code != http.StatusUnauthorized {
+		t.Errorf("no signature: status = %d", rec.Code)
+	}
+	// GitHub has no secret, so its endpoint is off
+	if rec := deliver(s, "/webhooks/github", body, nil); rec.Code != http.StatusNotFound {
+		t.Errorf("unconfigured source: status = %d", rec.Code)
+	}
+	if len(jobs.tickets) != 0 {
+		t.Errorf("tickets = %v", jobs.tickets)
+	}
+}
+
+func TestGitHubWebhook(t *testing.T) {
+	comment := func(action, body, association string) string {
+		return fmt.Sprintf(`{"action": %q, "issue": {"number": 12, "title": "Export fails (ENG-9)"},
+			"comment": {"body": %q, "author_association": %q, "user": {"login": "jo"}}}`, action, body, association)
+	}
+	tests := []struct {
+		name   string
+		event  string
+		body   string
+		ticket string
+	}{
+		{"named ticket", "issue_comment", comment("created", "/boatman implement ENG-42\nplease", "MEMBER"), "ENG-42/0"},
+		{"ticket from title", "issue_comment", comment("created", "/boatman implement", "OWNER"), "ENG-9/0"},
+		{"not the command", "issue_comment", comment("created", "/boatman implementation notes", "MEMBER"), ""},
+		{"edited", "issue_comment", comment("edited", "/boatman implement ENG-42", "MEMBER"), ""},
+		{"outsider", "issue_comment", comment("created", "/boatman implement ENG-42", "NONE"), ""},
+		{"ping", "ping", `{"zen": "Keep it logically awesome."}`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, jobs := newTestServer(t, config.ServeConfig{GitHub: config.GitHubWebhookConfig{Secret: "gh-secret"}})
+			rec := deliver(s, "/webhooks/github", tt.body, map[string]string{
+				"X-GitHub-Event":      tt.event,
+				"X-Hub-Signature-256": "sha256=" + sign([]byte(tt.body), "gh-secret"),
+			})
+			want := http.StatusOK
+			if tt.ticket != "" {
+				want = http.StatusAccepted
+			}
+			if rec.Code != want {
+				t.Errorf("status = %d, want %d: %s", rec.Code, want, rec.Body)
+			}
+			if got := strings.Join(jobs.tickets, ","); got != tt.ticket {
+				t.Errorf("tickets = %q, want %q", got, tt.ticket)
+			}
+		})
+	}
+}
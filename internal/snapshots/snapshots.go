@@ -0,0 +1,170 @@
+// Package snapshots enforces the snapshot-test update policy: the agent may
+// update a snapshot only when the snapshot's diff is small and it explains
+// why; otherwise it must fix the code that broke the snapshot test.
+package snapshots
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/scottbott"
+	"github.com/philjestin/boatmanmode/internal/unidiff"
+)
+
+// defaultPatterns match the snapshot files of Jest, Vitest, insta, syrupy
+// and Go golden files.
+var defaultPatterns = []string{"__snapshots__/", "*.snap", "*.ambr", "*.golden"}
+
+// Heading introduces the snapshot justifications in the agent's reply.
+const Heading = "## Snapshot Updates"
+
+// Change is a snapshot file the diff updates.
+type Change struct {
+	File  string
+	Lines int // Lines added plus lines removed
+}
+
+// Policy checks snapshot updates in a diff.
+type Policy struct {
+	maxLines int
+	patterns []string
+}
+
+// New creates a Policy. Returns nil when review.snapshots.max_lines is
+// not set.
+func New(cfg config.SnapshotConfig) *Policy {
+	if cfg.MaxLines <= 0 {
+		return nil
+	}
+	patterns := cfg.Patterns
+	if len(patterns) == 0 {
+		patterns = defaultPatterns
+	}
+	return &Policy{maxLines: cfg.MaxLines, patterns: patterns}
+}
+
+// Guidance tells the executor when it may update snapshots.
+func (p *Policy) Guidance() string {
+	return fmt.Sprintf(`## Snapshot Tests
+
+If your change breaks snapshot tests, fix the code so the snapshots still match. Update a
+snapshot only when its new output is what the task asks for and the snapshot's diff stays
+within %d changed lines. For every snapshot you update, end your reply with:
+
+%s
+- path/to/snapshot: why the new output is correct`, p.maxLines, Heading)
+}
+
+// IsSnapshot reports whether path is a snapshot file.
+func (p *Policy) IsSnapshot(path string) bool {
+	for _, pattern := range p.patterns {
+		if config.MatchPathPattern(pattern, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// Changes returns the snapshot files the diff updates, in diff order.
+func (p *Policy) Changes(diff string) []Change {
+	var changes []Change
+	for _, f := range unidiff.Parse(diff) {
+		if !p.IsSnapshot(f.Path()) {
+			continue
+		}
+		lines := len(f.Added()) + len(f.Removed())
+		if lines > 0 {
+			changes = append(changes, Change{File: f.Path(), Lines: lines})
+		}
+	}
+	return changes
+}
+
+// Issues returns a major issue for each snapshot update over the line
+// limit, and for each one the agent did not justify.
+func (p *Policy) Issues(diff string, reasons map[string]string) []scottbott.Issue {
+	var issues []scottbott.Issue
+	for _, c := range p.Changes(diff) {
+		switch {
+		case c.Lines > p.maxLines:
+			issues = append(issues, scottbott.Issue{
+				Severity:    "major",
+				File:        c.File,
+				Description: fmt.Sprintf("Snapshot update changes %d lines, over the %d-line limit", c.Lines, p.maxLines),
+				Suggestion:  "Revert the snapshot and fix the code that changed its output",
+			})
+		case Reason(reasons, c.File) == "":
+			issues = append(issues, scottbott.Issue{
+				Severity:    "major",
+				File:        c.File,
+				Description: "Snapshot update has no justification",
+				Suggestion:  fmt.Sprintf("Fix the code instead, or explain under %q why the new output is correct", Heading),
+			})
+		}
+	}
+	return issues
+}
+
+// Markdown lists the snapshot updates and their justifications for the
+// PR body.
+func (p *Policy) Markdown(diff string, reasons map[string]string) string {
+	changes := p.Changes(diff)
+	if len(changes) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("### 📸 Snapshot Updates\n")
+	for _, c := range changes {
+		reason := Reason(reasons, c.File)
+		if reason == "" {
+			reason = "_no justification given_"
+		}
+		sb.WriteString(fmt.Sprintf("- `%s` (%d lines): %s\n", c.File, c.Lines, reason))
+	}
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+var justification = regexp.MustCompile("^\\s*[-*]\\s+`?([^`:]+?)`?\\s*:\\s*(.+)$")
+
+// ParseJustifications reads the snapshot justifications from the agent's
+// reply, keyed by snapshot path.
+func ParseJustifications(response string) map[string]string {
+	reasons := map[string]string{}
+	inSection := false
+	for _, line := range strings.Split(response, "\n") {
+		if strings.HasPrefix(line, "#") {
+			inSection = strings.EqualFold(strings.TrimSpace(line), Heading)
+			continue
+		}
+		if !inSection {
+			continue
+		}
+		if m := justification.FindStringSubmatch(line); m != nil {
+			reasons[strings.TrimSpace(m[1])] = strings.TrimSpace(m[2])
+		}
+	}
+	return reasons
+}
+
+// Reason returns the justification for file, matching paths the agent
+// gave relative to a subdirectory too.
+func Reason(reasons map[string]string, file string) string {
+	if reason := reasons[file]; reason != "" {
+		return reason
+	}
+	keys := make([]string, 0, len(reasons))
+	for k := range reasons {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if strings.HasSuffix(file, "/"+strings.TrimPrefix(k, "./")) {
+			return reasons[k]
+		}
+	}
+	return ""
+}
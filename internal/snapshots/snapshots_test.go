@@ -0,0 +1,114 @@
+package snapshots
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+)
+
+const diff = `diff --git a/src/__snapshots__/Button.test.tsx.snap b/src/__snapshots__/Button.test.tsx.snap
+--- a/src/__snapshots__/Button.test.tsx.snap
++++ b/src/__snapshots__/Button.test.tsx.snap
+@@ -1,3 +1,3 @@
+ exports[` + "`Button renders`" + `] = ` + "`" + `
+-<button class="btn">
++<button class="btn btn-primary">
+ ` + "`" + `;
+diff --git a/testdata/report.golden b/testdata/report.golden
+--- a/testdata/report.golden
++++ b/testdata/report.golden
+@@ -1,2 +1,5 @@
+-total: 3
++total: 4
++a
++b
++c
+diff --git a/src/Button.tsx b/src/Button.tsx
+--- a/src/Button.tsx
++++ b/src/Button.tsx
+@@ -1 +1 @@
+-const cls = "btn";
++const cls = "btn btn-primary";
+`
+
+func TestNew(t *testing.T) {
+	if p := New(config.SnapshotConfig{}); p != nil {
+		t.Errorf("New without max_lines = %v, want nil", p)
+	}
+}
+
+func TestChanges(t *testing.T) {
+	p := New(config.SnapshotConfig{MaxLines: 3})
+	changes := p.Changes(diff)
+	if len(changes) != 2 || changes[0].File != "src/__snapshots__/Button.test.tsx.snap" || changes[0].Lines != 2 ||
+		changes[1].File != "testdata/report.golden" || changes[1].Lines != 5 {
+		t.Errorf("changes = %+v", changes)
+	}
+	if p.IsSnapshot("src/Button.tsx") {
+		t.Error("source file treated as a snapshot")
+	}
+
+	p = New(config.SnapshotConfig{MaxLines: 3, Patterns: []string{"*.golden"}})
+	if changes := p.Changes(diff); len(changes) != 1 || changes[0].File != "testdata/report.golden" {
+		t.Errorf("custom pattern changes = %+v", changes)
+	}
+}
+
+func TestIssues(t *testing.T) {
+	p := New(config.SnapshotConfig{MaxLines: 3})
+	issues := p.Issues(diff, nil)
+	if len(issues) != 2 {
+		t.Fatalf("issues = %+v, want unjustified and over limit", issues)
+	}
+	if issues[0].Description != "Snapshot update has no justification" {
+		t.Errorf("first issue = %q", issues[0].Description)
+	}
+	if !strings.Contains(issues[1].Description, "changes 5 lines, over the 3-line limit") {
+		t.Errorf("second issue = %q", issues[1].Description)
+	}
+
+	reasons := map[string]string{"__snapshots__/Button.test.tsx.snap": "The ticket makes the button primary"}
+	if issues := p.Issues(diff, reasons); len(issues) != 1 || issues[0].File != "testdata/report.golden" {
+		t.Errorf("justified issues = %+v, want only the over-limit update", issues)
+	}
+}
+
+func TestParseJustifications(t *testing.T) {
+	response := `Made the button primary.
+
+## Snapshot Updates
+- ` + "`src/__snapshots__/Button.test.tsx.snap`" + `: The ticket makes the button primary
+* testdata/report.golden: One more row is expected
+
+## Summary
+- src/Button.tsx: changed the class`
+
+	reasons := ParseJustifications(response)
+	if len(reasons) != 2 {
+		t.Fatalf("reasons = %v", reasons)
+	}
+	if reasons["src/__snapshots__/Button.test.tsx.snap"] != "The ticket makes the button primary" {
+		t.Errorf("button reason = %q", reasons["src/__snapshots__/Button.test.tsx.snap"])
+	}
+	if reasons["testdata/report.golden"] != "One more row is expected" {
+		t.Errorf("golden reason = %q", reasons["testdata/report.golden"])
+	}
+}
+
+func TestMarkdown(t *testing.T) {
+	p := New(config.SnapshotConfig{MaxLines: 10})
+	md := p.Markdown(diff, map[string]string{"testdata/report.golden": "One more row is expected"})
+	for _, want := range []string{
+		"### 📸 Snapshot Updates",
+		"- `src/__snapshots__/Button.test.tsx.snap` (2 lines): _no justification given_",
+		"- `testdata/report.golden` (5 lines): One more row is expected",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+	if md := p.Markdown("", nil); md != "" {
+		t.Errorf("markdown without snapshots = %q", md)
+	}
+}
@@ -0,0 +1,208 @@
+package specs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// API endpoints; tests point them at local servers.
+var (
+	notionAPI = "https://api.notion.com/v1"
+	googleAPI = "https://www.googleapis.com/drive/v3"
+)
+
+// notionVersion is the Notion API version requests are made against.
+const notionVersion = "2022-06-28"
+
+// maxNotionPages caps the pages of blocks read from a Notion page.
+const maxNotionPages = 10
+
+type notionText []struct {
+	PlainText string `json:"plain_text"`
+}
+
+func (t notionText) String() string {
+	var sb strings.Builder
+	for _, part := range t {
+		sb.WriteString(part.PlainText)
+	}
+	return sb.String()
+}
+
+// notionPrefix is how each block type is written as text.
+var notionPrefix = map[string]string{
+	"heading_1":          "# ",
+	"heading_2":          "## ",
+	"heading_3":          "### ",
+	"bulleted_list_item": "- ",
+	"numbered_list_item": "1. ",
+	"to_do":              "- [ ] ",
+	"quote":              "> ",
+	"callout":            "> ",
+	"paragraph":          "",
+	"toggle":             "",
+	"code":               "",
+}
+
+func (c *Client) fetchNotion(ctx context.Context, link Link) (*Doc, error) {
+	auth := func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.NotionToken)
+		req.Header.Set("Notion-Version", notionVersion)
+	}
+	doc := &Doc{Link: link}
+
+	body, err := c.get(ctx, notionAPI+"/pages/"+link.ID, auth)
+	if err != nil {
+		return nil, err
+	}
+	var page struct {
+		Properties map[string]struct {
+			Type  string     `json:"type"`
+			Title notionText `json:"title"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(body, &page); err != nil {
+		return nil, fmt.Errorf("failed to parse page: %w", err)
+	}
+	for _, prop := range page.Properties {
+		if prop.Type == "title" {
+			doc.Title = prop.Title.String()
+		}
+	}
+
+	var lines []string
+	cursor := ""
+	for i := 0; i < maxNotionPages; i++ {
+		query := url.Values{"page_size": {"100"}}
+		if cursor != "" {
+			query.Set("start_cursor", cursor)
+		}
+		body, err := c.get(ctx, notionAPI+"/blocks/"+link.ID+"/children?"+query.Encode(), auth)
+		if err != nil {
+			return nil, err
+		}
+		var blocks struct {
+			Results    []map[string]json.RawMessage `json:"results"`
+			HasMore    bool                         `json:"has_more"`
+			NextCursor string                       `json:"next_cursor"`
+		}
+		if err := json.Unmarshal(body, &blocks); err != nil {
+			return nil, fmt.Errorf("failed to parse blocks: %w", err)
+		}
+		for _, block := range blocks.Results {
+			var kind string
+			if json.Unmarshal(block["type"], &kind) != nil {
+				continue
+			}
+			prefix, ok := notionPrefix[kind]
+			if !ok {
+				continue
+			}
+			var content struct {
+				RichText notionText `json:"rich_text"`
+			}
+			if json.Unmarshal(block[kind], &content) != nil {
+				continue
+			}
+			lines = append(lines, prefix+content.RichText.String())
+		}
+		if !blocks.HasMore || blocks.NextCursor == "" {
+			break
+		}
+		cursor = blocks.NextCursor
+	}
+	doc.Text = strings.Join(lines, "\n")
+	return doc, nil
+}
+
+func (c *Client) fetchGoogleDoc(ctx context.Context, link Link) (*Doc, error) {
+	auth := func(req *http.Request) {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.GoogleToken)
+	}
+	file := googleAPI + "/files/" + url.PathEscape(link.ID)
+
+	body, err := c.get(ctx, file+"?fields=name", auth)
+	if err != nil {
+		return nil, err
+	}
+	var meta struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(body, &meta); err != nil {
+		return nil, fmt.Errorf("failed to parse file: %w", err)
+	}
+
+	text, err := c.get(ctx, file+"/export?mimeType=text/plain", auth)
+	if err != nil {
+		return nil, err
+	}
+	return &Doc{Link: link, Title: meta.Name, Text: strings.TrimPrefix(string(text), "\ufeff")}, nil
+}
+
+func (c *Client) fetchConfluence(ctx context.Context, link Link) (*Doc, error) {
+	cfg := c.cfg.Confluence
+	auth := func(req *http.Request) {
+		req.Header.Set("Accept", "application/json")
+		if cfg.Email != "" {
+			req.SetBasicAuth(cfg.Email, cfg.Token)
+		} else {
+			req.Header.Set("Authorization", "Bearer "+cfg.Token)
+		}
+	}
+	// Confluence Cloud serves under /wiki; Server and Data Center at the root
+	base := cfg.URL
+	if u, err := url.Parse(link.URL); err == nil && strings.HasPrefix(u.Path, "/wiki/") && !strings.HasSuffix(base, "/wiki") {
+		base += "/wiki"
+	}
+
+	body, err := c.get(ctx, base+"/rest/api/content/"+link.ID+"?expand=body.storage", auth)
+	if err != nil {
+		return nil, err
+	}
+	var page struct {
+		Title string `json:"title"`
+		Body  struct {
+			Storage struct {
+				Value string `json:"value"`
+			} `json:"storage"`
+		} `json:"body"`
+	}
+	if err := json.Unmarshal(body, &page); err != nil {
+		return nil, fmt.Errorf("failed to parse page: %w", err)
+	}
+	return &Doc{Link: link, Title: page.Title, Text: htmlText(page.Body.Storage.Value)}, nil
+}
+
+var (
+	htmlHeading   = regexp.MustCompile(`(?i)<h([1-6])[^>]*>`)
+	htmlListItem  = regexp.MustCompile(`(?i)<li[^>]*>`)
+	htmlBreak     = regexp.MustCompile(`(?i)<br\s*/?>|</(?:p|div|h[1-6]|tr|pre|blockquote)>`)
+	htmlListEnd   = regexp.MustCompile(`(?i)</(?:ul|ol)>`)
+	htmlTag       = regexp.MustCompile(`<[^>]+>`)
+	htmlBlankRuns = regexp.MustCompile(`\n{3,}`)
+)
+
+// htmlText converts a page's storage format (XHTML) to plain text,
+// keeping headings and list items.
+func htmlText(s string) string {
+	s = htmlHeading.ReplaceAllStringFunc(s, func(tag string) string {
+		level := htmlHeading.FindStringSubmatch(tag)[1]
+		return "\n" + strings.Repeat("#", int(level[0]-'0')) + " "
+	})
+	s = htmlListItem.ReplaceAllString(s, "\n- ")
+	s = htmlListEnd.ReplaceAllString(s, "\n\n")
+	s = htmlBreak.ReplaceAllString(s, "\n")
+	s = htmlTag.ReplaceAllString(s, "")
+	s = html.UnescapeString(s)
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.TrimSpace(htmlBlankRuns.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
@@ -0,0 +1,219 @@
+// Package specs fetches the feature specs linked in ticket descriptions
+// (Notion, Google Docs and Confluence pages) as plain text, and summarizes
+// them within a token budget as supplementary context for planning and
+// execution.
+package specs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/handoff"
+	"github.com/philjestin/boatmanmode/internal/retry"
+)
+
+// Kind is the service a spec is hosted on.
+type Kind string
+
+const (
+	Notion     Kind = "Notion"
+	GoogleDocs Kind = "Google Docs"
+	Confluence Kind = "Confluence"
+)
+
+// maxDocs caps the docs fetched for a ticket.
+const maxDocs = 5
+
+// maxDownload caps the size of a fetched doc.
+const maxDownload = 1 << 20
+
+// Link is a spec linked from a ticket.
+type Link struct {
+	Kind Kind
+	ID   string // The page or document ID
+	URL  string
+}
+
+// Doc is a fetched spec.
+type Doc struct {
+	Link
+	Title string
+	Text  string
+}
+
+var (
+	// https://www.notion.so/acme/Export-spec-0123456789abcdef0123456789abcdef
+	notionLink = regexp.MustCompile(`https://(?:[\w-]+\.)?notion\.(?:so|site)/[^\s)\]>"]*?([0-9a-f]{32}|[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})\b`)
+	// https://docs.google.com/document/d/1AbC.../edit
+	googleDocLink = regexp.MustCompile(`https://docs\.google\.com/document/(?:u/\d+/)?d/([\w-]{20,})`)
+	// https://acme.atlassian.net/wiki/spaces/ENG/pages/123456/Export+spec
+	confluenceLink = regexp.MustCompile(`https?://[\w.-]+(?::\d+)?(?:/wiki)?/(?:spaces/[\w~-]+/pages/|pages/viewpage\.action\?pageId=)(\d+)`)
+)
+
+// Links returns the specs linked in text, each once, in the order they
+// appear by service.
+func Links(text string) []Link {
+	var links []Link
+	seen := make(map[string]bool)
+	add := func(kind Kind, id, u string) {
+		key := string(kind) + "/" + id
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		links = append(links, Link{Kind: kind, ID: id, URL: u})
+	}
+	for _, m := range notionLink.FindAllStringSubmatch(text, -1) {
+		add(Notion, strings.ReplaceAll(m[1], "-", ""), m[0])
+	}
+	for _, m := range googleDocLink.FindAllStringSubmatch(text, -1) {
+		add(GoogleDocs, m[1], m[0])
+	}
+	for _, m := range confluenceLink.FindAllStringSubmatch(text, -1) {
+		add(Confluence, m[1], m[0])
+	}
+	return links
+}
+
+// Client fetches specs with the configured credentials.
+type Client struct {
+	cfg        config.SpecsConfig
+	httpClient *http.Client
+}
+
+// New creates a spec client.
+func New(cfg config.SpecsConfig) *Client {
+	cfg.Confluence.URL = strings.TrimSuffix(cfg.Confluence.URL, "/")
+	return &Client{cfg: cfg, httpClient: &http.Client{}}
+}
+
+// CanFetch reports whether the client has credentials for link. Confluence
+// pages must be on the configured site.
+func (c *Client) CanFetch(link Link) bool {
+	switch link.Kind {
+	case Notion:
+		return c.cfg.NotionToken != ""
+	case GoogleDocs:
+		return c.cfg.GoogleToken != ""
+	case Confluence:
+		return c.cfg.Confluence.Token != "" && sameHost(link.URL, c.cfg.Confluence.URL)
+	}
+	return false
+}
+
+// Fetch fetches the spec at link as plain text.
+func (c *Client) Fetch(ctx context.Context, link Link) (*Doc, error) {
+	if !c.CanFetch(link) {
+		return nil, fmt.Errorf("no %s credentials configured for %s", link.Kind, link.URL)
+	}
+	switch link.Kind {
+	case Notion:
+		return c.fetchNotion(ctx, link)
+	case GoogleDocs:
+		return c.fetchGoogleDoc(ctx, link)
+	default:
+		return c.fetchConfluence(ctx, link)
+	}
+}
+
+// FetchAll fetches the specs linked in text that the client has
+// credentials for, up to maxDocs. Links it cannot fetch are reported
+// through warn and skipped.
+func (c *Client) FetchAll(ctx context.Context, text string, warn func(format string, args ...any)) []*Doc {
+	var docs []*Doc
+	for _, link := range Links(text) {
+		if len(docs) == maxDocs {
+			break
+		}
+		if !c.CanFetch(link) {
+			warn("Skipping %s page %s: no credentials configured", link.Kind, link.URL)
+			continue
+		}
+		doc, err := c.Fetch(ctx, link)
+		if err != nil {
+			warn("Could not fetch %s page %s: %v", link.Kind, link.URL, err)
+			continue
+		}
+		if strings.TrimSpace(doc.Text) == "" {
+			continue
+		}
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+// Summarize renders docs for a prompt within maxTokens, sharing the budget
+// between them. Docs over their share are compressed to their key points.
+func Summarize(docs []*Doc, maxTokens int) string {
+	if len(docs) == 0 {
+		return ""
+	}
+	share := maxTokens / len(docs)
+	var sb strings.Builder
+	sb.WriteString("## Linked Specs\n")
+	sb.WriteString("The ticket links these specs; follow them where the ticket leaves details open.\n")
+	for _, doc := range docs {
+		title := doc.Title
+		if title == "" {
+			title = doc.URL
+		}
+		body := handoff.CompressText(strings.TrimSpace(doc.Text), share)
+		sb.WriteString(fmt.Sprintf("\n### %s (%s)\n%s\n\n%s\n", title, doc.Kind, doc.URL, body))
+	}
+	return sb.String()
+}
+
+// get performs an authenticated GET request with retry logic.
+func (c *Client) get(ctx context.Context, rawURL string, auth func(*http.Request)) ([]byte, error) {
+	var result []byte
+
+	err := retry.Do(ctx, retry.APIConfig(), "spec request", func() error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+		if err != nil {
+			return retry.Permanent(fmt.Errorf("failed to create request: %w", err))
+		}
+		auth(req)
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("request failed: %w", err) // Retryable
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(io.LimitReader(resp.Body, maxDownload))
+		if err != nil {
+			return fmt.Errorf("failed to read response: %w", err)
+		}
+
+		// 429 is retryable; other 4xx errors are permanent (client errors)
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+			return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		}
+		if resp.StatusCode >= 400 {
+			return retry.Permanent(fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body))))
+		}
+
+		result = body
+		return nil
+	})
+
+	return result, err
+}
+
+func sameHost(a, b string) bool {
+	ua, err := url.Parse(a)
+	if err != nil {
+		return false
+	}
+	ub, err := url.Parse(b)
+	if err != nil {
+		return false
+	}
+	return ua.Host != "" && strings.EqualFold(ua.Host, ub.Host)
+}
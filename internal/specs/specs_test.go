@@ -0,0 +1,132 @@
+package specs
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/handoff"
+)
+
+func TestLinks(t *testing.T) {
+	text := `Spec: https://www.notion.so/acme/CSV-export-0123456789abcdef0123456789abcdef?pvs=4
+Design: https://docs.google.com/document/d/1AbCdEfGhIjKlMnOpQrStUvWxYz0123456789/edit#heading=h.x
+Old notes: https://acme.atlassian.net/wiki/spaces/ENG/pages/123456/Export+notes
+Again: https://acme.notion.site/01234567-89ab-cdef-0123-456789abcdef
+Not a spec: https://github.com/acme/app/pull/12`
+
+	got := Links(text)
+	want := []Link{
+		{Kind: Notion, ID: "0123456789abcdef0123456789abcdef"},
+		{Kind: GoogleDocs, ID: "1AbCdEfGhIjKlMnOpQrStUvWxYz0123456789"},
+		{Kind: Confluence, ID: "123456"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Links = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i].Kind != want[i].Kind || got[i].ID != want[i].ID {
+			t.Errorf("Links[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestFetchNotion(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" || r.Header.Get("Notion-Version") == "" {
+			t.Errorf("headers = %v", r.Header)
+		}
+		switch r.URL.Path {
+		case "/pages/abc":
+			w.Write([]byte(`{"properties": {"Name": {"type": "title", "title": [{"plain_text": "CSV export"}]}}}`))
+		case "/blocks/abc/children":
+			if r.URL.Query().Get("start_cursor") == "" {
+				w.Write([]byte(`{"results": [
+					{"type": "heading_2", "heading_2": {"rich_text": [{"plain_text": "Scope"}]}},
+					{"type": "bulleted_list_item", "bulleted_list_item": {"rich_text": [{"plain_text": "Export "}, {"plain_text": "invoices"}]}},
+					{"type": "image", "image": {}}],
+					"has_more": true, "next_cursor": "c2"}`))
+				return
+			}
+			w.Write([]byte(`{"results": [{"type": "paragraph", "paragraph": {"rich_text": [{"plain_text": "Columns match the UI."}]}}], "has_more": false}`))
+		default:
+			t.Errorf("path = %s", r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+	defer func(orig string) { notionAPI = orig }(notionAPI)
+	notionAPI = srv.URL
+
+	c := New(config.SpecsConfig{NotionToken: "secret"})
+	doc, err := c.Fetch(context.Background(), Link{Kind: Notion, ID: "abc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.Title != "CSV export" || doc.Text != "## Scope\n- Export invoices\nColumns match the UI." {
+		t.Errorf("doc = %q: %q", doc.Title, doc.Text)
+	}
+}
+
+func TestFetchConfluence(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/wiki/rest/api/content/123" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		if user, pass, ok := r.BasicAuth(); !ok || user != "jo@acme.com" || pass != "token" {
+			t.Errorf("basic auth = %q, %q, %v", user, pass, ok)
+		}
+		w.Write([]byte(`{"title": "Export notes", "body": {"storage": {"value":
+			"<h2>Rules</h2><ul><li>Dates in UTC</li><li>Amounts &amp; totals</li></ul><p>Ask <strong>billing</strong>.</p>"}}}`))
+	}))
+	defer srv.Close()
+
+	c := New(config.SpecsConfig{Confluence: config.ConfluenceConfig{URL: srv.URL + "/", Email: "jo@acme.com", Token: "token"}})
+	link := Link{Kind: Confluence, ID: "123", URL: srv.URL + "/wiki/spaces/ENG/pages/123/Export+notes"}
+	doc, err := c.Fetch(context.Background(), link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "## Rules\n\n- Dates in UTC\n- Amounts & totals\n\nAsk billing."; doc.Title != "Export notes" || doc.Text != want {
+		t.Errorf("doc = %q: %q, want %q", doc.Title, doc.Text, want)
+	}
+
+	// Pages on other sites never get the credentials
+	if c.CanFetch(Link{Kind: Confluence, ID: "9", URL: "https://evil.example.com/wiki/spaces/X/pages/9"}) {
+		t.Error("CanFetch accepted a page on another site")
+	}
+}
+
+func TestFetchAllSkipsUnconfigured(t *testing.T) {
+	var warnings []string
+	c := New(config.SpecsConfig{})
+	docs := c.FetchAll(context.Background(), "See https://docs.google.com/document/d/1AbCdEfGhIjKlMnOpQrStUvWxYz/edit", func(format string, args ...any) {
+		warnings = append(warnings, format)
+	})
+	if len(docs) != 0 || len(warnings) != 1 {
+		t.Errorf("docs = %v, warnings = %v", docs, warnings)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	long := strings.Repeat("Background paragraph that explains the history of exports.\n\n", 200) +
+		"- Export must include tax\n- Dates are UTC\n"
+	docs := []*Doc{
+		{Link: Link{Kind: Notion, URL: "https://notion.so/a"}, Title: "Short", Text: "- Keep the header row"},
+		{Link: Link{Kind: GoogleDocs, URL: "https://docs.google.com/document/d/b"}, Title: "Long", Text: long},
+	}
+	out := Summarize(docs, 1000)
+	if tokens := handoff.EstimateTokens(out); tokens > 1100 {
+		t.Errorf("summary is %d tokens, want about 1000", tokens)
+	}
+	for _, want := range []string{"### Short (Notion)", "- Keep the header row", "### Long (Google Docs)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("summary missing %q:\n%s", want, out)
+		}
+	}
+	if Summarize(nil, 1000) != "" {
+		t.Error("Summarize(nil) is not empty")
+	}
+}
@@ -0,0 +1,342 @@
+// Package sqlreview reviews the SQL queries a diff adds or changes: it plans
+// them with EXPLAIN against a dev database to find full table scans, and
+// runs the repo's static SQL analyzer. Findings become review issues.
+package sqlreview
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/scottbott"
+	"github.com/philjestin/boatmanmode/internal/unidiff"
+)
+
+// DefaultMinRows is the estimated row count from which a full table scan
+// is reported.
+const DefaultMinRows = 1000
+
+// Query is a SQL query found in a diff's added lines.
+type Query struct {
+	File string
+	Line int
+	SQL  string
+}
+
+// Checker reviews the SQL in a worktree's changes.
+type Checker struct {
+	worktreePath string
+	cfg          config.SQLReviewConfig
+	psql         string
+}
+
+// New creates a Checker. Returns nil when neither a database nor an
+// analyzer is configured.
+func New(worktreePath string, cfg config.SQLReviewConfig) *Checker {
+	if cfg.DatabaseURL == "" && cfg.Analyzer == "" {
+		return nil
+	}
+	if cfg.MinRows <= 0 {
+		cfg.MinRows = DefaultMinRows
+	}
+	if cfg.Severity == "" {
+		cfg.Severity = "major"
+	}
+	return &Checker{worktreePath: worktreePath, cfg: cfg, psql: "psql"}
+}
+
+// Check explains the diff's queries and runs the analyzer, returning the
+// issues found. Errors mean a tool could not run at all; the issues the
+// other tool found are still returned.
+func (c *Checker) Check(ctx context.Context, diff string) ([]scottbott.Issue, error) {
+	if !TouchesSQL(diff) {
+		return nil, nil
+	}
+	var issues []scottbott.Issue
+	var errs []string
+	if c.cfg.DatabaseURL != "" {
+		found, err := c.Explain(ctx, Extract(diff))
+		issues = append(issues, found...)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if c.cfg.Analyzer != "" {
+		found, err := c.Analyze(ctx)
+		issues = append(issues, found...)
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return issues, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return issues, nil
+}
+
+// Explain plans each query against the dev database and returns an issue
+// per large full table scan. Each is planned in a read-only transaction;
+// queries with more than one statement, and ones the database cannot
+// plan, such as ones built from interpolated fragments, are skipped.
+func (c *Checker) Explain(ctx context.Context, queries []Query) ([]scottbott.Issue, error) {
+	if len(queries) == 0 {
+		return nil, nil
+	}
+	if _, err := exec.LookPath(c.psql); err != nil {
+		return nil, fmt.Errorf("EXPLAIN needs psql: %w", err)
+	}
+	url := os.ExpandEnv(c.cfg.DatabaseURL)
+
+	var issues []scottbott.Issue
+	for _, q := range queries {
+		sql, generic := parameterize(strings.TrimSuffix(strings.TrimSpace(q.SQL), ";"))
+		if strings.Contains(sql, ";") {
+			continue
+		}
+		options := "FORMAT JSON"
+		if generic {
+			options += ", GENERIC_PLAN"
+		}
+		cmd := exec.CommandContext(ctx, c.psql, url, "-X", "-q", "-A", "-t", "-v", "ON_ERROR_STOP=1", "--single-transaction",
+			"-c", "SET TRANSACTION READ ONLY", "-c", fmt.Sprintf("EXPLAIN (%s) %s", options, sql))
+		out, err := cmd.Output()
+		if ctx.Err() != nil {
+			return issues, ctx.Err()
+		}
+		if err != nil {
+			continue
+		}
+		for _, scan := range ParsePlan(out, c.cfg.MinRows) {
+			description := fmt.Sprintf("Query does a full scan of `%s` (~%d rows)", scan.Relation, scan.Rows)
+			if scan.Filter != "" {
+				description += fmt.Sprintf(" filtering on `%s`", scan.Filter)
+			}
+			issues = append(issues, scottbott.Issue{
+				Severity:    c.cfg.Severity,
+				File:        q.File,
+				Line:        q.Line,
+				Description: description,
+				Suggestion:  fmt.Sprintf("Add an index on `%s` covering the filtered columns, or narrow the query", scan.Relation),
+			})
+		}
+	}
+	return issues, nil
+}
+
+// Scan is a sequential scan found in a query plan.
+type Scan struct {
+	Relation string
+	Filter   string
+	Rows     int
+}
+
+// planNode is the part of a Postgres JSON plan node the review needs.
+type planNode struct {
+	NodeType string     `json:"Node Type"`
+	Relation string     `json:"Relation Name"`
+	Filter   string     `json:"Filter"`
+	Rows     float64    `json:"Plan Rows"`
+	Plans    []planNode `json:"Plans"`
+}
+
+// ParsePlan returns the sequential scans estimated at minRows or more rows
+// in psql's EXPLAIN (FORMAT JSON) output.
+func ParsePlan(output []byte, minRows int) []Scan {
+	var plans []struct {
+		Plan planNode `json:"Plan"`
+	}
+	if err := json.Unmarshal(bytes.TrimSpace(output), &plans); err != nil {
+		return nil
+	}
+	var scans []Scan
+	var walk func(n planNode)
+	walk = func(n planNode) {
+		if n.NodeType == "Seq Scan" && int(n.Rows) >= minRows {
+			scans = append(scans, Scan{Relation: n.Relation, Filter: n.Filter, Rows: int(n.Rows)})
+		}
+		for _, child := range n.Plans {
+			walk(child)
+		}
+	}
+	for _, p := range plans {
+		walk(p.Plan)
+	}
+	return scans
+}
+
+var placeholder = regexp.MustCompile(`\$\d+|\?`)
+
+// parameterize rewrites ? placeholders as $n, reporting whether the query
+// has parameters and so needs a generic plan.
+func parameterize(sql string) (string, bool) {
+	n := 0
+	generic := false
+	sql = placeholder.ReplaceAllStringFunc(sql, func(m string) string {
+		generic = true
+		if m != "?" {
+			return m
+		}
+		n++
+		return "$" + strconv.Itoa(n)
+	})
+	return sql, generic
+}
+
+// analyzerLine matches "path:line[:col]: message" analyzer output.
+var analyzerLine = regexp.MustCompile(`^([^\s:]+):(\d+)(?::\d+)?:\s*(.+)$`)
+
+// Analyze runs the static analyzer in the worktree. Each "file:line:
+// message" line it prints becomes an issue; a failing run with no such
+// lines becomes a single issue with its output.
+func (c *Checker) Analyze(ctx context.Context) ([]scottbott.Issue, error) {
+	cmd := exec.CommandContext(ctx, "sh", "-c", c.cfg.Analyzer)
+	cmd.Dir = c.worktreePath
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	runErr := cmd.Run()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if _, ok := runErr.(*exec.ExitError); runErr != nil && !ok {
+		return nil, fmt.Errorf("%s: %w", c.cfg.Analyzer, runErr)
+	}
+
+	var issues []scottbott.Issue
+	for _, l := range strings.Split(out.String(), "\n") {
+		m := analyzerLine.FindStringSubmatch(strings.TrimSpace(l))
+		if m == nil {
+			continue
+		}
+		line, _ := strconv.Atoi(m[2])
+		issues = append(issues, scottbott.Issue{
+			Severity:    c.cfg.Severity,
+			File:        m[1],
+			Line:        line,
+			Description: m[3],
+			Suggestion:  fmt.Sprintf("Fix the query so `%s` passes", c.cfg.Analyzer),
+		})
+	}
+	if runErr != nil && len(issues) == 0 {
+		if status := runErr.(*exec.ExitError).ExitCode(); status == 127 {
+			return nil, fmt.Errorf("%s: command not found", c.cfg.Analyzer)
+		}
+		issues = append(issues, scottbott.Issue{
+			Severity:    c.cfg.Severity,
+			Description: fmt.Sprintf("`%s` failed: %s", c.cfg.Analyzer, truncate(strings.TrimSpace(out.String()), 500)),
+			Suggestion:  fmt.Sprintf("Fix the query so `%s` passes", c.cfg.Analyzer),
+		})
+	}
+	return issues, nil
+}
+
+var (
+	// sqlStart matches the start of a query worth planning.
+	sqlStart = regexp.MustCompile(`(?is)^\s*(SELECT\s+\S.*\sFROM\s+\w|WITH\s+\w+\s+AS\s*\(|UPDATE\s+\S+\s+SET\s|DELETE\s+FROM\s+\w)`)
+	// literal matches single-line string literals in code.
+	literal = regexp.MustCompile("\"((?:[^\"\\\\]|\\\\.)*)\"|'((?:[^'\\\\]|\\\\.)*)'|`([^`]*)`")
+	// ormCall matches added ORM and query-builder calls the analyzer should see.
+	ormCall = regexp.MustCompile(`\.(where|find_by|joins|includes|order|group|having|Where|Joins|Raw|Query|QueryRow|Exec|QueryContext|ExecContext|filter|select_related|annotate)\(`)
+)
+
+// TouchesSQL reports whether the diff changes SQL files, adds queries, or
+// adds ORM query calls.
+func TouchesSQL(diff string) bool {
+	for _, f := range unidiff.Parse(diff) {
+		if f.NewPath == "" {
+			continue
+		}
+		if strings.HasSuffix(f.Path(), ".sql") && len(f.Added()) > 0 {
+			return true
+		}
+		for _, line := range f.Added() {
+			if ormCall.MatchString(line) {
+				return true
+			}
+		}
+	}
+	return len(Extract(diff)) > 0
+}
+
+// Extract returns the queries in the diff's added lines: statements in
+// .sql files, and SQL string literals in code.
+func Extract(diff string) []Query {
+	var queries []Query
+	for _, f := range unidiff.Parse(diff) {
+		if f.NewPath == "" {
+			continue
+		}
+		path := f.Path()
+		if strings.HasSuffix(path, ".sql") {
+			queries = append(queries, sqlStatements(path, f.Lines())...)
+			continue
+		}
+		for _, l := range f.Lines() {
+			if l.Kind != unidiff.LineAdded {
+				continue
+			}
+			for _, m := range literal.FindAllStringSubmatch(l.Content, -1) {
+				s := m[1] + m[2] + m[3]
+				if sqlStart.MatchString(s) {
+					queries = append(queries, Query{File: path, Line: l.NewLine, SQL: strings.TrimSpace(s)})
+				}
+			}
+		}
+	}
+	return queries
+}
+
+// sqlStatements splits a .sql file's added lines into statements, keeping
+// those worth planning.
+func sqlStatements(path string, lines []unidiff.Line) []Query {
+	var queries []Query
+	var stmt strings.Builder
+	start := 0
+	flush := func() {
+		s := strings.TrimSpace(stmt.String())
+		if sqlStart.MatchString(s) {
+			queries = append(queries, Query{File: path, Line: start, SQL: s})
+		}
+		stmt.Reset()
+		start = 0
+	}
+	for _, l := range lines {
+		if l.Kind != unidiff.LineAdded {
+			flush()
+			continue
+		}
+		content := l.Content
+		if i := strings.Index(content, "--"); i >= 0 {
+			content = content[:i]
+		}
+		for {
+			if start == 0 && strings.TrimSpace(content) != "" {
+				start = l.NewLine
+			}
+			i := strings.Index(content, ";")
+			if i < 0 {
+				stmt.WriteString(content + "\n")
+				break
+			}
+			stmt.WriteString(content[:i])
+			flush()
+			content = content[i+1:]
+		}
+	}
+	flush()
+	return queries
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max-3] + "..."
+}
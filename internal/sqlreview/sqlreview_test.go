@@ -0,0 +1,190 @@
+package sqlreview
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+)
+
+const queryDiff = `diff --git a/db/queries.sql b/db/queries.sql
+--- a/db/queries.sql
++++ b/db/queries.sql
+@@ -1,2 +1,7 @@
+ -- name: GetUser :one
+ SELECT * FROM users WHERE id = $1;
++-- name: ListOrders :many
++SELECT * FROM orders
++WHERE customer_id = $1 ORDER BY created_at;
++CREATE INDEX orders_created_at ON orders (created_at);
+diff --git a/app/store.go b/app/store.go
+--- a/app/store.go
++++ b/app/store.go
+@@ -10,2 +10,4 @@ func (s *Store) Active() {
+ 	ctx := context.Background()
++	rows, err := s.db.QueryContext(ctx, "SELECT id FROM accounts WHERE status = ?", "active")
++	log.Printf("select from the menu")
+`
+
+func TestExtract(t *testing.T) {
+	queries := Extract(queryDiff)
+	if len(queries) != 2 {
+		t.Fatalf("queries = %+v, want 2", queries)
+	}
+	if q := queries[0]; q.File != "db/queries.sql" || q.Line != 4 || q.SQL != "SELECT * FROM orders\nWHERE customer_id = $1 ORDER BY created_at" {
+		t.Errorf("sql query = %+v", q)
+	}
+	if q := queries[1]; q.File != "app/store.go" || q.Line != 11 || q.SQL != "SELECT id FROM accounts WHERE status = ?" {
+		t.Errorf("code query = %+v", q)
+	}
+}
+
+func TestTouchesSQL(t *testing.T) {
+	if !TouchesSQL(queryDiff) {
+		t.Error("query diff should touch SQL")
+	}
+	orm := `--- a/app/models/user.rb
++++ b/app/models/user.rb
+@@ -1,1 +1,2 @@
+ class User
++  scope :recent, -> { where(created_at: 1.day.ago..).order(:created_at) }
+`
+	if !TouchesSQL(orm) {
+		t.Error("ORM calls should touch SQL")
+	}
+	plain := `--- a/README.md
++++ b/README.md
+@@ -1,1 +1,2 @@
+ # App
++Select the right option from the menu.
+`
+	if TouchesSQL(plain) {
+		t.Error("prose should not touch SQL")
+	}
+}
+
+func TestParameterize(t *testing.T) {
+	sql, generic := parameterize("SELECT 1 FROM t WHERE a = ? AND b = ?")
+	if sql != "SELECT 1 FROM t WHERE a = $1 AND b = $2" || !generic {
+		t.Errorf("parameterize = %q, %v", sql, generic)
+	}
+	if _, generic := parameterize("SELECT 1 FROM t"); generic {
+		t.Error("query without parameters needs no generic plan")
+	}
+}
+
+const plan = `[{"Plan": {"Node Type": "Sort", "Plan Rows": 5000, "Plans": [
+  {"Node Type": "Seq Scan", "Relation Name": "orders", "Filter": "(customer_id = $1)", "Plan Rows": 5000},
+  {"Node Type": "Seq Scan", "Relation Name": "regions", "Plan Rows": 12},
+  {"Node Type": "Index Scan", "Relation Name": "users", "Plan Rows": 1}
+]}}]`
+
+func TestParsePlan(t *testing.T) {
+	scans := ParsePlan([]byte(plan), 1000)
+	if len(scans) != 1 {
+		t.Fatalf("scans = %+v, want only the large orders scan", scans)
+	}
+	if s := scans[0]; s.Relation != "orders" || s.Rows != 5000 || s.Filter != "(customer_id = $1)" {
+		t.Errorf("scan = %+v", s)
+	}
+	if scans := ParsePlan([]byte("ERROR: syntax error"), 1000); scans != nil {
+		t.Errorf("invalid output: scans = %+v", scans)
+	}
+}
+
+// fakePsql installs a psql that prints output and records its arguments.
+func fakePsql(t *testing.T, output string) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	args := filepath.Join(dir, "args")
+	script := "#!/bin/sh\nprintf '%s\\n' \"$@\" >> " + args + "\ncat <<'EOF'\n" + output + "\nEOF\n"
+	path := filepath.Join(dir, "psql")
+	if err := os.WriteFile(path, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path, args
+}
+
+func TestExplain(t *testing.T) {
+	psql, argsFile := fakePsql(t, plan)
+	t.Setenv("DEV_DB", "postgres://localhost/dev")
+	c := New(t.TempDir(), config.SQLReviewConfig{DatabaseURL: "$DEV_DB"})
+	c.psql = psql
+
+	issues, err := c.Explain(context.Background(), Extract(queryDiff)[:1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 {
+		t.Fatalf("issues = %+v", issues)
+	}
+	issue := issues[0]
+	if issue.Severity != "major" || issue.File != "db/queries.sql" || issue.Line != 4 {
+		t.Errorf("issue = %+v", issue)
+	}
+	if !strings.Contains(issue.Description, "full scan of `orders` (~5000 rows)") || !strings.Contains(issue.Description, "customer_id") {
+		t.Errorf("description = %q", issue.Description)
+	}
+
+	args, _ := os.ReadFile(argsFile)
+	if !strings.Contains(string(args), "postgres://localhost/dev") || !strings.Contains(string(args), "EXPLAIN (FORMAT JSON, GENERIC_PLAN) SELECT * FROM orders") {
+		t.Errorf("psql args = %s", args)
+	}
+	if strings.Contains(string(args), "ANALYZE") {
+		t.Error("queries must be planned, never run")
+	}
+	if !strings.Contains(string(args), "--single-transaction\n-c\nSET TRANSACTION READ ONLY\n") {
+		t.Errorf("queries must be planned read-only, psql args = %s", args)
+	}
+
+	os.Remove(argsFile)
+	injected := []Query{{File: "db/queries.sql", Line: 1, SQL: "SELECT 1; DROP TABLE orders"}}
+	if issues, err := c.Explain(context.Background(), injected); err != nil || len(issues) != 0 {
+		t.Errorf("Explain(multiple statements) = %+v, %v", issues, err)
+	}
+	if _, err := os.Stat(argsFile); err == nil {
+		t.Error("a query with more than one statement was sent to psql")
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	c := New(t.TempDir(), config.SQLReviewConfig{
+		Analyzer: `echo "db/queries.sql:7:1: query ListOrders: missing LIMIT"; echo "2 problems"; exit 1`,
+		Severity: "minor",
+	})
+	issues, err := c.Analyze(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 1 {
+		t.Fatalf("issues = %+v", issues)
+	}
+	if i := issues[0]; i.File != "db/queries.sql" || i.Line != 7 || i.Description != "query ListOrders: missing LIMIT" || i.Severity != "minor" {
+		t.Errorf("issue = %+v", i)
+	}
+
+	c.cfg.Analyzer = "echo 'config error: no queries'; exit 2"
+	issues, err = c.Analyze(context.Background())
+	if err != nil || len(issues) != 1 || !strings.Contains(issues[0].Description, "config error") {
+		t.Errorf("failing run = %+v, %v", issues, err)
+	}
+
+	c.cfg.Analyzer = "true"
+	if issues, err := c.Analyze(context.Background()); err != nil || len(issues) != 0 {
+		t.Errorf("passing run = %+v, %v", issues, err)
+	}
+
+	c.cfg.Analyzer = "definitely-not-a-sql-linter"
+	if _, err := c.Analyze(context.Background()); err == nil {
+		t.Error("missing analyzer should be an error")
+	}
+}
+
+func TestNewDisabled(t *testing.T) {
+	if c := New(t.TempDir(), config.SQLReviewConfig{}); c != nil {
+		t.Errorf("New without database or analyzer = %+v, want nil", c)
+	}
+}
@@ -0,0 +1,193 @@
+// Package stacktrace finds the source locations in stack traces and log
+// snippets, as pasted into or attached to bug tickets, and maps them to
+// the files of a repository. It understands Go, Python, Ruby, JavaScript
+// and JVM traces, and any "path/file.ext:line" reference, and never fails:
+// text it cannot make sense of is skipped.
+package stacktrace
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxLocations caps the locations Resolve returns; the top of a trace is
+// where the problem usually is.
+const maxLocations = 10
+
+// Frame is a source location referenced by a trace, as written there.
+type Frame struct {
+	Path     string
+	Line     int
+	Function string // Empty when the trace doesn't name it
+}
+
+// Location is a frame mapped to a file in the repository.
+type Location struct {
+	File     string
+	Line     int
+	Function string
+}
+
+// String formats the location as file:line, with its function if known.
+func (l Location) String() string {
+	if l.Function != "" {
+		return fmt.Sprintf("%s:%d (in %s)", l.File, l.Line, l.Function)
+	}
+	return fmt.Sprintf("%s:%d", l.File, l.Line)
+}
+
+var (
+	// File "app/models.py", line 12, in save
+	pythonFrame = regexp.MustCompile(`File "([^"]+)", line (\d+)(?:, in (\S+))?`)
+	// at com.acme.billing.Invoice.total(Invoice.java:42)
+	jvmFrame = regexp.MustCompile(`at ([\w$.<>]+)\(([\w$]+\.(?:java|kt|scala|groovy)):(\d+)\)`)
+	// at render (/app/src/view.js:10:5)
+	jsFrame = regexp.MustCompile(`at ([\w$.<>\[\] ]+?) \((?:file://)?([^()\s]+?):(\d+)(?::\d+)?\)`)
+	// /app/models/user.rb:42:in 'save'
+	rubyFrame = regexp.MustCompile("([^\\s:'\"(]+\\.rb):(\\d+):in [`']([^']+)'")
+	// Any path/file.ext:line reference, e.g. Go traces and compiler errors
+	anyFrame = regexp.MustCompile(`((?:[\w.@~-]+[/\\])*[\w.@-]+\.[A-Za-z][A-Za-z0-9]{0,5}):(\d+)`)
+)
+
+// Parse returns the frames referenced in text, in the order they appear,
+// each path and line once.
+func Parse(text string) []Frame {
+	var frames []Frame
+	seen := make(map[string]bool)
+	add := func(path, line, function string) {
+		n, err := strconv.Atoi(line)
+		if err != nil || n <= 0 {
+			return
+		}
+		key := path + ":" + line
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		frames = append(frames, Frame{Path: path, Line: n, Function: function})
+	}
+
+	for _, line := range strings.Split(text, "\n") {
+		for _, m := range pythonFrame.FindAllStringSubmatch(line, -1) {
+			add(m[1], m[2], m[3])
+		}
+		for _, m := range jvmFrame.FindAllStringSubmatch(line, -1) {
+			add(jvmPath(m[1], m[2]), m[3], m[1])
+		}
+		for _, m := range jsFrame.FindAllStringSubmatch(line, -1) {
+			add(m[2], m[3], m[1])
+		}
+		for _, m := range rubyFrame.FindAllStringSubmatch(line, -1) {
+			add(m[1], m[2], m[3])
+		}
+		for _, m := range anyFrame.FindAllStringSubmatch(line, -1) {
+			if strings.Contains(line, "://"+m[1]) {
+				// A URL's host and port, not a file
+				continue
+			}
+			add(m[1], m[2], "")
+		}
+	}
+	return frames
+}
+
+// jvmPath turns a JVM frame's method and file into a source path, e.g.
+// com.acme.Invoice.total and Invoice.java into com/acme/Invoice.java.
+func jvmPath(method, file string) string {
+	parts := strings.Split(method, ".")
+	if len(parts) <= 2 {
+		return file
+	}
+	return strings.Join(parts[:len(parts)-2], "/") + "/" + file
+}
+
+// Resolve maps frames to files, the repository's tracked paths. A frame
+// matches the file its path ends with (traces usually have absolute
+// paths from another machine), or the one file ending with its path
+// (JVM traces name only the package). Frames in dependencies, or that
+// match no file or several, are dropped.
+func Resolve(frames []Frame, files []string) []Location {
+	var locations []Location
+	seen := make(map[string]bool)
+	for _, f := range frames {
+		path := normalize(f.Path)
+		if path == "" || isDependency(path) {
+			continue
+		}
+		file := match(path, files)
+		if file == "" || isDependency(file) {
+			continue
+		}
+		key := fmt.Sprintf("%s:%d", file, f.Line)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		locations = append(locations, Location{File: file, Line: f.Line, Function: f.Function})
+		if len(locations) == maxLocations {
+			break
+		}
+	}
+	return locations
+}
+
+// Files returns the distinct files of locations, in order.
+func Files(locations []Location) []string {
+	var files []string
+	seen := make(map[string]bool)
+	for _, l := range locations {
+		if !seen[l.File] {
+			seen[l.File] = true
+			files = append(files, l.File)
+		}
+	}
+	return files
+}
+
+func match(path string, files []string) string {
+	var longest string
+	var within []string
+	for _, file := range files {
+		file = strings.TrimSpace(file)
+		if file == "" {
+			continue
+		}
+		switch {
+		case path == file || strings.HasSuffix(path, "/"+file):
+			if len(file) > len(longest) {
+				longest = file
+			}
+		case strings.HasSuffix(file, "/"+path):
+			within = append(within, file)
+		}
+	}
+	if longest != "" {
+		return longest
+	}
+	if len(within) == 1 {
+		return within[0]
+	}
+	return ""
+}
+
+func normalize(path string) string {
+	path = strings.ReplaceAll(path, `\`, "/")
+	for _, prefix := range []string{"file://", "webpack:///", "webpack://"} {
+		path = strings.TrimPrefix(path, prefix)
+	}
+	return strings.TrimPrefix(path, "./")
+}
+
+// isDependency reports whether path is in a dependency rather than the
+// project's own code.
+func isDependency(path string) bool {
+	path = "/" + path
+	for _, dir := range []string{"/node_modules/", "/vendor/", "/site-packages/", "/dist-packages/", "/go/pkg/mod/", "/gems/"} {
+		if strings.Contains(path, dir) {
+			return true
+		}
+	}
+	return false
+}
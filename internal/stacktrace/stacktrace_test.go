@@ -0,0 +1,93 @@
+package stacktrace
+
+import (
+	"reflect"
+	"testing"
+)
+
+var repoFiles = []string{
+	"app/models/user.rb",
+	"billing/views.py",
+	"internal/export/csv.go",
+	"src/main/java/com/acme/billing/Invoice.java",
+	"web/src/view.js",
+	"web/node_modules/react/index.js",
+	"docs/util.go",
+	"lib/util.go",
+}
+
+func TestParseAndResolve(t *testing.T) {
+	tests := []struct {
+		name  string
+		trace string
+		want  []Location
+	}{
+		{
+			name: "go panic",
+			trace: `panic: runtime error: index out of range [3] with length 3
+
+goroutine 1 [running]:
+github.com/acme/app/internal/export.writeRow(...)
+	/home/ci/build/internal/export/csv.go:88 +0x1d
+runtime.main()
+	/usr/local/go/src/runtime/proc.go:250 +0x207`,
+			want: []Location{{File: "internal/export/csv.go", Line: 88}},
+		},
+		{
+			name: "python",
+			trace: `Traceback (most recent call last):
+  File "/srv/app/billing/views.py", line 42, in create_invoice
+    total = compute(items)
+  File "/usr/lib/python3.11/site-packages/django/core.py", line 7, in compute`,
+			want: []Location{{File: "billing/views.py", Line: 42, Function: "create_invoice"}},
+		},
+		{
+			name: "jvm",
+			trace: `java.lang.NullPointerException
+	at com.acme.billing.Invoice.total(Invoice.java:57)
+	at java.base/java.lang.Thread.run(Thread.java:833)`,
+			want: []Location{{File: "src/main/java/com/acme/billing/Invoice.java", Line: 57, Function: "com.acme.billing.Invoice.total"}},
+		},
+		{
+			name: "javascript",
+			trace: `TypeError: Cannot read properties of undefined (reading 'id')
+    at render (/app/web/src/view.js:10:5)
+    at Object.run (/app/web/node_modules/react/index.js:99:1)`,
+			want: []Location{{File: "web/src/view.js", Line: 10, Function: "render"}},
+		},
+		{
+			name:  "ruby",
+			trace: "/var/www/app/models/user.rb:12:in `save'",
+			want:  []Location{{File: "app/models/user.rb", Line: 12, Function: "save"}},
+		},
+		{
+			name:  "urls and ambiguous names are skipped",
+			trace: "GET https://api.example.com:443/v1 failed\nat util.go:3",
+			want:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Resolve(Parse(tt.trace), repoFiles)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFiles(t *testing.T) {
+	locations := []Location{{File: "a.go", Line: 1}, {File: "b.go", Line: 2}, {File: "a.go", Line: 9}}
+	if got := Files(locations); !reflect.DeepEqual(got, []string{"a.go", "b.go"}) {
+		t.Errorf("Files = %v", got)
+	}
+}
+
+func TestLocationString(t *testing.T) {
+	if got := (Location{File: "a.py", Line: 3, Function: "f"}).String(); got != "a.py:3 (in f)" {
+		t.Errorf("String = %q", got)
+	}
+	if got := (Location{File: "a.go", Line: 3}).String(); got != "a.go:3" {
+		t.Errorf("String = %q", got)
+	}
+}
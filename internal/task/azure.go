@@ -0,0 +1,66 @@
+package task
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/azure"
+)
+
+// AzureTask wraps an Azure Boards work item to implement the Task interface.
+type AzureTask struct {
+	item *azure.WorkItem
+}
+
+// NewAzureTask creates a Task from an Azure Boards work item.
+func NewAzureTask(item *azure.WorkItem) Task {
+	return &AzureTask{item: item}
+}
+
+// GetID returns the work item ID (e.g., "123").
+func (t *AzureTask) GetID() string {
+	return strconv.Itoa(t.item.ID)
+}
+
+// GetTitle returns the work item title.
+func (t *AzureTask) GetTitle() string {
+	return t.item.Title
+}
+
+// GetDescription returns the work item description as plain text.
+func (t *AzureTask) GetDescription() string {
+	return t.item.Description
+}
+
+// GetBranchName generates a branch name from the branch scheme; work
+// items have no branch name of their own.
+func (t *AzureTask) GetBranchName() string {
+	return branchScheme.Render(t.GetID(), t.item.Title, t.labels())
+}
+
+// GetLabels returns the work item tags.
+func (t *AzureTask) GetLabels() []string {
+	return t.item.Tags
+}
+
+// GetMetadata returns task metadata.
+func (t *AzureTask) GetMetadata() TaskMetadata {
+	return TaskMetadata{
+		Source:    SourceAzure,
+		CreatedAt: time.Now(),
+	}
+}
+
+// GetWorkItem returns the underlying work item.
+func (t *AzureTask) GetWorkItem() *azure.WorkItem {
+	return t.item
+}
+
+// labels adds the work item type to the tags, so a Bug picks the fix
+// branch type the way a "bug" label does in Linear.
+func (t *AzureTask) labels() []string {
+	if t.item.Type == "" {
+		return t.item.Tags
+	}
+	return append([]string{t.item.Type}, t.item.Tags...)
+}
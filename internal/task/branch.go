@@ -0,0 +1,172 @@
+package task
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// DefaultBranchTemplate is the branch naming used when none is configured.
+const DefaultBranchTemplate = "{ticket}-{slug}"
+
+// BranchScheme controls how branch names are generated from tasks.
+type BranchScheme struct {
+	// Template with placeholders {type}, {ticket} and {slug}, e.g.
+	// "{type}/{ticket}-{slug}". Empty means DefaultBranchTemplate, and
+	// Linear's suggested branch name is used when the ticket has one.
+	Template string
+
+	// SlugLength caps the slugified title (default 30).
+	SlugLength int
+
+	// Types maps task labels (case-insensitive) to {type}, e.g.
+	// bug → fix, feature → feat. The first matching label wins.
+	Types map[string]string
+
+	// DefaultType is {type} when no label matches (default "feat").
+	DefaultType string
+}
+
+// DefaultBranchScheme returns the built-in scheme.
+func DefaultBranchScheme() BranchScheme {
+	return BranchScheme{
+		SlugLength:  30,
+		Types:       map[string]string{"bug": "fix", "feature": "feat"},
+		DefaultType: "feat",
+	}
+}
+
+// branchScheme is the scheme used by all tasks; see SetBranchScheme.
+var branchScheme = DefaultBranchScheme()
+
+// SetBranchScheme validates and installs the scheme used by GetBranchName.
+// Zero fields fall back to DefaultBranchScheme.
+func SetBranchScheme(s BranchScheme) error {
+	def := DefaultBranchScheme()
+	if s.SlugLength <= 0 {
+		s.SlugLength = def.SlugLength
+	}
+	if s.Types == nil {
+		s.Types = def.Types
+	}
+	if s.DefaultType == "" {
+		s.DefaultType = def.DefaultType
+	}
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	branchScheme = s
+	return nil
+}
+
+var (
+	placeholderPattern = regexp.MustCompile(`\{[^{}]*\}`)
+	repeatedSlashes    = regexp.MustCompile(`/{2,}`)
+	repeatedHyphens    = regexp.MustCompile(`-{2,}`)
+)
+
+// Validate checks the template's placeholders and that it renders to a
+// valid git branch name.
+func (s BranchScheme) Validate() error {
+	template := s.template()
+	for _, p := range placeholderPattern.FindAllString(template, -1) {
+		switch p {
+		case "{type}", "{ticket}", "{slug}":
+		default:
+			return fmt.Errorf("branch template %q: unknown placeholder %s (want {type}, {ticket} or {slug})", template, p)
+		}
+	}
+	if !strings.Contains(template, "{ticket}") && !strings.Contains(template, "{slug}") {
+		return fmt.Errorf("branch template %q must include {ticket} or {slug} to be unique", template)
+	}
+	for label, typ := range s.Types {
+		if typ != "" && slugify(typ, 0) != strings.ToLower(typ) {
+			return fmt.Errorf("branch type %q for label %q must be lowercase letters, digits, - or _", typ, label)
+		}
+	}
+	if err := ValidateBranchName(s.Render("ENG-123", "Example title", nil)); err != nil {
+		return fmt.Errorf("branch template %q: %w", template, err)
+	}
+	return nil
+}
+
+func (s BranchScheme) template() string {
+	if s.Template == "" {
+		return DefaultBranchTemplate
+	}
+	return s.Template
+}
+
+// Render builds a branch name for a ticket ID, title and labels.
+func (s BranchScheme) Render(ticket, title string, labels []string) string {
+	slugLength := s.SlugLength
+	if slugLength <= 0 {
+		slugLength = DefaultBranchScheme().SlugLength
+	}
+
+	name := strings.NewReplacer(
+		"{type}", s.typeFor(labels),
+		"{ticket}", sanitizeRefComponent(ticket),
+		"{slug}", slugify(title, slugLength),
+	).Replace(s.template())
+
+	// Drop separators left by empty placeholders
+	name = repeatedSlashes.ReplaceAllString(name, "/")
+	name = repeatedHyphens.ReplaceAllString(name, "-")
+	return strings.Trim(name, "/-")
+}
+
+// typeFor returns the {type} for the first label with a mapping.
+func (s BranchScheme) typeFor(labels []string) string {
+	for _, label := range labels {
+		for key, typ := range s.Types {
+			if strings.EqualFold(key, label) {
+				return typ
+			}
+		}
+	}
+	return s.DefaultType
+}
+
+// invalidRefChars matches characters git forbids in ref names.
+var invalidRefChars = regexp.MustCompile(`[\x00-\x20\x7f~^:?*\[\\]`)
+
+// sanitizeRefComponent makes a ticket ID safe inside a branch name,
+// keeping its case.
+func sanitizeRefComponent(s string) string {
+	s = invalidRefChars.ReplaceAllString(s, "-")
+	s = strings.ReplaceAll(s, "/", "-")
+	s = strings.ReplaceAll(s, "..", ".")
+	s = strings.ReplaceAll(s, "@{", "-")
+	return strings.Trim(s, ".-")
+}
+
+// ValidateBranchName checks a branch name against git's ref name rules
+// (see git check-ref-format).
+func ValidateBranchName(name string) error {
+	switch {
+	case name == "":
+		return fmt.Errorf("branch name is empty")
+	case name == "@":
+		return fmt.Errorf("branch name cannot be %q", name)
+	case strings.HasPrefix(name, "-"):
+		return fmt.Errorf("branch name %q cannot start with -", name)
+	case strings.HasPrefix(name, "/"), strings.HasSuffix(name, "/"), strings.Contains(name, "//"):
+		return fmt.Errorf("branch name %q has an empty path component", name)
+	case strings.HasSuffix(name, "."):
+		return fmt.Errorf("branch name %q cannot end with .", name)
+	case strings.Contains(name, ".."):
+		return fmt.Errorf("branch name %q cannot contain ..", name)
+	case strings.Contains(name, "@{"):
+		return fmt.Errorf("branch name %q cannot contain @{", name)
+	}
+	if c := invalidRefChars.FindString(name); c != "" {
+		return fmt.Errorf("branch name %q contains invalid character %q", name, c)
+	}
+	for _, component := range strings.Split(name, "/") {
+		if strings.HasPrefix(component, ".") || strings.HasSuffix(component, ".lock") {
+			return fmt.Errorf("branch name %q: component %q cannot start with . or end with .lock", name, component)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package task
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/linear"
+)
+
+func TestBranchSchemeRender(t *testing.T) {
+	s := DefaultBranchScheme()
+	s.Template = "{type}/{ticket}-{slug}"
+
+	tests := []struct {
+		name   string
+		ticket string
+		title  string
+		labels []string
+		want   string
+	}{
+		{"bug label", "ENG-123", "Fix login redirect", []string{"Bug"}, "fix/ENG-123-fix-login-redirect"},
+		{"feature label", "ENG-124", "Add SSO", []string{"frontend", "feature"}, "feat/ENG-124-add-sso"},
+		{"default type", "ENG-125", "Tidy docs", nil, "feat/ENG-125-tidy-docs"},
+		{"unsafe ticket", "ENG 1:2", "Title", nil, "feat/ENG-1-2-title"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.Render(tt.ticket, tt.title, tt.labels); got != tt.want {
+				t.Errorf("expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBranchSchemeSlugLength(t *testing.T) {
+	s := BranchScheme{Template: "{slug}", SlugLength: 10}
+	if got := s.Render("ENG-1", "Add user authentication flow", nil); got != "add-user-a" {
+		t.Errorf("expected slug capped at 10 chars, got %q", got)
+	}
+	// Truncation must not leave a trailing hyphen
+	if got := s.Render("ENG-1", "Add users and more", nil); got != "add-users" {
+		t.Errorf("expected trailing hyphen trimmed, got %q", got)
+	}
+}
+
+func TestBranchSchemeEmptyType(t *testing.T) {
+	s := BranchScheme{Template: "{type}/{ticket}", Types: map[string]string{"chore": ""}}
+	if got := s.Render("ENG-1", "x", []string{"chore"}); got != "ENG-1" {
+		t.Errorf("expected separators of empty placeholders dropped, got %q", got)
+	}
+}
+
+func TestBranchSchemeValidate(t *testing.T) {
+	valid := []BranchScheme{
+		DefaultBranchScheme(),
+		{Template: "{type}/{ticket}-{slug}"},
+		{Template: "users/alice/{ticket}"},
+	}
+	for _, s := range valid {
+		if err := s.Validate(); err != nil {
+			t.Errorf("Validate(%q) unexpected error: %v", s.Template, err)
+		}
+	}
+
+	invalid := map[string]BranchScheme{
+		"unknown placeholder": {Template: "{team}/{ticket}"},
+		"not unique":          {Template: "{type}/work"},
+		"invalid ref":         {Template: "{ticket}..{slug}"},
+		"invalid type":        {Template: "{type}/{ticket}", Types: map[string]string{"bug": "Fix Me"}},
+	}
+	for name, s := range invalid {
+		if err := s.Validate(); err == nil {
+			t.Errorf("%s: expected error for %+v", name, s)
+		}
+	}
+}
+
+func TestValidateBranchName(t *testing.T) {
+	for _, name := range []string{"main", "feat/ENG-123-add-auth", "release/1.2", "a_b-c"} {
+		if err := ValidateBranchName(name); err != nil {
+			t.Errorf("ValidateBranchName(%q) unexpected error: %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "@", "-x", "/x", "x/", "a//b", "a..b", "x.", "a@{b", "a b", "a:b", "a~1", "a^", "a?", "a*", "a[b", `a\b`, ".hidden", "x/.y", "x.lock"} {
+		if err := ValidateBranchName(name); err == nil {
+			t.Errorf("ValidateBranchName(%q) expected error", name)
+		}
+	}
+}
+
+func TestSetBranchScheme(t *testing.T) {
+	defer func() { branchScheme = DefaultBranchScheme() }()
+
+	if err := SetBranchScheme(BranchScheme{Template: "{bad}"}); err == nil {
+		t.Fatal("expected invalid scheme to be rejected")
+	}
+	if err := SetBranchScheme(BranchScheme{Template: "{type}/{ticket}-{slug}"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A configured template overrides Linear's suggested branch name
+	linearTask := NewLinearTask(&linear.Ticket{
+		Identifier: "ENG-9",
+		Title:      "Crash on save",
+		Labels:     []string{"bug"},
+		BranchName: "alice/eng-9-crash-on-save",
+	})
+	if got := linearTask.GetBranchName(); got != "fix/ENG-9-crash-on-save" {
+		t.Errorf("expected templated Linear branch, got %q", got)
+	}
+
+	promptTask := NewPromptTask("# Add caching\n", "", "")
+	if !strings.HasPrefix(promptTask.GetBranchName(), "feat/prompt-") || !strings.HasSuffix(promptTask.GetBranchName(), "-add-caching") {
+		t.Errorf("expected templated prompt branch, got %q", promptTask.GetBranchName())
+	}
+}
+
+func TestCreateFromPromptValidatesBranch(t *testing.T) {
+	if _, err := CreateFromPrompt("Do it", "", "bad..branch"); err == nil {
+		t.Error("expected invalid --branch-name to be rejected")
+	}
+	if _, err := CreateFromPrompt("Do it", "", "good/branch"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/philjestin/boatmanmode/internal/azure"
+	"github.com/philjestin/boatmanmode/internal/jira"
 	"github.com/philjestin/boatmanmode/internal/linear"
 )
 
@@ -12,6 +14,8 @@ type InputMode string
 
 const (
 	ModeLinear InputMode = "linear"
+	ModeJira   InputMode = "jira"
+	ModeAzure  InputMode = "azure"
 	ModePrompt InputMode = "prompt"
 	ModeFile   InputMode = "file"
 )
@@ -25,11 +29,37 @@ func CreateFromLinear(ctx context.Context, linearClient *linear.Client, ticketID
 	return NewLinearTask(ticket), nil
 }
 
+// CreateFromJira creates a Task from a Jira issue.
+func CreateFromJira(ctx context.Context, jiraClient *jira.Client, key string) (Task, error) {
+	ticket, err := jiraClient.GetTicket(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Jira ticket: %w", err)
+	}
+	return NewJiraTask(ticket), nil
+}
+
+// CreateFromAzure creates a Task from an Azure Boards work item, given as
+// 123 or AB#123.
+func CreateFromAzure(ctx context.Context, azureClient *azure.Client, id string) (Task, error) {
+	workItemID, err := azure.ParseWorkItemID(id)
+	if err != nil {
+		return nil, err
+	}
+	item, err := azureClient.GetWorkItem(ctx, workItemID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Azure work item: %w", err)
+	}
+	return NewAzureTask(item), nil
+}
+
 // CreateFromPrompt creates a Task from an inline prompt.
 func CreateFromPrompt(prompt string, overrideTitle, overrideBranch string) (Task, error) {
 	if prompt == "" {
 		return nil, fmt.Errorf("prompt cannot be empty")
 	}
+	if err := validateOverrideBranch(overrideBranch); err != nil {
+		return nil, err
+	}
 	return NewPromptTask(prompt, overrideTitle, overrideBranch), nil
 }
 
@@ -38,5 +68,19 @@ func CreateFromFile(filePath string, overrideTitle, overrideBranch string) (Task
 	if filePath == "" {
 		return nil, fmt.Errorf("file path cannot be empty")
 	}
+	if err := validateOverrideBranch(overrideBranch); err != nil {
+		return nil, err
+	}
 	return NewFileTask(filePath, overrideTitle, overrideBranch)
 }
+
+// validateOverrideBranch checks a user-supplied branch name, if any.
+func validateOverrideBranch(name string) error {
+	if name == "" {
+		return nil
+	}
+	if err := ValidateBranchName(name); err != nil {
+		return fmt.Errorf("invalid --branch-name: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package task
+
+import (
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/jira"
+)
+
+// JiraTask wraps a Jira issue to implement the Task interface.
+type JiraTask struct {
+	ticket *jira.Ticket
+}
+
+// NewJiraTask creates a Task from a Jira issue.
+func NewJiraTask(ticket *jira.Ticket) Task {
+	return &JiraTask{ticket: ticket}
+}
+
+// GetID returns the Jira issue key (e.g., "PROJ-123").
+func (t *JiraTask) GetID() string {
+	return t.ticket.Key
+}
+
+// GetTitle returns the issue summary.
+func (t *JiraTask) GetTitle() string {
+	return t.ticket.Title
+}
+
+// GetDescription returns the issue description.
+func (t *JiraTask) GetDescription() string {
+	return t.ticket.Description
+}
+
+// GetBranchName generates a branch name from the branch scheme; Jira
+// has no branch name of its own.
+func (t *JiraTask) GetBranchName() string {
+	return branchScheme.Render(t.ticket.Key, t.ticket.Title, t.labels())
+}
+
+// GetLabels returns the issue labels.
+func (t *JiraTask) GetLabels() []string {
+	return t.ticket.Labels
+}
+
+// GetMetadata returns task metadata.
+func (t *JiraTask) GetMetadata() TaskMetadata {
+	return TaskMetadata{
+		Source:    SourceJira,
+		CreatedAt: time.Now(),
+	}
+}
+
+// GetTicket returns the underlying Jira issue.
+func (t *JiraTask) GetTicket() *jira.Ticket {
+	return t.ticket
+}
+
+// labels adds the issue type to the labels, so a Bug picks the fix
+// branch type the way a "bug" label does in Linear.
+func (t *JiraTask) labels() []string {
+	if t.ticket.Type == "" {
+		return t.ticket.Labels
+	}
+	return append([]string{t.ticket.Type}, t.ticket.Labels...)
+}
@@ -26,8 +26,8 @@ func NewPromptTask(prompt string, overrideTitle, overrideBranch string) Task {
 
 	branchName := overrideBranch
 	if branchName == "" {
-		// Generate branch name: prompt-timestamp-hash-title
-		branchName = fmt.Sprintf("%s-%s", id, sanitizeBranchName(title))
+		// Generate branch name, by default: prompt-timestamp-hash-title
+		branchName = branchScheme.Render(id, title, nil)
 	}
 
 	return &PromptTask{
@@ -40,6 +40,19 @@ func NewPromptTask(prompt string, overrideTitle, overrideBranch string) Task {
 	}
 }
 
+// RestorePromptTask recreates a prompt task from a checkpoint, keeping its
+// ID and branch so a resumed run continues the same work.
+func RestorePromptTask(id, title, description, branchName string) Task {
+	return &PromptTask{
+		id:          id,
+		title:       title,
+		description: description,
+		branchName:  branchName,
+		labels:      []string{},
+		createdAt:   time.Now(),
+	}
+}
+
 // GetID returns the auto-generated task ID.
 func (t *PromptTask) GetID() string {
 	return t.id
@@ -1,5 +1,5 @@
 // Package task provides an abstraction over different work input sources.
-// This allows boatmanmode to work with Linear tickets, inline prompts, or file-based prompts.
+// This allows boatmanmode to work with Linear or Jira tickets, inline prompts, or file-based prompts.
 package task
 
 import (
@@ -18,6 +18,8 @@ type TaskSource string
 
 const (
 	SourceLinear TaskSource = "linear"
+	SourceJira   TaskSource = "jira"
+	SourceAzure  TaskSource = "azure"
 	SourcePrompt TaskSource = "prompt"
 	SourceFile   TaskSource = "file"
 )
@@ -64,12 +66,13 @@ func (t *LinearTask) GetDescription() string {
 	return t.ticket.Description
 }
 
-// GetBranchName returns the branch name from Linear or generates one.
+// GetBranchName returns Linear's branch name, unless a branch template
+// is configured, or generates one from the branch scheme.
 func (t *LinearTask) GetBranchName() string {
-	if t.ticket.BranchName != "" {
+	if t.ticket.BranchName != "" && branchScheme.Template == "" {
 		return t.ticket.BranchName
 	}
-	return fmt.Sprintf("%s-%s", t.ticket.Identifier, sanitizeBranchName(t.ticket.Title))
+	return branchScheme.Render(t.ticket.Identifier, t.ticket.Title, t.ticket.Labels)
 }
 
 // GetLabels returns the ticket labels.
@@ -142,6 +145,12 @@ func truncateTitle(title string) string {
 
 // sanitizeBranchName makes a string safe for use in git branch names.
 func sanitizeBranchName(s string) string {
+	return slugify(s, 30)
+}
+
+// slugify lowercases s to letters, digits, - and _, capped at maxLen
+// characters (0 = no limit).
+func slugify(s string, maxLen int) string {
 	s = strings.ToLower(s)
 
 	// Replace spaces and slashes with hyphens
@@ -161,8 +170,8 @@ func sanitizeBranchName(s string) string {
 	s = strings.Trim(s, "-")
 
 	// Limit length
-	if len(s) > 30 {
-		s = s[:30]
+	if maxLen > 0 && len(s) > maxLen {
+		s = strings.TrimRight(s[:maxLen], "-")
 	}
 
 	if s == "" {
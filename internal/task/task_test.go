@@ -6,6 +6,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/philjestin/boatmanmode/internal/azure"
+	"github.com/philjestin/boatmanmode/internal/jira"
 	"github.com/philjestin/boatmanmode/internal/linear"
 )
 
@@ -68,6 +70,77 @@ func TestLinearTask_GeneratedBranchName(t *testing.T) {
 	}
 }
 
+func TestJiraTask(t *testing.T) {
+	ticket := &jira.Ticket{
+		Key:         "PROJ-42",
+		Title:       "Login fails on Safari",
+		Description: "Steps to reproduce...",
+		Type:        "Bug",
+		Labels:      []string{"auth"},
+		URL:         "https://acme.atlassian.net/browse/PROJ-42",
+	}
+
+	task := NewJiraTask(ticket)
+
+	if task.GetID() != "PROJ-42" {
+		t.Errorf("expected ID PROJ-42, got %s", task.GetID())
+	}
+	if task.GetMetadata().Source != SourceJira {
+		t.Errorf("expected source %s, got %s", SourceJira, task.GetMetadata().Source)
+	}
+	if branch := task.GetBranchName(); branch != "PROJ-42-login-fails-on-safari" {
+		t.Errorf("expected generated branch name, got %s", branch)
+	}
+
+	// The Bug issue type picks the fix branch type
+	defer func() { branchScheme = DefaultBranchScheme() }()
+	if err := SetBranchScheme(BranchScheme{Template: "{type}/{ticket}-{slug}"}); err != nil {
+		t.Fatal(err)
+	}
+	if branch := task.GetBranchName(); !strings.HasPrefix(branch, "fix/PROJ-42") {
+		t.Errorf("expected fix/ branch for a Bug, got %s", branch)
+	}
+	if labels := task.GetLabels(); len(labels) != 1 || labels[0] != "auth" {
+		t.Errorf("expected labels [auth], got %v", labels)
+	}
+	if task.(*JiraTask).GetTicket().URL != ticket.URL {
+		t.Error("GetTicket() should return original ticket")
+	}
+}
+
+func TestAzureTask(t *testing.T) {
+	item := &azure.WorkItem{
+		ID:    123,
+		Title: "Export invoices as PDF",
+		Type:  "Bug",
+		Tags:  []string{"billing"},
+		URL:   "https://dev.azure.com/acme/shop/_workitems/edit/123",
+	}
+
+	task := NewAzureTask(item)
+
+	if task.GetID() != "123" {
+		t.Errorf("expected ID 123, got %s", task.GetID())
+	}
+	if task.GetMetadata().Source != SourceAzure {
+		t.Errorf("expected source %s, got %s", SourceAzure, task.GetMetadata().Source)
+	}
+	if branch := task.GetBranchName(); branch != "123-export-invoices-as-pdf" {
+		t.Errorf("expected generated branch name, got %s", branch)
+	}
+
+	defer func() { branchScheme = DefaultBranchScheme() }()
+	if err := SetBranchScheme(BranchScheme{Template: "{type}/{ticket}-{slug}"}); err != nil {
+		t.Fatal(err)
+	}
+	if branch := task.GetBranchName(); !strings.HasPrefix(branch, "fix/123") {
+		t.Errorf("expected fix/ branch for a Bug, got %s", branch)
+	}
+	if task.(*AzureTask).GetWorkItem() != item {
+		t.Error("GetWorkItem() should return original work item")
+	}
+}
+
 func TestPromptTask(t *testing.T) {
 	prompt := "# Add user registration\n\nImplement user registration with email validation"
 
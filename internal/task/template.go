@@ -0,0 +1,116 @@
+package task
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+//go:embed templates/*.md
+var builtinTemplates embed.FS
+
+// Template is a reusable task prompt with {{placeholder}} markers.
+type Template struct {
+	Name        string
+	Description string
+	Content     string
+	Source      string // "builtin" or the file it was loaded from
+}
+
+// builtinDescriptions describe the shipped templates.
+var builtinDescriptions = map[string]string{
+	"bugfix":          "Fix a bug with a regression test",
+	"endpoint":        "Add an API endpoint",
+	"dependency-bump": "Upgrade a dependency and fix breaking changes",
+	"refactor":        "Restructure code without changing behavior",
+}
+
+// placeholderRe matches {{name}} placeholders.
+var placeholderRe = regexp.MustCompile(`\{\{\s*([a-z][a-z0-9_]*)\s*\}\}`)
+
+// LoadTemplates returns the built-in templates plus *.md templates from
+// dirs; later dirs override earlier ones (and the built-ins) by name.
+func LoadTemplates(dirs ...string) (map[string]Template, error) {
+	templates := make(map[string]Template)
+
+	entries, err := builtinTemplates.ReadDir("templates")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		data, err := builtinTemplates.ReadFile("templates/" + entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		name := strings.TrimSuffix(entry.Name(), ".md")
+		templates[name] = Template{
+			Name:        name,
+			Description: builtinDescriptions[name],
+			Content:     string(data),
+			Source:      "builtin",
+		}
+	}
+
+	for _, dir := range dirs {
+		paths, _ := filepath.Glob(filepath.Join(dir, "*.md"))
+		for _, path := range paths {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read template: %w", err)
+			}
+			name := strings.TrimSuffix(filepath.Base(path), ".md")
+			templates[name] = Template{
+				Name:        name,
+				Description: extractTitle(string(data)),
+				Content:     string(data),
+				Source:      path,
+			}
+		}
+	}
+	return templates, nil
+}
+
+// TemplateNames returns the template names, sorted.
+func TemplateNames(templates map[string]Template) []string {
+	names := make([]string, 0, len(templates))
+	for name := range templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Placeholders returns the distinct placeholder names in order of appearance.
+func (t Template) Placeholders() []string {
+	return Placeholders(t.Content)
+}
+
+// Render fills placeholders from values. Placeholders without a value are
+// left in place for the user to edit.
+func (t Template) Render(values map[string]string) string {
+	return placeholderRe.ReplaceAllStringFunc(t.Content, func(m string) string {
+		name := placeholderRe.FindStringSubmatch(m)[1]
+		if v, ok := values[name]; ok {
+			return v
+		}
+		return m
+	})
+}
+
+// Placeholders returns the distinct unfilled {{placeholder}} names in a
+// prompt, in order of appearance.
+func Placeholders(content string) []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, m := range placeholderRe.FindAllStringSubmatch(content, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			names = append(names, m[1])
+		}
+	}
+	return names
+}
@@ -0,0 +1,70 @@
+package task
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadBuiltinTemplates(t *testing.T) {
+	templates, err := LoadTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"bugfix", "dependency-bump", "endpoint", "refactor"}
+	if got := TemplateNames(templates); !reflect.DeepEqual(got, want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for _, name := range want {
+		tmpl := templates[name]
+		if tmpl.Description == "" || tmpl.Source != "builtin" {
+			t.Errorf("%s: expected builtin template with description, got %+v", name, tmpl)
+		}
+		if !strings.HasPrefix(tmpl.Content, "# ") {
+			t.Errorf("%s: template should start with a markdown title", name)
+		}
+	}
+}
+
+func TestLoadTemplatesOverrides(t *testing.T) {
+	user := t.TempDir()
+	repo := t.TempDir()
+	os.WriteFile(filepath.Join(user, "migration.md"), []byte("# Add {{table}} migration\n"), 0644)
+	os.WriteFile(filepath.Join(user, "bugfix.md"), []byte("# User bugfix\n"), 0644)
+	os.WriteFile(filepath.Join(repo, "bugfix.md"), []byte("# Repo bugfix {{ticket}}\n"), 0644)
+
+	templates, err := LoadTemplates(user, repo, filepath.Join(repo, "missing"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tmpl := templates["migration"]; tmpl.Description != "Add {{table}} migration" {
+		t.Errorf("expected custom template, got %+v", tmpl)
+	}
+	if tmpl := templates["bugfix"]; tmpl.Source != filepath.Join(repo, "bugfix.md") {
+		t.Errorf("expected repo template to override, got source %s", tmpl.Source)
+	}
+}
+
+func TestTemplateRender(t *testing.T) {
+	tmpl := Template{Content: "# Add {{method}} {{path}} endpoint\n\nMethod: {{ method }}\nAuth: {{auth}}\n"}
+
+	if got := tmpl.Placeholders(); !reflect.DeepEqual(got, []string{"method", "path", "auth"}) {
+		t.Errorf("unexpected placeholders: %v", got)
+	}
+
+	out := tmpl.Render(map[string]string{"method": "POST", "path": "/api/users"})
+	if !strings.Contains(out, "# Add POST /api/users endpoint") || !strings.Contains(out, "Method: POST") {
+		t.Errorf("placeholders not filled:\n%s", out)
+	}
+	if got := Placeholders(out); !reflect.DeepEqual(got, []string{"auth"}) {
+		t.Errorf("expected only auth left unfilled, got %v", got)
+	}
+
+	// The rendered file feeds straight into a prompt task
+	task := NewPromptTask(out, "", "")
+	if task.GetTitle() != "Add POST /api/users endpoint" {
+		t.Errorf("unexpected title %q", task.GetTitle())
+	}
+}
@@ -0,0 +1,226 @@
+// Package telemetry reports opt-in, anonymous aggregate usage metrics.
+//
+// Reports contain only step names and durations, iteration counts, the run
+// outcome, and a coarse failure category (failed step plus error kind).
+// Ticket IDs, code, diffs, prompts, paths, and error messages are never
+// included. Every report is also appended to a local log so users can audit
+// exactly what was sent.
+package telemetry
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+)
+
+// version is reported with each report; set by the CLI at startup.
+var version = "dev"
+
+// SetVersion sets the boatman version included in reports.
+func SetVersion(v string) {
+	version = v
+}
+
+// Run outcomes.
+const (
+	OutcomePRCreated    = "pr_created"
+	OutcomeNoPR         = "no_pr"
+	OutcomeReviewFailed = "review_failed"
+	OutcomeInconclusive = "inconclusive"
+	OutcomeError        = "error"
+	OutcomeCancelled    = "cancelled"
+)
+
+// StepMetric is the timing of one workflow step.
+type StepMetric struct {
+	Name       string `json:"name"`
+	DurationMs int64  `json:"duration_ms"`
+	Failed     bool   `json:"failed,omitempty"`
+}
+
+// Report is one anonymized run summary.
+type Report struct {
+	InstallID       string       `json:"install_id"`
+	Version         string       `json:"version"`
+	OS              string       `json:"os"`
+	Arch            string       `json:"arch"`
+	Outcome         string       `json:"outcome"`
+	FailureCategory string       `json:"failure_category,omitempty"`
+	Iterations      int          `json:"iterations"`
+	DurationMs      int64        `json:"duration_ms"`
+	Steps           []StepMetric `json:"steps"`
+	Timestamp       time.Time    `json:"timestamp"`
+}
+
+// Client sends reports when telemetry is enabled.
+type Client struct {
+	cfg  config.TelemetryConfig
+	dir  string
+	http *http.Client
+}
+
+// New creates a Client storing its install ID and report log in dir
+// (default: ~/.boatman/telemetry).
+func New(cfg config.TelemetryConfig, dir string) *Client {
+	if dir == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			dir = filepath.Join(home, ".boatman", "telemetry")
+		}
+	}
+	return &Client{
+		cfg:  cfg,
+		dir:  dir,
+		http: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Enabled reports whether the user opted in.
+func (c *Client) Enabled() bool {
+	return c.cfg.Enabled
+}
+
+// Endpoint returns where reports are sent ("" = local log only).
+func (c *Client) Endpoint() string {
+	return c.cfg.Endpoint
+}
+
+// LogPath returns the local log of sent reports.
+func (c *Client) LogPath() string {
+	return filepath.Join(c.dir, "reports.jsonl")
+}
+
+// InstallID returns a random identifier for this installation, creating it
+// on first use. It is not derived from any user or machine information.
+func (c *Client) InstallID() (string, error) {
+	path := filepath.Join(c.dir, "install_id")
+	if data, err := os.ReadFile(path); err == nil && len(bytes.TrimSpace(data)) > 0 {
+		return string(bytes.TrimSpace(data)), nil
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	id := hex.EncodeToString(buf)
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, []byte(id+"\n"), 0644); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// Send records and (if an endpoint is configured) posts a report.
+// It does nothing unless telemetry is enabled.
+func (c *Client) Send(ctx context.Context, r Report) error {
+	if !c.Enabled() {
+		return nil
+	}
+
+	id, err := c.InstallID()
+	if err != nil {
+		return fmt.Errorf("failed to create install ID: %w", err)
+	}
+	r.InstallID = id
+	r.Version = version
+	r.OS = runtime.GOOS
+	r.Arch = runtime.GOARCH
+	if r.Timestamp.IsZero() {
+		r.Timestamp = time.Now().UTC()
+	}
+
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	if err := c.appendLog(data); err != nil {
+		return err
+	}
+
+	if c.cfg.Endpoint == "" {
+		return nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.Endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send telemetry: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("telemetry endpoint returned %s", resp.Status)
+	}
+	return nil
+}
+
+func (c *Client) appendLog(data []byte) error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(c.LogPath(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// Recent returns up to n of the most recently logged reports, newest last.
+func (c *Client) Recent(n int) ([]Report, error) {
+	f, err := os.Open(c.LogPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var reports []Report
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var r Report
+		if json.Unmarshal(scanner.Bytes(), &r) == nil {
+			reports = append(reports, r)
+		}
+	}
+	if len(reports) > n {
+		reports = reports[len(reports)-n:]
+	}
+	return reports, scanner.Err()
+}
+
+// FailureCategory classifies a failure without including its message:
+// the failed step plus whether it timed out, was canceled, or errored.
+func FailureCategory(step string, err error) string {
+	if err == nil {
+		return ""
+	}
+	kind := "error"
+	msg := strings.ToLower(err.Error())
+	switch {
+	case errors.Is(err, context.Canceled):
+		kind = "canceled"
+	case errors.Is(err, context.DeadlineExceeded), strings.Contains(msg, "timed out"), strings.Contains(msg, "timeout"):
+		kind = "timeout"
+	}
+	return step + ":" + kind
+}
@@ -0,0 +1,84 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/config"
+)
+
+func TestSendDisabledDoesNothing(t *testing.T) {
+	dir := t.TempDir()
+	client := New(config.TelemetryConfig{Enabled: false}, dir)
+
+	if err := client.Send(context.Background(), Report{Outcome: OutcomePRCreated}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "install_id")); !os.IsNotExist(err) {
+		t.Error("Expected no install ID when disabled")
+	}
+	if reports, _ := client.Recent(10); len(reports) != 0 {
+		t.Errorf("Expected no logged reports, got %d", len(reports))
+	}
+}
+
+func TestSendPostsAndLogs(t *testing.T) {
+	var received Report
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &received)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	client := New(config.TelemetryConfig{Enabled: true, Endpoint: server.URL}, dir)
+
+	report := Report{
+		Outcome:    OutcomeReviewFailed,
+		Iterations: 3,
+		Steps:      []StepMetric{{Name: "planning", DurationMs: 1200}},
+	}
+	if err := client.Send(context.Background(), report); err != nil {
+		t.Fatal(err)
+	}
+
+	if received.Outcome != OutcomeReviewFailed || received.Iterations != 3 || received.InstallID == "" {
+		t.Errorf("Unexpected report received: %+v", received)
+	}
+
+	// Install ID is stable across sends
+	client.Send(context.Background(), report)
+	reports, err := client.Recent(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 2 || reports[0].InstallID != reports[1].InstallID {
+		t.Errorf("Expected 2 logged reports with the same install ID, got %+v", reports)
+	}
+}
+
+func TestFailureCategory(t *testing.T) {
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{nil, ""},
+		{context.Canceled, "execution:canceled"},
+		{fmt.Errorf("wrapped: %w", context.DeadlineExceeded), "execution:timeout"},
+		{errors.New("claude timed out after 10m"), "execution:timeout"},
+		{errors.New("secret/path/in/message.go failed"), "execution:error"},
+	}
+	for _, tt := range tests {
+		if got := FailureCategory("execution", tt.err); got != tt.want {
+			t.Errorf("FailureCategory(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
@@ -14,6 +14,8 @@ import (
 	"time"
 
 	"github.com/philjestin/boatmanmode/internal/coordinator"
+	"github.com/philjestin/boatmanmode/internal/coverage"
+	"github.com/philjestin/boatmanmode/internal/impact"
 )
 
 // TestResult contains the outcome of test execution.
@@ -28,12 +30,18 @@ type TestResult struct {
 	Output      string
 	Duration    time.Duration
 	FailedNames []string
+	// Files is per-file coverage, collected with SetCoverage
+	Files       coverage.Profile
 }
 
 // Agent runs tests for the project.
 type Agent struct {
 	id           string
 	worktreePath string
+	command      string
+	renames      map[string]string
+	diff         string
+	perFile      bool
 	coord        *coordinator.Coordinator
 }
 
@@ -65,6 +73,32 @@ func (a *Agent) SetCoordinator(c *coordinator.Coordinator) {
 	a.coord = c
 }
 
+// SetCommand replaces framework detection with the project's own test
+// command, run via sh -c. Pass/fail then comes from its exit code alone.
+func (a *Agent) SetCommand(command string) {
+	a.command = command
+}
+
+// SetRenames tells the runner which changed files were moved, as a map
+// from new path to old path. Tests of a moved file are looked for at both
+// its new and old locations, since a test left behind may no longer build.
+func (a *Agent) SetRenames(renames map[string]string) {
+	a.renames = renames
+}
+
+// SetDiff gives the runner the diff under test. Besides the test files
+// named after changed files, tests referencing the functions and types
+// the diff changes are then run too.
+func (a *Agent) SetDiff(diff string) {
+	a.diff = diff
+}
+
+// SetCoverage makes runs collect per-file coverage: Go's cover profile,
+// or an lcov report the tests write (see coverage.LCOVPaths).
+func (a *Agent) SetCoverage(enabled bool) {
+	a.perFile = enabled
+}
+
 // Framework represents a detected test framework.
 type Framework struct {
 	Name    string
@@ -76,6 +110,14 @@ type Framework struct {
 
 // DetectFramework figures out what test framework the project uses.
 func (a *Agent) DetectFramework() (*Framework, error) {
+	if a.command != "" {
+		return &Framework{
+			Name:    "custom",
+			Command: "sh",
+			Args:    []string{"-c", a.command},
+		}, nil
+	}
+
 	// Check for Go
 	if _, err := os.Stat(filepath.Join(a.worktreePath, "go.mod")); err == nil {
 		return &Framework{
@@ -214,6 +256,17 @@ func (a *Agent) RunForFiles(ctx context.Context, changedFiles []string) (*TestRe
 	return a.runTests(ctx, framework, args)
 }
 
+// RelatedTests returns the existing test files for changedFiles (test
+// files among them included). It returns nil when no test framework is
+// detected.
+func (a *Agent) RelatedTests(changedFiles []string) []string {
+	framework, err := a.DetectFramework()
+	if err != nil {
+		return nil
+	}
+	return a.findRelatedTests(changedFiles, framework)
+}
+
 // findRelatedTests finds test files related to changed files.
 func (a *Agent) findRelatedTests(changedFiles []string, framework *Framework) []string {
 	var testFiles []string
@@ -229,11 +282,28 @@ func (a *Agent) findRelatedTests(changedFiles []string, framework *Framework) []
 			continue
 		}
 
-		// Find corresponding test file
-		testFile := a.findTestFile(file, framework)
-		if testFile != "" && !seen[testFile] {
-			testFiles = append(testFiles, testFile)
-			seen[testFile] = true
+		// Find corresponding test file, where the file is and where it was
+		sources := []string{file}
+		if from, ok := a.renames[file]; ok {
+			sources = append(sources, from)
+		}
+		for _, source := range sources {
+			testFile := a.findTestFile(source, framework)
+			if testFile != "" && !seen[testFile] {
+				testFiles = append(testFiles, testFile)
+				seen[testFile] = true
+			}
+		}
+	}
+
+	// Tests that use the changed code, wherever they live
+	if a.diff != "" {
+		isTest := func(path string) bool { return a.isTestFile(path, framework) }
+		for _, testFile := range impact.New(a.worktreePath).ReferencingTests(a.diff, isTest) {
+			if !seen[testFile] {
+				testFiles = append(testFiles, testFile)
+				seen[testFile] = true
+			}
 		}
 	}
 
@@ -355,6 +425,19 @@ func (a *Agent) runTests(ctx context.Context, framework *Framework, args []strin
 
 	start := time.Now()
 
+	var profile string
+	stamps := coverage.Stamps(a.worktreePath)
+	if a.perFile && framework.Name == "go" {
+		f, err := os.CreateTemp("", "boatman-cover-*.out")
+		if err != nil {
+			return nil, err
+		}
+		f.Close()
+		profile = f.Name()
+		defer os.Remove(profile)
+		args = append([]string{args[0], "-coverprofile=" + profile}, args[1:]...)
+	}
+
 	cmd := exec.CommandContext(ctx, framework.Command, args...)
 	cmd.Dir = a.worktreePath
 
@@ -375,6 +458,13 @@ func (a *Agent) runTests(ctx context.Context, framework *Framework, args []strin
 
 	// Parse output based on framework
 	a.parseOutput(result, output, framework)
+	if a.perFile {
+		if data, err := os.ReadFile(profile); profile != "" && err == nil {
+			result.Files = coverage.ParseGo(data, coverage.ModulePath(a.worktreePath))
+		} else {
+			result.Files = coverage.FindLCOV(a.worktreePath, stamps)
+		}
+	}
 
 	// If command failed but we couldn't parse failures, check exit code
 	if err != nil && result.FailedTests == 0 {
@@ -401,6 +491,9 @@ func (a *Agent) parseOutput(result *TestResult, output string, framework *Framew
 		a.parseJestOutput(result, output)
 	case "pytest":
 		a.parsePytestOutput(result, output)
+	case "custom":
+		// Failures are detected from the exit code
+		result.Passed = true
 	default:
 		// Generic pass/fail detection
 		result.Passed = !strings.Contains(strings.ToLower(output), "fail")
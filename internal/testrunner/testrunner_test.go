@@ -345,3 +345,91 @@ func TestRunAllNoFramework(t *testing.T) {
 		t.Error("Should pass when no framework detected")
 	}
 }
+
+func TestRunAllCustomCommand(t *testing.T) {
+	tmpDir := t.TempDir()
+	// A go.mod would normally select the go framework
+	os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module test\n"), 0644)
+
+	agent := New(tmpDir)
+	agent.SetCommand("echo 0 failures")
+	result, err := agent.RunAll(context.Background())
+	if err != nil {
+		t.Fatalf("RunAll failed: %v", err)
+	}
+	if result.Framework != "custom" || !result.Passed {
+		t.Errorf("Expected passing custom run, got %+v", result)
+	}
+
+	agent.SetCommand("exit 1")
+	result, _ = agent.RunAll(context.Background())
+	if result.Passed {
+		t.Error("Expected failure from non-zero exit")
+	}
+}
+
+func TestRunAllCoverage(t *testing.T) {
+	tmpDir := t.TempDir()
+	agent := New(tmpDir)
+	agent.SetCommand("mkdir -p coverage && printf 'SF:src/app.js\\nLF:10\\nLH:7\\nend_of_record\\n' > coverage/lcov.info")
+	result, _ := agent.RunAll(context.Background())
+	if result.Files != nil {
+		t.Errorf("Expected no per-file coverage without SetCoverage, got %v", result.Files)
+	}
+
+	agent.SetCoverage(true)
+	result, _ = agent.RunAll(context.Background())
+	if f, ok := result.Files["src/app.js"]; !ok || f.Covered != 7 || f.Total != 10 {
+		t.Errorf("Expected the lcov report written by the run, got %v", result.Files)
+	}
+}
+
+func TestRelatedTests(t *testing.T) {
+	tmpDir := t.TempDir()
+	if got := New(tmpDir).RelatedTests([]string{"main.go"}); got != nil {
+		t.Errorf("Expected nil without a framework, got %v", got)
+	}
+
+	os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module test\n"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "user.go"), []byte("package test\n"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "user_test.go"), []byte("package test\n"), 0644)
+
+	got := New(tmpDir).RelatedTests([]string{"user.go", "order.go"})
+	if len(got) != 1 || got[0] != "user_test.go" {
+		t.Errorf("Expected [user_test.go], got %v", got)
+	}
+}
+
+func TestRelatedTestsFollowRenames(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module test\n"), 0644)
+	os.MkdirAll(filepath.Join(tmpDir, "billing"), 0755)
+	os.WriteFile(filepath.Join(tmpDir, "billing", "invoice.go"), []byte("package billing\n"), 0644)
+	// The test stayed behind when invoice.go moved
+	os.WriteFile(filepath.Join(tmpDir, "invoice_test.go"), []byte("package test\n"), 0644)
+
+	agent := New(tmpDir)
+	if got := agent.RelatedTests([]string{"billing/invoice.go"}); len(got) != 0 {
+		t.Errorf("Expected no tests without rename info, got %v", got)
+	}
+	agent.SetRenames(map[string]string{"billing/invoice.go": "invoice.go"})
+	if got := agent.RelatedTests([]string{"billing/invoice.go"}); len(got) != 1 || got[0] != "invoice_test.go" {
+		t.Errorf("Expected the test at the old location, got %v", got)
+	}
+}
+
+func TestRelatedTestsBySymbol(t *testing.T) {
+	tmpDir := t.TempDir()
+	os.WriteFile(filepath.Join(tmpDir, "go.mod"), []byte("module test\n"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "money.go"), []byte("package test\n\nfunc Round(x float64) float64 {\n\treturn x\n}\n"), 0644)
+	os.WriteFile(filepath.Join(tmpDir, "ledger_test.go"), []byte("package test\n\nimport \"testing\"\n\nfunc TestLedger(t *testing.T) { Round(1) }\n"), 0644)
+
+	agent := New(tmpDir)
+	if got := agent.RelatedTests([]string{"money.go"}); len(got) != 0 {
+		t.Errorf("Expected no tests by name, got %v", got)
+	}
+	agent.SetDiff("--- a/money.go\n+++ b/money.go\n@@ -4 +4 @@\n-\treturn 0\n+\treturn x\n")
+	if got := agent.RelatedTests([]string{"money.go"}); len(got) != 1 || got[0] != "ledger_test.go" {
+		t.Errorf("Expected the test calling Round, got %v", got)
+	}
+}
@@ -7,9 +7,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
 	"time"
@@ -29,11 +31,28 @@ type Session struct {
 type Manager struct {
 	sessionPrefix string
 	outputDir     string
+	out           io.Writer // Progress output; stdout when nil
+}
+
+// OutputDir is where sessions keep their prompt, result and output files.
+// It is under os.TempDir(), so it exists on every platform.
+func OutputDir() string {
+	return filepath.Join(os.TempDir(), "boatman-sessions")
+}
+
+// Available reports whether agents can run in tmux: it is installed, and
+// the platform can run the session scripts, which Windows cannot.
+func Available() bool {
+	if runtime.GOOS == "windows" {
+		return false
+	}
+	_, err := exec.LookPath("tmux")
+	return err == nil
 }
 
 // NewManager creates a new tmux session manager.
 func NewManager(prefix string) *Manager {
-	outputDir := filepath.Join(os.TempDir(), "boatman-sessions")
+	outputDir := OutputDir()
 	_ = os.MkdirAll(outputDir, 0755) // Best effort, failure handled later when writing files
 
 	return &Manager{
@@ -42,6 +61,19 @@ func NewManager(prefix string) *Manager {
 	}
 }
 
+// SetOutput sends the manager's progress output to w. nil restores stdout.
+func (m *Manager) SetOutput(w io.Writer) {
+	m.out = w
+}
+
+// output returns where progress output goes.
+func (m *Manager) output() io.Writer {
+	if m.out == nil {
+		return os.Stdout
+	}
+	return m.out
+}
+
 // CreateSession creates a new tmux session for a Claude agent.
 func (m *Manager) CreateSession(name, workDir string) (*Session, error) {
 	sessionName := fmt.Sprintf("%s-%s", m.sessionPrefix, name)
@@ -167,6 +199,10 @@ parse_claude_output() {
 type ClaudeOptions struct {
 	Model               string
 	EnablePromptCaching bool
+	// ToolArgs are the tool restriction flags, e.g. --tools Read,Grep
+	ToolArgs []string
+	// Env are KEY=value variables set for the claude process
+	Env []string
 }
 
 func (m *Manager) RunClaudeStreaming(ctx context.Context, sess *Session, systemPrompt, userPrompt string) (string, *cost.Usage, error) {
@@ -193,8 +229,19 @@ func (m *Manager) RunClaudeStreamingWithOptions(ctx context.Context, sess *Sessi
 	if opts.Model != "" {
 		claudeFlags += fmt.Sprintf(" --model %s", opts.Model)
 	}
+	for _, arg := range opts.ToolArgs {
+		claudeFlags += " " + shellQuote(arg)
+	}
 	// Note: Prompt caching happens automatically at the API level, no flag needed
 
+	// Variables set on the command line of claude only
+	claudeEnv := ""
+	for _, kv := range opts.Env {
+		if k, v, ok := strings.Cut(kv, "="); ok {
+			claudeEnv += k + "=" + shellQuote(v) + " "
+		}
+	}
+
 	// Raw output file for debugging when result parsing fails
 	rawOutputFile := filepath.Join(m.outputDir, fmt.Sprintf("%s-raw.txt", sess.Name))
 	os.Remove(rawOutputFile) // Clear any old output
@@ -233,7 +280,7 @@ if ! command -v claude &> /dev/null; then
 fi
 
 # Run Claude with stream-json and parse output
-claude %s --system-prompt "$SYSTEM_PROMPT" "$USER_PROMPT" 2>&1 | parse_claude_output
+%sclaude %s --system-prompt "$SYSTEM_PROMPT" "$USER_PROMPT" 2>&1 | parse_claude_output
 
 EXIT_CODE=$?
 echo ''
@@ -247,7 +294,7 @@ touch '%s'
 
 # Cleanup (leave result and raw files for parsing)
 rm -f '%s' '%s' '%s'
-`, resultFile, rawOutputFile, parseScript, sysFile, promptFile, sess.DoneFile, claudeFlags, sess.DoneFile, promptFile, sysFile, scriptFile)
+`, resultFile, rawOutputFile, parseScript, sysFile, promptFile, sess.DoneFile, claudeEnv, claudeFlags, sess.DoneFile, promptFile, sysFile, scriptFile)
 	} else {
 		script = fmt.Sprintf(`#!/bin/bash
 echo ''
@@ -275,7 +322,7 @@ if ! command -v claude &> /dev/null; then
 fi
 
 # Run Claude with stream-json and parse output
-claude %s "$USER_PROMPT" 2>&1 | parse_claude_output
+%sclaude %s "$USER_PROMPT" 2>&1 | parse_claude_output
 
 EXIT_CODE=$?
 echo ''
@@ -289,7 +336,7 @@ touch '%s'
 
 # Cleanup (leave result and raw files for parsing)
 rm -f '%s' '%s'
-`, resultFile, rawOutputFile, parseScript, promptFile, sess.DoneFile, claudeFlags, sess.DoneFile, promptFile, scriptFile)
+`, resultFile, rawOutputFile, parseScript, promptFile, sess.DoneFile, claudeEnv, claudeFlags, sess.DoneFile, promptFile, scriptFile)
 	}
 
 	if err := os.WriteFile(scriptFile, []byte(script), 0755); err != nil {
@@ -309,12 +356,13 @@ rm -f '%s' '%s'
 
 // waitAndCapture waits for Claude to finish and captures the output.
 func (m *Manager) waitAndCapture(ctx context.Context, sess *Session) (string, *cost.Usage, error) {
-	fmt.Println("   ┌─────────────────────────────────────────────────────────────")
-	fmt.Printf("   │ 📺 Session: %s\n", sess.Name)
-	fmt.Println("   │ 💡 Watch live: boatman watch")
-	fmt.Println("   │ 💡 Or: tmux attach -t " + sess.Name)
-	fmt.Println("   └─────────────────────────────────────────────────────────────")
-	fmt.Println("   ⏳ Waiting for Claude (watch live with 'boatman watch')...")
+	out := m.output()
+	fmt.Fprintln(out, "   ┌─────────────────────────────────────────────────────────────")
+	fmt.Fprintf(out, "   │ 📺 Session: %s\n", sess.Name)
+	fmt.Fprintln(out, "   │ 💡 Watch live: boatman watch")
+	fmt.Fprintln(out, "   │ 💡 Or: tmux attach -t " + sess.Name)
+	fmt.Fprintln(out, "   └─────────────────────────────────────────────────────────────")
+	fmt.Fprintln(out, "   ⏳ Waiting for Claude (watch live with 'boatman watch')...")
 
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
@@ -330,6 +378,8 @@ func (m *Manager) waitAndCapture(ctx context.Context, sess *Session) (string, *c
 	for {
 		select {
 		case <-ctx.Done():
+			// Stop Claude with the run; the session's output stays on disk
+			m.KillSession(sess)
 			return "", nil, ctx.Err()
 		case <-timeout:
 			return "", nil, fmt.Errorf("timeout waiting for Claude response")
@@ -337,7 +387,7 @@ func (m *Manager) waitAndCapture(ctx context.Context, sess *Session) (string, *c
 			// Check if done
 			if _, err := os.Stat(sess.DoneFile); err == nil {
 				elapsed := time.Since(startTime)
-				fmt.Printf("\n   ⏱️  Completed in %s\n", elapsed.Round(time.Second))
+				fmt.Fprintf(out, "\n   ⏱️  Completed in %s\n", elapsed.Round(time.Second))
 
 				// Capture the pane content
 				output, err := m.capturePane(sess, 5000) // Capture last 5000 lines
@@ -356,7 +406,7 @@ func (m *Manager) waitAndCapture(ctx context.Context, sess *Session) (string, *c
 
 					// Display usage if available
 					if usage != nil && !usage.IsEmpty() {
-						fmt.Printf("   💰 Cost: $%.4f (in: %d, out: %d, cache: %d)\n",
+						fmt.Fprintf(out, "   💰 Cost: $%.4f (in: %d, out: %d, cache: %d)\n",
 							usage.TotalCostUSD, usage.InputTokens, usage.OutputTokens, usage.CacheReadTokens)
 					}
 
@@ -375,23 +425,23 @@ func (m *Manager) waitAndCapture(ctx context.Context, sess *Session) (string, *c
 					}
 					// Log that we have raw output but couldn't parse it
 					if os.Getenv("BOATMAN_DEBUG") == "1" {
-						fmt.Printf("   ⚠️  Raw output available (%d bytes) but no result found\n", len(rawContent))
-						fmt.Printf("   📁 Debug file: %s\n", rawOutputFile)
+						fmt.Fprintf(out, "   ⚠️  Raw output available (%d bytes) but no result found\n", len(rawContent))
+						fmt.Fprintf(out, "   📁 Debug file: %s\n", rawOutputFile)
 					}
 				}
 
 				// Final fallback: extract from pane content
 				fallbackResult := extractClaudeOutput(output)
 				if fallbackResult == "" && os.Getenv("BOATMAN_DEBUG") == "1" {
-					fmt.Println("   ⚠️  No result could be extracted from Claude's output")
-					fmt.Printf("   📁 Check pane output: %s\n", sess.OutputFile)
+					fmt.Fprintln(out, "   ⚠️  No result could be extracted from Claude's output")
+					fmt.Fprintf(out, "   📁 Check pane output: %s\n", sess.OutputFile)
 				}
 				return fallbackResult, nil, nil
 			}
 
 			// Print progress dots
 			if time.Since(lastDot) >= 5*time.Second {
-				fmt.Print(".")
+				fmt.Fprint(out, ".")
 				lastDot = time.Now()
 			}
 		}
@@ -692,6 +742,26 @@ func (m *Manager) ListSessions() ([]string, error) {
 	return sessions, nil
 }
 
+// SessionPaths maps boatman tmux session names to their working directories.
+func (m *Manager) SessionPaths() (map[string]string, error) {
+	cmd := exec.Command("tmux", "list-sessions", "-F", "#{session_name}\t#{session_path}")
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, nil // No sessions
+	}
+
+	paths := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		name, path, _ := strings.Cut(scanner.Text(), "\t")
+		if strings.HasPrefix(name, m.sessionPrefix) {
+			paths[name] = path
+		}
+	}
+
+	return paths, nil
+}
+
 // sendKeys sends keys to a tmux session.
 func (m *Manager) sendKeys(sessionName, keys string) error {
 	cmd := exec.Command("tmux", "send-keys", "-t", sessionName, keys, "Enter")
@@ -702,3 +772,8 @@ func (m *Manager) sendKeys(sessionName, keys string) error {
 func (m *Manager) CapturePane(sess *Session) (string, error) {
 	return m.capturePane(sess, 1000)
 }
+
+// shellQuote quotes s for the runner script.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
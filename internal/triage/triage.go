@@ -0,0 +1,239 @@
+// Package triage investigates a ticket without changing any code: a
+// read-only agent looks for the root cause and proposes a fix, and the
+// analysis is posted back to the ticket with an effort estimate. It costs
+// a planning run, so it can be run across a whole backlog.
+package triage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/philjestin/boatmanmode/internal/claude"
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/cost"
+	"github.com/philjestin/boatmanmode/internal/estimate"
+	"github.com/philjestin/boatmanmode/internal/planner"
+	"github.com/philjestin/boatmanmode/internal/task"
+)
+
+// ErrNoTools is returned when the configured model can't read the code.
+var ErrNoTools = errors.New("triage needs the Claude CLI to read the code; API providers cannot use tools")
+
+// SuspectedFile is a file the investigation implicates.
+type SuspectedFile struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+// Analysis is the structured outcome of a triage investigation.
+type Analysis struct {
+	Summary        string          `json:"summary"`
+	RootCause      string          `json:"root_cause"`
+	SuspectedFiles []SuspectedFile `json:"suspected_files"`
+	Approach       []string        `json:"approach"`
+	TestStrategy   string          `json:"test_strategy"`
+	Risks          []string        `json:"risks"`
+	OpenQuestions  []string        `json:"open_questions"`
+}
+
+// Triager is a read-only Claude agent that investigates tickets.
+type Triager struct {
+	client *claude.Client
+	out    io.Writer // Progress output; stdout when nil
+}
+
+// New creates a Triager for the repository at repoPath. It uses the
+// planner's model.
+func New(repoPath string, cfg *config.Config) *Triager {
+	client := claude.NewReadOnly(repoPath, "triage")
+	if cfg.Claude.Models.Planner != "" {
+		client.Model = cfg.Claude.Models.Planner
+	}
+	client.Sampling = cfg.Claude.SamplingFor("planner")
+	client.EnablePromptCaching = cfg.Claude.EnablePromptCaching
+	client.Configure(cfg.Claude)
+	return &Triager{client: client}
+}
+
+// SetOutput sends the triager's progress output, including the agent's
+// streamed response, to w.
+func (tr *Triager) SetOutput(w io.Writer) {
+	tr.out = w
+	tr.client.Output = w
+}
+
+// output returns where progress output goes.
+func (tr *Triager) output() io.Writer {
+	if tr.out == nil {
+		return os.Stdout
+	}
+	return tr.out
+}
+
+const systemPrompt = `You are a senior engineer triaging a ticket. Investigate it, but do NOT change anything:
+you can only read and search the code.
+
+Your process:
+1. Read the ticket carefully; for a bug, work out the behavior it describes
+2. Search for the code involved (use Glob, Grep) and read it
+3. Trace the likely root cause, citing the files and functions responsible
+4. Propose the smallest change that fixes it, and how to test it
+
+After your investigation, output a JSON analysis in this exact format:
+
+` + "```json" + `
+{
+  "summary": "One sentence describing the problem",
+  "root_cause": "What is causing it and where, or for features what is missing",
+  "suspected_files": [
+    {"path": "path/to/file.go", "reason": "Why it is involved"}
+  ],
+  "approach": [
+    "Step 1: Do X",
+    "Step 2: Do Y"
+  ],
+  "test_strategy": "How to verify the fix",
+  "risks": ["Things that could go wrong"],
+  "open_questions": ["What the ticket leaves unclear"]
+}
+` + "```" + `
+
+Output ONLY the JSON block after your investigation. No other text after the JSON.`
+
+// Investigate runs the investigation for t.
+func (tr *Triager) Investigate(ctx context.Context, t task.Task) (*Analysis, *cost.Usage, error) {
+	if !tr.client.SupportsTools() {
+		return nil, nil, ErrNoTools
+	}
+	fmt.Fprintln(tr.output(), "   🔎 Investigating the codebase (read-only)...")
+
+	prompt := fmt.Sprintf(`# Ticket: %s
+
+## Description
+%s
+
+Investigate this ticket and find its root cause. Do not modify any files.`,
+		t.GetTitle(),
+		t.GetDescription())
+
+	start := time.Now()
+	response, usage, err := tr.client.Message(ctx, systemPrompt, prompt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("triage agent failed: %w", err)
+	}
+	fmt.Fprintf(tr.output(), "   ⏱️  Investigation completed in %s\n", time.Since(start).Round(time.Second))
+
+	analysis, err := Parse(response)
+	if err != nil {
+		return nil, usage, fmt.Errorf("could not parse the analysis: %w", err)
+	}
+	return analysis, usage, nil
+}
+
+var jsonBlock = regexp.MustCompile("```(?:json)?\\s*\\n?([\\s\\S]*?)\\n?```")
+
+// Parse extracts the JSON analysis from the agent's response.
+func Parse(response string) (*Analysis, error) {
+	var jsonStr string
+	if m := jsonBlock.FindStringSubmatch(response); len(m) > 1 {
+		jsonStr = m[1]
+	} else {
+		start := strings.Index(response, "{")
+		end := strings.LastIndex(response, "}")
+		if start < 0 || end <= start {
+			return nil, fmt.Errorf("no JSON found in response")
+		}
+		jsonStr = response[start : end+1]
+	}
+
+	var a Analysis
+	if err := json.Unmarshal([]byte(jsonStr), &a); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if a.Summary == "" && a.RootCause == "" {
+		return nil, fmt.Errorf("analysis has no summary or root cause")
+	}
+	return &a, nil
+}
+
+// Plan converts the analysis into a plan, for estimating the effort of
+// automating the fix.
+func (a *Analysis) Plan() *planner.Plan {
+	files := make([]string, len(a.SuspectedFiles))
+	for i, f := range a.SuspectedFiles {
+		files[i] = f.Path
+	}
+	return &planner.Plan{
+		Summary:       a.Summary,
+		Approach:      a.Approach,
+		RelevantFiles: files,
+		TestStrategy:  a.TestStrategy,
+		Warnings:      a.Risks,
+	}
+}
+
+// Comment formats the analysis and its estimate as a markdown ticket
+// comment.
+func (a *Analysis) Comment(e *estimate.Estimate) string {
+	var sb strings.Builder
+	sb.WriteString("## 🔎 boatman triage\n\n")
+	if a.Summary != "" {
+		sb.WriteString(a.Summary + "\n\n")
+	}
+	if a.RootCause != "" {
+		sb.WriteString("### Suspected root cause\n" + a.RootCause + "\n\n")
+	}
+	if len(a.SuspectedFiles) > 0 {
+		sb.WriteString("### Suspected files\n")
+		for _, f := range a.SuspectedFiles {
+			if f.Reason != "" {
+				sb.WriteString(fmt.Sprintf("- `%s`: %s\n", f.Path, f.Reason))
+			} else {
+				sb.WriteString(fmt.Sprintf("- `%s`\n", f.Path))
+			}
+		}
+		sb.WriteString("\n")
+	}
+	if len(a.Approach) > 0 {
+		sb.WriteString("### Proposed approach\n")
+		for i, step := range a.Approach {
+			sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, step))
+		}
+		sb.WriteString("\n")
+	}
+	if a.TestStrategy != "" {
+		sb.WriteString("### Testing\n" + a.TestStrategy + "\n\n")
+	}
+	if e != nil {
+		sb.WriteString("### Estimated effort\n")
+		sb.WriteString(fmt.Sprintf("%s complexity, ~%.1f review iteration(s), ~$%.2f to automate",
+			e.Complexity, e.PredictedIterations, e.PredictedCostUSD))
+		if e.SuccessLikelihood > 0 {
+			sb.WriteString(fmt.Sprintf("; %d%% likely to succeed without human help", e.SuccessLikelihood))
+		}
+		sb.WriteString(".\n\n")
+	}
+	if len(a.Risks) > 0 {
+		sb.WriteString("### Risks\n")
+		for _, r := range a.Risks {
+			sb.WriteString("- " + r + "\n")
+		}
+		sb.WriteString("\n")
+	}
+	if len(a.OpenQuestions) > 0 {
+		sb.WriteString("### Open questions\n")
+		for _, q := range a.OpenQuestions {
+			sb.WriteString("- " + q + "\n")
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("_Investigation only: no code was changed._\n")
+	return sb.String()
+}
@@ -0,0 +1,67 @@
+package triage
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/estimate"
+)
+
+const response = "I looked at the export code.\n\n```json\n" + `{
+  "summary": "CSV export drops the last row",
+  "root_cause": "writeRows stops one short of len(rows)",
+  "suspected_files": [
+    {"path": "internal/export/csv.go", "reason": "off-by-one in writeRows"},
+    {"path": "internal/export/csv_test.go"}
+  ],
+  "approach": ["Fix the loop bound", "Add a regression test"],
+  "test_strategy": "Export three rows and count them",
+  "risks": ["Other callers may rely on the short write"],
+  "open_questions": ["Is the header row counted?"]
+}` + "\n```\n"
+
+func TestParse(t *testing.T) {
+	a, err := Parse(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.RootCause != "writeRows stops one short of len(rows)" || len(a.SuspectedFiles) != 2 || len(a.Approach) != 2 {
+		t.Errorf("analysis = %+v", a)
+	}
+	plan := a.Plan()
+	if len(plan.RelevantFiles) != 2 || plan.RelevantFiles[0] != "internal/export/csv.go" || len(plan.Warnings) != 1 {
+		t.Errorf("plan = %+v", plan)
+	}
+
+	for _, bad := range []string{"no json here", "```json\n{\"approach\": []}\n```", "{not json}"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Parse(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestComment(t *testing.T) {
+	a, err := Parse(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := &estimate.Estimate{Complexity: estimate.ComplexityLow, PredictedIterations: 1.5, PredictedCostUSD: 0.8, SuccessLikelihood: 72}
+	got := a.Comment(e)
+	for _, want := range []string{
+		"## 🔎 boatman triage\n\nCSV export drops the last row\n",
+		"### Suspected root cause\nwriteRows stops one short of len(rows)\n",
+		"- `internal/export/csv.go`: off-by-one in writeRows\n- `internal/export/csv_test.go`\n",
+		"1. Fix the loop bound\n2. Add a regression test\n",
+		"low complexity, ~1.5 review iteration(s), ~$0.80 to automate; 72% likely to succeed without human help.\n",
+		"### Open questions\n- Is the header row counted?\n",
+		"no code was changed",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("comment missing %q:\n%s", want, got)
+		}
+	}
+
+	if got := a.Comment(nil); strings.Contains(got, "Estimated effort") {
+		t.Errorf("comment without an estimate has an effort section:\n%s", got)
+	}
+}
@@ -0,0 +1,422 @@
+// Package unidiff parses unified diffs, as produced by git diff, into files,
+// hunks and lines. It understands renames, copies, mode changes, binary
+// files and "\ No newline at end of file" markers, and never fails: input
+// it cannot make sense of is skipped.
+package unidiff
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Status is how a file changed.
+type Status string
+
+const (
+	Modified Status = "modified"
+	Added    Status = "added"
+	Deleted  Status = "deleted"
+	Renamed  Status = "renamed"
+	Copied   Status = "copied"
+)
+
+// File is the diff of one file.
+type File struct {
+	OldPath    string // Empty for added files
+	NewPath    string // Empty for deleted files
+	Status     Status
+	OldMode    string // Set when the mode changed, or the file was deleted
+	NewMode    string // Set when the mode changed, or the file was added
+	Similarity int    // Percent similarity of a rename or copy
+	Binary     bool
+	Hunks      []Hunk
+}
+
+// Path returns the file's path after the change, or before it for
+// deleted files.
+func (f *File) Path() string {
+	if f.NewPath != "" {
+		return f.NewPath
+	}
+	return f.OldPath
+}
+
+// Lines returns every line of every hunk, in order.
+func (f *File) Lines() []Line {
+	var lines []Line
+	for _, h := range f.Hunks {
+		lines = append(lines, h.Lines...)
+	}
+	return lines
+}
+
+// Added returns the content of the file's added lines.
+func (f *File) Added() []string { return f.contents(LineAdded) }
+
+// Removed returns the content of the file's removed lines.
+func (f *File) Removed() []string { return f.contents(LineRemoved) }
+
+// Context returns the content of the file's unchanged context lines.
+func (f *File) Context() []string { return f.contents(LineContext) }
+
+func (f *File) contents(kind LineKind) []string {
+	var out []string
+	for _, h := range f.Hunks {
+		for _, l := range h.Lines {
+			if l.Kind == kind {
+				out = append(out, l.Content)
+			}
+		}
+	}
+	return out
+}
+
+// Hunk is a run of changes with its surrounding context.
+type Hunk struct {
+	OldStart, OldLines int
+	NewStart, NewLines int
+	Section            string // Heading after the @@ range, e.g. the enclosing function
+	Lines              []Line
+}
+
+// LineKind says whether a hunk line was added, removed or unchanged.
+type LineKind int
+
+const (
+	LineContext LineKind = iota
+	LineAdded
+	LineRemoved
+)
+
+// Line is one line of a hunk.
+type Line struct {
+	Kind    LineKind
+	Content string // Without the leading +, - or space
+	// OldLine and NewLine are the line's numbers in the old and new file.
+	// An added line has no OldLine and a removed line has no NewLine of
+	// its own; theirs is the line at which it was added or removed.
+	OldLine, NewLine int
+	// NoNewline is set on the last line of a side without a trailing newline.
+	NoNewline bool
+}
+
+// Changed returns the number of added and removed lines in files.
+func Changed(files []*File) int {
+	n := 0
+	for _, f := range files {
+		for _, h := range f.Hunks {
+			for _, l := range h.Lines {
+				if l.Kind != LineContext {
+					n++
+				}
+			}
+		}
+	}
+	return n
+}
+
+var hunkRe = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@ ?(.*)$`)
+
+// parser holds the state of a Parse.
+type parser struct {
+	files []*File
+	file  *File
+	hunk  *Hunk
+	// Lines the current hunk's header says remain on each side
+	oldLeft, newLeft int
+	oldLine, newLine int
+	implicit         bool // Hunk lines without a hunk header, so without counts
+	binaryPatch      bool // Skipping a GIT binary patch
+}
+
+// Parse parses a unified diff. Git's extended format, plain unified diffs
+// and fragments whose changes lack hunk headers are all accepted.
+func Parse(diff string) []*File {
+	p := &parser{}
+	lines := strings.Split(strings.ReplaceAll(diff, "\r\n", "\n"), "\n")
+	if n := len(lines); n > 0 && lines[n-1] == "" {
+		lines = lines[:n-1]
+	}
+	for _, line := range lines {
+		p.line(line)
+	}
+	p.endFile()
+	return p.files
+}
+
+func (p *parser) line(line string) {
+	if p.hunk != nil {
+		if p.hunkLine(line) {
+			return
+		}
+		p.endHunk()
+	}
+
+	switch {
+	case strings.HasPrefix(line, "diff --git "):
+		p.startFile()
+		p.file.OldPath, p.file.NewPath = gitHeaderPaths(strings.TrimPrefix(line, "diff --git "))
+	case p.binaryPatch:
+		// Base85 data until the next file
+	case strings.HasPrefix(line, "@@"):
+		p.startHunk(line)
+	case strings.HasPrefix(line, "--- "):
+		// Starts a new file in plain diffs, or one whose hunks already ended
+		if p.file == nil || len(p.file.Hunks) > 0 {
+			p.startFile()
+		}
+		p.file.OldPath = headerPath(strings.TrimPrefix(line, "--- "), "a/")
+		if p.file.OldPath == "" {
+			p.file.Status = Added
+		}
+	case strings.HasPrefix(line, "+++ "):
+		if p.file == nil || len(p.file.Hunks) > 0 {
+			p.startFile()
+		}
+		p.file.NewPath = headerPath(strings.TrimPrefix(line, "+++ "), "b/")
+		if p.file.NewPath == "" {
+			p.file.Status = Deleted
+		}
+	case p.file == nil:
+		// Preamble such as a commit message
+	case line != "" && strings.ContainsRune(" +-", rune(line[0])):
+		// Changes without a hunk header, as in hand-written diff fragments
+		p.startImplicitHunk()
+		p.hunkLine(line)
+	default:
+		p.extendedHeader(line)
+	}
+}
+
+// extendedHeader handles the git header lines between diff --git and the
+// first hunk.
+func (p *parser) extendedHeader(line string) {
+	f := p.file
+	key, value, _ := strings.Cut(line, " ")
+	switch key {
+	case "old":
+		f.OldMode = strings.TrimPrefix(value, "mode ")
+	case "new":
+		if mode, ok := strings.CutPrefix(value, "file mode "); ok {
+			f.NewMode, f.Status, f.OldPath = mode, Added, ""
+		} else {
+			f.NewMode = strings.TrimPrefix(value, "mode ")
+		}
+	case "deleted":
+		f.OldMode, f.Status, f.NewPath = strings.TrimPrefix(value, "file mode "), Deleted, ""
+	case "similarity":
+		f.Similarity, _ = strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(value, "index "), "%"))
+	case "rename", "copy":
+		f.Status = Renamed
+		if key == "copy" {
+			f.Status = Copied
+		}
+		if path, ok := strings.CutPrefix(value, "from "); ok {
+			f.OldPath = unquote(path)
+		} else if path, ok := strings.CutPrefix(value, "to "); ok {
+			f.NewPath = unquote(path)
+		}
+	case "Binary":
+		f.Binary = true
+	case "GIT":
+		f.Binary = true
+		p.binaryPatch = true
+	}
+}
+
+// hunkLine consumes line as part of the current hunk, reporting false if
+// the hunk has ended. The hunk header's line counts decide where it ends,
+// so a removed "-- x" line is not mistaken for a new file's header.
+func (p *parser) hunkLine(line string) bool {
+	h := p.hunk
+	if strings.HasPrefix(line, `\`) {
+		if n := len(h.Lines); n > 0 {
+			h.Lines[n-1].NoNewline = true
+		}
+		return true
+	}
+	if p.implicit {
+		if strings.HasPrefix(line, "--- ") || strings.HasPrefix(line, "+++ ") {
+			return false
+		}
+	} else if p.oldLeft <= 0 && p.newLeft <= 0 {
+		return false
+	}
+	if line == "" {
+		// Editors strip the space from blank context lines
+		line = " "
+	}
+	if line[0] != ' ' && line[0] != '+' && line[0] != '-' {
+		return false
+	}
+
+	l := Line{Content: line[1:], OldLine: p.oldLine, NewLine: p.newLine}
+	switch line[0] {
+	case ' ':
+		l.Kind = LineContext
+		p.oldLine++
+		p.newLine++
+		p.oldLeft--
+		p.newLeft--
+	case '+':
+		l.Kind = LineAdded
+		p.newLine++
+		p.newLeft--
+	case '-':
+		l.Kind = LineRemoved
+		p.oldLine++
+		p.oldLeft--
+	}
+	h.Lines = append(h.Lines, l)
+	return true
+}
+
+func (p *parser) startHunk(line string) {
+	if p.file == nil {
+		p.startFile()
+	}
+	m := hunkRe.FindStringSubmatch(line)
+	if m == nil {
+		return
+	}
+	h := &Hunk{
+		OldStart: atoi(m[1]),
+		OldLines: count(m[2]),
+		NewStart: atoi(m[3]),
+		NewLines: count(m[4]),
+		Section:  m[5],
+	}
+	p.hunk = h
+	p.implicit = false
+	p.oldLeft, p.newLeft = h.OldLines, h.NewLines
+	p.oldLine, p.newLine = h.OldStart, h.NewStart
+	// An empty side starts after the given line
+	if h.OldLines == 0 {
+		p.oldLine++
+	}
+	if h.NewLines == 0 {
+		p.newLine++
+	}
+}
+
+// startImplicitHunk starts a hunk for lines with no hunk header, which
+// ends at the next line that cannot be part of it.
+func (p *parser) startImplicitHunk() {
+	p.hunk = &Hunk{}
+	p.implicit = true
+	p.oldLine, p.newLine = max(p.oldLine, 1), max(p.newLine, 1)
+}
+
+func (p *parser) endHunk() {
+	if p.hunk != nil {
+		p.file.Hunks = append(p.file.Hunks, *p.hunk)
+		p.hunk = nil
+		p.implicit = false
+	}
+}
+
+func (p *parser) startFile() {
+	p.endFile()
+	p.file = &File{Status: Modified}
+	p.oldLine, p.newLine = 0, 0
+}
+
+func (p *parser) endFile() {
+	p.endHunk()
+	p.binaryPatch = false
+	if p.file == nil {
+		return
+	}
+	f := p.file
+	p.file = nil
+	// Plain diffs mark added and deleted files only by /dev/null
+	if f.Status == Added {
+		f.OldPath = ""
+	} else if f.Status == Deleted {
+		f.NewPath = ""
+	}
+	if f.Path() != "" {
+		p.files = append(p.files, f)
+	}
+}
+
+// gitHeaderPaths splits the paths of a "diff --git a/x b/y" line. Paths
+// with spaces are ambiguous there, so ---, +++ and rename lines, parsed
+// later, take precedence.
+func gitHeaderPaths(s string) (string, string) {
+	if strings.HasPrefix(s, `"`) {
+		if oldPath, rest, ok := cutQuoted(s); ok {
+			return strings.TrimPrefix(oldPath, "a/"), strings.TrimPrefix(unquote(strings.TrimSpace(rest)), "b/")
+		}
+	}
+	// The same path on both sides: "a/p b/p"
+	if n := len(s); n%2 == 1 {
+		oldPath, newPath := s[:n/2], s[n/2+1:]
+		if strings.HasPrefix(oldPath, "a/") && strings.HasPrefix(newPath, "b/") && oldPath[2:] == newPath[2:] {
+			return oldPath[2:], newPath[2:]
+		}
+	}
+	if i := strings.LastIndex(s, " b/"); i >= 0 {
+		return strings.TrimPrefix(s[:i], "a/"), unquote(s[i+3:])
+	}
+	if oldPath, newPath, ok := strings.Cut(s, " "); ok {
+		return oldPath, newPath
+	}
+	return s, s
+}
+
+// headerPath returns the path of a ---/+++ line, or "" for /dev/null.
+func headerPath(s, prefix string) string {
+	if strings.HasPrefix(s, `"`) {
+		if path, _, ok := cutQuoted(s); ok {
+			s = path
+		}
+	} else if i := strings.IndexByte(s, '\t'); i >= 0 {
+		// Plain diffs follow the path with a timestamp
+		s = s[:i]
+	}
+	s = strings.TrimRight(s, " ")
+	if s == "/dev/null" {
+		return ""
+	}
+	return strings.TrimPrefix(s, prefix)
+}
+
+// cutQuoted unquotes the C-style quoted path git uses for unusual names,
+// returning the rest of s after it.
+func cutQuoted(s string) (string, string, bool) {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			path, err := strconv.Unquote(s[:i+1])
+			if err != nil {
+				return "", "", false
+			}
+			return path, s[i+1:], true
+		}
+	}
+	return "", "", false
+}
+
+func unquote(s string) string {
+	if path, _, ok := cutQuoted(s); ok {
+		return path
+	}
+	return s
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// count parses a hunk range's line count, which is 1 when omitted.
+func count(s string) int {
+	if s == "" {
+		return 1
+	}
+	return atoi(s)
+}
@@ -0,0 +1,167 @@
+package unidiff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseModified(t *testing.T) {
+	diff := `diff --git a/main.go b/main.go
+index 1234567..89abcde 100644
+--- a/main.go
++++ b/main.go
+@@ -1,6 +1,6 @@ package main
+ package main
+
++import "fmt"
+ func main() {
+-	-- decrement
+-	println("hello")
++	fmt.Println("hello")
+ }
+@@ -20 +21 @@ func other() {
+-	return 1
++	return 2
+`
+	files := Parse(diff)
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 file, got %d", len(files))
+	}
+	f := files[0]
+	if f.Path() != "main.go" || f.Status != Modified || len(f.Hunks) != 2 {
+		t.Fatalf("Unexpected file: %+v", f)
+	}
+	if h := f.Hunks[0]; h.Section != "package main" || h.OldLines != 6 || h.NewLines != 6 {
+		t.Errorf("Unexpected hunk header: %+v", h)
+	}
+
+	// A removed "-- x" line is content, not an old-file header
+	if got := f.Removed(); len(got) != 3 || got[0] != "\t-- decrement" {
+		t.Errorf("Unexpected removed lines: %q", got)
+	}
+	if got := f.Added(); len(got) != 3 {
+		t.Errorf("Unexpected added lines: %q", got)
+	}
+	if got := f.Context(); len(got) != 4 || got[1] != "" {
+		t.Errorf("Expected a blank context line with its space stripped, got %q", got)
+	}
+
+	lines := f.Hunks[0].Lines
+	if added := lines[2]; added.Kind != LineAdded || added.NewLine != 3 {
+		t.Errorf("Expected the import added at new line 3, got %+v", added)
+	}
+	if removed := lines[4]; removed.Kind != LineRemoved || removed.OldLine != 4 || removed.NewLine != 5 {
+		t.Errorf("Expected a removal at old line 4 before new line 5, got %+v", removed)
+	}
+	if second := f.Hunks[1].Lines[1]; second.NewLine != 21 {
+		t.Errorf("Expected an omitted count to mean 1 line, got %+v", second)
+	}
+	if n := Changed(files); n != 6 {
+		t.Errorf("Expected 6 changed lines, got %d", n)
+	}
+}
+
+func TestParseExtendedHeaders(t *testing.T) {
+	diff := `diff --git a/old name.go b/new name.go
+similarity index 88%
+rename from old name.go
+rename to new name.go
+index 1111111..2222222 100644
+--- a/old name.go
++++ b/new name.go
+@@ -1,2 +1,2 @@
+ package x
+-const a = 1
+\ No newline at end of file
++const a = 2
+\ No newline at end of file
+diff --git a/run.sh b/run.sh
+old mode 100644
+new mode 100755
+diff --git a/gone.go b/gone.go
+deleted file mode 100644
+index 3333333..0000000
+--- a/gone.go
++++ /dev/null
+@@ -1 +0,0 @@
+-package gone
+diff --git a/logo.png b/logo.png
+new file mode 100644
+index 0000000..4444444
+Binary files /dev/null and b/logo.png differ
+diff --git "a/tab\there.go" "b/tab\there.go"
+new file mode 100644
+--- /dev/null
++++ "b/tab\there.go"
+@@ -0,0 +1 @@
++package tab
+`
+	files := Parse(diff)
+	if len(files) != 5 {
+		t.Fatalf("Expected 5 files, got %d", len(files))
+	}
+
+	rename := files[0]
+	if rename.Status != Renamed || rename.OldPath != "old name.go" || rename.NewPath != "new name.go" || rename.Similarity != 88 {
+		t.Errorf("Unexpected rename: %+v", rename)
+	}
+	if lines := rename.Lines(); !lines[1].NoNewline || !lines[2].NoNewline || lines[0].NoNewline {
+		t.Errorf("Expected both sides' last lines marked without newline: %+v", lines)
+	}
+
+	if mode := files[1]; mode.OldMode != "100644" || mode.NewMode != "100755" || len(mode.Hunks) != 0 || mode.Path() != "run.sh" {
+		t.Errorf("Unexpected mode change: %+v", mode)
+	}
+	if gone := files[2]; gone.Status != Deleted || gone.NewPath != "" || gone.Path() != "gone.go" || len(gone.Removed()) != 1 {
+		t.Errorf("Unexpected deletion: %+v", gone)
+	}
+	if logo := files[3]; logo.Status != Added || !logo.Binary || logo.OldPath != "" || logo.Path() != "logo.png" {
+		t.Errorf("Unexpected binary file: %+v", logo)
+	}
+	if quoted := files[4]; quoted.Path() != "tab\there.go" || quoted.Hunks[0].Lines[0].NewLine != 1 {
+		t.Errorf("Unexpected quoted path: %+v", quoted)
+	}
+}
+
+func TestParsePlainDiff(t *testing.T) {
+	diff := "Some preamble\n" +
+		"--- a/one.txt\t2026-01-01 00:00:00\n" +
+		"+++ b/one.txt\t2026-01-02 00:00:00\n" +
+		"@@ -1 +1 @@\n" +
+		"-a\n" +
+		"+b\n" +
+		"--- /dev/null\n" +
+		"+++ b/two.txt\n" +
+		"@@ -0,0 +1,2 @@\n" +
+		"+x\n" +
+		"+y\n"
+	files := Parse(diff)
+	if len(files) != 2 || files[0].Path() != "one.txt" || files[1].Status != Added || len(files[1].Added()) != 2 {
+		t.Fatalf("Unexpected files: %+v", files)
+	}
+
+	// Fragments without hunk headers still yield their changes
+	files = Parse("+++ b/app.js\n+debugger;\n-old();\n+++ b/b.js\n+x\n")
+	if len(files) != 2 || len(files[0].Added()) != 1 || len(files[0].Removed()) != 1 || files[1].Lines()[0].NewLine != 1 {
+		t.Fatalf("Unexpected fragment files: %+v", files)
+	}
+}
+
+func FuzzParse(f *testing.F) {
+	f.Add("diff --git a/x b/x\n--- a/x\n+++ b/x\n@@ -1,2 +1,2 @@\n a\n-b\n+c\n\\ No newline at end of file\n")
+	f.Add("diff --git \"a/\\\"q\" b/y\nrename from \"\\\"q\"\nGIT binary patch\nliteral 0\n")
+	f.Add("@@ -1 +1 @@\n--- \n+++ \n@@ -x @@\n")
+	f.Fuzz(func(t *testing.T, diff string) {
+		files := Parse(diff)
+		changed := 0
+		for _, file := range files {
+			if file.Path() == "" {
+				t.Fatalf("File without a path in %q", diff)
+			}
+			changed += len(file.Added()) + len(file.Removed())
+		}
+		if changed != Changed(files) || changed > strings.Count(diff, "\n")+1 {
+			t.Fatalf("Inconsistent line counts in %q", diff)
+		}
+	})
+}
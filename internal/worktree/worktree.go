@@ -2,28 +2,78 @@
 package worktree
 
 import (
+	"crypto/sha1"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"sort"
 	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/gitops"
 )
 
 // Manager handles git worktree operations.
 type Manager struct {
 	repoPath     string
 	worktreeBase string
+	git          *gitops.Repo
+	opts         Options
 }
 
+// Options configures how worktrees are created.
+type Options struct {
+	// Sparse creates worktrees with only root files checked out.
+	// Directories are added later with ExpandSparse.
+	Sparse bool
+
+	// FetchDepth limits history fetched for the base branch in shallow
+	// clones (0 = normal fetch). Ignored for full clones, which it would
+	// otherwise convert to shallow.
+	FetchDepth int
+
+	// Root places worktrees under Root/<repo>-<hash>/ instead of
+	// <repo>/.worktrees/ (e.g. a fast scratch disk). "~/" is expanded.
+	Root string
+
+	// Output receives progress output; nil means stdout.
+	Output io.Writer
+}
+
+const (
+	// maxNameLen caps a single worktree directory name, well under the
+	// 255-byte NAME_MAX of common filesystems.
+	maxNameLen = 100
+
+	// maxWorktreePathLen caps the worktree root path so files deep inside the
+	// repo stay under macOS's 1024-byte PATH_MAX.
+	maxWorktreePathLen = 400
+)
+
 // Worktree represents an active git worktree.
 type Worktree struct {
 	Path       string
 	BranchName string
 	BaseBranch string
+	Sparse     bool // Sparse checkout is enabled
+
+	// Submodules is true when the repo declares submodules.
+	Submodules bool
+
+	// LFSPatterns are the Git LFS-tracked patterns from .gitattributes.
+	LFSPatterns []string
+	// LFSReady is true when git-lfs is installed and configured for the worktree.
+	LFSReady bool
 }
 
 // New creates a new worktree manager.
 func New(repoPath string) (*Manager, error) {
+	return NewWithOptions(repoPath, Options{})
+}
+
+// NewWithOptions creates a worktree manager with creation options.
+func NewWithOptions(repoPath string, opts Options) (*Manager, error) {
 	absPath, err := filepath.Abs(repoPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get absolute path: %w", err)
@@ -34,29 +84,67 @@ func New(repoPath string) (*Manager, error) {
 		return nil, fmt.Errorf("not a git repository: %s", absPath)
 	}
 
-	worktreeBase := filepath.Join(absPath, ".worktrees")
+	worktreeBase, err := BaseDir(absPath, opts.Root)
+	if err != nil {
+		return nil, err
+	}
 
 	return &Manager{
 		repoPath:     absPath,
 		worktreeBase: worktreeBase,
+		git:          gitops.New(absPath),
+		opts:         opts,
 	}, nil
 }
 
+// BaseDir returns the directory worktrees of repoPath are created in.
+// Without root this is <repo>/.worktrees; with root, worktrees are namespaced
+// by repo name plus a hash of its path so same-named repos don't collide.
+func BaseDir(repoPath, root string) (string, error) {
+	if root == "" {
+		return filepath.Join(repoPath, ".worktrees"), nil
+	}
+
+	if rest, ok := strings.CutPrefix(root, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to expand worktree root: %w", err)
+		}
+		root = filepath.Join(home, rest)
+	}
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve worktree root: %w", err)
+	}
+
+	sum := sha1.Sum([]byte(repoPath))
+	namespace := fmt.Sprintf("%s-%s", safeName(filepath.Base(repoPath)), hex.EncodeToString(sum[:4]))
+	return filepath.Join(root, namespace), nil
+}
+
 // Create creates a new worktree for the given branch name.
 // If the worktree/branch already exists, it reuses it.
 func (m *Manager) Create(branchName, baseBranch string) (*Worktree, error) {
 	// Sanitize branch name for filesystem
-	safeBranchName := sanitizeBranchName(branchName)
+	safeBranchName := safeName(sanitizeBranchName(branchName))
 	worktreePath := filepath.Join(m.worktreeBase, safeBranchName)
+	if len(worktreePath) > maxWorktreePathLen {
+		return nil, fmt.Errorf("worktree path too long (%d > %d bytes): %s; set worktree.root to a shorter directory",
+			len(worktreePath), maxWorktreePathLen, worktreePath)
+	}
 
 	// Check if worktree already exists
 	if _, err := os.Stat(worktreePath); err == nil {
-		fmt.Printf("♻️  Reusing existing worktree: %s\n", worktreePath)
-		return &Worktree{
+		fmt.Fprintf(m.output(), "♻️  Reusing existing worktree: %s\n", worktreePath)
+		wt := &Worktree{
 			Path:       worktreePath,
 			BranchName: branchName,
 			BaseBranch: baseBranch,
-		}, nil
+			Sparse:     gitops.New(worktreePath).IsSparse(),
+		}
+		wt.setupSubmodules(m.output())
+		wt.setupLFS(m.output())
+		return wt, nil
 	}
 
 	// Ensure worktree base directory exists
@@ -65,42 +153,172 @@ func (m *Manager) Create(branchName, baseBranch string) (*Worktree, error) {
 	}
 
 	// Fetch latest from remote
-	if err := m.runGit("fetch", "origin", baseBranch); err != nil {
+	fetchArgs := []string{baseBranch}
+	if m.opts.FetchDepth > 0 && m.git.IsShallow() {
+		fetchArgs = append(fetchArgs, fmt.Sprintf("--depth=%d", m.opts.FetchDepth))
+	}
+	if err := m.git.Fetch("origin", fetchArgs...); err != nil {
 		return nil, fmt.Errorf("failed to fetch: %w", err)
 	}
 
 	// Check if branch already exists
-	branchExists := m.runGit("show-ref", "--verify", "--quiet", fmt.Sprintf("refs/heads/%s", branchName)) == nil
+	branchExists := m.git.RefExists(fmt.Sprintf("refs/heads/%s", branchName))
+
+	// Sparse worktrees skip the initial checkout and populate only root files
+	var addFlags []string
+	if m.opts.Sparse {
+		addFlags = append(addFlags, "--no-checkout")
+	}
 
 	if branchExists {
 		// Branch exists but worktree doesn't - create worktree for existing branch
-		if err := m.runGit("worktree", "add", worktreePath, branchName); err != nil {
+		if err := m.git.WorktreeAdd(worktreePath, branchName, false, "", addFlags...); err != nil {
 			return nil, fmt.Errorf("failed to create worktree for existing branch: %w", err)
 		}
 	} else {
 		// Create the worktree with a new branch
-		if err := m.runGit("worktree", "add", "-b", branchName, worktreePath, fmt.Sprintf("origin/%s", baseBranch)); err != nil {
+		if err := m.git.WorktreeAdd(worktreePath, branchName, true, fmt.Sprintf("origin/%s", baseBranch), addFlags...); err != nil {
 			return nil, fmt.Errorf("failed to create worktree: %w", err)
 		}
 	}
 
-	return &Worktree{
+	wt := &Worktree{
 		Path:       worktreePath,
 		BranchName: branchName,
 		BaseBranch: baseBranch,
-	}, nil
+	}
+
+	if m.opts.Sparse {
+		wtGit := gitops.New(worktreePath)
+		if err := wtGit.SparseCheckoutSet(); err != nil {
+			return nil, fmt.Errorf("failed to enable sparse checkout: %w", err)
+		}
+		if err := wtGit.Checkout(); err != nil {
+			return nil, fmt.Errorf("failed to check out sparse worktree: %w", err)
+		}
+		wt.Sparse = true
+	}
+
+	wt.setupSubmodules(m.output())
+	wt.setupLFS(m.output())
+	return wt, nil
+}
+
+// output returns where progress output goes.
+func (m *Manager) output() io.Writer {
+	if m.opts.Output == nil {
+		return os.Stdout
+	}
+	return m.opts.Output
+}
+
+// setupSubmodules initializes submodules, which new worktrees leave empty.
+// Failure is reported but not fatal: most tasks don't touch submodules.
+func (wt *Worktree) setupSubmodules(out io.Writer) {
+	wtGit := gitops.New(wt.Path)
+	if !wtGit.HasSubmodules() {
+		return
+	}
+	wt.Submodules = true
+	if err := wtGit.SubmoduleUpdate(); err != nil {
+		fmt.Fprintf(out, "   ⚠️  Failed to initialize submodules: %v\n", err)
+	}
+}
+
+// setupLFS configures Git LFS in worktrees of repos that track LFS files,
+// so LFS files are real content rather than pointers. Without git-lfs the
+// worktree is still usable but LFSReady stays false.
+func (wt *Worktree) setupLFS(out io.Writer) {
+	wtGit := gitops.New(wt.Path)
+	wt.LFSPatterns = wtGit.LFSPatterns()
+	if len(wt.LFSPatterns) == 0 {
+		return
+	}
+
+	if !wtGit.LFSAvailable() {
+		fmt.Fprintf(out, "   ⚠️  Repo tracks %d pattern(s) with Git LFS but git-lfs is not installed\n", len(wt.LFSPatterns))
+		return
+	}
+	if err := wtGit.LFSInstall(); err != nil {
+		fmt.Fprintf(out, "   ⚠️  Failed to set up Git LFS: %v\n", err)
+		return
+	}
+	wt.LFSReady = true
+}
+
+// ExpandSparse adds the directories containing paths to a sparse worktree.
+// File paths are expanded to their parent directory. No-op if not sparse.
+func (wt *Worktree) ExpandSparse(paths ...string) error {
+	if !wt.Sparse {
+		return nil
+	}
+	dirs := sparseDirs(paths)
+	if len(dirs) == 0 {
+		return nil
+	}
+	wtGit := gitops.New(wt.Path)
+	if err := wtGit.SparseCheckoutAdd(dirs...); err != nil {
+		return err
+	}
+	// Newly checked-out directories may contain submodules
+	if wt.Submodules {
+		return wtGit.SubmoduleUpdate()
+	}
+	return nil
+}
+
+// DisableSparse switches a sparse worktree to a full checkout.
+func (wt *Worktree) DisableSparse() error {
+	if !wt.Sparse {
+		return nil
+	}
+	wtGit := gitops.New(wt.Path)
+	if err := wtGit.SparseCheckoutDisable(); err != nil {
+		return err
+	}
+	wt.Sparse = false
+	if wt.Submodules {
+		return wtGit.SubmoduleUpdate()
+	}
+	return nil
+}
+
+// sparseDirs converts paths to unique, sorted directories for cone mode.
+// Paths that look like files (have an extension) map to their parent.
+func sparseDirs(paths []string) []string {
+	seen := make(map[string]bool)
+	var dirs []string
+	for _, p := range paths {
+		p = filepath.ToSlash(filepath.Clean(strings.TrimSpace(p)))
+		p = strings.TrimPrefix(p, "./")
+		if p == "" || p == "." || strings.HasPrefix(p, "../") || filepath.IsAbs(p) {
+			continue
+		}
+		if filepath.Ext(p) != "" {
+			p = filepath.ToSlash(filepath.Dir(p))
+			if p == "." {
+				continue // Root files are always checked out
+			}
+		}
+		if !seen[p] {
+			seen[p] = true
+			dirs = append(dirs, p)
+		}
+	}
+	sort.Strings(dirs)
+	return dirs
 }
 
 // Remove removes a worktree and its branch.
 func (m *Manager) Remove(wt *Worktree) error {
-	if err := m.runGit("worktree", "remove", wt.Path, "--force"); err != nil {
+	if err := m.git.WorktreeRemove(wt.Path, true); err != nil {
 		return fmt.Errorf("failed to remove worktree: %w", err)
 	}
 
 	// Optionally delete the branch
-	if err := m.runGit("branch", "-D", wt.BranchName); err != nil {
+	if err := m.git.DeleteBranch(wt.BranchName, true); err != nil {
 		// Branch deletion failure is not critical
-		fmt.Printf("Warning: failed to delete branch %s: %v\n", wt.BranchName, err)
+		fmt.Fprintf(m.output(), "Warning: failed to delete branch %s: %v\n", wt.BranchName, err)
 	}
 
 	return nil
@@ -108,45 +326,32 @@ func (m *Manager) Remove(wt *Worktree) error {
 
 // List returns all active worktrees.
 func (m *Manager) List() ([]*Worktree, error) {
-	cmd := exec.Command("git", "worktree", "list", "--porcelain")
-	cmd.Dir = m.repoPath
-
-	output, err := cmd.Output()
+	entries, err := m.git.WorktreeList()
 	if err != nil {
 		return nil, fmt.Errorf("failed to list worktrees: %w", err)
 	}
 
 	var worktrees []*Worktree
-	lines := strings.Split(string(output), "\n")
-
-	var currentPath, currentBranch string
-	for _, line := range lines {
-		if strings.HasPrefix(line, "worktree ") {
-			currentPath = strings.TrimPrefix(line, "worktree ")
-		} else if strings.HasPrefix(line, "branch ") {
-			currentBranch = strings.TrimPrefix(line, "branch refs/heads/")
-		} else if line == "" && currentPath != "" {
-			if strings.HasPrefix(currentPath, m.worktreeBase) {
-				worktrees = append(worktrees, &Worktree{
-					Path:       currentPath,
-					BranchName: currentBranch,
-				})
-			}
-			currentPath = ""
-			currentBranch = ""
+	for _, entry := range entries {
+		if strings.HasPrefix(entry.Path, m.worktreeBase) {
+			worktrees = append(worktrees, &Worktree{
+				Path:       entry.Path,
+				BranchName: entry.Branch,
+			})
 		}
 	}
 
 	return worktrees, nil
 }
 
-// runGit executes a git command in the repo directory.
-func (m *Manager) runGit(args ...string) error {
-	cmd := exec.Command("git", args...)
-	cmd.Dir = m.repoPath
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-	return cmd.Run()
+// safeName shortens names longer than maxNameLen, keeping them unique by
+// replacing the tail with a hash of the full name.
+func safeName(name string) string {
+	if len(name) <= maxNameLen {
+		return name
+	}
+	sum := sha1.Sum([]byte(name))
+	return name[:maxNameLen-9] + "-" + hex.EncodeToString(sum[:4])
 }
 
 // sanitizeBranchName makes a branch name safe for filesystem use.
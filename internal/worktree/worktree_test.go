@@ -0,0 +1,214 @@
+package worktree
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSparseDirs(t *testing.T) {
+	got := sparseDirs([]string{
+		"internal/agent/agent.go",
+		"internal/agent/",
+		"./docs",
+		"README.md",
+		"../outside",
+		"",
+		"app/models/user.rb",
+	})
+
+	want := []string{"app/models", "docs", "internal/agent"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestSanitizeBranchName(t *testing.T) {
+	if got := sanitizeBranchName("feature/ENG-1 fix:thing"); got != "feature-ENG-1-fix-thing" {
+		t.Errorf("Unexpected sanitized name: %s", got)
+	}
+}
+
+func TestBaseDir(t *testing.T) {
+	base, _ := BaseDir("/src/app", "")
+	if base != "/src/app/.worktrees" {
+		t.Errorf("Expected default base in repo, got %s", base)
+	}
+
+	a, _ := BaseDir("/src/app", "/scratch")
+	b, _ := BaseDir("/other/app", "/scratch")
+	if filepath.Dir(a) != "/scratch" || !strings.HasPrefix(filepath.Base(a), "app-") {
+		t.Errorf("Expected /scratch/app-<hash>, got %s", a)
+	}
+	if a == b {
+		t.Error("Expected same-named repos to get different namespaces")
+	}
+
+	home, _ := os.UserHomeDir()
+	if h, _ := BaseDir("/src/app", "~/wt"); !strings.HasPrefix(h, filepath.Join(home, "wt")) {
+		t.Errorf("Expected ~ to expand, got %s", h)
+	}
+}
+
+func TestSafeName(t *testing.T) {
+	if safeName("ENG-123-fix") != "ENG-123-fix" {
+		t.Error("Expected short names to be unchanged")
+	}
+
+	long := strings.Repeat("a", 300)
+	got := safeName(long)
+	if len(got) != maxNameLen {
+		t.Errorf("Expected %d bytes, got %d", maxNameLen, len(got))
+	}
+	if got == safeName(long+"b") {
+		t.Error("Expected truncated names to stay unique")
+	}
+}
+
+func TestCreateWithRoot(t *testing.T) {
+	repo := setupRemoteRepo(t)
+	root := t.TempDir()
+
+	mgr, err := NewWithOptions(repo, Options{Root: root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, err := mgr.Create("ENG-42/add-thing", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(wt.Path, root) || filepath.Base(wt.Path) != "ENG-42-add-thing" {
+		t.Errorf("Expected worktree under %s, got %s", root, wt.Path)
+	}
+
+	list, err := mgr.List()
+	if err != nil || len(list) != 1 {
+		t.Errorf("Expected 1 listed worktree, got %d (%v)", len(list), err)
+	}
+}
+
+// runGit runs a git command in dir, failing the test on error.
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@t", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@t")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+// setupRemoteRepo creates a repo with an "origin" remote containing a few directories.
+func setupRemoteRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	root := t.TempDir()
+	origin := filepath.Join(root, "origin")
+	repo := filepath.Join(root, "repo")
+
+	os.MkdirAll(origin, 0755)
+	runGit(t, origin, "init", "-q", "-b", "main")
+	for _, f := range []string{"README.md", "pkg/a/a.go", "pkg/b/b.go"} {
+		path := filepath.Join(origin, f)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte("x\n"), 0644)
+	}
+	runGit(t, origin, "add", "-A")
+	runGit(t, origin, "commit", "-q", "-m", "init")
+	runGit(t, root, "clone", "-q", origin, repo)
+	return repo
+}
+
+func TestCreateSparse(t *testing.T) {
+	repo := setupRemoteRepo(t)
+
+	mgr, err := NewWithOptions(repo, Options{Sparse: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, err := mgr.Create("feature/sparse", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !wt.Sparse {
+		t.Fatal("Expected sparse worktree")
+	}
+
+	exists := func(rel string) bool {
+		_, err := os.Stat(filepath.Join(wt.Path, rel))
+		return err == nil
+	}
+	if !exists("README.md") || exists("pkg/a/a.go") {
+		t.Error("Expected only root files before expansion")
+	}
+
+	if err := wt.ExpandSparse("pkg/a/a.go"); err != nil {
+		t.Fatal(err)
+	}
+	if !exists("pkg/a/a.go") || exists("pkg/b/b.go") {
+		t.Error("Expected only pkg/a after expansion")
+	}
+
+	if err := wt.DisableSparse(); err != nil {
+		t.Fatal(err)
+	}
+	if !exists("pkg/b/b.go") || wt.Sparse {
+		t.Error("Expected full checkout after disabling sparse")
+	}
+}
+
+func TestCreateErrorKeepsStderr(t *testing.T) {
+	repo := setupRemoteRepo(t)
+
+	mgr, err := New(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = mgr.Create("feature/missing-base", "no-such-branch")
+	if err == nil || !strings.Contains(err.Error(), "no-such-branch") || !strings.Contains(err.Error(), "fatal:") {
+		t.Errorf("Expected git's stderr in the error, got %v", err)
+	}
+}
+
+func TestCreateInitializesSubmodules(t *testing.T) {
+	repo := setupRemoteRepo(t)
+	root := filepath.Dir(repo)
+
+	// Local file:// submodules are blocked by default in recent git
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "protocol.file.allow")
+	t.Setenv("GIT_CONFIG_VALUE_0", "always")
+
+	lib := filepath.Join(root, "lib")
+	os.MkdirAll(lib, 0755)
+	runGit(t, lib, "init", "-q", "-b", "main")
+	os.WriteFile(filepath.Join(lib, "lib.go"), []byte("package lib\n"), 0644)
+	runGit(t, lib, "add", "-A")
+	runGit(t, lib, "commit", "-q", "-m", "lib")
+
+	origin := filepath.Join(root, "origin")
+	runGit(t, origin, "submodule", "add", "-q", lib, "vendor/lib")
+	runGit(t, origin, "commit", "-q", "-m", "add submodule")
+
+	mgr, err := New(repo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt, err := mgr.Create("feature/submodules", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !wt.Submodules {
+		t.Error("Expected submodules to be detected")
+	}
+	if _, err := os.Stat(filepath.Join(wt.Path, "vendor/lib/lib.go")); err != nil {
+		t.Errorf("Expected submodule to be checked out: %v", err)
+	}
+}
@@ -0,0 +1,212 @@
+package testenv
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/philjestin/boatmanmode/internal/agent"
+	"github.com/philjestin/boatmanmode/internal/config"
+	"github.com/philjestin/boatmanmode/internal/task"
+	"github.com/spf13/viper"
+)
+
+// Agent session names the fake claude matches turns against. A turn for
+// SessionRefactor, SessionReviewer or SessionTestFix answers every
+// iteration's session (refactor-1, reviewer-2, test-fix-1, ...) until used.
+const (
+	SessionPlanner  = "planner"
+	SessionExecutor = "executor"
+	SessionRefactor = "refactor"
+	SessionReviewer = "reviewer"
+	SessionTestFix  = "test-fix"
+	SessionRetro    = "retro"
+)
+
+// Turn is one scripted reply of the fake claude.
+type Turn struct {
+	// Session is the prefix of the agent session this turn answers.
+	// Empty answers whichever agent calls next.
+	Session string
+
+	// Response is the text of the reply.
+	Response string
+
+	// Files are written relative to the agent's working directory before
+	// replying, the way the executor's tools edit the worktree.
+	Files map[string]string
+
+	// CostUSD is reported as the cost of the call.
+	CostUSD float64
+}
+
+// ScriptClaude appends turns to the fake claude's script. Each turn is
+// used once, in order among the turns for its session.
+func (e *Environment) ScriptClaude(turns ...Turn) {
+	e.t.Helper()
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, turn := range turns {
+		e.claudeTurns++
+		dir := filepath.Join(e.RootDir, "claude", "turns", fmt.Sprintf("%04d", e.claudeTurns))
+		streamJSON := fmt.Sprintf(`{"type":"result","message":{"content":[{"type":"text","text":%s}]},"total_cost_usd":%g}`,
+			jsonEscape(turn.Response), turn.CostUSD)
+
+		files := map[string]string{
+			"session":       turn.Session,
+			"response.txt":  turn.Response,
+			"response.json": streamJSON + "\n",
+		}
+		for path, content := range turn.Files {
+			files[filepath.Join("files", path)] = content
+		}
+		for path, content := range files {
+			fullPath := filepath.Join(dir, path)
+			if err := os.MkdirAll(filepath.Dir(fullPath), 0755); err != nil {
+				e.t.Fatalf("Failed to create dir: %v", err)
+			}
+			if err := os.WriteFile(fullPath, []byte(content), 0644); err != nil {
+				e.t.Fatalf("Failed to write claude turn: %v", err)
+			}
+		}
+	}
+}
+
+// ClaudeSessions returns the session of every call to the fake claude,
+// in order.
+func (e *Environment) ClaudeSessions() []string {
+	data, err := os.ReadFile(filepath.Join(e.RootDir, "claude_sessions.log"))
+	if err != nil {
+		return nil
+	}
+	return strings.Fields(string(data))
+}
+
+// UnusedTurns returns how many scripted turns no agent asked for.
+func (e *Environment) UnusedTurns() int {
+	dirs, _ := filepath.Glob(filepath.Join(e.RootDir, "claude", "turns", "*"))
+	unused := 0
+	for _, dir := range dirs {
+		if _, err := os.Stat(filepath.Join(dir, "used")); err != nil {
+			unused++
+		}
+	}
+	return unused
+}
+
+// PullRequest is a PR created through the fake gh.
+type PullRequest struct {
+	Title string
+	Body  string
+	Base  string
+}
+
+// PullRequests returns the PRs created through the fake gh, in order.
+func (e *Environment) PullRequests() []PullRequest {
+	var prs []PullRequest
+	for i := 1; ; i++ {
+		data, err := os.ReadFile(filepath.Join(e.RootDir, "gh", fmt.Sprintf("pr-%d", i)))
+		if err != nil {
+			return prs
+		}
+		var pr PullRequest
+		args := strings.Split(strings.TrimSuffix(string(data), "\x00"), "\x00")
+		for j := 0; j+1 < len(args); j++ {
+			switch args[j] {
+			case "--title":
+				pr.Title = args[j+1]
+			case "--body":
+				pr.Body = args[j+1]
+			case "--base":
+				pr.Base = args[j+1]
+			}
+		}
+		prs = append(prs, pr)
+	}
+}
+
+// Config returns configuration for running boatman in the environment:
+// the settings in configFile, if given, with claude pointed at the fake
+// and run headless. Tests can check their own .boatman.yaml this way.
+// Viper is reset when the test ends.
+func (e *Environment) Config(configFile string) *config.Config {
+	e.t.Helper()
+	viper.Reset()
+	e.t.Cleanup(viper.Reset)
+	if configFile != "" {
+		viper.SetConfigFile(configFile)
+		if err := viper.ReadInConfig(); err != nil {
+			e.t.Fatalf("Failed to read config %s: %v", configFile, err)
+		}
+	}
+
+	cfg := config.LoadUnvalidated()
+	cfg.LinearKey = "test-api-key"
+	cfg.GitHub.Token = "" // PRs go through the fake gh
+	cfg.BaseBranch = "main"
+	cfg.Claude.Command = filepath.Join(e.BinDir, "claude")
+	cfg.Claude.Runner = "direct"
+	cfg.Worktree.Root = e.WorktreeDir
+	cfg.Retry.InitialDelay = 0
+	return cfg
+}
+
+// Enter runs the rest of the test as boatman would run in the repo: from
+// RepoDir, with the fake claude and gh first on PATH and HOME in HomeDir.
+// Restored when the test ends.
+func (e *Environment) Enter() {
+	e.t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		e.t.Fatalf("Failed to get working directory: %v", err)
+	}
+	if err := os.Chdir(e.RepoDir); err != nil {
+		e.t.Fatalf("Failed to enter repo: %v", err)
+	}
+	e.t.Cleanup(func() { os.Chdir(wd) })
+
+	e.t.Setenv("PATH", e.BinDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	// Keep the build cache when moving HOME, or go test starts cold
+	if os.Getenv("GOCACHE") == "" {
+		if out, err := exec.Command("go", "env", "GOCACHE").Output(); err == nil {
+			e.t.Setenv("GOCACHE", strings.TrimSpace(string(out)))
+		}
+	}
+	e.t.Setenv("HOME", e.HomeDir)
+}
+
+// Work runs the whole workflow for t in the environment with the settings
+// in configFile, as Config and Enter set them up. It is how repos outside
+// boatman, which cannot import its internal packages, test their configs.
+func (e *Environment) Work(ctx context.Context, configFile string, t task.Task) (*agent.WorkResult, error) {
+	e.t.Helper()
+	cfg := e.Config(configFile)
+	e.Enter()
+	a, err := agent.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return a.Work(ctx, t)
+}
+
+// RemoteLog returns the subjects of commits on branch in the remote.
+func (e *Environment) RemoteLog(branch string) []string {
+	out, err := exec.Command("git", "-C", e.RemoteDir, "log", "--format=%s", branch).Output()
+	if err != nil {
+		return nil
+	}
+	return strings.Split(strings.TrimSpace(string(out)), "\n")
+}
+
+// RemoteFile returns a file's content on branch in the remote.
+func (e *Environment) RemoteFile(branch, path string) string {
+	out, err := exec.Command("git", "-C", e.RemoteDir, "show", branch+":"+path).Output()
+	if err != nil {
+		e.t.Fatalf("Failed to read %s on %s: %v", path, branch, err)
+	}
+	return string(out)
+}
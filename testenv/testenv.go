@@ -1,6 +1,8 @@
 // Package testenv provides an end-to-end test environment for boatman.
-// It sets up mock servers and fixtures to enable full workflow testing
-// without hitting real APIs.
+// It sets up a git repository with a remote, a mock Linear server, a
+// scripted fake claude and a fake gh, so the full workflow can be tested
+// without tmux, network or real APIs. It is outside internal/ so other
+// repos can run boatman against their own config in tests.
 package testenv
 
 import (
@@ -15,6 +17,9 @@ import (
 	"strings"
 	"sync"
 	"testing"
+
+	"github.com/philjestin/boatmanmode/internal/linear"
+	"github.com/philjestin/boatmanmode/internal/task"
 )
 
 // Environment represents a complete test environment for e2e testing.
@@ -24,8 +29,10 @@ type Environment struct {
 	// Paths
 	RootDir     string // Temporary root directory
 	RepoDir     string // Git repository directory
+	RemoteDir   string // Bare repository RepoDir pushes to as origin
 	WorktreeDir string // Worktree directory
 	BinDir      string // Mock binaries directory
+	HomeDir     string // HOME for runs, holding checkpoints and memory
 
 	// Mock servers
 	LinearServer *httptest.Server
@@ -33,8 +40,7 @@ type Environment struct {
 
 	// Mock responses
 	linearResponses map[string]interface{}
-	claudeResponses []string
-	claudeIndex     int
+	claudeTurns     int
 	mu              sync.Mutex
 
 	// Recorded interactions
@@ -58,10 +64,11 @@ func New(t *testing.T) *Environment {
 		t:               t,
 		RootDir:         rootDir,
 		RepoDir:         filepath.Join(rootDir, "repo"),
+		RemoteDir:       filepath.Join(rootDir, "remote.git"),
 		WorktreeDir:     filepath.Join(rootDir, "worktrees"),
 		BinDir:          filepath.Join(rootDir, "bin"),
+		HomeDir:         filepath.Join(rootDir, "home"),
 		linearResponses: make(map[string]interface{}),
-		claudeResponses: []string{},
 		ClaudePrompts:   []string{},
 		LinearQueries:   []string{},
 	}
@@ -71,7 +78,7 @@ func New(t *testing.T) *Environment {
 	})
 
 	// Create directories
-	for _, dir := range []string{env.RepoDir, env.WorktreeDir, env.BinDir} {
+	for _, dir := range []string{env.RepoDir, env.WorktreeDir, env.BinDir, env.HomeDir} {
 		if err := os.MkdirAll(dir, 0755); err != nil {
 			t.Fatalf("Failed to create dir %s: %v", dir, err)
 		}
@@ -96,13 +103,15 @@ func (e *Environment) Cleanup() {
 	}
 }
 
-// setupGitRepo creates a git repository with test files.
+// setupGitRepo creates a git repository with test files, pushed to a
+// bare origin on main.
 func (e *Environment) setupGitRepo() {
 	e.t.Helper()
 
 	// Initialize git repo
 	cmds := [][]string{
 		{"git", "init"},
+		{"git", "symbolic-ref", "HEAD", "refs/heads/main"},
 		{"git", "config", "user.email", "test@example.com"},
 		{"git", "config", "user.name", "Test User"},
 	}
@@ -177,6 +186,19 @@ func TestAdd(t *testing.T) {
 	if out, err := cmd.CombinedOutput(); err != nil {
 		e.t.Fatalf("git commit failed: %v\n%s", err, out)
 	}
+
+	// Push to a bare origin so worktrees can fetch and runs can push
+	for _, args := range [][]string{
+		{"git", "init", "--bare", e.RemoteDir},
+		{"git", "remote", "add", "origin", e.RemoteDir},
+		{"git", "push", "-u", "origin", "main"},
+	} {
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Dir = e.RepoDir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			e.t.Fatalf("Failed to run %v: %v\n%s", args, err, out)
+		}
+	}
 }
 
 // setupLinearMock creates a mock Linear GraphQL server.
@@ -239,7 +261,10 @@ func (e *Environment) setupLinearMock() {
 	})
 }
 
-// setupClaudeMock creates a mock claude CLI.
+// setupClaudeMock creates a mock claude CLI. It answers with the first
+// unused turn scripted for the calling agent's session (see ScriptClaude),
+// writing the turn's files first the way an agent's tools would, and
+// falls back to the response set by SetClaudeResponse.
 func (e *Environment) setupClaudeMock() {
 	e.t.Helper()
 
@@ -247,19 +272,46 @@ func (e *Environment) setupClaudeMock() {
 	mockScript := fmt.Sprintf(`#!/bin/bash
 # Mock Claude CLI for testing
 
+ROOT="%s"
+SESSION="${BOATMAN_SESSION:-}"
+
 # Record the prompt
-PROMPT_FILE="%s/claude_prompts.log"
-echo "---PROMPT---" >> "$PROMPT_FILE"
+PROMPT_FILE="$ROOT/claude_prompts.log"
+echo "---PROMPT--- $SESSION" >> "$PROMPT_FILE"
 echo "$@" >> "$PROMPT_FILE"
+if [ ! -t 0 ]; then
+    cat >> "$PROMPT_FILE"
+fi
+echo "$SESSION" >> "$ROOT/claude_sessions.log"
+
+FORMAT=txt
+case " $* " in
+    *" stream-json "*) FORMAT=json ;;
+esac
+
+# Replay the first unused turn scripted for this session
+for TURN in "$ROOT"/claude/turns/*/; do
+    [ -d "$TURN" ] || continue
+    case "$SESSION" in
+        "$(cat "$TURN/session")"*) ;;
+        *) continue ;;
+    esac
+    mkdir "$TURN/used" 2>/dev/null || continue
+    if [ -d "$TURN/files" ]; then
+        cp -R "$TURN/files/." .
+    fi
+    cat "$TURN/response.$FORMAT"
+    exit 0
+done
 
 # Read response from response file
-RESPONSE_FILE="%s/claude_response.txt"
+RESPONSE_FILE="$ROOT/claude_response.txt"
 if [ -f "$RESPONSE_FILE" ]; then
     cat "$RESPONSE_FILE"
 else
     echo '{"type":"result","message":{"content":[{"type":"text","text":"Mock response: I will implement the requested changes."}]}}'
 fi
-`, e.RootDir, e.RootDir)
+`, e.RootDir)
 
 	mockPath := filepath.Join(e.BinDir, "claude")
 	if err := os.WriteFile(mockPath, []byte(mockScript), 0755); err != nil {
@@ -267,15 +319,26 @@ fi
 	}
 }
 
-// setupGitHubMock creates a mock gh CLI.
+// setupGitHubMock creates a mock gh CLI. PRs it creates are recorded for
+// PullRequests and reported as open.
 func (e *Environment) setupGitHubMock() {
 	e.t.Helper()
 
-	mockScript := `#!/bin/bash
+	mockScript := fmt.Sprintf(`#!/bin/bash
 # Mock GitHub CLI for testing
 
+ROOT="%s"
+
 if [[ "$1" == "pr" && "$2" == "create" ]]; then
-    echo "https://github.com/example/repo/pull/42"
+    mkdir -p "$ROOT/gh"
+    N=$(ls "$ROOT/gh" | wc -l | tr -d ' ')
+    printf '%%s\0' "$@" > "$ROOT/gh/pr-$((N + 1))"
+    echo "https://github.com/example/repo/pull/$((N + 42))"
+    exit 0
+fi
+
+if [[ "$1" == "pr" && "$2" == "view" ]]; then
+    echo "OPEN"
     exit 0
 fi
 
@@ -285,7 +348,7 @@ if [[ "$1" == "auth" && "$2" == "status" ]]; then
 fi
 
 echo "Mock gh: $@"
-`
+`, e.RootDir)
 
 	mockPath := filepath.Join(e.BinDir, "gh")
 	if err := os.WriteFile(mockPath, []byte(mockScript), 0755); err != nil {
@@ -332,12 +395,14 @@ func (e *Environment) SetClaudeResponse(response string) {
 	}
 }
 
-// SetClaudeResponseSequence sets a sequence of Claude responses.
+// SetClaudeResponseSequence sets a sequence of Claude responses, given in
+// order to whichever agent calls next.
 func (e *Environment) SetClaudeResponseSequence(responses []string) {
-	e.mu.Lock()
-	e.claudeResponses = responses
-	e.claudeIndex = 0
-	e.mu.Unlock()
+	turns := make([]Turn, len(responses))
+	for i, r := range responses {
+		turns[i] = Turn{Response: r}
+	}
+	e.ScriptClaude(turns...)
 }
 
 // GetEnv returns environment variables for running commands with mocks.
@@ -424,6 +489,20 @@ type TicketFixture struct {
 	Labels      []string
 }
 
+// Task returns the ticket as a Linear task with the given identifier.
+func (f TicketFixture) Task(identifier string) task.Task {
+	return task.NewLinearTask(&linear.Ticket{
+		ID:          f.ID,
+		Identifier:  identifier,
+		Title:       f.Title,
+		Description: f.Description,
+		State:       f.State,
+		Priority:    f.Priority,
+		Labels:      f.Labels,
+		BranchName:  f.BranchName,
+	})
+}
+
 // jsonEscape escapes a string for JSON.
 func jsonEscape(s string) string {
 	b, _ := json.Marshal(s)
@@ -3,6 +3,7 @@ package testenv
 import (
 	"context"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -274,3 +275,44 @@ func TestCleanup(t *testing.T) {
 		t.Error("Root dir should be removed after cleanup")
 	}
 }
+
+func TestScriptedClaude(t *testing.T) {
+	env := New(t).Setup()
+	defer env.Cleanup()
+	env.ScriptClaude(
+		Turn{Session: SessionReviewer, Response: "review one"},
+		Turn{Session: SessionExecutor, Response: "done", Files: map[string]string{"pkg/util/new.go": "package util\n"}},
+		Turn{Session: SessionReviewer, Response: "review two"},
+	)
+
+	call := func(session string, args ...string) string {
+		cmd := exec.Command(filepath.Join(env.BinDir, "claude"), args...)
+		cmd.Dir = env.RepoDir
+		cmd.Env = append(os.Environ(), "BOATMAN_SESSION="+session)
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("mock claude failed: %v", err)
+		}
+		return strings.TrimSpace(string(out))
+	}
+
+	// Turns answer their session in order, whatever was scripted between
+	if out := call("reviewer-1", "-p"); out != "review one" {
+		t.Errorf("Expected the first review, got %q", out)
+	}
+	if out := call("executor", "-p", "--output-format", "stream-json"); !strings.Contains(out, `"text":"done"`) {
+		t.Errorf("Expected a stream-json reply, got %q", out)
+	}
+	if !env.FileExists("pkg/util/new.go") {
+		t.Error("Expected the executor turn to write its file")
+	}
+	if out := call("reviewer-2"); out != "review two" {
+		t.Errorf("Expected the second review, got %q", out)
+	}
+	if got := strings.Join(env.ClaudeSessions(), " "); got != "reviewer-1 executor reviewer-2" {
+		t.Errorf("Unexpected sessions: %s", got)
+	}
+	if n := env.UnusedTurns(); n != 0 {
+		t.Errorf("Expected all turns used, %d left", n)
+	}
+}